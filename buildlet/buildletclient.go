@@ -6,7 +6,9 @@ package buildlet // import "golang.org/x/build/buildlet"
 
 import (
 	"bufio"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,9 +18,15 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 var _ Client = (*client)(nil)
@@ -28,18 +36,64 @@ var _ Client = (*client)(nil)
 //
 // This constructor returns immediately without testing the host or auth.
 func NewClient(ipPort string, kp KeyPair) Client {
-	tr := &http.Transport{
-		Dial:            defaultDialer(),
-		DialTLS:         kp.tlsDialer(),
-		IdleConnTimeout: time.Minute,
-	}
+	return NewClientOpts(ipPort, kp, ClientOpts{})
+}
+
+// ClientOpts customizes how a client connects to its buildlet. See
+// NewClientOpts.
+type ClientOpts struct {
+	// DialContext dials the connections to the buildlet, including
+	// those of ConnectSSHContext, such as through a SOCKS proxy.
+	// If nil, connections are dialed directly.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Proxy returns the HTTP proxy to use for a request, as in
+	// http.Transport.Proxy. If nil, no proxy is used. Use
+	// http.ProxyFromEnvironment to honor $HTTPS_PROXY and $HTTP_PROXY.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// TLSConfig is the base configuration of TLS connections to the
+	// buildlet, such as one with client certificates required by a
+	// proxy. If the client has a KeyPair, the buildlet's certificate
+	// is checked against it rather than verified with RootCAs.
+	TLSConfig *tls.Config
+
+	// Transport, if non-nil, sends the client's HTTP requests instead
+	// of a transport built from the options above. DialContext and
+	// TLSConfig still apply to ConnectSSHContext.
+	Transport http.RoundTripper
+}
+
+// NewClientOpts is like NewClient, but connects to the buildlet as
+// configured by opts.
+func NewClientOpts(ipPort string, kp KeyPair, opts ClientOpts) Client {
 	c := &client{
-		ipPort:     ipPort,
-		tls:        kp,
-		password:   kp.Password(),
-		httpClient: &http.Client{Transport: tr},
-		closeFuncs: []func(){tr.CloseIdleConnections},
+		ipPort:    ipPort,
+		tls:       kp,
+		password:  kp.Password(),
+		netDial:   opts.DialContext,
+		tlsConfig: opts.TLSConfig,
+	}
+	rt := opts.Transport
+	if rt == nil {
+		tr := &http.Transport{
+			Proxy:           opts.Proxy,
+			DialContext:     opts.DialContext,
+			TLSClientConfig: opts.TLSConfig,
+			IdleConnTimeout: time.Minute,
+		}
+		if tr.DialContext == nil {
+			tr.Dial = defaultDialer()
+		}
+		if !kp.IsZero() {
+			tr.TLSClientConfig = kp.tlsConfig(opts.TLSConfig)
+		}
+		rt = tr
 	}
+	if ci, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		c.closeFuncs = append(c.closeFuncs, ci.CloseIdleConnections)
+	}
+	c.httpClient = &http.Client{Transport: rt}
 	c.setCommon()
 	return c
 }
@@ -47,6 +101,7 @@ func NewClient(ipPort string, kp KeyPair) Client {
 func (c *client) setCommon() {
 	c.peerDead = make(chan struct{})
 	c.ctx, c.ctxCancel = context.WithCancel(context.Background())
+	c.deadCtx, c.deadCancel = context.WithCancel(context.Background())
 }
 
 // SetOnHeartbeatFailure sets a function to be called when heartbeats
@@ -60,19 +115,21 @@ func (c *client) SetOnHeartbeatFailure(fn func()) {
 var ErrClosed = errors.New("buildlet: Client closed")
 
 // Close destroys and closes down the buildlet, destroying all state
-// immediately.
+// immediately. It is equivalent to CloseContext with a background context.
 func (c *client) Close() error {
-	// TODO(bradfitz): have a Client-wide Done channel we set on
-	// all outbound HTTP Requests and close it in the once here?
-	// Then if something was in-flight and somebody else Closes,
-	// the local http.Transport notices, rather than noticing via
-	// the remote machine dying or timing out.
+	return c.CloseContext(context.Background())
+}
+
+// CloseContext destroys and closes down the buildlet, destroying all
+// state immediately. Any requests still in flight are aborted.
+// The context bounds the best-effort request asking the buildlet to halt.
+func (c *client) CloseContext(ctx context.Context) error {
 	c.closeOnce.Do(func() {
 		// Send a best-effort notification to the server to destroy itself.
 		// Don't want too long (since it's likely in a broken state anyway).
 		// Ignore the return value, since we're about to forcefully destroy
 		// it anyway.
-		req, err := http.NewRequest("POST", c.URL()+"/halt", nil)
+		req, err := http.NewRequestWithContext(ctx, "POST", c.URL()+"/halt", nil)
 		if err != nil {
 			// ignore.
 		} else {
@@ -97,9 +154,118 @@ func (c *client) setPeerDead(err error) {
 		}
 		c.deadErr = err
 		close(c.peerDead)
+		if c.deadCancel != nil {
+			c.deadCancel()
+		}
 	})
 }
 
+// ReconnectOpts configures how a client recovers when its connection to
+// the buildlet breaks. See SetReconnect.
+type ReconnectOpts struct {
+	// Window is how long the client keeps trying to reach the buildlet
+	// again once heartbeats fail, before declaring it dead.
+	// Zero disables reconnecting.
+	Window time.Duration
+
+	// MinBackoff and MaxBackoff bound the delay between attempts, which
+	// doubles after each failed attempt. They default to one second and
+	// 30 seconds.
+	MinBackoff, MaxBackoff time.Duration
+
+	// OnEvent, if non-nil, is called when the client starts
+	// reconnecting, reconnects, or gives up.
+	OnEvent func(ReconnectEvent)
+}
+
+// ReconnectState is the state reported by a ReconnectEvent.
+type ReconnectState int
+
+const (
+	// Reconnecting means the connection broke and the client started
+	// trying to reach the buildlet again.
+	Reconnecting ReconnectState = iota
+	// Reconnected means the buildlet was reached again.
+	Reconnected
+	// ReconnectFailed means the buildlet could not be reached within
+	// the reconnect window, and the client was declared dead.
+	ReconnectFailed
+)
+
+func (s ReconnectState) String() string {
+	switch s {
+	case Reconnecting:
+		return "reconnecting"
+	case Reconnected:
+		return "reconnected"
+	case ReconnectFailed:
+		return "reconnect failed"
+	}
+	return fmt.Sprintf("ReconnectState(%d)", int(s))
+}
+
+// ReconnectEvent describes a change in the state of a reconnecting client.
+type ReconnectEvent struct {
+	State    ReconnectState
+	Attempts int           // attempts made so far
+	Elapsed  time.Duration // time since the client started reconnecting
+	Err      error         // the error which started reconnecting, or the last attempt's error
+}
+
+// SetObserver sets an observer to be told about every request sent to
+// the buildlet, including heartbeats.
+// SetObserver must be called before any use of the buildlet.
+func (c *client) SetObserver(o Observer) {
+	c.observer = o
+}
+
+// SetCallTimeout sets the default timeout of each call making requests
+// to the buildlet, such as Exec, PutTar, GetTar or ListDir. A Timeout
+// set in a call's options replaces it. A call which times out returns
+// a *TimeoutError. The default, zero, means calls only end when their
+// context does.
+// SetCallTimeout must be called before any use of the buildlet.
+func (c *client) SetCallTimeout(d time.Duration) {
+	c.callTimeout = d
+}
+
+// SetConcurrencyLimits limits the number of calls in flight to the
+// buildlet at once: heavy calls, which transfer files or run commands
+// (PutTar, PutTarFromURL, GetTar, Put and Exec), and light ones (Status,
+// WorkDir, ListDir, RemoveAll and RemoveGlob) are limited separately. Calls beyond a
+// limit wait for an earlier one to finish, or for their context to be
+// done. A limit which isn't positive means no limit, the default.
+// Heartbeats are never limited. Time spent waiting is reported to the
+// observer as RequestStats.QueueWait.
+// SetConcurrencyLimits must be called before any use of the buildlet.
+func (c *client) SetConcurrencyLimits(heavy, light int) {
+	c.heavy = newLimiter(heavy)
+	c.light = newLimiter(light)
+}
+
+// SetReconnect enables reconnecting to the buildlet when heartbeats or
+// requests fail because of a broken connection, rather than declaring
+// the buildlet dead right away. While reconnecting, each attempt redials
+// the buildlet, re-authenticating as needed. Calls which are safe to
+// repeat, listed in RetryPolicy, are retried once the client reconnects.
+// SetReconnect must be called before any use of the buildlet.
+func (c *client) SetReconnect(opts ReconnectOpts) {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(30*time.Second, opts.MinBackoff)
+	}
+	c.reconnect = opts
+}
+
+// SetRetryPolicy sets the policy for retrying calls which are safe to
+// repeat when they fail transiently. By default, calls aren't retried.
+// SetRetryPolicy must be called before any use of the buildlet.
+func (c *client) SetRetryPolicy(p RetryPolicy) {
+	c.retryPolicy = p
+}
+
 // SetDescription sets a short description of where the buildlet
 // connection came from.  This is used by the build coordinator status
 // page, mostly for debugging.
@@ -163,6 +329,12 @@ type client struct {
 	name           string                                  // optional name for debugging, returned by Name
 	instanceName   string                                  // instance name for GCE and EC2 VMs
 
+	// netDial and tlsConfig are ClientOpts.DialContext and
+	// ClientOpts.TLSConfig. A nil netDial means to use
+	// net.Dialer.DialContext.
+	netDial   func(ctx context.Context, network, addr string) (net.Conn, error)
+	tlsConfig *tls.Config
+
 	closeFuncs []func() // optional extra code to run on close
 
 	ctx              context.Context
@@ -176,8 +348,30 @@ type client struct {
 	peerDead          chan struct{} // closed on peer death
 	deadErr           error         // guarded by peerDead's close
 
+	// deadCtx is canceled on peer death, which aborts all in-flight requests.
+	deadCtx    context.Context
+	deadCancel context.CancelFunc
+
+	reconnect   ReconnectOpts
+	retryPolicy RetryPolicy
+	observer    Observer      // optional
+	callTimeout time.Duration // default timeout of each call; zero for none
+	heavy       limiter       // limits heavy calls; nil for no limit
+	light       limiter       // limits light calls; nil for no limit
+
 	mu     sync.Mutex
 	broken bool // client is broken in some way
+	// reconnected is non-nil while reconnecting, and closed once the
+	// client either reconnected or was declared dead.
+	reconnected chan struct{}
+	// heartbeatRTTs is a ring of the round-trip times of the last
+	// heartbeats, of which there were heartbeats in total.
+	heartbeatRTTs [heartbeatWindow]time.Duration
+	heartbeats    int
+	// encodings are the tarball encodings the buildlet supports, if
+	// haveEncodings is set.
+	encodings     []string
+	haveEncodings bool
 }
 
 func (c *client) String() string {
@@ -248,27 +442,118 @@ func (c *client) authUsername() string {
 	return "gomote"
 }
 
+// do sends req to the buildlet. The request is aborted, including while
+// its request or response body is being transferred, when either its
+// context is done or the peer dies, such as when the client is closed.
+// Connections upgraded with a 101 response are not affected by either.
 func (c *client) do(req *http.Request) (*http.Response, error) {
 	c.initHeartbeatOnce.Do(c.initHeartbeats)
+	if c.observer != nil {
+		return c.observe(req)
+	}
+	return c.send(req)
+}
+
+// observe sends req, reporting it to c's observer. The request is done
+// once its response body has been read to EOF or closed.
+func (c *client) observe(req *http.Request) (*http.Response, error) {
+	r := Request{Method: req.URL.Path, Instance: c.Name(), Start: time.Now()}
+	queueWait, _ := req.Context().Value(queueWaitKey{}).(time.Duration)
+	encoding, _ := req.Context().Value(encodingKey{}).(string)
+	var sent, received atomic.Int64
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = countingReadCloser{countingReader{req.Body, &sent}, req.Body}
+	}
+	c.observer.RequestStart(r)
+	done := func(err error) {
+		c.observer.RequestDone(r, RequestStats{
+			Duration:      time.Since(r.Start),
+			BytesSent:     sent.Load(),
+			BytesReceived: received.Load(),
+			QueueWait:     queueWait,
+			Encoding:      encoding,
+			Err:           err,
+		})
+	}
+	res, err := c.send(req)
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+	if res.StatusCode == http.StatusSwitchingProtocols {
+		done(nil)
+		return res, nil
+	}
+	if encoding != "" && req.Method == "GET" {
+		// The buildlet picks the encoding of the tarballs it sends.
+		encoding = responseEncoding(res)
+	}
+	var statusErr error
+	if res.StatusCode >= 400 {
+		statusErr = errors.New(res.Status)
+	}
+	body := countingReadCloser{countingReader{res.Body, &received}, res.Body}
+	res.Body = onEOFReadCloser{body, sync.OnceFunc(func() { done(statusErr) })}
+	return res, nil
+}
+
+// send sends req to the buildlet.
+func (c *client) send(req *http.Request) (*http.Response, error) {
 	if c.password != "" {
 		req.SetBasicAuth(c.authUsername(), c.password)
 	}
 	if c.remoteBuildlet != "" {
 		req.Header.Set("X-Buildlet-Proxy", c.remoteBuildlet)
 	}
-	return c.httpClient.Do(req)
+	if c.deadCtx == nil {
+		return c.httpClient.Do(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(c.deadCtx, cancel)
+	release := func() {
+		stop()
+		cancel()
+	}
+	req = req.WithContext(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = newContextBody(ctx, req.Body)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if res.StatusCode == http.StatusSwitchingProtocols {
+		// The body is the upgraded connection, which the transport
+		// hands over to the caller; canceling the request no longer
+		// affects it.
+		release()
+		return res, nil
+	}
+	// Release the request's context once the caller is done with the body.
+	res.Body = onEOFReadCloser{res.Body, sync.OnceFunc(release)}
+	return res, nil
 }
 
 // ProxyTCP connects to the given port on the remote buildlet.
+//
+// Deprecated: Use ProxyTCPContext.
+func (c *client) ProxyTCP(port int) (io.ReadWriteCloser, error) {
+	return c.ProxyTCPContext(context.Background(), port)
+}
+
+// ProxyTCPContext connects to the given port on the remote buildlet.
 // The buildlet client must currently be a gomote client (RemoteName != "")
 // and the target type must be a VM type running on GCE. This was primarily
 // created for RDP to Windows machines, but it might get reused for other
 // purposes in the future.
-func (c *client) ProxyTCP(port int) (io.ReadWriteCloser, error) {
+//
+// The context only bounds establishing the connection.
+func (c *client) ProxyTCPContext(ctx context.Context, port int) (io.ReadWriteCloser, error) {
 	if c.RemoteName() == "" {
 		return nil, errors.New("ProxyTCP currently only supports gomote-created buildlets")
 	}
-	req, err := http.NewRequest("POST", c.URL()+"/tcpproxy", nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL()+"/tcpproxy", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -324,19 +609,137 @@ func (c *client) heartbeatLoop() {
 			return
 		case <-time.After(10 * time.Second):
 			t0 := time.Now()
-			if _, err := c.Status(context.Background()); err != nil {
+			if pr, err := c.ping(context.Background()); err != nil {
 				failInARow++
 				if failInARow == 3 {
 					log.Printf("Buildlet %v failed three heartbeats; final error: %v", c, err)
+					if c.reconnect.Window > 0 {
+						<-c.startReconnect(err)
+						failInARow = 0
+						continue
+					}
 					c.setPeerDead(fmt.Errorf("Buildlet %v failed heartbeat after %v; marking dead; err=%v", c, time.Since(t0), err))
 				}
 			} else {
 				failInARow = 0
+				c.recordHeartbeat(pr.RTT)
 			}
 		}
 	}
 }
 
+// startReconnect starts reconnecting to the buildlet because of err,
+// unless the client is already reconnecting. It returns a channel which
+// is closed once the client reconnected or was declared dead.
+func (c *client) startReconnect(err error) <-chan struct{} {
+	select {
+	case <-c.peerDead:
+		// Nothing to reconnect to.
+		return c.peerDead
+	default:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reconnected == nil {
+		c.reconnected = make(chan struct{})
+		go c.reconnectLoop(err, c.reconnected)
+	}
+	return c.reconnected
+}
+
+func (c *client) reconnectLoop(err error, done chan struct{}) {
+	defer func() {
+		c.mu.Lock()
+		c.reconnected = nil
+		c.mu.Unlock()
+		close(done)
+	}()
+	event := func(state ReconnectState, ev ReconnectEvent) {
+		ev.State = state
+		if c.reconnect.OnEvent != nil {
+			c.reconnect.OnEvent(ev)
+		}
+	}
+	t0 := time.Now()
+	ev := ReconnectEvent{Err: err}
+	log.Printf("Buildlet %v: reconnecting after error: %v", c, err)
+	event(Reconnecting, ev)
+	backoff := c.reconnect.MinBackoff
+	for time.Since(t0) < c.reconnect.Window {
+		// Drop any connection left over from before the failure, so that
+		// the next request dials and authenticates afresh.
+		c.httpClient.CloseIdleConnections()
+		ctx, cancel := context.WithDeadline(context.Background(), t0.Add(c.reconnect.Window))
+		_, err := c.status(ctx, false)
+		cancel()
+		ev.Attempts++
+		ev.Elapsed = time.Since(t0)
+		if err == nil {
+			log.Printf("Buildlet %v: reconnected after %v", c, ev.Elapsed)
+			event(Reconnected, ev)
+			return
+		}
+		ev.Err = err
+		select {
+		case <-c.peerDead:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, c.reconnect.MaxBackoff)
+	}
+	ev.Elapsed = time.Since(t0)
+	log.Printf("Buildlet %v: unable to reconnect within %v; final error: %v", c, c.reconnect.Window, ev.Err)
+	c.setPeerDead(fmt.Errorf("Buildlet %v: unable to reconnect within %v; marking dead; err=%v", c, c.reconnect.Window, ev.Err))
+	event(ReconnectFailed, ev)
+}
+
+// reconnectAfter reports whether an idempotent request which failed with
+// err should be retried. If reconnecting is enabled and err is the result
+// of a broken connection, it waits for the client to reconnect and
+// reports whether it did.
+func (c *client) reconnectAfter(ctx context.Context, err error) bool {
+	if c.reconnect.Window <= 0 || ctx.Err() != nil || !isConnError(err) {
+		return false
+	}
+	select {
+	case <-c.startReconnect(err):
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case <-c.peerDead:
+		return false
+	default:
+		return true
+	}
+}
+
+// retry calls fn, which makes a request that is safe to repeat, retrying
+// it according to c's retry policy. If it still fails because of a broken
+// connection and reconnecting is enabled, it's called once more after the
+// client reconnects. Once canRetry, if non-nil, reports false, fn isn't
+// called again.
+func (c *client) retry(ctx context.Context, canRetry func() bool, fn func() error) error {
+	retryable := func(err error) bool {
+		return (canRetry == nil || canRetry()) && c.retryPolicy.retryable(err)
+	}
+	err := c.retryPolicy.Retry(ctx, retryable, fn)
+	if err != nil && (canRetry == nil || canRetry()) && c.reconnectAfter(ctx, err) {
+		err = fn()
+	}
+	return err
+}
+
+// isConnError reports whether err is likely the result of a broken
+// connection to the buildlet.
+func isConnError(err error) bool {
+	if errors.Is(err, errHeaderTimeout) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
 var errHeaderTimeout = errors.New("timeout waiting for headers")
 
 // doHeaderTimeout calls c.do(req) and returns its results, or
@@ -397,7 +800,7 @@ func (c *client) doOK(req *http.Request) error {
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
 		slurp, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
-		return fmt.Errorf("%v; body: %s", res.Status, slurp)
+		return &statusError{res.StatusCode, fmt.Sprintf("%v; body: %s", res.Status, slurp)}
 	}
 	return nil
 }
@@ -407,12 +810,34 @@ func (c *client) doOK(req *http.Request) error {
 // If dir is empty, they're placed at the root of the buildlet's work directory.
 // The dir is created if necessary.
 // The Reader must be of a tar.gz file.
-func (c *client) PutTar(ctx context.Context, r io.Reader, dir string) error {
-	req, err := http.NewRequest("PUT", c.URL()+"/writetgz?dir="+url.QueryEscape(dir), r)
+func (c *client) PutTar(ctx context.Context, r io.Reader, dir string, opts ...TarOpts) error {
+	enc, err := tarEncoding(opts)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.callContext(ctx, "PutTar", tarTimeout(opts))
+	defer cancel()
+	ctx, release, err := c.acquire(ctx, c.heavy)
+	if err != nil {
+		return timeoutError(ctx, err)
+	}
+	defer release()
+	wire, err := c.wireEncoding(ctx, enc)
+	if err != nil {
+		return timeoutError(ctx, err)
+	}
+	if wire != enc {
+		rc := recompressTar(r, enc, wire)
+		defer rc.Close()
+		r = rc
+	}
+	ctx = context.WithValue(ctx, encodingKey{}, wire)
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.URL()+"/writetgz?dir="+url.QueryEscape(dir), r)
 	if err != nil {
 		return err
 	}
-	return c.doOK(req.WithContext(ctx))
+	req.Header.Set("Content-Encoding", wire)
+	return timeoutError(ctx, c.doOK(req))
 }
 
 // PutTarFromURL tells the buildlet to download the tar.gz file from tarURL
@@ -420,49 +845,233 @@ func (c *client) PutTar(ctx context.Context, r io.Reader, dir string) error {
 // If dir is empty, they're placed at the root of the buildlet's work directory.
 // The dir is created if necessary.
 // The url must be of a tar.gz file.
-func (c *client) PutTarFromURL(ctx context.Context, tarURL, dir string) error {
+func (c *client) PutTarFromURL(ctx context.Context, tarURL, dir string, opts ...TarOpts) error {
+	_, err := c.PutTarFromURLSHA256(ctx, tarURL, dir, "", opts...)
+	return err
+}
+
+// PutTarFromURLSHA256 is like PutTarFromURL, but if wantSHA256 is not
+// empty, the buildlet verifies that the downloaded file has that
+// hex-encoded SHA-256 digest while extracting it, and leaves dir
+// unmodified if it doesn't. Verification requires buildlet version 30
+// or later.
+//
+// It returns the digest computed by the buildlet, which is empty for
+// older buildlets.
+func (c *client) PutTarFromURLSHA256(ctx context.Context, tarURL, dir, wantSHA256 string, opts ...TarOpts) (sha256 string, err error) {
+	ctx, cancel := c.callContext(ctx, "PutTarFromURL", tarTimeout(opts))
+	defer cancel()
+	defer func() { err = timeoutError(ctx, err) }()
+	ctx, release, err := c.acquire(ctx, c.heavy)
+	if err != nil {
+		return "", err
+	}
+	defer release()
 	form := url.Values{
 		"url": {tarURL},
 	}
-	req, err := http.NewRequest("POST", c.URL()+"/writetgz?dir="+url.QueryEscape(dir), strings.NewReader(form.Encode()))
+	header := tarHeader(opts)
+	if wantSHA256 != "" || len(header) > 0 {
+		// Older buildlets would ignore the digest and headers.
+		st, err := c.Status(ctx)
+		if err != nil {
+			return "", err
+		}
+		if wantSHA256 != "" && st.Version < 30 {
+			return "", fmt.Errorf("buildlet version %d can't verify SHA-256 digests; need version 30 or later", st.Version)
+		}
+		if len(header) > 0 && st.Version < 36 {
+			return "", fmt.Errorf("buildlet version %d can't send headers with URL downloads; need version 36 or later", st.Version)
+		}
+	}
+	if wantSHA256 != "" {
+		form.Set("sha256", wantSHA256)
+	}
+	for name, values := range header {
+		for _, v := range values {
+			form.Add("header", name+": "+v)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL()+"/writetgz?dir="+url.QueryEscape(dir), strings.NewReader(form.Encode()))
 	if err != nil {
-		return err
+		return "", err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	return c.doOK(req.WithContext(ctx))
+	res, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		slurp, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return "", fmt.Errorf("%v; body: %s", res.Status, slurp)
+	}
+	return res.Header.Get("X-Go-Tgz-Sha256"), nil
 }
 
 // Put writes the provided file to path (relative to workdir) and sets mode.
 // It creates any missing parent directories with 0755 permission.
 func (c *client) Put(ctx context.Context, r io.Reader, path string, mode os.FileMode) error {
+	ctx, cancel := c.callContext(ctx, "Put", 0)
+	defer cancel()
+	ctx, release, err := c.acquire(ctx, c.heavy)
+	if err != nil {
+		return timeoutError(ctx, err)
+	}
+	defer release()
 	param := url.Values{
 		"path": {path},
 		"mode": {fmt.Sprint(int64(mode))},
 	}
-	req, err := http.NewRequest("PUT", c.URL()+"/write?"+param.Encode(), r)
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.URL()+"/write?"+param.Encode(), r)
 	if err != nil {
 		return err
 	}
-	return c.doOK(req.WithContext(ctx))
+	return timeoutError(ctx, c.doOK(req))
 }
 
 // GetTar returns a .tar.gz stream of the given directory, relative to the buildlet's work dir.
 // The provided dir may be empty to get everything.
-func (c *client) GetTar(ctx context.Context, dir string) (io.ReadCloser, error) {
-	req, err := http.NewRequest("GET", c.URL()+"/tgz?dir="+url.QueryEscape(dir), nil)
+// The timeout, if any, also bounds reading the stream.
+// The stream is compressed with zstd instead if the options ask for it.
+// Either way, it's transferred compressed with zstd if the buildlet
+// supports it, which is much cheaper for the buildlet than gzip.
+func (c *client) GetTar(ctx context.Context, dir string, opts ...TarOpts) (io.ReadCloser, error) {
+	enc, err := tarEncoding(opts)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.do(req.WithContext(ctx))
+	ctx, cancel := c.callContext(ctx, "GetTar", tarTimeout(opts))
+	ctx, release, err := c.acquire(ctx, c.heavy)
+	if err != nil {
+		cancel()
+		return nil, timeoutError(ctx, err)
+	}
+	// The stream holds its slot until it's closed.
+	cancel = func(cancelCall context.CancelFunc) context.CancelFunc {
+		return func() {
+			cancelCall()
+			release()
+		}
+	}(cancel)
+	// Buildlets which don't support zstd ignore the request for it, and
+	// send gzip with no Content-Encoding.
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, encodingKey{}, EncodingGzip), "GET", c.URL()+"/tgz?dir="+url.QueryEscape(dir), nil)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
+	req.Header.Set("Accept-Encoding", EncodingZstd)
+	res, err := c.do(req)
+	if err != nil {
+		cancel()
+		return nil, timeoutError(ctx, err)
+	}
 	if res.StatusCode != http.StatusOK {
 		slurp, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
 		res.Body.Close()
+		cancel()
 		return nil, fmt.Errorf("%v; body: %s", res.Status, slurp)
 	}
-	return res.Body, nil
+	rc := res.Body
+	if wire := responseEncoding(res); wire != enc {
+		rc = recompressTar(res.Body, wire, enc)
+	}
+	return &timeoutReadCloser{rc: rc, ctx: ctx, cancel: cancel}, nil
+}
+
+// TarOpts are options for the calls transferring tarballs. At most one
+// may be passed to each call.
+type TarOpts struct {
+	// Timeout, if positive, bounds the transfer without affecting other
+	// calls, replacing the client's default call timeout. A transfer
+	// which times out returns a *TimeoutError.
+	Timeout time.Duration
+
+	// Encoding is the compression of the tarball passed to PutTar, or
+	// returned by GetTar: EncodingGzip, the default, or EncodingZstd.
+	// PutTar sends tarballs compressed with zstd only if the buildlet
+	// supports it (see Status.Encodings); otherwise, the client converts
+	// them to the gzip the buildlet uses. GetTar receives them compressed
+	// with zstd whenever the buildlet supports it, and the client
+	// converts them to Encoding if needed.
+	Encoding string
+
+	// Header, if set, is sent with the request fetching the URL passed
+	// to PutTarFromURL or PutTarFromURLSHA256, such as to authenticate
+	// to an artifact store. It requires buildlet version 36 or later.
+	Header http.Header
+}
+
+// The encodings of tarballs; see TarOpts.Encoding.
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
+
+func tarTimeout(opts []TarOpts) time.Duration {
+	if len(opts) == 0 {
+		return 0
+	}
+	return opts[0].Timeout
+}
+
+func tarHeader(opts []TarOpts) http.Header {
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts[0].Header
+}
+
+func tarEncoding(opts []TarOpts) (string, error) {
+	if len(opts) == 0 || opts[0].Encoding == "" {
+		return EncodingGzip, nil
+	}
+	switch enc := opts[0].Encoding; enc {
+	case EncodingGzip, EncodingZstd:
+		return enc, nil
+	default:
+		return "", fmt.Errorf("unsupported tarball encoding %q", enc)
+	}
+}
+
+// encodingKey is the context key for the encoding of the tarball a
+// request transfers, which is reported to the observer.
+type encodingKey struct{}
+
+// wireEncoding returns the encoding in which to send the buildlet a
+// tarball compressed with enc: enc itself if the buildlet supports it,
+// and gzip otherwise.
+func (c *client) wireEncoding(ctx context.Context, enc string) (string, error) {
+	if enc == EncodingGzip {
+		return enc, nil
+	}
+	c.mu.Lock()
+	encs, ok := c.encodings, c.haveEncodings
+	c.mu.Unlock()
+	if !ok {
+		st, err := c.status(ctx, false)
+		if err != nil {
+			return "", err
+		}
+		encs = st.Encodings
+		c.mu.Lock()
+		c.encodings, c.haveEncodings = encs, true
+		c.mu.Unlock()
+	}
+	if slices.Contains(encs, enc) {
+		return enc, nil
+	}
+	return EncodingGzip, nil
+}
+
+// responseEncoding returns the encoding of the tarball in the response
+// to a /tgz request.
+func responseEncoding(res *http.Response) string {
+	if enc := res.Header.Get("Content-Encoding"); enc != "" {
+		return enc
+	}
+	return EncodingGzip
 }
 
 // ExecOpts are options for a remote command invocation.
@@ -471,6 +1080,24 @@ type ExecOpts struct {
 	// If nil, the output is discarded.
 	Output io.Writer
 
+	// Stderr, if non-nil, is the output of stderr, which is then not
+	// written to Output. Buildlets which don't separate the streams
+	// write both to Output.
+	Stderr io.Writer
+
+	// OnOutput, if non-nil, is called with each chunk of output as it
+	// arrives from the buildlet, after the chunk is written to Output.
+	// Chunks are passed in order and are not aligned to lines. OnOutput
+	// must not retain p after it returns, and it is never called after
+	// Exec returns. If OnOutput returns an error, Exec stops and returns
+	// it as execErr without marking the buildlet as broken.
+	OnOutput func(p []byte) error
+
+	// OnStream is like OnOutput, but is also passed the stream each
+	// chunk belongs to. Buildlets which don't separate the streams
+	// report all output as ExecOutput. It's called after OnOutput.
+	OnStream func(s ExecStream, p []byte) error
+
 	// Dir is the directory from which to execute the command,
 	// as an absolute or relative path using the buildlet's native
 	// path separator, or a slash-separated relative path.
@@ -512,12 +1139,149 @@ type ExecOpts struct {
 	// response from the buildlet, but before the output begins
 	// writing to Output.
 	OnStartExec func()
+
+	// Timeout, if positive, bounds this call, replacing the client's
+	// default call timeout. If it expires, the buildlet kills the
+	// command and Exec returns a *TimeoutError as execErr; unlike when
+	// the context's deadline is exceeded, the buildlet is not marked
+	// as broken, so the command can be retried.
+	Timeout time.Duration
 }
 
 // ErrTimeout is a sentinel error that represents that waiting
 // for a command to complete has exceeded the given timeout.
 var ErrTimeout = errors.New("buildlet: timeout waiting for command to complete")
 
+// TimeoutError is returned by a call which exceeded its own timeout: the
+// Timeout in its options, or the client's default set with
+// SetCallTimeout. Such timeouts don't affect other calls, so the call
+// can be retried.
+//
+// errors.Is reports TimeoutErrors as both ErrTimeout and
+// context.DeadlineExceeded.
+type TimeoutError struct {
+	Op    string        // the call, such as "Exec" or "PutTar"
+	Limit time.Duration // the timeout which expired
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("buildlet: %s timed out after %v", e.Op, e.Limit)
+}
+
+// Timeout reports true, so that TimeoutError satisfies net.Error.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// Temporary reports true: retrying may succeed.
+func (e *TimeoutError) Temporary() bool { return true }
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout || target == context.DeadlineExceeded
+}
+
+// callContext returns the context for the call op, which times out
+// after timeout, or after c's default call timeout if timeout isn't
+// positive.
+func (c *client) callContext(ctx context.Context, op string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = c.callTimeout
+	}
+	return withCallTimeout(ctx, op, timeout)
+}
+
+// withCallTimeout returns a context for the call op which, if timeout is
+// positive, times out after it with a *TimeoutError as its cause.
+func withCallTimeout(ctx context.Context, op string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, &TimeoutError{Op: op, Limit: timeout})
+}
+
+// callTimedOut returns the *TimeoutError if the call with context ctx
+// exceeded its own timeout, and nil otherwise.
+func callTimedOut(ctx context.Context) *TimeoutError {
+	var te *TimeoutError
+	if errors.As(context.Cause(ctx), &te) {
+		return te
+	}
+	return nil
+}
+
+// timeoutError returns the *TimeoutError if err, from the call with
+// context ctx, is due to the call exceeding its own timeout. Otherwise,
+// if ctx is done, it returns err wrapping the context's error, and err
+// if not.
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if te := callTimedOut(ctx); te != nil {
+		return te
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		// The transport may report the failure to send the request
+		// body, such as a closed pipe, rather than the cancellation
+		// which caused it.
+		return fmt.Errorf("%w: %v", ctxErr, err)
+	}
+	return err
+}
+
+// A limiter limits the number of calls in flight. A nil limiter
+// imposes no limit.
+type limiter chan struct{}
+
+func newLimiter(n int) limiter {
+	if n <= 0 {
+		return nil
+	}
+	return make(limiter, n)
+}
+
+// queueWaitKey is the context key for the time a call waited for a
+// slot from a limiter.
+type queueWaitKey struct{}
+
+// acquire waits for a slot from l, or until ctx is done or the peer
+// dies. On success, it returns a context recording the time waited and
+// a function releasing the slot.
+func (c *client) acquire(ctx context.Context, l limiter) (context.Context, func(), error) {
+	if l == nil {
+		return ctx, func() {}, nil
+	}
+	start := time.Now()
+	select {
+	case l <- struct{}{}:
+	case <-ctx.Done():
+		return ctx, nil, ctx.Err()
+	case <-c.peerDead:
+		return ctx, nil, c.deadErr
+	}
+	ctx = context.WithValue(ctx, queueWaitKey{}, time.Since(start))
+	return ctx, sync.OnceFunc(func() { <-l }), nil
+}
+
+// timeoutReadCloser reads the response to a call with a timeout, and
+// ends the call once closed.
+type timeoutReadCloser struct {
+	rc     io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	if err != io.EOF {
+		err = timeoutError(r.ctx, err)
+	}
+	return n, err
+}
+
+func (r *timeoutReadCloser) Close() error {
+	r.cancel()
+	return r.rc.Close()
+}
+
 // Exec runs cmd on the buildlet.
 //
 // cmd may be an absolute or relative path using the buildlet's native path
@@ -531,8 +1295,18 @@ var ErrTimeout = errors.New("buildlet: timeout waiting for command to complete")
 // meaningless.
 //
 // If the context's deadline is exceeded while waiting for the command
-// to complete, the returned execErr is ErrTimeout.
+// to complete, the returned execErr is ErrTimeout. If the context is
+// canceled, Exec returns promptly with the context's error as execErr,
+// and the buildlet is not marked as broken. See ExecOpts.Timeout for
+// bounding only this call.
 func (c *client) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr, execErr error) {
+	callCtx, cancel := c.callContext(ctx, "Exec", opts.Timeout)
+	defer cancel()
+	callCtx, release, err := c.acquire(callCtx, c.heavy)
+	if err != nil {
+		return nil, timeoutError(callCtx, err)
+	}
+	defer release()
 	var mode string
 	if opts.SystemLevel {
 		mode = "sys"
@@ -552,11 +1326,13 @@ func (c *client) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr
 		"path":   path,
 		"debug":  {fmt.Sprint(opts.Debug)},
 	}
-	req, err := http.NewRequest("POST", c.URL()+"/exec", strings.NewReader(form.Encode()))
+	if opts.Stderr != nil || opts.OnStream != nil {
+		form.Set("streams", "true")
+	}
+	req, err := http.NewRequestWithContext(callCtx, "POST", c.URL()+"/exec", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	// The first thing the buildlet's exec handler does is flush the headers, so
@@ -569,7 +1345,7 @@ func (c *client) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr
 		c.MarkBroken()
 		return nil, errors.New("buildlet: timeout waiting for exec header response")
 	} else if err != nil {
-		return nil, err
+		return nil, timeoutError(callCtx, err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
@@ -580,15 +1356,25 @@ func (c *client) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr
 
 	type errs struct {
 		remoteErr, execErr error
+		// onOutput is whether execErr was returned by opts.OnOutput
+		// or opts.OnStream.
+		onOutput bool
 	}
 	resc := make(chan errs, 1)
 	go func() {
 		// Stream the output:
-		out := opts.Output
-		if out == nil {
-			out = io.Discard
+		out := &execOutput{w: opts.Output, stderr: opts.Stderr, fn: opts.OnOutput, streamFn: opts.OnStream}
+		var err error
+		if res.Header.Get(ExecStreamsHeader) != "" {
+			err = readExecStreams(res.Body, out.writeStream)
+		} else {
+			_, err = io.Copy(out, res.Body)
 		}
-		if _, err := io.Copy(out, res.Body); err != nil {
+		if err != nil {
+			if out.fnErr != nil {
+				resc <- errs{execErr: out.fnErr, onOutput: true}
+				return
+			}
 			resc <- errs{execErr: fmt.Errorf("error copying response: %w", err)}
 			return
 		}
@@ -604,13 +1390,27 @@ func (c *client) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr
 			return
 		}
 		if state != "ok" {
-			resc <- errs{remoteErr: errors.New(state)}
+			resc <- errs{remoteErr: execError(state, res.Trailer.Get("Process-Exit-Code"))}
 		} else {
 			resc <- errs{} // success
 		}
 	}()
+	// Every case waits for the output to be copied, which the request's
+	// cancellation aborts, so that none of the outputs and callbacks are used
+	// after Exec returns.
 	select {
 	case res := <-resc:
+		if res.onOutput {
+			return nil, res.execErr
+		}
+		if res.execErr != nil {
+			if te := callTimedOut(callCtx); te != nil {
+				return nil, te
+			}
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil, ctx.Err()
+			}
+		}
 		if res.execErr != nil {
 			// Note: We've historically marked the buildlet as unhealthy after
 			// reaching any kind of execution error, even when it's a remote command
@@ -626,7 +1426,18 @@ func (c *client) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr
 		}
 		return res.remoteErr, res.execErr
 	case <-c.peerDead:
+		<-resc
 		return nil, c.deadErr
+	case <-callCtx.Done():
+		<-resc
+		if te := callTimedOut(callCtx); te != nil {
+			return nil, te
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.MarkBroken()
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
 	}
 }
 
@@ -635,13 +1446,180 @@ func (c *client) RemoveAll(ctx context.Context, paths ...string) error {
 	if len(paths) == 0 {
 		return nil
 	}
+	ctx, cancel := c.callContext(ctx, "RemoveAll", 0)
+	defer cancel()
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return timeoutError(ctx, err)
+	}
+	defer release()
 	form := url.Values{"path": paths}
-	req, err := http.NewRequest("POST", c.URL()+"/removeall", strings.NewReader(form.Encode()))
+	return timeoutError(ctx, c.retry(ctx, nil, func() error {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.URL()+"/removeall", strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return c.doOK(req)
+	}))
+}
+
+// RemoveGlobOpts are options for Client.RemoveGlob.
+type RemoveGlobOpts struct {
+	// Patterns select the entries to remove, using the syntax and
+	// matching rules of ListDirOpts.Patterns. A matching directory is
+	// removed along with its contents. The pattern "." selects the
+	// directory passed to RemoveGlob itself. At least one pattern is
+	// required.
+	Patterns []string
+
+	// Force allows patterns which match arbitrary names in the
+	// directories they select, such as "*", "go/*" or "[a-z]*", and the
+	// pattern ".". The buildlet refuses them otherwise.
+	Force bool
+}
+
+// RemoveGlob removes the entries under dir, relative to the work
+// directory, which match opts.Patterns, and returns how many it removed,
+// not counting entries within removed directories. An empty dir means
+// the work directory. The patterns are expanded by the buildlet, which
+// must be version 31 or later.
+func (c *client) RemoveGlob(ctx context.Context, dir string, opts RemoveGlobOpts) (removed int, err error) {
+	if len(opts.Patterns) == 0 {
+		return 0, errors.New("RemoveGlob requires at least one pattern")
+	}
+	for _, pat := range opts.Patterns {
+		if _, err := path.Match(pat, ""); err != nil {
+			return 0, fmt.Errorf("invalid pattern %q: %w", pat, err)
+		}
+	}
+	ctx, cancel := c.callContext(ctx, "RemoveGlob", 0)
+	defer cancel()
+	defer func() { err = timeoutError(ctx, err) }()
+	// Older buildlets would reject the request for lacking paths.
+	st, err := c.Status(ctx)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if st.Version < 31 {
+		return 0, fmt.Errorf("buildlet version %d can't remove entries by pattern; need version 31 or later", st.Version)
+	}
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	form := url.Values{
+		"dir":     {dir},
+		"pattern": opts.Patterns,
+		"force":   {fmt.Sprint(opts.Force)},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL()+"/removeall", strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	return c.doOK(req.WithContext(ctx))
+	res, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		slurp, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return 0, fmt.Errorf("%v; body: %s", res.Status, slurp)
+	}
+	n, err := strconv.Atoi(res.Header.Get("X-Go-Removed"))
+	if err != nil {
+		return 0, fmt.Errorf("buildlet: bad X-Go-Removed header: %v", err)
+	}
+	return n, nil
+}
+
+// TailOpts are options for Client.Tail.
+type TailOpts struct {
+	// Offset is the offset in the file at which to start.
+	Offset int64
+
+	// Lines, if positive, starts at the beginning of the file's last
+	// Lines lines instead of at Offset.
+	Lines int
+
+	// Follow, if true, keeps sending data appended to the file, like
+	// tail -f, until the context is done.
+	Follow bool
+
+	// OnTruncate, if non-nil, is called when the file is found to have
+	// been truncated or replaced, before Tail restarts from its
+	// beginning.
+	OnTruncate func()
+}
+
+// Tail writes the contents of the file at path, relative to the work
+// directory, to w as they arrive. Without opts.Follow, it returns once
+// the end of the file is reached; otherwise it returns the context's
+// error once the context is done. Tail requires buildlet version 32 or
+// later.
+//
+// Tail isn't subject to the client's default call timeout nor its
+// concurrency limits, since following a file may last indefinitely.
+func (c *client) Tail(ctx context.Context, path string, w io.Writer, opts TailOpts) error {
+	st, err := c.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if st.Version < 32 {
+		return fmt.Errorf("buildlet version %d can't tail files; need version 32 or later", st.Version)
+	}
+	offset, lines := opts.Offset, opts.Lines
+	for {
+		truncated, err := c.tail(ctx, path, w, offset, lines, opts.Follow)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if !truncated {
+			return nil
+		}
+		condRun(opts.OnTruncate)
+		offset, lines = 0, 0
+	}
+}
+
+// tail makes one /tail request, reporting whether it ended because the
+// file was truncated.
+func (c *client) tail(ctx context.Context, path string, w io.Writer, offset int64, lines int, follow bool) (truncated bool, err error) {
+	param := url.Values{
+		"path":   {path},
+		"offset": {fmt.Sprint(offset)},
+		"lines":  {fmt.Sprint(lines)},
+		"follow": {fmt.Sprint(follow)},
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL()+"/tail?"+param.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		slurp, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return false, fmt.Errorf("%v; body: %s", res.Status, slurp)
+	}
+	if _, err := io.Copy(w, res.Body); err != nil {
+		return false, err
+	}
+	switch state := res.Trailer.Get("Tail-State"); state {
+	case "eof":
+		return false, nil
+	case "truncated":
+		return true, nil
+	default:
+		return false, fmt.Errorf("buildlet: unexpected Tail-State trailer %q in /tail response", state)
+	}
 }
 
 // Status provides status information about the buildlet.
@@ -650,27 +1628,148 @@ func (c *client) RemoveAll(ctx context.Context, paths ...string) error {
 // to do with a buildlet.
 type Status struct {
 	Version int // buildlet version, coordinator rejects value that is too old (see minBuildletVersion).
+
+	// Encodings are the compressions of tarballs the buildlet supports
+	// besides gzip, such as EncodingZstd. It is empty for buildlets older
+	// than version 34.
+	Encodings []string
+
+	// Time is the buildlet's wall-clock time when it answered. It is
+	// zero for buildlets older than version 33.
+	Time time.Time
+
+	// The following fields describe the machine the buildlet runs on.
+	// They are zero if unknown, such as for buildlets older than
+	// version 35 or on systems where the buildlet can't measure them.
+	DiskFree     int64     // bytes available on the work directory's volume
+	DiskTotal    int64     // size in bytes of the work directory's volume
+	MemTotal     int64     // bytes of physical memory
+	MemAvailable int64     // bytes of memory available to new processes
+	LoadAvg      []float64 // 1, 5 and 15 minute load averages
+
+	// WorkDirSize is the total size in bytes of the files in the work
+	// directory. Walking the work directory is too slow to do for every
+	// status request, so it's only reported by DetailedStatus.
+	WorkDirSize int64
 }
 
 // Status returns an Status value describing this buildlet.
 func (c *client) Status(ctx context.Context) (Status, error) {
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return Status{}, err
+	}
+	defer release()
+	var st Status
+	err = c.retry(ctx, nil, func() (err error) {
+		st, err = c.status(ctx, false)
+		return err
+	})
+	return st, err
+}
+
+// DetailedStatus is like Status, but also reports the size of the
+// buildlet's work directory, which is expensive to compute.
+func (c *client) DetailedStatus(ctx context.Context) (Status, error) {
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return Status{}, err
+	}
+	defer release()
+	var st Status
+	err = c.retry(ctx, nil, func() (err error) {
+		st, err = c.status(ctx, true)
+		return err
+	})
+	return st, err
+}
+
+// PingResult is the result of Client.Ping.
+type PingResult struct {
+	Status Status        // the buildlet's status
+	RTT    time.Duration // round-trip time of the request
+
+	// Skew is the buildlet's clock minus the client's, assuming the
+	// buildlet answered halfway through the round trip. It is zero if
+	// the buildlet doesn't report its time; see Status.Time.
+	Skew time.Duration
+}
+
+// Ping makes a minimal round trip to the buildlet, measuring its
+// latency and the skew of the buildlet's clock.
+func (c *client) Ping(ctx context.Context) (PingResult, error) {
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return PingResult{}, err
+	}
+	defer release()
+	pr, err := c.ping(ctx)
+	if err != nil && c.reconnectAfter(ctx, err) {
+		pr, err = c.ping(ctx)
+	}
+	return pr, err
+}
+
+func (c *client) ping(ctx context.Context) (PingResult, error) {
+	t0 := time.Now()
+	st, err := c.status(ctx, false)
+	if err != nil {
+		return PingResult{}, err
+	}
+	pr := PingResult{Status: st, RTT: time.Since(t0)}
+	if !st.Time.IsZero() {
+		pr.Skew = st.Time.Sub(t0.Add(pr.RTT / 2))
+	}
+	return pr, nil
+}
+
+// heartbeatWindow is the number of recent heartbeats whose round-trip
+// times are kept.
+const heartbeatWindow = 10
+
+// HeartbeatLatencies returns the round-trip times of the most recent
+// successful heartbeats, up to 10 of them, oldest first. Heartbeats are
+// sent every 10 seconds once the client is first used.
+func (c *client) HeartbeatLatencies() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := min(c.heartbeats, heartbeatWindow)
+	ret := make([]time.Duration, n)
+	for i := range ret {
+		ret[i] = c.heartbeatRTTs[(c.heartbeats-n+i)%heartbeatWindow]
+	}
+	return ret
+}
+
+func (c *client) recordHeartbeat(rtt time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.heartbeatRTTs[c.heartbeats%heartbeatWindow] = rtt
+	c.heartbeats++
+}
+
+func (c *client) status(ctx context.Context, detailed bool) (Status, error) {
 	select {
 	case <-c.peerDead:
 		return Status{}, c.deadErr
 	default:
 		// Continue below.
 	}
-	req, err := http.NewRequest("GET", c.URL()+"/status", nil)
+	u := c.URL() + "/status"
+	if detailed {
+		u += "?detailed=1"
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return Status{}, err
 	}
-	req = req.WithContext(ctx)
 	resp, err := c.doHeaderTimeout(req, 20*time.Second) // plenty of time
 	if err != nil {
 		return Status{}, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return Status{}, errors.New(resp.Status)
+		resp.Body.Close()
+		return Status{}, &statusError{resp.StatusCode, resp.Status}
 	}
 	b, err := io.ReadAll(resp.Body)
 	resp.Body.Close()
@@ -686,17 +1785,31 @@ func (c *client) Status(ctx context.Context) (Status, error) {
 
 // WorkDir returns the absolute path to the buildlet work directory.
 func (c *client) WorkDir(ctx context.Context) (string, error) {
-	req, err := http.NewRequest("GET", c.URL()+"/workdir", nil)
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	var dir string
+	err = c.retry(ctx, nil, func() (err error) {
+		dir, err = c.workDir(ctx)
+		return err
+	})
+	return dir, err
+}
+
+func (c *client) workDir(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL()+"/workdir", nil)
 	if err != nil {
 		return "", err
 	}
-	req = req.WithContext(ctx)
 	resp, err := c.doHeaderTimeout(req, 20*time.Second) // plenty of time
 	if err != nil {
 		return "", err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(resp.Status)
+		resp.Body.Close()
+		return "", &statusError{resp.StatusCode, resp.Status}
 	}
 	b, err := io.ReadAll(resp.Body)
 	resp.Body.Close()
@@ -769,44 +1882,111 @@ type ListDirOpts struct {
 	// Digest controls whether the SHA-1 digests of regular files
 	// are returned.
 	Digest bool
+
+	// Patterns, if non-empty, limits the listed entries to those
+	// matching any of the patterns, using the syntax of path.Match.
+	// A pattern without a slash is matched against the last element
+	// of an entry's name; otherwise it is matched against the whole
+	// name relative to the directory passed to ListDir. Directories
+	// are still descended into when they don't match.
+	Patterns []string
+
+	// MaxDepth, if positive, limits how deep a Recursive listing goes.
+	// A MaxDepth of 1 lists only the entries directly in the directory.
+	MaxDepth int
+}
+
+// Includes reports whether the entry name, relative to the listed
+// directory and with a trailing slash for directories as returned by
+// DirEntry.Name, is included by o's Patterns and MaxDepth.
+func (o ListDirOpts) Includes(name string) bool {
+	name = strings.TrimSuffix(name, "/")
+	if o.MaxDepth > 0 && strings.Count(name, "/")+1 > o.MaxDepth {
+		return false
+	}
+	if len(o.Patterns) == 0 {
+		return true
+	}
+	for _, pat := range o.Patterns {
+		target := name
+		if !strings.Contains(pat, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(pat, target); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // ListDir lists the contents of a directory.
 // The fn callback is run for each entry.
 // The directory dir itself is not included.
+//
+// Buildlets older than version 29 don't support opts.Patterns and
+// opts.MaxDepth; the entries they list are filtered by the client.
 func (c *client) ListDir(ctx context.Context, dir string, opts ListDirOpts, fn func(DirEntry)) error {
+	for _, pat := range opts.Patterns {
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pat, err)
+		}
+	}
+	ctx, cancel := c.callContext(ctx, "ListDir", 0)
+	defer cancel()
+	ctx, release, err := c.acquire(ctx, c.light)
+	if err != nil {
+		return timeoutError(ctx, err)
+	}
+	defer release()
+	called := false
+	// Don't retry once entries were passed to fn, which would see them twice.
+	err = c.retry(ctx, func() bool { return !called }, func() error {
+		return c.listDir(ctx, dir, opts, func(de DirEntry) {
+			called = true
+			fn(de)
+		})
+	})
+	return timeoutError(ctx, err)
+}
+
+func (c *client) listDir(ctx context.Context, dir string, opts ListDirOpts, fn func(DirEntry)) error {
 	param := url.Values{
 		"dir":       {dir},
 		"recursive": {fmt.Sprint(opts.Recursive)},
 		"skip":      opts.Skip,
 		"digest":    {fmt.Sprint(opts.Digest)},
+		"pattern":   opts.Patterns,
+	}
+	if opts.MaxDepth > 0 {
+		param.Set("maxdepth", fmt.Sprint(opts.MaxDepth))
 	}
-	req, err := http.NewRequest("GET", c.URL()+"/ls?"+param.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL()+"/ls?"+param.Encode(), nil)
 	if err != nil {
 		return err
 	}
-	resp, err := c.do(req.WithContext(ctx))
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		slurp, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
-		return fmt.Errorf("%s: %s", resp.Status, slurp)
+		return &statusError{resp.StatusCode, fmt.Sprintf("%s: %s", resp.Status, slurp)}
 	}
 	sc := bufio.NewScanner(resp.Body)
 	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
-		fn(DirEntry{Line: line})
+		de := DirEntry{Line: strings.TrimSpace(sc.Text())}
+		// Older buildlets ignore Patterns and MaxDepth.
+		if opts.Includes(de.Name()) {
+			fn(de)
+		}
 	}
 	return sc.Err()
 }
 
 func (c *client) getDialer() func(context.Context) (net.Conn, error) {
 	if !c.tls.IsZero() {
-		return func(_ context.Context) (net.Conn, error) {
-			return c.tls.tlsDialer()("tcp", c.ipPort)
-		}
+		return c.dialTLS
 	}
 	if c.dialer != nil {
 		return c.dialer
@@ -815,15 +1995,43 @@ func (c *client) getDialer() func(context.Context) (net.Conn, error) {
 }
 
 func (c *client) dialWithNetDial(ctx context.Context) (net.Conn, error) {
+	if c.netDial != nil {
+		return c.netDial(ctx, "tcp", c.ipPort)
+	}
 	var d net.Dialer
 	return d.DialContext(ctx, "tcp", c.ipPort)
 }
 
+// dialTLS dials the buildlet and checks that it presents the
+// certificate of c's KeyPair.
+func (c *client) dialTLS(ctx context.Context) (net.Conn, error) {
+	conn, err := c.dialWithNetDial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tc := tls.Client(conn, c.tls.tlsConfig(c.tlsConfig))
+	if err := tc.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
 // ConnectSSH opens an SSH connection to the buildlet for the given username.
+//
+// Deprecated: Use ConnectSSHContext.
+func (c *client) ConnectSSH(user, authorizedPubKey string) (net.Conn, error) {
+	return c.ConnectSSHContext(context.Background(), user, authorizedPubKey)
+}
+
+// ConnectSSHContext opens an SSH connection to the buildlet for the given username.
 // The authorizedPubKey must be a line from an ~/.ssh/authorized_keys file
 // and correspond to the private key to be used to communicate over the net.Conn.
-func (c *client) ConnectSSH(user, authorizedPubKey string) (net.Conn, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+//
+// The context bounds establishing the connection, which is also limited
+// to 15 seconds. It has no effect on the returned connection.
+func (c *client) ConnectSSHContext(ctx context.Context, user, authorizedPubKey string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 	conn, err := c.getDialer()(ctx)
 	if err != nil {
@@ -831,6 +2039,9 @@ func (c *client) ConnectSSH(user, authorizedPubKey string) (net.Conn, error) {
 	}
 	deadline, _ := ctx.Deadline()
 	conn.SetDeadline(deadline)
+	// Abort the handshake promptly if ctx is canceled.
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
 	req, err := http.NewRequest("POST", "/connect-ssh", nil)
 	if err != nil {
 		conn.Close()
@@ -843,12 +2054,18 @@ func (c *client) ConnectSSH(user, authorizedPubKey string) (net.Conn, error) {
 	}
 	if err := req.Write(conn); err != nil {
 		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, fmt.Errorf("writing /connect-ssh HTTP request failed: %v", err)
 	}
 	bufr := bufio.NewReader(conn)
 	res, err := http.ReadResponse(bufr, req)
 	if err != nil {
 		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, fmt.Errorf("reading /connect-ssh response: %v", err)
 	}
 	if res.StatusCode != http.StatusSwitchingProtocols {
@@ -856,6 +2073,11 @@ func (c *client) ConnectSSH(user, authorizedPubKey string) (net.Conn, error) {
 		conn.Close()
 		return nil, fmt.Errorf("unexpected /connect-ssh response: %v, %s", res.Status, slurp)
 	}
+	if !stop() {
+		// ctx was canceled concurrently; the deadline may have been set.
+		conn.Close()
+		return nil, ctx.Err()
+	}
 	conn.SetDeadline(time.Time{})
 	return conn, nil
 }
@@ -866,6 +2088,79 @@ func condRun(fn func()) {
 	}
 }
 
+// execOutput is the destination of the output of Exec.
+type execOutput struct {
+	w        io.Writer                      // or nil
+	stderr   io.Writer                      // or nil to write stderr to w
+	fn       func([]byte) error             // or nil
+	streamFn func(ExecStream, []byte) error // or nil
+	fnErr    error                          // the error returned by fn or streamFn, if any
+}
+
+func (o *execOutput) Write(p []byte) (int, error) {
+	if err := o.writeStream(ExecOutput, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeStream writes p, a chunk of stream s, to o.
+func (o *execOutput) writeStream(s ExecStream, p []byte) error {
+	w := o.w
+	if s == ExecStderr && o.stderr != nil {
+		w = o.stderr
+	}
+	if w != nil {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	if o.fn != nil {
+		if err := o.fn(p); err != nil {
+			o.fnErr = err
+			return err
+		}
+	}
+	if o.streamFn != nil {
+		if err := o.streamFn(s, p); err != nil {
+			o.fnErr = err
+			return err
+		}
+	}
+	return nil
+}
+
+// contextBody is a request body which is interrupted when its context is
+// done. The transport waits for the request body to be done with before
+// returning from an aborted request, so a blocking Read of a caller's
+// io.Reader would otherwise delay the abort indefinitely.
+type contextBody struct {
+	body io.ReadCloser
+	pr   *io.PipeReader
+	stop func() bool
+}
+
+func newContextBody(ctx context.Context, body io.ReadCloser) *contextBody {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := io.Copy(pw, body)
+		pw.CloseWithError(err)
+	}()
+	return &contextBody{
+		body: body,
+		pr:   pr,
+		stop: context.AfterFunc(ctx, func() { pr.CloseWithError(context.Cause(ctx)) }),
+	}
+}
+
+func (b *contextBody) Read(p []byte) (int, error) { return b.pr.Read(p) }
+
+func (b *contextBody) Close() error {
+	b.stop()
+	b.pr.Close()
+	return b.body.Close()
+}
+
 type onEOFReadCloser struct {
 	rc io.ReadCloser
 	fn func()
@@ -883,3 +2178,66 @@ func (o onEOFReadCloser) Close() error {
 	o.fn()
 	return o.rc.Close()
 }
+
+// recompressTar returns a reader of the tarball r, compressed with to
+// instead of from. Closing it stops reading r.
+func recompressTar(r io.Reader, from, to string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(copyRecompressed(pw, r, from, to))
+	}()
+	rc := &recompressReader{pr: pr}
+	if c, ok := r.(io.Closer); ok {
+		rc.src = c
+	}
+	return rc
+}
+
+func copyRecompressed(w io.Writer, r io.Reader, from, to string) error {
+	var zr io.Reader
+	if from == EncodingZstd {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		zr = dec
+	} else {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		zr = gr
+	}
+	var zw io.WriteCloser
+	if to == EncodingZstd {
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return err
+		}
+		zw = enc
+	} else {
+		zw = gzip.NewWriter(w)
+	}
+	if _, err := io.Copy(zw, zr); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// recompressReader reads the output of recompressTar.
+type recompressReader struct {
+	pr  *io.PipeReader
+	src io.Closer // or nil
+}
+
+func (r *recompressReader) Read(p []byte) (int, error) { return r.pr.Read(p) }
+
+func (r *recompressReader) Close() error {
+	r.pr.Close()
+	if r.src != nil {
+		return r.src.Close()
+	}
+	return nil
+}
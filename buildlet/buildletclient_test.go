@@ -5,16 +5,25 @@
 package buildlet
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestConnectSSHTLS(t *testing.T) {
@@ -176,6 +185,68 @@ func createKeyPair(t *testing.T) KeyPair {
 
 // Test that Exec returns ErrTimeout upon reaching the context timeout
 // during command execution, as its documentation promises.
+func TestNewClientOpts(t *testing.T) {
+	kp := createKeyPair(t)
+	cert, err := tls.X509KeyPair([]byte(kp.CertPEM), []byte(kp.KeyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(Status{Version: 35})
+	}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	ts.StartTLS()
+	defer ts.Close()
+	ipPort := strings.TrimPrefix(ts.URL, "https://")
+
+	var dials atomic.Int32
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+	cl := NewClientOpts(ipPort, kp, ClientOpts{DialContext: dial})
+	defer cl.Close()
+	if _, err := cl.Status(context.Background()); err != nil {
+		t.Fatalf("Status with the buildlet's key pair: %v", err)
+	}
+	if dials.Load() == 0 {
+		t.Errorf("Status didn't use the DialContext option")
+	}
+
+	// A buildlet presenting another certificate must be rejected, even
+	// though the base TLS configuration skips verification.
+	other := NewClientOpts(ipPort, createKeyPair(t), ClientOpts{
+		DialContext: dial,
+		TLSConfig:   &tls.Config{InsecureSkipVerify: true},
+	})
+	defer other.Close()
+	if _, err := other.Status(context.Background()); err == nil {
+		t.Errorf("Status with another key pair succeeded; want an error")
+	}
+
+	// A Transport replaces the client's own.
+	var sent atomic.Int32
+	tr := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer tr.CloseIdleConnections()
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent.Add(1)
+		return tr.RoundTrip(req)
+	})
+	cl = NewClientOpts(ipPort, kp, ClientOpts{Transport: rt})
+	defer cl.Close()
+	if _, err := cl.Status(context.Background()); err != nil {
+		t.Fatalf("Status with a Transport: %v", err)
+	}
+	if sent.Load() == 0 {
+		t.Errorf("Status didn't use the Transport option")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
 func TestExecTimeoutError(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
@@ -211,6 +282,145 @@ func TestExecTimeoutError(t *testing.T) {
 	}
 }
 
+func TestExecOnOutput(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/exec", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Trailer", "Process-State")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+			w.(http.Flusher).Flush()
+		}
+		w.Header().Set("Process-State", "ok")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	const want = "line 0\nline 1\nline 2\n"
+	var out, chunks strings.Builder
+	remoteErr, execErr := cl.Exec(context.Background(), "./bin/test", ExecOpts{
+		Output: &out,
+		OnOutput: func(p []byte) error {
+			if out.Len() < chunks.Len()+len(p) {
+				t.Errorf("OnOutput called before the chunk was written to Output")
+			}
+			chunks.Write(p)
+			return nil
+		},
+	})
+	if remoteErr != nil || execErr != nil {
+		t.Fatalf("cl.Exec = %v, %v; want no errors", remoteErr, execErr)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("Output = %q; want %q", got, want)
+	}
+	if got := chunks.String(); got != want {
+		t.Errorf("OnOutput chunks = %q; want %q", got, want)
+	}
+
+	errStop := errors.New("stop")
+	_, execErr = cl.Exec(context.Background(), "./bin/test", ExecOpts{
+		OnOutput: func(p []byte) error { return errStop },
+	})
+	if execErr != errStop {
+		t.Errorf("cl.Exec error = %v; want %v", execErr, errStop)
+	}
+	if cl.IsBroken() {
+		t.Errorf("client marked broken after OnOutput error")
+	}
+}
+
+func TestExecStreams(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/exec", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Trailer", "Process-State, Process-Exit-Code")
+		out := io.Writer(w)
+		stdout, stderr, system := out, out, out
+		if req.FormValue("streams") == "true" {
+			w.Header().Set(ExecStreamsHeader, "1")
+			sw := NewExecStreamWriter(w)
+			stdout, stderr, system = sw.Stream(ExecStdout), sw.Stream(ExecStderr), sw.Stream(ExecSystem)
+		}
+		fmt.Fprint(system, ":: debug\n")
+		fmt.Fprint(stdout, "out 1\n")
+		fmt.Fprint(stderr, "err 1\n")
+		fmt.Fprint(stdout, "out 2\n")
+		w.Header().Set("Process-State", "exit status 3")
+		w.Header().Set("Process-Exit-Code", "3")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	var out, stderr, chunks, streams strings.Builder
+	remoteErr, execErr := cl.Exec(context.Background(), "./bin/test", ExecOpts{
+		Output:   &out,
+		Stderr:   &stderr,
+		OnOutput: func(p []byte) error { chunks.Write(p); return nil },
+		OnStream: func(s ExecStream, p []byte) error {
+			fmt.Fprintf(&streams, "%v:%s", s, p)
+			return nil
+		},
+	})
+	if execErr != nil {
+		t.Fatalf("cl.Exec error = %v; want nil", execErr)
+	}
+	if code := ExitCode(remoteErr); remoteErr == nil || remoteErr.Error() != "exit status 3" || code != 3 {
+		t.Errorf("cl.Exec remote error = %v with exit code %d; want exit status 3", remoteErr, code)
+	}
+	if got, want := out.String(), ":: debug\nout 1\nout 2\n"; got != want {
+		t.Errorf("Output = %q; want %q", got, want)
+	}
+	if got, want := stderr.String(), "err 1\n"; got != want {
+		t.Errorf("Stderr = %q; want %q", got, want)
+	}
+	if got, want := chunks.String(), ":: debug\nout 1\nerr 1\nout 2\n"; got != want {
+		t.Errorf("OnOutput chunks = %q; want %q", got, want)
+	}
+	if got, want := streams.String(), "system::: debug\nstdout:out 1\nstderr:err 1\nstdout:out 2\n"; got != want {
+		t.Errorf("OnStream chunks = %q; want %q", got, want)
+	}
+
+	// Without Stderr or OnStream, the output is combined.
+	out.Reset()
+	if _, execErr := cl.Exec(context.Background(), "./bin/test", ExecOpts{Output: &out}); execErr != nil {
+		t.Fatalf("cl.Exec error = %v; want nil", execErr)
+	}
+	if got, want := out.String(), ":: debug\nout 1\nerr 1\nout 2\n"; got != want {
+		t.Errorf("combined Output = %q; want %q", got, want)
+	}
+}
+
+func TestExecError(t *testing.T) {
+	for _, tc := range []struct {
+		state, exitCode string
+		want            int
+	}{
+		{"exit status 3", "3", 3},
+		{"exit status 3", "", 3}, // from a buildlet which predates Process-Exit-Code
+		{"signal: killed", "-1", -1},
+		{"signal: killed", "", -1},
+		{"fork/exec: no such file", "", -1},
+	} {
+		if got := execError(tc.state, tc.exitCode).ExitCode; got != tc.want {
+			t.Errorf("execError(%q, %q).ExitCode = %d; want %d", tc.state, tc.exitCode, got, tc.want)
+		}
+	}
+	if got := ExitCode(errors.New("exit status 1")); got != -1 {
+		t.Errorf("ExitCode of a plain error = %d; want -1", got)
+	}
+}
+
 type deadlineOnDemandContext struct {
 	context.Context
 	done chan struct{}
@@ -225,3 +435,936 @@ func (c deadlineOnDemandContext) Err() error {
 		return context.DeadlineExceeded
 	}
 }
+
+// newHangingBuildlet returns a client for a fake buildlet whose handlers
+// for paths start responding, or reading the request body of PUT requests,
+// then hang until their request is canceled. The returned channel receives
+// a value once a handler hangs.
+func newHangingBuildlet(t *testing.T, paths ...string) (Client, <-chan struct{}) {
+	t.Helper()
+	hit := make(chan struct{}, 1)
+	mux := http.NewServeMux()
+	for _, path := range paths {
+		mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == "PUT" {
+				// Wait for the first bytes of the body to make sure the
+				// client is busy sending it, then for the rest of it,
+				// which never comes.
+				req.Body.Read(make([]byte, 1))
+				hit <- struct{}{}
+				io.Copy(io.Discard, req.Body)
+				return
+			}
+			w.Write([]byte("."))
+			w.(http.Flusher).Flush()
+			hit <- struct{}{}
+			<-req.Context().Done()
+		})
+	}
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	t.Cleanup(func() { cl.Close() })
+	return cl, hit
+}
+
+// blockingReader returns one byte, then blocks until ctx is done.
+type blockingReader struct {
+	ctx  context.Context
+	sent bool
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	if !r.sent {
+		r.sent = true
+		p[0] = 'x'
+		return 1, nil
+	}
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func TestContextCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		call func(ctx context.Context, cl Client) error
+	}{
+		{
+			name: "Exec",
+			path: "/exec",
+			call: func(ctx context.Context, cl Client) error {
+				_, err := cl.Exec(ctx, "./bin/test", ExecOpts{})
+				return err
+			},
+		},
+		{
+			name: "GetTar",
+			path: "/tgz",
+			call: func(ctx context.Context, cl Client) error {
+				rc, err := cl.GetTar(ctx, "dir")
+				if err != nil {
+					return err
+				}
+				defer rc.Close()
+				_, err = io.Copy(io.Discard, rc)
+				return err
+			},
+		},
+		{
+			name: "PutTar",
+			path: "/writetgz",
+			call: func(ctx context.Context, cl Client) error {
+				bctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				return cl.PutTar(ctx, &blockingReader{ctx: bctx}, "dir")
+			},
+		},
+		{
+			name: "ListDir",
+			path: "/ls",
+			call: func(ctx context.Context, cl Client) error {
+				return cl.ListDir(ctx, "dir", ListDirOpts{}, func(DirEntry) {})
+			},
+		},
+		{
+			name: "Status",
+			path: "/status",
+			call: func(ctx context.Context, cl Client) error {
+				_, err := cl.Status(ctx)
+				return err
+			},
+		},
+		{
+			name: "WorkDir",
+			path: "/workdir",
+			call: func(ctx context.Context, cl Client) error {
+				_, err := cl.WorkDir(ctx)
+				return err
+			},
+		},
+		{
+			name: "RemoveAll",
+			path: "/removeall",
+			call: func(ctx context.Context, cl Client) error {
+				return cl.RemoveAll(ctx, "dir")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl, hit := newHangingBuildlet(t, tt.path)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			errc := make(chan error, 1)
+			go func() { errc <- tt.call(ctx, cl) }()
+			select {
+			case <-hit:
+			case err := <-errc:
+				t.Fatalf("%s returned before the buildlet was reached: %v", tt.name, err)
+			}
+			cancel()
+			select {
+			case err := <-errc:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("%s error = %v; want %v", tt.name, err, context.Canceled)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatalf("%s did not return after its context was canceled", tt.name)
+			}
+			if tt.name == "Exec" && cl.IsBroken() {
+				t.Errorf("client marked broken after canceled Exec")
+			}
+		})
+	}
+}
+
+func TestCloseAbortsRequests(t *testing.T) {
+	cl, hit := newHangingBuildlet(t, "/tgz")
+	errc := make(chan error, 1)
+	go func() {
+		rc, err := cl.GetTar(context.Background(), "dir")
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer rc.Close()
+		_, err = io.Copy(io.Discard, rc)
+		errc <- err
+	}()
+	<-hit
+	cl.Close()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Errorf("GetTar read succeeded after Close")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("GetTar did not return after Close")
+	}
+}
+
+func TestConnectSSHContextCancel(t *testing.T) {
+	hit := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/connect-ssh", func(w http.ResponseWriter, req *http.Request) {
+		close(hit)
+		<-req.Context().Done()
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		conn, err := cl.ConnectSSHContext(ctx, "kate", "key-foo")
+		if err == nil {
+			conn.Close()
+		}
+		errc <- err
+	}()
+	<-hit
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ConnectSSHContext error = %v; want %v", err, context.Canceled)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("ConnectSSHContext did not return after its context was canceled")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	var partitioned atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/workdir", func(w http.ResponseWriter, req *http.Request) {
+		if partitioned.Load() {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		io.WriteString(w, "/workdir")
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		if partitioned.Load() {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		json.NewEncoder(w).Encode(Status{})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+
+	newClient := func(window time.Duration) (Client, <-chan ReconnectEvent) {
+		events := make(chan ReconnectEvent, 10)
+		cl := NewClient(u.Host, NoKeyPair)
+		cl.SetReconnect(ReconnectOpts{
+			Window:     window,
+			MinBackoff: 10 * time.Millisecond,
+			OnEvent:    func(ev ReconnectEvent) { events <- ev },
+		})
+		t.Cleanup(func() { cl.Close() })
+		return cl, events
+	}
+
+	t.Run("reconnected", func(t *testing.T) {
+		cl, events := newClient(time.Minute)
+		partitioned.Store(true)
+		defer partitioned.Store(false)
+		type result struct {
+			dir string
+			err error
+		}
+		resc := make(chan result, 1)
+		go func() {
+			dir, err := cl.WorkDir(context.Background())
+			resc <- result{dir, err}
+		}()
+		if ev := <-events; ev.State != Reconnecting {
+			t.Fatalf("first event state = %v; want %v", ev.State, Reconnecting)
+		}
+		partitioned.Store(false)
+		if ev := <-events; ev.State != Reconnected {
+			t.Errorf("second event state = %v; want %v", ev.State, Reconnected)
+		}
+		if res := <-resc; res.err != nil || res.dir != "/workdir" {
+			t.Errorf("WorkDir = %q, %v; want %q, no error", res.dir, res.err, "/workdir")
+		}
+		if cl.IsBroken() {
+			t.Errorf("client marked broken after reconnecting")
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		cl, events := newClient(100 * time.Millisecond)
+		partitioned.Store(true)
+		defer partitioned.Store(false)
+		if _, err := cl.WorkDir(context.Background()); err == nil {
+			t.Errorf("WorkDir succeeded while partitioned")
+		}
+		if ev := <-events; ev.State != Reconnecting {
+			t.Errorf("first event state = %v; want %v", ev.State, Reconnecting)
+		}
+		if ev := <-events; ev.State != ReconnectFailed || ev.Attempts == 0 {
+			t.Errorf("second event = %v after %d attempts; want %v after some", ev.State, ev.Attempts, ReconnectFailed)
+		}
+		if !cl.IsBroken() {
+			t.Errorf("client not marked broken after failing to reconnect")
+		}
+	})
+}
+
+func TestListDirOptsIncludes(t *testing.T) {
+	tests := []struct {
+		opts ListDirOpts
+		name string
+		want bool
+	}{
+		{ListDirOpts{}, "src/net/http/", true},
+		{ListDirOpts{Patterns: []string{"*.log"}}, "out/test.log", true},
+		{ListDirOpts{Patterns: []string{"*.log"}}, "out/test.go", false},
+		{ListDirOpts{Patterns: []string{"*.go", "*.log"}}, "test.log", true},
+		{ListDirOpts{Patterns: []string{"out/*.log"}}, "out/test.log", true},
+		{ListDirOpts{Patterns: []string{"out/*.log"}}, "out/sub/test.log", false},
+		{ListDirOpts{Patterns: []string{"sub"}}, "out/sub/", true},
+		{ListDirOpts{Patterns: []string{"[bad"}}, "bad", false},
+		{ListDirOpts{MaxDepth: 1}, "out/", true},
+		{ListDirOpts{MaxDepth: 1}, "out/test.log", false},
+		{ListDirOpts{MaxDepth: 2, Patterns: []string{"*.log"}}, "out/test.log", true},
+		{ListDirOpts{MaxDepth: 2, Patterns: []string{"*.log"}}, "out/sub/test.log", false},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.Includes(tt.name); got != tt.want {
+			t.Errorf("%+v.Includes(%q) = %v; want %v", tt.opts, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListDirFiltersOldBuildlet(t *testing.T) {
+	// Older buildlets ignore the pattern and maxdepth parameters.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ls", func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "drwxr-xr-x\tout/\n")
+		io.WriteString(w, "-rw-r--r--\tout/a.log\t1\t2024-01-01T00:00:00Z\n")
+		io.WriteString(w, "-rw-r--r--\tout/a.go\t1\t2024-01-01T00:00:00Z\n")
+		io.WriteString(w, "drwxr-xr-x\tout/sub/\n")
+		io.WriteString(w, "-rw-r--r--\tout/sub/b.log\t1\t2024-01-01T00:00:00Z\n")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	var got []string
+	opts := ListDirOpts{Recursive: true, Patterns: []string{"*.log"}, MaxDepth: 2}
+	if err := cl.ListDir(context.Background(), ".", opts, func(de DirEntry) {
+		got = append(got, de.Name())
+	}); err != nil {
+		t.Fatalf("ListDir: %v", err)
+	}
+	if want := []string{"out/a.log"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListDir entries = %q; want %q", got, want)
+	}
+
+	opts = ListDirOpts{Patterns: []string{"[bad"}}
+	if err := cl.ListDir(context.Background(), ".", opts, func(DirEntry) {}); err == nil {
+		t.Errorf("ListDir with an invalid pattern succeeded")
+	}
+}
+
+func TestPutTarFromURLSHA256(t *testing.T) {
+	const digest = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	var version atomic.Int32
+	version.Store(30)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(Status{Version: int(version.Load())})
+	})
+	mux.HandleFunc("/writetgz", func(w http.ResponseWriter, req *http.Request) {
+		if want := req.FormValue("sha256"); want != "" && want != digest {
+			http.Error(w, "SHA-256 mismatch", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Go-Tgz-Sha256", digest)
+		io.WriteString(w, "OK")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+	ctx := context.Background()
+
+	for _, want := range []string{"", digest} {
+		if got, err := cl.PutTarFromURLSHA256(ctx, "https://example.com/x.tgz", "dir", want); err != nil || got != digest {
+			t.Errorf("PutTarFromURLSHA256(%q) = %q, %v; want %q, no error", want, got, err, digest)
+		}
+	}
+	if _, err := cl.PutTarFromURLSHA256(ctx, "https://example.com/x.tgz", "dir", strings.Repeat("0", 64)); err == nil {
+		t.Errorf("PutTarFromURLSHA256 with the wrong digest succeeded")
+	}
+	version.Store(29)
+	if _, err := cl.PutTarFromURLSHA256(ctx, "https://example.com/x.tgz", "dir", digest); err == nil {
+		t.Errorf("PutTarFromURLSHA256 with a digest succeeded on an old buildlet")
+	}
+}
+
+func TestObserverMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/writetgz", func(w http.ResponseWriter, req *http.Request) {
+		io.Copy(io.Discard, req.Body)
+		if req.FormValue("dir") == "bad" {
+			http.Error(w, "bad dir", http.StatusBadRequest)
+		}
+	})
+	mux.HandleFunc("/exec", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Trailer", "Process-State")
+		io.WriteString(w, "some output\n")
+		w.Header().Set("Process-State", "ok")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	m := NewMetrics()
+	cl.SetObserver(m)
+	defer cl.Close()
+
+	ctx := context.Background()
+	const tgz = "not really a tarball"
+	if err := cl.PutTar(ctx, strings.NewReader(tgz), "dir"); err != nil {
+		t.Fatalf("PutTar: %v", err)
+	}
+	if err := cl.PutTar(ctx, strings.NewReader(tgz), "bad"); err == nil {
+		t.Fatalf("PutTar(bad dir) succeeded; want error")
+	}
+	if remoteErr, execErr := cl.Exec(ctx, "./bin/test", ExecOpts{}); remoteErr != nil || execErr != nil {
+		t.Fatalf("Exec = %v, %v; want no errors", remoteErr, execErr)
+	}
+
+	for _, tc := range []struct {
+		method, stat, want string
+	}{
+		{"/writetgz", "requests", "2"},
+		{"/writetgz", "in_flight", "0"},
+		{"/writetgz", "errors", "1"},
+		{"/writetgz", "bytes_sent", fmt.Sprint(2 * len(tgz))},
+		{"/writetgz", "encoding_gzip", "2"},
+		{"/exec", "requests", "1"},
+		{"/exec", "errors", "0"},
+		{"/exec", "bytes_received", fmt.Sprint(len("some output\n"))},
+	} {
+		v := m.Get(tc.method, tc.stat)
+		if v == nil || v.String() != tc.want {
+			t.Errorf("%s %s = %v; want %s", tc.method, tc.stat, v, tc.want)
+		}
+	}
+	if v := m.Get("/exec", "seconds"); v == nil || v.String() == "0" {
+		t.Errorf("/exec seconds = %v; want nonzero", v)
+	}
+}
+
+func TestCallTimeouts(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	checkTimeout := func(t *testing.T, cl Client, err error, op string) {
+		t.Helper()
+		var te *TimeoutError
+		if !errors.As(err, &te) || te.Op != op {
+			t.Fatalf("%s error = %v; want *TimeoutError for %s", op, err, op)
+		}
+		if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("errors.Is(%v, ErrTimeout or context.DeadlineExceeded) = false; want true", err)
+		}
+		if cl.IsBroken() {
+			t.Errorf("client marked broken after %s timed out", op)
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("Exec", func(t *testing.T) {
+		cl, _ := newHangingBuildlet(t, "/exec")
+		_, execErr := cl.Exec(ctx, "./bin/test", ExecOpts{Timeout: timeout})
+		checkTimeout(t, cl, execErr, "Exec")
+	})
+	t.Run("PutTar", func(t *testing.T) {
+		cl, _ := newHangingBuildlet(t, "/writetgz")
+		err := cl.PutTar(ctx, &blockingReader{ctx: ctx}, "dir", TarOpts{Timeout: timeout})
+		checkTimeout(t, cl, err, "PutTar")
+	})
+	t.Run("GetTar default", func(t *testing.T) {
+		cl, _ := newHangingBuildlet(t, "/tgz")
+		cl.SetCallTimeout(timeout)
+		rc, err := cl.GetTar(ctx, "dir")
+		if err != nil {
+			t.Fatalf("GetTar: %v", err)
+		}
+		defer rc.Close()
+		_, err = io.ReadAll(rc)
+		checkTimeout(t, cl, err, "GetTar")
+	})
+	t.Run("explicit overrides default", func(t *testing.T) {
+		cl, _ := newHangingBuildlet(t, "/exec")
+		cl.SetCallTimeout(time.Hour)
+		start := time.Now()
+		_, execErr := cl.Exec(ctx, "./bin/test", ExecOpts{Timeout: timeout})
+		checkTimeout(t, cl, execErr, "Exec")
+		if d := time.Since(start); d > time.Minute {
+			t.Errorf("Exec took %v; want about %v", d, timeout)
+		}
+	})
+}
+
+func TestConcurrencyLimits(t *testing.T) {
+	started := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(Status{})
+	})
+	mux.HandleFunc("/exec", func(w http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		w.Header().Set("Trailer", "Process-State")
+		w.Header().Set("Process-State", "ok")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	cl.SetConcurrencyLimits(1, 1)
+	m := NewMetrics()
+	cl.SetObserver(m)
+	defer cl.Close()
+
+	ctx := context.Background()
+	exec := func() <-chan error {
+		errc := make(chan error, 1)
+		go func() {
+			remoteErr, execErr := cl.Exec(ctx, "./bin/test", ExecOpts{})
+			errc <- errors.Join(remoteErr, execErr)
+		}()
+		return errc
+	}
+	first := exec()
+	<-started
+
+	// A heavy call waits for the first one, until its context is done.
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, execErr := cl.Exec(waitCtx, "./bin/test", ExecOpts{}); !errors.Is(execErr, context.DeadlineExceeded) {
+		t.Errorf("queued Exec error = %v; want %v", execErr, context.DeadlineExceeded)
+	}
+	if cl.IsBroken() {
+		t.Errorf("client marked broken after a queued Exec's context was done")
+	}
+
+	// Light calls aren't held up by heavy ones.
+	if _, err := cl.Status(ctx); err != nil {
+		t.Errorf("Status during Exec: %v", err)
+	}
+
+	second := exec()
+	select {
+	case <-started:
+		t.Fatalf("second Exec reached the buildlet while the first was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(unblock)
+	for _, errc := range []<-chan error{first, second} {
+		if err := <-errc; err != nil {
+			t.Errorf("Exec: %v", err)
+		}
+	}
+	if v := m.Get("/exec", "requests"); v == nil || v.String() != "2" {
+		t.Errorf("/exec requests = %v; want 2", v)
+	}
+	if v := m.Get("/exec", "queue_seconds"); v == nil || v.String() == "0" {
+		t.Errorf("/exec queue_seconds = %v; want nonzero", v)
+	}
+}
+
+func TestRemoveGlob(t *testing.T) {
+	version := 31
+	var form url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(Status{Version: version})
+	})
+	mux.HandleFunc("/removeall", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		form = req.PostForm
+		w.Header().Set("X-Go-Removed", "2")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	ctx := context.Background()
+	n, err := cl.RemoveGlob(ctx, "go/src", RemoveGlobOpts{Patterns: []string{"*.test", "*.exe"}})
+	if err != nil {
+		t.Fatalf("RemoveGlob: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("RemoveGlob = %d; want 2", n)
+	}
+	want := url.Values{"dir": {"go/src"}, "pattern": {"*.test", "*.exe"}, "force": {"false"}}
+	if !reflect.DeepEqual(form, want) {
+		t.Errorf("buildlet got form %v; want %v", form, want)
+	}
+
+	if _, err := cl.RemoveGlob(ctx, "", RemoveGlobOpts{}); err == nil {
+		t.Errorf("RemoveGlob without patterns succeeded; want error")
+	}
+	if _, err := cl.RemoveGlob(ctx, "", RemoveGlobOpts{Patterns: []string{"[x"}}); err == nil {
+		t.Errorf("RemoveGlob with an invalid pattern succeeded; want error")
+	}
+	version = 30
+	if _, err := cl.RemoveGlob(ctx, "", RemoveGlobOpts{Patterns: []string{"*.test"}}); err == nil || !strings.Contains(err.Error(), "version 31") {
+		t.Errorf("RemoveGlob on old buildlet = %v; want version error", err)
+	}
+}
+
+func TestTail(t *testing.T) {
+	var reqs []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(Status{Version: 32})
+	})
+	mux.HandleFunc("/tail", func(w http.ResponseWriter, req *http.Request) {
+		reqs = append(reqs, req.URL.RawQuery)
+		w.Header().Set("Trailer", "Tail-State")
+		if req.FormValue("offset") != "0" {
+			io.WriteString(w, "old\n")
+			w.Header().Set("Tail-State", "truncated")
+			return
+		}
+		io.WriteString(w, "new\n")
+		w.Header().Set("Tail-State", "eof")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	var out strings.Builder
+	truncated := 0
+	err = cl.Tail(context.Background(), "log", &out, TailOpts{
+		Offset:     100,
+		Lines:      3,
+		OnTruncate: func() { truncated++ },
+	})
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if out.String() != "old\nnew\n" || truncated != 1 {
+		t.Errorf("Tail wrote %q and truncated %d times; want %q and once", out.String(), truncated, "old\nnew\n")
+	}
+	want := []string{
+		"follow=false&lines=3&offset=100&path=log",
+		"follow=false&lines=0&offset=0&path=log",
+	}
+	if !reflect.DeepEqual(reqs, want) {
+		t.Errorf("buildlet got requests %q; want %q", reqs, want)
+	}
+}
+
+func TestPing(t *testing.T) {
+	buildletTime := time.Now().Add(time.Hour)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(Status{Version: 33, Time: buildletTime})
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	pr, err := cl.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if pr.RTT <= 0 || pr.Status.Version != 33 {
+		t.Errorf("Ping = %+v; want positive RTT and version 33", pr)
+	}
+	if pr.Skew < 59*time.Minute || pr.Skew > 61*time.Minute {
+		t.Errorf("Ping skew = %v; want about 1h", pr.Skew)
+	}
+
+	c := cl.(*client)
+	for i := 1; i <= heartbeatWindow+2; i++ {
+		c.recordHeartbeat(time.Duration(i))
+	}
+	got := cl.HeartbeatLatencies()
+	if len(got) != heartbeatWindow || got[0] != 3 || got[heartbeatWindow-1] != heartbeatWindow+2 {
+		t.Errorf("HeartbeatLatencies = %v; want the last %d heartbeats, oldest first", got, heartbeatWindow)
+	}
+}
+
+func TestDetailedStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		st := Status{Version: 35, DiskFree: 1 << 30, LoadAvg: []float64{1, 2, 3}}
+		if req.FormValue("detailed") == "1" {
+			st.WorkDirSize = 123
+		}
+		json.NewEncoder(w).Encode(st)
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	defer cl.Close()
+
+	st, err := cl.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if st.DiskFree != 1<<30 || len(st.LoadAvg) != 3 || st.WorkDirSize != 0 {
+		t.Errorf("Status = %+v; want disk, load and no work dir size", st)
+	}
+	st, err = cl.DetailedStatus(context.Background())
+	if err != nil {
+		t.Fatalf("DetailedStatus: %v", err)
+	}
+	if st.WorkDirSize != 123 {
+		t.Errorf("DetailedStatus work dir size = %d; want 123", st.WorkDirSize)
+	}
+}
+
+// newFlakyBuildlet returns a client of a buildlet whose responses to
+// each path fail with 502 Bad Gateway fails times before succeeding, and
+// a count of the requests for each path.
+func newFlakyBuildlet(t *testing.T, fails int) (Client, map[string]int) {
+	var mu sync.Mutex
+	reqs := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		reqs[req.URL.Path]++
+		n := reqs[req.URL.Path]
+		mu.Unlock()
+		if n <= fails {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		switch req.URL.Path {
+		case "/status":
+			json.NewEncoder(w).Encode(Status{Version: 33})
+		case "/ls":
+			io.WriteString(w, "-rw-r--r--\tfile\t0\t2024-01-01T00:00:00Z\n")
+		case "/exec":
+			w.Header().Set("Trailer", "Process-State")
+			w.Header().Set("Process-State", "ok")
+		}
+	}))
+	t.Cleanup(ts.Close)
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("unable to parse http server url %s", err)
+	}
+	cl := NewClient(u.Host, NoKeyPair)
+	t.Cleanup(func() { cl.Close() })
+	return cl, reqs
+}
+
+func TestRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond, StatusCodes: []int{http.StatusBadGateway}}
+
+	t.Run("off by default", func(t *testing.T) {
+		cl, reqs := newFlakyBuildlet(t, 1)
+		if _, err := cl.Status(ctx); err == nil {
+			t.Errorf("Status succeeded; want error")
+		}
+		if reqs["/status"] != 1 {
+			t.Errorf("Status sent %d requests; want 1", reqs["/status"])
+		}
+	})
+	t.Run("idempotent", func(t *testing.T) {
+		cl, reqs := newFlakyBuildlet(t, 2)
+		cl.SetRetryPolicy(policy)
+		if st, err := cl.Status(ctx); err != nil || st.Version != 33 {
+			t.Errorf("Status = %+v, %v; want version 33", st, err)
+		}
+		var entries int
+		if err := cl.ListDir(ctx, ".", ListDirOpts{}, func(DirEntry) { entries++ }); err != nil || entries != 1 {
+			t.Errorf("ListDir = %v with %d entries; want one entry", err, entries)
+		}
+		if reqs["/status"] != 3 || reqs["/ls"] != 3 {
+			t.Errorf("sent %d status and %d ls requests; want 3 of each", reqs["/status"], reqs["/ls"])
+		}
+	})
+	t.Run("too many failures", func(t *testing.T) {
+		cl, reqs := newFlakyBuildlet(t, 3)
+		cl.SetRetryPolicy(policy)
+		if _, err := cl.Status(ctx); err == nil {
+			t.Errorf("Status succeeded; want error")
+		}
+		if reqs["/status"] != 3 {
+			t.Errorf("Status sent %d requests; want 3", reqs["/status"])
+		}
+	})
+	t.Run("not idempotent", func(t *testing.T) {
+		cl, reqs := newFlakyBuildlet(t, 1)
+		cl.SetRetryPolicy(policy)
+		if _, execErr := cl.Exec(ctx, "./bin/test", ExecOpts{}); execErr == nil {
+			t.Errorf("Exec succeeded; want error")
+		}
+		if reqs["/exec"] != 1 {
+			t.Errorf("Exec sent %d requests; want 1", reqs["/exec"])
+		}
+	})
+}
+
+func TestTarEncodings(t *testing.T) {
+	const content = "the contents of a tarball"
+	compress := func(t *testing.T, enc string) []byte {
+		var buf bytes.Buffer
+		if err := copyRecompressed(&buf, bytes.NewReader(gzipped(t, content)), EncodingGzip, enc); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	decompress := func(t *testing.T, r io.Reader, enc string) string {
+		var buf bytes.Buffer
+		if err := copyRecompressed(&buf, r, enc, EncodingGzip); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	for _, supportsZstd := range []bool{false, true} {
+		t.Run(fmt.Sprintf("supportsZstd=%t", supportsZstd), func(t *testing.T) {
+			wantWire := EncodingGzip
+			var encodings []string
+			if supportsZstd {
+				wantWire = EncodingZstd
+				encodings = []string{EncodingZstd}
+			}
+			var putEncoding, putContent string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+				json.NewEncoder(w).Encode(Status{Version: 34, Encodings: encodings})
+			})
+			mux.HandleFunc("/writetgz", func(w http.ResponseWriter, req *http.Request) {
+				putEncoding = req.Header.Get("Content-Encoding")
+				putContent = decompress(t, req.Body, putEncoding)
+			})
+			var getEncodings []string
+			mux.HandleFunc("/tgz", func(w http.ResponseWriter, req *http.Request) {
+				if supportsZstd && req.Header.Get("Accept-Encoding") == EncodingZstd {
+					getEncodings = append(getEncodings, EncodingZstd)
+					w.Header().Set("Content-Encoding", EncodingZstd)
+					w.Write(compress(t, EncodingZstd))
+					return
+				}
+				getEncodings = append(getEncodings, EncodingGzip)
+				w.Write(gzipped(t, content))
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+			u, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatalf("unable to parse http server url %s", err)
+			}
+			cl := NewClient(u.Host, NoKeyPair)
+			m := NewMetrics()
+			cl.SetObserver(m)
+			defer cl.Close()
+			ctx := context.Background()
+
+			for _, enc := range []string{EncodingGzip, EncodingZstd} {
+				err := cl.PutTar(ctx, bytes.NewReader(compress(t, enc)), "dir", TarOpts{Encoding: enc})
+				if err != nil {
+					t.Fatalf("PutTar(%s): %v", enc, err)
+				}
+				if want := map[string]string{EncodingGzip: EncodingGzip, EncodingZstd: wantWire}[enc]; putEncoding != want || putContent != content {
+					t.Errorf("PutTar(%s) sent %q with encoding %s; want %q with %s", enc, putContent, putEncoding, content, want)
+				}
+
+				rc, err := cl.GetTar(ctx, "dir", TarOpts{Encoding: enc})
+				if err != nil {
+					t.Fatalf("GetTar(%s): %v", enc, err)
+				}
+				got := decompress(t, rc, enc)
+				rc.Close()
+				if got != content {
+					t.Errorf("GetTar(%s) = %q; want %q", enc, got, content)
+				}
+			}
+			// GetTar transfers zstd whenever the buildlet supports it,
+			// even when the caller wants gzip.
+			if want := []string{wantWire, wantWire}; !slices.Equal(getEncodings, want) {
+				t.Errorf("GetTar transferred tarballs with encodings %q; want %q", getEncodings, want)
+			}
+			for _, method := range []string{"/writetgz", "/tgz"} {
+				if v := m.Get(method, "encoding_"+wantWire); v == nil || v.String() == "0" {
+					t.Errorf("%s encoding_%s = %v; want nonzero", method, wantWire, v)
+				}
+			}
+			if err := cl.PutTar(ctx, strings.NewReader(content), "dir", TarOpts{Encoding: "br"}); err == nil {
+				t.Errorf("PutTar with an unsupported encoding succeeded; want error")
+			}
+		})
+	}
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
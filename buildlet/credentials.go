@@ -0,0 +1,212 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/build"
+	"golang.org/x/build/buildenv"
+)
+
+// Environment variables with CoordinatorClient settings; see
+// NewCoordinatorClientFromFlags.
+const (
+	envCoordinator = "GOMOTE_COORDINATOR"
+	envUser        = "GOMOTE_USER"
+	envToken       = "GOMOTE_TOKEN"
+	envCACert      = "GOMOTE_CA_CERT"
+	envInsecure    = "GOMOTE_INSECURE_SKIP_VERIFY"
+)
+
+// credentialsFile is the name of the credentials file in the gomote
+// config directory.
+const credentialsFile = "credentials.json"
+
+// coordinatorSettings are the CoordinatorClient settings from one
+// source. Zero fields are unset.
+type coordinatorSettings struct {
+	Coordinator        string `json:"coordinator,omitempty"`
+	User               string `json:"user,omitempty"`
+	Token              string `json:"token,omitempty"`
+	CACert             string `json:"caCert,omitempty"` // file name
+	InsecureSkipVerify *bool  `json:"insecureSkipVerify,omitempty"`
+}
+
+// fill sets the fields of s which are unset from o.
+func (s *coordinatorSettings) fill(o coordinatorSettings) {
+	if s.Coordinator == "" {
+		s.Coordinator = o.Coordinator
+	}
+	if s.User == "" {
+		s.User = o.User
+	}
+	if s.Token == "" {
+		s.Token = o.Token
+	}
+	if s.CACert == "" {
+		s.CACert = o.CACert
+	}
+	if s.InsecureSkipVerify == nil {
+		s.InsecureSkipVerify = o.InsecureSkipVerify
+	}
+}
+
+// flagSettings returns the settings from the flags registered by
+// RegisterFlags which were set on the command line.
+func flagSettings() coordinatorSettings {
+	var s coordinatorSettings
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "user":
+			s.User = gomoteUserFlag
+		case "staging", "localdev":
+			switch buildenv.FromFlags() {
+			case buildenv.Staging:
+				s.Coordinator = string(build.StagingCoordinator)
+			case buildenv.Development:
+				s.Coordinator = "localhost:8119"
+			default:
+				s.Coordinator = string(build.ProdCoordinator)
+			}
+		}
+	})
+	return s
+}
+
+// envSettings returns the settings from the environment.
+func envSettings() (coordinatorSettings, error) {
+	s := coordinatorSettings{
+		Coordinator: os.Getenv(envCoordinator),
+		User:        os.Getenv(envUser),
+		Token:       os.Getenv(envToken),
+		CACert:      os.Getenv(envCACert),
+	}
+	if v := os.Getenv(envInsecure); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("invalid $%s: %w", envInsecure, err)
+		}
+		s.InsecureSkipVerify = &insecure
+	}
+	return s, nil
+}
+
+// fileSettings returns the settings from the credentials file, if any.
+func fileSettings() (coordinatorSettings, error) {
+	var s coordinatorSettings
+	name := filepath.Join(configDir(), credentialsFile)
+	b, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("reading credentials file %s: %w", name, err)
+	}
+	return s, nil
+}
+
+// newCoordinatorClientFrom returns a CoordinatorClient using the settings
+// in s, and those from the environment and credentials file which s
+// leaves unset.
+func newCoordinatorClientFrom(s coordinatorSettings) (cc *CoordinatorClient, err error) {
+	// The token may be mentioned by errors from any source, such as a
+	// credentials file which doesn't parse.
+	defer func() {
+		if err != nil {
+			err = redact(err, s.Token)
+		}
+	}()
+	env, err := envSettings()
+	if err != nil {
+		return nil, err
+	}
+	s.fill(env)
+	file, err := fileSettings()
+	if err != nil {
+		return nil, err
+	}
+	s.fill(file)
+
+	inst := build.CoordinatorInstance(s.Coordinator)
+	if inst == "" {
+		inst = build.ProdCoordinator
+	}
+	user, tok := s.User, s.Token
+	if user == "" {
+		user = username()
+	}
+	if user == "" {
+		return nil, fmt.Errorf("user flag or $%s must be specified", envUser)
+	}
+	if tok == "" {
+		user, tok, err = userToken(user, inst == build.StagingCoordinator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cc = &CoordinatorClient{
+		Auth: UserPass{
+			Username: "user-" + user,
+			Password: tok,
+		},
+		Instance: inst,
+	}
+	insecure := s.InsecureSkipVerify != nil && *s.InsecureSkipVerify
+	if s.CACert != "" || insecure {
+		cc.TLSConfig = &tls.Config{InsecureSkipVerify: insecure}
+		if s.CACert != "" {
+			pem, err := os.ReadFile(s.CACert)
+			if err != nil {
+				return nil, fmt.Errorf("reading coordinator CA certificates: %w", err)
+			}
+			cc.TLSConfig.RootCAs = x509.NewCertPool()
+			if !cc.TLSConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificates found in %s", s.CACert)
+			}
+		}
+	}
+	return cc, nil
+}
+
+// redact returns err with the non-empty secrets replaced in its message.
+func redact(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, secret := range secrets {
+		if secret != "" {
+			msg = strings.ReplaceAll(msg, secret, "REDACTED")
+		}
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError is an error whose message has secrets removed.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+// Is reports whether the original error matches target, without
+// exposing it through Unwrap.
+func (e *redactedError) Is(target error) bool { return errors.Is(e.err, target) }
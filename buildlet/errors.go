@@ -0,0 +1,187 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The errors of the gomote server and the coordinator which callers are
+// likely to handle, along with ErrQuotaExceeded. The errors returned by
+// the CreateBuildletWithStatus methods of CoordinatorClient and
+// GRPCCoordinatorClient match them with errors.Is when they apply, as do
+// those of gRPC clients dialed with ServerErrorDialOptions.
+var (
+	// ErrUnknownBuilderType is returned when creating a buildlet of a
+	// builder type the server doesn't know.
+	ErrUnknownBuilderType = errors.New("buildlet: unknown builder type")
+
+	// ErrInstanceNotFound is returned when the named buildlet doesn't
+	// exist, such as because it has expired.
+	ErrInstanceNotFound = errors.New("buildlet: instance not found")
+
+	// ErrNotOwner is returned when the named buildlet belongs to
+	// someone else.
+	ErrNotOwner = errors.New("buildlet: instance not owned by the caller")
+
+	// ErrServerUnavailable is returned when the server can't be
+	// reached, or is briefly unable to handle requests.
+	ErrServerUnavailable = errors.New("buildlet: server unavailable")
+)
+
+// A ServerError is an error returned by the gomote server or the
+// coordinator, which Kind classifies. errors.Is matches both Kind and
+// Err, and errors.As and status.Code see through it to the gRPC status
+// error, if Err is one.
+type ServerError struct {
+	// Kind is one of the errors of this package which the server's
+	// response maps to, such as ErrInstanceNotFound.
+	Kind error
+
+	// Err is the error returned by the server.
+	Err error
+}
+
+func (e *ServerError) Error() string   { return e.Err.Error() }
+func (e *ServerError) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// createInstanceMethod is the gRPC method creating gomote instances.
+const createInstanceMethod = "/protos.GomoteService/CreateInstance"
+
+const (
+	// ErrorDomain is the domain of the google.rpc.ErrorInfo details of
+	// the errors of the gomote server, which give their reason.
+	ErrorDomain = "gomote.golang.org"
+
+	// ReasonUnknownBuilderType is the reason of the error of the gomote
+	// server for a request to create an instance of a builder type it
+	// doesn't know. The error's code is InvalidArgument, which the server
+	// also returns for other invalid arguments, such as lifetimes.
+	ReasonUnknownBuilderType = "UNKNOWN_BUILDER_TYPE"
+)
+
+// UnknownBuilderTypeError returns the error of the gomote server for a
+// request to create an instance of a builder type it doesn't know: an
+// InvalidArgument status error with the reason ReasonUnknownBuilderType.
+func UnknownBuilderTypeError() error {
+	s := status.New(codes.InvalidArgument, "unknown builder type")
+	if d, err := s.WithDetails(&errdetails.ErrorInfo{Reason: ReasonUnknownBuilderType, Domain: ErrorDomain}); err == nil {
+		s = d
+	}
+	return s.Err()
+}
+
+// hasReason reports whether s has the google.rpc.ErrorInfo details of the
+// gomote server with the given reason.
+func hasReason(s *status.Status, reason string) bool {
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == ErrorDomain && info.GetReason() == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// GRPCServerError returns err, an error returned by the RPC of the gomote
+// server with the full method name method, as a *ServerError if its code
+// maps to one of the errors of this package, and otherwise unchanged.
+func GRPCServerError(method string, err error) error {
+	var se *ServerError
+	if err == nil || errors.As(err, &se) {
+		return err
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	var kind error
+	switch s.Code() {
+	case codes.InvalidArgument:
+		// CreateInstance rejects other arguments, such as lifetimes and
+		// labels, with InvalidArgument too, so the reason tells an
+		// unknown builder type apart. Servers which predate it only
+		// have their message.
+		if hasReason(s, ReasonUnknownBuilderType) || (method == createInstanceMethod && s.Message() == "unknown builder type") {
+			kind = ErrUnknownBuilderType
+		}
+	case codes.NotFound:
+		kind = ErrInstanceNotFound
+	case codes.PermissionDenied:
+		// CreateInstance fails with PermissionDenied when the caller
+		// may not use the builder type, rather than an instance.
+		if method != createInstanceMethod {
+			kind = ErrNotOwner
+		}
+	case codes.ResourceExhausted:
+		kind = ErrQuotaExceeded
+	case codes.Unavailable:
+		kind = ErrServerUnavailable
+	}
+	if kind == nil {
+		return err
+	}
+	return &ServerError{Kind: kind, Err: err}
+}
+
+// httpServerError returns err, the error of a response of the coordinator
+// with the given status code to a request to create a buildlet, as a
+// *ServerError if the status code maps to one of the errors of this
+// package, and otherwise unchanged.
+func httpServerError(statusCode int, err error) error {
+	var kind error
+	switch statusCode {
+	case http.StatusBadRequest:
+		// The coordinator rejects unknown builder types with 400.
+		kind = ErrUnknownBuilderType
+	case http.StatusTooManyRequests:
+		kind = ErrQuotaExceeded
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		kind = ErrServerUnavailable
+	}
+	if kind == nil {
+		return err
+	}
+	return &ServerError{Kind: kind, Err: err}
+}
+
+// ServerErrorDialOptions returns the options of a gRPC client of the gomote
+// server which make the errors of its RPCs *ServerErrors, per
+// GRPCServerError.
+func ServerErrorDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return GRPCServerError(method, invoker(ctx, method, req, reply, cc, opts...))
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			s, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				return nil, GRPCServerError(method, err)
+			}
+			return &serverErrorStream{ClientStream: s, method: method}, nil
+		}),
+	}
+}
+
+// serverErrorStream is a gRPC client stream whose errors are
+// *ServerErrors, per GRPCServerError.
+type serverErrorStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *serverErrorStream) SendMsg(m any) error {
+	return GRPCServerError(s.method, s.ClientStream.SendMsg(m))
+}
+
+func (s *serverErrorStream) RecvMsg(m any) error {
+	return GRPCServerError(s.method, s.ClientStream.RecvMsg(m))
+}
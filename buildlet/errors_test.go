@@ -0,0 +1,156 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCServerError(t *testing.T) {
+	const other = "/protos.GomoteService/InstanceStatus"
+	testCases := []struct {
+		desc   string
+		method string
+		err    error
+		want   error // nil if err isn't classified
+	}{
+		{"unknown builder type", createInstanceMethod, UnknownBuilderTypeError(), ErrUnknownBuilderType},
+		{"unknown builder type, any method", "", UnknownBuilderTypeError(), ErrUnknownBuilderType},
+		{"unknown builder type, old server", createInstanceMethod, status.Error(codes.InvalidArgument, "unknown builder type"), ErrUnknownBuilderType},
+		{"missing builder type", createInstanceMethod, status.Error(codes.InvalidArgument, "invalid builder type"), nil},
+		{"lifetime too long", createInstanceMethod, status.Error(codes.InvalidArgument, "requested lifetime of 172800 seconds exceeds the maximum of 24h0m0s"), nil},
+		{"invalid label", createInstanceMethod, status.Error(codes.InvalidArgument, "17 labels requested; the maximum is 16"), nil},
+		{"invalid argument", other, status.Error(codes.InvalidArgument, "invalid gomote name"), nil},
+		{"not found", other, status.Error(codes.NotFound, "instance not found"), ErrInstanceNotFound},
+		{"not owner", other, status.Error(codes.PermissionDenied, "not owned"), ErrNotOwner},
+		{"builder type denied", createInstanceMethod, status.Error(codes.PermissionDenied, "not allowed"), nil},
+		{"quota", createInstanceMethod, status.Error(codes.ResourceExhausted, "too many instances"), ErrQuotaExceeded},
+		{"unavailable", other, status.Error(codes.Unavailable, "connection refused"), ErrServerUnavailable},
+		{"wrapped", "", fmt.Errorf("unable to ping instance: %w", status.Error(codes.NotFound, "instance not found")), ErrInstanceNotFound},
+		{"internal", other, status.Error(codes.Internal, "oops"), nil},
+		{"not grpc", other, errors.New("disk full"), nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := GRPCServerError(tc.method, tc.err)
+			var se *ServerError
+			if isSE := errors.As(got, &se); isSE != (tc.want != nil) {
+				t.Fatalf("GRPCServerError(%q, %v) = %#v; want *ServerError: %t", tc.method, tc.err, got, tc.want != nil)
+			}
+			if tc.want == nil {
+				if got != tc.err {
+					t.Errorf("GRPCServerError(%q, %v) = %v; want it unchanged", tc.method, tc.err, got)
+				}
+				return
+			}
+			if !errors.Is(got, tc.want) {
+				t.Errorf("GRPCServerError(%q, %v) isn't %v", tc.method, tc.err, tc.want)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("GRPCServerError(%q, %v) doesn't wrap the error", tc.method, tc.err)
+			}
+			if got.Error() != tc.err.Error() {
+				t.Errorf("GRPCServerError(%q, %v).Error() = %q; want %q", tc.method, tc.err, got.Error(), tc.err.Error())
+			}
+			if gotCode, wantCode := status.Code(got), status.Code(tc.err); gotCode != wantCode {
+				t.Errorf("status.Code(GRPCServerError(%q, %v)) = %v; want %v", tc.method, tc.err, gotCode, wantCode)
+			}
+			if again := GRPCServerError("", got); again != got {
+				t.Errorf("GRPCServerError of a *ServerError = %#v; want it unchanged", again)
+			}
+		})
+	}
+	if err := GRPCServerError(other, nil); err != nil {
+		t.Errorf("GRPCServerError(%q, nil) = %v; want nil", other, err)
+	}
+}
+
+func TestHTTPServerError(t *testing.T) {
+	testCases := []struct {
+		statusCode int
+		want       error // nil if the error isn't classified
+	}{
+		{http.StatusBadRequest, ErrUnknownBuilderType},
+		{http.StatusTooManyRequests, ErrQuotaExceeded},
+		{http.StatusBadGateway, ErrServerUnavailable},
+		{http.StatusServiceUnavailable, ErrServerUnavailable},
+		{http.StatusGatewayTimeout, ErrServerUnavailable},
+		{http.StatusInternalServerError, nil},
+		{http.StatusForbidden, nil},
+	}
+	for _, tc := range testCases {
+		err := fmt.Errorf("%d %s", tc.statusCode, http.StatusText(tc.statusCode))
+		got := httpServerError(tc.statusCode, err)
+		if tc.want == nil {
+			if got != err {
+				t.Errorf("httpServerError(%d, %v) = %v; want it unchanged", tc.statusCode, err, got)
+			}
+			continue
+		}
+		if !errors.Is(got, tc.want) || !errors.Is(got, err) {
+			t.Errorf("httpServerError(%d, %v) = %v; want it to be %v and wrap the error", tc.statusCode, err, got, tc.want)
+		}
+	}
+}
+
+func TestServerErrorDialOptions(t *testing.T) {
+	// The interceptors aren't exported by grpc, so dial a connection
+	// which is never used, and check the errors of fake invokers and
+	// streamers through the options applied to calls made on it.
+	conn, err := grpc.Dial("localhost:0",
+		append(ServerErrorDialOptions(), grpc.WithInsecure(),
+			grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				return status.Error(codes.NotFound, "instance not found")
+			}),
+			grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return &fakeClientStream{recvErr: status.Error(codes.Unavailable, "connection reset")}, nil
+			}),
+		)...)
+	if err != nil {
+		t.Fatalf("grpc.Dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	err = conn.Invoke(ctx, "/protos.GomoteService/InstanceStatus", nil, nil)
+	if !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("Invoke = %v; want %v", err, ErrInstanceNotFound)
+	}
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, createInstanceMethod)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	if err := stream.RecvMsg(nil); !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("RecvMsg = %v; want %v", err, ErrServerUnavailable)
+	}
+	if err := stream.RecvMsg(nil); err != io.EOF {
+		t.Errorf("second RecvMsg = %v; want io.EOF", err)
+	}
+}
+
+// fakeClientStream is a grpc.ClientStream whose first RecvMsg fails with
+// recvErr, and later ones with io.EOF.
+type fakeClientStream struct {
+	grpc.ClientStream
+	recvErr error
+}
+
+func (s *fakeClientStream) RecvMsg(m any) error {
+	err := s.recvErr
+	if err == nil {
+		err = io.EOF
+	}
+	s.recvErr = nil
+	return err
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// An ExecStream identifies the stream a chunk of the output of a command
+// run by Exec belongs to.
+type ExecStream uint8
+
+const (
+	// ExecOutput is the combined standard output and standard error of
+	// the command, sent by buildlets which don't separate them.
+	ExecOutput ExecStream = iota
+	ExecStdout            // the command's standard output
+	ExecStderr            // the command's standard error
+	ExecSystem            // messages from the buildlet itself, such as debug info
+)
+
+func (s ExecStream) String() string {
+	switch s {
+	case ExecOutput:
+		return "output"
+	case ExecStdout:
+		return "stdout"
+	case ExecStderr:
+		return "stderr"
+	case ExecSystem:
+		return "system"
+	}
+	return fmt.Sprintf("ExecStream(%d)", uint8(s))
+}
+
+// ExecStreamsHeader is the response header set by the buildlet's /exec
+// handler when it sends the output of the command as frames written by an
+// ExecStreamWriter, which it does when the request's "streams" form value
+// is true. Older buildlets ignore the form value and send combined output.
+const ExecStreamsHeader = "X-Buildlet-Exec-Streams"
+
+// execFrameHeaderLen is the length of the header of each frame: the
+// stream, followed by the big-endian length of the data.
+const execFrameHeaderLen = 5
+
+// An ExecStreamWriter multiplexes the streams of a command's output onto
+// a single writer.
+type ExecStreamWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewExecStreamWriter returns an ExecStreamWriter writing to w.
+func NewExecStreamWriter(w io.Writer) *ExecStreamWriter {
+	return &ExecStreamWriter{w: w}
+}
+
+// Stream returns a writer of stream s. Each Write to it writes a single
+// frame to the underlying writer. It's safe to write to several streams
+// concurrently.
+func (sw *ExecStreamWriter) Stream(s ExecStream) io.Writer {
+	return execStreamWriter{sw, s}
+}
+
+type execStreamWriter struct {
+	sw *ExecStreamWriter
+	s  ExecStream
+}
+
+func (w execStreamWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	frame := make([]byte, execFrameHeaderLen+len(p))
+	frame[0] = byte(w.s)
+	binary.BigEndian.PutUint32(frame[1:execFrameHeaderLen], uint32(len(p)))
+	copy(frame[execFrameHeaderLen:], p)
+
+	w.sw.mu.Lock()
+	defer w.sw.mu.Unlock()
+	if _, err := w.sw.w.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// readExecStreams reads the frames written by an ExecStreamWriter from r,
+// calling fn with the data of each, in chunks of at most 32 KiB, until
+// r is at EOF. It stops and returns fn's error if fn fails.
+func readExecStreams(r io.Reader, fn func(s ExecStream, p []byte) error) error {
+	var hdr [execFrameHeaderLen]byte
+	buf := make([]byte, 32<<10)
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		s := ExecStream(hdr[0])
+		for n := binary.BigEndian.Uint32(hdr[1:]); n > 0; {
+			p := buf
+			if uint32(len(p)) > n {
+				p = p[:n]
+			}
+			if _, err := io.ReadFull(r, p); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			if err := fn(s, p); err != nil {
+				return err
+			}
+			n -= uint32(len(p))
+		}
+	}
+}
+
+// An ExecError is the remoteErr returned by Exec when the command ran,
+// but failed.
+type ExecError struct {
+	// State describes how the command exited, such as "exit status 1"
+	// or "signal: killed".
+	State string
+
+	// ExitCode is the exit code of the command, or -1 if it was killed
+	// by a signal or the buildlet didn't report it.
+	ExitCode int
+}
+
+func (e *ExecError) Error() string { return e.State }
+
+// execError returns the ExecError for a command which exited with state,
+// given the value of the Process-Exit-Code trailer, which buildlets
+// predating it don't set.
+func execError(state, exitCode string) *ExecError {
+	e := &ExecError{State: state, ExitCode: -1}
+	if code, err := strconv.Atoi(exitCode); err == nil {
+		e.ExitCode = code
+	} else if s, ok := strings.CutPrefix(state, "exit status "); ok {
+		if code, err := strconv.Atoi(s); err == nil {
+			e.ExitCode = code
+		}
+	}
+	return e
+}
+
+// ExitCode returns the exit code of the command which failed with
+// remoteErr, as returned by Exec, or -1 if it's unknown.
+func ExitCode(remoteErr error) int {
+	var ee *ExecError
+	if errors.As(remoteErr, &ee) {
+		return ee.ExitCode
+	}
+	return -1
+}
@@ -13,20 +13,28 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
 // RemoteClient is a subset of methods that can be used by a gomote client.
+//
+// Methods which take a context abort any request to the buildlet when the
+// context is canceled, including while streaming a request or response body.
 type RemoteClient interface {
 	Close() error
+	CloseContext(ctx context.Context) error
 	Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr, execErr error)
-	GetTar(ctx context.Context, dir string) (io.ReadCloser, error)
+	GetTar(ctx context.Context, dir string, opts ...TarOpts) (io.ReadCloser, error)
 	ListDir(ctx context.Context, dir string, opts ListDirOpts, fn func(DirEntry)) error
 	Put(ctx context.Context, r io.Reader, path string, mode os.FileMode) error
-	PutTar(ctx context.Context, r io.Reader, dir string) error
-	PutTarFromURL(ctx context.Context, tarURL, dir string) error
-	ProxyTCP(port int) (io.ReadWriteCloser, error)
+	PutTar(ctx context.Context, r io.Reader, dir string, opts ...TarOpts) error
+	PutTarFromURL(ctx context.Context, tarURL, dir string, opts ...TarOpts) error
+	PutTarFromURLSHA256(ctx context.Context, tarURL, dir, wantSHA256 string, opts ...TarOpts) (sha256 string, err error)
+	ProxyTCP(port int) (io.ReadWriteCloser, error) // Deprecated: Use ProxyTCPContext.
+	ProxyTCPContext(ctx context.Context, port int) (io.ReadWriteCloser, error)
 	RemoteName() string
 	RemoveAll(ctx context.Context, paths ...string) error
+	Tail(ctx context.Context, path string, w io.Writer, opts TailOpts) error
 	WorkDir(ctx context.Context) (string, error)
 }
 
@@ -37,19 +45,29 @@ type RemoteClient interface {
 // coordinator should use RemoteClient.
 type Client interface {
 	RemoteClient
-	ConnectSSH(user, authorizedPubKey string) (net.Conn, error)
+	ConnectSSH(user, authorizedPubKey string) (net.Conn, error) // Deprecated: Use ConnectSSHContext.
+	ConnectSSHContext(ctx context.Context, user, authorizedPubKey string) (net.Conn, error)
+	DetailedStatus(ctx context.Context) (Status, error)
 	IPPort() string
 	InstanceName() string
 	IsBroken() bool
+	HeartbeatLatencies() []time.Duration
 	MarkBroken()
 	Name() string
+	Ping(ctx context.Context) (PingResult, error)
 	ProxyRoundTripper() http.RoundTripper
+	RemoveGlob(ctx context.Context, dir string, opts RemoveGlobOpts) (removed int, err error)
 	SetDescription(v string)
 	SetDialer(dialer func(context.Context) (net.Conn, error))
 	SetHTTPClient(httpClient *http.Client)
 	SetInstanceName(v string)
 	SetName(name string)
+	SetCallTimeout(d time.Duration)
+	SetConcurrencyLimits(heavy, light int)
+	SetObserver(o Observer)
 	SetOnHeartbeatFailure(fn func())
+	SetReconnect(opts ReconnectOpts)
+	SetRetryPolicy(p RetryPolicy)
 	Status(ctx context.Context) (Status, error)
 	String() string
 	URL() string
@@ -68,6 +86,11 @@ type FakeClient struct {
 
 // Close is a fake client closer.
 func (fc *FakeClient) Close() error {
+	return fc.CloseContext(context.Background())
+}
+
+// CloseContext is a fake client closer.
+func (fc *FakeClient) CloseContext(ctx context.Context) error {
 	for _, f := range fc.closeFuncs {
 		f()
 	}
@@ -76,6 +99,11 @@ func (fc *FakeClient) Close() error {
 
 // ConnectSSH connects to a fake SSH server.
 func (fc *FakeClient) ConnectSSH(user, authorizedPubKey string) (net.Conn, error) {
+	return fc.ConnectSSHContext(context.Background(), user, authorizedPubKey)
+}
+
+// ConnectSSHContext connects to a fake SSH server.
+func (fc *FakeClient) ConnectSSHContext(ctx context.Context, user, authorizedPubKey string) (net.Conn, error) {
 	return nil, errUnimplemented
 }
 
@@ -84,13 +112,22 @@ func (fc *FakeClient) Exec(ctx context.Context, cmd string, opts ExecOpts) (remo
 	if cmd == "" {
 		return nil, errors.New("invalid command")
 	}
-	if opts.Output == nil {
-		return nil, nil
-	}
 	out := []byte("<this is a song that never ends>")
 	for it := 0; it < 3; it++ {
-		if n, err := opts.Output.Write(out); n != len(out) || err != nil {
-			return nil, fmt.Errorf("Output.Write(...) = %d, %q; want %d, no error", n, err, len(out))
+		if opts.Output != nil {
+			if n, err := opts.Output.Write(out); n != len(out) || err != nil {
+				return nil, fmt.Errorf("Output.Write(...) = %d, %q; want %d, no error", n, err, len(out))
+			}
+		}
+		if opts.OnOutput != nil {
+			if err := opts.OnOutput(out); err != nil {
+				return nil, err
+			}
+		}
+		if opts.OnStream != nil {
+			if err := opts.OnStream(ExecStdout, out); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return nil, nil
@@ -100,7 +137,7 @@ func (fc *FakeClient) Exec(ctx context.Context, cmd string, opts ExecOpts) (remo
 func (fc *FakeClient) InstanceName() string { return fc.instanceName }
 
 // GetTar gives a vake tar zipped directory.
-func (fc *FakeClient) GetTar(ctx context.Context, dir string) (io.ReadCloser, error) {
+func (fc *FakeClient) GetTar(ctx context.Context, dir string, opts ...TarOpts) (io.ReadCloser, error) {
 	r := strings.NewReader("the gopher goes to the sea and fights the kraken")
 	return io.NopCloser(r), nil
 }
@@ -135,7 +172,14 @@ func (fc *FakeClient) Name() string { return fc.name }
 func (fc *FakeClient) ProxyRoundTripper() http.RoundTripper { return nil }
 
 // ProxyTCP provides a fake proxy.
-func (fc *FakeClient) ProxyTCP(port int) (io.ReadWriteCloser, error) { return nil, errUnimplemented }
+func (fc *FakeClient) ProxyTCP(port int) (io.ReadWriteCloser, error) {
+	return fc.ProxyTCPContext(context.Background(), port)
+}
+
+// ProxyTCPContext provides a fake proxy.
+func (fc *FakeClient) ProxyTCPContext(ctx context.Context, port int) (io.ReadWriteCloser, error) {
+	return nil, errUnimplemented
+}
 
 // Put places a file on a fake buildlet.
 func (fc *FakeClient) Put(ctx context.Context, r io.Reader, path string, mode os.FileMode) error {
@@ -147,16 +191,22 @@ func (fc *FakeClient) Put(ctx context.Context, r io.Reader, path string, mode os
 }
 
 // PutTar fakes putting  a tar zipped file on a buildldet.
-func (fc *FakeClient) PutTar(ctx context.Context, r io.Reader, dir string) error {
+func (fc *FakeClient) PutTar(ctx context.Context, r io.Reader, dir string, opts ...TarOpts) error {
 	// TODO(go.dev/issue/48742) add a file system implementation which would enable proper testing.
 	return errUnimplemented
 }
 
 // PutTarFromURL fakes putting a tar zipped file on a builelt.
-func (fc *FakeClient) PutTarFromURL(ctx context.Context, tarURL, dir string) error {
+func (fc *FakeClient) PutTarFromURL(ctx context.Context, tarURL, dir string, opts ...TarOpts) error {
 	return nil
 }
 
+// PutTarFromURLSHA256 fakes putting a tar zipped file on a buildlet,
+// reporting that it has the expected digest.
+func (fc *FakeClient) PutTarFromURLSHA256(ctx context.Context, tarURL, dir, wantSHA256 string, opts ...TarOpts) (string, error) {
+	return wantSHA256, nil
+}
+
 // RemoteName gives the remote name of the fake buildlet.
 func (fc *FakeClient) RemoteName() string { return "" }
 
@@ -179,12 +229,32 @@ func (fc *FakeClient) SetName(name string) {
 	fc.name = name
 }
 
+// SetCallTimeout sets the default timeout of calls to the fake buildlet, which never time out.
+func (fc *FakeClient) SetCallTimeout(d time.Duration) {}
+
+// SetConcurrencyLimits sets the limits of calls in flight to the fake buildlet, which doesn't limit them.
+func (fc *FakeClient) SetConcurrencyLimits(heavy, light int) {}
+
+// SetObserver sets an observer of requests to the fake buildlet, which makes none.
+func (fc *FakeClient) SetObserver(o Observer) {}
+
 // SetOnHeartbeatFailure sets a function to be called when heartbeats against this fake buildlet fail.
 func (fc *FakeClient) SetOnHeartbeatFailure(fn func()) {}
 
+// SetReconnect configures reconnecting to the fake buildlet.
+func (fc *FakeClient) SetReconnect(opts ReconnectOpts) {}
+
+// SetRetryPolicy sets the policy for retrying calls to the fake buildlet, which never fail.
+func (fc *FakeClient) SetRetryPolicy(p RetryPolicy) {}
+
 // Status provides a status on the fake client.
 func (fc *FakeClient) Status(ctx context.Context) (Status, error) { return Status{}, errUnimplemented }
 
+// DetailedStatus provides a detailed status on the fake client.
+func (fc *FakeClient) DetailedStatus(ctx context.Context) (Status, error) {
+	return Status{}, errUnimplemented
+}
+
 // String provides a fake string representation of the client.
 func (fc *FakeClient) String() string { return "" }
 
@@ -201,3 +271,27 @@ func (fc *FakeClient) RemoveAll(ctx context.Context, paths ...string) error {
 	// TODO(go.dev/issue/48742) add a file system implementation which would enable proper testing.
 	return nil
 }
+
+// Tail writes the contents of a file on a fake buildlet, which are empty.
+func (fc *FakeClient) Tail(ctx context.Context, path string, w io.Writer, opts TailOpts) error {
+	if path == "" {
+		return errors.New("invalid arguments")
+	}
+	return nil
+}
+
+// Ping makes a round trip to a fake buildlet, which is instantaneous.
+func (fc *FakeClient) Ping(ctx context.Context) (PingResult, error) {
+	return PingResult{Status: Status{Time: time.Now()}}, nil
+}
+
+// HeartbeatLatencies returns the round-trip times of heartbeats to a fake buildlet, which has none.
+func (fc *FakeClient) HeartbeatLatencies() []time.Duration { return nil }
+
+// RemoveGlob deletes the entries matching patterns under dir for a fake buildlet, which has none.
+func (fc *FakeClient) RemoveGlob(ctx context.Context, dir string, opts RemoveGlobOpts) (int, error) {
+	if len(opts.Patterns) == 0 {
+		return 0, errors.New("RemoveGlob requires at least one pattern")
+	}
+	return 0, nil
+}
@@ -38,7 +38,8 @@ func apiGate() {
 }
 
 // ErrQuotaExceeded matches errors.Is when VM creation fails with a
-// quota error. Currently, it only supports GCE quota errors.
+// GCE quota error, or when the gomote server or the coordinator refuse
+// to create a buildlet because the caller has too many of them.
 var ErrQuotaExceeded = errors.New("quota exceeded")
 
 type GCEError struct {
@@ -349,8 +350,12 @@ type extraCloseClient struct {
 }
 
 func (e *extraCloseClient) Close() error {
+	return e.CloseContext(context.Background())
+}
+
+func (e *extraCloseClient) CloseContext(ctx context.Context) error {
 	defer e.close()
-	return e.Close()
+	return e.Client.CloseContext(ctx)
 }
 
 func createIAPTunnel(ctx context.Context, inst *compute.Instance) (string, func(), error) {
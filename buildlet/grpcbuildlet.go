@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"golang.org/x/build/internal/gomote/protos"
 	"golang.org/x/build/types"
@@ -21,6 +22,10 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// A GRPCCoordinatorClient creates and lists buildlets through the gomote
+// server. Its errors match those of this package, such as
+// ErrUnknownBuilderType; those of the buildlets it creates do if Client is
+// dialed with ServerErrorDialOptions.
 type GRPCCoordinatorClient struct {
 	Client protos.GomoteServiceClient
 }
@@ -32,7 +37,7 @@ func (c *GRPCCoordinatorClient) CreateBuildlet(ctx context.Context, builderType
 func (c *GRPCCoordinatorClient) CreateBuildletWithStatus(ctx context.Context, builderType string, status func(types.BuildletWaitStatus)) (RemoteClient, error) {
 	stream, err := c.Client.CreateInstance(ctx, &protos.CreateInstanceRequest{BuilderType: builderType})
 	if err != nil {
-		return nil, err
+		return nil, GRPCServerError(createInstanceMethod, err)
 	}
 	var instance *protos.Instance
 	for {
@@ -45,11 +50,21 @@ func (c *GRPCCoordinatorClient) CreateBuildletWithStatus(ctx context.Context, bu
 				workDir: instance.GetWorkingDir(),
 			}, nil
 		case err != nil:
-			return nil, err
+			return nil, GRPCServerError(createInstanceMethod, err)
 		case update.GetStatus() != protos.CreateInstanceResponse_COMPLETE:
-			status(types.BuildletWaitStatus{
-				Ahead: int(update.WaitersAhead),
-			})
+			qp := update.GetQueuePosition()
+			ws := types.BuildletWaitStatus{
+				Ahead:         int(update.GetWaitersAhead()),
+				AheadHostType: int(qp.GetWaitersAhead()),
+				PoolSize:      int(qp.GetPoolSize()),
+				Connected:     int(qp.GetConnected()),
+				EstimatedWait: time.Duration(qp.GetEstimatedWaitSeconds()) * time.Second,
+			}
+			if at := qp.GetEstimatedAt(); at != 0 {
+				t := time.Unix(at, 0)
+				ws.EstimatedAt = &t
+			}
+			status(ws)
 
 		case update.GetStatus() == protos.CreateInstanceResponse_COMPLETE:
 			instance = update.GetInstance()
@@ -58,6 +73,56 @@ func (c *GRPCCoordinatorClient) CreateBuildletWithStatus(ctx context.Context, bu
 	}
 }
 
+// ListBuildlets returns the caller's gomote instances selected by f.
+// The filter is evaluated by the gomote server, and again by the client
+// for servers which don't support it.
+func (c *GRPCCoordinatorClient) ListBuildlets(ctx context.Context, f InstanceFilter) ([]RemoteBuildlet, error) {
+	// The server can't filter on idleness, but buildlets idle for
+	// MinIdle are at least as old.
+	minAge := f.MinAge
+	if f.MinIdle > minAge {
+		minAge = f.MinIdle
+	}
+	resp, err := c.Client.ListInstances(ctx, &protos.ListInstancesRequest{
+		BuilderTypePrefix: f.BuilderTypePrefix,
+		MinAgeSeconds:     int64(minAge / time.Second),
+		LabelSelector:     f.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rbs := make([]RemoteBuildlet, 0, len(resp.GetInstances()))
+	for _, inst := range resp.GetInstances() {
+		rbs = append(rbs, RemoteBuildletFromInstance(inst))
+	}
+	return f.filter(rbs), nil
+}
+
+// RemoteBuildletFromInstance converts a gomote instance description into a
+// RemoteBuildlet.
+func RemoteBuildletFromInstance(inst *protos.Instance) RemoteBuildlet {
+	rb := RemoteBuildlet{
+		HostType:    inst.GetHostType(),
+		BuilderType: inst.GetBuilderType(),
+		Name:        inst.GetGomoteId(),
+		Expires:     time.Unix(inst.GetExpires(), 0),
+	}
+	if inst.GetCreated() != 0 {
+		rb.Created = time.Unix(inst.GetCreated(), 0)
+	}
+	if inst.GetLastActivity() != 0 {
+		rb.LastActivity = time.Unix(inst.GetLastActivity(), 0)
+	}
+	for _, l := range inst.GetLabels() {
+		k, v, _ := strings.Cut(l, "=")
+		if rb.Labels == nil {
+			rb.Labels = make(map[string]string)
+		}
+		rb.Labels[k] = v
+	}
+	return rb
+}
+
 type grpcBuildlet struct {
 	client  protos.GomoteServiceClient
 	id      string
@@ -67,13 +132,19 @@ type grpcBuildlet struct {
 var _ RemoteClient = (*grpcBuildlet)(nil)
 
 func (b *grpcBuildlet) Close() error {
-	_, err := b.client.DestroyInstance(context.TODO(), &protos.DestroyInstanceRequest{
+	return b.CloseContext(context.Background())
+}
+
+func (b *grpcBuildlet) CloseContext(ctx context.Context) error {
+	_, err := b.client.DestroyInstance(ctx, &protos.DestroyInstanceRequest{
 		GomoteId: b.id,
 	})
 	return err
 }
 
 func (b *grpcBuildlet) Exec(ctx context.Context, cmd string, opts ExecOpts) (remoteErr error, execErr error) {
+	ctx, cancel := withCallTimeout(ctx, "Exec", opts.Timeout)
+	defer cancel()
 	stream, err := b.client.ExecuteCommand(ctx, &protos.ExecuteCommandRequest{
 		GomoteId:          b.id,
 		Command:           cmd,
@@ -85,31 +156,59 @@ func (b *grpcBuildlet) Exec(ctx context.Context, cmd string, opts ExecOpts) (rem
 		Args:              opts.Args,
 	})
 	if err != nil {
-		return nil, err
+		return nil, timeoutError(ctx, err)
 	}
 	if opts.OnStartExec != nil {
 		opts.OnStartExec()
 	}
+	out := &execOutput{w: opts.Output, stderr: opts.Stderr, fn: opts.OnOutput, streamFn: opts.OnStream}
+	var exit *protos.ExecuteCommandResponse_ExitStatus
 	for {
 		update, err := stream.Recv()
 		if errors.Is(err, io.EOF) {
 			return nil, nil
 		}
 		if err != nil {
+			if te := callTimedOut(ctx); te != nil {
+				return nil, te
+			}
 			// Execution error.
 			if status.Code(err) == codes.Aborted {
 				return nil, err
 			}
-			// Unknown, presumed command error.
+			// Command error, whose exit status newer servers report
+			// before failing the call.
+			if exit != nil && exit.GetState() != "ok" {
+				return &ExecError{State: exit.GetState(), ExitCode: int(exit.GetExitCode())}, nil
+			}
 			return err, nil
 		}
-		if opts.Output != nil {
-			opts.Output.Write(update.Output)
+		if st := update.GetExitStatus(); st != nil {
+			exit = st
+			continue
+		}
+		if len(update.GetOutput()) == 0 {
+			continue
+		}
+		// The streams of the protocol have the same values as ExecStreams.
+		if err := out.writeStream(ExecStream(update.GetStream()), update.GetOutput()); err != nil {
+			return nil, err
 		}
 	}
 }
 
-func (b *grpcBuildlet) GetTar(ctx context.Context, dir string) (io.ReadCloser, error) {
+func (b *grpcBuildlet) GetTar(ctx context.Context, dir string, opts ...TarOpts) (_ io.ReadCloser, err error) {
+	enc, err := tarEncoding(opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := withCallTimeout(ctx, "GetTar", tarTimeout(opts))
+	defer func() {
+		if err != nil {
+			cancel()
+			err = timeoutError(ctx, err)
+		}
+	}()
 	resp, err := b.client.ReadTGZToURL(ctx, &protos.ReadTGZToURLRequest{
 		GomoteId:  b.id,
 		Directory: dir,
@@ -126,9 +225,15 @@ func (b *grpcBuildlet) GetTar(ctx context.Context, dir string) (io.ReadCloser, e
 		return nil, fmt.Errorf("error fetching tgz: %v", err)
 	}
 	if r.StatusCode != http.StatusOK {
+		r.Body.Close()
 		return nil, fmt.Errorf("unexpected status reading tgz: %v", r.Status)
 	}
-	return r.Body, nil
+	// The gomote server only transfers gzip.
+	rc := r.Body
+	if enc != EncodingGzip {
+		rc = recompressTar(r.Body, EncodingGzip, enc)
+	}
+	return &timeoutReadCloser{rc: rc, ctx: ctx, cancel: cancel}, nil
 }
 
 func (b *grpcBuildlet) ListDir(ctx context.Context, dir string, opts ListDirOpts, fn func(DirEntry)) error {
@@ -162,26 +267,61 @@ func (b *grpcBuildlet) Put(ctx context.Context, r io.Reader, path string, mode o
 	return err
 }
 
-func (b *grpcBuildlet) PutTar(ctx context.Context, r io.Reader, dir string) error {
-	url, err := b.upload(ctx, r)
+func (b *grpcBuildlet) PutTar(ctx context.Context, r io.Reader, dir string, opts ...TarOpts) error {
+	enc, err := tarEncoding(opts)
 	if err != nil {
 		return err
 	}
+	ctx, cancel := withCallTimeout(ctx, "PutTar", tarTimeout(opts))
+	defer cancel()
+	if enc != EncodingGzip {
+		// The gomote server only transfers gzip.
+		rc := recompressTar(r, enc, EncodingGzip)
+		defer rc.Close()
+		r = rc
+	}
+	url, err := b.upload(ctx, r)
+	if err != nil {
+		return timeoutError(ctx, err)
+	}
 	_, err = b.client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{
 		GomoteId:  b.id,
 		Url:       url,
 		Directory: dir,
 	})
+	return timeoutError(ctx, err)
+}
+
+func (b *grpcBuildlet) PutTarFromURL(ctx context.Context, url string, dir string, opts ...TarOpts) error {
+	_, err := b.PutTarFromURLSHA256(ctx, url, dir, "", opts...)
 	return err
 }
 
-func (b *grpcBuildlet) PutTarFromURL(ctx context.Context, url string, dir string) error {
-	_, err := b.client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{
-		GomoteId:  b.id,
-		Url:       url,
-		Directory: dir,
+func (b *grpcBuildlet) PutTarFromURLSHA256(ctx context.Context, url, dir, wantSHA256 string, opts ...TarOpts) (string, error) {
+	ctx, cancel := withCallTimeout(ctx, "PutTarFromURL", tarTimeout(opts))
+	defer cancel()
+	resp, err := b.client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{
+		GomoteId:   b.id,
+		Url:        url,
+		Directory:  dir,
+		Sha256:     wantSHA256,
+		UrlHeaders: urlHeaders(tarHeader(opts)),
 	})
-	return err
+	if err != nil {
+		return "", timeoutError(ctx, err)
+	}
+	return resp.GetSha256(), nil
+}
+
+// urlHeaders returns h as the headers of a request to the gomote server.
+func urlHeaders(h http.Header) []*protos.HTTPHeader {
+	var hs []*protos.HTTPHeader
+	for name, values := range h {
+		for _, v := range values {
+			hs = append(hs, &protos.HTTPHeader{Name: name, Value: v})
+		}
+	}
+	return hs
 }
 
 func (b *grpcBuildlet) upload(ctx context.Context, r io.Reader) (string, error) {
@@ -219,6 +359,10 @@ func (b *grpcBuildlet) upload(ctx context.Context, r io.Reader) (string, error)
 }
 
 func (b *grpcBuildlet) ProxyTCP(port int) (io.ReadWriteCloser, error) {
+	return b.ProxyTCPContext(context.Background(), port)
+}
+
+func (b *grpcBuildlet) ProxyTCPContext(ctx context.Context, port int) (io.ReadWriteCloser, error) {
 	return nil, fmt.Errorf("TCP proxying unimplemented in grpc")
 }
 
@@ -234,6 +378,43 @@ func (b *grpcBuildlet) RemoveAll(ctx context.Context, paths ...string) error {
 	return err
 }
 
+func (b *grpcBuildlet) Tail(ctx context.Context, path string, w io.Writer, opts TailOpts) error {
+	// Cancel the stream if writing to w fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := b.client.TailFile(ctx, &protos.TailFileRequest{
+		GomoteId: b.id,
+		Path:     path,
+		Offset:   opts.Offset,
+		Lines:    int32(opts.Lines),
+		Follow:   opts.Follow,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if update.GetTruncated() {
+			condRun(opts.OnTruncate)
+		}
+		if len(update.GetData()) == 0 {
+			continue
+		}
+		if _, err := w.Write(update.GetData()); err != nil {
+			return err
+		}
+	}
+}
+
 func (b *grpcBuildlet) WorkDir(ctx context.Context) (string, error) {
 	return b.workDir, nil
 }
@@ -16,7 +16,6 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"net"
 	"time"
 )
 
@@ -40,42 +39,36 @@ func (kp KeyPair) Password() string {
 	return ""
 }
 
-// tlsDialer returns a TLS dialer for http.Transport.DialTLS that expects
-// exactly our TLS cert.
-func (kp KeyPair) tlsDialer() func(network, addr string) (net.Conn, error) {
-	if kp.IsZero() {
-		// Unused.
-		return nil
-	}
+// tlsConfig returns a TLS configuration based on base, which may be
+// nil, that expects exactly our TLS cert.
+func (kp KeyPair) tlsConfig(base *tls.Config) *tls.Config {
 	wantCert, _ := tls.X509KeyPair([]byte(kp.CertPEM), []byte(kp.KeyPEM))
 	var wantPubKey *rsa.PublicKey = &wantCert.PrivateKey.(*rsa.PrivateKey).PublicKey
-
-	return func(network, addr string) (net.Conn, error) {
-		if network != "tcp" {
-			return nil, fmt.Errorf("unexpected network %q", network)
+	cfg := base.Clone()
+	if cfg == nil {
+		cfg = new(tls.Config)
+	}
+	// The certificate is self-signed, so check that it's the expected
+	// one instead of verifying its chain.
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) < 1 {
+			return errors.New("no server peer certificate")
 		}
-		plainConn, err := defaultDialer()("tcp", addr)
+		cert, err := x509.ParseCertificate(rawCerts[0])
 		if err != nil {
-			return nil, err
+			return err
 		}
-		tlsConn := tls.Client(plainConn, &tls.Config{InsecureSkipVerify: true})
-		if err := tlsConn.Handshake(); err != nil {
-			return nil, err
-		}
-		certs := tlsConn.ConnectionState().PeerCertificates
-		if len(certs) < 1 {
-			return nil, errors.New("no server peer certificate")
-		}
-		cert := certs[0]
 		peerPubRSA, ok := cert.PublicKey.(*rsa.PublicKey)
 		if !ok {
-			return nil, fmt.Errorf("peer cert was a %T; expected RSA", cert.PublicKey)
+			return fmt.Errorf("peer cert was a %T; expected RSA", cert.PublicKey)
 		}
 		if peerPubRSA.N.Cmp(wantPubKey.N) != 0 {
-			return nil, fmt.Errorf("unexpected TLS certificate")
+			return fmt.Errorf("unexpected TLS certificate")
 		}
-		return tlsConn, nil
+		return nil
 	}
+	return cfg
 }
 
 // NoKeyPair is used by the coordinator to speak http directly to buildlets,
@@ -0,0 +1,162 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"expvar"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// An Observer is told about requests as they start and finish, such as
+// to collect metrics. A Client reports the requests it sends to its
+// buildlet; see Client.SetObserver.
+//
+// Its methods may be called concurrently and should return quickly.
+type Observer interface {
+	// RequestStart is called when a request is sent.
+	RequestStart(r Request)
+	// RequestDone is called when the request is done: its response
+	// has been read or abandoned, or it failed.
+	RequestDone(r Request, s RequestStats)
+}
+
+// Request describes a request reported to an Observer.
+type Request struct {
+	// Method identifies the kind of request. For buildlet requests, it
+	// is the path of the buildlet endpoint, such as "/writetgz",
+	// "/exec" or "/ls".
+	Method string
+
+	// Instance is the name of the buildlet the request is for, if known.
+	Instance string
+
+	// Stream reports whether the request is a streaming gRPC call,
+	// whose messages RequestStats counts.
+	Stream bool
+
+	Start time.Time
+}
+
+// RequestStats describes how a request went.
+type RequestStats struct {
+	Duration      time.Duration
+	BytesSent     int64 // bytes of request body, such as tarball contents
+	BytesReceived int64 // bytes of response body, such as command output
+	// MessagesSent and MessagesReceived count the messages of a
+	// streaming gRPC call. They're zero for other requests.
+	MessagesSent, MessagesReceived int64
+	// QueueWait is the time the call making the request waited for a
+	// slot before sending it; see Client.SetConcurrencyLimits.
+	QueueWait time.Duration
+	// Encoding is the compression of the tarball the request
+	// transferred, such as EncodingGzip or EncodingZstd, or empty if it
+	// didn't transfer one.
+	Encoding string
+	// Err is the error sending the request or reading its response,
+	// or reports an error status from the buildlet.
+	Err error
+}
+
+// Metrics is an Observer which counts requests, errors, bytes and time
+// spent for each method. The counts are available as an expvar.Var.
+type Metrics struct {
+	mu   sync.Mutex // serializes adding methods to vars
+	vars expvar.Map // method -> *expvar.Map of counters
+}
+
+// NewMetrics returns new Metrics, with all counts zero.
+func NewMetrics() *Metrics {
+	m := new(Metrics)
+	m.vars.Init()
+	return m
+}
+
+// Publish publishes the metrics as the expvar variable name, where they
+// appear as a JSON object keyed by method with the counters
+// "requests", "in_flight", "errors", "bytes_sent", "bytes_received",
+// "seconds" and "queue_seconds" for each, and "encoding_gzip" or
+// "encoding_zstd" counting the tarballs transferred with each encoding.
+// Like expvar.Publish, it panics if name is already in use.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, m)
+}
+
+// String returns the counts as JSON, implementing expvar.Var.
+func (m *Metrics) String() string {
+	return m.vars.String()
+}
+
+// Get returns the counter named stat for method, such as
+// "bytes_sent" for "/writetgz", or nil if there is none yet.
+func (m *Metrics) Get(method, stat string) expvar.Var {
+	mm, ok := m.vars.Get(method).(*expvar.Map)
+	if !ok {
+		return nil
+	}
+	return mm.Get(stat)
+}
+
+func (m *Metrics) method(method string) *expvar.Map {
+	if mm, ok := m.vars.Get(method).(*expvar.Map); ok {
+		return mm
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mm, ok := m.vars.Get(method).(*expvar.Map); ok {
+		return mm
+	}
+	mm := new(expvar.Map).Init()
+	for _, stat := range []string{"requests", "in_flight", "errors", "bytes_sent", "bytes_received"} {
+		mm.Set(stat, new(expvar.Int))
+	}
+	mm.Set("seconds", new(expvar.Float))
+	mm.Set("queue_seconds", new(expvar.Float))
+	m.vars.Set(method, mm)
+	return mm
+}
+
+// RequestStart implements Observer.
+func (m *Metrics) RequestStart(r Request) {
+	mm := m.method(r.Method)
+	mm.Add("requests", 1)
+	mm.Add("in_flight", 1)
+}
+
+// RequestDone implements Observer.
+func (m *Metrics) RequestDone(r Request, s RequestStats) {
+	mm := m.method(r.Method)
+	mm.Add("in_flight", -1)
+	if s.Err != nil {
+		mm.Add("errors", 1)
+	}
+	mm.Add("bytes_sent", s.BytesSent)
+	mm.Add("bytes_received", s.BytesReceived)
+	mm.AddFloat("seconds", s.Duration.Seconds())
+	mm.AddFloat("queue_seconds", s.QueueWait.Seconds())
+	if s.Encoding != "" {
+		mm.Add("encoding_"+s.Encoding, 1)
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (cr countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+	return n, err
+}
+
+// countingReadCloser is a countingReader which can be closed.
+type countingReadCloser struct {
+	countingReader
+	io.Closer
+}
@@ -7,6 +7,8 @@ package buildlet
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -41,6 +43,10 @@ type CoordinatorClient struct {
 	// to. If zero, the production coordinator is used.
 	Instance build.CoordinatorInstance
 
+	// TLSConfig optionally specifies the TLS configuration used to
+	// connect to the coordinator. If nil, the Instance's is used.
+	TLSConfig *tls.Config
+
 	mu sync.Mutex
 	hc *http.Client
 }
@@ -52,18 +58,31 @@ func (cc *CoordinatorClient) instance() build.CoordinatorInstance {
 	return cc.Instance
 }
 
+// redact removes cc's password from err's message, including as part
+// of a basic authorization header.
+func (cc *CoordinatorClient) redact(err error) error {
+	if cc.Auth.Password == "" {
+		return err
+	}
+	basic := base64.StdEncoding.EncodeToString([]byte(cc.Auth.Username + ":" + cc.Auth.Password))
+	return redact(err, basic, cc.Auth.Password)
+}
+
 func (cc *CoordinatorClient) client() (*http.Client, error) {
 	cc.mu.Lock()
 	defer cc.mu.Unlock()
 	if cc.hc != nil {
 		return cc.hc, nil
 	}
-	cc.hc = &http.Client{
-		Transport: &http.Transport{
-			Dial:    defaultDialer(),
-			DialTLS: cc.instance().TLSDialer(),
-		},
+	tr := &http.Transport{
+		Dial:    defaultDialer(),
+		DialTLS: cc.instance().TLSDialer(),
 	}
+	if cc.TLSConfig != nil {
+		tr.DialTLS = nil
+		tr.TLSClientConfig = cc.TLSConfig
+	}
+	cc.hc = &http.Client{Transport: tr}
 	return cc.hc, nil
 }
 
@@ -108,12 +127,12 @@ func (cc *CoordinatorClient) CreateBuildletWithStatus(builderType string, status
 	// TODO: accept a context for deadline/cancelation
 	res, err := hc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, cc.redact(err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != 200 {
 		slurp, _ := io.ReadAll(res.Body)
-		return nil, fmt.Errorf("%s: %s", res.Status, slurp)
+		return nil, httpServerError(res.StatusCode, cc.redact(fmt.Errorf("%s: %s", res.Status, slurp)))
 	}
 
 	// TODO: delete this once the server's been deployed with it.
@@ -139,7 +158,7 @@ func (cc *CoordinatorClient) CreateBuildletWithStatus(builderType string, status
 			return nil, err
 		}
 		if m.Error != "" {
-			return nil, errors.New(m.Error)
+			return nil, cc.redact(errors.New(m.Error))
 		}
 		if m.Buildlet != nil {
 			if m.Buildlet.Name == "" {
@@ -164,11 +183,107 @@ func (cc *CoordinatorClient) CreateBuildletWithStatus(builderType string, status
 }
 
 type RemoteBuildlet struct {
-	HostType    string // "host-linux-bullseye"
-	BuilderType string // "linux-386-387"
-	Name        string // "buildlet-adg-openbsd-386-2"
-	Created     time.Time
+	HostType    string    // "host-linux-bullseye"
+	BuilderType string    // "linux-386-387"
+	Name        string    // "buildlet-adg-openbsd-386-2"
+	Created     time.Time // zero if unknown
 	Expires     time.Time
+	Labels      map[string]string // {"cl": "12345"}; nil if none
+
+	// LastActivity is the last time the buildlet ran a command, had
+	// files written or removed, or had an SSH session. It is zero if
+	// unknown.
+	LastActivity time.Time
+}
+
+// LastActive returns the last time rb was used: its last activity, or its
+// creation time if that's unknown. It is zero if both are unknown.
+func (rb RemoteBuildlet) LastActive() time.Time {
+	if !rb.LastActivity.IsZero() {
+		return rb.LastActivity
+	}
+	return rb.Created
+}
+
+// InstanceInfo returns the description of rb as a gomote instance.
+func (rb RemoteBuildlet) InstanceInfo() types.GomoteInstanceInfo {
+	return types.GomoteInstanceInfo{
+		ID:           rb.Name,
+		BuilderType:  rb.BuilderType,
+		HostType:     rb.HostType,
+		Created:      rb.Created,
+		Expires:      rb.Expires,
+		LastActivity: rb.LastActivity,
+		Labels:       rb.Labels,
+	}
+}
+
+// InstanceFilter selects remote buildlets when listing them.
+// The zero value selects all of them.
+//
+// Listings only ever include the caller's own buildlets.
+type InstanceFilter struct {
+	// BuilderTypePrefix, if non-empty, selects buildlets whose
+	// builder type starts with it, such as "linux-arm64".
+	BuilderTypePrefix string
+
+	// MinAge, if positive, selects buildlets created at least MinAge
+	// ago. Buildlets whose creation time is unknown are not selected.
+	MinAge time.Duration
+
+	// MinIdle, if positive, selects buildlets which haven't been used
+	// for at least MinIdle, as reported by LastActive. Buildlets whose
+	// last use is unknown are not selected.
+	MinIdle time.Duration
+
+	// Labels, if non-empty, selects buildlets with all of the labels.
+	// Each selector is either "key=value", selecting buildlets whose
+	// label key has that value, or "key", selecting buildlets with the
+	// label key.
+	Labels []string
+}
+
+// Match reports whether f selects rb at time now.
+func (f InstanceFilter) Match(rb RemoteBuildlet, now time.Time) bool {
+	if !strings.HasPrefix(rb.BuilderType, f.BuilderTypePrefix) {
+		return false
+	}
+	if f.MinAge > 0 && (rb.Created.IsZero() || now.Sub(rb.Created) < f.MinAge) {
+		return false
+	}
+	if f.MinIdle > 0 && (rb.LastActive().IsZero() || now.Sub(rb.LastActive()) < f.MinIdle) {
+		return false
+	}
+	for _, sel := range f.Labels {
+		k, want, hasValue := strings.Cut(sel, "=")
+		if v, ok := rb.Labels[k]; !ok || (hasValue && v != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the buildlets in rbs selected by f.
+func (f InstanceFilter) filter(rbs []RemoteBuildlet) []RemoteBuildlet {
+	now := time.Now()
+	var ret []RemoteBuildlet
+	for _, rb := range rbs {
+		if f.Match(rb, now) {
+			ret = append(ret, rb)
+		}
+	}
+	return ret
+}
+
+// ListBuildlets returns the caller's remote buildlets selected by f.
+// The coordinator can't filter buildlets, so they are filtered by the
+// client.
+func (cc *CoordinatorClient) ListBuildlets(f InstanceFilter) ([]RemoteBuildlet, error) {
+	rbs, err := cc.RemoteBuildlets()
+	if err != nil {
+		return nil, err
+	}
+	return f.filter(rbs), nil
 }
 
 func (cc *CoordinatorClient) RemoteBuildlets() ([]RemoteBuildlet, error) {
@@ -181,12 +296,12 @@ func (cc *CoordinatorClient) RemoteBuildlets() ([]RemoteBuildlet, error) {
 	req.SetBasicAuth(cc.Auth.Username, cc.Auth.Password)
 	res, err := hc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, cc.redact(err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != 200 {
 		slurp, _ := io.ReadAll(res.Body)
-		return nil, fmt.Errorf("%s: %s", res.Status, slurp)
+		return nil, cc.redact(fmt.Errorf("%s: %s", res.Status, slurp))
 	}
 	var ret []RemoteBuildlet
 	if err := json.NewDecoder(res.Body).Decode(&ret); err != nil {
@@ -249,55 +364,68 @@ func configDir() string {
 	return filepath.Join(os.Getenv("HOME"), ".config", "gomote")
 }
 
-// userToken reads the gomote token from the user's home directory.
-func userToken() (string, error) {
-	if gomoteUserFlag == "" {
-		panic("userToken called with user flag empty")
-	}
+// userToken reads the token of user from the user's gomote config
+// directory, for the staging coordinator if staging is set. A
+// user-$USER.user file in the directory may map user to another
+// gomote user, which is returned along with the token.
+func userToken(user string, staging bool) (gomoteUser, token string, err error) {
 	keyDir := configDir()
-	userPath := filepath.Join(keyDir, "user-"+gomoteUserFlag+".user")
+	userPath := filepath.Join(keyDir, "user-"+user+".user")
 	b, err := os.ReadFile(userPath)
 	if err == nil {
-		gomoteUserFlag = string(bytes.TrimSpace(b))
+		user = string(bytes.TrimSpace(b))
 	}
-	baseFile := "user-" + gomoteUserFlag + ".token"
-	if buildenv.FromFlags() == buildenv.Staging {
+	baseFile := "user-" + user + ".token"
+	if staging {
 		baseFile = "staging-" + baseFile
 	}
 	tokenFile := filepath.Join(keyDir, baseFile)
 	slurp, err := os.ReadFile(tokenFile)
 	if os.IsNotExist(err) {
-		return "", fmt.Errorf("Missing file %s for user %q. Change --user or obtain a token and place it there.",
-			tokenFile, gomoteUserFlag)
+		return "", "", fmt.Errorf("Missing file %s for user %q. Change --user or obtain a token and place it there, or set $%s.",
+			tokenFile, user, envToken)
 	}
-	return strings.TrimSpace(string(slurp)), err
+	return user, strings.TrimSpace(string(slurp)), err
 }
 
 // NewCoordinatorClientFromFlags constructs a CoordinatorClient for the current user.
+//
+// Each setting comes from the first of these sources which sets it:
+//   - the "user", "staging" and "localdev" flags, if set explicitly on
+//     the command line;
+//   - the environment variables listed below;
+//   - the credentials file, credentials.json in the user's gomote config
+//     directory.
+//
+// Otherwise, the production coordinator is used, the user is $USER
+// (%USERNAME% on Windows), and the user's token is read from the
+// user-$USER.token file in the gomote config directory. The config
+// directory is $XDG_CONFIG_HOME/gomote or $HOME/.config/gomote, or
+// %APPDATA%\Gomote on Windows.
+//
+// The environment variables are:
+//
+//	GOMOTE_COORDINATOR           coordinator address ("host:port"), "prod" or "staging"
+//	GOMOTE_USER                  gomote user name
+//	GOMOTE_TOKEN                 gomote user token
+//	GOMOTE_CA_CERT               file of PEM CA certificates to verify the coordinator with
+//	GOMOTE_INSECURE_SKIP_VERIFY  if true, don't verify the coordinator's certificate
+//
+// and the credentials file holds a JSON object with the optional
+// fields "coordinator", "user", "token", "caCert" and
+// "insecureSkipVerify", with the same meanings. Passing the token
+// through either avoids exposing it in process listings.
+//
+// Secrets are redacted from the errors returned.
 func NewCoordinatorClientFromFlags() (*CoordinatorClient, error) {
 	if !flagsRegistered {
 		return nil, errors.New("RegisterFlags not called")
 	}
-	inst := build.ProdCoordinator
-	env := buildenv.FromFlags()
-	if env == buildenv.Staging {
-		inst = build.StagingCoordinator
-	} else if env == buildenv.Development {
-		inst = "localhost:8119"
-	}
+	return newCoordinatorClientFrom(flagSettings())
+}
 
-	if gomoteUserFlag == "" {
-		return nil, errors.New("user flag must be specified")
-	}
-	tok, err := userToken()
-	if err != nil {
-		return nil, err
-	}
-	return &CoordinatorClient{
-		Auth: UserPass{
-			Username: "user-" + gomoteUserFlag,
-			Password: tok,
-		},
-		Instance: inst,
-	}, nil
+// NewCoordinatorClientFromEnv is like NewCoordinatorClientFromFlags, but
+// doesn't use flags, so it can be used without calling RegisterFlags.
+func NewCoordinatorClientFromEnv() (*CoordinatorClient, error) {
+	return newCoordinatorClientFrom(coordinatorSettings{})
 }
@@ -0,0 +1,253 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build"
+)
+
+func TestInstanceFilterMatch(t *testing.T) {
+	now := time.Now()
+	rb := RemoteBuildlet{
+		BuilderType: "gotip-linux-amd64",
+		Name:        "user-linux-amd64-0",
+		Created:     now.Add(-time.Hour),
+		Labels:      map[string]string{"cl": "12345", "bot": ""},
+	}
+	unknown := rb
+	unknown.Created = time.Time{}
+	active := rb
+	active.LastActivity = now.Add(-10 * time.Minute)
+	testCases := []struct {
+		desc string
+		f    InstanceFilter
+		rb   RemoteBuildlet
+		want bool
+	}{
+		{"zero", InstanceFilter{}, rb, true},
+		{"zero unknown age", InstanceFilter{}, unknown, true},
+		{"prefix", InstanceFilter{BuilderTypePrefix: "gotip-linux"}, rb, true},
+		{"other prefix", InstanceFilter{BuilderTypePrefix: "gotip-darwin"}, rb, false},
+		{"old enough", InstanceFilter{MinAge: 30 * time.Minute}, rb, true},
+		{"too young", InstanceFilter{MinAge: 2 * time.Hour}, rb, false},
+		{"unknown age", InstanceFilter{MinAge: time.Minute}, unknown, false},
+		{"both", InstanceFilter{BuilderTypePrefix: "gotip-", MinAge: time.Hour}, rb, true},
+		{"idle since created", InstanceFilter{MinIdle: 30 * time.Minute}, rb, true},
+		{"recently active", InstanceFilter{MinIdle: 30 * time.Minute}, active, false},
+		{"idle since active", InstanceFilter{MinIdle: 5 * time.Minute}, active, true},
+		{"old but recently active", InstanceFilter{MinAge: 30 * time.Minute, MinIdle: 30 * time.Minute}, active, false},
+		{"unknown idle", InstanceFilter{MinIdle: time.Minute}, unknown, false},
+		{"label", InstanceFilter{Labels: []string{"cl=12345"}}, rb, true},
+		{"other label value", InstanceFilter{Labels: []string{"cl=1"}}, rb, false},
+		{"label key", InstanceFilter{Labels: []string{"bot"}}, rb, true},
+		{"empty label value", InstanceFilter{Labels: []string{"bot="}}, rb, true},
+		{"missing label", InstanceFilter{Labels: []string{"pipeline"}}, rb, false},
+		{"labels", InstanceFilter{Labels: []string{"cl=12345", "pipeline=x"}}, rb, false},
+	}
+	for _, tc := range testCases {
+		if got := tc.f.Match(tc.rb, now); got != tc.want {
+			t.Errorf("%s: %+v.Match = %t; want %t", tc.desc, tc.f, got, tc.want)
+		}
+	}
+}
+
+func TestNewCoordinatorClientFrom(t *testing.T) {
+	const credentials = `{"coordinator": "file.example:443", "user": "file-user", "token": "file-token", "insecureSkipVerify": true}`
+	testCases := []struct {
+		desc  string
+		flags coordinatorSettings
+		env   map[string]string
+		file  string // contents of the credentials file, if any
+
+		wantInstance build.CoordinatorInstance
+		wantUser     string
+		wantToken    string
+		wantInsecure bool
+	}{
+		{
+			desc:         "defaults",
+			wantInstance: build.ProdCoordinator,
+			wantUser:     "user-gopher",
+			wantToken:    "gopher-token",
+		},
+		{
+			desc:         "staging token file",
+			flags:        coordinatorSettings{Coordinator: string(build.StagingCoordinator)},
+			wantInstance: build.StagingCoordinator,
+			wantUser:     "user-gopher",
+			wantToken:    "staging-gopher-token",
+		},
+		{
+			desc:         "file",
+			file:         credentials,
+			wantInstance: "file.example:443",
+			wantUser:     "user-file-user",
+			wantToken:    "file-token",
+			wantInsecure: true,
+		},
+		{
+			desc: "env over file",
+			env: map[string]string{
+				envCoordinator: "staging",
+				envUser:        "env-user",
+				envToken:       "env-token",
+			},
+			file:         credentials,
+			wantInstance: build.StagingCoordinator,
+			wantUser:     "user-env-user",
+			wantToken:    "env-token",
+			wantInsecure: true,
+		},
+		{
+			desc:         "env insecure over file",
+			env:          map[string]string{envInsecure: "false"},
+			file:         credentials,
+			wantInstance: "file.example:443",
+			wantUser:     "user-file-user",
+			wantToken:    "file-token",
+		},
+		{
+			desc:  "flags over env",
+			flags: coordinatorSettings{Coordinator: "localhost:8119", User: "flag-user"},
+			env: map[string]string{
+				envCoordinator: "staging",
+				envUser:        "env-user",
+				envToken:       "env-token",
+			},
+			file:         credentials,
+			wantInstance: "localhost:8119",
+			wantUser:     "user-flag-user",
+			wantToken:    "env-token",
+			wantInsecure: true,
+		},
+		{
+			desc:         "flag user token file",
+			flags:        coordinatorSettings{User: "gopher"},
+			env:          map[string]string{envUser: "env-user"},
+			wantInstance: build.ProdCoordinator,
+			wantUser:     "user-gopher",
+			wantToken:    "gopher-token",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := setupConfigDir(t)
+			if tc.file != "" {
+				writeFile(t, filepath.Join(dir, credentialsFile), tc.file)
+			}
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			cc, err := newCoordinatorClientFrom(tc.flags)
+			if err != nil {
+				t.Fatalf("newCoordinatorClientFrom: %v", err)
+			}
+			if cc.Instance != tc.wantInstance || cc.Auth.Username != tc.wantUser || cc.Auth.Password != tc.wantToken {
+				t.Errorf("got instance %q, user %q, token %q; want %q, %q, %q",
+					cc.Instance, cc.Auth.Username, cc.Auth.Password, tc.wantInstance, tc.wantUser, tc.wantToken)
+			}
+			if insecure := cc.TLSConfig != nil && cc.TLSConfig.InsecureSkipVerify; insecure != tc.wantInsecure {
+				t.Errorf("InsecureSkipVerify = %t; want %t", insecure, tc.wantInsecure)
+			}
+		})
+	}
+}
+
+func TestNewCoordinatorClientFromErrors(t *testing.T) {
+	dir := setupConfigDir(t)
+	notPEM := filepath.Join(dir, "ca.pem")
+	writeFile(t, notPEM, "not a certificate")
+	for _, tc := range []struct {
+		desc string
+		env  map[string]string
+		file string
+	}{
+		{"invalid insecure", map[string]string{envInsecure: "maybe"}, ""},
+		{"no CA certificates", map[string]string{envCACert: notPEM}, ""},
+		{"missing CA certificates", map[string]string{envCACert: filepath.Join(dir, "missing.pem")}, ""},
+		{"missing token file", map[string]string{envUser: "nobody"}, ""},
+		{"bad credentials file", nil, `{"user": "file-user", "token": 42}`},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			name := filepath.Join(dir, credentialsFile)
+			if tc.file != "" {
+				writeFile(t, name, tc.file)
+				t.Cleanup(func() { os.Remove(name) })
+			}
+			if _, err := newCoordinatorClientFrom(coordinatorSettings{}); err == nil {
+				t.Errorf("newCoordinatorClientFrom succeeded; want error")
+			}
+		})
+	}
+}
+
+func TestCoordinatorClientRedactsToken(t *testing.T) {
+	const token = "s3cret-token"
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo the credentials, as a misbehaving server or proxy might.
+		http.Error(w, "bad credentials: "+r.Header.Get("Authorization"), http.StatusForbidden)
+	}))
+	defer ts.Close()
+	setupConfigDir(t)
+	t.Setenv(envCoordinator, strings.TrimPrefix(ts.URL, "https://"))
+	t.Setenv(envToken, token)
+	t.Setenv(envInsecure, "true")
+	cc, err := NewCoordinatorClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewCoordinatorClientFromEnv: %v", err)
+	}
+	_, err = cc.RemoteBuildlets()
+	if err == nil {
+		t.Fatalf("RemoteBuildlets succeeded; want error")
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(cc.Auth.Username + ":" + token))
+	if !strings.Contains(err.Error(), "403") {
+		t.Errorf("RemoteBuildlets error = %q; want a 403 error", err)
+	}
+	if strings.Contains(err.Error(), encoded) {
+		t.Errorf("RemoteBuildlets error = %q; contains the credentials", err)
+	}
+}
+
+// setupConfigDir points the gomote config directory at a new temporary
+// directory with token files for the user "gopher", the current user,
+// and clears the environment variables with settings.
+func setupConfigDir(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", base)
+	t.Setenv("APPDATA", base)
+	t.Setenv("USER", "gopher")
+	t.Setenv("USERNAME", "gopher")
+	for _, k := range []string{envCoordinator, envUser, envToken, envCACert, envInsecure} {
+		t.Setenv(k, "")
+	}
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "user-gopher.token"), "gopher-token\n")
+	writeFile(t, filepath.Join(dir, "staging-user-gopher.token"), "staging-gopher-token\n")
+	return dir
+}
+
+func writeFile(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
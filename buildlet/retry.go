@@ -0,0 +1,96 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// A RetryPolicy controls how calls which fail transiently, such as
+// because a proxy in front of the buildlet briefly returned 502 Bad
+// Gateway, are retried. See Client.SetRetryPolicy.
+//
+// Only calls which are safe to repeat are ever retried: Status, WorkDir,
+// ListDir and RemoveAll. Calls which change the buildlet's state in
+// other ways, such as Exec, Put, PutTar, PutTarFromURL and RemoveGlob,
+// never are.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts of a call, including
+	// the first. Values below 2 disable retrying.
+	Attempts int
+
+	// Backoff is the delay before the first retry, which doubles before
+	// each further one. It defaults to 100ms. MaxBackoff, if positive,
+	// bounds the delay.
+	Backoff, MaxBackoff time.Duration
+
+	// StatusCodes are the HTTP status codes of responses which are
+	// retried. Requests which fail because of a broken connection are
+	// always retried.
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy is the retry policy of interactive tools such as
+// gomote. Clients don't retry calls unless they're given a policy.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	Backoff:    200 * time.Millisecond,
+	MaxBackoff: 2 * time.Second,
+	StatusCodes: []int{
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// Retry calls fn until it succeeds, it fails with an error for which
+// retryable reports false, p.Attempts attempts were made, or ctx is
+// done. It returns fn's last error.
+func (p RetryPolicy) Retry(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.Attempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		backoff *= 2
+		if p.MaxBackoff > 0 {
+			backoff = min(backoff, p.MaxBackoff)
+		}
+	}
+}
+
+// retryable reports whether a request which failed with err may
+// succeed if retried.
+func (p RetryPolicy) retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return slices.Contains(p.StatusCodes, se.code)
+	}
+	return isConnError(err)
+}
+
+// statusError is the error for a response from the buildlet with an
+// unexpected HTTP status code.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
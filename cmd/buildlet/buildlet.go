@@ -18,7 +18,9 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -34,6 +36,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -44,6 +47,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/gliderlabs/ssh"
+	"github.com/klauspost/compress/zstd"
 	"golang.org/x/build/buildlet"
 	"golang.org/x/build/internal/cloud"
 	"golang.org/x/build/internal/envutil"
@@ -88,7 +92,15 @@ var (
 //	26: clean up path validation and normalization
 //	27: export GOPLSCACHE=$workdir/goplscache
 //	28: add support for gomote server
-const buildletVersion = 28
+//	29: ls patterns and maximum depth
+//	30: writetgz SHA-256 verification of URL downloads
+//	31: removeall patterns
+//	32: tail
+//	33: status reports the buildlet's time
+//	34: zstd-compressed tarballs
+//	35: status reports disk, memory, load and workdir usage
+//	36: writetgz headers of URL downloads
+const buildletVersion = 36
 
 func defaultListenAddr() string {
 	if runtime.GOOS == "darwin" {
@@ -113,6 +125,7 @@ func defaultListenAddr() string {
 var (
 	configureSerialLogOutput func()
 	setOSRlimit              func() error
+	fillMachineStatus        func(dir string, st *buildlet.Status)
 )
 
 // If non-empty, the $TMPDIR, $GOCACHE, and $GOPLSCACHE environment
@@ -252,6 +265,7 @@ func main() {
 	http.Handle("/workdir", requireAuth(handleWorkDir))
 	http.Handle("/status", requireAuth(handleStatus))
 	http.Handle("/ls", requireAuth(handleLs))
+	http.Handle("/tail", requireAuth(handleTail))
 	http.Handle("/connect-ssh", requireAuth(handleConnectSSH))
 	http.HandleFunc("/healthz", handleHealthz)
 
@@ -570,7 +584,18 @@ func handleGetTGZ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	zw := pargzip.NewWriter(w)
+	var zw io.WriteCloser
+	if acceptsEncoding(r, buildlet.EncodingZstd) {
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Encoding", buildlet.EncodingZstd)
+		zw = enc
+	} else {
+		zw = pargzip.NewWriter(w)
+	}
 	tw := tar.NewWriter(zw)
 	base := filepath.Join(*workDir, dir)
 	err = filepath.Walk(base, func(path string, fi os.FileInfo, err error) error {
@@ -619,6 +644,20 @@ func handleGetTGZ(w http.ResponseWriter, r *http.Request) {
 	zw.Close()
 }
 
+// acceptsEncoding reports whether the client sending r accepts
+// responses with the content encoding enc.
+func acceptsEncoding(r *http.Request, enc string) bool {
+	for _, v := range r.Header.Values("Accept-Encoding") {
+		for _, e := range strings.Split(v, ",") {
+			e, _, _ = strings.Cut(e, ";")
+			if strings.TrimSpace(e) == enc {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func handleWriteTGZ(w http.ResponseWriter, r *http.Request) {
 	if !mkdirAllWorkdirOr500(w) {
 		return
@@ -653,11 +692,17 @@ func handleWriteTGZ(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var tgz io.Reader
+	encoding := buildlet.EncodingGzip
 	var urlStr string
+	var wantSHA256 string
+	hash := sha256.New()
 	switch r.Method {
 	case "PUT":
 		tgz = r.Body
-		log.Printf("writetgz: untarring Request.Body into %s", baseDir)
+		if enc := r.Header.Get("Content-Encoding"); enc != "" {
+			encoding = enc
+		}
+		log.Printf("writetgz: untarring %s Request.Body into %s", encoding, baseDir)
 	case "POST":
 		urlStr = r.FormValue("url")
 		if urlStr == "" {
@@ -665,8 +710,27 @@ func handleWriteTGZ(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "missing url POST param", http.StatusBadRequest)
 			return
 		}
+		wantSHA256 = strings.ToLower(r.FormValue("sha256"))
+		if _, err := hex.DecodeString(wantSHA256); err != nil || len(wantSHA256) != 0 && len(wantSHA256) != 2*sha256.Size {
+			log.Printf("writetgz: bogus sha256 %q", wantSHA256)
+			http.Error(w, "invalid 'sha256' parameter: want a hex-encoded SHA-256 digest", http.StatusBadRequest)
+			return
+		}
+		header, err := parseHeaderParams(r.Form["header"])
+		if err != nil {
+			log.Printf("writetgz: %v", err)
+			http.Error(w, "invalid 'header' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req, err := http.NewRequestWithContext(r.Context(), "GET", urlStr, nil)
+		if err != nil {
+			log.Printf("writetgz: bogus url %q: %v", urlStr, err)
+			http.Error(w, "invalid 'url' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Header = header
 		t0 := time.Now()
-		res, err := http.Get(urlStr)
+		res, err := http.DefaultClient.Do(req)
 		if err != nil {
 			log.Printf("writetgz: failed to fetch tgz URL %s: %v", urlStr, err)
 			http.Error(w, fmt.Sprintf("fetching URL %s: %v", urlStr, err), http.StatusInternalServerError)
@@ -678,22 +742,104 @@ func handleWriteTGZ(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("writetgz: fetching provided URL %q: %s", urlStr, res.Status), http.StatusInternalServerError)
 			return
 		}
-		tgz = res.Body
-		log.Printf("writetgz: untarring %s (got headers in %v) into %s", urlStr, time.Since(t0), baseDir)
+		tgz = io.TeeReader(res.Body, hash)
+		log.Printf("writetgz: untarring %s (sent headers %v, got headers in %v) into %s", urlStr, headerNames(header), time.Since(t0), baseDir)
 	default:
 		log.Printf("writetgz: invalid method %q", r.Method)
 		http.Error(w, "requires PUT or POST method", http.StatusBadRequest)
 		return
 	}
 
-	err := untar(tgz, baseDir)
-	if err != nil {
+	destDir := baseDir
+	if wantSHA256 != "" {
+		// Extract into a staging directory first, so that nothing
+		// is left behind in baseDir if the digest doesn't match.
+		staging, err := os.MkdirTemp(*workDir, ".writetgz-")
+		if err != nil {
+			http.Error(w, "creating staging directory: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer removeAllIncludingReadonly(staging)
+		destDir = staging
+	}
+	if err := untar(tgz, encoding, destDir); err != nil {
 		http.Error(w, err.Error(), httpStatus(err))
 		return
 	}
+	if r.Method == "POST" {
+		// Hash any trailing data untar didn't need to read.
+		if _, err := io.Copy(io.Discard, tgz); err != nil {
+			http.Error(w, fmt.Sprintf("fetching URL %s: %v", urlStr, err), http.StatusInternalServerError)
+			return
+		}
+		got := hex.EncodeToString(hash.Sum(nil))
+		if wantSHA256 != "" && got != wantSHA256 {
+			log.Printf("writetgz: SHA-256 mismatch for %s: got %s, want %s", urlStr, got, wantSHA256)
+			http.Error(w, fmt.Sprintf("SHA-256 mismatch for %s: got %s, want %s", urlStr, got, wantSHA256), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(hdrTgzSHA256, got)
+	}
+	if destDir != baseDir {
+		if err := moveTree(destDir, baseDir); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 	io.WriteString(w, "OK")
 }
 
+// hdrTgzSHA256 is an HTTP header set by the /writetgz handler to the
+// hex-encoded SHA-256 digest of a tarball downloaded from a URL.
+const hdrTgzSHA256 = "X-Go-Tgz-Sha256"
+
+// moveTree moves the contents of the directory src into the directory
+// dst, replacing any files that already exist there.
+// parseHeaderParams parses the "header" parameters of writetgz, each of
+// the form "Name: value", into the headers sent with the request fetching
+// its URL.
+func parseHeaderParams(params []string) (http.Header, error) {
+	h := make(http.Header)
+	for _, p := range params {
+		name, value, ok := strings.Cut(p, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			// Don't report p, whose value may be a credential.
+			return nil, errors.New(`want headers of the form "Name: value"`)
+		}
+		h.Add(name, strings.TrimSpace(value))
+	}
+	return h, nil
+}
+
+// headerNames returns the sorted names of the headers in h, for logging
+// them without their values.
+func headerNames(h http.Header) []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func moveTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return os.Rename(path, target)
+	})
+}
+
 func handleWrite(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "PUT" {
 		http.Error(w, "requires POST method", http.StatusBadRequest)
@@ -761,8 +907,9 @@ func writeFile(r io.Reader, path string, mode os.FileMode) error {
 	return f.Close()
 }
 
-// untar reads the gzip-compressed tar file from r and writes it into dir.
-func untar(r io.Reader, dir string) (err error) {
+// untar reads the tar file from r, compressed with encoding, and writes
+// it into dir.
+func untar(r io.Reader, encoding, dir string) (err error) {
 	t0 := time.Now()
 	nFiles := 0
 	madeDir := map[string]bool{}
@@ -774,9 +921,23 @@ func untar(r io.Reader, dir string) (err error) {
 			log.Printf("error extracting tarball into %s after %d files, %d dirs, %v: %v", dir, nFiles, len(madeDir), td, err)
 		}
 	}()
-	zr, err := gzip.NewReader(r)
-	if err != nil {
-		return badRequestf("requires gzip-compressed body: %w", err)
+	var zr io.Reader
+	switch encoding {
+	case buildlet.EncodingGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return badRequestf("requires gzip-compressed body: %w", err)
+		}
+		zr = gr
+	case buildlet.EncodingZstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return badRequestf("requires zstd-compressed body: %w", err)
+		}
+		defer dec.Close()
+		zr = dec
+	default:
+		return badRequestf("unsupported Content-Encoding %q", encoding)
 	}
 	tr := tar.NewReader(zr)
 	loggedChtimesError := false
@@ -879,6 +1040,11 @@ func untar(r io.Reader, dir string) (err error) {
 // on success, or os.ProcessState.String() on failure.
 const hdrProcessState = "Process-State"
 
+// Process-Exit-Code is an HTTP Trailer set in the /exec handler to the
+// exit code of the command, or -1 if it was killed by a signal or
+// couldn't be started.
+const hdrExitCode = "Process-Exit-Code"
+
 func handleExec(w http.ResponseWriter, r *http.Request) {
 	cn := w.(http.CloseNotifier)
 	clientGone := cn.CloseNotify()
@@ -912,10 +1078,14 @@ func handleExec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Trailer", hdrProcessState) // declare it so we can set it
+	w.Header().Set("Trailer", hdrProcessState+", "+hdrExitCode) // declare them so we can set them
 
 	sysMode := r.FormValue("mode") == "sys"
 	debug, _ := strconv.ParseBool(r.FormValue("debug"))
+	streams, _ := strconv.ParseBool(r.FormValue("streams"))
+	if streams {
+		w.Header().Set(buildlet.ExecStreamsHeader, "1")
+	}
 
 	absCmd, err := absExecCmd(r.FormValue("cmd"), sysMode) // required
 	if err != nil {
@@ -969,15 +1139,22 @@ func handleExec(w http.ResponseWriter, r *http.Request) {
 	cmd.Args = append(cmd.Args, r.PostForm["cmdArg"]...)
 	cmd.Env = env
 	envutil.SetDir(cmd, absDir)
-	cmdOutput := flushWriter{w}
+	var cmdOutput io.Writer = flushWriter{w}
 	cmd.Stdout = cmdOutput
 	cmd.Stderr = cmdOutput
+	sysOutput := cmdOutput
+	if streams {
+		sw := buildlet.NewExecStreamWriter(cmdOutput)
+		cmd.Stdout = sw.Stream(buildlet.ExecStdout)
+		cmd.Stderr = sw.Stream(buildlet.ExecStderr)
+		sysOutput = sw.Stream(buildlet.ExecSystem)
+	}
 
 	log.Printf("[%p] Running %s with args %q and env %q in dir %s",
 		cmd, cmd.Path, cmd.Args, cmd.Env, cmd.Dir)
 
 	if debug {
-		fmt.Fprintf(cmdOutput, ":: Running %s with args %q and env %q in dir %s\n\n",
+		fmt.Fprintf(sysOutput, ":: Running %s with args %q and env %q in dir %s\n\n",
 			cmd.Path, cmd.Args, cmd.Env, cmd.Dir)
 	}
 
@@ -997,15 +1174,16 @@ func handleExec(w http.ResponseWriter, r *http.Request) {
 		}()
 		err = cmd.Wait()
 	}
-	state := "ok"
+	state, exitCode := "ok", 0
 	if err != nil {
 		if ps := cmd.ProcessState; ps != nil {
-			state = ps.String()
+			state, exitCode = ps.String(), ps.ExitCode()
 		} else {
-			state = err.Error()
+			state, exitCode = err.Error(), -1
 		}
 	}
 	w.Header().Set(hdrProcessState, state)
+	w.Header().Set(hdrExitCode, strconv.Itoa(exitCode))
 	log.Printf("[%p] Run = %s, after %v", cmd, state, time.Since(t0))
 }
 
@@ -1301,6 +1479,10 @@ func handleRemoveAll(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if len(r.Form["pattern"]) > 0 {
+		removeGlob(w, r)
+		return
+	}
 	paths := r.Form["path"]
 	if len(paths) == 0 {
 		http.Error(w, "requires 'path' parameter", http.StatusBadRequest)
@@ -1314,31 +1496,147 @@ func handleRemoveAll(w http.ResponseWriter, r *http.Request) {
 	}
 	for _, p := range paths {
 		log.Printf("Removing %s", p)
-		fullDir := filepath.Join(*workDir, filepath.FromSlash(p))
-		err := removeAllIncludingReadonly(fullDir)
-		if p == "." && err != nil {
-			// If workDir is a mountpoint and/or contains a binary
-			// using it, we can get a "Device or resource busy" error.
-			// See if it's now empty and ignore the error.
-			if f, oerr := os.Open(*workDir); oerr == nil {
-				if all, derr := f.Readdirnames(-1); derr == nil && len(all) == 0 {
-					log.Printf("Ignoring fail of RemoveAll(.)")
-					err = nil
-				} else {
-					log.Printf("Readdir = %q, %v", all, derr)
-				}
-				f.Close()
+		if err := removeWorkPath(p); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// removeWorkPath removes p, a relative path validated by nativeRelPath,
+// from the work directory.
+func removeWorkPath(p string) error {
+	fullDir := filepath.Join(*workDir, filepath.FromSlash(p))
+	err := removeAllIncludingReadonly(fullDir)
+	if p == "." && err != nil {
+		// If workDir is a mountpoint and/or contains a binary
+		// using it, we can get a "Device or resource busy" error.
+		// See if it's now empty and ignore the error.
+		if f, oerr := os.Open(*workDir); oerr == nil {
+			if all, derr := f.Readdirnames(-1); derr == nil && len(all) == 0 {
+				log.Printf("Ignoring fail of RemoveAll(.)")
+				err = nil
 			} else {
-				log.Printf("Failed to open workdir: %v", oerr)
+				log.Printf("Readdir = %q, %v", all, derr)
 			}
+			f.Close()
+		} else {
+			log.Printf("Failed to open workdir: %v", oerr)
 		}
-		if err != nil {
+	}
+	return err
+}
+
+// removeGlob handles a /removeall request with 'pattern' parameters,
+// which removes the entries under the 'dir' parameter matching any of
+// them, as with the 'pattern' parameters of /ls. A pattern of "."
+// removes dir itself. Unless the 'force' parameter is true, patterns
+// which match arbitrary names, such as "*" or "[a-z]*", and "." are
+// refused. The
+// number of entries removed, not counting those within removed
+// directories, is set in the hdrRemoved header.
+func removeGlob(w http.ResponseWriter, r *http.Request) {
+	dir := r.FormValue("dir")
+	if dir == "" {
+		dir = "."
+	}
+	if _, err := nativeRelPath(dir); err != nil {
+		http.Error(w, "invalid 'dir' parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	force, _ := strconv.ParseBool(r.FormValue("force"))
+	patterns := r.Form["pattern"]
+	removeDir := false
+	for _, pat := range patterns {
+		if _, err := path.Match(pat, ""); err != nil {
+			http.Error(w, fmt.Sprintf("invalid 'pattern' parameter %q: %v", pat, err), http.StatusBadRequest)
+			return
+		}
+		if path.Clean(pat) == "." {
+			removeDir = true
+		}
+		if !force && (path.Clean(pat) == "." || matchesAnyName(pat)) {
+			http.Error(w, fmt.Sprintf("refusing to remove everything in %q with pattern %q without 'force' parameter", dir, pat), http.StatusBadRequest)
+			return
+		}
+	}
+
+	log.Printf("Removing entries of %s matching %q", dir, patterns)
+	if removeDir {
+		if err := removeWorkPath(dir); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		w.Header().Set(hdrRemoved, "1")
+		return
+	}
+	removed := 0
+	base := filepath.Join(*workDir, filepath.FromSlash(dir))
+	opts := buildlet.ListDirOpts{Patterns: patterns}
+	err := filepath.Walk(base, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if path == base && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(path, base)), "/")
+		if rel == "" || !opts.Includes(rel) {
+			return nil
+		}
+		if err := removeAllIncludingReadonly(path); err != nil {
+			return err
+		}
+		removed++
+		if fi.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set(hdrRemoved, strconv.Itoa(removed))
+}
+
+// matchesAnyName reports whether the path.Match pattern pat matches
+// arbitrary names in the directories it selects, such as "*", "go/*",
+// "?*" or "[a-z]*": that is, whether its last element has no literal
+// character, which every name it matches must contain. Character
+// classes aren't literal, however narrow.
+func matchesAnyName(pat string) bool {
+	return !hasLiteral(path.Base(path.Clean(pat)))
+}
+
+// hasLiteral reports whether the path.Match pattern elem, which has
+// no '/', has a character outside of its wildcards and character
+// classes.
+func hasLiteral(elem string) bool {
+	for i := 0; i < len(elem); i++ {
+		switch elem[i] {
+		case '*', '?':
+		case '\\':
+			return true
+		case '[':
+			// Skip the class up to its closing bracket. path.Match
+			// already validated it.
+			for i++; i < len(elem) && elem[i] != ']'; i++ {
+				if elem[i] == '\\' {
+					i++
+				}
+			}
+		default:
+			return true
+		}
+	}
+	return false
 }
 
+// hdrRemoved is an HTTP header set by the /removeall handler to the
+// number of entries matching the 'pattern' parameters it removed.
+const hdrRemoved = "X-Go-Removed"
+
 // mkdirAllWorkdirOr500 reports whether *workDir either exists or was created.
 // If it returns false, it also writes an HTTP 500 error to w.
 // This is used by callers to verify *workDir exists, even if it might've been
@@ -1365,7 +1663,15 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	status := buildlet.Status{
-		Version: buildletVersion,
+		Version:   buildletVersion,
+		Time:      time.Now(),
+		Encodings: []string{buildlet.EncodingZstd},
+	}
+	if fillMachineStatus != nil {
+		fillMachineStatus(*workDir, &status)
+	}
+	if detailed, _ := strconv.ParseBool(r.FormValue("detailed")); detailed {
+		status.WorkDirSize = dirSize(*workDir)
 	}
 	b, err := json.Marshal(status)
 	if err != nil {
@@ -1376,6 +1682,23 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+// dirSize returns the total size of the regular files in dir. Files
+// which can't be read, such as because a build removed them during the
+// walk, are skipped.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size
+}
+
 func handleLs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "requires GET method", http.StatusBadRequest)
@@ -1395,6 +1718,15 @@ func handleLs(w http.ResponseWriter, r *http.Request) {
 	recursive, _ := strconv.ParseBool(r.FormValue("recursive"))
 	digest, _ := strconv.ParseBool(r.FormValue("digest"))
 	skip := r.Form["skip"] // '/'-separated relative dirs
+	opts := buildlet.ListDirOpts{Patterns: r.Form["pattern"]}
+	if v := r.FormValue("maxdepth"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid 'maxdepth' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.MaxDepth = n
+	}
 
 	if !mkdirAllWorkdirOr500(w) {
 		return
@@ -1417,6 +1749,18 @@ func handleLs(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		}
+		// Whether to list the directory's entries, if it is one.
+		descend := recursive && (opts.MaxDepth <= 0 || strings.Count(rel, "/")+1 < opts.MaxDepth)
+		name := rel
+		if fi.IsDir() {
+			name += "/"
+		}
+		if !opts.Includes(name) {
+			if fi.IsDir() && !descend {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		anyOutput = true
 		fmt.Fprintf(w, "%s\t%s", fi.Mode(), rel)
 		if fi.Mode().IsRegular() {
@@ -1432,7 +1776,7 @@ func handleLs(w http.ResponseWriter, r *http.Request) {
 			io.WriteString(w, "/")
 		}
 		io.WriteString(w, "\n")
-		if fi.IsDir() && !recursive {
+		if fi.IsDir() && !descend {
 			return filepath.SkipDir
 		}
 		return nil
@@ -1451,6 +1795,143 @@ func handleLs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// tailPollInterval is how often a followed file is checked for
+// appended data.
+var tailPollInterval = 500 * time.Millisecond
+
+// hdrTailState is an HTTP trailer set by the /tail handler once the
+// whole file was sent, to "eof", or once the file was truncated or
+// replaced while being followed, to "truncated".
+const hdrTailState = "Tail-State"
+
+// handleTail sends the contents of the file named by the 'path'
+// parameter, starting at the 'offset' parameter or, if the 'lines'
+// parameter is positive, at the start of that many last lines. If the
+// 'follow' parameter is true, it then sends data appended to the file
+// until the request is canceled, or the file is truncated or replaced.
+func handleTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "requires GET method", http.StatusBadRequest)
+		return
+	}
+	p, err := nativeRelPath(r.FormValue("path"))
+	if err != nil {
+		http.Error(w, "invalid 'path' parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var offset int64
+	if v := r.FormValue("offset"); v != "" {
+		offset, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			http.Error(w, "invalid 'offset' parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	var lines int
+	if v := r.FormValue("lines"); v != "" {
+		lines, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid 'lines' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	follow, _ := strconv.ParseBool(r.FormValue("follow"))
+
+	name := filepath.Join(*workDir, filepath.FromSlash(p))
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !fi.Mode().IsRegular() {
+		http.Error(w, fmt.Sprintf("%s is not a regular file", p), http.StatusBadRequest)
+		return
+	}
+	if lines > 0 {
+		offset, err = lastLinesOffset(f, fi.Size(), lines)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Trailer", hdrTailState)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	if offset > fi.Size() {
+		// The file shrank since the caller last read it.
+		w.Header().Set(hdrTailState, "truncated")
+		return
+	}
+	for {
+		n, err := io.Copy(w, f)
+		if err != nil {
+			log.Printf("tail of %s: %v", p, err)
+			return
+		}
+		if n > 0 {
+			w.(http.Flusher).Flush()
+		}
+		if !follow {
+			w.Header().Set(hdrTailState, "eof")
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(tailPollInterval):
+		}
+		pos, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			log.Printf("tail of %s: %v", p, err)
+			return
+		}
+		// The file may be missing briefly while it's being replaced;
+		// keep following the old one until a new one appears.
+		if cur, err := os.Stat(name); err == nil && (!os.SameFile(fi, cur) || cur.Size() < pos) {
+			w.Header().Set(hdrTailState, "truncated")
+			return
+		}
+	}
+}
+
+// lastLinesOffset returns the offset in f, a file of the given size, at
+// which its last n lines start. A final newline doesn't start a line.
+func lastLinesOffset(f io.ReaderAt, size int64, n int) (int64, error) {
+	buf := make([]byte, 32<<10)
+	for end := size; end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		b := buf[:end-start]
+		if _, err := f.ReadAt(b, start); err != nil {
+			return 0, err
+		}
+		for i := len(b) - 1; i >= 0; i-- {
+			if pos := start + int64(i); b[i] == '\n' && pos != size-1 {
+				n--
+				if n == 0 {
+					return pos + 1, nil
+				}
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
 func useBuildletSSHServer() bool {
 	return *swarmingBot && runtime.GOOS != "plan9"
 }
@@ -5,9 +5,29 @@
 package main
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/build/buildlet"
 )
 
 func TestPathEnv(t *testing.T) {
@@ -110,3 +130,489 @@ func TestPathListSeparator(t *testing.T) {
 		t.Errorf("pathListSeparator(%q) = %q; want %q", runtime.GOOS, sep, want)
 	}
 }
+
+func TestWriteTGZFromURLSHA256(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "dir/file", Typeflag: tar.TypeReg, Mode: 0644, Size: 5})
+	tw.Write([]byte("hello"))
+	tw.Close()
+	zw.Close()
+	tgz := buf.Bytes()
+	sum := sha256.Sum256(tgz)
+	digest := hex.EncodeToString(sum[:])
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tgz)
+	}))
+	defer src.Close()
+
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = t.TempDir()
+	writeTGZ := func(dir, sha256 string) *httptest.ResponseRecorder {
+		form := url.Values{"url": {src.URL}, "sha256": {sha256}}
+		req := httptest.NewRequest("POST", "/writetgz?dir="+dir, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handleWriteTGZ(rec, req)
+		return rec
+	}
+
+	rec := writeTGZ("bad", strings.Repeat("0", 64))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("writetgz with the wrong digest: status %d; want %d", rec.Code, http.StatusBadRequest)
+	}
+	entries, err := os.ReadDir(*workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "bad" {
+			t.Errorf("writetgz with the wrong digest left %s behind in the work dir", e.Name())
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Join(*workDir, "bad")); len(entries) != 0 {
+		t.Errorf("writetgz with the wrong digest extracted %d entries", len(entries))
+	}
+
+	for _, want := range []string{digest, ""} {
+		rec = writeTGZ("good", want)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("writetgz with digest %q: status %d: %s", want, rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get(hdrTgzSHA256); got != digest {
+			t.Errorf("writetgz with digest %q: %s = %q; want %q", want, hdrTgzSHA256, got, digest)
+		}
+		if b, err := os.ReadFile(filepath.Join(*workDir, "good", "dir", "file")); err != nil || string(b) != "hello" {
+			t.Errorf("writetgz with digest %q: extracted file = %q, %v; want %q", want, b, err, "hello")
+		}
+	}
+}
+
+func TestWriteTGZFromURLHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	tw.WriteHeader(&tar.Header{Name: "file", Typeflag: tar.TypeReg, Mode: 0644, Size: 5})
+	tw.Write([]byte("hello"))
+	tw.Close()
+	zw.Close()
+	tgz := buf.Bytes()
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-JFrog-Art-Api") != "key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write(tgz)
+	}))
+	defer src.Close()
+
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = t.TempDir()
+	for _, tc := range []struct {
+		headers []string
+		want    int
+	}{
+		{nil, http.StatusInternalServerError},
+		{[]string{"Authorization: Bearer secret"}, http.StatusInternalServerError},
+		{[]string{"Authorization: Bearer secret", "X-JFrog-Art-Api: key"}, http.StatusOK},
+		{[]string{"Authorization"}, http.StatusBadRequest},
+		{[]string{": secret"}, http.StatusBadRequest},
+	} {
+		form := url.Values{"url": {src.URL}, "header": tc.headers}
+		req := httptest.NewRequest("POST", "/writetgz?dir=out", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handleWriteTGZ(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("writetgz with headers %q: status %d; want %d", tc.headers, rec.Code, tc.want)
+		}
+		if strings.Contains(rec.Body.String(), "secret") {
+			t.Errorf("writetgz with headers %q: response %q contains the header value", tc.headers, rec.Body)
+		}
+	}
+	if b, err := os.ReadFile(filepath.Join(*workDir, "out", "file")); err != nil || string(b) != "hello" {
+		t.Errorf("writetgz with headers: extracted file = %q, %v; want %q", b, err, "hello")
+	}
+}
+
+func TestRemoveAllPatterns(t *testing.T) {
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = t.TempDir()
+	files := []string{
+		"keep.go",
+		"go/src/a/a.test",
+		"go/src/a/a.go",
+		"go/src/b/b.test",
+		"go/src/b.test/file",
+		"go/pkg/c.test",
+	}
+	for _, f := range files {
+		name := filepath.Join(*workDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removeAll := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/removeall", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handleRemoveAll(rec, req)
+		return rec
+	}
+	exists := func(f string) bool {
+		_, err := os.Stat(filepath.Join(*workDir, filepath.FromSlash(f)))
+		return err == nil
+	}
+
+	for _, form := range []url.Values{
+		{"pattern": {"*"}},
+		{"pattern": {"*/*"}, "dir": {"go"}},
+		{"pattern": {"?*"}},
+		{"pattern": {"[!.]*"}},
+		{"pattern": {"*.test", "[a-z]*"}, "dir": {"go/src"}},
+		{"pattern": {"."}, "dir": {"go"}},
+	} {
+		if rec := removeAll(form); rec.Code != http.StatusBadRequest {
+			t.Errorf("removeall %v: status %d; want %d", form, rec.Code, http.StatusBadRequest)
+		}
+	}
+	for _, f := range files {
+		if !exists(f) {
+			t.Fatalf("refused removeall removed %s", f)
+		}
+	}
+
+	rec := removeAll(url.Values{"pattern": {"*.test"}, "dir": {"go/src"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("removeall *.test: status %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get(hdrRemoved); got != "3" {
+		t.Errorf("removeall *.test: %s = %q; want %q", hdrRemoved, got, "3")
+	}
+	for _, f := range files {
+		want := !strings.HasPrefix(f, "go/src/") || f == "go/src/a/a.go"
+		if exists(f) != want {
+			t.Errorf("after removeall *.test, %s exists = %t; want %t", f, !want, want)
+		}
+	}
+
+	rec = removeAll(url.Values{"pattern": {"."}, "dir": {"go"}, "force": {"true"}})
+	if rec.Code != http.StatusOK || rec.Header().Get(hdrRemoved) != "1" {
+		t.Errorf("forced removeall of go: status %d, %s = %q; want %d, %q", rec.Code, hdrRemoved, rec.Header().Get(hdrRemoved), http.StatusOK, "1")
+	}
+	if exists("go") || !exists("keep.go") {
+		t.Errorf("forced removeall of go: go exists = %t, keep.go exists = %t; want false, true", exists("go"), exists("keep.go"))
+	}
+
+	rec = removeAll(url.Values{"pattern": {"*.test"}, "dir": {"missing"}})
+	if rec.Code != http.StatusOK || rec.Header().Get(hdrRemoved) != "0" {
+		t.Errorf("removeall in missing dir: status %d, %s = %q; want %d, %q", rec.Code, hdrRemoved, rec.Header().Get(hdrRemoved), http.StatusOK, "0")
+	}
+}
+
+func TestMatchesAnyName(t *testing.T) {
+	for _, tc := range []struct {
+		pat  string
+		want bool
+	}{
+		{"*", true},
+		{"*/*", true},
+		{"?*", true},
+		{"?", true},
+		{"[!.]*", true},
+		{"[a-z]*", true},
+		{"[^.]*/[a-z]?", true},
+		{"*.test", false},
+		{"go*", false},
+		{"[a-z]*.go", false},
+		{"*/bin", false},
+		{"*/go/bin", false},
+		{"go/*", true},
+		{`\*`, false},
+	} {
+		if got := matchesAnyName(tc.pat); got != tc.want {
+			t.Errorf("matchesAnyName(%q) = %t; want %t", tc.pat, got, tc.want)
+		}
+	}
+}
+
+func TestStatusWorkDirSize(t *testing.T) {
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(*workDir, "go", "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"a": 100, "go/src/b": 20, "go/src/c": 3} {
+		if err := os.WriteFile(filepath.Join(*workDir, filepath.FromSlash(name)), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := func(target string) buildlet.Status {
+		rec := httptest.NewRecorder()
+		handleStatus(rec, httptest.NewRequest("GET", target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d; want %d", target, rec.Code, http.StatusOK)
+		}
+		var st buildlet.Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+	if st := status("/status"); st.Version != buildletVersion || st.WorkDirSize != 0 {
+		t.Errorf("status = version %d, work dir size %d; want %d, 0", st.Version, st.WorkDirSize, buildletVersion)
+	}
+	if st := status("/status?detailed=1"); st.WorkDirSize != 123 {
+		t.Errorf("detailed status work dir size = %d; want 123", st.WorkDirSize)
+	}
+}
+
+func TestTail(t *testing.T) {
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = t.TempDir()
+	defer func(old time.Duration) { tailPollInterval = old }(tailPollInterval)
+	tailPollInterval = 10 * time.Millisecond
+	name := filepath.Join(*workDir, "log")
+	if err := os.WriteFile(name, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handleTail))
+	defer ts.Close()
+	tail := func(ctx context.Context, params string) (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/tail?"+params, nil)
+		return http.DefaultClient.Do(req)
+	}
+
+	for _, tc := range []struct {
+		params, want string
+	}{
+		{"path=log", "one\ntwo\nthree\n"},
+		{"path=log&offset=4", "two\nthree\n"},
+		{"path=log&lines=2", "two\nthree\n"},
+		{"path=log&lines=5", "one\ntwo\nthree\n"},
+	} {
+		res, err := tail(context.Background(), tc.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != tc.want || res.Trailer.Get(hdrTailState) != "eof" {
+			t.Errorf("tail %s = %q, %s %q; want %q, eof", tc.params, body, hdrTailState, res.Trailer.Get(hdrTailState), tc.want)
+		}
+	}
+	for _, params := range []string{"path=missing", "path=../log", "path=log&offset=-1", "path=."} {
+		res, err := tail(context.Background(), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			t.Errorf("tail %s: status %d; want error", params, res.StatusCode)
+		}
+	}
+
+	// Following sends appended data, and ends when the file is truncated.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := tail(ctx, "path=log&offset=14&follow=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("four\n")
+	f.Close()
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(res.Body, buf); err != nil || string(buf) != "four\n" {
+		t.Fatalf("following: read %q, %v; want %q", buf, err, "four\n")
+	}
+	if err := os.WriteFile(name, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if rest, err := io.ReadAll(res.Body); err != nil || len(rest) != 0 {
+		t.Errorf("following after truncation: read %q, %v; want nothing", rest, err)
+	}
+	if got := res.Trailer.Get(hdrTailState); got != "truncated" {
+		t.Errorf("following after truncation: %s = %q; want %q", hdrTailState, got, "truncated")
+	}
+}
+
+// writeSyntheticTree writes a tree of files with various modes, and a
+// symlink where supported, into dir.
+func writeSyntheticTree(tb testing.TB, dir string, files int) {
+	tb.Helper()
+	for i := 0; i < files; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("pkg%d", i%10), fmt.Sprintf("file%d.go", i))
+		mode := os.FileMode(0644)
+		if i%3 == 0 {
+			mode = 0755
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		content := strings.Repeat(fmt.Sprintf("// line of file %d\n", i), 100+i%50)
+		if err := os.WriteFile(name, []byte(content), mode); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink("pkg0/file0.go", filepath.Join(dir, "link")); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// getTGZ returns the tarball of the work directory sent by the /tgz
+// handler to a client accepting encoding, and the encoding it's
+// compressed with.
+func getTGZ(tb testing.TB, encoding string) ([]byte, string) {
+	tb.Helper()
+	req := httptest.NewRequest("GET", "/tgz?dir=.", nil)
+	req.Header.Set("Accept-Encoding", encoding)
+	rec := httptest.NewRecorder()
+	handleGetTGZ(rec, req)
+	if rec.Code != http.StatusOK {
+		tb.Fatalf("tgz with Accept-Encoding %s: status %d: %s", encoding, rec.Code, rec.Body)
+	}
+	got := rec.Header().Get("Content-Encoding")
+	if got == "" {
+		got = buildlet.EncodingGzip
+	}
+	return rec.Body.Bytes(), got
+}
+
+// tarEntry is the part of a tar header which must survive a round trip.
+type tarEntry struct {
+	Name     string
+	Mode     int64
+	Linkname string
+	Content  string
+}
+
+func readTarEntries(t *testing.T, r io.Reader, encoding string) []tarEntry {
+	t.Helper()
+	var zr io.Reader
+	if encoding == buildlet.EncodingZstd {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dec.Close()
+		zr = dec
+	} else {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr = gr
+	}
+	var entries []tarEntry
+	tr := tar.NewReader(zr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, tarEntry{h.Name, h.Mode, h.Linkname, string(b)})
+	}
+}
+
+func TestTGZEncodings(t *testing.T) {
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = t.TempDir()
+	writeSyntheticTree(t, *workDir, 30)
+
+	tgz, enc := getTGZ(t, buildlet.EncodingGzip)
+	if enc != buildlet.EncodingGzip {
+		t.Fatalf("tgz with Accept-Encoding gzip: Content-Encoding %s", enc)
+	}
+	want := readTarEntries(t, bytes.NewReader(tgz), enc)
+	if runtime.GOOS != "windows" && !slices.Contains(want, tarEntry{Name: "link", Mode: 0777, Linkname: "pkg0/file0.go"}) {
+		t.Errorf("gzip tarball lacks the symlink: %v", want)
+	}
+	tzst, enc := getTGZ(t, buildlet.EncodingZstd)
+	if enc != buildlet.EncodingZstd {
+		t.Fatalf("tgz with Accept-Encoding zstd: Content-Encoding %s", enc)
+	}
+	if got := readTarEntries(t, bytes.NewReader(tzst), enc); !reflect.DeepEqual(got, want) {
+		t.Errorf("zstd tarball entries differ from gzip ones:\n got %v\nwant %v", got, want)
+	}
+
+	// Write each tarball back, and check that the files and their modes
+	// survived.
+	for enc, body := range map[string][]byte{buildlet.EncodingGzip: tgz, buildlet.EncodingZstd: tzst} {
+		req := httptest.NewRequest("PUT", "/writetgz?dir="+enc, bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", enc)
+		rec := httptest.NewRecorder()
+		handleWriteTGZ(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("writetgz with Content-Encoding %s: status %d: %s", enc, rec.Code, rec.Body)
+		}
+		for _, e := range want {
+			if e.Linkname != "" || strings.HasSuffix(e.Name, "/") {
+				continue
+			}
+			name := filepath.Join(*workDir, enc, filepath.FromSlash(e.Name))
+			b, err := os.ReadFile(name)
+			if err != nil || string(b) != e.Content {
+				t.Errorf("writetgz with Content-Encoding %s: %s = %d bytes, %v; want %d bytes", enc, e.Name, len(b), err, len(e.Content))
+				continue
+			}
+			if fi, err := os.Stat(name); runtime.GOOS != "windows" && (err != nil || int64(fi.Mode().Perm()) != e.Mode) {
+				t.Errorf("writetgz with Content-Encoding %s: mode of %s = %v; want %o", enc, e.Name, fi.Mode(), e.Mode)
+			}
+		}
+	}
+
+	req := httptest.NewRequest("PUT", "/writetgz?dir=bad", bytes.NewReader(tzst))
+	req.Header.Set("Content-Encoding", "br")
+	rec := httptest.NewRecorder()
+	handleWriteTGZ(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("writetgz with Content-Encoding br: status %d; want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func BenchmarkTGZ(b *testing.B) {
+	defer func(old string) { *workDir = old }(*workDir)
+	*workDir = b.TempDir()
+	writeSyntheticTree(b, *workDir, 500)
+	for _, enc := range []string{buildlet.EncodingGzip, buildlet.EncodingZstd} {
+		b.Run(enc, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				body, _ := getTGZ(b, enc)
+				b.SetBytes(int64(len(body)))
+				req := httptest.NewRequest("PUT", "/writetgz?dir=out", bytes.NewReader(body))
+				req.Header.Set("Content-Encoding", enc)
+				rec := httptest.NewRecorder()
+				handleWriteTGZ(rec, req)
+				if rec.Code != http.StatusOK {
+					b.Fatalf("writetgz: status %d: %s", rec.Code, rec.Body)
+				}
+				// Remove the extracted tree, so that the next archive
+				// of the work directory doesn't include it.
+				b.StopTimer()
+				if err := os.RemoveAll(filepath.Join(*workDir, "out")); err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
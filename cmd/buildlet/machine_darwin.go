@@ -0,0 +1,40 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	fillMachineStatus = fillMachineStatusDarwin
+}
+
+// fillMachineStatusDarwin fills in everything but MemAvailable, which
+// macOS doesn't report in any simple way.
+func fillMachineStatusDarwin(dir string, st *buildlet.Status) {
+	var fs unix.Statfs_t
+	if unix.Statfs(dir, &fs) == nil {
+		st.DiskFree = int64(fs.Bavail) * int64(fs.Bsize)
+		st.DiskTotal = int64(fs.Blocks) * int64(fs.Bsize)
+	}
+	if n, err := unix.SysctlUint64("hw.memsize"); err == nil {
+		st.MemTotal = int64(n)
+	}
+	// vm.loadavg is a struct loadavg: three 32-bit fixed-point
+	// values, padding, then their 64-bit scale.
+	if b, err := unix.SysctlRaw("vm.loadavg"); err == nil && len(b) >= 24 {
+		scale := float64(binary.LittleEndian.Uint64(b[16:]))
+		if scale > 0 {
+			st.LoadAvg = make([]float64, 3)
+			for i := range st.LoadAvg {
+				st.LoadAvg[i] = float64(binary.LittleEndian.Uint32(b[4*i:])) / scale
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/build/buildlet"
+)
+
+func init() {
+	fillMachineStatus = fillMachineStatusLinux
+}
+
+func fillMachineStatusLinux(dir string, st *buildlet.Status) {
+	var fs syscall.Statfs_t
+	if syscall.Statfs(dir, &fs) == nil {
+		st.DiskFree = int64(fs.Bavail) * int64(fs.Bsize)
+		st.DiskTotal = int64(fs.Blocks) * int64(fs.Bsize)
+	}
+	if b, err := os.ReadFile("/proc/meminfo"); err == nil {
+		st.MemTotal, st.MemAvailable = parseMeminfo(b)
+	}
+	if b, err := os.ReadFile("/proc/loadavg"); err == nil {
+		st.LoadAvg = parseLoadavg(b)
+	}
+}
+
+// parseMeminfo returns the total and available memory in bytes from the
+// contents of /proc/meminfo.
+func parseMeminfo(b []byte) (total, avail int64) {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		// Lines look like "MemTotal:       16318012 kB".
+		k, v, ok := strings.Cut(s.Text(), ":")
+		if !ok {
+			continue
+		}
+		f := strings.Fields(v)
+		if len(f) != 2 || f[1] != "kB" {
+			continue
+		}
+		n, err := strconv.ParseInt(f[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch k {
+		case "MemTotal":
+			total = n << 10
+		case "MemAvailable":
+			avail = n << 10
+		}
+	}
+	return total, avail
+}
+
+// parseLoadavg returns the 1, 5 and 15 minute load averages from the
+// contents of /proc/loadavg, or nil if they can't be parsed.
+func parseLoadavg(b []byte) []float64 {
+	f := strings.Fields(string(b))
+	if len(f) < 3 {
+		return nil
+	}
+	load := make([]float64, 3)
+	for i := range load {
+		v, err := strconv.ParseFloat(f[i], 64)
+		if err != nil {
+			return nil
+		}
+		load[i] = v
+	}
+	return load
+}
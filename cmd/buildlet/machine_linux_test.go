@@ -0,0 +1,32 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	const meminfo = `MemTotal:       16318012 kB
+MemFree:          529668 kB
+MemAvailable:    8123456 kB
+Buffers:          181172 kB
+HugePages_Total:       0
+`
+	total, avail := parseMeminfo([]byte(meminfo))
+	if total != 16318012<<10 || avail != 8123456<<10 {
+		t.Errorf("parseMeminfo = %d, %d; want %d, %d", total, avail, 16318012<<10, 8123456<<10)
+	}
+}
+
+func TestParseLoadavg(t *testing.T) {
+	if got, want := parseLoadavg([]byte("0.52 0.61 1.70 2/1203 48213\n")), []float64{0.52, 0.61, 1.70}; !slices.Equal(got, want) {
+		t.Errorf("parseLoadavg = %v; want %v", got, want)
+	}
+	if got := parseLoadavg([]byte("garbage")); got != nil {
+		t.Errorf("parseLoadavg(garbage) = %v; want nil", got)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/sys/windows"
+)
+
+func init() {
+	fillMachineStatus = fillMachineStatusWindows
+}
+
+var procGlobalMemoryStatusEx = windows.NewLazySystemDLL("kernel32.dll").NewProc("GlobalMemoryStatusEx")
+
+// memoryStatusEx is the MEMORYSTATUSEX structure.
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// fillMachineStatusWindows fills in everything but LoadAvg, which
+// Windows has no equivalent of.
+func fillMachineStatusWindows(dir string, st *buildlet.Status) {
+	if p, err := windows.UTF16PtrFromString(dir); err == nil {
+		var free, total uint64
+		if windows.GetDiskFreeSpaceEx(p, &free, &total, nil) == nil {
+			st.DiskFree = int64(free)
+			st.DiskTotal = int64(total)
+		}
+	}
+	ms := memoryStatusEx{Length: uint32(unsafe.Sizeof(memoryStatusEx{}))}
+	if r, _, _ := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&ms))); r != 0 {
+		st.MemTotal = int64(ms.TotalPhys)
+		st.MemAvailable = int64(ms.AvailPhys)
+	}
+}
@@ -17,15 +17,38 @@ import (
 	"strings"
 
 	"golang.org/x/build/dashboard"
+	"golang.org/x/build/internal/coordinator/pool"
+	"golang.org/x/build/types"
 )
 
+// builderJSON is a builder of the JSON listing of builders, which clients
+// decode as a types.BuilderInfo. It adds the scripts of the builder to its
+// fields, since they're computed by methods.
+type builderJSON struct {
+	*dashboard.BuildConfig
+	MakeScript string
+	AllScript  string
+}
+
 func handleBuilders(w http.ResponseWriter, r *http.Request) {
 	data := struct {
 		Builders map[string]*dashboard.BuildConfig
 		Hosts    map[string]*dashboard.HostConfig
 	}{dashboard.Builders, dashboard.Hosts}
 	if r.FormValue("mode") == "json" {
-		j, err := json.MarshalIndent(data, "", "\t")
+		builders := make(map[string]builderJSON, len(data.Builders))
+		for name, bc := range data.Builders {
+			builders[name] = builderJSON{
+				BuildConfig: bc,
+				MakeScript:  bc.MakeScript(),
+				AllScript:   bc.AllScript(),
+			}
+		}
+		j, err := json.MarshalIndent(struct {
+			Builders map[string]builderJSON
+			Hosts    map[string]*dashboard.HostConfig
+			Retired  map[string]string
+		}{builders, data.Hosts, dashboard.RetiredBuilders}, "", "\t")
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -43,6 +66,38 @@ func handleBuilders(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleCapacity serves the current capacity of the reverse host types,
+// which clients decode as a map of types.HostCapacity.
+func handleCapacity(w http.ResponseWriter, r *http.Request) {
+	capacity := hostCapacity(dashboard.Hosts, pool.ReversePool().HostTypeCount(), pool.ReversePool().WaitingCount())
+	j, err := json.MarshalIndent(capacity, "", "\t")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(j)
+}
+
+// hostCapacity returns the capacity of the reverse host types of hosts,
+// given the numbers of connected machines and of waiting items per host
+// type.
+func hostCapacity(hosts map[string]*dashboard.HostConfig, connected, waiting map[string]int) map[string]types.HostCapacity {
+	capacity := make(map[string]types.HostCapacity)
+	for name, hc := range hosts {
+		if !hc.IsReverse {
+			continue
+		}
+		capacity[name] = types.HostCapacity{
+			HostType:  name,
+			Expected:  hc.ExpectNum,
+			Connected: connected[name],
+			Waiting:   waiting[name],
+		}
+	}
+	return capacity
+}
+
 //go:embed templates/builders.html
 var buildersTmplStr string
 
@@ -62,6 +62,7 @@ import (
 	"golang.org/x/build/internal/https"
 	"golang.org/x/build/internal/metrics"
 	"golang.org/x/build/internal/secret"
+	"golang.org/x/build/internal/wstunnel"
 	"golang.org/x/build/kubernetes/gke"
 	"golang.org/x/build/maintner/maintnerd/apipb"
 	"golang.org/x/build/repos"
@@ -348,6 +349,7 @@ func main() {
 
 	var gomoteBucket string
 	var opts []grpc.ServerOption
+	var requireIAP bool
 	if *buildEnvName == "" && *mode != "dev" && metadata.OnGCE() {
 		projectID, err := metadata.ProjectID()
 		if err != nil {
@@ -361,6 +363,7 @@ func main() {
 		}
 		opts = append(opts, grpc.UnaryInterceptor(access.RequireIAPAuthUnaryInterceptor(access.IAPSkipAudienceValidation)))
 		opts = append(opts, grpc.StreamInterceptor(access.RequireIAPAuthStreamInterceptor(access.IAPSkipAudienceValidation)))
+		requireIAP = true
 	}
 	// grpcServer is a shared gRPC server. It is global, as it needs to be used in places that aren't factored otherwise.
 	grpcServer := grpc.NewServer(opts...)
@@ -400,9 +403,16 @@ func main() {
 	mux.Handle("build.golang.org/", dashV1)                        // Serve a build dashboard at build.golang.org.
 	mux.Handle("build-staging.golang.org/", dashV1)
 	mux.HandleFunc("/builders", handleBuilders)
+	mux.HandleFunc("/capacity", handleCapacity)
 	mux.HandleFunc("/temporarylogs", handleLogs)
 	mux.HandleFunc("/reverse", pool.HandleReverse)
 	mux.Handle("/revdial", revdial.ConnHandler())
+	var tunnel http.Handler = wstunnel.Handler(grpcServer, access.IAPHeaders()...)
+	if requireIAP {
+		// Authenticate tunnels once, so that requests made through them don't fail when their JWT expires.
+		tunnel = access.RequireIAPAuthTunnelHandler(tunnel, access.IAPSkipAudienceValidation)
+	}
+	mux.Handle(wstunnel.Path, tunnel) // For gomote clients which can only use HTTPS.
 	mux.HandleFunc("/style.css", handleStyleCSS)
 	mux.HandleFunc("/try", serveTryStatus(false))
 	mux.HandleFunc("/try.json", serveTryStatus(true))
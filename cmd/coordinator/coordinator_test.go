@@ -8,6 +8,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"log"
 	"net/http"
@@ -18,10 +19,12 @@ import (
 	"time"
 
 	"golang.org/x/build/buildenv"
+	"golang.org/x/build/dashboard"
 	"golang.org/x/build/gerrit"
 	"golang.org/x/build/internal/buildgo"
 	"golang.org/x/build/internal/coordinator/pool"
 	"golang.org/x/build/maintner/maintnerd/apipb"
+	"golang.org/x/build/types"
 )
 
 type Seconds float64
@@ -317,6 +320,70 @@ func TestBuildersJSON(t *testing.T) {
 		res.Write(&buf)
 		t.Error(buf.String())
 	}
+	// Clients decode the listing into types.BuilderListing, so its fields
+	// must keep matching those of the dashboard package.
+	var l types.BuilderListing
+	if err := json.NewDecoder(res.Body).Decode(&l); err != nil {
+		t.Fatalf("decoding builders JSON: %v", err)
+	}
+	for name, bc := range dashboard.Builders {
+		hc := bc.HostConfig()
+		hi, ok := l.Host(name)
+		if !ok {
+			t.Errorf("builder %s or its host type is missing from the builders JSON", name)
+			continue
+		}
+		if hi.HostType != hc.HostType || hi.IsReverse != hc.IsReverse || hi.ExpectNum != hc.ExpectNum || hi.ContainerImage != hc.ContainerImage || hi.VMImage != hc.VMImage {
+			t.Errorf("host type of %s in the builders JSON = %+v; want the fields of %+v", name, hi, hc)
+		}
+		if bi := l.Builders[name]; bi.MakeScript != bc.MakeScript() || bi.AllScript != bc.AllScript() {
+			t.Errorf("scripts of %s in the builders JSON = %q, %q; want %q, %q", name, bi.MakeScript, bi.AllScript, bc.MakeScript(), bc.AllScript())
+		}
+	}
+	if !reflect.DeepEqual(l.Retired, dashboard.RetiredBuilders) {
+		t.Errorf("retired builders in the builders JSON = %v; want %v", l.Retired, dashboard.RetiredBuilders)
+	}
+}
+
+func TestHostCapacity(t *testing.T) {
+	hosts := map[string]*dashboard.HostConfig{
+		"host-darwin-arm64-13":      {IsReverse: true, ExpectNum: 4},
+		"host-openbsd-riscv64":      {IsReverse: true, ExpectNum: 1},
+		"host-linux-amd64-bullseye": {ContainerImage: "linux-x86-bullseye:latest"},
+	}
+	connected := map[string]int{"host-darwin-arm64-13": 2}
+	waiting := map[string]int{"host-darwin-arm64-13": 6, "host-linux-amd64-bullseye": 1}
+	want := map[string]types.HostCapacity{
+		"host-darwin-arm64-13": {HostType: "host-darwin-arm64-13", Expected: 4, Connected: 2, Waiting: 6},
+		"host-openbsd-riscv64": {HostType: "host-openbsd-riscv64", Expected: 1},
+	}
+	if got := hostCapacity(hosts, connected, waiting); !reflect.DeepEqual(got, want) {
+		t.Errorf("hostCapacity = %+v; want %+v", got, want)
+	}
+}
+
+func TestCapacityJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleCapacity(rec, httptest.NewRequest("GET", "https://farmer.tld/capacity", nil))
+	res := rec.Result()
+	if res.Header.Get("Content-Type") != "application/json" || res.StatusCode != 200 {
+		var buf bytes.Buffer
+		res.Write(&buf)
+		t.Fatal(buf.String())
+	}
+	var capacity map[string]types.HostCapacity
+	if err := json.NewDecoder(res.Body).Decode(&capacity); err != nil {
+		t.Fatalf("decoding capacity JSON: %v", err)
+	}
+	for name, hc := range dashboard.Hosts {
+		c, ok := capacity[name]
+		if ok != hc.IsReverse {
+			t.Errorf("host type %s is in the capacity JSON: %t; want %t", name, ok, hc.IsReverse)
+		}
+		if ok && (c.HostType != name || c.Expected != hc.ExpectNum) {
+			t.Errorf("capacity of %s = %+v; want host type %s expecting %d machines", name, c, name, hc.ExpectNum)
+		}
+	}
 }
 
 func TestSlowBotsFromComments(t *testing.T) {
@@ -0,0 +1,240 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+func alias(args []string) error {
+	cm := map[string]struct {
+		run  func([]string) error
+		desc string
+	}{
+		"set":   {setAlias, "give an instance an alias"},
+		"unset": {unsetAlias, "remove an alias"},
+		"list":  {listAliases, "list aliases and their instances"},
+	}
+	if len(args) == 0 {
+		var cmds []string
+		for cmd := range cm {
+			cmds = append(cmds, cmd)
+		}
+		sort.Strings(cmds)
+		fmt.Fprintf(os.Stderr, "Usage of gomote alias: gomote [global-flags] alias <cmd> [cmd-flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Commands:\n\n")
+		for _, name := range cmds {
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, cm[name].desc)
+		}
+		fmt.Fprintln(os.Stderr)
+		os.Exit(exitUsage)
+	}
+	subCmd := args[0]
+	sc, ok := cm[subCmd]
+	if !ok {
+		return usageErrorf("unknown sub-command %q\n", subCmd)
+	}
+	return sc.run(args[1:])
+}
+
+func setAlias(args []string) error {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "alias set usage: gomote alias set <alias> <instance>")
+		os.Exit(exitUsage)
+	}
+	name := args[0]
+	ctx := context.Background()
+	client := gomoteServerClient(ctx)
+	resp, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+	if err != nil {
+		return fmt.Errorf("unable to list instances: %w", err)
+	}
+	var insts []string
+	for _, inst := range resp.GetInstances() {
+		insts = append(insts, inst.GetGomoteId())
+	}
+	if err := validateAlias(name, insts); err != nil {
+		return err
+	}
+	inst, err := matchInstance(args[1], insts, nil)
+	if err != nil {
+		return err
+	}
+	if err := doPing(ctx, inst); err != nil {
+		return fmt.Errorf("instance %q: %w", inst, err)
+	}
+	aliases, err := loadAliases()
+	if err != nil {
+		return err
+	}
+	pruneAliases(aliases, insts)
+	aliases[name] = inst
+	return storeAliases(aliases)
+}
+
+func unsetAlias(args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "alias unset usage: gomote alias unset <alias>")
+		os.Exit(exitUsage)
+	}
+	aliases, err := loadAliases()
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[args[0]]; !ok {
+		return usageErrorf("alias %q does not exist", args[0])
+	}
+	delete(aliases, args[0])
+	return storeAliases(aliases)
+}
+
+func listAliases(args []string) error {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "alias list usage: gomote alias list")
+		os.Exit(exitUsage)
+	}
+	aliases, err := loadAliases()
+	if err != nil {
+		return err
+	}
+	if len(aliases) > 0 {
+		ctx := context.Background()
+		resp, err := gomoteServerClient(ctx).ListInstances(ctx, &protos.ListInstancesRequest{})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Unable to list instances to prune the aliases of those which no longer exist: %v", err)))
+		} else {
+			var insts []string
+			for _, inst := range resp.GetInstances() {
+				insts = append(insts, inst.GetGomoteId())
+			}
+			if pruneAliases(aliases, insts) {
+				if err := storeAliases(aliases); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(aliases) == 0 {
+		fmt.Println("(none)")
+		return nil
+	}
+	var names []string
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ALIAS\tINSTANCE")
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t%s\n", name, aliases[name])
+	}
+	return tw.Flush()
+}
+
+// validateAlias checks that name is a valid alias, given the names of the
+// user's instances. An alias may not be confused with an instance name,
+// which commands would otherwise match by prefix.
+func validateAlias(name string, instances []string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n/\\") {
+		return usageErrorf("invalid alias %q: must not be empty, start with '-' or contain spaces or slashes", name)
+	}
+	for _, inst := range instances {
+		if strings.HasPrefix(inst, name) {
+			return usageErrorf("invalid alias %q: it is a prefix of the name of instance %q", name, inst)
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the instance named by alias, or name unchanged if it
+// is not an alias.
+func resolveAlias(name string) (string, error) {
+	aliases, err := loadAliases()
+	if err != nil {
+		return "", err
+	}
+	inst, ok := aliases[name]
+	if !ok {
+		return name, nil
+	}
+	fmt.Fprintf(os.Stderr, "# Using instance %s for alias %q\n", inst, name)
+	return inst, nil
+}
+
+// loadAliases returns the aliases, keyed by alias name. Unlike groups,
+// aliases of instances which no longer exist aren't pruned on load, which
+// would need a call to the server for every command given an alias; the
+// alias subcommands which list the instances prune them.
+func loadAliases() (map[string]string, error) {
+	fname, err := aliasFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("loading aliases: %w", err)
+	}
+	data, err := os.ReadFile(fname)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("loading aliases: %w", err)
+	}
+	aliases := make(map[string]string)
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("loading aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// pruneAliases deletes the aliases of instances which aren't in insts, the
+// names of all of the user's instances, and reports whether it deleted any.
+func pruneAliases(aliases map[string]string, insts []string) bool {
+	live := make(map[string]bool)
+	for _, inst := range insts {
+		live[inst] = true
+	}
+	pruned := false
+	for name, inst := range aliases {
+		if !live[inst] {
+			delete(aliases, name)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+func storeAliases(aliases map[string]string) error {
+	fname, err := aliasFilePath()
+	if err != nil {
+		return fmt.Errorf("storing aliases: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return fmt.Errorf("storing aliases: %w", err)
+	}
+	data, err := json.Marshal(aliases)
+	if err != nil {
+		return fmt.Errorf("storing aliases: %w", err)
+	}
+	if err := os.WriteFile(fname, data, 0644); err != nil {
+		return fmt.Errorf("storing aliases: %w", err)
+	}
+	return nil
+}
+
+func aliasFilePath() (string, error) {
+	dir, err := groupDir()
+	if err != nil {
+		return "", err
+	}
+	// Aliases are stored alongside the groups directory.
+	return filepath.Join(filepath.Dir(dir), "aliases.json"), nil
+}
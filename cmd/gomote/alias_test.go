@@ -0,0 +1,66 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateAlias(t *testing.T) {
+	instances := []string{
+		"user-gopher-linux-amd64-0",
+		"user-gopher-windows-amd64-0",
+	}
+	for _, tc := range []struct {
+		name string
+		ok   bool
+	}{
+		{"win", true},
+		{"linux-0", true},
+		{"windows-amd64-0", true},
+		{"", false},
+		{"-win", false},
+		{"my win", false},
+		{"a/b", false},
+		{"user-gopher-linux-amd64-0", false},
+		{"user-gopher-windows", false},
+		{"user", false},
+	} {
+		err := validateAlias(tc.name, instances)
+		if tc.ok && err != nil {
+			t.Errorf("validateAlias(%q) = %v, want nil", tc.name, err)
+		}
+		if !tc.ok {
+			var ue usageError
+			if !errors.As(err, &ue) {
+				t.Errorf("validateAlias(%q) = %v, want usage error", tc.name, err)
+			}
+		}
+	}
+}
+
+func TestPruneAliases(t *testing.T) {
+	aliases := map[string]string{
+		"a": "user-foo-linux-amd64-0",
+		"b": "user-foo-linux-arm64-0",
+		"c": "user-foo-linux-amd64-0",
+	}
+	if !pruneAliases(aliases, []string{"user-foo-linux-amd64-0", "user-foo-linux-amd64-1"}) {
+		t.Errorf("pruneAliases() = false; want true, as an alias was pruned")
+	}
+	want := map[string]string{
+		"a": "user-foo-linux-amd64-0",
+		"c": "user-foo-linux-amd64-0",
+	}
+	if diff := cmp.Diff(want, aliases); diff != "" {
+		t.Errorf("aliases after pruneAliases() mismatch (-want +got):\n%s", diff)
+	}
+	if pruneAliases(aliases, []string{"user-foo-linux-amd64-0"}) {
+		t.Errorf("pruneAliases() = true; want false, as all instances exist")
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/perf/benchstat"
+	"golang.org/x/sync/errgroup"
+)
+
+// benchCompare compares the benchmarks of a package built with two
+// GOROOTs, each on an instance of its own.
+func benchCompare(args []string) error {
+	var flags benchCompareFlags
+	fs := benchCompareFlagSet(&flags)
+	parseFlags(fs, args)
+	if flags.old == "" || flags.new == "" || flags.pkg == "" {
+		return usageErrorf("benchcompare requires -old, -new and -pkg")
+	}
+	for _, goroot := range []string{flags.old, flags.new} {
+		if fi, err := os.Stat(filepath.Join(goroot, "src")); err != nil || !fi.IsDir() {
+			return usageErrorf("%s isn't a GOROOT; it has no src directory", goroot)
+		}
+	}
+	if flags.count < 1 {
+		return usageErrorf("invalid -count %d; want at least 1", flags.count)
+	}
+	var builderType string
+	var reuse []string
+	switch {
+	case flags.instances != "" && fs.NArg() == 0:
+		reuse = strings.Split(flags.instances, ",")
+		if len(reuse) != 2 || reuse[0] == "" || reuse[1] == "" || reuse[0] == reuse[1] {
+			return usageErrorf("invalid -instances %q; want two instances, for -old and -new, separated by a comma", flags.instances)
+		}
+	case flags.instances == "" && fs.NArg() == 1:
+		builderType = fs.Arg(0)
+	default:
+		fs.Usage()
+	}
+
+	outDir := flags.outDir
+	if outDir == "" {
+		outDir = defaultBenchCompareDir(time.Now())
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the directory for benchmark results: %w", err)
+	}
+
+	ctx := context.Background()
+	sides := []*benchSide{{name: "old", goroot: flags.old}, {name: "new", goroot: flags.new}}
+	for _, side := range sides {
+		var err error
+		side.setup, err = newSetup(ctx, setupFlags{logDir: outDir, forwardEnv: flags.forwardEnv, goroot: side.goroot}, false)
+		if err != nil {
+			return err
+		}
+	}
+	reportForwardedEnv(os.Stderr, sides[0].setup.env)
+
+	t := newTimer("benchcompare")
+	defer t.report(os.Stderr, flags.timings)
+	eg, ectx := errgroup.WithContext(ctx)
+	for i, side := range sides {
+		i, side := i, side
+		eg.Go(func() error {
+			if reuse != nil {
+				inst, err := resolveInstance(ectx, reuse[i])
+				if err != nil {
+					return err
+				}
+				resp, err := gomoteServerClient(ectx).InstanceStatus(ectx, &protos.InstanceStatusRequest{GomoteId: inst})
+				if err != nil {
+					return fmt.Errorf("unable to retrieve status of instance %s: %w", inst, err)
+				}
+				side.inst, side.builderType, side.workDir = inst, resp.GetInstance().GetBuilderType(), resp.GetInstance().GetWorkingDir()
+			} else {
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Creating a %s instance for -%s...", builderType, side.name)))
+				endCreate := t.span("create", side.name)
+				inst, workDir, err := createInstance(ectx, builderType)
+				endCreate()
+				if err != nil {
+					return err
+				}
+				side.inst, side.builderType, side.workDir, side.created = inst, builderType, workDir, true
+				fmt.Println(inst)
+			}
+			return side.setup.run(ectx, t, side.inst, side.builderType, side.workDir)
+		})
+	}
+	err := eg.Wait()
+	if !flags.keep {
+		defer destroyCreated(ctx, sides)
+	}
+	if err != nil {
+		return err
+	}
+	for _, side := range sides {
+		if err := side.setup.report(os.Stderr); err != nil {
+			return err
+		}
+	}
+
+	for _, side := range sides {
+		f, err := os.Create(filepath.Join(outDir, side.name+".txt"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		side.out = f
+	}
+	fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Writing benchmark results to %q...", outDir)))
+	bench := func(ctx context.Context, side *benchSide, count int) error {
+		defer t.span("bench", side.inst)()
+		return doRun(ctx, side.inst, "go/bin/go", benchArgs(flags.bench, count, flags.pkg),
+			runDir("go/src"), runEnv(side.setup.env), runWriters(side.out))
+	}
+	if flags.interleave {
+		// Alternate which toolchain goes first, so that neither
+		// systematically runs in the wake of the other.
+		for round := 0; round < flags.count; round++ {
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Running round %d of %d...", round+1, flags.count)))
+			for i := range sides {
+				if err := bench(ctx, sides[(round+i)%2], 1); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Running %s benchmarks on %s and %s...", flags.pkg, styles.Instance(sides[0].inst), styles.Instance(sides[1].inst))))
+		eg, ectx := errgroup.WithContext(ctx)
+		for _, side := range sides {
+			side := side
+			eg.Go(func() error { return bench(ectx, side, flags.count) })
+		}
+		if err := eg.Wait(); err != nil {
+			return err
+		}
+	}
+
+	var results [2][]byte
+	for i, side := range sides {
+		if results[i], err = os.ReadFile(side.out.Name()); err != nil {
+			return err
+		}
+	}
+	return compareBenchmarks(os.Stdout, results[0], results[1])
+}
+
+// benchCompareFlags are the flags of the benchcompare command.
+type benchCompareFlags struct {
+	old, new   string
+	bench      string
+	pkg        string
+	count      int
+	interleave bool
+	instances  string
+	outDir     string
+	keep       bool
+	forwardEnv string
+	timings    timingsFlag
+}
+
+func benchCompareFlagSet(flags *benchCompareFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("benchcompare", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "benchcompare usage: gomote benchcompare -old <goroot> -new <goroot> -pkg <package> [benchcompare-opts] <builder type>")
+		fmt.Fprintln(os.Stderr, "       gomote benchcompare -old <goroot> -new <goroot> -pkg <package> [benchcompare-opts] -instances <old>,<new>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Creates two instances of the builder type, or uses the given ones, sets")
+		fmt.Fprintln(os.Stderr, "one up with each GOROOT as create -setup does, runs the benchmarks of")
+		fmt.Fprintln(os.Stderr, "the package on both, and compares the results as benchstat does.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.old, "old", "", "the GOROOT to compare against")
+	fs.StringVar(&flags.new, "new", "", "the GOROOT to compare")
+	fs.StringVar(&flags.bench, "bench", ".", "run the benchmarks matching `regexp`, as go test -bench does")
+	fs.StringVar(&flags.pkg, "pkg", "", "the import `path` of the package whose benchmarks to run, such as strings")
+	fs.IntVar(&flags.count, "count", 10, "run each benchmark this many times with each GOROOT")
+	fs.BoolVar(&flags.interleave, "interleave", false, "run the benchmarks one count at a time, alternating between the instances, rather than all at once on both, to spread noise evenly between them")
+	fs.StringVar(&flags.instances, "instances", "", "use the existing instances `old,new` rather than creating them")
+	fs.StringVar(&flags.outDir, "out", "", "write the setup logs, and the raw results to old.txt and new.txt, in `dir`; the default is a new directory under the user's cache directory")
+	fs.BoolVar(&flags.keep, "keep", false, "keep the instances created rather than destroying them once done")
+	fs.StringVar(&flags.forwardEnv, "forward-env", defaultForwardEnv, forwardEnvUsage)
+	fs.Var(&flags.timings, "timings", timingsUsage)
+	return fs
+}
+
+// A benchSide is one of the two GOROOTs benchcompare compares, and the
+// instance it runs on.
+type benchSide struct {
+	name        string // "old" or "new"
+	goroot      string
+	setup       *setup
+	inst        string
+	builderType string
+	workDir     string
+	created     bool     // whether benchcompare created inst
+	out         *os.File // the raw results
+}
+
+// benchArgs returns the arguments of go test which run the benchmarks
+// matching pattern in pkg count times, and no tests.
+func benchArgs(pattern string, count int, pkg string) []string {
+	return []string{"test", "-run=^$", "-bench=" + pattern, fmt.Sprintf("-count=%d", count), pkg}
+}
+
+// defaultBenchCompareDir returns the directory the results of
+// benchcompare started at now are written to without -out.
+func defaultBenchCompareDir(now time.Time) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gomote", "benchcompare", now.Format("20060102-150405"))
+}
+
+// compareBenchmarks writes a comparison of the benchmark results old and
+// new, in the format of go test -bench, to w.
+func compareBenchmarks(w io.Writer, old, new []byte) error {
+	c := &benchstat.Collection{
+		Alpha:      0.05,
+		AddGeoMean: true,
+		DeltaTest:  benchstat.UTest,
+	}
+	c.AddConfig("old", old)
+	c.AddConfig("new", new)
+	tables := c.Tables()
+	if len(tables) == 0 {
+		return errors.New("no benchmark results to compare; check that -bench matches benchmarks of -pkg")
+	}
+	benchstat.FormatText(w, tables)
+	return nil
+}
+
+// createInstance creates an instance of builderType, and returns its name
+// and work directory once it's ready.
+func createInstance(ctx context.Context, builderType string) (inst, workDir string, err error) {
+	stream, err := gomoteServerClient(ctx).CreateInstance(ctx, &protos.CreateInstanceRequest{
+		BuilderType: builderType,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create buildlet: %w", err)
+	}
+	for {
+		update, err := stream.Recv()
+		switch {
+		case err == io.EOF:
+			if inst == "" {
+				return "", "", errors.New("failed to create buildlet: the server didn't report the instance")
+			}
+			return inst, workDir, nil
+		case err != nil:
+			return "", "", fmt.Errorf("failed to create buildlet: %w", err)
+		case update.GetStatus() == protos.CreateInstanceResponse_COMPLETE:
+			inst = update.GetInstance().GetGomoteId()
+			workDir = update.GetInstance().GetWorkingDir()
+		}
+	}
+}
+
+// destroyCreated destroys the instances benchcompare created.
+func destroyCreated(ctx context.Context, sides []*benchSide) {
+	for _, side := range sides {
+		if !side.created {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "# Destroying %s\n", side.inst)
+		if _, err := gomoteServerClient(ctx).DestroyInstance(ctx, &protos.DestroyInstanceRequest{GomoteId: side.inst}); err != nil {
+			fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Unable to destroy %s: %v", side.inst, err)))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchResults returns go test -bench output with count results of
+// BenchmarkIndex taking about ns ns/op.
+func benchResults(count int, ns float64) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "goos: linux\ngoarch: amd64\npkg: strings")
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&buf, "BenchmarkIndex-8\t1000000\t%.1f ns/op\n", ns+float64(i%3))
+	}
+	fmt.Fprintln(&buf, "PASS\nok  \tstrings\t1.234s")
+	return buf.Bytes()
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	var buf bytes.Buffer
+	if err := compareBenchmarks(&buf, benchResults(10, 100), benchResults(10, 50)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"old time/op", "new time/op", "delta", "Index-8", "-49.55%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("compareBenchmarks() =\n%s\nwant it to contain %q", out, want)
+		}
+	}
+
+	if err := compareBenchmarks(&buf, []byte("PASS\n"), []byte("PASS\n")); err == nil {
+		t.Errorf("compareBenchmarks() without results succeeded; want an error")
+	}
+}
+
+func TestBenchArgs(t *testing.T) {
+	got := strings.Join(benchArgs("Index", 5, "strings"), " ")
+	if want := "test -run=^$ -bench=Index -count=5 strings"; got != want {
+		t.Errorf("benchArgs() = %q; want %q", got, want)
+	}
+}
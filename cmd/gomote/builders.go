@@ -0,0 +1,368 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/build/cmd/gomote/internal/builderlist"
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/types"
+)
+
+func builders(args []string) error {
+	var flags buildersFlags
+	fs := buildersFlagSet(&flags)
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+	}
+	if _, err := path.Match(flags.namePattern, ""); err != nil {
+		return usageErrorf("invalid pattern %q: %w", flags.namePattern, err)
+	}
+	c := builderListClient()
+	c.All = flags.all
+	list := c.List
+	if flags.refresh {
+		list = c.Refresh
+	}
+	bts, err := list(context.Background())
+	if err != nil {
+		return err
+	}
+	bts = filterBuilderTypes(bts, flags.goos, flags.goarch, flags.namePattern)
+	if len(bts) == 0 {
+		fmt.Fprintln(os.Stderr, "no matching builder types")
+	}
+	var capacity map[string]types.HostCapacity
+	if slices.ContainsFunc(bts, func(bt builderlist.Builder) bool { return bt.IsReverse }) {
+		capacity, err = c.Capacity(context.Background())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Unable to fetch the capacity of the reverse builders: %v", err)))
+		}
+	}
+	if flags.jsonOut {
+		out := []builderJSON{}
+		for _, bt := range bts {
+			bj := builderJSON{Builder: bt, MiscCompile: bt.MiscCompile()}
+			if hc := capacityOf(capacity, bt); hc != nil {
+				bj.Connected, bj.Waiting = &hc.Connected, &hc.Waiting
+			}
+			out = append(out, bj)
+		}
+		return writeJSON(os.Stdout, out)
+	}
+	return writeBuilderTypesTable(os.Stdout, bts, capacity)
+}
+
+// builderJSON is a builder type as printed by "gomote builders -json",
+// including the current capacity of reverse builders if it's known.
+type builderJSON struct {
+	builderlist.Builder
+	MiscCompile bool `json:"misc_compile,omitempty"`
+	Connected   *int `json:"connected,omitempty"`
+	Waiting     *int `json:"waiting,omitempty"`
+}
+
+// buildersFlags are the flags of the builders command.
+type buildersFlags struct {
+	jsonOut     bool
+	refresh     bool
+	all         bool
+	goos        string
+	goarch      string
+	namePattern string
+}
+
+func buildersFlagSet(flags *buildersFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("builders", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "builders usage: gomote builders [builders-opts]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Lists the builder types instances can be created with. They're")
+		fmt.Fprintf(os.Stderr, "fetched from the server at most every %v, unless -refresh is set.\n", builderlist.DefaultTTL)
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.jsonOut, "json", false, "print the builder types as a JSON array")
+	fs.BoolVar(&flags.refresh, "refresh", false, "fetch the builder types from the server rather than using the cached ones")
+	fs.BoolVar(&flags.all, "all", false, "also list the coordinator's misc-compile builders, which instances can't be created with, and the ports they cross-compile for")
+	fs.StringVar(&flags.goos, "goos", "", "only list builder types for this GOOS")
+	fs.StringVar(&flags.goarch, "goarch", "", "only list builder types for this GOARCH")
+	fs.StringVar(&flags.namePattern, "match", "", "only list builder types whose name matches the glob `pattern`")
+	return fs
+}
+
+// filterBuilderTypes returns the builder types for goos and goarch whose
+// name matches the path.Match pattern. Empty arguments match everything.
+// Misc-compile builders are for the ports they cross-compile for.
+func filterBuilderTypes(bts []builderlist.Builder, goos, goarch, pattern string) []builderlist.Builder {
+	matches := func(btGOOS, btGOARCH string) bool {
+		return (goos == "" || btGOOS == goos) && (goarch == "" || btGOARCH == goarch)
+	}
+	var filtered []builderlist.Builder
+	for _, bt := range bts {
+		if !matches(bt.GOOS, bt.GOARCH) && !slices.ContainsFunc(bt.Ports, func(port string) bool {
+			portGOOS, portGOARCH, _ := strings.Cut(port, "/")
+			return matches(portGOOS, portGOARCH)
+		}) {
+			continue
+		}
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, bt.Name); !ok {
+				continue
+			}
+		}
+		filtered = append(filtered, bt)
+	}
+	return filtered
+}
+
+// writeBuilderTypesTable writes a table of the builder types and the
+// backends serving them. Whether they're reverse builders, their capacity and their images are included
+// if any of them has one of them. The capacity is the current one in
+// capacity, which may be nil, if it has the builder's host type. The
+// misc-compile builders are in a separate table, with their ports.
+func writeBuilderTypesTable(w io.Writer, bts []builderlist.Builder, capacity map[string]types.HostCapacity) error {
+	var creatable, misc []builderlist.Builder
+	for _, bt := range bts {
+		if bt.MiscCompile() {
+			misc = append(misc, bt)
+		} else {
+			creatable = append(creatable, bt)
+		}
+	}
+	if len(creatable) > 0 || len(misc) == 0 {
+		if err := writeCreatableBuilderTypes(w, creatable, capacity); err != nil {
+			return err
+		}
+		if len(misc) == 0 {
+			return nil
+		}
+		fmt.Fprintln(w)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "MISC-COMPILE BUILDER\tPORTS")
+	for _, bt := range misc {
+		ports := "-"
+		if len(bt.Ports) > 0 {
+			ports = strings.Join(bt.Ports, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", bt.Name, ports)
+	}
+	return tw.Flush()
+}
+
+// writeCreatableBuilderTypes writes the table of writeBuilderTypesTable
+// for the builder types instances can be created with.
+func writeCreatableBuilderTypes(w io.Writer, bts []builderlist.Builder, capacity map[string]types.HostCapacity) error {
+	detailed := slices.ContainsFunc(bts, func(bt builderlist.Builder) bool {
+		return bt.IsReverse || bt.Image != ""
+	})
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if detailed {
+		fmt.Fprintln(tw, "NAME\tGOOS\tGOARCH\tBACKEND\tREVERSE\tCAPACITY\tIMAGE")
+	} else {
+		fmt.Fprintln(tw, "NAME\tGOOS\tGOARCH\tBACKEND")
+	}
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	for _, bt := range bts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s", bt.Name, orDash(bt.GOOS), orDash(bt.GOARCH), orDash(strings.Join(bt.Backends, ",")))
+		if detailed {
+			reverse := "no"
+			if bt.IsReverse {
+				reverse = "yes"
+			}
+			summary := capacitySummary(bt, capacityOf(capacity, bt))
+			fmt.Fprintf(tw, "\t%s\t%s\t%s", reverse, orDash(summary), orDash(bt.Image))
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// capacityOf returns the current capacity of the reverse builder type bt
+// in capacity, or nil if it isn't known.
+func capacityOf(capacity map[string]types.HostCapacity, bt builderlist.Builder) *types.HostCapacity {
+	if !bt.IsReverse || bt.HostType == "" {
+		return nil
+	}
+	hc, ok := capacity[bt.HostType]
+	if !ok {
+		return nil
+	}
+	return &hc
+}
+
+// capacitySummary describes the capacity of the reverse builder type bt,
+// such as "4 machines, 2 connected, 6 waiting". If its current capacity hc
+// is nil, only its expected number of machines is described, if known.
+func capacitySummary(bt builderlist.Builder, hc *types.HostCapacity) string {
+	if hc == nil {
+		if bt.ExpectNum <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d machines", bt.ExpectNum)
+	}
+	expected := hc.Expected
+	if expected <= 0 {
+		expected = bt.ExpectNum
+	}
+	var machines string
+	if expected > 0 {
+		machines = fmt.Sprintf("%d machines, ", expected)
+	}
+	return fmt.Sprintf("%s%d connected, %d waiting", machines, hc.Connected, hc.Waiting)
+}
+
+// busyWarning returns the warning to print before creating instances of
+// the reverse builder type bt, whose current capacity is hc, or "" if
+// there's no need for one: it's only needed if more requests are waiting
+// for its machines than there are machines to serve them.
+func busyWarning(bt builderlist.Builder, hc *types.HostCapacity) string {
+	if hc == nil || hc.Waiting <= hc.Connected {
+		return ""
+	}
+	return fmt.Sprintf("# warning: %s is busy (%s); new instances may take a while to be created", bt.Name, capacitySummary(bt, hc))
+}
+
+// warnBusyBuilder prints a warning if builderType is a reverse builder
+// type with more requests waiting than connected machines. It's silent
+// if that can't be determined quickly, such as when LUCI is enabled,
+// since the gomote server doesn't report the capacity of its builders.
+func warnBusyBuilder(builderType string) {
+	if !luciDisabled() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := builderListClient()
+	c.Logf = nil
+	bts, err := c.List(ctx)
+	if err != nil {
+		return
+	}
+	i := slices.IndexFunc(bts, func(bt builderlist.Builder) bool { return bt.Name == builderType })
+	if i < 0 || !bts[i].IsReverse {
+		return
+	}
+	capacity, err := c.Capacity(ctx)
+	if err != nil {
+		return
+	}
+	if msg := busyWarning(bts[i], capacityOf(capacity, bts[i])); msg != "" {
+		fmt.Fprintln(os.Stderr, styles.Warning(msg))
+	}
+}
+
+// builderTypesTimeout bounds each attempt to fetch the builder types,
+// which are only displayed, so it's short: if fetching them fails, the
+// cached or built-in ones are displayed instead.
+const builderTypesTimeout = 5 * time.Second
+
+// builderListClient returns the client listing the builder types for
+// "gomote builders", the usage of "gomote create" and the shell completion.
+// The builder types of the coordinator and of the gomote server's swarming
+// backend are merged, whichever of them is selected.
+func builderListClient() *builderlist.Client {
+	c := &builderlist.Client{
+		Timeout:      builderTypesTimeout,
+		UseSnapshot:  true,
+		ListSwarming: swarmingBuilders,
+		Logf: func(format string, args ...any) {
+			fmt.Fprintln(os.Stderr, styles.Warning("# "+fmt.Sprintf(format, args...)))
+		},
+	}
+	if cfgDir, err := os.UserConfigDir(); err == nil {
+		c.CacheFile = filepath.Join(cfgDir, "gomote", "builders.json")
+	}
+	return c
+}
+
+// swarmingBuilders lists the builder types of the gomote server's
+// swarming backend. When LUCI is disabled, the gomote server is dialed
+// just for that.
+func swarmingBuilders(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var client protos.GomoteServiceClient
+	if luciDisabled() {
+		conn, err := dialServerAt(ctx, luciServerAddr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to reach %s: %w", luciServerAddr, err)
+		}
+		defer conn.Close()
+		client = protos.NewGomoteServiceClient(conn)
+	} else {
+		client = gomoteServerClient(ctx)
+	}
+	resp, err := client.ListSwarmingBuilders(ctx, &protos.ListSwarmingBuildersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve swarming builders: %s", err)
+	}
+	return resp.Builders, nil
+}
+
+// selectedBackend returns the backend instances are created with: the
+// gomote server's swarming backend, unless LUCI is disabled.
+func selectedBackend() string {
+	if luciDisabled() {
+		return builderlist.BackendCoordinator
+	}
+	return builderlist.BackendSwarming
+}
+
+// backendError returns an error if the builder type name in bts isn't
+// available via backend, saying which backend it's available via
+// instead. It returns nil if that isn't known, such as when name isn't
+// in bts or when none of bts are available via backend, which happens if
+// that backend couldn't be listed.
+func backendError(name, backend string, bts []builderlist.Builder) error {
+	i := slices.IndexFunc(bts, func(bt builderlist.Builder) bool { return bt.Name == name })
+	if i < 0 || bts[i].AvailableVia(backend) {
+		return nil
+	}
+	listed := slices.ContainsFunc(bts, func(bt builderlist.Builder) bool {
+		return slices.Contains(bt.Backends, backend)
+	})
+	if !listed {
+		return nil
+	}
+	hint := "set GOMOTEDISABLELUCI=true to use it"
+	if backend == builderlist.BackendCoordinator {
+		hint = "unset GOMOTEDISABLELUCI to use it"
+	}
+	return usageErrorf("%s: this builder type is only available via the %s backend; %s", name, strings.Join(bts[i].Backends, " and "), hint)
+}
+
+// checkBackend returns backendError for builderType and the selected
+// backend. It returns nil if the builder types can't be listed.
+func checkBackend(ctx context.Context, builderType string) error {
+	ctx, cancel := context.WithTimeout(ctx, builderTypesTimeout)
+	defer cancel()
+	c := builderListClient()
+	c.Logf = nil
+	c.UseSnapshot = false
+	bts, err := c.List(ctx)
+	if err != nil {
+		return nil
+	}
+	return backendError(builderType, selectedBackend(), bts)
+}
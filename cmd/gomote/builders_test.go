@@ -0,0 +1,238 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/build/cmd/gomote/internal/builderlist"
+	"golang.org/x/build/types"
+)
+
+func TestFilterBuilderTypes(t *testing.T) {
+	bts := []builderlist.Builder{
+		{Name: "gotip-darwin-arm64", GOOS: "darwin", GOARCH: "arm64"},
+		{Name: "gotip-linux-amd64", GOOS: "linux", GOARCH: "amd64"},
+		{Name: "gotip-linux-amd64-race", GOOS: "linux", GOARCH: "amd64"},
+		{Name: "gotip-linux-arm64", GOOS: "linux", GOARCH: "arm64"},
+		{Name: "misc-compile-mips", Ports: []string{"linux/mips", "linux/mipsle"}},
+	}
+	names := func(bts []builderlist.Builder) []string {
+		var s []string
+		for _, bt := range bts {
+			s = append(s, bt.Name)
+		}
+		return s
+	}
+	for _, tc := range []struct {
+		goos, goarch, pattern string
+		want                  []string
+	}{
+		{"", "", "", names(bts)},
+		{"linux", "", "", []string{"gotip-linux-amd64", "gotip-linux-amd64-race", "gotip-linux-arm64", "misc-compile-mips"}},
+		{"", "arm64", "", []string{"gotip-darwin-arm64", "gotip-linux-arm64"}},
+		{"linux", "amd64", "*-race", []string{"gotip-linux-amd64-race"}},
+		{"linux", "mipsle", "", []string{"misc-compile-mips"}},
+		{"windows", "", "", nil},
+	} {
+		got := names(filterBuilderTypes(bts, tc.goos, tc.goarch, tc.pattern))
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("filterBuilderTypes(%q, %q, %q) = %q; want %q", tc.goos, tc.goarch, tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestWriteBuilderTypesTable(t *testing.T) {
+	capacity := map[string]types.HostCapacity{
+		"host-darwin-arm64-13": {HostType: "host-darwin-arm64-13", Expected: 4, Connected: 2, Waiting: 6},
+	}
+	for _, tc := range []struct {
+		desc     string
+		bts      []builderlist.Builder
+		capacity map[string]types.HostCapacity
+		want     string
+	}{
+		{
+			desc: "swarming",
+			bts: []builderlist.Builder{
+				{Name: "gotip-linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{"coordinator", "swarming"}},
+				{Name: "gotip-other", Backends: []string{"swarming"}},
+				{Name: "gotip-unknown"},
+			},
+			want: `NAME               GOOS   GOARCH  BACKEND
+gotip-linux-amd64  linux  amd64   coordinator,swarming
+gotip-other        -      -       swarming
+gotip-unknown      -      -       -
+`,
+		},
+		{
+			desc: "coordinator",
+			bts: []builderlist.Builder{
+				{Name: "darwin-arm64-13", GOOS: "darwin", GOARCH: "arm64", HostType: "host-darwin-arm64-13", IsReverse: true, ExpectNum: 3},
+				{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Image: "linux-x86-bullseye:latest"},
+			},
+			want: `NAME             GOOS    GOARCH  BACKEND  REVERSE  CAPACITY    IMAGE
+darwin-arm64-13  darwin  arm64   -        yes      3 machines  -
+linux-amd64      linux   amd64   -        no       -           linux-x86-bullseye:latest
+`,
+		},
+		{
+			desc: "coordinator with capacity",
+			bts: []builderlist.Builder{
+				{Name: "darwin-arm64-13", GOOS: "darwin", GOARCH: "arm64", Backends: []string{"coordinator"}, HostType: "host-darwin-arm64-13", IsReverse: true, ExpectNum: 3},
+				{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{"coordinator", "swarming"}, Image: "linux-x86-bullseye:latest"},
+			},
+			capacity: capacity,
+			want: `NAME             GOOS    GOARCH  BACKEND               REVERSE  CAPACITY                            IMAGE
+darwin-arm64-13  darwin  arm64   coordinator           yes      4 machines, 2 connected, 6 waiting  -
+linux-amd64      linux   amd64   coordinator,swarming  no       -                                   linux-x86-bullseye:latest
+`,
+		},
+		{
+			desc: "misc-compile",
+			bts: []builderlist.Builder{
+				{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Image: "linux-x86-bullseye:latest"},
+				{Name: "misc-compile-linux-arm-arm5", GOOS: "linux", GOARCH: "arm", Ports: []string{"linux/arm"}},
+				{Name: "misc-compile-mips", Ports: []string{"linux/mips", "linux/mipsle"}},
+				{Name: "misc-compile-other"},
+			},
+			want: `NAME         GOOS   GOARCH  BACKEND  REVERSE  CAPACITY  IMAGE
+linux-amd64  linux  amd64   -        no       -         linux-x86-bullseye:latest
+
+MISC-COMPILE BUILDER         PORTS
+misc-compile-linux-arm-arm5  linux/arm
+misc-compile-mips            linux/mips, linux/mipsle
+misc-compile-other           -
+`,
+		},
+		{
+			desc: "only misc-compile",
+			bts: []builderlist.Builder{
+				{Name: "misc-compile-mips", Ports: []string{"linux/mips", "linux/mipsle"}},
+			},
+			want: `MISC-COMPILE BUILDER  PORTS
+misc-compile-mips     linux/mips, linux/mipsle
+`,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf strings.Builder
+			if err := writeBuilderTypesTable(&buf, tc.bts, tc.capacity); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("writeBuilderTypesTable =\n%s\nwant:\n%s", buf.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestCapacitySummary(t *testing.T) {
+	bt := builderlist.Builder{Name: "darwin-arm64-13", HostType: "host-darwin-arm64-13", IsReverse: true, ExpectNum: 3}
+	for _, tc := range []struct {
+		desc        string
+		bt          builderlist.Builder
+		hc          *types.HostCapacity
+		wantSummary string
+		wantWarning string
+	}{
+		{
+			desc:        "unknown capacity",
+			bt:          bt,
+			wantSummary: "3 machines",
+		},
+		{
+			desc: "unknown capacity or machines",
+			bt:   builderlist.Builder{Name: "darwin-arm64-13", IsReverse: true},
+		},
+		{
+			desc:        "idle",
+			bt:          bt,
+			hc:          &types.HostCapacity{Expected: 4, Connected: 2, Waiting: 2},
+			wantSummary: "4 machines, 2 connected, 2 waiting",
+		},
+		{
+			desc:        "busy",
+			bt:          bt,
+			hc:          &types.HostCapacity{Expected: 4, Connected: 2, Waiting: 6},
+			wantSummary: "4 machines, 2 connected, 6 waiting",
+			wantWarning: "# warning: darwin-arm64-13 is busy (4 machines, 2 connected, 6 waiting); new instances may take a while to be created",
+		},
+		{
+			desc:        "none connected",
+			bt:          builderlist.Builder{Name: "darwin-arm64-13", IsReverse: true},
+			hc:          &types.HostCapacity{Waiting: 1},
+			wantSummary: "0 connected, 1 waiting",
+			wantWarning: "# warning: darwin-arm64-13 is busy (0 connected, 1 waiting); new instances may take a while to be created",
+		},
+	} {
+		if got := capacitySummary(tc.bt, tc.hc); got != tc.wantSummary {
+			t.Errorf("%s: capacitySummary = %q; want %q", tc.desc, got, tc.wantSummary)
+		}
+		if got := busyWarning(tc.bt, tc.hc); got != tc.wantWarning {
+			t.Errorf("%s: busyWarning = %q; want %q", tc.desc, got, tc.wantWarning)
+		}
+	}
+}
+
+func TestCapacityOf(t *testing.T) {
+	capacity := map[string]types.HostCapacity{
+		"host-darwin-arm64-13": {HostType: "host-darwin-arm64-13", Connected: 2},
+	}
+	reverse := builderlist.Builder{Name: "darwin-arm64-13", HostType: "host-darwin-arm64-13", IsReverse: true}
+	if hc := capacityOf(capacity, reverse); hc == nil || hc.Connected != 2 {
+		t.Errorf("capacityOf(reverse) = %+v; want the capacity of its host type", hc)
+	}
+	// Builder types cached before their host types were listed have none.
+	for _, bt := range []builderlist.Builder{
+		{Name: "darwin-arm64-13", IsReverse: true},
+		{Name: "linux-amd64", HostType: "host-darwin-arm64-13"},
+	} {
+		if hc := capacityOf(capacity, bt); hc != nil {
+			t.Errorf("capacityOf(%+v) = %+v; want nil", bt, hc)
+		}
+	}
+	if hc := capacityOf(nil, reverse); hc != nil {
+		t.Errorf("capacityOf(nil, reverse) = %+v; want nil", hc)
+	}
+}
+
+func TestBackendError(t *testing.T) {
+	bts := []builderlist.Builder{
+		{Name: "darwin-arm64-13", Backends: []string{"coordinator"}},
+		{Name: "gotip-linux-amd64", Backends: []string{"swarming"}},
+		{Name: "linux-amd64", Backends: []string{"coordinator", "swarming"}},
+		{Name: "linux-amd64-cached"},
+	}
+	for _, tc := range []struct {
+		name, backend string
+		bts           []builderlist.Builder
+		want          string
+	}{
+		{"linux-amd64", "swarming", bts, ""},
+		{"linux-amd64", "coordinator", bts, ""},
+		{"gotip-linux-amd64", "swarming", bts, ""},
+		{"gotip-linux-amd64", "coordinator", bts, "gotip-linux-amd64: this builder type is only available via the swarming backend; unset GOMOTEDISABLELUCI to use it"},
+		{"darwin-arm64-13", "swarming", bts, "darwin-arm64-13: this builder type is only available via the coordinator backend; set GOMOTEDISABLELUCI=true to use it"},
+		{"linux-amd64-cached", "swarming", bts, ""},
+		{"unknown", "swarming", bts, ""},
+		// The swarming backend couldn't be listed.
+		{"darwin-arm64-13", "swarming", bts[:1], ""},
+	} {
+		err := backendError(tc.name, tc.backend, tc.bts)
+		var got string
+		if err != nil {
+			got = err.Error()
+			if exitCode(err) != exitUsage {
+				t.Errorf("backendError(%q, %q) exit code = %d; want %d", tc.name, tc.backend, exitCode(err), exitUsage)
+			}
+		}
+		if got != tc.want {
+			t.Errorf("backendError(%q, %q) = %q; want %q", tc.name, tc.backend, got, tc.want)
+		}
+	}
+}
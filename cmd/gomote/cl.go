@@ -0,0 +1,126 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/build/gerrit"
+)
+
+const (
+	// goGerritURL is the Gerrit server of the Go project.
+	goGerritURL = "https://go-review.googlesource.com"
+	// goRepoURL is the main Go repository, which -cl fetches CLs from.
+	goRepoURL = "https://go.googlesource.com/go"
+)
+
+// A gerritCL is a patch set of a CL of the main Go repository, which
+// create -cl applies to the pushed GOROOT.
+type gerritCL struct {
+	Number   int
+	PatchSet int
+	Subject  string
+	Ref      string // such as "refs/changes/45/12345/3"
+}
+
+func (cl *gerritCL) String() string {
+	return fmt.Sprintf("CL %d patch set %d (%s)", cl.Number, cl.PatchSet, cl.Subject)
+}
+
+// parseCL parses the value of -cl, a CL number optionally followed by a
+// slash and a patch set number, such as "12345" or "12345/3". The patch
+// set is zero if it's omitted.
+func parseCL(s string) (number, patchSet int, err error) {
+	num, ps, hasPS := strings.Cut(s, "/")
+	number, err = strconv.Atoi(num)
+	if err != nil || number <= 0 {
+		return 0, 0, usageErrorf("invalid -cl %q; want a CL number, optionally followed by /patchset", s)
+	}
+	if hasPS {
+		patchSet, err = strconv.Atoi(ps)
+		if err != nil || patchSet <= 0 {
+			return 0, 0, usageErrorf("invalid -cl %q; want a CL number, optionally followed by /patchset", s)
+		}
+	}
+	return number, patchSet, nil
+}
+
+// lookupCL looks up patch set patchSet of CL number on Gerrit, or its
+// current patch set if patchSet is zero.
+func lookupCL(ctx context.Context, number, patchSet int) (*gerritCL, error) {
+	c := gerrit.NewClient(goGerritURL, gerrit.NoAuth)
+	ci, err := c.GetChange(ctx, strconv.Itoa(number), gerrit.QueryChangesOpt{
+		Fields: []string{"ALL_REVISIONS", "ALL_COMMITS"},
+	})
+	if errors.Is(err, gerrit.ErrResourceNotExist) {
+		return nil, usageErrorf("CL %d doesn't exist", number)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to look up CL %d: %w", number, err)
+	}
+	return selectPatchSet(ci, patchSet)
+}
+
+// selectPatchSet returns patch set patchSet of the change ci, which must
+// include all its revisions, or its current patch set if patchSet is zero.
+func selectPatchSet(ci *gerrit.ChangeInfo, patchSet int) (*gerritCL, error) {
+	if ci.Project != "go" {
+		return nil, usageErrorf("CL %d is for the %s repository; -cl only applies CLs for the go repository", ci.ChangeNumber, ci.Project)
+	}
+	latest := 0
+	for commit, rev := range ci.Revisions {
+		if rev.PatchSetNumber > latest {
+			latest = rev.PatchSetNumber
+		}
+		if (patchSet == 0 && commit != ci.CurrentRevision) || (patchSet != 0 && rev.PatchSetNumber != patchSet) {
+			continue
+		}
+		cl := &gerritCL{Number: ci.ChangeNumber, PatchSet: rev.PatchSetNumber, Subject: ci.Subject, Ref: rev.Ref}
+		if rev.Commit != nil && rev.Commit.Subject != "" {
+			cl.Subject = rev.Commit.Subject
+		}
+		return cl, nil
+	}
+	if patchSet == 0 {
+		return nil, fmt.Errorf("CL %d has no current patch set", ci.ChangeNumber)
+	}
+	return nil, usageErrorf("CL %d has no patch set %d; the latest is %d", ci.ChangeNumber, patchSet, latest)
+}
+
+// applyCLCommands returns the git commands, run in the GOROOT pushed to an
+// instance, which apply cl on top of it. Since the pushed GOROOT isn't a
+// git repository, it's committed first, so that the CL can be cherry-picked
+// on top of it. Only the CL and its parent are fetched, which is all that
+// cherry-picking it needs.
+func applyCLCommands(cl *gerritCL) [][]string {
+	git := []string{"-c", "user.name=gomote", "-c", "user.email=gomote@golang.org"}
+	return [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		append(git, "commit", "-q", "--allow-empty", "-m", "GOROOT pushed by gomote"),
+		{"fetch", "-q", "--depth=2", goRepoURL, cl.Ref},
+		append(git, "cherry-pick", "FETCH_HEAD"),
+	}
+}
+
+// applyCL applies cl to the GOROOT pushed to inst. If it doesn't apply,
+// the output of git, which describes the conflicts, is printed.
+func applyCL(ctx context.Context, inst string, cl *gerritCL) error {
+	for _, args := range applyCLCommands(cl) {
+		var out bytes.Buffer
+		if err := doRun(ctx, inst, "git", args, runDir("go"), runWriters(&out)); err != nil {
+			os.Stderr.Write(out.Bytes())
+			return fmt.Errorf("unable to apply %v on %s: git %s: %w", cl, inst, strings.Join(args, " "), err)
+		}
+	}
+	fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Applied %v on %s.", cl, styles.Instance(inst))))
+	return nil
+}
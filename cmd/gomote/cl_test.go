@@ -0,0 +1,106 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/build/gerrit"
+)
+
+func TestParseCL(t *testing.T) {
+	for _, tc := range []struct {
+		in               string
+		number, patchSet int
+		wantErr          bool
+	}{
+		{in: "12345", number: 12345},
+		{in: "12345/3", number: 12345, patchSet: 3},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "12345/", wantErr: true},
+		{in: "12345/0", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "12345/3/1", wantErr: true},
+	} {
+		number, patchSet, err := parseCL(tc.in)
+		if tc.wantErr {
+			if err == nil || exitCode(err) != exitUsage {
+				t.Errorf("parseCL(%q) = %d, %d, %v; want a usage error", tc.in, number, patchSet, err)
+			}
+			continue
+		}
+		if err != nil || number != tc.number || patchSet != tc.patchSet {
+			t.Errorf("parseCL(%q) = %d, %d, %v; want %d, %d", tc.in, number, patchSet, err, tc.number, tc.patchSet)
+		}
+	}
+}
+
+func TestSelectPatchSet(t *testing.T) {
+	ci := &gerrit.ChangeInfo{
+		ChangeNumber:    12345,
+		Project:         "go",
+		Subject:         "runtime: fix the thing",
+		CurrentRevision: "bbb",
+		Revisions: map[string]gerrit.RevisionInfo{
+			"aaa": {PatchSetNumber: 1, Ref: "refs/changes/45/12345/1", Commit: &gerrit.CommitInfo{Subject: "runtime: fix a thing"}},
+			"bbb": {PatchSetNumber: 2, Ref: "refs/changes/45/12345/2", Commit: &gerrit.CommitInfo{Subject: "runtime: fix the thing"}},
+		},
+	}
+	for _, tc := range []struct {
+		patchSet int
+		want     *gerritCL
+		wantErr  string
+	}{
+		{0, &gerritCL{Number: 12345, PatchSet: 2, Subject: "runtime: fix the thing", Ref: "refs/changes/45/12345/2"}, ""},
+		{1, &gerritCL{Number: 12345, PatchSet: 1, Subject: "runtime: fix a thing", Ref: "refs/changes/45/12345/1"}, ""},
+		{3, nil, "CL 12345 has no patch set 3; the latest is 2"},
+	} {
+		got, err := selectPatchSet(ci, tc.patchSet)
+		if tc.wantErr != "" {
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("selectPatchSet(%d) = %v, %v; want error %q", tc.patchSet, got, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("selectPatchSet(%d) = %+v, %v; want %+v", tc.patchSet, got, err, tc.want)
+		}
+	}
+
+	tools := &gerrit.ChangeInfo{ChangeNumber: 54321, Project: "tools"}
+	if _, err := selectPatchSet(tools, 0); err == nil || !strings.Contains(err.Error(), "tools repository") {
+		t.Errorf("selectPatchSet(tools CL) = %v; want an error about the repository", err)
+	}
+}
+
+func TestApplyCLCommands(t *testing.T) {
+	cl := &gerritCL{Number: 12345, PatchSet: 2, Ref: "refs/changes/45/12345/2"}
+	cmds := applyCLCommands(cl)
+	if got := cmds[0]; !reflect.DeepEqual(got, []string{"init", "-q"}) {
+		t.Errorf("first command = git %q; want git init", got)
+	}
+	var fetch, pick []string
+	for _, args := range cmds {
+		switch {
+		case slices.Contains(args, "fetch"):
+			fetch = args
+		case slices.Contains(args, "cherry-pick"):
+			pick = args
+		}
+	}
+	if want := []string{"fetch", "-q", "--depth=2", "https://go.googlesource.com/go", "refs/changes/45/12345/2"}; !reflect.DeepEqual(fetch, want) {
+		t.Errorf("fetch command = git %q; want git %q", fetch, want)
+	}
+	if pick == nil || pick[len(pick)-1] != "FETCH_HEAD" {
+		t.Errorf("cherry-pick command = git %q; want it to cherry-pick FETCH_HEAD", pick)
+	}
+	if last := cmds[len(cmds)-1]; !reflect.DeepEqual(last, pick) {
+		t.Errorf("last command = git %q; want the cherry-pick", last)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func completion(args []string) error {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "completion usage: gomote completion bash|zsh|fish")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Prints a script which completes gomote subcommands, their flags")
+		fmt.Fprintln(os.Stderr, "and builder types for the given shell. For example:")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "\tsource <(gomote completion bash)")
+		os.Exit(exitUsage)
+	}
+	if len(args) != 1 {
+		usage()
+	}
+	global := completionFlags(flag.CommandLine)
+	cmds := completionCommands()
+	switch args[0] {
+	case "bash":
+		return writeBashCompletion(os.Stdout, global, cmds)
+	case "zsh":
+		return writeZshCompletion(os.Stdout, global, cmds)
+	case "fish":
+		return writeFishCompletion(os.Stdout, global, cmds)
+	case "builders":
+		// Used by the completion scripts to complete the argument of create.
+		return writeBuilderTypes(os.Stdout)
+	}
+	usage()
+	return nil
+}
+
+// completionFlag describes a flag for the completion scripts.
+type completionFlag struct {
+	name  string
+	usage string // first sentence of the flag's usage
+	value bool   // the flag takes a value
+}
+
+// completionCommand describes a subcommand for the completion scripts.
+type completionCommand struct {
+	name  string
+	des   string
+	flags []completionFlag
+}
+
+// completionFlags returns the flags defined in fs, sorted by name.
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		usage, _, _ = strings.Cut(usage, "\n")
+		if i := strings.Index(usage, ". "); i >= 0 {
+			usage = usage[:i]
+		}
+		bf, isBool := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:  f.Name,
+			usage: strings.TrimSuffix(strings.TrimSpace(usage), "."),
+			value: !isBool || !bf.IsBoolFlag(),
+		})
+	})
+	return flags
+}
+
+// completionCommands returns the registered commands and their flags, sorted by name.
+func completionCommands() []completionCommand {
+	var cmds []completionCommand
+	for _, name := range sortedCommands() {
+		c := commands[name]
+		cc := completionCommand{name: name, des: strings.TrimSpace(c.des)}
+		if c.flags != nil {
+			cc.flags = completionFlags(c.flags())
+		}
+		cmds = append(cmds, cc)
+	}
+	return cmds
+}
+
+// writeBuilderTypes writes the builder types accepted by create, one per line.
+func writeBuilderTypes(w io.Writer) error {
+	bts, err := builderListClient().List(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, bt := range bts {
+		fmt.Fprintln(w, bt.Name)
+	}
+	return nil
+}
+
+func flagNames(flags []completionFlag, valueOnly bool) []string {
+	var names []string
+	for _, f := range flags {
+		if !valueOnly || f.value {
+			names = append(names, "-"+f.name)
+		}
+	}
+	return names
+}
+
+func writeBashCompletion(w io.Writer, global []completionFlag, cmds []completionCommand) error {
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.name)
+	}
+	fmt.Fprintf(w, `# bash completion for gomote. Generated by "gomote completion bash".
+
+_gomote() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local cmd="" i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "${COMP_WORDS[i]}" in
+`)
+	if vf := flagNames(global, true); len(vf) > 0 {
+		fmt.Fprintf(w, "\t\t%s) ((i++)) ;;\n", strings.Join(vf, "|"))
+	}
+	fmt.Fprintf(w, `		-*) ;;
+		*) cmd="${COMP_WORDS[i]}"; break ;;
+		esac
+	done
+	local flags="" valueflags=""
+	case "$cmd" in
+	"")
+		flags=%q
+		valueflags=%q
+		;;
+`, strings.Join(flagNames(global, false), " "), strings.Join(flagNames(global, true), " "))
+	for _, c := range cmds {
+		fmt.Fprintf(w, "\t%s)\n\t\tflags=%q\n\t\tvalueflags=%q\n\t\t;;\n", c.name,
+			strings.Join(flagNames(c.flags, false), " "), strings.Join(flagNames(c.flags, true), " "))
+	}
+	fmt.Fprintf(w, `	esac
+	case " $valueflags " in
+	*" $prev "*)
+		# Complete the value of a flag with the default completion.
+		return
+		;;
+	esac
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	elif [[ -z "$cmd" ]]; then
+		COMPREPLY=($(compgen -W %q -- "$cur"))
+	elif [[ "$cmd" == create ]]; then
+		COMPREPLY=($(compgen -W "$(gomote completion builders 2>/dev/null)" -- "$cur"))
+	fi
+}
+
+complete -o default -F _gomote gomote
+`, strings.Join(names, " "))
+	return nil
+}
+
+// zshQuote quotes s for use as a single argument in a zsh script.
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, `'`, `'\''`) + "'"
+}
+
+// zshEscape escapes the characters of s which are special in _arguments
+// and _describe specifications.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+func zshFlagSpecs(flags []completionFlag) []string {
+	var specs []string
+	for _, f := range flags {
+		if f.value {
+			specs = append(specs, zshQuote(fmt.Sprintf("-%s=[%s]:value:_default", f.name, zshEscape(f.usage))))
+		} else {
+			specs = append(specs, zshQuote(fmt.Sprintf("-%s[%s]", f.name, zshEscape(f.usage))))
+		}
+	}
+	return specs
+}
+
+func writeZshCompletion(w io.Writer, global []completionFlag, cmds []completionCommand) error {
+	fmt.Fprintf(w, `#compdef gomote
+# zsh completion for gomote. Generated by "gomote completion zsh".
+
+_gomote_builders() {
+	local -a builders
+	builders=(${(f)"$(gomote completion builders 2>/dev/null)"})
+	_describe -t builders 'builder type' builders
+}
+
+_gomote() {
+	local curcontext="$curcontext" state line
+	local -a commands
+	commands=(
+`)
+	for _, c := range cmds {
+		fmt.Fprintf(w, "\t\t%s\n", zshQuote(c.name+":"+zshEscape(c.des)))
+	}
+	fmt.Fprintf(w, "\t)\n\t_arguments -C \\\n")
+	for _, spec := range zshFlagSpecs(global) {
+		fmt.Fprintf(w, "\t\t%s \\\n", spec)
+	}
+	fmt.Fprintf(w, `		'1:command:->command' \
+		'*::arg:->args'
+	case $state in
+	command)
+		_describe -t commands 'gomote command' commands
+		;;
+	args)
+		case $line[1] in
+`)
+	for _, c := range cmds {
+		rest := `'*:file:_files'`
+		if c.name == "create" {
+			rest = `'*:builder type:_gomote_builders'`
+		}
+		fmt.Fprintf(w, "\t\t%s)\n\t\t\t_arguments \\\n", c.name)
+		for _, spec := range zshFlagSpecs(c.flags) {
+			fmt.Fprintf(w, "\t\t\t\t%s \\\n", spec)
+		}
+		fmt.Fprintf(w, "\t\t\t\t%s\n\t\t\t;;\n", rest)
+	}
+	fmt.Fprintf(w, `		esac
+		;;
+	esac
+}
+
+if [[ "$funcstack[1]" = "_gomote" ]]; then
+	_gomote "$@"
+else
+	compdef _gomote gomote
+fi
+`)
+	return nil
+}
+
+// fishQuote quotes s for use as a single argument in a fish script.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func writeFishFlags(w io.Writer, cond string, flags []completionFlag) {
+	for _, f := range flags {
+		req := ""
+		if f.value {
+			req = " -r"
+		}
+		fmt.Fprintf(w, "complete -c gomote -n %s -o %s%s -d %s\n", fishQuote(cond), f.name, req, fishQuote(f.usage))
+	}
+}
+
+func writeFishCompletion(w io.Writer, global []completionFlag, cmds []completionCommand) error {
+	fmt.Fprintf(w, "# fish completion for gomote. Generated by \"gomote completion fish\".\n\n")
+	writeFishFlags(w, "__fish_use_subcommand", global)
+	for _, c := range cmds {
+		fmt.Fprintf(w, "complete -c gomote -n __fish_use_subcommand -f -a %s -d %s\n", c.name, fishQuote(c.des))
+	}
+	for _, c := range cmds {
+		cond := "__fish_seen_subcommand_from " + c.name
+		writeFishFlags(w, cond, c.flags)
+		if c.name == "create" {
+			fmt.Fprintf(w, "complete -c gomote -n %s -f -a '(gomote completion builders 2>/dev/null)'\n", fishQuote(cond))
+		}
+	}
+	return nil
+}
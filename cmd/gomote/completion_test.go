@@ -0,0 +1,57 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompletionFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("force", false, "do not ask for confirmation.")
+	fs.String("dir", "", "Directory to run from. Defaults to the work directory.")
+	var env stringSlice
+	fs.Var(&env, "e", "Environment variable KEY=value.")
+	got := completionFlags(fs)
+	want := []completionFlag{
+		{name: "dir", usage: "Directory to run from", value: true},
+		{name: "e", usage: "Environment variable KEY=value", value: true},
+		{name: "force", usage: "do not ask for confirmation", value: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completionFlags() = %+v; want %+v", got, want)
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	cmds := []completionCommand{
+		{name: "create", des: "create a buildlet", flags: []completionFlag{{name: "count", usage: "number of instances", value: true}}},
+		{name: "rm", des: "delete files", flags: []completionFlag{{name: "f", usage: "don't ask"}}},
+	}
+	global := []completionFlag{{name: "server", usage: "Address for GRPC server", value: true}}
+	for _, tc := range []struct {
+		shell string
+		write func(*strings.Builder) error
+	}{
+		{"bash", func(w *strings.Builder) error { return writeBashCompletion(w, global, cmds) }},
+		{"zsh", func(w *strings.Builder) error { return writeZshCompletion(w, global, cmds) }},
+		{"fish", func(w *strings.Builder) error { return writeFishCompletion(w, global, cmds) }},
+	} {
+		t.Run(tc.shell, func(t *testing.T) {
+			var b strings.Builder
+			if err := tc.write(&b); err != nil {
+				t.Fatalf("writing %s completion: %v", tc.shell, err)
+			}
+			for _, s := range []string{"create", "rm", "count", "server", "completion builders"} {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("%s completion does not contain %q", tc.shell, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+var compressionFlag = flag.String("compression", "", "compression of the RPCs to and from the server, such as the large directory listings of push: gzip or none (default is $GOMOTE_COMPRESSION, or none)")
+
+// rpcCompression returns the name of the compressor of RPCs selected by
+// -compression or $GOMOTE_COMPRESSION, or "" if RPCs aren't compressed.
+// Older servers can't decompress RPCs, so they aren't compressed by
+// default.
+func rpcCompression(getenv func(string) string) (string, error) {
+	c := *compressionFlag
+	if c == "" {
+		c = getenv("GOMOTE_COMPRESSION")
+	}
+	switch c {
+	case "", "none":
+		return "", nil
+	case gzip.Name:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid compression %q; want gzip or none", c)
+}
+
+// compressionDialOptions returns the options compressing the RPCs made
+// with them using compressor, if it's not empty. The server compresses
+// its responses with the compressor of the request.
+func compressionDialOptions(compressor string) []grpc.DialOption {
+	if compressor == "" {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.UseCompressor(compressor))}
+}
+
+// uploadBufferSize is the size of the chunks the archives and files sent
+// to instances are uploaded in. Uploads are already compressed, so they
+// are limited by the throughput of the connection, which is higher with
+// fewer, larger writes than the 4 KiB of http.DefaultTransport; see
+// BenchmarkUpload.
+const uploadBufferSize = 256 << 10
+
+// uploadClient is the HTTP client used to upload archives and files.
+var uploadClient = newUploadClient(uploadBufferSize)
+
+func newUploadClient(bufferSize int) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.WriteBufferSize = bufferSize
+	return &http.Client{Transport: t}
+}
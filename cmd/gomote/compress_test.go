@@ -0,0 +1,101 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRPCCompression(t *testing.T) {
+	defer func(c string) { *compressionFlag = c }(*compressionFlag)
+	for _, tc := range []struct {
+		flag    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"", nil, "", false},
+		{"none", nil, "", false},
+		{"gzip", nil, "gzip", false},
+		{"", map[string]string{"GOMOTE_COMPRESSION": "gzip"}, "gzip", false},
+		{"none", map[string]string{"GOMOTE_COMPRESSION": "gzip"}, "", false},
+		{"zstd", nil, "", true},
+	} {
+		*compressionFlag = tc.flag
+		got, err := rpcCompression(fakeEnv(tc.env))
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("rpcCompression with -compression=%q and %v = %q, %v; want %q, error %t", tc.flag, tc.env, got, err, tc.want, tc.wantErr)
+		}
+		if opts := compressionDialOptions(got); (len(opts) > 0) != (got != "") {
+			t.Errorf("compressionDialOptions(%q) = %d options", got, len(opts))
+		}
+	}
+}
+
+// syntheticGOROOT writes a GOROOT of n Go source files of about 16 KiB
+// each to a temporary directory, and returns it and the files.
+func syntheticGOROOT(tb testing.TB, n int) (string, []string) {
+	goroot := tb.TempDir()
+	var files []string
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("src/pkg%d/file%d.go", i/10, i)
+		var src strings.Builder
+		fmt.Fprintf(&src, "package pkg%d\n", i/10)
+		for j := 0; src.Len() < 16<<10; j++ {
+			fmt.Fprintf(&src, "\n// F%d returns the sum of x and %d.\nfunc F%d(x int) int {\n\treturn x + %d\n}\n", j, i*j, j, i*j)
+		}
+		path := filepath.Join(goroot, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src.String()), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		files = append(files, name)
+	}
+	return goroot, files
+}
+
+// BenchmarkUpload measures pushing a synthetic GOROOT to a local server,
+// with the compression levels and upload buffer sizes push may use.
+func BenchmarkUpload(b *testing.B) {
+	goroot, files := syntheticGOROOT(b, 500)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	defer func(c *http.Client) { uploadClient = c }(uploadClient)
+
+	for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression} {
+		for _, size := range []int{4 << 10, uploadBufferSize} {
+			b.Run(fmt.Sprintf("level=%d/buffer=%d", level, size), func(b *testing.B) {
+				uploadClient = newUploadClient(size)
+				ctx := context.Background()
+				var uploaded int
+				for i := 0; i < b.N; i++ {
+					tgz, err := generateDeltaTgz(goroot, files, level, nil)
+					if err != nil {
+						b.Fatal(err)
+					}
+					uploaded = tgz.Len()
+					if err := uploadToGCS(ctx, nil, tgz, "go.tar.gz", srv.URL); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(uploaded), "uploaded-bytes/op")
+			})
+		}
+	}
+}
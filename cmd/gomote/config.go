@@ -0,0 +1,258 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The gomote config file contains default values for flags, one per line, in the
+// form "key = value". Blank lines and lines starting with "#" are ignored.
+//
+// A key without a dot sets the default of a global flag, such as "server".
+// A key of the form "cmd.flag" sets the default of a command's flag, such as
+// "run.dir". Explicitly passed flags always override the config file.
+//
+// Keys which are not flags are listed in configOnlyKeys.
+
+// config holds the contents of the config file, loaded at startup.
+var config = map[string]string{}
+
+// configOnlyKeys are the config keys which do not correspond to a flag.
+var configOnlyKeys = map[string]string{
+	"create.builder": "default builder type for create",
+}
+
+func configCmd(args []string) error {
+	cm := map[string]struct {
+		run  func([]string) error
+		desc string
+	}{
+		"get":  {configGet, "print the value of a config key"},
+		"set":  {configSet, "set the value of a config key"},
+		"list": {configList, "list all config keys and values"},
+		"path": {configPrintPath, "print the path of the config file"},
+	}
+	if len(args) == 0 {
+		var cmds []string
+		for cmd := range cm {
+			cmds = append(cmds, cmd)
+		}
+		sort.Strings(cmds)
+		fmt.Fprintf(os.Stderr, "Usage of gomote config: gomote [global-flags] config <cmd> [cmd-flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Commands:\n\n")
+		for _, name := range cmds {
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, cm[name].desc)
+		}
+		fmt.Fprintln(os.Stderr)
+		os.Exit(exitUsage)
+	}
+	subCmd := args[0]
+	sc, ok := cm[subCmd]
+	if !ok {
+		return usageErrorf("unknown sub-command %q\n", subCmd)
+	}
+	return sc.run(args[1:])
+}
+
+func configGet(args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "config get usage: gomote config get <key>")
+		os.Exit(exitUsage)
+	}
+	v, ok := config[args[0]]
+	if !ok {
+		return fmt.Errorf("config key %q is not set", args[0])
+	}
+	fmt.Println(v)
+	return nil
+}
+
+func configSet(args []string) error {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "config set usage: gomote config set <key> <value>")
+		os.Exit(exitUsage)
+	}
+	key, value := args[0], args[1]
+	if strings.ContainsAny(key, " \t=#\n") || key == "" {
+		return usageErrorf("invalid config key %q", key)
+	}
+	if strings.Contains(value, "\n") {
+		return usageErrorf("invalid config value %q", value)
+	}
+	if !knownConfigKey(key) {
+		fmt.Fprintf(os.Stderr, "# Warning: %q is not a known config key.\n", key)
+	}
+	config[key] = value
+	return storeConfig(config)
+}
+
+func configList(args []string) error {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "config list usage: gomote config list")
+		os.Exit(exitUsage)
+	}
+	return writeConfig(os.Stdout, config)
+}
+
+func configPrintPath(args []string) error {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "config path usage: gomote config path")
+		os.Exit(exitUsage)
+	}
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
+
+// parseFlags parses args with fs after setting the defaults found in the
+// config file for fs. Explicitly passed flags override the config file.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	applyConfig(fs, strings.ReplaceAll(fs.Name(), " ", ".")+".")
+	return fs.Parse(args)
+}
+
+// applyConfig sets the flags in fs from the config keys with the given prefix.
+// Keys which aren't flags of fs are skipped silently: warnUnknownConfigKeys
+// has warned about them once already.
+func applyConfig(fs *flag.FlagSet, prefix string) {
+	for key, value := range config {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok || strings.Contains(name, ".") || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "# Warning: ignoring config key %q: %v\n", key, err)
+		}
+	}
+}
+
+// knownConfigKey reports whether key may be a valid config key. The flags
+// of commands without a FlagSet of their own, and of subcommands, such as
+// "group.create.x", aren't known, so their keys are assumed to be valid.
+func knownConfigKey(key string) bool {
+	if _, ok := configOnlyKeys[key]; ok {
+		return true
+	}
+	cmd, name, ok := strings.Cut(key, ".")
+	if !ok {
+		return flag.Lookup(key) != nil
+	}
+	c, ok := commands[cmd]
+	if !ok {
+		return false
+	}
+	if c.flags == nil || strings.Contains(name, ".") {
+		return true
+	}
+	return c.flags().Lookup(name) != nil
+}
+
+// warnUnknownConfigKeys warns about config keys which cannot be valid,
+// rather than failing, so that older clients tolerate newer configs.
+func warnUnknownConfigKeys() {
+	var keys []string
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !knownConfigKey(key) {
+			fmt.Fprintf(os.Stderr, "# Warning: ignoring unknown config key %q\n", key)
+		}
+	}
+}
+
+// loadConfig reads the config file. A missing config file is not an error.
+func loadConfig() (map[string]string, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, fmt.Errorf("acquiring config path: %w", err)
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg, err := parseConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func storeConfig(cfg map[string]string) error {
+	path, err := configPath()
+	if err != nil {
+		return fmt.Errorf("acquiring config path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := writeConfig(&buf, cfg); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// parseConfig parses the contents of a config file.
+func parseConfig(r io.Reader) (map[string]string, error) {
+	cfg := make(map[string]string)
+	s := bufio.NewScanner(r)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: want key = value, got %q", lineno, line)
+		}
+		cfg[key] = strings.TrimSpace(value)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// writeConfig writes cfg in the config file format, sorted by key.
+func writeConfig(w io.Writer, cfg map[string]string) error {
+	var keys []string
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s = %s\n", key, cfg[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func configPath() (string, error) {
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, "gomote", "config"), nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConfig(t *testing.T) {
+	const in = `
+# A comment.
+server = localhost:8080
+run.dir=go/src
+  create.builder =  linux-amd64  
+empty =
+`
+	got, err := parseConfig(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("parseConfig() = %v; want no error", err)
+	}
+	want := map[string]string{
+		"server":         "localhost:8080",
+		"run.dir":        "go/src",
+		"create.builder": "linux-amd64",
+		"empty":          "",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseConfig() mismatch (-want, +got):\n%s", diff)
+	}
+
+	var buf strings.Builder
+	if err := writeConfig(&buf, got); err != nil {
+		t.Fatalf("writeConfig() = %v; want no error", err)
+	}
+	roundTrip, err := parseConfig(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("parseConfig(writeConfig()) = %v; want no error", err)
+	}
+	if diff := cmp.Diff(want, roundTrip); diff != "" {
+		t.Errorf("parseConfig(writeConfig()) mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParseConfigError(t *testing.T) {
+	for _, in := range []string{"no-value", "= value"} {
+		if _, err := parseConfig(strings.NewReader(in)); err == nil {
+			t.Errorf("parseConfig(%q) = nil; want error", in)
+		}
+	}
+}
+
+func TestParseFlagsConfig(t *testing.T) {
+	defer func(old map[string]string) { config = old }(config)
+	config = map[string]string{
+		"run.dir":     "go/src",
+		"run.system":  "true",
+		"run.unknown": "x",
+		"ls.dir":      "ignored",
+	}
+	newFlagSet := func() (*flag.FlagSet, *string, *bool) {
+		fs := flag.NewFlagSet("run", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return fs, fs.String("dir", "", ""), fs.Bool("system", false, "")
+	}
+
+	fs, dir, system := newFlagSet()
+	if err := parseFlags(fs, []string{"arg"}); err != nil {
+		t.Fatalf("parseFlags() = %v; want no error", err)
+	}
+	if *dir != "go/src" || !*system {
+		t.Errorf("parseFlags() set -dir=%q -system=%t; want config defaults go/src, true", *dir, *system)
+	}
+
+	fs, dir, _ = newFlagSet()
+	if err := parseFlags(fs, []string{"-dir", "go/test", "arg"}); err != nil {
+		t.Fatalf("parseFlags() = %v; want no error", err)
+	}
+	if *dir != "go/test" {
+		t.Errorf("parseFlags() set -dir=%q; want explicit flag go/test to override config", *dir)
+	}
+}
+
+func TestKnownConfigKey(t *testing.T) {
+	defer func(old map[string]command) { commands = old }(commands)
+	commands = map[string]command{
+		"run": {name: "run", flags: func() *flag.FlagSet {
+			fs := flag.NewFlagSet("run", flag.ContinueOnError)
+			fs.String("dir", "", "")
+			return fs
+		}},
+		"group": {name: "group"},
+	}
+	for key, want := range map[string]bool{
+		"create.builder":   true,
+		"oplog":            true,
+		"no-such-flag":     false,
+		"run.dir":          true,
+		"run.unknown":      false,
+		"nocommand.dir":    false,
+		"group.create.dir": true,
+	} {
+		if got := knownConfigKey(key); got != want {
+			t.Errorf("knownConfigKey(%q) = %t; want %t", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// rmConfirmThreshold is the number of paths above which "gomote rm"
+// asks for confirmation before removing them.
+const rmConfirmThreshold = 10
+
+// errAborted is returned when the user declines to confirm an operation.
+var errAborted = errors.New("operation aborted")
+
+// confirm lists the targets of a destructive action and asks the user to
+// confirm it by typing want, reading the answer from in and writing the
+// prompt to out. It returns nil if the action should proceed.
+//
+// If force is set, the action proceeds without a prompt. Otherwise, if the
+// input is not interactive, the action is refused rather than guessing an answer.
+func confirm(in io.Reader, out io.Writer, interactive, force bool, action string, targets []string, want string) error {
+	if force {
+		return nil
+	}
+	if !interactive {
+		return usageErrorf("refusing to %s without confirmation; use -f to proceed when stdin is not a terminal", action)
+	}
+	fmt.Fprintf(out, "This will %s:\n", action)
+	for _, t := range targets {
+		fmt.Fprintf(out, "\t%s\n", t)
+	}
+	fmt.Fprintf(out, "Type %q to continue: ", want)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("reading confirmation: %w", err)
+	}
+	if strings.TrimSpace(line) != want {
+		return errAborted
+	}
+	return nil
+}
+
+// confirmStdio is confirm using the standard input and standard error of the process.
+func confirmStdio(force bool, action string, targets []string, want string) error {
+	return confirm(os.Stdin, os.Stderr, term.IsTerminal(int(os.Stdin.Fd())), force, action, targets, want)
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	targets := []string{"user-foo-linux-amd64-0", "user-foo-linux-amd64-1"}
+	testCases := []struct {
+		desc        string
+		input       string
+		interactive bool
+		force       bool
+		want        string
+		wantErr     error // nil means the action should proceed
+		wantRefused bool  // the action is refused without prompting
+	}{
+		{desc: "force", interactive: true, force: true, want: "y"},
+		{desc: "force non-interactive", force: true, want: "y"},
+		{desc: "non-interactive", interactive: false, input: "y\n", want: "y", wantRefused: true},
+		{desc: "confirmed", interactive: true, input: "y\n", want: "y"},
+		{desc: "confirmed without newline", interactive: true, input: "y", want: "y"},
+		{desc: "confirmed with spaces", interactive: true, input: "  y \n", want: "y"},
+		{desc: "declined", interactive: true, input: "n\n", want: "y", wantErr: errAborted},
+		{desc: "empty answer", interactive: true, input: "\n", want: "y", wantErr: errAborted},
+		{desc: "no input", interactive: true, input: "", want: "y", wantErr: errAborted},
+		{desc: "group name", interactive: true, input: "debug\n", want: "debug"},
+		{desc: "group name answered with y", interactive: true, input: "y\n", want: "debug", wantErr: errAborted},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var out strings.Builder
+			err := confirm(strings.NewReader(tc.input), &out, tc.interactive, tc.force, "destroy 2 instances", targets, tc.want)
+			switch {
+			case tc.wantRefused:
+				if err == nil || errors.Is(err, errAborted) {
+					t.Fatalf("confirm() = %v; want refusal error", err)
+				}
+				if out.Len() != 0 {
+					t.Errorf("confirm() wrote %q; want no prompt", out.String())
+				}
+				return
+			case tc.wantErr != nil:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("confirm() = %v; want %v", err, tc.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("confirm() = %v; want no error", err)
+			}
+			if tc.force {
+				if out.Len() != 0 {
+					t.Errorf("confirm() wrote %q; want no prompt with force", out.String())
+				}
+				return
+			}
+			for _, target := range targets {
+				if !strings.Contains(out.String(), target) {
+					t.Errorf("confirm() prompt %q does not list target %q", out.String(), target)
+				}
+			}
+			if !strings.Contains(out.String(), `"`+tc.want+`"`) {
+				t.Errorf("confirm() prompt %q does not ask for %q", out.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestNeedsRmConfirmation(t *testing.T) {
+	many := make([]string, rmConfirmThreshold+1)
+	for i := range many {
+		many[i] = "file"
+	}
+	testCases := []struct {
+		desc  string
+		insts []string
+		paths []string
+		want  bool
+	}{
+		{"single file", []string{"a"}, []string{"go/bin/go"}, false},
+		{"several files", []string{"a"}, []string{"go/bin/go", "go/pkg"}, false},
+		{"multiple instances", []string{"a", "b"}, []string{"go/bin/go"}, true},
+		{"work directory", []string{"a"}, []string{"."}, true},
+		{"glob", []string{"a"}, []string{"go/bin/*"}, true},
+		{"many paths", []string{"a"}, many, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := needsRmConfirmation(tc.insts, tc.paths); got != tc.want {
+				t.Errorf("needsRmConfirmation(%q, %q) = %t; want %t", tc.insts, tc.paths, got, tc.want)
+			}
+		})
+	}
+}
@@ -6,148 +6,93 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
-	"log"
-	"net/http"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"golang.org/x/build/cmd/gomote/internal/builderlist"
+	"golang.org/x/build/cmd/gomote/progresstypes"
 	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/types"
 	"golang.org/x/sync/errgroup"
 )
 
-type builderType struct {
-	Name      string
-	IsReverse bool
-	ExpectNum int
-}
+// builderListingTimeout bounds how long fetching the coordinator's listing
+// of builders may take.
+const builderListingTimeout = 30 * time.Second
 
-func builders() (bt []builderType) {
-	type builderInfo struct {
-		HostType string
-	}
-	type hostInfo struct {
-		IsReverse      bool
-		ExpectNum      int
-		ContainerImage string
-		VMImage        string
+func create(args []string) error {
+	var flags createFlags
+	fs := createFlagSet(&flags)
+	parseFlags(fs, args)
+	builderType := fs.Arg(0)
+	if fs.NArg() == 0 && config["create.builder"] != "" {
+		builderType = config["create.builder"]
+	} else if fs.NArg() != 1 {
+		fs.Usage()
 	}
-	// resj is the response JSON from the builders.
-	var resj struct {
-		Builders map[string]builderInfo
-		Hosts    map[string]hostInfo
+	if flags.installGo != "" && flags.setup {
+		return usageErrorf("-install-go and -setup are mutually exclusive")
 	}
-	res, err := http.Get("https://farmer.golang.org/builders?mode=json")
-	if err != nil {
-		log.Fatal(err)
+	if flags.setupOpts.set() && !flags.setup {
+		return usageErrorf("-setup-run, -log-dir, -cl, -no-auto-bootstrap and -forward-env require -setup")
 	}
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		log.Fatalf("fetching builder types: %s", res.Status)
+	if !flags.noResolve {
+		var err error
+		builderType, err = resolveBuilderType(context.Background(), builderType)
+		if err != nil {
+			return err
+		}
 	}
-	if err := json.NewDecoder(res.Body).Decode(&resj); err != nil {
-		log.Fatalf("decoding builder types: %v", err)
+	if err := checkBackend(context.Background(), builderType); err != nil {
+		return err
 	}
-	for b, bi := range resj.Builders {
-		if strings.HasPrefix(b, "misc-compile") {
-			continue
+	// Find the release of Go to install before creating instances, so
+	// that an unknown version doesn't leave them unused.
+	var goArchive goFile
+	if flags.installGo != "" {
+		goos, goarch := builderlist.Platform(builderType)
+		if goos == "" {
+			return usageErrorf("unable to determine the GOOS and GOARCH of %s for -install-go", builderType)
 		}
-		hi, ok := resj.Hosts[bi.HostType]
-		if !ok {
-			continue
+		releases, err := fetchGoReleases(context.Background())
+		if err != nil {
+			return err
 		}
-		if !hi.IsReverse && hi.ContainerImage == "" && hi.VMImage == "" {
-			continue
+		if goArchive, err = findGoArchive(releases, flags.installGo, goos, goarch); err != nil {
+			return err
 		}
-		bt = append(bt, builderType{
-			Name:      b,
-			IsReverse: hi.IsReverse,
-			ExpectNum: hi.ExpectNum,
-		})
 	}
-	sort.Slice(bt, func(i, j int) bool {
-		return bt[i].Name < bt[j].Name
-	})
-	return
-}
-
-func swarmingBuilders() ([]string, error) {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
-	client := gomoteServerClient(ctx)
-	resp, err := client.ListSwarmingBuilders(ctx, &protos.ListSwarmingBuildersRequest{})
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve swarming builders: %s", err)
-	}
-	return resp.Builders, nil
-}
-
-func create(args []string) error {
-	fs := flag.NewFlagSet("create", flag.ContinueOnError)
-
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "create usage: gomote create [create-opts] <type>")
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "If there's a valid group specified, new instances are")
-		fmt.Fprintln(os.Stderr, "automatically added to the group. If the group in")
-		fmt.Fprintln(os.Stderr, "$GOMOTE_GROUP doesn't exist, and there's no other group")
-		fmt.Fprintln(os.Stderr, "specified, it will be created and new instances will be")
-		fmt.Fprintln(os.Stderr, "added to that group.")
-		fs.PrintDefaults()
-		fmt.Fprintln(os.Stderr, "\nValid types:")
-		if luciDisabled() {
-			for _, bt := range builders() {
-				var warn string
-				if bt.IsReverse {
-					if bt.ExpectNum > 0 {
-						warn = fmt.Sprintf("   [limited capacity: %d machines]", bt.ExpectNum)
-					} else {
-						warn = "   [limited capacity]"
-					}
-				}
-				fmt.Fprintf(os.Stderr, "  * %s%s\n", bt.Name, warn)
-			}
-			os.Exit(1)
-		} else {
-			swarmingBuilders, err := swarmingBuilders()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, " %s\n", err)
-			} else {
-				for _, builder := range swarmingBuilders {
-					fmt.Fprintf(os.Stderr, "  * %s\n", builder)
-				}
-			}
-			os.Exit(1)
+	// Likewise, look up the CL to apply and check the setup commands
+	// first.
+	var su *setup
+	if flags.setup {
+		var err error
+		if su, err = newSetup(context.Background(), flags.setupOpts, flags.count == 1); err != nil {
+			return err
 		}
+		if _, err := su.commands(context.Background(), builderType); err != nil {
+			return err
+		}
+		reportForwardedEnv(os.Stderr, su.env)
 	}
-	var status bool
-	fs.BoolVar(&status, "status", true, "print regular status updates while waiting")
-	var count int
-	fs.IntVar(&count, "count", 1, "number of instances to create")
-	var setup bool
-	fs.BoolVar(&setup, "setup", false, "set up the instance by pushing GOROOT and building the Go toolchain")
-	var newGroup string
-	fs.StringVar(&newGroup, "new-group", "", "also create a new group and add the new instances to it")
-	var useGolangbuild bool
-	fs.BoolVar(&useGolangbuild, "use-golangbuild", true, "disable the installation of build dependencies installed by golangbuild")
-
-	fs.Parse(args)
-	if fs.NArg() != 1 {
-		fs.Usage()
-	}
-	builderType := fs.Arg(0)
+	t := newTimer("create")
+	defer t.report(os.Stderr, flags.timings)
 
 	var groupMu sync.Mutex
 	group := activeGroup
 	var err error
-	if newGroup != "" {
-		group, err = doCreateGroup(newGroup)
+	if flags.newGroup != "" {
+		group, err = doCreateGroup(flags.newGroup)
 		if err != nil {
 			return err
 		}
@@ -159,102 +104,541 @@ func create(args []string) error {
 		}
 	}
 
-	var tmpOutDir string
-	var tmpOutDirOnce sync.Once
+	// Keep track of the instances which have been created so they can be
+	// reported, or destroyed, if the command is interrupted.
+	var createdMu sync.Mutex
+	var created []string
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sigc:
+		case <-done:
+			return
+		}
+		createdMu.Lock()
+		insts := append([]string(nil), created...)
+		createdMu.Unlock()
+		handleCreateInterrupt(insts, flags.destroyOnInterrupt)
+		if group != nil && !flags.destroyOnInterrupt {
+			groupMu.Lock()
+			if err := storeGroup(group); err != nil {
+				fmt.Fprintf(os.Stderr, "# Unable to store group %q: %v\n", group.Name, err)
+			}
+			groupMu.Unlock()
+		}
+		exitOp(exitInterrupted, errInterrupted)
+	}()
+
+	warnBusyBuilder(builderType)
+
+	// Without resolving the builder type, it isn't known to be retired
+	// until the server fails to create instances of it.
+	var retiredOnce sync.Once
+	warnRetired := func() {
+		if flags.noResolve {
+			retiredOnce.Do(func() { warnRetiredBuilderType(builderType) })
+		}
+	}
+
 	eg, ctx := errgroup.WithContext(context.Background())
 	client := gomoteServerClient(ctx)
-	for i := 0; i < count; i++ {
+	for i := 0; i < flags.count; i++ {
 		i := i
 		eg.Go(func() error {
 			start := time.Now()
 			var exp []string
-			if !useGolangbuild {
+			if !flags.useGolangbuild {
 				exp = append(exp, "disable-golang-build")
 			}
-			stream, err := client.CreateInstance(ctx, &protos.CreateInstanceRequest{BuilderType: builderType, ExperimentOption: exp})
+			stream, err := client.CreateInstance(ctx, &protos.CreateInstanceRequest{
+				BuilderType:      builderType,
+				ExperimentOption: exp,
+				LifetimeSeconds:  int64(flags.lifetime / time.Second),
+				Labels:           flags.labels.labels,
+			})
 			if err != nil {
+				warnRetired()
 				return fmt.Errorf("failed to create buildlet: %w", err)
 			}
-			var inst string
+			var inst, workDir string
+			// The instance boots once there are no requests ahead of it in the queue.
+			var bootStart time.Time
 		updateLoop:
 			for {
 				update, err := stream.Recv()
+				if err == nil && bootStart.IsZero() && (waitersAhead(update) == 0 || update.GetStatus() == protos.CreateInstanceResponse_COMPLETE) {
+					bootStart = time.Now()
+				}
 				switch {
 				case err == io.EOF:
 					break updateLoop
 				case err != nil:
+					warnRetired()
+					emitProgressDone(progresstypes.Event{Phase: progresstypes.PhaseCreate, Builder: builderType}, err)
 					return fmt.Errorf("failed to create buildlet (%d): %w", i+1, err)
-				case update.GetStatus() != protos.CreateInstanceResponse_COMPLETE && status:
-					fmt.Fprintf(os.Stderr, "# still creating %s (%d) after %v; %d requests ahead of you\n", builderType, i+1, time.Since(start).Round(time.Second), update.GetWaitersAhead())
+				case update.GetStatus() != protos.CreateInstanceResponse_COMPLETE:
+					emitProgress(progresstypes.Event{
+						Phase:   progresstypes.PhaseCreate,
+						Builder: builderType,
+						Message: fmt.Sprintf("waiting for %v; %s", time.Since(start).Round(time.Second), queueStatus(update, time.Now())),
+					})
+					if flags.status {
+						fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# still creating %s (%d) after %v; %s", builderType, i+1, time.Since(start).Round(time.Second), queueStatus(update, time.Now()))))
+					}
 				case update.GetStatus() == protos.CreateInstanceResponse_COMPLETE:
 					inst = update.GetInstance().GetGomoteId()
+					workDir = update.GetInstance().GetWorkingDir()
+					emitProgressDone(progresstypes.Event{Phase: progresstypes.PhaseCreate, Builder: builderType, Instance: inst}, nil)
+					noteOpTarget(inst)
+					createdMu.Lock()
+					created = append(created, inst)
+					createdMu.Unlock()
 				}
 			}
+			if !bootStart.IsZero() {
+				t.add(span{Name: "queue", Instance: inst, Start: start, Duration: bootStart.Sub(start)})
+				t.record("boot", inst, bootStart)
+			}
 			fmt.Println(inst)
 			if group != nil {
 				groupMu.Lock()
 				group.Instances = append(group.Instances, inst)
 				groupMu.Unlock()
 			}
-			if !setup {
+			if flags.installGo != "" {
+				defer t.span("install-go", inst)()
+				return doInstallGo(ctx, inst, workDir, goArchive, false)
+			}
+			if su == nil {
 				return nil
 			}
+			return su.run(ctx, t, inst, builderType, workDir)
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	if su != nil {
+		if err := su.report(os.Stderr); err != nil {
+			if group != nil {
+				if err := storeGroup(group); err != nil {
+					fmt.Fprintf(os.Stderr, "# Unable to store group %q: %v\n", group.Name, err)
+				}
+			}
+			return err
+		}
+	}
+	if group != nil {
+		return storeGroup(group)
+	}
+	return nil
+}
 
-			// -setup is set, so push GOROOT and run make.bash.
+// A setupCmd is a command create -setup runs in the work directory of the
+// instances once GOROOT is pushed.
+type setupCmd struct {
+	cmd  string
+	args []string
+}
 
-			tmpOutDirOnce.Do(func() {
-				tmpOutDir, err = os.MkdirTemp("", "gomote")
-			})
-			if err != nil {
-				return fmt.Errorf("failed to create a temporary directory for setup output: %w", err)
-			}
+func (sc setupCmd) String() string {
+	return strings.Join(append([]string{sc.cmd}, sc.args...), " ")
+}
 
-			// Push GOROOT.
-			detailedProgress := count == 1
-			goroot, err := getGOROOT()
-			if err != nil {
-				return err
-			}
-			if !detailedProgress {
-				fmt.Fprintf(os.Stderr, "# Pushing GOROOT %q to %q...\n", goroot, inst)
-			}
-			if err := doPush(ctx, inst, goroot, false, detailedProgress); err != nil {
-				return err
-			}
+// setupCommands returns the commands create -setup runs for the -setup-run
+// target, given makeScript, the path of the script building Go relative to
+// the work directory, such as "go/src/make.bash":
+//
+//   - "make", or "", runs makeScript;
+//   - "all" runs the all.bash script, or its equivalent, instead, which
+//     builds Go and then tests it;
+//   - anything else is a command, and its arguments separated by spaces,
+//     run after makeScript. A relative command is relative to GOROOT, such
+//     as "bin/go test -short std".
+func setupCommands(makeScript, target string) ([]setupCmd, error) {
+	switch target {
+	case "", "make":
+		return []setupCmd{{cmd: makeScript}}, nil
+	case "all":
+		dir, script := path.Split(makeScript)
+		if !strings.HasPrefix(script, "make.") {
+			return nil, usageErrorf("unable to determine how to run all tests from %s; use -setup-run with a custom target", makeScript)
+		}
+		return []setupCmd{{cmd: dir + "all" + strings.TrimPrefix(script, "make")}}, nil
+	}
+	f := strings.Fields(target)
+	if len(f) == 0 {
+		return nil, usageErrorf("invalid -setup-run %q", target)
+	}
+	cmd := f[0]
+	if !path.IsAbs(cmd) {
+		cmd = path.Join("go", cmd)
+	}
+	return []setupCmd{{cmd: makeScript}, {cmd: cmd, args: f[1:]}}, nil
+}
 
-			// Run make.bash or make.bat.
-			cmd := "go/src/make.bash"
-			if strings.Contains(builderType, "windows") {
-				cmd = "go/src/make.bat"
-			}
+// defaultSetupLogDir returns the directory the output of create -setup
+// started at now is written to without -log-dir. It's in the user's cache
+// directory, so that it's easy to find again, and named after now.
+func defaultSetupLogDir(now time.Time) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gomote", "setup", now.Format("20060102-150405"))
+}
 
-			// Create a file to write output to so it doesn't get lost.
-			outf, err := os.Create(filepath.Join(tmpOutDir, fmt.Sprintf("%s.stdout", inst)))
-			if err != nil {
-				return err
+// A setupResult is the result of create -setup on an instance.
+type setupResult struct {
+	log string          // the path of the file its output was written to
+	err *cmdFailedError // the command which failed, or nil
+}
+
+// setupLogTailLines is the number of lines at the end of the log of a
+// failed instance which reportSetupResults writes.
+const setupLogTailLines = 20
+
+// reportSetupResults writes the results of create -setup, by instance, to
+// w, with the path of their logs. If there are several instances, the
+// last lines of the logs of those which failed are included, since their
+// output wasn't printed. It returns the failure of the single instance, or
+// errCommandsFailed if any of several instances failed.
+func reportSetupResults(w io.Writer, results map[string]setupResult) error {
+	insts := make([]string, 0, len(results))
+	failed := 0
+	for inst, r := range results {
+		insts = append(insts, inst)
+		if r.err != nil {
+			failed++
+		}
+	}
+	sort.Strings(insts)
+	switch len(insts) {
+	case 0:
+		return nil
+	case 1:
+		r := results[insts[0]]
+		fmt.Fprintln(w, styles.Status(fmt.Sprintf("# Wrote results from %s to %q.", styles.Instance(insts[0]), r.log)))
+		if r.err != nil {
+			return r.err
+		}
+		return nil
+	}
+	for _, inst := range insts {
+		r := results[inst]
+		if r.err == nil {
+			fmt.Fprintln(w, styles.Success(fmt.Sprintf("# ok   %s  %s", styles.Instance(inst), r.log)))
+			continue
+		}
+		fmt.Fprintln(w, styles.Failure(fmt.Sprintf("# FAIL %s  %s: %q %s", styles.Instance(inst), r.log, r.err.cmd, r.err.reason())))
+		data, err := readTail(r.log, 64<<10)
+		if err != nil {
+			fmt.Fprintf(w, "#   unable to read the log: %v\n", err)
+			continue
+		}
+		for _, line := range lastLines(data, setupLogTailLines) {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+	summary := fmt.Sprintf("# Setup succeeded on %d of %d instances.", len(insts)-failed, len(insts))
+	if failed > 0 {
+		fmt.Fprintln(w, styles.Failure(summary))
+		return errCommandsFailed
+	}
+	fmt.Fprintln(w, styles.Success(summary))
+	return nil
+}
+
+// createFlags are the flags of the create command.
+type createFlags struct {
+	status             bool
+	count              int
+	setup              bool
+	newGroup           string
+	useGolangbuild     bool
+	destroyOnInterrupt bool
+	all                bool
+	noResolve          bool
+	installGo          string
+	setupOpts          setupFlags
+	lifetime           time.Duration
+	labels             labelFlag
+	timings            timingsFlag
+}
+
+func createFlagSet(flags *createFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "create usage: gomote create [create-opts] <type>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The type is optional if create.builder is set in the config file.")
+		fmt.Fprintln(os.Stderr, "It may be a prefix of the name of a builder type, such as")
+		fmt.Fprintln(os.Stderr, "linux-amd64 for linux-amd64-bookworm, if only one builder")
+		fmt.Fprintln(os.Stderr, "type which isn't deprecated has that prefix. It must be")
+		fmt.Fprintln(os.Stderr, "available via the selected backend: the swarming one, or")
+		fmt.Fprintln(os.Stderr, "the coordinator if GOMOTEDISABLELUCI is set.")
+		fmt.Fprintln(os.Stderr, "If there's a valid group specified, new instances are")
+		fmt.Fprintln(os.Stderr, "automatically added to the group. If the group in")
+		fmt.Fprintln(os.Stderr, "$GOMOTE_GROUP doesn't exist, and there's no other group")
+		fmt.Fprintln(os.Stderr, "specified, it will be created and new instances will be")
+		fmt.Fprintln(os.Stderr, "added to that group.")
+		fs.PrintDefaults()
+		fmt.Fprintln(os.Stderr, "\nValid types (run \"gomote builders\" for details):")
+		c := builderListClient()
+		bts, err := c.List(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " %s\n", err)
+		}
+		// The current capacity is a nicety, so the types are
+		// listed without it if it can't be fetched.
+		var capacity map[string]types.HostCapacity
+		if slices.ContainsFunc(bts, func(bt builderlist.Builder) bool { return bt.IsReverse }) {
+			capacity, _ = c.Capacity(context.Background())
+		}
+		for _, bt := range bts {
+			var warn string
+			if bt.IsReverse {
+				if summary := capacitySummary(bt, capacityOf(capacity, bt)); summary != "" {
+					warn = fmt.Sprintf("   [limited capacity: %s]", summary)
+				} else {
+					warn = "   [limited capacity]"
+				}
 			}
-			defer func() {
-				outf.Close()
-				fmt.Fprintf(os.Stderr, "# Wrote results from %q to %q.\n", inst, outf.Name())
-			}()
-			fmt.Fprintf(os.Stderr, "# Streaming results from %q to %q...\n", inst, outf.Name())
-
-			// If this is the only command running, print to stdout too, for convenience and
-			// backwards compatibility.
-			outputs := []io.Writer{outf}
-			if detailedProgress {
-				outputs = append(outputs, os.Stdout)
-			} else {
-				fmt.Fprintf(os.Stderr, "# Running %q on %q...\n", cmd, inst)
+			var backends string
+			if len(bt.Backends) > 0 {
+				backends = fmt.Sprintf("   [%s]", strings.Join(bt.Backends, ", "))
 			}
-			return doRun(ctx, inst, cmd, []string{}, runWriters(outputs...))
-		})
+			fmt.Fprintf(os.Stderr, "  * %s%s%s\n", bt.Name, backends, warn)
+		}
+		if flags.all {
+			writeMiscCompileUsage(os.Stderr, c)
+		}
+		os.Exit(exitUsage)
 	}
-	if err := eg.Wait(); err != nil {
-		return err
+	fs.BoolVar(&flags.status, "status", true, "print regular status updates while waiting")
+	fs.IntVar(&flags.count, "count", 1, "number of instances to create")
+	fs.BoolVar(&flags.setup, "setup", false, "set up the instance by pushing GOROOT and building the Go toolchain")
+	fs.StringVar(&flags.newGroup, "new-group", "", "also create a new group and add the new instances to it")
+	fs.BoolVar(&flags.useGolangbuild, "use-golangbuild", true, "disable the installation of build dependencies installed by golangbuild")
+	fs.BoolVar(&flags.destroyOnInterrupt, "destroy-on-interrupt", false, "destroy any instances already created if interrupted before completion")
+	fs.BoolVar(&flags.all, "all", false, "with no type, also list the coordinator's misc-compile builders, which can't be used as types, and the ports they cross-compile for")
+	fs.StringVar(&flags.installGo, "install-go", "", "install an official release of Go, such as 1.22.6 or latest, from go.dev/dl on the new instances, instead of pushing GOROOT with -setup")
+	flags.setupOpts.register(fs, true)
+	fs.BoolVar(&flags.noResolve, "no-resolve", false, "use the type as is, rather than resolving a prefix of the name of a builder type")
+	fs.DurationVar(&flags.lifetime, "lifetime", 0, "destroy the instances after this long, even if they're in use; limited by the server (default is to expire them once idle)")
+	fs.Var(&flags.labels, "label", "attach the `key=value` label to the instances; may be repeated")
+	fs.Var(&flags.timings, "timings", timingsUsage)
+	return fs
+}
+
+// writeMiscCompileUsage writes the misc-compile builders listed by c for
+// "gomote create -all" to w. They're listed separately from the valid
+// types since instances can't be created with them.
+func writeMiscCompileUsage(w io.Writer, c *builderlist.Client) {
+	c.All = true
+	bts, err := c.List(context.Background())
+	if err != nil {
+		return
 	}
-	if group != nil {
-		return storeGroup(group)
+	var misc []builderlist.Builder
+	for _, bt := range bts {
+		if bt.MiscCompile() {
+			misc = append(misc, bt)
+		}
 	}
-	return nil
+	if len(misc) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nMisc-compile builders, which only cross-compile and aren't valid types:")
+	for _, bt := range misc {
+		ports := "unknown ports"
+		if len(bt.Ports) > 0 {
+			ports = strings.Join(bt.Ports, ", ")
+		}
+		fmt.Fprintf(w, "  * %s   [%s]\n", bt.Name, ports)
+	}
+}
+
+// handleCreateInterrupt reports the instances which were created before "gomote create"
+// was interrupted. If destroy is set, the instances are destroyed instead of being leaked
+// until they expire.
+func handleCreateInterrupt(insts []string, destroy bool) {
+	if len(insts) == 0 {
+		fmt.Fprintln(os.Stderr, "# Interrupted before any instances were created.")
+		return
+	}
+	if !destroy {
+		fmt.Fprintln(os.Stderr, "# Interrupted; the following instances were already created and will remain until they expire:")
+		for _, inst := range insts {
+			fmt.Fprintf(os.Stderr, "#\t%s\n", inst)
+		}
+		return
+	}
+	// The create context may already be canceled, so use a fresh one.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := gomoteServerClient(ctx)
+	for _, inst := range insts {
+		if _, err := client.DestroyInstance(ctx, &protos.DestroyInstanceRequest{
+			GomoteId: inst,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "# Interrupted; unable to destroy %s: %v\n", inst, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "# Interrupted; destroyed %s\n", inst)
+	}
+}
+
+// waitersAhead returns the number of requests ahead of a waiting create
+// request. It counts only those for the same host type if the server
+// reports them.
+func waitersAhead(update *protos.CreateInstanceResponse) int64 {
+	if qp := update.GetQueuePosition(); qp != nil {
+		return qp.GetWaitersAhead()
+	}
+	return update.GetWaitersAhead()
+}
+
+// queueStatus describes the position of a waiting create request in the
+// queue at now, including how many machines are connected for static
+// (reverse) host types and how much longer it's estimated to wait, if the
+// server estimates it.
+func queueStatus(update *protos.CreateInstanceResponse, now time.Time) string {
+	qp := update.GetQueuePosition()
+	if qp == nil {
+		return fmt.Sprintf("%d requests ahead", update.GetWaitersAhead())
+	}
+	msg := fmt.Sprintf("%d requests ahead for %s", qp.GetWaitersAhead(), qp.GetHostType())
+	if qp.GetPoolSize() > 0 {
+		msg += fmt.Sprintf(", %d of %d machines connected", qp.GetConnected(), qp.GetPoolSize())
+	}
+	if left := estimatedWait(qp, now); left > 0 {
+		msg += fmt.Sprintf(", about %v left", left)
+	}
+	return msg
+}
+
+// estimatedWait returns how much longer the request at qp is estimated to
+// wait at now, or zero if the server made no estimate or it has passed.
+func estimatedWait(qp *protos.CreateInstanceResponse_QueuePosition, now time.Time) time.Duration {
+	left := time.Duration(qp.GetEstimatedWaitSeconds()) * time.Second
+	if left == 0 {
+		return 0
+	}
+	if at := time.Unix(qp.GetEstimatedAt(), 0); qp.GetEstimatedAt() != 0 && now.After(at) {
+		left -= now.Sub(at).Round(time.Second)
+	}
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// setupMakeScript returns the path of the script which builds Go on the
+// instances of builderType, relative to their work directory, such as
+// "go/src/make.bash". For instances created by the coordinator, it's the
+// one in the coordinator's listing of builders, if the listing can be
+// fetched and has it. The listing doesn't describe the builder types of
+// the swarming backend, whose script is the one for their GOOS.
+func setupMakeScript(ctx context.Context, builderType string) string {
+	if selectedBackend() != builderlist.BackendCoordinator {
+		goos, _ := builderlist.Platform(builderType)
+		return path.Join("go", types.BuildScript(goos))
+	}
+	l, err := types.FetchBuilderListing(ctx, nil, types.BuilderListingURL, builderListingTimeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Unable to fetch the builder listing: %v", err)))
+	}
+	script, ok := makeScriptOf(l, builderType)
+	if !ok {
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# The builder listing doesn't say how to build Go on %s; guessing %s.", builderType, script)))
+	}
+	return path.Join("go", script)
+}
+
+// makeScriptOf returns the path, relative to GOROOT, of the script which
+// builds Go on builderType according to l, which may be nil, and true.
+// If l doesn't have it, such as because the coordinator predates the
+// scripts in its listing, it's guessed from builderType instead, and
+// makeScriptOf returns false.
+func makeScriptOf(l *types.BuilderListing, builderType string) (string, bool) {
+	if l != nil {
+		if bi, ok := l.Builders[builderType]; ok && bi.MakeScript != "" {
+			return bi.MakeScript, true
+		}
+	}
+	goos := ""
+	for _, g := range []string{"windows", "plan9"} {
+		if strings.Contains(builderType, g) {
+			goos = g
+		}
+	}
+	return types.BuildScript(goos), false
+}
+
+// minSetupDiskFree is the free space on an instance's work volume below
+// which create -setup warns that building the toolchain is likely to run
+// out of space.
+const minSetupDiskFree = 2 << 30
+
+// warnLowDiskSpace prints a warning if inst has less than
+// minSetupDiskFree bytes of free space. Instances whose buildlet doesn't
+// report its free space are assumed to have plenty.
+func warnLowDiskSpace(ctx context.Context, inst string) {
+	resp, err := gomoteServerClient(ctx).InstanceStatus(ctx, &protos.InstanceStatusRequest{GomoteId: inst})
+	if err != nil {
+		return
+	}
+	if msg := lowDiskSpaceWarning(inst, resp.GetDiskFreeBytes()); msg != "" {
+		fmt.Fprintln(os.Stderr, styles.Warning(msg))
+	}
+}
+
+// lowDiskSpaceWarning returns the warning for an instance with free bytes
+// of free space, or "" if there's no need for one.
+func lowDiskSpaceWarning(inst string, free int64) string {
+	if free <= 0 || free >= minSetupDiskFree {
+		return ""
+	}
+	return fmt.Sprintf("# warning: %s has only %s of free disk space; building the toolchain needs about %s", inst, formatBytes(free), formatBytes(minSetupDiskFree))
+}
+
+// readTail returns at most the last n bytes of the file at path.
+func readTail(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if off := fi.Size() - n; off > 0 {
+		if _, err := f.Seek(off, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
+
+// lastLines returns the last n lines of data, without their line endings.
+// A final line without one counts as a line.
+func lastLines(data []byte, n int) []string {
+	lines := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
 }
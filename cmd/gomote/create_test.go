@@ -0,0 +1,266 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/types"
+)
+
+func TestQueueStatus(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for _, tc := range []struct {
+		update *protos.CreateInstanceResponse
+		ahead  int64
+		want   string
+	}{
+		{
+			// An older server reports only the overall count.
+			update: &protos.CreateInstanceResponse{WaitersAhead: 7},
+			ahead:  7,
+			want:   "7 requests ahead",
+		},
+		{
+			update: &protos.CreateInstanceResponse{
+				WaitersAhead: 7,
+				QueuePosition: &protos.CreateInstanceResponse_QueuePosition{
+					HostType:     "host-linux-amd64-bullseye",
+					WaitersAhead: 0,
+				},
+			},
+			ahead: 0,
+			want:  "0 requests ahead for host-linux-amd64-bullseye",
+		},
+		{
+			update: &protos.CreateInstanceResponse{
+				WaitersAhead: 7,
+				QueuePosition: &protos.CreateInstanceResponse_QueuePosition{
+					HostType:     "host-aix-ppc64-osuosl",
+					WaitersAhead: 2,
+					PoolSize:     3,
+					Connected:    1,
+				},
+			},
+			ahead: 2,
+			want:  "2 requests ahead for host-aix-ppc64-osuosl, 1 of 3 machines connected",
+		},
+		{
+			update: &protos.CreateInstanceResponse{
+				QueuePosition: &protos.CreateInstanceResponse_QueuePosition{
+					HostType:             "host-linux-amd64-bullseye",
+					EstimatedWaitSeconds: 300,
+					EstimatedAt:          now.Add(-time.Minute).Unix(),
+				},
+			},
+			want: "0 requests ahead for host-linux-amd64-bullseye, about 4m0s left",
+		},
+		{
+			// The estimate has passed.
+			update: &protos.CreateInstanceResponse{
+				QueuePosition: &protos.CreateInstanceResponse_QueuePosition{
+					HostType:             "host-linux-amd64-bullseye",
+					EstimatedWaitSeconds: 30,
+					EstimatedAt:          now.Add(-time.Minute).Unix(),
+				},
+			},
+			want: "0 requests ahead for host-linux-amd64-bullseye",
+		},
+		{
+			// A server which doesn't report when it made the estimate.
+			update: &protos.CreateInstanceResponse{
+				QueuePosition: &protos.CreateInstanceResponse_QueuePosition{
+					HostType:             "host-linux-amd64-bullseye",
+					EstimatedWaitSeconds: 90,
+				},
+			},
+			want: "0 requests ahead for host-linux-amd64-bullseye, about 1m30s left",
+		},
+	} {
+		if got := waitersAhead(tc.update); got != tc.ahead {
+			t.Errorf("waitersAhead(%v) = %d; want %d", tc.update, got, tc.ahead)
+		}
+		if got := queueStatus(tc.update, now); got != tc.want {
+			t.Errorf("queueStatus(%v, %v) = %q; want %q", tc.update, now, got, tc.want)
+		}
+	}
+}
+
+func TestMakeScriptOf(t *testing.T) {
+	l := &types.BuilderListing{Builders: map[string]types.BuilderInfo{
+		"plan9-386":     {Name: "plan9-386", MakeScript: "src/make.rc"},
+		"windows-amd64": {Name: "windows-amd64", MakeScript: "src/make.bat"},
+		// Older coordinators don't list the scripts.
+		"linux-amd64": {Name: "linux-amd64"},
+	}}
+	testCases := []struct {
+		desc        string
+		l           *types.BuilderListing
+		builderType string
+		want        string
+		wantListed  bool
+	}{
+		{"listed", l, "plan9-386", "src/make.rc", true},
+		{"listed windows", l, "windows-amd64", "src/make.bat", true},
+		{"no script", l, "linux-amd64", "src/make.bash", false},
+		{"not listed", l, "gotip-windows-arm64", "src/make.bat", false},
+		{"not listed plan9", l, "gotip-plan9-amd64", "src/make.rc", false},
+		{"no listing", nil, "gotip-linux-amd64", "src/make.bash", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, listed := makeScriptOf(tc.l, tc.builderType)
+			if got != tc.want || listed != tc.wantListed {
+				t.Errorf("makeScriptOf(%q) = %q, %t; want %q, %t", tc.builderType, got, listed, tc.want, tc.wantListed)
+			}
+		})
+	}
+}
+
+func TestSetupMakeScriptSwarming(t *testing.T) {
+	t.Setenv("GOMOTEDISABLELUCI", "")
+	// The coordinator's listing isn't fetched for builder types of the
+	// swarming backend, so this doesn't need the network.
+	for builderType, want := range map[string]string{
+		"gotip-linux-amd64":       "go/src/make.bash",
+		"gotip-windows-arm64":     "go/src/make.bat",
+		"go1.22-plan9-386":        "go/src/make.rc",
+		"gotip-darwin-amd64_14":   "go/src/make.bash",
+		"x_tools-gotip-linux-386": "go/src/make.bash",
+	} {
+		if got := setupMakeScript(context.Background(), builderType); got != want {
+			t.Errorf("setupMakeScript(%q) = %q; want %q", builderType, got, want)
+		}
+	}
+}
+
+func TestSetupCommands(t *testing.T) {
+	for _, tc := range []struct {
+		makeScript, target string
+		want               []string
+	}{
+		{"go/src/make.bash", "", []string{"go/src/make.bash"}},
+		{"go/src/make.bash", "make", []string{"go/src/make.bash"}},
+		{"go/src/make.bash", "all", []string{"go/src/all.bash"}},
+		{"go/src/make.bat", "all", []string{"go/src/all.bat"}},
+		{"go/src/make.rc", "all", []string{"go/src/all.rc"}},
+		{"go/src/make.bash", "bin/go test -short std", []string{"go/src/make.bash", "go/bin/go test -short std"}},
+		{"go/src/make.bash", "/usr/bin/env", []string{"go/src/make.bash", "/usr/bin/env"}},
+		{"go/src/build.sh", "all", nil},
+		{"go/src/make.bash", "  ", nil},
+	} {
+		cmds, err := setupCommands(tc.makeScript, tc.target)
+		var got []string
+		for _, sc := range cmds {
+			got = append(got, sc.String())
+		}
+		if tc.want == nil {
+			if err == nil || exitCode(err) != exitUsage {
+				t.Errorf("setupCommands(%q, %q) = %q, %v; want a usage error", tc.makeScript, tc.target, got, err)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tc.want) {
+			t.Errorf("setupCommands(%q, %q) = %q, %v; want %q", tc.makeScript, tc.target, got, err, tc.want)
+		}
+	}
+}
+
+func TestReportSetupResults(t *testing.T) {
+	dir := t.TempDir()
+	logA := filepath.Join(dir, "inst-a.log")
+	logB := filepath.Join(dir, "inst-b.log")
+	var out strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&out, "line %d\n", i)
+	}
+	for name, data := range map[string]string{logA: "ALL TESTS PASSED\n", logB: out.String()} {
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failed := &cmdFailedError{inst: "inst-b", cmd: "go/src/all.bash", err: errors.New("exit status 1")}
+	var tail strings.Builder
+	for i := 11; i <= 30; i++ {
+		fmt.Fprintf(&tail, "    line %d\n", i)
+	}
+	for _, tc := range []struct {
+		desc    string
+		results map[string]setupResult
+		want    string
+		wantErr error
+	}{
+		{
+			desc: "no setup",
+		},
+		{
+			desc:    "one instance failed",
+			results: map[string]setupResult{"inst-b": {log: logB, err: failed}},
+			want:    fmt.Sprintf("# Wrote results from \"inst-b\" to %q.\n", logB),
+			wantErr: failed,
+		},
+		{
+			desc:    "one instance",
+			results: map[string]setupResult{"inst-a": {log: logA}},
+			want:    fmt.Sprintf("# Wrote results from \"inst-a\" to %q.\n", logA),
+		},
+		{
+			desc:    "several instances",
+			results: map[string]setupResult{"inst-b": {log: logB, err: failed}, "inst-a": {log: logA}},
+			want: fmt.Sprintf("# ok   \"inst-a\"  %s\n# FAIL \"inst-b\"  %s: \"go/src/all.bash\" exit status 1\n", logA, logB) +
+				tail.String() +
+				"# Setup succeeded on 1 of 2 instances.\n",
+			wantErr: errCommandsFailed,
+		},
+		{
+			desc:    "several instances succeeded",
+			results: map[string]setupResult{"inst-b": {log: logB}, "inst-a": {log: logA}},
+			want:    fmt.Sprintf("# ok   \"inst-a\"  %s\n# ok   \"inst-b\"  %s\n# Setup succeeded on 2 of 2 instances.\n", logA, logB),
+		},
+	} {
+		var buf strings.Builder
+		err := reportSetupResults(&buf, tc.results)
+		if err != tc.wantErr {
+			t.Errorf("%s: reportSetupResults = %v; want %v", tc.desc, err, tc.wantErr)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("%s: reportSetupResults wrote:\n%s\nwant:\n%s", tc.desc, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		n    int
+		want []string
+	}{
+		{"", 3, nil},
+		{"\n", 3, nil},
+		{"a\nb\nc\nd\n", 2, []string{"c", "d"}},
+		{"a\nb\nc", 2, []string{"b", "c"}},
+		{"a\r\nb\r\n", 5, []string{"a", "b"}},
+	} {
+		if got := lastLines([]byte(tc.data), tc.n); !slices.Equal(got, tc.want) {
+			t.Errorf("lastLines(%q, %d) = %q; want %q", tc.data, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestDefaultSetupLogDir(t *testing.T) {
+	dir := defaultSetupLogDir(time.Date(2024, 10, 16, 15, 4, 5, 0, time.UTC))
+	if want := filepath.Join("gomote", "setup", "20241016-150405"); !strings.HasSuffix(dir, want) {
+		t.Errorf("defaultSetupLogDir = %q; want a path ending in %q", dir, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	debugFlag    = flag.Bool("debug", debugFromEnv(), "log every RPC made to the gomote server to stderr (default is $GOMOTE_DEBUG)")
+	debugLogFlag = flag.String("debug-log", "", "log every RPC made to the gomote server to this file instead of stderr; implies -debug")
+)
+
+// debugLog logs the RPCs made to the gomote server. It is nil unless
+// debug logging is enabled.
+var debugLog *log.Logger
+
+func debugFromEnv() bool {
+	on, _ := strconv.ParseBool(os.Getenv("GOMOTE_DEBUG"))
+	return on
+}
+
+// setupDebugLog enables debug logging if it was requested by -debug or -debug-log.
+func setupDebugLog() error {
+	var w io.Writer = os.Stderr
+	if *debugLogFlag != "" {
+		f, err := os.OpenFile(*debugLogFlag, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("opening debug log: %w", err)
+		}
+		// The file is closed when the process exits.
+		w = f
+	} else if !*debugFlag {
+		return nil
+	}
+	debugLog = log.New(w, "# gomote: ", log.LstdFlags|log.Lmicroseconds)
+	observeRPCs(debugObserver{})
+	return nil
+}
+
+// debugObserver logs the RPCs made to the gomote server to debugLog.
+// Streams are logged when they're opened and closed, with the number of
+// messages sent and received.
+type debugObserver struct{}
+
+func (debugObserver) RequestStart(r buildlet.Request) {
+	if r.Stream {
+		debugLog.Printf("stream %s%s: opened", r.Method, debugInstance(r))
+	}
+}
+
+func (debugObserver) RequestDone(r buildlet.Request, s buildlet.RequestStats) {
+	if r.Stream {
+		debugLog.Printf("stream %s%s: closed; sent %d messages (%d bytes), received %d messages (%d bytes), took %v, %s",
+			r.Method, debugInstance(r), s.MessagesSent, s.BytesSent, s.MessagesReceived, s.BytesReceived, s.Duration.Round(time.Millisecond), debugStatus(s.Err))
+		return
+	}
+	debugLog.Printf("rpc %s%s: sent %d bytes, received %d bytes, took %v, %s",
+		r.Method, debugInstance(r), s.BytesSent, s.BytesReceived, s.Duration.Round(time.Millisecond), debugStatus(s.Err))
+}
+
+// debugInstance returns a description of the instance targeted by r, if
+// any.
+func debugInstance(r buildlet.Request) string {
+	if r.Instance != "" {
+		return " on " + r.Instance
+	}
+	return ""
+}
+
+func debugStatus(err error) string {
+	if err == nil {
+		return "status OK"
+	}
+	return fmt.Sprintf("status %s: %s", status.Code(err), status.Convert(err).Message())
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDebugUnaryInterceptor(t *testing.T) {
+	var buf strings.Builder
+	defer func(l *log.Logger) { debugLog = l }(debugLog)
+	debugLog = log.New(&buf, "", 0)
+	defer func(list []buildlet.Observer) { rpcObservers.list = list }(rpcObservers.list)
+	rpcObservers.list = []buildlet.Observer{debugObserver{}}
+
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Errorf(codes.NotFound, "instance not found")
+	}
+	req := &protos.InstanceAliveRequest{GomoteId: "user-foo-linux-amd64-0"}
+	err := observeUnaryInterceptor(context.Background(), "/protos.GomoteService/InstanceAlive", req, &protos.InstanceAliveResponse{}, nil, invoker)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("observeUnaryInterceptor() = %v; want the invoker's error", err)
+	}
+	for _, want := range []string{"/protos.GomoteService/InstanceAlive", "user-foo-linux-amd64-0", "NotFound"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("debug log %q does not contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestDebugStreamInterceptor(t *testing.T) {
+	var buf strings.Builder
+	defer func(l *log.Logger) { debugLog = l }(debugLog)
+	debugLog = log.New(&buf, "", 0)
+	defer func(list []buildlet.Observer) { rpcObservers.list = list }(rpcObservers.list)
+	rpcObservers.list = []buildlet.Observer{debugObserver{}}
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &fakeClientStream{replies: []string{"hello ", "world\n"}}, nil
+	}
+	cs, err := observeStreamInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/protos.GomoteService/ExecuteCommand", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.SendMsg(&protos.ExecuteCommandRequest{GomoteId: "user-foo-linux-amd64-0", Command: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if err := cs.RecvMsg(new(protos.ExecuteCommandResponse)); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("debug log has %d lines; want the stream's open and close:\n%s", len(lines), buf.String())
+	}
+	if want := "stream /protos.GomoteService/ExecuteCommand on user-foo-linux-amd64-0: opened"; lines[0] != want {
+		t.Errorf("debug log line 1 = %q; want %q", lines[0], want)
+	}
+	for _, want := range []string{"closed", "sent 1 messages", "received 2 messages", "status OK"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("debug log line 2 %q does not contain %q", lines[1], want)
+		}
+	}
+}
@@ -10,42 +10,37 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	"golang.org/x/build/internal/gomote/protos"
 )
 
 func destroy(args []string) error {
-	fs := flag.NewFlagSet("destroy", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "destroy usage: gomote destroy [instance]")
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "Destroys a single instance, or all instances in a group.")
-		fmt.Fprintln(os.Stderr, "Instance argument is optional with a group.")
-		fs.PrintDefaults()
-		if fs.NArg() == 0 {
-			// List buildlets that you might want to destroy.
-			client := gomoteServerClient(context.Background())
-			resp, err := client.ListInstances(context.Background(), &protos.ListInstancesRequest{})
-			if err != nil {
-				log.Fatalf("unable to list possible instances to destroy: %v", err)
-			}
-			if len(resp.GetInstances()) > 0 {
-				fmt.Printf("possible instances:\n")
-				for _, inst := range resp.GetInstances() {
-					fmt.Printf("\t%s\n", inst.GetGomoteId())
-				}
-			}
-		}
-		os.Exit(1)
-	}
-	var destroyGroup bool
-	fs.BoolVar(&destroyGroup, "destroy-group", false, "if a group is used, destroy the group too")
-
-	fs.Parse(args)
+	var flags destroyFlags
+	fs := destroyFlagSet(&flags)
+	parseFlags(fs, args)
 
 	var destroySet []string
-	if fs.NArg() == 1 {
-		destroySet = append(destroySet, fs.Arg(0))
+	if flags.destroyAll {
+		if fs.NArg() != 0 {
+			fs.Usage()
+		}
+		ctx := context.Background()
+		client := gomoteServerClient(ctx)
+		resp, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+		if err != nil {
+			return fmt.Errorf("unable to list instances: %w", err)
+		}
+		for _, inst := range resp.GetInstances() {
+			destroySet = append(destroySet, inst.GetGomoteId())
+		}
+	} else if fs.NArg() == 1 {
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		destroySet = append(destroySet, inst)
 	} else if activeGroup != nil {
 		for _, inst := range activeGroup.Instances {
 			destroySet = append(destroySet, inst)
@@ -53,18 +48,54 @@ func destroy(args []string) error {
 	} else {
 		fs.Usage()
 	}
+	if len(destroySet) > 1 {
+		if err := confirmStdio(flags.force, fmt.Sprintf("destroy %d instances", len(destroySet)), destroySet, "y"); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+	client := gomoteServerClient(ctx)
+	// Look up the instances before destroying them, so that destroying
+	// an instance which is still in use can be confirmed and what was
+	// destroyed can be reported.
+	statuses := instanceStatuses(ctx, client, destroySet)
+	var busy []string
+	for _, name := range destroySet {
+		resp, ok := statuses[name]
+		if !ok {
+			continue
+		}
+		if n, m := resp.GetActiveCommands(), resp.GetActiveSessions(); n > 0 || m > 0 {
+			busy = append(busy, fmt.Sprintf("%s (%d commands running, %d ssh sessions connected)", name, n, m))
+		}
+	}
+	if len(busy) > 0 {
+		fmt.Fprintln(os.Stderr, "# Warning: work is in progress on instances being destroyed.")
+		if err := confirmStdio(flags.force, fmt.Sprintf("destroy %d instances with work in progress", len(busy)), busy, "y"); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
 	for _, name := range destroySet {
 		fmt.Fprintf(os.Stderr, "# Destroying %s\n", name)
-		ctx := context.Background()
-		client := gomoteServerClient(ctx)
 		if _, err := client.DestroyInstance(ctx, &protos.DestroyInstanceRequest{
 			GomoteId: name,
 		}); err != nil {
 			return fmt.Errorf("unable to destroy instance: %w", err)
 		}
+		inst := statuses[name].GetInstance()
+		if inst == nil {
+			fmt.Fprintf(os.Stderr, "# Destroyed %s\n", name)
+			continue
+		}
+		age := "unknown age"
+		if inst.GetCreated() != 0 {
+			age = fmt.Sprintf("age %v", now.Sub(time.Unix(inst.GetCreated(), 0)).Round(time.Second))
+		}
+		fmt.Fprintf(os.Stderr, "# Destroyed %s (%s, %s)\n", name, inst.GetBuilderType(), age)
 	}
-	if activeGroup != nil {
-		if destroyGroup {
+	if activeGroup != nil && !flags.destroyAll {
+		if flags.destroyGroup {
 			if err := deleteGroup(activeGroup.Name); err != nil {
 				return err
 			}
@@ -77,3 +108,72 @@ func destroy(args []string) error {
 	}
 	return nil
 }
+
+// instanceStatuses looks up the instances named concurrently, without
+// contacting their buildlets, and returns the statuses of those found.
+// Instances which can't be looked up, such as with older servers which
+// don't support the request, are left out; destroying them reports any
+// real problem.
+func instanceStatuses(ctx context.Context, client protos.GomoteServiceClient, names []string) map[string]*protos.InstanceStatusResponse {
+	var (
+		mu       sync.Mutex
+		statuses = make(map[string]*protos.InstanceStatusResponse)
+		wg       sync.WaitGroup
+	)
+	for _, name := range names {
+		name := name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.InstanceStatus(ctx, &protos.InstanceStatusRequest{
+				GomoteId:     name,
+				SkipBuildlet: true,
+			})
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			statuses[name] = resp
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// destroyFlags are the flags of the destroy command.
+type destroyFlags struct {
+	destroyGroup bool
+	destroyAll   bool
+	force        bool
+}
+
+func destroyFlagSet(flags *destroyFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("destroy", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "destroy usage: gomote destroy [instance]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Destroys a single instance, or all instances in a group.")
+		fmt.Fprintln(os.Stderr, "Instance argument is optional with a group.")
+		fs.PrintDefaults()
+		if fs.NArg() == 0 {
+			// List buildlets that you might want to destroy.
+			client := gomoteServerClient(context.Background())
+			resp, err := client.ListInstances(context.Background(), &protos.ListInstancesRequest{})
+			if err != nil {
+				log.Fatalf("unable to list possible instances to destroy: %v", err)
+			}
+			if len(resp.GetInstances()) > 0 {
+				fmt.Printf("possible instances:\n")
+				for _, inst := range resp.GetInstances() {
+					fmt.Printf("\t%s\n", inst.GetGomoteId())
+				}
+			}
+		}
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.destroyGroup, "destroy-group", false, "if a group is used, destroy the group too")
+	fs.BoolVar(&flags.destroyAll, "all", false, "destroy all of your instances")
+	fs.BoolVar(&flags.force, "f", false, "do not ask for confirmation before destroying more than one instance, or instances with commands or ssh sessions in progress")
+	return fs
+}
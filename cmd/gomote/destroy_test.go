@@ -0,0 +1,70 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStatusClient is a GomoteServiceClient which reports the status of
+// instances, blocking until all of the expected requests have arrived.
+type fakeStatusClient struct {
+	protos.GomoteServiceClient
+	active map[string]int32
+	all    sync.WaitGroup
+
+	mu   sync.Mutex
+	reqs []*protos.InstanceStatusRequest
+}
+
+func (c *fakeStatusClient) InstanceStatus(ctx context.Context, req *protos.InstanceStatusRequest, _ ...grpc.CallOption) (*protos.InstanceStatusResponse, error) {
+	c.mu.Lock()
+	c.reqs = append(c.reqs, req)
+	c.mu.Unlock()
+	c.all.Done()
+	c.all.Wait()
+	n, ok := c.active[req.GetGomoteId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "instance not found")
+	}
+	return &protos.InstanceStatusResponse{
+		Instance:       &protos.Instance{GomoteId: req.GetGomoteId()},
+		ActiveCommands: n,
+	}, nil
+}
+
+func TestInstanceStatuses(t *testing.T) {
+	names := []string{"inst-a", "inst-b", "inst-c"}
+	client := &fakeStatusClient{active: map[string]int32{"inst-a": 0, "inst-b": 2}}
+	// Each request waits for the others, so the lookups only finish if
+	// they're made concurrently.
+	client.all.Add(len(names))
+	done := make(chan map[string]*protos.InstanceStatusResponse)
+	go func() {
+		done <- instanceStatuses(context.Background(), client, names)
+	}()
+	var statuses map[string]*protos.InstanceStatusResponse
+	select {
+	case statuses = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("instanceStatuses didn't look up the instances concurrently")
+	}
+	if len(statuses) != 2 || statuses["inst-b"].GetActiveCommands() != 2 {
+		t.Errorf("instanceStatuses = %v; want inst-a and inst-b, with 2 commands running on inst-b", statuses)
+	}
+	for _, req := range client.reqs {
+		if !req.GetSkipBuildlet() {
+			t.Errorf("request %v contacts the buildlet; want it skipped", req)
+		}
+	}
+}
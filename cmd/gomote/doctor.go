@@ -0,0 +1,330 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/internal/iapclient"
+	"golang.org/x/build/types"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+)
+
+// doctorTimeout bounds each network check run by doctor.
+const doctorTimeout = 30 * time.Second
+
+// errDoctorFailed is returned by doctor when a critical check fails.
+var errDoctorFailed = errors.New("one or more critical checks failed")
+
+// doctorCheck is a single check run by doctor.
+type doctorCheck struct {
+	name string
+	// critical reports whether gomote is unusable if the check fails.
+	// Failures of other checks are only reported as warnings.
+	critical bool
+	// needs is the name of a check which must pass for this check to run.
+	needs string
+	run   func(ctx context.Context) error
+}
+
+// hintError is an error with a hint on how to fix it.
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e hintError) Error() string { return e.err.Error() }
+func (e hintError) Unwrap() error { return e.err }
+
+func withHint(err error, format string, a ...any) error {
+	return hintError{err: err, hint: fmt.Sprintf(format, a...)}
+}
+
+func doctor(args []string) error {
+	fs := doctorFlagSet()
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+	}
+	checks, err := doctorChecks()
+	if err != nil {
+		return err
+	}
+	writeNetworkSettings(os.Stdout, os.Getenv)
+	if !runDoctorChecks(context.Background(), os.Stdout, checks) {
+		return errDoctorFailed
+	}
+	return nil
+}
+
+func doctorFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "doctor usage: gomote doctor")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Checks the gomote environment and connectivity to the gomote server,")
+		fmt.Fprintln(os.Stderr, "with hints on how to fix any problems found.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	return fs
+}
+
+// doctorChecks returns the checks run by doctor, in order.
+func doctorChecks() ([]doctorCheck, error) {
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve user configuration directory: %w", err)
+	}
+	gomoteDir := filepath.Join(cfgDir, "gomote")
+	tokenPath, err := iapclient.TokenPath(credentialScope(*serverAddr))
+	if err != nil {
+		return nil, err
+	}
+	return []doctorCheck{
+		{
+			name:     "config files",
+			critical: true,
+			run: func(context.Context) error {
+				return checkConfigFiles(gomoteDir)
+			},
+		},
+		{
+			name:     "cache directory",
+			critical: true,
+			run: func(context.Context) error {
+				return checkCacheDir(gomoteDir)
+			},
+		},
+		{
+			name:     "credentials",
+			critical: true,
+			run: func(context.Context) error {
+				return checkCredentials(tokenPath)
+			},
+		},
+		{
+			name:     "CA bundle",
+			critical: true,
+			run: func(context.Context) error {
+				return checkCABundle(caBundlePath(os.Getenv))
+			},
+		},
+		{
+			name:     "gomote server",
+			critical: true,
+			needs:    "credentials",
+			run: func(ctx context.Context) error {
+				return checkServer(ctx, dialDoctorServer)
+			},
+		},
+		{
+			name: "builder list",
+			// The builder list is only used without LUCI.
+			critical: luciDisabled(),
+			run: func(ctx context.Context) error {
+				return checkBuilderList(ctx, http.DefaultClient, types.BuilderListingURL)
+			},
+		},
+		{
+			name: "ssh key",
+			run: func(context.Context) error {
+				return checkSSHKey(filepath.Join(gomoteDir, ".ssh"))
+			},
+		},
+	}, nil
+}
+
+// runDoctorChecks runs checks in order, writing their results to w.
+// It reports whether all critical checks passed.
+func runDoctorChecks(ctx context.Context, w io.Writer, checks []doctorCheck) bool {
+	ok := true
+	passed := make(map[string]bool)
+	for _, c := range checks {
+		if c.needs != "" && !passed[c.needs] {
+			fmt.Fprintf(w, "SKIP %s: requires %s\n", c.name, c.needs)
+			if c.critical {
+				ok = false
+			}
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+		err := c.run(ctx)
+		cancel()
+		if err == nil {
+			passed[c.name] = true
+			fmt.Fprintf(w, "PASS %s\n", c.name)
+			continue
+		}
+		result := "WARN"
+		if c.critical {
+			result = "FAIL"
+			ok = false
+		}
+		fmt.Fprintf(w, "%s %s: %v\n", result, c.name, err)
+		var he hintError
+		if errors.As(err, &he) {
+			fmt.Fprintf(w, "     hint: %s\n", he.hint)
+		}
+	}
+	return ok
+}
+
+// checkConfigFiles checks that the config, group and alias files in dir parse.
+func checkConfigFiles(dir string) error {
+	path := filepath.Join(dir, "config")
+	if f, err := os.Open(path); err == nil {
+		_, err := parseConfig(f)
+		f.Close()
+		if err != nil {
+			return withHint(fmt.Errorf("%s: %w", path, err), "fix the file or edit it with \"gomote config\"")
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return withHint(err, "check the permissions of %s", path)
+	}
+	groups, err := filepath.Glob(filepath.Join(dir, "groups", "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range groups {
+		var g groupData
+		if err := unmarshalFile(path, &g); err != nil {
+			return withHint(err, "remove the broken group with \"rm %s\"", path)
+		}
+	}
+	path = filepath.Join(dir, "aliases.json")
+	aliases := make(map[string]string)
+	if err := unmarshalFile(path, &aliases); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return withHint(err, "remove the broken aliases with \"rm %s\"", path)
+	}
+	return nil
+}
+
+func unmarshalFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// checkCacheDir checks that files can be created in dir, where gomote
+// caches credentials, groups and aliases.
+func checkCacheDir(dir string) error {
+	hint := fmt.Sprintf("make sure %s is a writable directory", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return withHint(err, "%s", hint)
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return withHint(err, "%s", hint)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkCredentials checks that the cached credentials at path exist and
+// have not expired.
+func checkCredentials(path string) error {
+	loginHint := "run any gomote command, such as \"gomote list\", to log in"
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return withHint(errors.New("not logged in"), "%s", loginHint)
+	} else if err != nil {
+		return withHint(err, "check the permissions of %s", path)
+	}
+	var tok oauth2.Token
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return withHint(fmt.Errorf("%s: %w", path, err), "remove %s, then %s", path, loginHint)
+	}
+	if !tok.Valid() {
+		return withHint(errors.New("credentials have expired"), "%s", loginHint)
+	}
+	return nil
+}
+
+// checkServer checks that the gomote server can be reached with dial and
+// accepts the user's credentials.
+func checkServer(ctx context.Context, dial func(context.Context) (protos.GomoteServiceClient, error)) error {
+	client, err := dial(ctx)
+	if err != nil {
+		hint := fmt.Sprintf("check that -server=%s is the right address", *serverAddr)
+		for _, env := range []string{"HTTPS_PROXY", "https_proxy", "ALL_PROXY", "all_proxy"} {
+			if v := os.Getenv(env); v != "" {
+				hint += fmt.Sprintf(" and that the proxy %s=%s allows gRPC connections to it, or use -transport=websocket", env, v)
+				break
+			}
+		}
+		return withHint(fmt.Errorf("unable to reach %s: %w", *serverAddr, err), "%s", hint)
+	}
+	if _, err := client.ListInstances(ctx, &protos.ListInstancesRequest{}); err != nil {
+		if code, _ := grpcCode(err); code == codes.Unauthenticated || code == codes.PermissionDenied {
+			return withHint(err, "make sure you are logged in with an account which has gomote access")
+		}
+		return err
+	}
+	return nil
+}
+
+// checkCABundle checks that the CA bundle at path, if any, contains
+// certificates.
+func checkCABundle(path string) error {
+	if path == "" {
+		return nil
+	}
+	if _, _, err := loadCABundle(path); err != nil {
+		return withHint(err, "fix or unset -ca-bundle and $GOMOTE_CA_BUNDLE")
+	}
+	return nil
+}
+
+func dialDoctorServer(ctx context.Context) (protos.GomoteServiceClient, error) {
+	grpcClient, err := dialServer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return protos.NewGomoteServiceClient(grpcClient), nil
+}
+
+// checkBuilderList checks that the list of builders can be fetched from url.
+func checkBuilderList(ctx context.Context, hc *http.Client, url string) error {
+	l, err := types.FetchBuilderListing(ctx, hc, url, 0)
+	if err != nil {
+		return withHint(err, "%s", "check your network connection and any HTTPS_PROXY settings")
+	}
+	if len(l.Builders) == 0 {
+		return errors.New("builder list is empty")
+	}
+	return nil
+}
+
+// checkSSHKey checks that the key pair used by "gomote ssh" exists in dir.
+func checkSSHKey(dir string) error {
+	var missing []string
+	for _, name := range []string{"id_ed25519", "id_ed25519.pub"} {
+		if !fileExists(filepath.Join(dir, name)) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return withHint(fmt.Errorf("missing %s in %s", strings.Join(missing, " and "), dir), "run \"gomote ssh\" on any instance to create the key pair")
+	}
+	return nil
+}
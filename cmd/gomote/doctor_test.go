@@ -0,0 +1,224 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func hintOf(err error) string {
+	var he hintError
+	if errors.As(err, &he) {
+		return he.hint
+	}
+	return ""
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	pass := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return withHint(errors.New("broken"), "fix it") }
+	testCases := []struct {
+		desc   string
+		checks []doctorCheck
+		want   []string
+		wantOK bool
+	}{
+		{
+			desc:   "all pass",
+			checks: []doctorCheck{{name: "a", critical: true, run: pass}, {name: "b", run: pass}},
+			want:   []string{"PASS a", "PASS b"},
+			wantOK: true,
+		},
+		{
+			desc:   "non-critical failure",
+			checks: []doctorCheck{{name: "a", critical: true, run: pass}, {name: "b", run: fail}},
+			want:   []string{"PASS a", "WARN b: broken", "     hint: fix it"},
+			wantOK: true,
+		},
+		{
+			desc:   "critical failure skips dependents",
+			checks: []doctorCheck{{name: "a", critical: true, run: fail}, {name: "b", critical: true, needs: "a", run: pass}},
+			want:   []string{"FAIL a: broken", "     hint: fix it", "SKIP b: requires a"},
+			wantOK: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			ok := runDoctorChecks(context.Background(), &buf, tc.checks)
+			if ok != tc.wantOK {
+				t.Errorf("runDoctorChecks() = %t; want %t", ok, tc.wantOK)
+			}
+			if got, want := buf.String(), strings.Join(tc.want, "\n")+"\n"; got != want {
+				t.Errorf("runDoctorChecks() output:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckConfigFiles(t *testing.T) {
+	write := func(t *testing.T, path, data string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := t.TempDir()
+	if err := checkConfigFiles(dir); err != nil {
+		t.Errorf("checkConfigFiles(empty dir) = %v; want nil", err)
+	}
+	write(t, filepath.Join(dir, "config"), "run.debug = true\n")
+	write(t, filepath.Join(dir, "groups", "g.json"), `{"Name":"g","Instances":["a"]}`)
+	write(t, filepath.Join(dir, "aliases.json"), `{"a":"b"}`)
+	if err := checkConfigFiles(dir); err != nil {
+		t.Errorf("checkConfigFiles(valid files) = %v; want nil", err)
+	}
+	for _, file := range []string{"config", "groups/g.json", "aliases.json"} {
+		t.Run(file, func(t *testing.T) {
+			dir := t.TempDir()
+			write(t, filepath.Join(dir, file), "{ not valid")
+			err := checkConfigFiles(dir)
+			if err == nil || hintOf(err) == "" {
+				t.Errorf("checkConfigFiles(broken %s) = %v; want error with hint", file, err)
+			}
+		})
+	}
+}
+
+func TestCheckCacheDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gomote")
+	if err := checkCacheDir(dir); err != nil {
+		t.Errorf("checkCacheDir() = %v; want nil", err)
+	}
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkCacheDir(file); err == nil {
+		t.Errorf("checkCacheDir(file) = nil; want error")
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	dir := t.TempDir()
+	writeToken := func(t *testing.T, tok oauth2.Token) string {
+		path := filepath.Join(dir, t.Name())
+		data, err := json.Marshal(tok)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	t.Run("valid", func(t *testing.T) {
+		path := writeToken(t, oauth2.Token{AccessToken: "x", Expiry: time.Now().Add(time.Hour)})
+		if err := checkCredentials(path); err != nil {
+			t.Errorf("checkCredentials() = %v; want nil", err)
+		}
+	})
+	t.Run("expired", func(t *testing.T) {
+		path := writeToken(t, oauth2.Token{AccessToken: "x", Expiry: time.Now().Add(-time.Hour)})
+		if err := checkCredentials(path); err == nil || hintOf(err) == "" {
+			t.Errorf("checkCredentials() = %v; want error with hint", err)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		if err := checkCredentials(filepath.Join(dir, "missing")); err == nil || hintOf(err) == "" {
+			t.Errorf("checkCredentials() = %v; want error with hint", err)
+		}
+	})
+}
+
+// fakeGomoteClient is a GomoteServiceClient whose ListInstances returns err.
+type fakeGomoteClient struct {
+	protos.GomoteServiceClient
+	err error
+}
+
+func (c fakeGomoteClient) ListInstances(context.Context, *protos.ListInstancesRequest, ...grpc.CallOption) (*protos.ListInstancesResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &protos.ListInstancesResponse{}, nil
+}
+
+func TestCheckServer(t *testing.T) {
+	dialer := func(client protos.GomoteServiceClient, err error) func(context.Context) (protos.GomoteServiceClient, error) {
+		return func(context.Context) (protos.GomoteServiceClient, error) { return client, err }
+	}
+	ctx := context.Background()
+	if err := checkServer(ctx, dialer(fakeGomoteClient{}, nil)); err != nil {
+		t.Errorf("checkServer(reachable) = %v; want nil", err)
+	}
+	if err := checkServer(ctx, dialer(nil, context.DeadlineExceeded)); err == nil || !strings.Contains(hintOf(err), "-server") {
+		t.Errorf("checkServer(unreachable) = %v; want error with hint about -server", err)
+	}
+	unauth := fakeGomoteClient{err: status.Error(codes.Unauthenticated, "no")}
+	if err := checkServer(ctx, dialer(unauth, nil)); err == nil || hintOf(err) == "" {
+		t.Errorf("checkServer(unauthenticated) = %v; want error with hint", err)
+	}
+}
+
+func TestCheckBuilderList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			fmt.Fprint(w, `{"Builders": {"linux-amd64": {"HostType": "host-linux-amd64"}}}`)
+		case "/empty":
+			fmt.Fprint(w, `{}`)
+		default:
+			http.Error(w, "nope", http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+	if err := checkBuilderList(ctx, ts.Client(), ts.URL+"/ok"); err != nil {
+		t.Errorf("checkBuilderList(ok) = %v; want nil", err)
+	}
+	for _, path := range []string{"/empty", "/error"} {
+		if err := checkBuilderList(ctx, ts.Client(), ts.URL+path); err == nil {
+			t.Errorf("checkBuilderList(%s) = nil; want error", path)
+		}
+	}
+}
+
+func TestCheckSSHKey(t *testing.T) {
+	dir := t.TempDir()
+	if err := checkSSHKey(dir); err == nil || hintOf(err) == "" {
+		t.Errorf("checkSSHKey(no keys) = %v; want error with hint", err)
+	}
+	for _, name := range []string{"id_ed25519", "id_ed25519.pub"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := checkSSHKey(dir); err != nil {
+		t.Errorf("checkSSHKey() = %v; want nil", err)
+	}
+}
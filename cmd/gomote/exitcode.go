@@ -0,0 +1,140 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/build/buildlet"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The exit codes of gomote. They are documented in the package comment, and
+// scripts rely on them to decide whether a failure is worth retrying, so they
+// must not change.
+const (
+	exitOK            = 0
+	exitFailure       = 1   // an error which is not classified further
+	exitUsage         = 2   // invalid usage, such as a bad flag or argument
+	exitCommandFailed = 3   // a command run on an instance failed
+	exitNotFound      = 4   // the instance does not exist or has expired
+	exitServerError   = 5   // the server could not be reached or failed
+	exitReclaimed     = 6   // "gomote gc" destroyed idle instances
+	exitInterrupted   = 130 // the user interrupted or aborted the operation
+)
+
+// usageError is an error caused by invalid usage of a command, such as a
+// malformed argument.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// usageErrorf is like fmt.Errorf, but returns a usageError.
+func usageErrorf(format string, a ...any) error {
+	return usageError{fmt.Errorf(format, a...)}
+}
+
+// remoteExitError is the error of a command run on an instance whose exit
+// status gomote exits with, as "gomote test" does with that of go test.
+type remoteExitError struct {
+	err *cmdFailedError
+}
+
+func (e remoteExitError) Error() string { return e.err.Error() }
+func (e remoteExitError) Unwrap() error { return e.err }
+
+// code returns the exit status of the command, or false if the server
+// didn't report it or the command was killed by a signal.
+func (e remoteExitError) code() (int, bool) {
+	code := e.err.exit.GetExitCode()
+	return int(code), code > 0
+}
+
+// errCommandsFailed is returned when a command run on one or more instances failed.
+var errCommandsFailed = errors.New("one or more commands failed")
+
+// errReclaimed is returned by "gomote gc" when it destroyed idle
+// instances. It isn't a failure, so it isn't reported, but it sets the
+// exit code to exitReclaimed.
+var errReclaimed = errors.New("idle instances were reclaimed")
+
+// errInterrupted is the error of a command which the user interrupted.
+var errInterrupted = errors.New("interrupted")
+
+// exitCode returns the exit code for a command which returned err.
+func exitCode(err error) int {
+	var ue usageError
+	var re remoteExitError
+	var ce *cmdFailedError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errReclaimed):
+		return exitReclaimed
+	case errors.As(err, &re):
+		if code, ok := re.code(); ok {
+			return code
+		}
+		return exitCommandFailed
+	case errors.As(err, &ce), errors.Is(err, errCommandsFailed):
+		return exitCommandFailed
+	case errors.As(err, &ue):
+		return exitUsage
+	case errors.Is(err, errAborted), errors.Is(err, errInterrupted), errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitServerError
+	case isServerError(err, buildlet.ErrInstanceNotFound):
+		return exitNotFound
+	case isServerError(err, buildlet.ErrUnknownBuilderType), isServerError(err, buildlet.ErrNotOwner):
+		return exitUsage
+	case isServerError(err, buildlet.ErrQuotaExceeded), isServerError(err, buildlet.ErrServerUnavailable):
+		return exitServerError
+	}
+	code, ok := grpcCode(err)
+	if !ok {
+		return exitFailure
+	}
+	switch code {
+	case codes.NotFound:
+		return exitNotFound
+	case codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated,
+		codes.FailedPrecondition, codes.AlreadyExists, codes.OutOfRange:
+		return exitUsage
+	case codes.Canceled:
+		return exitInterrupted
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Internal, codes.Unknown, codes.Aborted, codes.DataLoss, codes.Unimplemented:
+		return exitServerError
+	}
+	return exitFailure
+}
+
+// isServerError reports whether err is the error kind of the buildlet
+// package, such as buildlet.ErrInstanceNotFound. The interceptors of
+// buildlet.ServerErrorDialOptions classify the errors of the server, but
+// not those of the fake clients of tests, so it classifies err itself if
+// needed.
+func isServerError(err, kind error) bool {
+	return errors.Is(buildlet.GRPCServerError("", err), kind)
+}
+
+// grpcCode returns the code of the first gRPC status error in err's tree.
+func grpcCode(err error) (codes.Code, bool) {
+	if err == nil {
+		return codes.OK, false
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return codes.OK, false
+	}
+	return s.Code(), true
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		desc string
+		err  error
+		want int
+	}{
+		{"success", nil, exitOK},
+		{"unclassified", errors.New("disk full"), exitFailure},
+		{"usage", usageErrorf("invalid duration %q", "2x"), exitUsage},
+		{"wrapped usage", fmt.Errorf("extend: %w", usageErrorf("bad")), exitUsage},
+		{"remote command failed", &cmdFailedError{inst: "a", cmd: "go", err: status.Error(codes.Aborted, "exit status 1")}, exitCommandFailed},
+		{"remote command exited", &cmdFailedError{inst: "a", cmd: "go", err: status.Error(codes.Unknown, "exit status 3"), exit: &protos.ExecuteCommandResponse_ExitStatus{ExitCode: 3, State: "exit status 3"}}, exitCommandFailed},
+		{"remote commands failed", errCommandsFailed, exitCommandFailed},
+		{"remote exit status", remoteExitError{&cmdFailedError{inst: "a", cmd: "go", err: status.Error(codes.Unknown, "exit status 2"), exit: &protos.ExecuteCommandResponse_ExitStatus{ExitCode: 2, State: "exit status 2"}}}, 2},
+		{"remote exit status killed", remoteExitError{&cmdFailedError{inst: "a", cmd: "go", err: status.Error(codes.Unknown, "signal: killed"), exit: &protos.ExecuteCommandResponse_ExitStatus{ExitCode: -1, State: "signal: killed"}}}, exitCommandFailed},
+		{"remote exit status unreported", remoteExitError{&cmdFailedError{inst: "a", cmd: "go", err: status.Error(codes.Aborted, "exit status 1")}}, exitCommandFailed},
+		{"instances reclaimed", errReclaimed, exitReclaimed},
+		{"instance not found", fmt.Errorf("unable to ping instance: %w", status.Error(codes.NotFound, "instance not found")), exitNotFound},
+		{"not owned", status.Error(codes.PermissionDenied, "not owned"), exitUsage},
+		{"invalid argument", status.Error(codes.InvalidArgument, "invalid builder type"), exitUsage},
+		{"unauthenticated", status.Error(codes.Unauthenticated, "no credentials"), exitUsage},
+		{"server unavailable", fmt.Errorf("unable to list instances: %w", status.Error(codes.Unavailable, "connection refused")), exitServerError},
+		{"server internal error", status.Error(codes.Internal, "oops"), exitServerError},
+		{"deadline", fmt.Errorf("dialing: %w", context.DeadlineExceeded), exitServerError},
+		{"aborted by user", errAborted, exitInterrupted},
+		{"interrupted", errInterrupted, exitInterrupted},
+		{"canceled", fmt.Errorf("creating instance: %w", context.Canceled), exitInterrupted},
+		{"canceled rpc", status.Error(codes.Canceled, "context canceled"), exitInterrupted},
+		{"classified not found", &buildlet.ServerError{Kind: buildlet.ErrInstanceNotFound, Err: errors.New("instance not found")}, exitNotFound},
+		{"unknown builder type", fmt.Errorf("failed to create buildlet: %w", &buildlet.ServerError{Kind: buildlet.ErrUnknownBuilderType, Err: errors.New("400 Bad Request")}), exitUsage},
+		{"classified not owner", buildlet.GRPCServerError("/protos.GomoteService/ExtendInstance", status.Error(codes.PermissionDenied, "not owned")), exitUsage},
+		{"quota exceeded", buildlet.GRPCServerError("/protos.GomoteService/CreateInstance", status.Error(codes.ResourceExhausted, "too many instances")), exitServerError},
+		{"classified unavailable", &buildlet.ServerError{Kind: buildlet.ErrServerUnavailable, Err: errors.New("503 Service Unavailable")}, exitServerError},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := exitCode(tc.err); got != tc.want {
+				t.Errorf("exitCode(%v) = %d; want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+func extend(args []string) error {
+	fs := extendFlagSet()
+	parseFlags(fs, args)
+
+	var extendSet []string
+	var instArg, durArg string
+	switch {
+	case fs.NArg() == 2:
+		instArg = fs.Arg(0)
+		durArg = fs.Arg(1)
+	case fs.NArg() == 1 && activeGroup != nil && isDuration(fs.Arg(0)):
+		extendSet = activeGroup.Instances
+		durArg = fs.Arg(0)
+	case fs.NArg() == 1:
+		instArg = fs.Arg(0)
+	case fs.NArg() == 0 && activeGroup != nil:
+		extendSet = activeGroup.Instances
+	default:
+		fs.Usage()
+	}
+	if instArg != "" {
+		inst, err := resolveInstance(context.Background(), instArg)
+		if err != nil {
+			return err
+		}
+		extendSet = []string{inst}
+	}
+	var d time.Duration
+	if durArg != "" {
+		var err error
+		d, err = time.ParseDuration(durArg)
+		if err != nil {
+			return usageErrorf("invalid duration %q: %w", durArg, err)
+		}
+		if d < time.Second {
+			return usageErrorf("invalid duration %q: must be at least one second", durArg)
+		}
+	}
+
+	ctx := context.Background()
+	for _, inst := range extendSet {
+		if err := doExtend(ctx, inst, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extendFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("extend", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "extend usage: gomote extend [instance] [duration]")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Extends the lifetime of an instance by duration, such as 2h30m.")
+		fmt.Fprintln(os.Stderr, "If no duration is given, the server's default increment is used.")
+		fmt.Fprintln(os.Stderr, "The server may limit the total lifetime of an instance.")
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	return fs
+}
+
+// doExtend extends the lifetime of the named instance by d, or by the server's
+// default increment if d is zero, and reports the new expiration time.
+func doExtend(ctx context.Context, name string, d time.Duration) error {
+	client := gomoteServerClient(ctx)
+	resp, err := client.ExtendInstance(ctx, &protos.ExtendInstanceRequest{
+		GomoteId: name,
+		Duration: int64(d / time.Second),
+	})
+	if isServerError(err, buildlet.ErrNotOwner) {
+		return fmt.Errorf("unable to extend instance %s: it is not owned by you", name)
+	} else if err != nil {
+		return fmt.Errorf("unable to extend instance %s: %w", name, err)
+	}
+	expires := time.Unix(resp.GetExpires(), 0)
+	fmt.Printf("%s: expires at %s (in %v)\n", name, expires.Format(time.RFC1123), time.Until(expires).Round(time.Second))
+	if resp.GetClamped() {
+		maxExpires := time.Unix(resp.GetMaxExpires(), 0)
+		fmt.Fprintf(os.Stderr, "# %s: extension limited by the maximum instance lifetime; instance cannot be extended past %s\n", name, maxExpires.Format(time.RFC1123))
+	}
+	return nil
+}
+
+// isDuration reports whether s can be parsed as a time.Duration.
+func isDuration(s string) bool {
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
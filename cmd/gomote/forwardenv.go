@@ -0,0 +1,54 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultForwardEnv is the default of -forward-env: the variables of the
+// local environment which change how Go builds and runs programs, and
+// which make sense on any instance.
+const defaultForwardEnv = "GOEXPERIMENT,GODEBUG,GOFLAGS"
+
+// forwardEnvUsage is the usage of -forward-env.
+const forwardEnvUsage = "comma-separated `list` of local environment variables to forward to the instance, if they're set; an empty list forwards none"
+
+// forwardedEnv returns the KEY=value pairs of the variables named in list,
+// a comma-separated list, which are set to a non-empty value according to
+// getenv. The variables set in explicit, KEY=value pairs set by -e, aren't
+// forwarded, so that they take precedence.
+func forwardedEnv(list string, explicit []string, getenv func(string) string) ([]string, error) {
+	set := make(map[string]bool)
+	for _, kv := range explicit {
+		k, _, _ := strings.Cut(kv, "=")
+		set[k] = true
+	}
+	var env []string
+	for _, k := range strings.Split(list, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		if strings.ContainsAny(k, "= \t") {
+			return nil, usageErrorf("invalid -forward-env %q; want a comma-separated list of environment variables", list)
+		}
+		if v := getenv(k); v != "" && !set[k] {
+			env = append(env, k+"="+v)
+			set[k] = true
+		}
+	}
+	return env, nil
+}
+
+// reportForwardedEnv writes the forwarded variables, if any, to w.
+func reportForwardedEnv(w io.Writer, env []string) {
+	if len(env) == 0 {
+		return
+	}
+	fmt.Fprintln(w, styles.Status(fmt.Sprintf("# Forwarding %s from the local environment.", strings.Join(env, " "))))
+}
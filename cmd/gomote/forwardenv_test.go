@@ -0,0 +1,51 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestForwardedEnv(t *testing.T) {
+	getenv := fakeEnv(map[string]string{
+		"GOEXPERIMENT": "rangefunc",
+		"GOFLAGS":      "-race",
+		"GODEBUG":      "",
+		"GOAMD64":      "v3",
+	})
+	for _, tc := range []struct {
+		list     string
+		explicit []string
+		want     []string
+		wantErr  bool
+	}{
+		{defaultForwardEnv, nil, []string{"GOEXPERIMENT=rangefunc", "GOFLAGS=-race"}, false},
+		{defaultForwardEnv, []string{"GOFLAGS=-count=1"}, []string{"GOEXPERIMENT=rangefunc"}, false},
+		{"GOAMD64, GOFLAGS,,GOAMD64", nil, []string{"GOAMD64=v3", "GOFLAGS=-race"}, false},
+		{"", nil, nil, false},
+		{"GODEBUG,GOCACHE", nil, nil, false},
+		{"GOFLAGS=-race", nil, nil, true},
+	} {
+		got, err := forwardedEnv(tc.list, tc.explicit, getenv)
+		if (err != nil) != tc.wantErr || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("forwardedEnv(%q, %q) = %q, %v; want %q, error %t", tc.list, tc.explicit, got, err, tc.want, tc.wantErr)
+		}
+		if err != nil && exitCode(err) != exitUsage {
+			t.Errorf("forwardedEnv(%q) error %v isn't a usage error", tc.list, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	reportForwardedEnv(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("reportForwardedEnv(nil) wrote %q; want nothing", buf.String())
+	}
+	reportForwardedEnv(&buf, []string{"GOEXPERIMENT=rangefunc", "GOFLAGS=-race"})
+	if got, want := buf.String(), "# Forwarding GOEXPERIMENT=rangefunc GOFLAGS=-race from the local environment.\n"; got != want {
+		t.Errorf("reportForwardedEnv() wrote %q; want %q", got, want)
+	}
+}
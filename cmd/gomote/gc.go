@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+func gc(args []string) error {
+	var flags gcFlags
+	fs := gcFlagSet(&flags)
+	parseFlags(fs, args)
+	if fs.NArg() != 0 || flags.idle <= 0 {
+		fs.Usage()
+	}
+
+	ctx := context.Background()
+	client := gomoteServerClient(ctx)
+	now := time.Now()
+	idle, err := idleInstances(ctx, client, flags.idle, flags.labels.labels)
+	if err != nil {
+		return err
+	}
+	var idleSet, targets []string
+	for _, rb := range idle {
+		idleSet = append(idleSet, rb.Name)
+		targets = append(targets, fmt.Sprintf("%s (%s, idle for %v)", rb.Name, rb.BuilderType, now.Sub(rb.LastActive()).Round(time.Minute)))
+	}
+	if len(idleSet) == 0 {
+		fmt.Fprintf(os.Stderr, "# No instances have been idle for more than %v.\n", flags.idle)
+		return nil
+	}
+	if flags.dryRun {
+		fmt.Fprintln(os.Stderr, "# Would destroy:")
+		for _, t := range targets {
+			fmt.Printf("%s\n", t)
+		}
+		return nil
+	}
+	if err := confirmStdio(flags.force, fmt.Sprintf("destroy %d idle instances", len(idleSet)), targets, "y"); err != nil {
+		return err
+	}
+	destroyed := 0
+	for _, name := range idleSet {
+		fmt.Fprintf(os.Stderr, "# Destroying %s\n", name)
+		_, err := client.DestroyInstance(ctx, &protos.DestroyInstanceRequest{
+			GomoteId: name,
+		})
+		switch {
+		case err == nil:
+			destroyed++
+		case instanceDoesNotExist(err):
+			// It expired meanwhile.
+		default:
+			return fmt.Errorf("unable to destroy instance: %w", err)
+		}
+	}
+	if destroyed == 0 {
+		return nil
+	}
+	return errReclaimed
+}
+
+// idleInstances returns the caller's instances which have been idle for
+// at least idle and which have the labels selected. An instance is idle
+// since its last activity, or since it was created if that's unknown.
+// Instances for which neither is known are never idle.
+func idleInstances(ctx context.Context, client protos.GomoteServiceClient, idle time.Duration, labels []string) ([]buildlet.RemoteBuildlet, error) {
+	cc := &buildlet.GRPCCoordinatorClient{Client: client}
+	rbs, err := cc.ListBuildlets(ctx, buildlet.InstanceFilter{MinIdle: idle, Labels: labels})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list instances: %w", err)
+	}
+	return rbs, nil
+}
+
+// gcFlags are the flags of the gc command.
+type gcFlags struct {
+	idle   time.Duration
+	labels labelFlag
+	dryRun bool
+	force  bool
+}
+
+func gcFlagSet(flags *gcFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("gc", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "gc usage: gomote gc [gc-opts]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Destroys your instances which have been idle for longer than -idle.")
+		fmt.Fprintln(os.Stderr, "An instance is idle since it last ran a command, had files written or")
+		fmt.Fprintln(os.Stderr, "removed, or had an SSH session, or since it was created if that's unknown.")
+		fmt.Fprintf(os.Stderr, "Exits with status %d if any instances were reclaimed.\n", exitReclaimed)
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.DurationVar(&flags.idle, "idle", 2*time.Hour, "destroy instances idle for longer than this duration")
+	flags.labels.selector = true
+	fs.Var(&flags.labels, "label", "only destroy instances with the label `key=value`, or with the label key if there's no value; may be repeated")
+	fs.BoolVar(&flags.dryRun, "dry-run", false, "print the instances which would be destroyed without destroying them")
+	fs.BoolVar(&flags.force, "f", false, "do not ask for confirmation before destroying instances")
+	return fs
+}
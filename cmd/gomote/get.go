@@ -19,26 +19,17 @@ import (
 
 // getTar a .tar.gz
 func getTar(args []string) error {
-	fs := flag.NewFlagSet("get", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "gettar usage: gomote gettar [get-opts] [buildlet-name]")
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "Writes tarball into the current working directory.")
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "Buildlet name is optional if a group is selected, in which case")
-		fmt.Fprintln(os.Stderr, "tarballs from all buildlets in the group are downloaded into the")
-		fmt.Fprintln(os.Stderr, "current working directory.")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	var dir string
-	fs.StringVar(&dir, "dir", "", "relative directory from buildlet's work dir to tar up")
-
-	fs.Parse(args)
+	var flags getTarFlags
+	fs := getTarFlagSet(&flags)
+	parseFlags(fs, args)
 
 	var getSet []string
 	if fs.NArg() == 1 {
-		getSet = []string{fs.Arg(0)}
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		getSet = []string{inst}
 	} else if fs.NArg() == 0 && activeGroup != nil {
 		for _, inst := range activeGroup.Instances {
 			getSet = append(getSet, inst)
@@ -58,12 +49,34 @@ func getTar(args []string) error {
 			}
 			defer f.Close()
 			fmt.Fprintf(os.Stderr, "# Downloading tarball for %q to %q...\n", inst, f.Name())
-			return doGetTar(ctx, inst, dir, f)
+			return doGetTar(ctx, inst, flags.dir, f)
 		})
 	}
 	return eg.Wait()
 }
 
+// getTarFlags are the flags of the gettar command.
+type getTarFlags struct {
+	dir string
+}
+
+func getTarFlagSet(flags *getTarFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("gettar", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "gettar usage: gomote gettar [get-opts] [buildlet-name]")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Writes tarball into the current working directory.")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Buildlet name is optional if a group is selected, in which case")
+		fmt.Fprintln(os.Stderr, "tarballs from all buildlets in the group are downloaded into the")
+		fmt.Fprintln(os.Stderr, "current working directory.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.dir, "dir", "", "relative directory from buildlet's work dir to tar up")
+	return fs
+}
+
 func doGetTar(ctx context.Context, name, dir string, out io.Writer) error {
 	client := gomoteServerClient(ctx)
 	resp, err := client.ReadTGZToURL(ctx, &protos.ReadTGZToURLRequest{
@@ -23,12 +23,24 @@ To list the subcommands, run "gomote" without arguments:
 
 	Commands:
 
+	  alias      manage aliases for instance names
+	  benchcompare compare benchmarks between two GOROOTs
+	  builders   list the builder types instances can be created with
+	  completion generate a shell completion script
+	  config     manage default flag values
 	  create     create a buildlet; with no args, list types of buildlets
 	  destroy    destroy a buildlet
+	  doctor     diagnose problems with the gomote environment
+	  extend     extend the lifetime of a buildlet
+	  gc         destroy idle buildlets
 	  gettar     extract a tar.gz from a buildlet
+	  install-go install an official release of Go on a buildlet
+	  kill       kill a command running on a buildlet
 	  list       list active buildlets
+	  log        print the operation log of commands which changed instances
 	  ls         list the contents of a directory on a buildlet
 	  ping       test whether a buildlet is alive and reachable
+	  ps         list the commands running on a buildlet
 	  push       sync your GOROOT directory to the buildlet
 	  put        put files on a buildlet
 	  put14      put Go 1.4 in place
@@ -36,11 +48,17 @@ To list the subcommands, run "gomote" without arguments:
 	  rm         delete files or directories
 	  rdp        RDP (Remote Desktop Protocol) to a Windows buildlet
 	  run        run a command on a buildlet
+	  setup      push GOROOT to a buildlet and build Go
+	  shell      start an interactive shell
 	  ssh        ssh to a buildlet
+	  status     show detailed status of a buildlet
+	  tail       print the end of a file on a buildlet, optionally following it
+	  test       push GOROOT to a buildlet and test a package
+	  version    print the client and server versions
 
-To list all the builder types available, run "create" with no arguments:
+To list all the builder types available, run "builders":
 
-	$ gomote create
+	$ gomote builders -goos linux
 	(list tons of buildlet types)
 
 The "gomote run" command has many of its own flags:
@@ -81,6 +99,29 @@ directly (for example, when working on the buildlet code), you can
 skip the "gomote create" step and use the special builder name
 "<build-config-name>@ip[:port>", such as "windows-amd64-2008@10.1.5.3".
 
+# Instance names
+
+Commands which take an instance name also accept an unambiguous prefix or
+substring of the name of one of your instances, such as "windows-amd64-0"
+for "user-username-windows-amd64-0". An exact name always wins, and when
+several instances match, the one in the active group is used. If the match
+is still ambiguous, the command fails and lists the candidates. Commands
+such as run, whose first argument may be either an instance or a command
+when a group is active, only accept full instance names in that case.
+
+Instances may also be given aliases, which are accepted anywhere an
+instance name is and are resolved before prefix matching:
+
+	$ gomote alias set win user-username-windows-amd64-0
+	$ gomote run win go/bin/go version
+	$ gomote alias list
+	$ gomote alias unset win
+
+Aliases are stored alongside groups. The aliases of instances which no
+longer exist are removed by alias list and alias set. An alias may not be
+a prefix of the name of an existing instance, since that would be
+confusing.
+
 # Groups
 
 Instances may be managed in named groups, and commands are broadcast to all
@@ -113,10 +154,45 @@ As this example demonstrates, groups are useful even if the group
 contains only a single instance: it can dramatically shorten most gomote
 commands.
 
+Groups are stored by the gomote server when it supports them, so they
+are available on all of your machines, or else in local files. The
+-local-groups flag keeps using local files. Local groups can be moved to
+the server once with "gomote group migrate". Only your own instances can
+be added to a group stored by the server, and destroyed instances are
+removed from groups automatically.
+
+A group stored by the server can be shared with other users with "gomote
+group share", after which "gomote group list" shows them which instances
+it contains, under the name owner/name. They can't modify the group, nor
+use its instances.
+
+# Configuration
+
+Default values for flags may be stored in a config file, whose location is
+printed by "gomote config path". Each line has the form "key = value", where
+a key such as "server" sets a global flag and a key such as "run.dir" sets a
+flag of a command. The "create.builder" key sets the builder type used when
+create is run without one. Flags passed on the command line always take
+precedence. The config file may be edited with the "config" subcommand:
+
+	$ gomote config set create.builder linux-amd64
+	$ gomote config list
+	create.builder = linux-amd64
+
+# Shell completion
+
+The completion command prints a script which completes subcommands, their
+flags and the builder types accepted by create, for bash, zsh or fish:
+
+	$ source <(gomote completion bash)
+
 # Tips and tricks
 
   - The create command accepts the -setup flag which also pushes a GOROOT
     and runs the appropriate equivalent of "make.bash" for the instance.
+    It warns up front if the instance looks short of disk space for that.
+  - The status command shows the free disk space, memory and load of an
+    instance's machine, and how much space its work directory takes up.
   - The create command accepts the -count flag for creating several
     instances at once.
   - The run command accepts the -collect flag for automatically writing
@@ -128,6 +204,50 @@ commands.
     a command until the output of the command matches some pattern. Useful
     for reproducing rare issues, and especially useful when used in tandem
     with -collect.
+  - The run command accepts the -split-output flag for keeping the
+    command's stderr apart from its stdout. When a command fails, run
+    reports its exit status and how long it ran.
+  - The putbootstrap command accepts the -version flag for installing a
+    particular bootstrap version of Go instead of the instance's default,
+    or -url and -sha256 for installing a release archive and checking it.
+    It reports the progress of the download and extraction as it goes.
+  - The create command accepts the -setup-run flag, with -setup, for
+    running all.bash, or its equivalent, with -setup-run=all, or another
+    command after building Go. Its output is written to the same file as
+    that of the build. With several instances, the result on each of them
+    is summarized at the end, with the path of its output and the end of
+    it if it failed. The output is kept under the user's cache directory,
+    or in the directory set by -log-dir, with a file per instance.
+  - The setup command sets up existing instances, or the instances of a
+    group, like create -setup does, with the same flags.
+  - When building Go with -setup fails because the instance has no
+    bootstrap version of Go, the version the make script asks for is
+    installed, as putbootstrap does, and Go is built again. The
+    -no-auto-bootstrap flag turns this off.
+  - The run command, and create -setup and setup for building Go, forward
+    GOEXPERIMENT, GODEBUG and GOFLAGS from the local environment, if
+    they're set, to the instances, and say so. The -forward-env flag
+    changes which variables are forwarded, and -e on run overrides them.
+  - The create command accepts the -cl flag, with -setup, for applying a
+    Gerrit CL, such as 12345 or 12345/3 for its third patch set, to the
+    pushed GOROOT before building it. It applies cleanly if GOROOT is
+    checked out at the CL's parent.
+  - The create command accepts the -install-go flag, and the install-go
+    command installs on existing instances, an official release of Go,
+    such as 1.22.6 or latest, from go.dev/dl, checking its published
+    SHA-256 checksum. It's handy when there's no local GOROOT to push.
+  - The benchcompare command compares the benchmarks of a package built
+    with two GOROOTs, -old and -new, on two new instances of a builder
+    type, or the two given by -instances. It sets them up as create -setup
+    does, runs go test -bench on both with the same -count, and prints a
+    comparison like benchstat's. With -interleave, the benchmarks run one
+    count at a time, alternating between the instances. The raw results
+    are kept, in old.txt and new.txt, for further analysis.
+  - The test command pushes GOROOT and runs go test on a package of it,
+    such as "gomote test -pkg net/http -run TestServe -count 10", with the
+    instance's go binary. With a group, it tests on all the instances at
+    once and ends with a table of the results by builder type. Like run,
+    it exits with status 3 if the tests fail.
   - The run command always streams output to a temporary file regardless
     of any additional flags to avoid losing output due to terminal
     scrollback. It always prints the location of the file.
@@ -139,10 +259,177 @@ to reproduce a rare failure, like so:
 	$ GOROOT=/path/to/goroot gomote create -setup -count=10 linux-amd64
 	$ gomote run -until='unexpected return pc' -collect go/bin/go run -run="MyFlakyTest" -count=100 runtime
 
+# Instance lifetime
+
+Instances expire when they go unused for a while, and "gomote extend"
+extends them. To get an instance for a fixed period instead, request
+its lifetime when creating it:
+
+	$ gomote create -lifetime=2h linux-amd64
+
+The instance is then destroyed once the lifetime elapses, even if it is
+still in use. The server limits the lifetime which may be requested, and
+rejects longer requests with an error naming the maximum.
+
+The same limit applies to "gomote extend": an instance can't be extended
+to last longer than the maximum for its owner and builder type, and the
+extension is shortened to fit, with a note saying so.
+
+To free your quota sooner, "gomote gc" destroys your instances which have
+been idle for longer than -idle. An instance is idle since it last ran a
+command, had files written or removed, or had an SSH session, so instances
+which are still being used aren't destroyed however old they are:
+
+	$ gomote gc -idle=30m
+
+# Labels
+
+Instances can be labeled when they're created, to tell which CL or
+pipeline each one belongs to when several people or bots share a quota:
+
+	$ gomote create -label=cl=12345 -label=owner=trybot linux-amd64
+
+The labels are shown by "gomote list" and "gomote status", and the -label
+flag of "gomote list" and "gomote gc" selects instances by label, either
+by key=value or by key alone:
+
+	$ gomote gc -idle=1h -label=owner=trybot
+
+# Interactive shell
+
+For long debugging sessions, "gomote shell" starts an interactive shell
+which keeps a single connection to the gomote server and a current target,
+so that commands don't need to repeat the instance name:
+
+	$ gomote shell
+	gomote> use linux-amd64-0
+	gomote(user-username-linux-amd64-0)> push
+	gomote(user-username-linux-amd64-0)> run go/bin/go test -short os
+	gomote(user-username-linux-amd64-0)> ls go/src
+
+The target may also be a group, with "use group:<name>", and defaults to
+the active group. The shell keeps a history of commands and completes
+command names and remote paths with the tab key. Interrupting a command
+cancels it without leaving the shell, and exiting the shell with "exit" or
+Ctrl-D leaves all instances running.
+
+# Timings
+
+The create, push and run commands accept a -timings flag, which prints a
+breakdown of where the time went once they are done: time spent queueing
+for and booting each instance, pushing GOROOT, running make.bash and so on.
+The breakdown is always printed if the command takes more than a minute.
+With -timings=json, it is written to stderr as a single JSON object instead,
+for tracking buildlet performance over time:
+
+	$ gomote create -count=4 -setup -timings=json gotip-linux-amd64 2>&1 | tail -1
+
+# Progress events
+
+Tools embedding gomote can ask it to write structured progress events for
+instance creation, pushes and command runs with the -progress-fd=N or
+-progress-file=path global flags. Each event is written as a line of JSON,
+and the human-readable output on stderr is unchanged. The events are
+described by the Event type in golang.org/x/build/cmd/gomote/progresstypes:
+
+	$ gomote -progress-fd=3 create -setup gotip-linux-amd64 3>progress.json
+
+# Operation log
+
+The -oplog global flag, the GOMOTE_OPLOG environment variable or the
+"oplog" config key names a file to which the create, destroy, extend, push,
+puttar, rm and run commands each append a line of JSON once done: when the
+command started, who ran it, the instances it targeted, its arguments with
+secrets such as -e GITHUB_TOKEN=... redacted, how long it took and its
+result. Several gomote processes may append to the same log at once. The
+"log" subcommand prints it, optionally only for an instance or recent
+commands, to reconstruct what happened to a shared instance:
+
+	$ gomote log -instance user-username-linux-amd64-0 -since 24h
+
+# Diagnosing problems
+
+The "doctor" subcommand checks the local gomote environment and
+connectivity: that the config, group and alias files parse, that the cache
+directory is writable, that you are logged in, that the gomote server can
+be reached and accepts your credentials, that the builder list can be
+fetched and that the ssh key pair exists. It first prints the proxy and CA
+settings in effect, then PASS, FAIL or WARN for each check, with a hint on
+how to fix any problem, and exits with a non-zero status if a critical
+check fails:
+
+	$ gomote doctor
+
+# Proxies and private CAs
+
+Both transports honor the HTTPS proxy given by $HTTPS_PROXY and $NO_PROXY.
+A SOCKS5 proxy given by $ALL_PROXY, such as socks5://localhost:1080, is
+used for every connection to the server instead. The -ca-bundle global flag,
+or its alias -ca-file, or $GOMOTE_CA_BUNDLE, names a PEM file of CA
+certificates to trust in addition to the system's, for servers with
+certificates from a private CA. "gomote doctor" prints the proxy and CA
+settings in effect.
+
+# Staging and self-hosted servers
+
+The -server global flag, the GOMOTE_SERVER environment variable or the
+"server" config key selects another gomote server, such as a staging or
+self-hosted one; the flag takes precedence over the environment, which
+takes precedence over the config file. For servers whose certificate can't
+be verified at all, the -insecure-skip-verify global flag turns off
+verification; it can't be used with Go's production servers. Each server
+other than the production ones gets its own credentials, so that logging in
+to one never sends its credentials to another. "gomote version" and "gomote
+doctor" print the server in use and where it was set:
+
+	$ GOMOTE_SERVER=gomote.staging.example.com:443 gomote version
+
+# Debugging the gomote server
+
+The -debug global flag, or setting GOMOTE_DEBUG=1, logs every RPC made to
+the gomote server to stderr: its method, target instance, request and
+response sizes, duration and status. Streaming RPCs are logged when they are
+opened and closed, along with the number of messages exchanged. The
+-debug-log flag writes the log to a file instead, which is convenient to
+attach to bug reports:
+
+	$ gomote -debug-log=/tmp/gomote.log run linux-amd64-0 go/bin/go version
+
+Requests which are safe to repeat, such as those made by "gomote list",
+"gomote ls" and "gomote status", are retried a couple of times when the
+server is briefly unavailable. The -retries global flag sets how many times;
+-retries=0 disables retrying.
+
+# Colors
+
+When stderr is a terminal, status messages are colored, and the name of
+each instance of a group is given its own color, so that the interleaved
+output of several instances is easy to follow. The -color global flag
+(auto, always or never) overrides this, and setting the NO_COLOR
+environment variable disables colors unless -color=always is set.
+
+# Exit codes
+
+The exit code of gomote tells scripts whether a failure is worth retrying:
+
+	0    success
+	1    an error which is not classified further
+	2    invalid usage, such as a bad flag or argument; do not retry
+	3    a command run on an instance failed
+	4    the instance does not exist or has expired
+	5    the server could not be reached or failed; usually worth retrying
+	6    "gomote gc" destroyed idle instances
+	130  the operation was interrupted or declined by the user
+
+The test command is an exception: when the tests fail on a single
+instance, gomote exits with the exit status of go test on it.
+
 # Legacy Infrastructure
 
 Setting the GOMOTEDISABLELUCI environmental variable equal to true will set the gomote client to communicate with
-the coordinator instead of the gomote server.
+the coordinator instead of the gomote server. Builder types are served by the gomote server's swarming backend, the
+coordinator or both; "gomote builders" lists which, and "gomote create" refuses builder types the selected backend
+can't serve.
 */
 package main
 
@@ -154,13 +441,15 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"golang.org/x/build/buildenv"
 	"golang.org/x/build/buildlet"
+	"golang.org/x/build/cmd/gomote/internal/output"
 	"golang.org/x/build/internal/gomote/protos"
 	"golang.org/x/build/internal/iapclient"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc"
 )
 
 var (
@@ -172,10 +461,22 @@ type command struct {
 	name string
 	des  string
 	run  func([]string) error
+	// flags returns a new FlagSet with the command's flags, which is
+	// used to generate shell completions. It is nil if the command does
+	// not parse its flags with a FlagSet of its own.
+	flags func() *flag.FlagSet
 }
 
 var commands = map[string]command{}
 
+// flagsOf adapts a function which defines a command's flags in a struct of
+// type T into a function suitable for command.flags.
+func flagsOf[T any](newFlagSet func(*T) *flag.FlagSet) func() *flag.FlagSet {
+	return func() *flag.FlagSet {
+		return newFlagSet(new(T))
+	}
+}
+
 func sortedCommands() []string {
 	s := make([]string, 0, len(commands))
 	for name := range commands {
@@ -195,55 +496,135 @@ Global flags:
 	for _, name := range sortedCommands() {
 		fmt.Fprintf(os.Stderr, "  %-13s %s\n", name, commands[name].des)
 	}
-	os.Exit(1)
+	os.Exit(exitUsage)
 }
 
-func registerCommand(name, des string, run func([]string) error) {
+func registerCommand(name, des string, run func([]string) error, flags func() *flag.FlagSet) {
 	if _, dup := commands[name]; dup {
 		panic("duplicate registration of " + name)
 	}
 	commands[name] = command{
-		name: name,
-		des:  des,
-		run:  run,
+		name:  name,
+		des:   des,
+		run:   run,
+		flags: flags,
 	}
 }
 
 func registerCommands() {
-	registerCommand("create", "create a buildlet; with no args, list types of buildlets", create)
-	registerCommand("destroy", "destroy a buildlet", destroy)
-	registerCommand("gettar", "extract a tar.gz from a buildlet", getTar)
-	registerCommand("group", "manage groups of instances", group)
-	registerCommand("ls", "list the contents of a directory on a buildlet", ls)
-	registerCommand("list", "list active buildlets", list)
-	registerCommand("ping", "test whether a buildlet is alive and reachable ", ping)
-	registerCommand("push", "sync your GOROOT directory to the buildlet", push)
-	registerCommand("put", "put files on a buildlet", put)
-	registerCommand("putbootstrap", "put bootstrap toolchain in place", putBootstrap)
-	registerCommand("puttar", "extract a tar.gz to a buildlet", putTar)
-	registerCommand("rdp", "Unimplimented: RDP (Remote Desktop Protocol) to a Windows buildlet", rdp)
-	registerCommand("rm", "delete files or directories", rm)
-	registerCommand("run", "run a command on a buildlet", run)
-	registerCommand("ssh", "ssh to a buildlet", ssh)
+	registerCommand("alias", "manage aliases for instance names", alias, nil)
+	registerCommand("benchcompare", "compare benchmarks between two GOROOTs", benchCompare, flagsOf(benchCompareFlagSet))
+	registerCommand("builders", "list the builder types instances can be created with", builders, flagsOf(buildersFlagSet))
+	registerCommand("completion", "generate a shell completion script", completion, nil)
+	registerCommand("config", "manage default flag values", configCmd, nil)
+	registerCommand("create", "create a buildlet; with no args, list types of buildlets", create, flagsOf(createFlagSet))
+	registerCommand("destroy", "destroy a buildlet", destroy, flagsOf(destroyFlagSet))
+	registerCommand("doctor", "diagnose problems with the gomote environment", doctor, doctorFlagSet)
+	registerCommand("extend", "extend the lifetime of a buildlet", extend, extendFlagSet)
+	registerCommand("gc", "destroy idle buildlets", gc, flagsOf(gcFlagSet))
+	registerCommand("gettar", "extract a tar.gz from a buildlet", getTar, flagsOf(getTarFlagSet))
+	registerCommand("group", "manage groups of instances", group, nil)
+	registerCommand("install-go", "install an official release of Go on a buildlet", installGo, flagsOf(installGoFlagSet))
+	registerCommand("ls", "list the contents of a directory on a buildlet", ls, flagsOf(lsFlagSet))
+	registerCommand("kill", "kill a command running on a buildlet", kill, killFlagSet)
+	registerCommand("list", "list active buildlets", list, flagsOf(listFlagSet))
+	registerCommand("log", "print the operation log of commands which changed instances", opLogCmd, flagsOf(opLogFlagSet))
+	registerCommand("ping", "test whether a buildlet is alive and reachable ", ping, flagsOf(pingFlagSet))
+	registerCommand("ps", "list the commands running on a buildlet", ps, psFlagSet)
+	registerCommand("push", "sync your GOROOT directory to the buildlet", push, flagsOf(pushFlagSet))
+	registerCommand("put", "put files on a buildlet", put, flagsOf(putFlagSet))
+	registerCommand("putbootstrap", "put bootstrap toolchain in place", putBootstrap, flagsOf(putBootstrapFlagSet))
+	registerCommand("puttar", "extract a tar.gz to a buildlet", putTar, flagsOf(putTarFlagSet))
+	registerCommand("rdp", "Unimplimented: RDP (Remote Desktop Protocol) to a Windows buildlet", rdp, nil)
+	registerCommand("rm", "delete files or directories", rm, flagsOf(rmFlagSet))
+	registerCommand("run", "run a command on a buildlet", run, flagsOf(runFlagSet))
+	registerCommand("setup", "push GOROOT to a buildlet and build Go", setupInstances, flagsOf(setupCommandFlagSet))
+	registerCommand("shell", "start an interactive shell", shell, shellFlagSet)
+	registerCommand("ssh", "ssh to a buildlet", ssh, sshFlagSet)
+	registerCommand("status", "show detailed status of a buildlet", instanceStatus, flagsOf(statusFlagSet))
+	registerCommand("tail", "print the end of a file on a buildlet, optionally following it", tail, flagsOf(tailFlagSet))
+	registerCommand("test", "push GOROOT to a buildlet and test a package", goTest, flagsOf(goTestFlagSet))
+	registerCommand("version", "print the client and server versions", version, flagsOf(versionFlagSet))
 }
 
 var (
-	serverAddr = flag.String("server", "gomote.golang.org:443", "Address for GRPC server")
+	serverAddr = flag.String("server", luciServerAddr, "Address for GRPC server; $GOMOTE_SERVER overrides the default and the config file")
+	transport  = flag.String("transport", "auto", "how to connect to the server: grpc, websocket to tunnel through HTTPS proxies, or auto to fall back to websocket when grpc fails")
+	colorFlag  = flag.String("color", "auto", "when to color the status output: auto, always or never; auto honors $NO_COLOR")
 )
 
+// styles styles the status output written to stderr.
+var styles output.Styler
+
 func main() {
 	// Set up and parse global flags.
 	groupName := flag.String("group", os.Getenv("GOMOTE_GROUP"), "name of the gomote group to apply commands to (default is $GOMOTE_GROUP)")
 	buildlet.RegisterFlags()
 	registerCommands()
 	flag.Usage = usage
+	if cfg, err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "# Warning: ignoring config file: %v\n", err)
+	} else {
+		config = cfg
+	}
+	warnUnknownConfigKeys()
+	applyConfig(flag.CommandLine, "")
+	if _, ok := config["server"]; ok {
+		serverSource = "config file"
+	}
+	if g := os.Getenv("GOMOTE_GROUP"); g != "" {
+		// The environment takes precedence over the config file.
+		flag.Set("group", g)
+	}
+	if s := os.Getenv("GOMOTE_SERVER"); s != "" {
+		flag.Set("server", s)
+		serverSource = "$GOMOTE_SERVER"
+	}
+	if p := os.Getenv("GOMOTE_OPLOG"); p != "" {
+		flag.Set("oplog", p)
+	}
+	beforeParse := *serverAddr
 	flag.Parse()
+	if *serverAddr != beforeParse {
+		serverSource = "-server"
+	}
 	args := flag.Args()
 	if len(args) == 0 {
 		usage()
 	}
-	if luciDisabled() {
+	if err := setupDebugLog(); err != nil {
+		logAndExitf("%v\n", err)
+	}
+	if err := setupProgress(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+	colorMode, err := output.ParseMode(*colorFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		usage()
+	}
+	styles = output.New(colorMode, os.Stderr)
+	switch *transport {
+	case "auto", "grpc", "websocket":
+	default:
+		fmt.Fprintf(os.Stderr, "invalid -transport %q; want grpc, websocket or auto\n", *transport)
+		usage()
+	}
+	if _, err := rpcCompression(os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		usage()
+	}
+	if luciDisabled() && serverSource == "default" {
 		*serverAddr = "build.golang.org:443"
+		serverSource = "$GOMOTEDISABLELUCI"
+	}
+	if err := checkInsecureSkipVerify(*serverAddr, *insecureSkipVerify); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		usage()
+	}
+	if *insecureSkipVerify {
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Not verifying the certificate of %s, as -insecure-skip-verify is set.", *serverAddr)))
 	}
 	// Set up globals.
 	buildEnv = buildenv.FromFlags()
@@ -274,35 +655,107 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Unknown command %q\n", cmdName)
 		usage()
 	}
-	if err := cmd.run(args[1:]); err != nil {
-		logAndExitf("Error running %s: %v\n", cmdName, err)
+	if *opLogFlag != "" && opLogCommands[cmdName] {
+		opLog = newOpRecorder(cmdName, args[1:])
+	}
+	err = cmd.run(args[1:])
+	recordOp(exitCode(err), err)
+	if errors.Is(err, errReclaimed) {
+		// Not a failure; only the exit code reports it.
+		os.Exit(exitReclaimed)
+	}
+	if err != nil {
+		if iapclient.IsCredentialsExpired(err) {
+			// The expired credentials have been removed, so running
+			// the command again prompts the user to log in.
+			err = fmt.Errorf("%w; run the command again to log in", iapclient.ErrCredentialsExpired)
+		}
+		fmt.Fprintf(os.Stderr, "Error running %s: %v\n", cmdName, err)
+		os.Exit(exitCode(err))
 	}
 }
 
+var (
+	serverClientMu sync.Mutex
+	serverClient   protos.GomoteServiceClient // dialed on first use
+)
+
 // gomoteServerClient returns a gomote server client which can be used to interact with the gomote GRPC server.
 // It will either retrieve a previously created authentication token or attempt to create a new one.
+// The connection is dialed once and shared by all callers for the life of the process.
 func gomoteServerClient(ctx context.Context) protos.GomoteServiceClient {
-	grpcClient, err := iapclient.GRPCClient(ctx, *serverAddr)
+	serverClientMu.Lock()
+	defer serverClientMu.Unlock()
+	if serverClient != nil {
+		return serverClient
+	}
+	grpcClient, err := dialServer(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dialing the server=%s failed with: %s\n", *serverAddr, err)
+		exitOp(exitServerError, fmt.Errorf("dialing the server %s: %w", *serverAddr, err))
+	}
+	serverClient = protos.NewGomoteServiceClient(grpcClient)
+	return serverClient
+}
+
+// luciServerAddr is the address of the gomote server, whose builder
+// types are served by its swarming backend.
+const luciServerAddr = "gomote.golang.org:443"
+
+// directDialTimeout is how long -transport=auto waits for a gRPC
+// connection before falling back to a WebSocket tunnel.
+const directDialTimeout = 20 * time.Second
+
+// dialServer dials the gomote server using the transport selected by
+// -transport.
+func dialServer(ctx context.Context) (*grpc.ClientConn, error) {
+	return dialServerAt(ctx, *serverAddr)
+}
+
+// dialServerAt is like dialServer, but dials the server at addr.
+func dialServerAt(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	d, err := serverDialer(addr)
+	if err != nil {
+		return nil, err
+	}
+	compressor, err := rpcCompression(os.Getenv)
 	if err != nil {
-		logAndExitf("dialing the server=%s failed with: %s\n", *serverAddr, err)
+		return nil, err
+	}
+	opts := append(retryDialOptions(), observeDialOptions()...)
+	opts = append(opts, compressionDialOptions(compressor)...)
+	// The errors are classified innermost, so that the other interceptors
+	// see the classified errors.
+	opts = append(opts, buildlet.ServerErrorDialOptions()...)
+	switch *transport {
+	case "grpc":
+		return d.GRPCClient(ctx, addr, opts...)
+	case "websocket":
+		return d.GRPCTunnelClient(ctx, addr, opts...)
+	}
+	// Log in first, so that the time it takes doesn't count against
+	// the timeout.
+	if _, err := d.TokenSource(ctx); err != nil {
+		return nil, err
 	}
-	return protos.NewGomoteServiceClient(grpcClient)
+	dctx, cancel := context.WithTimeout(ctx, directDialTimeout)
+	defer cancel()
+	conn, err := d.GRPCClient(dctx, addr, opts...)
+	if err == nil || ctx.Err() != nil {
+		return conn, err
+	}
+	fmt.Fprintf(os.Stderr, "# Unable to reach %s directly (%v); retrying through a WebSocket tunnel.\n", addr, err)
+	return d.GRPCTunnelClient(ctx, addr, opts...)
 }
 
 // logAndExitf is equivalent to Printf to Stderr followed by a call to os.Exit(1).
 func logAndExitf(format string, v ...interface{}) {
 	fmt.Fprintf(os.Stderr, format, v...)
-	os.Exit(1)
+	os.Exit(exitFailure)
 }
 
 func instanceDoesNotExist(err error) bool {
-	for err != nil {
-		if status.Code(err) == codes.NotFound {
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	return isServerError(err, buildlet.ErrInstanceNotFound)
 }
 
 func luciDisabled() bool {
@@ -0,0 +1,222 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/sync/errgroup"
+)
+
+// goTest pushes GOROOT to instances and runs go test on them.
+func goTest(args []string) error {
+	var flags goTestFlags
+	fs := goTestFlagSet(&flags)
+	parseFlags(fs, args)
+	if flags.pkg == "" {
+		return usageErrorf("test requires -pkg")
+	}
+	if flags.count < 0 {
+		return usageErrorf("invalid -count %d", flags.count)
+	}
+
+	ctx := context.Background()
+	var insts []string
+	switch fs.NArg() {
+	case 0:
+		if activeGroup == nil {
+			fmt.Fprintln(os.Stderr, "no active group found; need an active group with no arguments")
+			fs.Usage()
+		}
+		insts = activeGroup.Instances
+	case 1:
+		inst, err := resolveInstance(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		insts = []string{inst}
+	default:
+		fs.Usage()
+	}
+
+	goroot, err := getGOROOT()
+	if err != nil {
+		return err
+	}
+	env, err := forwardedEnv(flags.forwardEnv, nil, os.Getenv)
+	if err != nil {
+		return err
+	}
+	reportForwardedEnv(os.Stderr, env)
+	outDir, err := os.MkdirTemp("", "gomote")
+	if err != nil {
+		return err
+	}
+
+	t := newTimer("test")
+	defer t.report(os.Stderr, flags.timings)
+	detailedProgress := len(insts) == 1
+	src := newPushSource(goroot, gzip.DefaultCompression)
+	var resultsMu sync.Mutex
+	var results []goTestResult
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, inst := range insts {
+		inst := inst
+		eg.Go(func() error {
+			resp, err := gomoteServerClient(ctx).InstanceStatus(ctx, &protos.InstanceStatusRequest{GomoteId: inst})
+			if err != nil {
+				return fmt.Errorf("unable to retrieve status of instance %s: %w", inst, err)
+			}
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Pushing GOROOT %q to %s...", goroot, styles.Instance(inst))))
+			endPush := t.span("push", inst)
+			if err := doPush(ctx, inst, src, false, detailedProgress); err != nil {
+				return err
+			}
+			endPush()
+
+			outf, err := os.Create(filepath.Join(outDir, inst+".stdout"))
+			if err != nil {
+				return err
+			}
+			defer func() {
+				outf.Close()
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Wrote results from %s to %q.", styles.Instance(inst), outf.Name())))
+			}()
+			outputs := []io.Writer{outf}
+			if detailedProgress {
+				outputs = append(outputs, os.Stdout)
+			} else {
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Testing %s on %s...", flags.pkg, styles.Instance(inst))))
+			}
+			endTest := t.span("test", inst)
+			err = doRun(ctx, inst, "go/bin/go", goTestArgs(flags), runDir("go/src"), runEnv(env), runWriters(outputs...))
+			endTest()
+			var ce *cmdFailedError
+			if err != nil && !errors.As(err, &ce) {
+				return err
+			}
+			resultsMu.Lock()
+			results = append(results, goTestResult{inst: inst, builderType: resp.GetInstance().GetBuilderType(), err: ce})
+			resultsMu.Unlock()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	if len(results) == 1 {
+		if ce := results[0].err; ce != nil {
+			return remoteExitError{ce}
+		}
+		return nil
+	}
+	return reportGoTestResults(os.Stderr, results)
+}
+
+// goTestFlags are the flags of the test command.
+type goTestFlags struct {
+	pkg        string
+	run        string
+	count      int
+	race       bool
+	short      bool
+	forwardEnv string
+	timings    timingsFlag
+}
+
+func goTestFlagSet(flags *goTestFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "test usage: gomote test -pkg <package> [test-opts] [instance]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Pushes GOROOT to the instance, and runs go test on the package with the")
+		fmt.Fprintln(os.Stderr, "instance's go binary, which must have been built, such as with")
+		fmt.Fprintln(os.Stderr, "create -setup. The package is one of GOROOT, such as net/http, or a")
+		fmt.Fprintln(os.Stderr, "pattern such as std. With a group, the tests run on all its instances")
+		fmt.Fprintln(os.Stderr, "at once, and the results on each of them are summarized at the end.")
+		fmt.Fprintln(os.Stderr, "On a single instance, gomote exits with the exit status of go test if")
+		fmt.Fprintln(os.Stderr, "the tests fail.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.pkg, "pkg", "", "the import `path` of the package to test, such as net/http, or a pattern such as std")
+	fs.StringVar(&flags.run, "run", "", "run only the tests matching `regexp`, as go test -run does")
+	fs.IntVar(&flags.count, "count", 0, "run the tests this many times, as go test -count does; the default is go test's")
+	fs.BoolVar(&flags.race, "race", false, "enable the race detector, as go test -race does")
+	fs.BoolVar(&flags.short, "short", false, "tell long-running tests to shorten their run time, as go test -short does")
+	fs.StringVar(&flags.forwardEnv, "forward-env", defaultForwardEnv, forwardEnvUsage)
+	fs.Var(&flags.timings, "timings", timingsUsage)
+	return fs
+}
+
+// goTestArgs returns the arguments of go test requested by flags.
+func goTestArgs(flags goTestFlags) []string {
+	args := []string{"test"}
+	if flags.run != "" {
+		args = append(args, "-run="+flags.run)
+	}
+	if flags.count > 0 {
+		args = append(args, "-count="+strconv.Itoa(flags.count))
+	}
+	if flags.race {
+		args = append(args, "-race")
+	}
+	if flags.short {
+		args = append(args, "-short")
+	}
+	return append(args, flags.pkg)
+}
+
+// A goTestResult is the result of go test on an instance.
+type goTestResult struct {
+	inst        string
+	builderType string
+	err         *cmdFailedError // or nil if the tests passed
+}
+
+// reportGoTestResults writes a table of the results of go test on each
+// instance, by builder type, to w, followed by a summary. It returns
+// errCommandsFailed if the tests failed on any instance.
+func reportGoTestResults(w io.Writer, results []goTestResult) error {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].builderType != results[j].builderType {
+			return results[i].builderType < results[j].builderType
+		}
+		return results[i].inst < results[j].inst
+	})
+	failed := 0
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "# BUILDER\tINSTANCE\tRESULT")
+	for _, r := range results {
+		result := "ok"
+		if r.err != nil {
+			failed++
+			result = "FAIL: " + r.err.reason()
+		}
+		fmt.Fprintf(tw, "# %s\t%s\t%s\n", r.builderType, r.inst, result)
+	}
+	tw.Flush()
+	summary := fmt.Sprintf("# Tests passed on %d of %d instances.", len(results)-failed, len(results))
+	if failed > 0 {
+		fmt.Fprintln(w, styles.Failure(summary))
+		return errCommandsFailed
+	}
+	fmt.Fprintln(w, styles.Success(summary))
+	return nil
+}
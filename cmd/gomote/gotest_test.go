@@ -0,0 +1,52 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGoTestArgs(t *testing.T) {
+	for _, tc := range []struct {
+		flags goTestFlags
+		want  string
+	}{
+		{goTestFlags{pkg: "net/http"}, "test net/http"},
+		{goTestFlags{pkg: "runtime", run: "TestGC", count: 10, race: true, short: true}, "test -run=TestGC -count=10 -race -short runtime"},
+	} {
+		if got := strings.Join(goTestArgs(tc.flags), " "); got != tc.want {
+			t.Errorf("goTestArgs(%+v) = %q; want %q", tc.flags, got, tc.want)
+		}
+	}
+}
+
+func TestReportGoTestResults(t *testing.T) {
+	failed := &cmdFailedError{inst: "inst-b", cmd: "go/bin/go", err: errors.New("exit status 1")}
+	var buf strings.Builder
+	err := reportGoTestResults(&buf, []goTestResult{
+		{inst: "inst-c", builderType: "windows-amd64"},
+		{inst: "inst-b", builderType: "linux-arm64", err: failed},
+		{inst: "inst-a", builderType: "linux-arm64"},
+	})
+	if !errors.Is(err, errCommandsFailed) {
+		t.Errorf("reportGoTestResults() = %v; want %v", err, errCommandsFailed)
+	}
+	want := `# BUILDER        INSTANCE  RESULT
+# linux-arm64    inst-a    ok
+# linux-arm64    inst-b    FAIL: exit status 1
+# windows-amd64  inst-c    ok
+# Tests passed on 2 of 3 instances.
+`
+	if got := buf.String(); got != want {
+		t.Errorf("reportGoTestResults() wrote\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := reportGoTestResults(&buf, []goTestResult{{inst: "inst-a", builderType: "linux-amd64"}}); err != nil {
+		t.Errorf("reportGoTestResults() with passing tests = %v; want nil", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,6 +25,9 @@ func group(args []string) error {
 		"add":     {addToGroup, "add an existing instance to a group"},
 		"remove":  {removeFromGroup, "remove an existing instance from a group"},
 		"list":    {listGroups, "list existing groups and their details"},
+		"share":   {shareGroup, "let other users read a group stored by the server"},
+		"unshare": {unshareGroup, "stop sharing a group stored by the server"},
+		"migrate": {migrateGroups, "move groups from local files to the server"},
 	}
 	if len(args) == 0 {
 		var cmds []string
@@ -37,12 +41,12 @@ func group(args []string) error {
 			fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, cm[name].desc)
 		}
 		fmt.Fprintln(os.Stderr)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 	subCmd := args[0]
 	sc, ok := cm[subCmd]
 	if !ok {
-		return fmt.Errorf("unknown sub-command %q\n", subCmd)
+		return usageErrorf("unknown sub-command %q\n", subCmd)
 	}
 	return sc.run(args[1:])
 }
@@ -50,7 +54,7 @@ func group(args []string) error {
 func createGroup(args []string) error {
 	usage := func() {
 		fmt.Fprintln(os.Stderr, "group create usage: gomote group create <name>")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 	if len(args) != 1 {
 		usage()
@@ -60,6 +64,10 @@ func createGroup(args []string) error {
 }
 
 func doCreateGroup(name string) (*groupData, error) {
+	if useServerGroups() {
+		ctx := context.Background()
+		return createServerGroup(ctx, gomoteServerClient(ctx), name, nil)
+	}
 	if _, err := loadGroup(name); err == nil {
 		return nil, fmt.Errorf("group %q already exists", name)
 	}
@@ -68,20 +76,22 @@ func doCreateGroup(name string) (*groupData, error) {
 }
 
 func destroyGroup(args []string) error {
-	usage := func() {
-		fmt.Fprintln(os.Stderr, "group destroy usage: gomote group destroy <name>")
-		os.Exit(1)
-	}
-	if len(args) != 1 {
-		usage()
-	}
-	name := args[0]
-	_, err := loadGroup(name)
+	var flags destroyGroupFlags
+	fs := destroyGroupFlagSet(&flags)
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+	}
+	name := fs.Arg(0)
+	g, err := loadGroup(name)
 	if errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("group %q does not exist", name)
+		return usageErrorf("group %q does not exist", name)
 	} else if err != nil {
 		return fmt.Errorf("loading group %q: %w", name, err)
 	}
+	if err := confirmStdio(flags.force, fmt.Sprintf("remove group %q but keep its %d instances", name, len(g.Instances)), g.Instances, name); err != nil {
+		return err
+	}
 	if err := deleteGroup(name); err != nil {
 		return err
 	}
@@ -91,10 +101,29 @@ func destroyGroup(args []string) error {
 	return nil
 }
 
+// destroyGroupFlags are the flags of the group destroy command.
+type destroyGroupFlags struct {
+	force bool
+}
+
+func destroyGroupFlagSet(flags *destroyGroupFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("group destroy", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "group destroy usage: gomote group destroy [-f] <name>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Removes the group. Its instances are kept; destroy them as well with")
+		fmt.Fprintln(os.Stderr, "gomote destroy -destroy-group.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.force, "f", false, "do not ask for confirmation before removing the group")
+	return fs
+}
+
 func addToGroup(args []string) error {
 	usage := func() {
 		fmt.Fprintln(os.Stderr, "group add usage: gomote group add [instances ...]")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 	if len(args) == 0 {
 		usage()
@@ -116,7 +145,7 @@ func addToGroup(args []string) error {
 func removeFromGroup(args []string) error {
 	usage := func() {
 		fmt.Fprintln(os.Stderr, "group add usage: gomote group add [instances ...]")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 	if len(args) == 0 {
 		usage()
@@ -146,34 +175,43 @@ func removeFromGroup(args []string) error {
 func listGroups(args []string) error {
 	usage := func() {
 		fmt.Fprintln(os.Stderr, "group list usage: gomote group list")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 	if len(args) != 0 {
 		usage()
 	}
-	groups, err := loadAllGroups()
+	var groups []*groupData
+	var err error
+	if useServerGroups() {
+		ctx := context.Background()
+		groups, err = loadServerGroups(ctx, gomoteServerClient(ctx), true)
+	} else {
+		groups, err = loadAllGroups()
+	}
 	if err != nil {
 		return err
 	}
-	// N.B. Glob ignores I/O errors, so no matches also means the directory
-	// does not exist.
 	emit := func(name, inst string) {
 		fmt.Printf("%s\t%s\t\n", name, inst)
 	}
 	emit("Name", "Instances")
 	for _, g := range groups {
 		sort.Strings(g.Instances)
+		name := g.Name
+		if g.Shared && g.Owner == "" {
+			name += " (shared)"
+		}
 		emitted := false
 		for _, inst := range g.Instances {
 			if !emitted {
-				emit(g.Name, inst)
+				emit(name, inst)
 			} else {
 				emit("", inst)
 			}
 			emitted = true
 		}
 		if !emitted {
-			emit(g.Name, "(none)")
+			emit(name, "(none)")
 		}
 	}
 	if len(groups) == 0 {
@@ -188,6 +226,19 @@ type groupData struct {
 
 	// Instances is a list of instances in the group.
 	Instances []string `json:"instances"`
+
+	// The following are only set for groups stored by the server.
+	//
+	// Owner is the user name of the owner of a group shared by another
+	// user, whose Name is then of the form "owner/name". It is empty for
+	// the caller's own groups.
+	Owner string `json:"-"`
+	// Shared is true if users other than the owner may read the group.
+	Shared bool `json:"-"`
+	// server is true if the group is stored by the server, which has the
+	// stored instances as members.
+	server bool
+	stored []string
 }
 
 func (g *groupData) has(inst string) bool {
@@ -199,7 +250,18 @@ func (g *groupData) has(inst string) bool {
 	return false
 }
 
+// loadAllGroups loads the caller's groups, from the server if it stores
+// groups, or else from local files.
 func loadAllGroups() ([]*groupData, error) {
+	if useServerGroups() {
+		ctx := context.Background()
+		return loadServerGroups(ctx, gomoteServerClient(ctx), false)
+	}
+	return loadLocalGroups()
+}
+
+// loadLocalGroups loads the groups stored in local files.
+func loadLocalGroups() ([]*groupData, error) {
 	dir, err := groupDir()
 	if err != nil {
 		return nil, fmt.Errorf("acquiring group directory: %w", err)
@@ -218,7 +280,13 @@ func loadAllGroups() ([]*groupData, error) {
 	return groups, nil
 }
 
+// loadGroup loads the named group, from the server if it stores groups,
+// or else from a local file.
 func loadGroup(name string) (*groupData, error) {
+	if useServerGroups() {
+		ctx := context.Background()
+		return loadServerGroup(ctx, gomoteServerClient(ctx), name)
+	}
 	fname, err := groupFilePath(name)
 	if err != nil {
 		return nil, fmt.Errorf("loading group %q: %w", name, err)
@@ -244,22 +312,38 @@ func loadGroupFromFile(fname string) (*groupData, error) {
 	//
 	// Otherwise, we can get into situations where we sometimes
 	// don't have an accurate record.
-	ctx := context.Background()
-	newInstances := make([]string, 0, len(g.Instances))
-	for _, inst := range g.Instances {
+	g.Instances, err = pruneInstances(context.Background(), g.Instances)
+	if err != nil {
+		return nil, err
+	}
+	return g, storeLocalGroup(g)
+}
+
+// pruneInstances returns the instances in insts which still exist.
+func pruneInstances(ctx context.Context, insts []string) ([]string, error) {
+	live := make([]string, 0, len(insts))
+	for _, inst := range insts {
 		err := doPing(ctx, inst)
 		if instanceDoesNotExist(err) {
 			continue
 		} else if err != nil {
 			return nil, err
 		}
-		newInstances = append(newInstances, inst)
+		live = append(live, inst)
 	}
-	g.Instances = newInstances
-	return g, storeGroup(g)
+	return live, nil
 }
 
+// storeGroup stores the group where it was loaded from.
 func storeGroup(data *groupData) error {
+	if data.server {
+		ctx := context.Background()
+		return storeServerGroup(ctx, gomoteServerClient(ctx), data)
+	}
+	return storeLocalGroup(data)
+}
+
+func storeLocalGroup(data *groupData) error {
 	fname, err := groupFilePath(data.Name)
 	if err != nil {
 		return fmt.Errorf("storing group %q: %w", data.Name, err)
@@ -278,7 +362,17 @@ func storeGroup(data *groupData) error {
 	return nil
 }
 
+// deleteGroup deletes the named group, from the server if it stores
+// groups, or else from local files.
 func deleteGroup(name string) error {
+	if useServerGroups() {
+		ctx := context.Background()
+		return deleteServerGroup(ctx, gomoteServerClient(ctx), name)
+	}
+	return deleteLocalGroup(name)
+}
+
+func deleteLocalGroup(name string) error {
 	fname, err := groupFilePath(name)
 	if err != nil {
 		return fmt.Errorf("deleting group %q: %w", name, err)
@@ -0,0 +1,224 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/cmd/gomote/internal/builderlist"
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/sync/errgroup"
+)
+
+// installGo installs an official release of Go on instances.
+func installGo(args []string) error {
+	var flags installGoFlags
+	fs := installGoFlagSet(&flags)
+	parseFlags(fs, args)
+
+	var instSet []string
+	var version string
+	switch fs.NArg() {
+	case 1:
+		if activeGroup == nil {
+			fmt.Fprintln(os.Stderr, "no active group found; need an active group with only 1 argument")
+			fs.Usage()
+		}
+		instSet = activeGroup.Instances
+		version = fs.Arg(0)
+	case 2:
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		instSet = []string{inst}
+		version = fs.Arg(1)
+	default:
+		fs.Usage()
+	}
+
+	ctx := context.Background()
+	releases, err := fetchGoReleases(ctx)
+	if err != nil {
+		return err
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, inst := range instSet {
+		inst := inst
+		eg.Go(func() error {
+			resp, err := gomoteServerClient(ctx).InstanceStatus(ctx, &protos.InstanceStatusRequest{GomoteId: inst})
+			if err != nil {
+				return fmt.Errorf("unable to retrieve status of instance %s: %w", inst, err)
+			}
+			builderType := resp.GetInstance().GetBuilderType()
+			goos, goarch := builderlist.Platform(builderType)
+			if goos == "" {
+				return fmt.Errorf("unable to determine the GOOS and GOARCH of %s from its builder type %q", inst, builderType)
+			}
+			archive, err := findGoArchive(releases, version, goos, goarch)
+			if err != nil {
+				return err
+			}
+			return doInstallGo(ctx, inst, resp.GetInstance().GetWorkingDir(), archive, flags.force)
+		})
+	}
+	return eg.Wait()
+}
+
+// installGoFlags are the flags of the install-go command.
+type installGoFlags struct {
+	force bool
+}
+
+func installGoFlagSet(flags *installGoFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("install-go", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "install-go usage: gomote install-go [install-go-opts] [instance] <version>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Installs an official release of Go, such as 1.22.6 or latest, built")
+		fmt.Fprintln(os.Stderr, "for the instance's GOOS and GOARCH, in the go directory of its work")
+		fmt.Fprintln(os.Stderr, "directory. The instance downloads the release from go.dev/dl and")
+		fmt.Fprintln(os.Stderr, "verifies its published SHA-256 checksum.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.force, "force", false, "replace the instance's go directory if it already has one")
+	return fs
+}
+
+// goReleasesURL lists the releases of Go, including the unstable and
+// archived ones, with their files.
+const goReleasesURL = "https://go.dev/dl/?mode=json&include=all"
+
+// goReleasesTimeout bounds fetching the releases of Go.
+const goReleasesTimeout = 30 * time.Second
+
+// A goRelease is a release of Go listed by go.dev/dl.
+type goRelease struct {
+	Version string   `json:"version"`
+	Stable  bool     `json:"stable"`
+	Files   []goFile `json:"files"`
+}
+
+// A goFile is a file of a goRelease.
+type goFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+	Kind     string `json:"kind"` // "archive", "installer" or "source"
+}
+
+// URL returns the URL f is downloaded from.
+func (f goFile) URL() string {
+	return "https://go.dev/dl/" + f.Filename
+}
+
+// fetchGoReleases fetches the releases of Go, newest first.
+func fetchGoReleases(ctx context.Context) ([]goRelease, error) {
+	ctx, cancel := context.WithTimeout(ctx, goReleasesTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, goReleasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the releases of Go: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to list the releases of Go: %s returned %s", goReleasesURL, resp.Status)
+	}
+	var releases []goRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("unable to list the releases of Go: %w", err)
+	}
+	return releases, nil
+}
+
+// findGoArchive returns the .tar.gz archive of the version of Go in
+// releases, which are newest first, for goos and goarch. The version may be
+// "latest" for the newest stable release, and its "go" prefix is optional.
+func findGoArchive(releases []goRelease, version, goos, goarch string) (goFile, error) {
+	var r *goRelease
+	for i := range releases {
+		if (version == "latest" && releases[i].Stable) || releases[i].Version == version || releases[i].Version == "go"+version {
+			r = &releases[i]
+			break
+		}
+	}
+	if r == nil {
+		return goFile{}, usageErrorf("Go version %q isn't listed on go.dev/dl", version)
+	}
+	for _, f := range r.Files {
+		arch := f.Arch
+		if arch == "armv6l" {
+			arch = "arm"
+		}
+		if f.Kind == "archive" && f.OS == goos && arch == goarch && strings.HasSuffix(f.Filename, ".tar.gz") {
+			return f, nil
+		}
+	}
+	return goFile{}, fmt.Errorf("%s has no .tar.gz archive for %s/%s on go.dev/dl", r.Version, goos, goarch)
+}
+
+// doInstallGo has inst download and extract archive in the go directory
+// of its work directory, workDir, which may be unknown. Unless force is
+// set, it fails if inst already has a go directory.
+func doInstallGo(ctx context.Context, inst, workDir string, archive goFile, force bool) error {
+	client := gomoteServerClient(ctx)
+	resp, err := client.ListDirectory(ctx, &protos.ListDirectoryRequest{
+		GomoteId:  inst,
+		Directory: ".",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list the work directory of %s: %w", inst, err)
+	}
+	for _, entry := range resp.GetEntries() {
+		if de := (buildlet.DirEntry{Line: entry}); de.IsDir() && strings.TrimSuffix(de.Name(), "/") == "go" {
+			if !force {
+				return fmt.Errorf("%s already has a go directory; use -force to replace it", inst)
+			}
+			if _, err := client.RemoveFiles(ctx, &protos.RemoveFilesRequest{
+				GomoteId: inst,
+				Paths:    []string{"go"},
+			}); err != nil {
+				return fmt.Errorf("unable to remove the go directory of %s: %w", inst, err)
+			}
+			break
+		}
+	}
+	fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Installing %s on %s...", archive.Filename, styles.Instance(inst))))
+	got, err := client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{
+		GomoteId: inst,
+		Url:      archive.URL(),
+		Sha256:   archive.SHA256,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to install %s on %s: %w", archive.Version, inst, err)
+	}
+	if sum := got.GetSha256(); sum != "" && sum != archive.SHA256 {
+		return fmt.Errorf("unable to install %s on %s: the archive has SHA-256 checksum %s; want %s", archive.Version, inst, sum, archive.SHA256)
+	}
+	goroot := "$WORKDIR/go"
+	if workDir != "" {
+		goroot = path.Join(workDir, "go")
+	}
+	fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Installed %s in %s on %s; run it as go/bin/go.", archive.Version, goroot, styles.Instance(inst))))
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestFindGoArchive(t *testing.T) {
+	releases := []goRelease{
+		{Version: "go1.24rc1", Files: []goFile{
+			{Filename: "go1.24rc1.linux-amd64.tar.gz", OS: "linux", Arch: "amd64", Version: "go1.24rc1", SHA256: "rc", Kind: "archive"},
+		}},
+		{Version: "go1.23.2", Stable: true, Files: []goFile{
+			{Filename: "go1.23.2.src.tar.gz", Version: "go1.23.2", SHA256: "src", Kind: "source"},
+			{Filename: "go1.23.2.darwin-arm64.pkg", OS: "darwin", Arch: "arm64", Version: "go1.23.2", SHA256: "pkg", Kind: "installer"},
+			{Filename: "go1.23.2.darwin-arm64.tar.gz", OS: "darwin", Arch: "arm64", Version: "go1.23.2", SHA256: "darwin", Kind: "archive"},
+			{Filename: "go1.23.2.linux-amd64.tar.gz", OS: "linux", Arch: "amd64", Version: "go1.23.2", SHA256: "linux", Kind: "archive"},
+			{Filename: "go1.23.2.linux-armv6l.tar.gz", OS: "linux", Arch: "armv6l", Version: "go1.23.2", SHA256: "arm", Kind: "archive"},
+			{Filename: "go1.23.2.windows-amd64.zip", OS: "windows", Arch: "amd64", Version: "go1.23.2", SHA256: "zip", Kind: "archive"},
+		}},
+		{Version: "go1.22.8", Stable: true, Files: []goFile{
+			{Filename: "go1.22.8.linux-amd64.tar.gz", OS: "linux", Arch: "amd64", Version: "go1.22.8", SHA256: "old", Kind: "archive"},
+		}},
+	}
+	for _, tc := range []struct {
+		version, goos, goarch string
+		want                  string // the filename, or "" for an error
+		wantExit              int
+	}{
+		{"latest", "linux", "amd64", "go1.23.2.linux-amd64.tar.gz", 0},
+		{"latest", "darwin", "arm64", "go1.23.2.darwin-arm64.tar.gz", 0},
+		{"1.22.8", "linux", "amd64", "go1.22.8.linux-amd64.tar.gz", 0},
+		{"go1.22.8", "linux", "amd64", "go1.22.8.linux-amd64.tar.gz", 0},
+		{"1.24rc1", "linux", "amd64", "go1.24rc1.linux-amd64.tar.gz", 0},
+		{"1.23.2", "linux", "arm", "go1.23.2.linux-armv6l.tar.gz", 0},
+		{"1.23.2", "windows", "amd64", "", exitFailure},
+		{"1.22.8", "darwin", "arm64", "", exitFailure},
+		{"1.21.0", "linux", "amd64", "", exitUsage},
+	} {
+		f, err := findGoArchive(releases, tc.version, tc.goos, tc.goarch)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("findGoArchive(%q, %s/%s) = %s; want an error", tc.version, tc.goos, tc.goarch, f.Filename)
+			} else if code := exitCode(err); code != tc.wantExit {
+				t.Errorf("findGoArchive(%q, %s/%s) error %q has exit code %d; want %d", tc.version, tc.goos, tc.goarch, err, code, tc.wantExit)
+			}
+			continue
+		}
+		if err != nil || f.Filename != tc.want {
+			t.Errorf("findGoArchive(%q, %s/%s) = %s, %v; want %s", tc.version, tc.goos, tc.goarch, f.Filename, err, tc.want)
+		}
+	}
+	if f, _ := findGoArchive(releases, "latest", "linux", "amd64"); f.URL() != "https://go.dev/dl/go1.23.2.linux-amd64.tar.gz" || f.SHA256 != "linux" {
+		t.Errorf("findGoArchive(latest) = %+v; want the URL and checksum of go1.23.2.linux-amd64.tar.gz", f)
+	}
+}
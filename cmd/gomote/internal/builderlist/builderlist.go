@@ -0,0 +1,610 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package builderlist lists the builder types gomote instances can be
+// created with, caching them on disk.
+//
+// The builder types are those of the coordinator's listing of builders,
+// merged with those of the gomote server's swarming backend if the Client
+// can list them, each recording which backends it's available through.
+// They optionally include the coordinator's misc-compile builders, which
+// only cross-compile for other ports and can't be created instances with.
+package builderlist
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/build/types"
+)
+
+// The backends builder types are available through.
+const (
+	BackendCoordinator = "coordinator"
+	BackendSwarming    = "swarming"
+)
+
+// A Builder is a builder type instances can be created with.
+type Builder struct {
+	Name   string `json:"name"`
+	GOOS   string `json:"goos,omitempty"`   // parsed from Name, if it can be
+	GOARCH string `json:"goarch,omitempty"` // parsed from Name, if it can be
+
+	// Backends are the backends, such as BackendSwarming, the builder
+	// type is available through, sorted.
+	Backends []string `json:"backends,omitempty"`
+
+	// The fields below are only known for the builders of the
+	// coordinator.
+	HostType  string `json:"host_type,omitempty"`
+	IsReverse bool   `json:"reverse,omitempty"`
+	ExpectNum int    `json:"expect_num,omitempty"` // the number of machines of a reverse builder, if known
+	Image     string `json:"image,omitempty"`      // the container or VM image of the builder's host
+
+	// Ports are the ports, such as "linux/arm", a misc-compile
+	// builder cross-compiles for.
+	Ports []string `json:"ports,omitempty"`
+
+	// Deprecated is whether the builder is on its way out: its notes
+	// say it's deprecated, or it's a reverse builder which is
+	// expected to have no machines anymore. Replacement is the builder
+	// to use instead, if its notes say "Deprecated: use <name>".
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// AvailableVia reports whether b is available through backend. Builder
+// types whose backends aren't known, such as those cached by older
+// versions of this package, are assumed to be available.
+func (b Builder) AvailableVia(backend string) bool {
+	return len(b.Backends) == 0 || slices.Contains(b.Backends, backend)
+}
+
+// MiscCompile reports whether b is one of the coordinator's misc-compile
+// builders, which are only listed if the Client's All field is set.
+func (b Builder) MiscCompile() bool {
+	return strings.HasPrefix(b.Name, "misc-compile")
+}
+
+// DefaultTTL is how long the cached builder types are used, unless the
+// Client says otherwise.
+const DefaultTTL = time.Hour
+
+// A Client lists the builder types. Its zero value fetches the
+// coordinator's listing of builders every time.
+type Client struct {
+	// HTTPClient is the client fetching the coordinator's listing of
+	// builders. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// URL is the URL of the coordinator's listing of builders. If
+	// empty, types.BuilderListingURL is used.
+	URL string
+
+	// CapacityURL is the URL of the coordinator's listing of the
+	// capacity of its reverse host types. If empty,
+	// types.HostCapacityURL is used.
+	CapacityURL string
+
+	// ListSwarming, if non-nil, lists the names of the builder types
+	// of the gomote server's swarming backend, which are merged with
+	// the coordinator's.
+	ListSwarming func(context.Context) ([]string, error)
+
+	// Timeout, if positive, bounds how long each attempt to fetch the
+	// builder types may take. A failed attempt is retried once.
+	Timeout time.Duration
+
+	// CacheFile is the file caching the builder types. If empty, they
+	// aren't cached.
+	CacheFile string
+
+	// TTL is how long the cached builder types are used before being
+	// fetched again. If zero, DefaultTTL is used.
+	TTL time.Duration
+
+	// Logf, if non-nil, logs the problems with the cache which don't
+	// prevent listing the builder types.
+	Logf func(format string, args ...any)
+
+	// All, if set, includes the misc-compile builders in the listed
+	// builder types.
+	All bool
+
+	// UseSnapshot, if set, makes List and Refresh return a built-in
+	// snapshot of common builder types, rather than an error, if the
+	// builder types can neither be fetched nor read from the cache.
+	// It's for callers which only display them.
+	UseSnapshot bool
+
+	// now returns the current time. If nil, time.Now is used.
+	now func() time.Time
+}
+
+// A FetchError is a failure to fetch one of the coordinator's listings.
+type FetchError struct {
+	URL        string
+	StatusCode int   // the status code of the response, if it wasn't 200 OK
+	Err        error // the error which occurred otherwise
+}
+
+func (e *FetchError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("fetching %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("fetching %s: %v", e.URL, e.Err)
+}
+
+func (e *FetchError) Unwrap() error { return e.Err }
+
+// cacheVersion is the version of the content of the CacheFile. Caches of
+// other versions, which may lack builder types or some of their fields,
+// are only used if the builder types can't be fetched.
+const cacheVersion = 4
+
+// listing is the builder types, including the misc-compile builders, and
+// the retired ones.
+type listing struct {
+	Builders []Builder `json:"builders"`
+
+	// Retired maps the names of the retired builders of the
+	// coordinator to those of their replacements, or "".
+	Retired map[string]string `json:"retired,omitempty"`
+
+	// partial is whether some of the backends couldn't be listed, in
+	// which case the listing isn't cached.
+	partial bool
+}
+
+// cache is the content of the CacheFile.
+type cache struct {
+	Version int       `json:"version"`
+	Fetched time.Time `json:"fetched"`
+	listing
+}
+
+// List returns the builder types, sorted by name. It returns the cached
+// ones if they were fetched less than the TTL ago. Otherwise, it fetches
+// them and caches them. If that fails, it returns the cached ones
+// regardless of their age, or the snapshot if there are none and the
+// Client uses it, and logs why.
+func (c *Client) List(ctx context.Context) ([]Builder, error) {
+	return c.list(ctx, false)
+}
+
+// Refresh fetches the builder types, sorted by name, and caches them. If
+// that fails, it falls back like List does.
+func (c *Client) Refresh(ctx context.Context) ([]Builder, error) {
+	return c.list(ctx, true)
+}
+
+func (c *Client) list(ctx context.Context, refresh bool) ([]Builder, error) {
+	l, err := c.listing(ctx, refresh)
+	if err != nil {
+		return nil, err
+	}
+	if c.All {
+		return l.Builders, nil
+	}
+	var filtered []Builder
+	for _, b := range l.Builders {
+		if !b.MiscCompile() {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered, nil
+}
+
+// A Retirement describes a builder type which was retired or deprecated.
+type Retirement struct {
+	Name        string
+	Replacement string // the builder type to use instead, if any
+	Retired     bool   // whether the builder type was removed, rather than only deprecated
+}
+
+func (r *Retirement) String() string {
+	status := "is deprecated"
+	if r.Retired {
+		status = "was retired"
+	}
+	if r.Replacement == "" {
+		return fmt.Sprintf("%s %s", r.Name, status)
+	}
+	return fmt.Sprintf("%s %s; use %s", r.Name, status, r.Replacement)
+}
+
+// Retirement returns the retirement of the builder type name, or nil if
+// it's neither retired nor deprecated, or unknown. The builder types are
+// listed like List does.
+func (c *Client) Retirement(ctx context.Context, name string) (*Retirement, error) {
+	l, err := c.listing(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if replacement, ok := l.Retired[name]; ok {
+		return &Retirement{Name: name, Replacement: replacement, Retired: true}, nil
+	}
+	for _, b := range l.Builders {
+		if b.Name == name && b.Deprecated {
+			return &Retirement{Name: name, Replacement: b.Replacement}, nil
+		}
+	}
+	return nil, nil
+}
+
+// listing returns the listing of the builder types, from the cache or
+// fetched, as List does.
+func (c *Client) listing(ctx context.Context, refresh bool) (*listing, error) {
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+	var cached *cache
+	if c.CacheFile != "" {
+		cached = c.readCache()
+	}
+	if cached != nil && cached.Version == cacheVersion && !refresh {
+		ttl := c.TTL
+		if ttl == 0 {
+			ttl = DefaultTTL
+		}
+		if age := now.Sub(cached.Fetched); age >= 0 && age < ttl {
+			return &cached.listing, nil
+		}
+	}
+	l, err := c.fetch(ctx)
+	if err != nil && retryable(err) && ctx.Err() == nil {
+		l, err = c.fetch(ctx)
+	}
+	if err != nil {
+		switch {
+		case cached != nil:
+			c.logf("using the builder types cached %s: %v", ago(now.Sub(cached.Fetched)), err)
+			return &cached.listing, nil
+		case c.UseSnapshot:
+			c.logf("using a built-in list of common builder types: %v", err)
+			return &listing{Builders: snapshot()}, nil
+		}
+		return nil, err
+	}
+	if c.CacheFile != "" && !l.partial {
+		if err := c.writeCache(&cache{Version: cacheVersion, Fetched: now, listing: *l}); err != nil {
+			c.logf("caching the builder types: %v", err)
+		}
+	}
+	return l, nil
+}
+
+// retryable reports whether fetching the builder types may succeed if
+// retried after failing with err. Requests the server rejected won't.
+func retryable(err error) bool {
+	var fe *FetchError
+	if errors.As(err, &fe) && fe.StatusCode >= 400 && fe.StatusCode < 500 {
+		return false
+	}
+	return true
+}
+
+// ago describes the age d of the cache, such as "3 hours ago".
+func ago(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "less than a minute ago"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	}
+	return plural(int(d/time.Hour), "hour")
+}
+
+// snapshotJSON is a snapshot of common builder types of the coordinator
+// and of the gomote server's swarming backend.
+//
+//go:embed snapshot.json
+var snapshotJSON []byte
+
+// snapshot returns the snapshot of common builder types, merged like
+// fetched ones.
+func snapshot() []Builder {
+	var s struct {
+		Coordinator []Builder `json:"coordinator"`
+		Swarming    []Builder `json:"swarming"`
+	}
+	if err := json.Unmarshal(snapshotJSON, &s); err != nil {
+		panic(fmt.Sprintf("invalid snapshot of builder types: %v", err))
+	}
+	swarming := make([]string, 0, len(s.Swarming))
+	for _, b := range s.Swarming {
+		swarming = append(swarming, b.Name)
+	}
+	return merge(s.Coordinator, swarming)
+}
+
+// readCache returns the content of the CacheFile, or nil if it can't be
+// read.
+func (c *Client) readCache() *cache {
+	data, err := os.ReadFile(c.CacheFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.logf("reading the cached builder types: %v", err)
+		}
+		return nil
+	}
+	var cached cache
+	if err := json.Unmarshal(data, &cached); err != nil {
+		c.logf("ignoring the cached builder types in %s: %v", c.CacheFile, err)
+		return nil
+	}
+	return &cached
+}
+
+func (c *Client) writeCache(cached *cache) error {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.CacheFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.CacheFile, data, 0644)
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
+
+// fetch fetches the builder types from the coordinator and, if the Client
+// can list them, from the gomote server's swarming backend, concurrently.
+// It only fails if none of them can be fetched; otherwise, it logs why
+// the others can't and returns a partial listing.
+func (c *Client) fetch(ctx context.Context) (*listing, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	var (
+		swarming    []string
+		swarmingErr error
+		done        = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		if c.ListSwarming != nil {
+			swarming, swarmingErr = c.ListSwarming(ctx)
+		}
+	}()
+	url := c.URL
+	if url == "" {
+		url = types.BuilderListingURL
+	}
+	var l types.BuilderListing
+	coordinatorErr := c.getJSON(ctx, url, &l)
+	<-done
+
+	switch {
+	case coordinatorErr != nil && (c.ListSwarming == nil || swarmingErr != nil):
+		return nil, errors.Join(coordinatorErr, swarmingErr)
+	case coordinatorErr != nil:
+		c.logf("listing the builder types of the %s backend: %v", BackendCoordinator, coordinatorErr)
+		return &listing{Builders: merge(nil, swarming), partial: true}, nil
+	case swarmingErr != nil:
+		c.logf("listing the builder types of the %s backend: %v", BackendSwarming, swarmingErr)
+		return &listing{Builders: merge(coordinatorBuilders(&l), nil), Retired: l.Retired, partial: true}, nil
+	}
+	return &listing{Builders: merge(coordinatorBuilders(&l), swarming), Retired: l.Retired}, nil
+}
+
+// merge merges the builder types of the coordinator with the names of
+// those of the swarming backend, sorted by name, recording the backends
+// each is available through. Builder types of both backends have the
+// fields of the coordinator's.
+func merge(coordinator []Builder, swarming []string) []Builder {
+	byName := make(map[string]*Builder)
+	var bs []*Builder
+	for _, b := range coordinator {
+		b := b
+		b.Backends = []string{BackendCoordinator}
+		byName[b.Name] = &b
+		bs = append(bs, &b)
+	}
+	for _, name := range swarming {
+		if b, ok := byName[name]; ok {
+			if !slices.Contains(b.Backends, BackendSwarming) {
+				b.Backends = append(b.Backends, BackendSwarming)
+			}
+			continue
+		}
+		goos, goarch := Platform(name)
+		b := &Builder{Name: name, GOOS: goos, GOARCH: goarch, Backends: []string{BackendSwarming}}
+		byName[name] = b
+		bs = append(bs, b)
+	}
+	merged := make([]Builder, 0, len(bs))
+	for _, b := range bs {
+		merged = append(merged, *b)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Name < merged[j].Name
+	})
+	return merged
+}
+
+// Capacity fetches the current capacity of the coordinator's reverse host
+// types, keyed by name. It's never cached.
+func (c *Client) Capacity(ctx context.Context) (map[string]types.HostCapacity, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	url := c.CapacityURL
+	if url == "" {
+		url = types.HostCapacityURL
+	}
+	var capacity map[string]types.HostCapacity
+	if err := c.getJSON(ctx, url, &capacity); err != nil {
+		return nil, err
+	}
+	return capacity, nil
+}
+
+// getJSON fetches the JSON document at url into v.
+func (c *Client) getJSON(ctx context.Context, url string, v any) error {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return &FetchError{URL: url, Err: err}
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return &FetchError{URL: url, Err: err}
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return &FetchError{URL: url, StatusCode: res.StatusCode}
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return &FetchError{URL: url, Err: fmt.Errorf("decoding: %w", err)}
+	}
+	return nil
+}
+
+// coordinatorBuilders returns the builders of the coordinator's listing
+// which instances can be created with, and its misc-compile builders,
+// sorted by name.
+func coordinatorBuilders(l *types.BuilderListing) []Builder {
+	var bs []Builder
+	for b, bi := range l.Builders {
+		if (Builder{Name: b}).MiscCompile() {
+			goos, goarch := Platform(b)
+			bs = append(bs, Builder{
+				Name:     b,
+				GOOS:     goos,
+				GOARCH:   goarch,
+				HostType: bi.HostType,
+				Ports:    miscCompilePorts(b, bi.Notes),
+			})
+			continue
+		}
+		hi, ok := l.Host(b)
+		if !ok {
+			continue
+		}
+		if !hi.IsReverse && hi.ContainerImage == "" && hi.VMImage == "" {
+			continue
+		}
+		image := hi.ContainerImage
+		if image == "" {
+			image = hi.VMImage
+		}
+		replacement, deprecated := deprecation(bi.Notes)
+		deprecated = deprecated || hi.IsReverse && hi.ExpectNum == 0
+		goos, goarch := Platform(b)
+		bs = append(bs, Builder{
+			Name:        b,
+			GOOS:        goos,
+			GOARCH:      goarch,
+			HostType:    bi.HostType,
+			IsReverse:   hi.IsReverse,
+			ExpectNum:   hi.ExpectNum,
+			Image:       image,
+			Deprecated:  deprecated,
+			Replacement: replacement,
+		})
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		return bs[i].Name < bs[j].Name
+	})
+	return bs
+}
+
+// deprecation reports whether the notes of a builder say it's deprecated,
+// with a paragraph starting with "Deprecated:", and returns the builder
+// to use instead if the paragraph continues with "use <name>".
+func deprecation(notes string) (replacement string, deprecated bool) {
+	_, after, ok := strings.Cut(notes, "Deprecated:")
+	if !ok {
+		return "", false
+	}
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(after), "use "); ok {
+		replacement, _, _ = strings.Cut(rest, " ")
+		replacement = strings.TrimRight(replacement, ",.;")
+	}
+	return replacement, true
+}
+
+// miscCompilePorts returns the ports, such as "linux/arm", the
+// misc-compile builder name cross-compiles for, sorted. They're parsed from
+// its notes, which end with either the port, as in "Runs make.bash (or
+// compile-only go test) for linux-arm-arm5, but doesn't run any tests.", or
+// a regular expression matching ports, as in "... for ^(linux-mips|
+// linux-mipsle)$, ...", which older misc-compile builders covering several
+// ports had. If the notes have neither, the port is parsed from name.
+func miscCompilePorts(name, notes string) []string {
+	var platforms []string
+	if _, after, ok := strings.Cut(notes, " for "); ok {
+		platform, _, _ := strings.Cut(after, " ")
+		platform = strings.TrimRight(platform, ",.")
+		platform = strings.TrimPrefix(platform, "^")
+		platform = strings.TrimSuffix(platform, "$")
+		platform = strings.TrimPrefix(platform, "(")
+		platform = strings.TrimSuffix(platform, ")")
+		platforms = strings.Split(platform, "|")
+	}
+	var ports []string
+	for _, p := range platforms {
+		if goos, goarch := Platform(p); goos != "" && !slices.Contains(ports, goos+"/"+goarch) {
+			ports = append(ports, goos+"/"+goarch)
+		}
+	}
+	if len(ports) == 0 {
+		if goos, goarch := Platform(strings.TrimPrefix(name, "misc-compile-")); goos != "" {
+			ports = append(ports, goos+"/"+goarch)
+		}
+	}
+	sort.Strings(ports)
+	return ports
+}
+
+// knownGOOS are the values of GOOS builder names may contain.
+var knownGOOS = []string{
+	"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "js",
+	"linux", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows",
+}
+
+// Platform returns the GOOS and GOARCH of the builder type name, which
+// are the first known GOOS among its dash-separated elements and the
+// element following it, such as "linux" and "amd64" for both
+// "linux-amd64-bullseye" and "gotip-linux-amd64-race". They're empty if
+// name doesn't contain a known GOOS.
+func Platform(name string) (goos, goarch string) {
+	elems := strings.Split(name, "-")
+	for i, e := range elems[:len(elems)-1] {
+		if slices.Contains(knownGOOS, e) {
+			return e, elems[i+1]
+		}
+	}
+	return "", ""
+}
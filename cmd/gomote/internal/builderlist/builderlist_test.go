@@ -0,0 +1,598 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builderlist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/build/types"
+)
+
+// testListing is the coordinator's listing of builders the test server
+// serves, and testBuilders the builders of it instances can be created with.
+var (
+	testListing = types.BuilderListing{
+		Builders: map[string]types.BuilderInfo{
+			"linux-amd64":          {HostType: "host-linux-amd64-bullseye"},
+			"darwin-arm64-13":      {HostType: "host-darwin-arm64-13"},
+			"windows-amd64-2016":   {HostType: "host-windows-amd64-2016"},
+			"misc-compile-openbsd": {HostType: "host-linux-amd64-bullseye"},
+			"linux-amd64-nohost":   {HostType: "host-missing"},
+			"linux-amd64-static":   {HostType: "host-linux-amd64-static"},
+			"linux-386-buster":     {HostType: "host-linux-amd64-bullseye", Notes: "Deprecated: use linux-386."},
+		},
+		Hosts: map[string]types.HostInfo{
+			"host-linux-amd64-bullseye": {ContainerImage: "linux-x86-bullseye:latest"},
+			"host-darwin-arm64-13":      {IsReverse: true, ExpectNum: 3},
+			"host-windows-amd64-2016":   {VMImage: "windows-amd64-server-2016-v7"},
+			"host-linux-amd64-static":   {},
+		},
+		Retired: map[string]string{
+			"linux-amd64-stretch": "linux-amd64",
+			"linux-arm-scaleway":  "",
+		},
+	}
+	coordinatorOnly = []string{BackendCoordinator}
+	testBuilders    = []Builder{
+		{Name: "darwin-arm64-13", GOOS: "darwin", GOARCH: "arm64", Backends: coordinatorOnly, HostType: "host-darwin-arm64-13", IsReverse: true, ExpectNum: 3},
+		{Name: "linux-386-buster", GOOS: "linux", GOARCH: "386", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Image: "linux-x86-bullseye:latest", Deprecated: true, Replacement: "linux-386"},
+		{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Image: "linux-x86-bullseye:latest"},
+		{Name: "windows-amd64-2016", GOOS: "windows", GOARCH: "amd64", Backends: coordinatorOnly, HostType: "host-windows-amd64-2016", Image: "windows-amd64-server-2016-v7"},
+	}
+)
+
+// testServer returns a server responding to each request with handler,
+// and a count of the requests.
+func testServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &requests
+}
+
+func serveListing(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(testListing)
+}
+
+func TestList(t *testing.T) {
+	ts, _ := testServer(t, serveListing)
+	c := &Client{HTTPClient: ts.Client(), URL: ts.URL}
+	got, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !reflect.DeepEqual(got, testBuilders) {
+		t.Errorf("List =\n%+v\nwant:\n%+v", got, testBuilders)
+	}
+}
+
+func TestListErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		handler http.HandlerFunc
+		check   func(*FetchError) error
+	}{
+		{
+			desc: "non-200",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			},
+			check: func(fe *FetchError) error {
+				if fe.StatusCode != http.StatusServiceUnavailable {
+					return fmt.Errorf("StatusCode = %d; want %d", fe.StatusCode, http.StatusServiceUnavailable)
+				}
+				return nil
+			},
+		},
+		{
+			desc: "malformed JSON",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Builders": nope}`))
+			},
+			check: func(fe *FetchError) error {
+				var se *json.SyntaxError
+				if fe.StatusCode != 0 || !errors.As(fe, &se) {
+					return fmt.Errorf("got %v; want a JSON decoding error", fe)
+				}
+				return nil
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts, _ := testServer(t, tc.handler)
+			c := &Client{HTTPClient: ts.Client(), URL: ts.URL}
+			got, err := c.List(context.Background())
+			var fe *FetchError
+			if !errors.As(err, &fe) {
+				t.Fatalf("List = %+v, %v; want a *FetchError", got, err)
+			}
+			if fe.URL != ts.URL {
+				t.Errorf("URL = %q; want %q", fe.URL, ts.URL)
+			}
+			if err := tc.check(fe); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestListCache(t *testing.T) {
+	var fail, failOnce atomic.Bool
+	ts, requests := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			if failOnce.Load() {
+				fail.Store(false)
+			}
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		serveListing(w, r)
+	})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var logs []string
+	c := &Client{
+		HTTPClient: ts.Client(),
+		URL:        ts.URL,
+		CacheFile:  filepath.Join(t.TempDir(), "gomote", "builders.json"),
+		Logf: func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	}
+	for _, tc := range []struct {
+		desc         string
+		age          time.Duration // since the first request
+		refresh      bool
+		fail         bool
+		failOnce     bool
+		wantRequests int32
+		wantErr      bool
+		wantLog      string
+	}{
+		{desc: "no cache, fetch fails", fail: true, wantRequests: 2, wantErr: true},
+		{desc: "no cache", wantRequests: 1},
+		{desc: "cache hit", age: DefaultTTL / 2},
+		{desc: "refresh", age: DefaultTTL / 2, refresh: true, wantRequests: 1},
+		{desc: "stale cache, fetch fails", age: 3 * DefaultTTL, fail: true, wantRequests: 2, wantLog: "cached 2 hours ago: fetching"},
+		{desc: "stale cache", age: 3 * DefaultTTL, wantRequests: 1},
+		{desc: "stale cache, fetch fails once", age: 6 * DefaultTTL, fail: true, failOnce: true, wantRequests: 2},
+	} {
+		requests.Store(0)
+		logs = nil
+		fail.Store(tc.fail)
+		failOnce.Store(tc.failOnce)
+		c.now = func() time.Time { return now.Add(tc.age) }
+		list := c.List
+		if tc.refresh {
+			list = c.Refresh
+		}
+		got, err := list(context.Background())
+		if n := requests.Load(); n != tc.wantRequests {
+			t.Errorf("%s: made %d requests; want %d", tc.desc, n, tc.wantRequests)
+		}
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: List = %+v; want error", tc.desc, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, testBuilders) {
+			t.Errorf("%s: List = %+v, %v; want %+v", tc.desc, got, err, testBuilders)
+		}
+		if tc.wantLog == "" && len(logs) != 0 {
+			t.Errorf("%s: logged %q; want nothing", tc.desc, logs)
+		}
+		if tc.wantLog != "" && (len(logs) != 1 || !strings.Contains(logs[0], tc.wantLog)) {
+			t.Errorf("%s: logged %q; want a message about %q", tc.desc, logs, tc.wantLog)
+		}
+	}
+
+	// A cache of another version is replaced.
+	if err := os.WriteFile(c.CacheFile, []byte(`{"fetched": "2024-05-01T12:00:00Z", "builders": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	requests.Store(0)
+	if got, err := c.List(context.Background()); err != nil || requests.Load() != 1 || !reflect.DeepEqual(got, testBuilders) {
+		t.Errorf("List with an older cache = %+v, %v after %d requests; want %+v after 1", got, err, requests.Load(), testBuilders)
+	}
+
+	// A corrupt cache is replaced.
+	if err := os.WriteFile(c.CacheFile, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	requests.Store(0)
+	if got, err := c.List(context.Background()); err != nil || requests.Load() != 1 || !reflect.DeepEqual(got, testBuilders) {
+		t.Errorf("List with a corrupt cache = %+v, %v after %d requests; want %+v after 1", got, err, requests.Load(), testBuilders)
+	}
+}
+
+func TestListRetry(t *testing.T) {
+	for _, tc := range []struct {
+		status       int
+		wantRequests int32
+	}{
+		{http.StatusServiceUnavailable, 2},
+		{http.StatusNotFound, 1},
+	} {
+		ts, requests := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(tc.status), tc.status)
+		})
+		c := &Client{HTTPClient: ts.Client(), URL: ts.URL}
+		if _, err := c.List(context.Background()); err == nil {
+			t.Errorf("List with status %d = nil error; want error", tc.status)
+		}
+		if n := requests.Load(); n != tc.wantRequests {
+			t.Errorf("List with status %d made %d requests; want %d", tc.status, n, tc.wantRequests)
+		}
+	}
+}
+
+func TestListSnapshot(t *testing.T) {
+	ts, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	})
+	var logs []string
+	c := &Client{
+		HTTPClient:  ts.Client(),
+		URL:         ts.URL,
+		UseSnapshot: true,
+		Logf: func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+		ListSwarming: func(context.Context) ([]string, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+	got, err := c.List(context.Background())
+	if err != nil || len(got) == 0 {
+		t.Fatalf("List = %+v, %v; want the snapshot", got, err)
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], "built-in") {
+		t.Errorf("List logged %q; want a message about the built-in list", logs)
+	}
+	for i, b := range got {
+		if i > 0 && got[i-1].Name >= b.Name {
+			t.Errorf("snapshot isn't sorted by name: %q before %q", got[i-1].Name, b.Name)
+		}
+		if goos, goarch := Platform(b.Name); b.GOOS != goos || b.GOARCH != goarch {
+			t.Errorf("snapshot has %q for %s/%s; want %s/%s", b.Name, b.GOOS, b.GOARCH, goos, goarch)
+		}
+		if b.MiscCompile() {
+			t.Errorf("snapshot has misc-compile builder type %q", b.Name)
+		}
+		if swarming := strings.HasPrefix(b.Name, "gotip-"); b.AvailableVia(BackendSwarming) != swarming || b.AvailableVia(BackendCoordinator) == swarming {
+			t.Errorf("snapshot has %q available via %q", b.Name, b.Backends)
+		}
+	}
+}
+
+func TestAgo(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "less than a minute ago"},
+		{time.Minute, "1 minute ago"},
+		{59 * time.Minute, "59 minutes ago"},
+		{time.Hour + 30*time.Minute, "1 hour ago"},
+		{50 * time.Hour, "50 hours ago"},
+	} {
+		if got := ago(tc.d); got != tc.want {
+			t.Errorf("ago(%v) = %q; want %q", tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestRetirement(t *testing.T) {
+	ts, requests := testServer(t, serveListing)
+	c := &Client{
+		HTTPClient: ts.Client(),
+		URL:        ts.URL,
+		CacheFile:  filepath.Join(t.TempDir(), "builders.json"),
+	}
+	for _, tc := range []struct {
+		name string
+		want string // the retirement's String, or "" if nil
+	}{
+		{"linux-amd64-stretch", "linux-amd64-stretch was retired; use linux-amd64"},
+		{"linux-arm-scaleway", "linux-arm-scaleway was retired"},
+		{"linux-386-buster", "linux-386-buster is deprecated; use linux-386"},
+		{"linux-amd64", ""},
+		{"plan9-386", ""},
+	} {
+		r, err := c.Retirement(context.Background(), tc.name)
+		if err != nil {
+			t.Errorf("Retirement(%q) = %v", tc.name, err)
+			continue
+		}
+		var got string
+		if r != nil {
+			got = r.String()
+		}
+		if got != tc.want {
+			t.Errorf("Retirement(%q) = %q; want %q", tc.name, got, tc.want)
+		}
+	}
+	// The retired builders are cached with the others.
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests; want 1", n)
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	for _, tc := range []struct {
+		notes           string
+		wantReplacement string
+		wantDeprecated  bool
+	}{
+		{"", "", false},
+		{"Debian Buster", "", false},
+		{"Deprecated: use linux-386-bookworm.", "linux-386-bookworm", true},
+		{"Debian Buster. Deprecated: use linux-amd64-bookworm, which is faster.", "linux-amd64-bookworm", true},
+		{"Deprecated: its machines are being decommissioned.", "", true},
+	} {
+		replacement, deprecated := deprecation(tc.notes)
+		if replacement != tc.wantReplacement || deprecated != tc.wantDeprecated {
+			t.Errorf("deprecation(%q) = %q, %t; want %q, %t", tc.notes, replacement, deprecated, tc.wantReplacement, tc.wantDeprecated)
+		}
+	}
+}
+
+func TestListSwarming(t *testing.T) {
+	ts, _ := testServer(t, serveListing)
+	c := &Client{
+		HTTPClient: ts.Client(),
+		URL:        ts.URL,
+		ListSwarming: func(context.Context) ([]string, error) {
+			return []string{"gotip-windows-arm64", "linux-amd64", "other"}, nil
+		},
+	}
+	got, err := c.List(context.Background())
+	want := []Builder{
+		testBuilders[0],
+		{Name: "gotip-windows-arm64", GOOS: "windows", GOARCH: "arm64", Backends: []string{BackendSwarming}},
+		testBuilders[1],
+		{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{BackendCoordinator, BackendSwarming}, HostType: "host-linux-amd64-bullseye", Image: "linux-x86-bullseye:latest"},
+		{Name: "other", Backends: []string{BackendSwarming}},
+		testBuilders[3],
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("List =\n%+v, %v\nwant:\n%+v", got, err, want)
+	}
+}
+
+func TestListPartial(t *testing.T) {
+	ok, _ := testServer(t, serveListing)
+	failing, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	swarming := func(context.Context) ([]string, error) { return []string{"gotip-linux-amd64"}, nil }
+	swarmingFails := func(context.Context) ([]string, error) { return nil, errors.New("unavailable") }
+	for _, tc := range []struct {
+		desc         string
+		url          string
+		listSwarming func(context.Context) ([]string, error)
+		want         []Builder
+		wantLog      string
+	}{
+		{
+			desc:         "coordinator fails",
+			url:          failing.URL,
+			listSwarming: swarming,
+			want:         []Builder{{Name: "gotip-linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{BackendSwarming}}},
+			wantLog:      BackendCoordinator,
+		},
+		{
+			desc:         "swarming fails",
+			url:          ok.URL,
+			listSwarming: swarmingFails,
+			want:         testBuilders,
+			wantLog:      "unavailable",
+		},
+		{
+			desc:         "both fail",
+			url:          failing.URL,
+			listSwarming: swarmingFails,
+		},
+	} {
+		var logs []string
+		c := &Client{
+			HTTPClient:   ok.Client(),
+			URL:          tc.url,
+			ListSwarming: tc.listSwarming,
+			CacheFile:    filepath.Join(t.TempDir(), "builders.json"),
+			Logf: func(format string, args ...any) {
+				logs = append(logs, fmt.Sprintf(format, args...))
+			},
+		}
+		got, err := c.List(context.Background())
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("%s: List = %+v; want an error", tc.desc, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: List = %+v, %v; want %+v", tc.desc, got, err, tc.want)
+		}
+		if len(logs) != 1 || !strings.Contains(logs[0], tc.wantLog) {
+			t.Errorf("%s: List logged %q; want a message containing %q", tc.desc, logs, tc.wantLog)
+		}
+		if _, err := os.Stat(c.CacheFile); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: partial listing was cached: %v", tc.desc, err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	coordinator := []Builder{
+		{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Image: "linux-x86-bullseye:latest"},
+		{Name: "darwin-arm64-13", GOOS: "darwin", GOARCH: "arm64", IsReverse: true},
+	}
+	for _, tc := range []struct {
+		desc        string
+		coordinator []Builder
+		swarming    []string
+		want        []Builder
+	}{
+		{
+			desc:        "coordinator only",
+			coordinator: coordinator,
+			want: []Builder{
+				{Name: "darwin-arm64-13", GOOS: "darwin", GOARCH: "arm64", Backends: coordinatorOnly, IsReverse: true},
+				{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: coordinatorOnly, Image: "linux-x86-bullseye:latest"},
+			},
+		},
+		{
+			desc:     "swarming only",
+			swarming: []string{"gotip-linux-amd64", "gotip-darwin-arm64"},
+			want: []Builder{
+				{Name: "gotip-darwin-arm64", GOOS: "darwin", GOARCH: "arm64", Backends: []string{BackendSwarming}},
+				{Name: "gotip-linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{BackendSwarming}},
+			},
+		},
+		{
+			desc:        "both",
+			coordinator: coordinator,
+			swarming:    []string{"linux-amd64", "gotip-linux-amd64", "linux-amd64"},
+			want: []Builder{
+				{Name: "darwin-arm64-13", GOOS: "darwin", GOARCH: "arm64", Backends: coordinatorOnly, IsReverse: true},
+				{Name: "gotip-linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{BackendSwarming}},
+				{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: []string{BackendCoordinator, BackendSwarming}, Image: "linux-x86-bullseye:latest"},
+			},
+		},
+		{
+			desc: "neither",
+			want: []Builder{},
+		},
+	} {
+		if got := merge(tc.coordinator, tc.swarming); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: merge =\n%+v\nwant:\n%+v", tc.desc, got, tc.want)
+		}
+	}
+	if coordinator[0].Backends != nil {
+		t.Errorf("merge modified its argument: %+v", coordinator[0])
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	want := map[string]types.HostCapacity{
+		"host-darwin-arm64-13": {HostType: "host-darwin-arm64-13", Expected: 3, Connected: 2, Waiting: 4},
+	}
+	ts, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(want)
+	})
+	c := &Client{HTTPClient: ts.Client(), CapacityURL: ts.URL}
+	got, err := c.Capacity(context.Background())
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Capacity = %+v, %v; want %+v", got, err, want)
+	}
+
+	ts, _ = testServer(t, http.NotFound)
+	c = &Client{HTTPClient: ts.Client(), CapacityURL: ts.URL}
+	got, err = c.Capacity(context.Background())
+	var fe *FetchError
+	if !errors.As(err, &fe) || fe.StatusCode != http.StatusNotFound {
+		t.Errorf("Capacity from an older coordinator = %+v, %v; want a 404 *FetchError", got, err)
+	}
+}
+
+func TestListAll(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+
+	c := &Client{HTTPClient: ts.Client(), URL: ts.URL + "/builders.json", All: true}
+	got, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []Builder{
+		{Name: "aix-ppc64", GOOS: "aix", GOARCH: "ppc64", Backends: coordinatorOnly, HostType: "host-aix-ppc64-osuosl", IsReverse: true, ExpectNum: 1},
+		{Name: "linux-amd64", GOOS: "linux", GOARCH: "amd64", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Image: "linux-x86-bullseye:latest"},
+		{Name: "misc-compile-linux-arm-arm5", GOOS: "linux", GOARCH: "arm", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Ports: []string{"linux/arm"}},
+		{Name: "misc-compile-openbsd-ppc64-go1.22", GOOS: "openbsd", GOARCH: "ppc64", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Ports: []string{"openbsd/ppc64"}},
+		{Name: "misc-compile-plan9-386", GOOS: "plan9", GOARCH: "386", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Ports: []string{"plan9/386"}},
+		{Name: "misc-compile-windows-arm64", GOOS: "windows", GOARCH: "arm64", Backends: coordinatorOnly, HostType: "host-linux-amd64-bullseye", Ports: []string{"windows/arm64"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List with All =\n%+v\nwant:\n%+v", got, want)
+	}
+
+	c.All = false
+	got, err = c.List(context.Background())
+	if err != nil || !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("List without All = %+v, %v; want %+v", got, err, want[:2])
+	}
+}
+
+func TestMiscCompilePorts(t *testing.T) {
+	for _, tc := range []struct {
+		name, notes string
+		want        []string
+	}{
+		{
+			name:  "misc-compile-linux-arm-arm5",
+			notes: "Runs make.bash (or compile-only go test) for linux-arm-arm5, but doesn't run any tests.",
+			want:  []string{"linux/arm"},
+		},
+		{
+			name:  "misc-compile-openbsd-riscv64-go1.23",
+			notes: "Runs make.bash (or compile-only go test) for openbsd-riscv64-go1.23, but doesn't run any tests. Applies to Go 1.23 and newer.",
+			want:  []string{"openbsd/riscv64"},
+		},
+		{
+			name:  "misc-compile-mips",
+			notes: "Runs buildall.bash to cross-compile & vet std+cmd packages for ^(linux-mipsle|linux-mips|linux-mips64|linux-mips64le)$, but doesn't run any tests.",
+			want:  []string{"linux/mips", "linux/mips64", "linux/mips64le", "linux/mipsle"},
+		},
+		{
+			// Without notes, the port is in the name.
+			name: "misc-compile-freebsd-riscv64",
+			want: []string{"freebsd/riscv64"},
+		},
+		{
+			name:  "misc-compile-other",
+			notes: "Runs make.bash for other ports.",
+		},
+	} {
+		if got := miscCompilePorts(tc.name, tc.notes); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("miscCompilePorts(%q, %q) = %q; want %q", tc.name, tc.notes, got, tc.want)
+		}
+	}
+}
+
+func TestPlatform(t *testing.T) {
+	for _, tc := range []struct {
+		name, goos, goarch string
+	}{
+		{"linux-amd64", "linux", "amd64"},
+		{"linux-amd64-bullseye", "linux", "amd64"},
+		{"gotip-linux-amd64-race", "linux", "amd64"},
+		{"go1.22-windows-arm64", "windows", "arm64"},
+		{"x_tools-gotip-js-wasm", "js", "wasm"},
+		{"linux", "", ""},
+		{"misc-compile-other", "", ""},
+	} {
+		goos, goarch := Platform(tc.name)
+		if goos != tc.goos || goarch != tc.goarch {
+			t.Errorf("Platform(%q) = %q, %q; want %q, %q", tc.name, goos, goarch, tc.goos, tc.goarch)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package output styles the terminal output of gomote.
+//
+// Styling is only applied when it is enabled, so that the output stays
+// plain when it is redirected to a file or when the user opted out of
+// colors with the NO_COLOR environment variable (see https://no-color.org).
+package output
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// Mode selects when output is styled.
+type Mode int
+
+const (
+	// Auto styles output written to a terminal, unless NO_COLOR is set.
+	Auto Mode = iota
+	// Always styles output.
+	Always
+	// Never styles output.
+	Never
+)
+
+// ParseMode parses a mode as accepted by the -color flag: auto, always or never.
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "auto":
+		return Auto, nil
+	case "always":
+		return Always, nil
+	case "never":
+		return Never, nil
+	}
+	return Auto, fmt.Errorf("invalid color mode %q; want one of auto, always, never", s)
+}
+
+// Styler styles the output written to a file. The zero value never styles output.
+type Styler struct {
+	enabled bool
+}
+
+// New returns a Styler for output written to f according to mode.
+func New(mode Mode, f *os.File) Styler {
+	switch mode {
+	case Always:
+		return Styler{enabled: true}
+	case Never:
+		return Styler{}
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return Styler{}
+	}
+	return Styler{enabled: term.IsTerminal(int(f.Fd()))}
+}
+
+// Enabled reports whether s styles output.
+func (s Styler) Enabled() bool {
+	return s.enabled
+}
+
+// The escape sequences only reset the attributes they set, so that styles
+// may be nested, such as an instance name within a status line.
+const (
+	faint      = "\x1b[2m"
+	boldRed    = "\x1b[1;31m"
+	green      = "\x1b[32m"
+	yellow     = "\x1b[33m"
+	resetFaint = "\x1b[22m"
+	resetBold  = "\x1b[22m"
+	resetColor = "\x1b[39m"
+)
+
+// instanceColors are the foreground colors of instance names. Red is
+// reserved for failures.
+var instanceColors = []string{
+	"\x1b[32m", "\x1b[33m", "\x1b[34m", "\x1b[35m", "\x1b[36m",
+	"\x1b[92m", "\x1b[93m", "\x1b[94m", "\x1b[95m", "\x1b[96m",
+}
+
+// Instance returns the quoted name of an instance. When styling is enabled,
+// it is colored deterministically by name, so that the output of each
+// instance of a group is easy to tell apart.
+func (s Styler) Instance(name string) string {
+	q := strconv.Quote(name)
+	if !s.enabled {
+		return q
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return instanceColors[h.Sum32()%uint32(len(instanceColors))] + q + resetColor
+}
+
+// Status styles a progress message.
+func (s Styler) Status(msg string) string {
+	return s.wrap(faint, msg, resetFaint)
+}
+
+// Success styles a message reporting that an operation succeeded.
+func (s Styler) Success(msg string) string {
+	return s.wrap(green, msg, resetColor)
+}
+
+// Warning styles a message which deserves the user's attention.
+func (s Styler) Warning(msg string) string {
+	return s.wrap(yellow, msg, resetColor)
+}
+
+// Failure styles a message reporting that an operation failed.
+func (s Styler) Failure(msg string) string {
+	return s.wrap(boldRed, msg, resetBold+resetColor)
+}
+
+func (s Styler) wrap(start, msg, end string) string {
+	if !s.enabled {
+		return msg
+	}
+	return start + msg + end
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package output
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	for s, want := range map[string]Mode{"auto": Auto, "always": Always, "never": Never} {
+		if got, err := ParseMode(s); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %v, %v; want %v, nil", s, got, err, want)
+		}
+	}
+	if _, err := ParseMode("sometimes"); err == nil {
+		t.Errorf("ParseMode(%q) = _, nil; want error", "sometimes")
+	}
+}
+
+func TestNew(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	t.Setenv("NO_COLOR", "")
+	os.Unsetenv("NO_COLOR")
+	if New(Auto, f).Enabled() {
+		t.Errorf("New(Auto, file).Enabled() = true; want false when not writing to a terminal")
+	}
+	if !New(Always, f).Enabled() {
+		t.Errorf("New(Always, file).Enabled() = false; want true")
+	}
+	if New(Never, f).Enabled() {
+		t.Errorf("New(Never, file).Enabled() = true; want false")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if New(Auto, os.Stderr).Enabled() {
+		t.Errorf("New(Auto, os.Stderr).Enabled() = true; want false with NO_COLOR set")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var s Styler
+	if got, want := s.Instance("user-foo-linux-amd64-0"), `"user-foo-linux-amd64-0"`; got != want {
+		t.Errorf("Instance() = %q; want %q", got, want)
+	}
+	for _, style := range []func(string) string{s.Status, s.Success, s.Warning, s.Failure} {
+		if got := style("msg"); got != "msg" {
+			t.Errorf("style(%q) = %q; want it unchanged", "msg", got)
+		}
+	}
+}
+
+func TestInstanceColor(t *testing.T) {
+	s := Styler{enabled: true}
+	a, b := s.Instance("user-foo-linux-amd64-0"), s.Instance("user-foo-linux-amd64-0")
+	if a != b {
+		t.Errorf("Instance() = %q, then %q; want the same color for the same instance", a, b)
+	}
+	if !strings.Contains(a, `"user-foo-linux-amd64-0"`) || !strings.HasPrefix(a, "\x1b[") {
+		t.Errorf("Instance() = %q; want the colored, quoted name", a)
+	}
+	if strings.HasPrefix(a, boldRed) {
+		t.Errorf("Instance() = %q; red is reserved for failures", a)
+	}
+}
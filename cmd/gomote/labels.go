@@ -0,0 +1,54 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelFlag implements flag.Value for a repeated -label flag. Each value
+// is of the form key=value or, if the flag selects instances, key.
+type labelFlag struct {
+	labels   []string
+	selector bool
+}
+
+func (f *labelFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(f.labels, ",")
+}
+
+func (f *labelFlag) Set(v string) error {
+	k, _, ok := strings.Cut(v, "=")
+	switch {
+	case k == "" && f.selector:
+		return fmt.Errorf("invalid label selector %q; want key=value or key", v)
+	case k == "" || (!ok && !f.selector):
+		return fmt.Errorf("invalid label %q; want key=value", v)
+	}
+	f.labels = append(f.labels, v)
+	return nil
+}
+
+// formatLabels returns the labels as a comma-separated list of key=value
+// pairs sorted by key, or "-" if there are none.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + labels[k]
+	}
+	return strings.Join(keys, ",")
+}
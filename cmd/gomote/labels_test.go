@@ -0,0 +1,73 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build/buildlet"
+)
+
+func TestLabelFlag(t *testing.T) {
+	for _, tc := range []struct {
+		selector bool
+		value    string
+		wantErr  bool
+	}{
+		{false, "cl=12345", false},
+		{false, "bot=", false},
+		{false, "cl", true},
+		{false, "=x", true},
+		{true, "cl=12345", false},
+		{true, "cl", false},
+		{true, "=x", true},
+		{true, "", true},
+	} {
+		f := labelFlag{selector: tc.selector}
+		err := f.Set(tc.value)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("labelFlag{selector: %t}.Set(%q) = %v; want error %t", tc.selector, tc.value, err, tc.wantErr)
+		}
+		if err == nil && !slices.Equal(f.labels, []string{tc.value}) {
+			t.Errorf("labelFlag{selector: %t}.Set(%q) labels = %q; want %q", tc.selector, tc.value, f.labels, tc.value)
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	if got, want := formatLabels(map[string]string{"pipeline": "nightly", "cl": "12345"}), "cl=12345,pipeline=nightly"; got != want {
+		t.Errorf("formatLabels = %q; want %q", got, want)
+	}
+	if got, want := formatLabels(nil), "-"; got != want {
+		t.Errorf("formatLabels(nil) = %q; want %q", got, want)
+	}
+}
+
+func TestWriteInstancesTableLabels(t *testing.T) {
+	now := time.Now()
+	instances := []buildlet.RemoteBuildlet{
+		{Name: "user-linux-amd64-0", BuilderType: "gotip-linux-amd64", Expires: now.Add(time.Hour), Labels: map[string]string{"cl": "12345"}},
+		{Name: "user-linux-amd64-1", BuilderType: "gotip-linux-amd64", Expires: now.Add(time.Hour)},
+	}
+	var b strings.Builder
+	if err := writeInstancesTable(&b, instances, nil, now, false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "LABELS") || !strings.HasSuffix(lines[1], "cl=12345") || !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("writeInstancesTable wrote:\n%s\nwant a LABELS column", b.String())
+	}
+
+	b.Reset()
+	if err := writeInstancesTable(&b, instances[1:], nil, now, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "LABELS") {
+		t.Errorf("writeInstancesTable wrote:\n%s\nwant no LABELS column without labels", b.String())
+	}
+}
@@ -5,54 +5,325 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path"
+	"slices"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"golang.org/x/build/buildlet"
 	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/types"
+	"golang.org/x/term"
 )
 
 func list(args []string) error {
-	fs := flag.NewFlagSet("list", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "list usage: gomote list")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	fs.Parse(args)
+	var flags listFlags
+	fs := listFlagSet(&flags)
+	parseFlags(fs, args)
 	if fs.NArg() != 0 {
 		fs.Usage()
 	}
+	for _, pattern := range []string{flags.typePattern, flags.namePattern} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return usageErrorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if flags.watch && flags.jsonOut {
+		return usageErrorf("-watch and -json cannot be used together")
+	}
+	if flags.interval <= 0 {
+		return usageErrorf("invalid -interval %v", flags.interval)
+	}
+	if err := sortInstances(nil, flags.sortBy); err != nil {
+		return err
+	}
+	// Groups are only loaded once, even with -watch, since loading them
+	// pings every member instance, which renews its expiration.
 	groups, err := loadAllGroups()
 	if err != nil {
 		return fmt.Errorf("loading groups: %w", err)
 	}
-	ctx := context.Background()
-	client := gomoteServerClient(ctx)
-	resp, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+	var g *groupData
+	if flags.groupFilter != "" {
+		for _, gd := range groups {
+			if gd.Name == flags.groupFilter {
+				g = gd
+				break
+			}
+		}
+		if g == nil {
+			return usageErrorf("group %q does not exist", flags.groupFilter)
+		}
+	}
+	query := func(ctx context.Context) ([]buildlet.RemoteBuildlet, error) {
+		return queryInstances(ctx, gomoteServerClient(ctx), g, flags.typePattern, flags.namePattern, flags.labels.labels, flags.sortBy)
+	}
+	filtered := g != nil || flags.typePattern != "" || flags.namePattern != "" || len(flags.labels.labels) > 0
+
+	if flags.watch {
+		return watchInstances(query, groups, flags.interval)
+	}
+	instances, err := query(context.Background())
 	if err != nil {
-		return fmt.Errorf("unable to list instance: %w", err)
+		return err
 	}
-	for _, inst := range resp.GetInstances() {
-		var groupList strings.Builder
-		for _, g := range groups {
-			if !g.has(inst.GetGomoteId()) {
-				continue
-			}
-			if groupList.Len() == 0 {
-				groupList.WriteString(" (")
-			} else {
-				groupList.WriteString(", ")
+	if len(instances) == 0 && filtered {
+		fmt.Fprintln(os.Stderr, "no matching instances")
+	}
+	if flags.jsonOut {
+		return writeInstancesJSON(os.Stdout, instances, groups)
+	}
+	return writeInstancesTable(os.Stdout, instances, groups, time.Now(), false)
+}
+
+// listFlags are the flags of the list command.
+type listFlags struct {
+	jsonOut     bool
+	sortBy      string
+	groupFilter string
+	typePattern string
+	namePattern string
+	labels      labelFlag
+	watch       bool
+	interval    time.Duration
+}
+
+func listFlagSet(flags *listFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "list usage: gomote list [list-opts]")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.jsonOut, "json", false, "print the instances as a JSON array")
+	fs.StringVar(&flags.sortBy, "sort", "name", "sort instances by one of: expiry, name, type")
+	fs.StringVar(&flags.groupFilter, "group", "", "only list instances which are members of the named group")
+	fs.StringVar(&flags.typePattern, "type", "", "only list instances whose builder type matches the glob `pattern`")
+	fs.StringVar(&flags.namePattern, "match", "", "only list instances whose name matches the glob `pattern`")
+	flags.labels.selector = true
+	fs.Var(&flags.labels, "label", "only list instances with the label `key=value`, or with the label key if there's no value; may be repeated")
+	fs.BoolVar(&flags.watch, "watch", false, "periodically refresh the list of instances until interrupted")
+	fs.DurationVar(&flags.interval, "interval", 5*time.Second, "how often to refresh the list of instances with -watch")
+	return fs
+}
+
+// queryInstances lists the caller's instances which are members of g, if
+// not nil, whose builder type and name match the patterns, and which have
+// the labels selected, sorted according to sortBy.
+func queryInstances(ctx context.Context, client protos.GomoteServiceClient, g *groupData, typePattern, namePattern string, labels []string, sortBy string) ([]buildlet.RemoteBuildlet, error) {
+	cc := &buildlet.GRPCCoordinatorClient{Client: client}
+	// Let the server do what it can of the filtering.
+	rbs, err := cc.ListBuildlets(ctx, buildlet.InstanceFilter{
+		BuilderTypePrefix: literalPrefix(typePattern),
+		Labels:            labels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list instance: %w", err)
+	}
+	var instances []buildlet.RemoteBuildlet
+	for _, rb := range rbs {
+		if g == nil || g.has(rb.Name) {
+			instances = append(instances, rb)
+		}
+	}
+	instances = filterInstances(instances, typePattern, namePattern)
+	return instances, sortInstances(instances, sortBy)
+}
+
+// literalPrefix returns the prefix of the path.Match pattern which
+// contains no special characters.
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// expiringSoon is the remaining lifetime below which "gomote list -watch"
+// highlights an instance.
+const expiringSoon = 10 * time.Minute
+
+// watchInstances periodically queries and prints the instances until interrupted.
+// On a terminal the table is redrawn in place; otherwise a timestamped snapshot is
+// printed for each interval.
+func watchInstances(query func(context.Context) ([]buildlet.RemoteBuildlet, error), groups []*groupData, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	tty := term.IsTerminal(int(os.Stdout.Fd()))
+	if tty {
+		// Hide the cursor while redrawing, and make sure it is restored.
+		fmt.Print("\x1b[?25l")
+		defer fmt.Print("\x1b[0m\x1b[?25h")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		instances, err := query(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		now := time.Now()
+		var buf bytes.Buffer
+		if tty {
+			// Move to the top left and clear the screen.
+			buf.WriteString("\x1b[H\x1b[2J")
+		}
+		fmt.Fprintf(&buf, "# %s (every %v)\n", now.Format(time.RFC3339), interval)
+		if err != nil {
+			fmt.Fprintf(&buf, "# %v\n", err)
+		} else if err := writeInstancesTable(&buf, instances, groups, now, tty); err != nil {
+			return err
+		}
+		if !tty {
+			buf.WriteString("\n")
+		}
+		os.Stdout.Write(buf.Bytes())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeInstancesTable writes a table of the instances and the groups they are members of.
+// The instances' labels are included if any of them has labels.
+// If highlight is set, instances which are about to expire are highlighted using
+// terminal escape sequences.
+func writeInstancesTable(w io.Writer, instances []buildlet.RemoteBuildlet, groups []*groupData, now time.Time, highlight bool) error {
+	labeled := slices.ContainsFunc(instances, func(inst buildlet.RemoteBuildlet) bool {
+		return len(inst.Labels) > 0
+	})
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	if labeled {
+		fmt.Fprintln(tw, "NAME\tGROUP\tBUILDER\tHOST\tEXPIRES\tLABELS")
+	} else {
+		fmt.Fprintln(tw, "NAME\tGROUP\tBUILDER\tHOST\tEXPIRES")
+	}
+	for _, inst := range instances {
+		groupList := "-"
+		if names := groupNames(groups, inst.Name); len(names) > 0 {
+			groupList = strings.Join(names, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\tin %v", inst.Name, groupList, inst.BuilderType, inst.HostType, inst.Expires.Sub(now).Round(time.Second))
+		if labeled {
+			fmt.Fprintf(tw, "\t%s", formatLabels(inst.Labels))
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !highlight {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	// Escape sequences would throw off the column widths computed by the tabwriter,
+	// so highlight whole lines after the table has been formatted. The first line
+	// is the header.
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, line := range lines {
+		if i > 0 && i <= len(instances) && instances[i-1].Expires.Sub(now) < expiringSoon {
+			line = "\x1b[1;31m" + strings.TrimSuffix(line, "\n") + "\x1b[0m\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterInstances returns the instances whose builder type matches typePattern
+// and whose name matches namePattern. An empty pattern matches everything.
+// The patterns must be valid path.Match patterns.
+func filterInstances(instances []buildlet.RemoteBuildlet, typePattern, namePattern string) []buildlet.RemoteBuildlet {
+	matches := func(pattern, s string) bool {
+		if pattern == "" {
+			return true
+		}
+		ok, _ := path.Match(pattern, s)
+		return ok
+	}
+	var filtered []buildlet.RemoteBuildlet
+	for _, inst := range instances {
+		if matches(typePattern, inst.BuilderType) && matches(namePattern, inst.Name) {
+			filtered = append(filtered, inst)
+		}
+	}
+	return filtered
+}
+
+// groupNames returns the sorted names of the groups which contain the instance.
+func groupNames(groups []*groupData, inst string) []string {
+	var names []string
+	for _, g := range groups {
+		if g.has(inst) {
+			names = append(names, g.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortInstances sorts instances in place according to the -sort flag value.
+func sortInstances(instances []buildlet.RemoteBuildlet, sortBy string) error {
+	var less func(a, b buildlet.RemoteBuildlet) bool
+	switch sortBy {
+	case "name":
+		less = func(a, b buildlet.RemoteBuildlet) bool {
+			return a.Name < b.Name
+		}
+	case "expiry":
+		less = func(a, b buildlet.RemoteBuildlet) bool {
+			if !a.Expires.Equal(b.Expires) {
+				return a.Expires.Before(b.Expires)
 			}
-			groupList.WriteString(g.Name)
+			return a.Name < b.Name
 		}
-		if groupList.Len() != 0 {
-			groupList.WriteString(")")
+	case "type":
+		less = func(a, b buildlet.RemoteBuildlet) bool {
+			if a.BuilderType != b.BuilderType {
+				return a.BuilderType < b.BuilderType
+			}
+			return a.Name < b.Name
 		}
-		fmt.Printf("%s%s\t%s\t%s\texpires in %v\n", inst.GetGomoteId(), groupList.String(), inst.GetBuilderType(), inst.GetHostType(), time.Unix(inst.GetExpires(), 0).Sub(time.Now()))
+	default:
+		return usageErrorf("unknown sort order %q; want one of expiry, name, type", sortBy)
 	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		return less(instances[i], instances[j])
+	})
 	return nil
 }
+
+// writeInstancesJSON writes instances as the JSON array printed by
+// "gomote list -json", whose elements are types.GomoteInstanceInfo.
+func writeInstancesJSON(w io.Writer, instances []buildlet.RemoteBuildlet, groups []*groupData) error {
+	out := make([]types.GomoteInstanceInfo, 0, len(instances))
+	for _, inst := range instances {
+		ii := inst.InstanceInfo()
+		ii.Groups = groupNames(groups, inst.Name)
+		out = append(out, ii)
+	}
+	return writeJSON(w, out)
+}
+
+// remainingLifetime returns the lifetime left at now of an instance which
+// expires at expires, such as "29m41s".
+func remainingLifetime(expires, now time.Time) string {
+	remaining := expires.Sub(now).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining.String()
+}
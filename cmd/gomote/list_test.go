@@ -0,0 +1,225 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+)
+
+// fakeListClient is a GomoteServiceClient which lists instances,
+// applying the request's filters the way the gomote server does.
+type fakeListClient struct {
+	protos.GomoteServiceClient
+	instances []*protos.Instance
+	reqs      []*protos.ListInstancesRequest
+}
+
+func (c *fakeListClient) ListInstances(_ context.Context, req *protos.ListInstancesRequest, _ ...grpc.CallOption) (*protos.ListInstancesResponse, error) {
+	c.reqs = append(c.reqs, req)
+	now := time.Now()
+	var insts []*protos.Instance
+	for _, inst := range c.instances {
+		if !strings.HasPrefix(inst.GetBuilderType(), req.GetBuilderTypePrefix()) {
+			continue
+		}
+		if minAge := time.Duration(req.GetMinAgeSeconds()) * time.Second; minAge > 0 {
+			if inst.GetCreated() == 0 || now.Sub(time.Unix(inst.GetCreated(), 0)) < minAge {
+				continue
+			}
+		}
+		insts = append(insts, inst)
+	}
+	return &protos.ListInstancesResponse{Instances: insts}, nil
+}
+
+func newFakeListClient(now time.Time) *fakeListClient {
+	inst := func(id, builderType string, age time.Duration) *protos.Instance {
+		i := &protos.Instance{
+			GomoteId:    id,
+			BuilderType: builderType,
+			HostType:    "host-" + builderType,
+			Expires:     now.Add(time.Hour).Unix(),
+		}
+		if age > 0 {
+			i.Created = now.Add(-age).Unix()
+		}
+		return i
+	}
+	labeled := inst("user-linux-amd64-0", "gotip-linux-amd64", 3*time.Hour)
+	labeled.Labels = []string{"cl=12345", "pipeline=nightly"}
+	return &fakeListClient{instances: []*protos.Instance{
+		labeled,
+		inst("user-linux-arm64-0", "gotip-linux-arm64", time.Minute),
+		inst("user-darwin-amd64-0", "gotip-darwin-amd64", 5*time.Hour),
+		inst("user-windows-amd64-0", "gotip-windows-amd64", 0),
+	}}
+}
+
+func names(rbs []buildlet.RemoteBuildlet) []string {
+	var ret []string
+	for _, rb := range rbs {
+		ret = append(ret, rb.Name)
+	}
+	return ret
+}
+
+func TestQueryInstances(t *testing.T) {
+	ctx := context.Background()
+	g := &groupData{Instances: []string{"user-linux-amd64-0", "user-linux-arm64-0", "user-darwin-amd64-0"}}
+	testCases := []struct {
+		desc                     string
+		group                    *groupData
+		typePattern, namePattern string
+		labels                   []string
+		sortBy                   string
+		wantPrefix               string
+		want                     []string
+	}{
+		{
+			desc:   "all",
+			sortBy: "name",
+			want:   []string{"user-darwin-amd64-0", "user-linux-amd64-0", "user-linux-arm64-0", "user-windows-amd64-0"},
+		},
+		{
+			desc:        "type",
+			typePattern: "gotip-linux-*",
+			sortBy:      "name",
+			wantPrefix:  "gotip-linux-",
+			want:        []string{"user-linux-amd64-0", "user-linux-arm64-0"},
+		},
+		{
+			desc:        "type and name",
+			typePattern: "gotip-*",
+			namePattern: "*-amd64-*",
+			sortBy:      "type",
+			wantPrefix:  "gotip-",
+			want:        []string{"user-darwin-amd64-0", "user-linux-amd64-0", "user-windows-amd64-0"},
+		},
+		{
+			desc:   "group",
+			group:  g,
+			sortBy: "type",
+			want:   []string{"user-darwin-amd64-0", "user-linux-amd64-0", "user-linux-arm64-0"},
+		},
+		{
+			desc:   "labels",
+			labels: []string{"cl=12345", "pipeline"},
+			sortBy: "name",
+			want:   []string{"user-linux-amd64-0"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := newFakeListClient(time.Now())
+			got, err := queryInstances(ctx, client, tc.group, tc.typePattern, tc.namePattern, tc.labels, tc.sortBy)
+			if err != nil {
+				t.Fatalf("queryInstances: %v", err)
+			}
+			if !slices.Equal(names(got), tc.want) {
+				t.Errorf("queryInstances = %q; want %q", names(got), tc.want)
+			}
+			if len(client.reqs) != 1 || client.reqs[0].GetBuilderTypePrefix() != tc.wantPrefix {
+				t.Errorf("requests = %v; want one with builder type prefix %q", client.reqs, tc.wantPrefix)
+			}
+			if len(client.reqs) == 1 && !slices.Equal(client.reqs[0].GetLabelSelector(), tc.labels) {
+				t.Errorf("request label selector = %q; want %q", client.reqs[0].GetLabelSelector(), tc.labels)
+			}
+		})
+	}
+}
+
+func TestIdleInstances(t *testing.T) {
+	now := time.Now()
+	client := newFakeListClient(now)
+	// An old instance which ran a command a minute ago isn't idle.
+	client.instances = append(client.instances, &protos.Instance{
+		GomoteId:     "user-linux-386-0",
+		BuilderType:  "gotip-linux-386",
+		Created:      now.Add(-4 * time.Hour).Unix(),
+		LastActivity: now.Add(-time.Minute).Unix(),
+	})
+	got, err := idleInstances(context.Background(), client, 2*time.Hour, nil)
+	if err != nil {
+		t.Fatalf("idleInstances: %v", err)
+	}
+	want := []string{"user-linux-amd64-0", "user-darwin-amd64-0"}
+	if !slices.Equal(names(got), want) {
+		t.Errorf("idleInstances = %q; want %q", names(got), want)
+	}
+	if got := client.reqs[0].GetMinAgeSeconds(); got != int64((2 * time.Hour).Seconds()) {
+		t.Errorf("request min age = %ds; want %ds", got, int64((2 * time.Hour).Seconds()))
+	}
+
+	got, err = idleInstances(context.Background(), client, 2*time.Hour, []string{"pipeline=nightly"})
+	if err != nil {
+		t.Fatalf("idleInstances: %v", err)
+	}
+	if want := []string{"user-linux-amd64-0"}; !slices.Equal(names(got), want) {
+		t.Errorf("idleInstances with labels = %q; want %q", names(got), want)
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	for pattern, want := range map[string]string{
+		"":                "",
+		"gotip-linux-*":   "gotip-linux-",
+		"gotip-linux-amd": "gotip-linux-amd",
+		"go1.2?-linux":    "go1.2",
+		`a\*b`:            "a",
+		"[gx]o":           "",
+	} {
+		if got := literalPrefix(pattern); got != want {
+			t.Errorf("literalPrefix(%q) = %q; want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestWriteInstancesJSON(t *testing.T) {
+	now := time.Now()
+	instances, err := queryInstances(context.Background(), newFakeListClient(now), nil, "", "", nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := []*groupData{{Name: "g", Instances: []string{"user-linux-amd64-0"}}}
+	var b strings.Builder
+	if err := writeInstancesJSON(&b, instances, groups); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatalf("decoding %s: %v", b.String(), err)
+	}
+	byID := make(map[string]map[string]any)
+	for _, inst := range got {
+		byID[inst["id"].(string)] = inst
+	}
+	if len(byID) != len(instances) {
+		t.Fatalf("writeInstancesJSON wrote %d instances; want %d:\n%s", len(byID), len(instances), b.String())
+	}
+	linux := byID["user-linux-amd64-0"]
+	if linux["builder_type"] != "gotip-linux-amd64" || linux["created"] == nil || linux["expires"] == nil {
+		t.Errorf("user-linux-amd64-0 = %v; want its builder type and timestamps", linux)
+	}
+	if groups, _ := linux["groups"].([]any); len(groups) != 1 || groups[0] != "g" {
+		t.Errorf("user-linux-amd64-0 groups = %v; want [g]", linux["groups"])
+	}
+	// The creation time of user-windows-amd64-0 is unknown.
+	windows := byID["user-windows-amd64-0"]
+	if _, ok := windows["created"]; ok {
+		t.Errorf("user-windows-amd64-0 = %v; want no creation time", windows)
+	}
+	if _, ok := windows["groups"]; ok {
+		t.Errorf("user-windows-amd64-0 = %v; want no groups", windows)
+	}
+}
@@ -9,27 +9,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 
 	"golang.org/x/build/internal/gomote/protos"
 )
 
 func ls(args []string) error {
-	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "ls usage: gomote ls [ls-opts] [instance] [dir]")
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	var recursive bool
-	fs.BoolVar(&recursive, "R", false, "recursive")
-	var digest bool
-	fs.BoolVar(&digest, "d", false, "get file digests")
-	var skip string
-	fs.StringVar(&skip, "skip", "", "comma-separated list of relative directories to skip (use forward slashes)")
-	fs.Parse(args)
+	var flags lsFlags
+	fs := lsFlagSet(&flags)
+	parseFlags(fs, args)
 
 	ctx := context.Background()
 	dir := "."
@@ -45,9 +34,22 @@ func ls(args []string) error {
 			lsSet = append(lsSet, inst)
 		}
 	case 1:
+		// Without an active group, the argument must be an instance.
+		if activeGroup == nil {
+			inst, err := resolveInstance(ctx, fs.Arg(0))
+			if err != nil {
+				return err
+			}
+			lsSet = []string{inst}
+			break
+		}
 		// Ambiguous case. Check if it's a real instance, if not, treat it
 		// as a directory.
-		if err := doPing(ctx, fs.Arg(0)); instanceDoesNotExist(err) {
+		name, err := resolveAlias(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if err := doPing(ctx, name); instanceDoesNotExist(err) {
 			// Not an instance.
 			for _, inst := range activeGroup.Instances {
 				lsSet = append(lsSet, inst)
@@ -55,26 +57,44 @@ func ls(args []string) error {
 			dir = fs.Arg(0)
 		} else if err == nil {
 			// It's an instance.
-			lsSet = []string{fs.Arg(0)}
+			lsSet = []string{name}
 		} else {
-			return fmt.Errorf("failed to ping %q: %w", fs.Arg(0), err)
+			return fmt.Errorf("failed to ping %q: %w", name, err)
 		}
 	case 2:
 		// Instance and directory is specified.
-		lsSet = []string{fs.Arg(0)}
+		inst, err := resolveInstance(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		lsSet = []string{inst}
 		dir = fs.Arg(1)
 	default:
 		fmt.Fprintln(os.Stderr, "error: too many arguments")
 		fs.Usage()
 	}
+	var patterns []string
+	if flags.pattern != "" {
+		patterns = strings.Split(flags.pattern, ",")
+		for _, pat := range patterns {
+			if _, err := path.Match(pat, ""); err != nil {
+				return usageErrorf("invalid -pattern %q: %v", pat, err)
+			}
+		}
+	}
+	if flags.depth < 0 {
+		return usageErrorf("-depth must not be negative")
+	}
 	for _, inst := range lsSet {
 		client := gomoteServerClient(ctx)
 		resp, err := client.ListDirectory(ctx, &protos.ListDirectoryRequest{
 			GomoteId:  inst,
 			Directory: dir,
-			Recursive: recursive,
-			SkipFiles: strings.Split(skip, ","),
-			Digest:    digest,
+			Recursive: flags.recursive,
+			SkipFiles: strings.Split(flags.skip, ","),
+			Digest:    flags.digest,
+			Patterns:  patterns,
+			MaxDepth:  int32(flags.depth),
 		})
 		if err != nil {
 			return fmt.Errorf("unable to ls: %w", err)
@@ -91,3 +111,29 @@ func ls(args []string) error {
 	}
 	return nil
 }
+
+// lsFlags are the flags of the ls command.
+type lsFlags struct {
+	recursive bool
+	digest    bool
+	skip      string
+	pattern   string
+	depth     int
+}
+
+func lsFlagSet(flags *lsFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "ls usage: gomote ls [ls-opts] [instance] [dir]")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.recursive, "R", false, "recursive")
+	fs.BoolVar(&flags.digest, "d", false, "get file digests")
+	fs.StringVar(&flags.skip, "skip", "", "comma-separated list of relative directories to skip (use forward slashes)")
+	fs.StringVar(&flags.pattern, "pattern", "", "comma-separated list of patterns, such as *.log or src/*/*.go, limiting the entries listed; patterns without a slash match file names only")
+	fs.IntVar(&flags.depth, "depth", 0, "with -R, the maximum depth to list; 1 lists only the entries in the directory itself (0 means no limit)")
+	return fs
+}
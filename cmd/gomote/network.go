@@ -0,0 +1,192 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"golang.org/x/build/internal/iapclient"
+	"golang.org/x/net/proxy"
+)
+
+var (
+	caBundleFlag       = flag.String("ca-bundle", "", "PEM file of CA certificates to trust for the server in addition to the system's, such as those of a self-hosted server (default is $GOMOTE_CA_BUNDLE)")
+	insecureSkipVerify = flag.Bool("insecure-skip-verify", false, "don't verify the certificate of the server, such as a staging or self-hosted one; not allowed with Go's production servers")
+)
+
+func init() {
+	flag.StringVar(caBundleFlag, "ca-file", "", "alias for -ca-bundle")
+}
+
+// productionServers are the addresses of Go's production gomote servers,
+// which share the default credentials.
+var productionServers = map[string]bool{
+	luciServerAddr:         true,
+	"build.golang.org:443": true,
+}
+
+// serverSource describes where the address of the gomote server was
+// set, for version and doctor.
+var serverSource = "default"
+
+// credentialScope returns the scope of the credentials used for the
+// server at addr. Each server other than the production ones has its own,
+// so that a staging or self-hosted server never receives the credentials
+// of the production servers, and the other way around.
+func credentialScope(addr string) string {
+	if productionServers[addr] {
+		return ""
+	}
+	return addr
+}
+
+// checkInsecureSkipVerify returns a usage error if -insecure-skip-verify
+// is set along with the address of a production server.
+func checkInsecureSkipVerify(addr string, skip bool) error {
+	if skip && productionServers[addr] {
+		return usageErrorf("-insecure-skip-verify can't be used with the production server %s", addr)
+	}
+	return nil
+}
+
+// serverDialer returns the dialer of the gomote server at addr. It
+// trusts the certificates in the -ca-bundle file, or trusts any with
+// -insecure-skip-verify unless it's a production server, uses the credentials of the server's scope, and
+// connects through the SOCKS proxy given by $ALL_PROXY, if any. HTTPS
+// proxies given by $HTTPS_PROXY are honored by both transports either way.
+func serverDialer(addr string) (*iapclient.Dialer, error) {
+	d := &iapclient.Dialer{CredentialScope: credentialScope(addr)}
+	if path := caBundlePath(os.Getenv); path != "" {
+		pool, _, err := loadCABundle(path)
+		if err != nil {
+			return nil, err
+		}
+		d.TLSConfig = &tls.Config{RootCAs: pool}
+	}
+	if *insecureSkipVerify && !productionServers[addr] {
+		if d.TLSConfig == nil {
+			d.TLSConfig = new(tls.Config)
+		}
+		d.TLSConfig.InsecureSkipVerify = true
+	}
+	if socksProxy(os.Getenv) != nil {
+		// proxy.Dial also honors $NO_PROXY.
+		d.DialContext = proxy.Dial
+	}
+	return d, nil
+}
+
+// caBundlePath returns the path of the CA bundle set by -ca-bundle, or its
+// alias -ca-file, or $GOMOTE_CA_BUNDLE, or "" if there is none.
+func caBundlePath(getenv func(string) string) string {
+	if *caBundleFlag != "" {
+		return *caBundleFlag
+	}
+	return getenv("GOMOTE_CA_BUNDLE")
+}
+
+// loadCABundle returns a pool of the system's roots and the certificates
+// in the PEM file at path, and the number of certificates in the file.
+func loadCABundle(path string) (*x509.CertPool, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	n := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, 0, fmt.Errorf("CA bundle %s: %w", path, err)
+		}
+		pool.AddCert(cert)
+		n++
+	}
+	if n == 0 {
+		return nil, 0, fmt.Errorf("CA bundle %s contains no certificates", path)
+	}
+	return pool, n, nil
+}
+
+// socksProxy returns the URL of the SOCKS proxy given by $ALL_PROXY, or
+// nil if there is none.
+func socksProxy(getenv func(string) string) *url.URL {
+	for _, env := range []string{"ALL_PROXY", "all_proxy"} {
+		v := getenv(env)
+		if v == "" {
+			continue
+		}
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "socks5" && u.Scheme != "socks5h") {
+			return nil
+		}
+		return u
+	}
+	return nil
+}
+
+// httpsProxy returns the name and value of the environment variable
+// giving the HTTPS proxy, with any password redacted.
+func httpsProxy(getenv func(string) string) (env, value string) {
+	for _, env := range []string{"HTTPS_PROXY", "https_proxy"} {
+		v := getenv(env)
+		if v == "" {
+			continue
+		}
+		if u, err := url.Parse(v); err == nil && u.Host != "" {
+			v = u.Redacted()
+		}
+		return env, v
+	}
+	return "", ""
+}
+
+// writeNetworkSettings writes the server, and the proxy and CA settings
+// used to reach it, for doctor.
+func writeNetworkSettings(w io.Writer, getenv func(string) string) {
+	fmt.Fprintf(w, "INFO server: %s (from %s, -transport=%s)\n", *serverAddr, serverSource, *transport)
+	if *insecureSkipVerify {
+		fmt.Fprintf(w, "INFO TLS verification: disabled by -insecure-skip-verify\n")
+	}
+	if env, v := httpsProxy(getenv); env != "" {
+		fmt.Fprintf(w, "INFO HTTPS proxy: %s=%s\n", env, v)
+	} else {
+		fmt.Fprintf(w, "INFO HTTPS proxy: none\n")
+	}
+	if u := socksProxy(getenv); u != nil {
+		fmt.Fprintf(w, "INFO SOCKS proxy: %s\n", u.Redacted())
+	} else {
+		fmt.Fprintf(w, "INFO SOCKS proxy: none\n")
+	}
+	for _, env := range []string{"NO_PROXY", "no_proxy"} {
+		if v := getenv(env); v != "" {
+			fmt.Fprintf(w, "INFO no proxy: %s=%s\n", env, v)
+			break
+		}
+	}
+	if path := caBundlePath(getenv); path != "" {
+		fmt.Fprintf(w, "INFO CA certificates: system roots and %s\n", path)
+	} else {
+		fmt.Fprintf(w, "INFO CA certificates: system roots\n")
+	}
+}
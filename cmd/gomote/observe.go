@@ -0,0 +1,176 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// rpcObservers are told about the RPCs made to the gomote server, for
+// debug logging and -timings.
+var rpcObservers struct {
+	sync.Mutex
+	list []buildlet.Observer
+}
+
+// observeRPCs adds o to the observers of the RPCs made to the gomote server.
+func observeRPCs(o buildlet.Observer) {
+	rpcObservers.Lock()
+	defer rpcObservers.Unlock()
+	rpcObservers.list = append(rpcObservers.list, o)
+}
+
+func currentRPCObservers() []buildlet.Observer {
+	rpcObservers.Lock()
+	defer rpcObservers.Unlock()
+	return rpcObservers.list
+}
+
+// observeDialOptions returns the dial options which report RPCs to the
+// observers added with observeRPCs.
+func observeDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(observeUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(observeStreamInterceptor),
+	}
+}
+
+func observeUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	obs := currentRPCObservers()
+	if len(obs) == 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	r := buildlet.Request{Method: method, Instance: rpcInstance(req), Start: time.Now()}
+	for _, o := range obs {
+		o.RequestStart(r)
+	}
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	s := buildlet.RequestStats{
+		Duration:      time.Since(r.Start),
+		BytesSent:     msgSize(req),
+		BytesReceived: msgSize(reply),
+		Err:           err,
+	}
+	for _, o := range obs {
+		o.RequestDone(r, s)
+	}
+	return err
+}
+
+func observeStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	obs := currentRPCObservers()
+	if len(obs) == 0 {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	s := &observedClientStream{obs: obs, req: buildlet.Request{Method: method, Stream: true, Start: time.Now()}}
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		s.closed(err)
+		return nil, err
+	}
+	s.ClientStream = cs
+	return s, nil
+}
+
+// observedClientStream counts the messages and bytes sent and received
+// on a stream and reports them to its observers once the stream is closed. The
+// stream's start is reported once its first message is sent, so that
+// the instance it targets is known.
+type observedClientStream struct {
+	grpc.ClientStream
+	obs []buildlet.Observer
+
+	mu                 sync.Mutex
+	req                buildlet.Request
+	started            bool
+	sent, recv         int64
+	sentSize, recvSize int64
+	closeOnce          sync.Once
+}
+
+func (s *observedClientStream) SendMsg(m any) error {
+	s.mu.Lock()
+	if !s.started {
+		s.req.Instance = rpcInstance(m)
+		s.start()
+	}
+	s.mu.Unlock()
+	err := s.ClientStream.SendMsg(m)
+	s.mu.Lock()
+	s.sent++
+	s.sentSize += msgSize(m)
+	s.mu.Unlock()
+	if err != nil {
+		s.closed(err)
+	}
+	return err
+}
+
+func (s *observedClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		s.closed(nil)
+		return err
+	} else if err != nil {
+		s.closed(err)
+		return err
+	}
+	s.mu.Lock()
+	s.recv++
+	s.recvSize += msgSize(m)
+	s.mu.Unlock()
+	return nil
+}
+
+// start reports the start of the stream. s.mu must be held.
+func (s *observedClientStream) start() {
+	s.started = true
+	for _, o := range s.obs {
+		o.RequestStart(s.req)
+	}
+}
+
+func (s *observedClientStream) closed(err error) {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.started {
+			s.start()
+		}
+		stats := buildlet.RequestStats{
+			Duration:         time.Since(s.req.Start),
+			BytesSent:        s.sentSize,
+			BytesReceived:    s.recvSize,
+			MessagesSent:     s.sent,
+			MessagesReceived: s.recv,
+			Err:              err,
+		}
+		for _, o := range s.obs {
+			o.RequestDone(s.req, stats)
+		}
+	})
+}
+
+// rpcInstance returns the instance targeted by an RPC request, if any.
+func rpcInstance(req any) string {
+	if r, ok := req.(interface{ GetGomoteId() string }); ok {
+		return r.GetGomoteId()
+	}
+	return ""
+}
+
+func msgSize(m any) int64 {
+	if pm, ok := m.(proto.Message); ok {
+		return int64(proto.Size(pm))
+	}
+	return 0
+}
@@ -0,0 +1,353 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/user"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/build/buildlet"
+)
+
+var opLogFlag = flag.String("oplog", os.Getenv("GOMOTE_OPLOG"), "append a line of JSON describing each command which changes instances, such as create, run and destroy, to this file (default is $GOMOTE_OPLOG)")
+
+// opLogCommands are the commands recorded in the operation log: those
+// which change instances.
+var opLogCommands = map[string]bool{
+	"create":  true,
+	"destroy": true,
+	"extend":  true,
+	"push":    true,
+	"puttar":  true,
+	"rm":      true,
+	"run":     true,
+}
+
+// An opLogEntry is a line of the operation log.
+type opLogEntry struct {
+	Time      time.Time     `json:"time"`
+	User      string        `json:"user,omitempty"`
+	Command   string        `json:"command"`
+	Instances []string      `json:"instances,omitempty"`
+	Args      []string      `json:"args,omitempty"` // with secrets redacted
+	Duration  time.Duration `json:"duration_ns"`
+	ExitCode  int           `json:"exit_code"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// opLog records the command being run for the operation log. It is nil
+// unless the command is recorded.
+var opLog *opRecorder
+
+// An opRecorder records the instances targeted by a command, from the
+// RPCs it makes as a buildlet.Observer. It is safe for concurrent use.
+type opRecorder struct {
+	cmd   string
+	args  []string
+	start time.Time
+
+	mu    sync.Mutex
+	insts map[string]bool
+
+	recordOnce sync.Once
+}
+
+// newOpRecorder returns a recorder of cmd run with args which observes
+// the RPCs made to the gomote server.
+func newOpRecorder(cmd string, args []string) *opRecorder {
+	r := &opRecorder{cmd: cmd, args: args, start: time.Now(), insts: make(map[string]bool)}
+	observeRPCs(r)
+	return r
+}
+
+// RequestStart implements buildlet.Observer.
+func (r *opRecorder) RequestStart(req buildlet.Request) {
+	r.target(req.Instance)
+}
+
+// RequestDone implements buildlet.Observer.
+func (r *opRecorder) RequestDone(buildlet.Request, buildlet.RequestStats) {}
+
+// target records inst as a target of the command.
+func (r *opRecorder) target(inst string) {
+	if inst == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insts[inst] = true
+}
+
+// entry returns the entry of the command, which exited with code after
+// err.
+func (r *opRecorder) entry(code int, err error) opLogEntry {
+	e := opLogEntry{
+		Time:     r.start,
+		Command:  r.cmd,
+		Args:     redactArgs(r.args),
+		Duration: time.Since(r.start),
+		ExitCode: code,
+	}
+	if u, err := user.Current(); err == nil {
+		e.User = u.Username
+	}
+	r.mu.Lock()
+	for inst := range r.insts {
+		e.Instances = append(e.Instances, inst)
+	}
+	r.mu.Unlock()
+	sort.Strings(e.Instances)
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// recordOp appends the entry of the command being run, which exited with
+// code after err, to the operation log, if the command is recorded. Only
+// the first call records it, so that a command which exits early, such as
+// when interrupted, isn't recorded twice.
+func recordOp(code int, err error) {
+	if opLog == nil {
+		return
+	}
+	opLog.recordOnce.Do(func() {
+		if err := appendOpLog(*opLogFlag, opLog.entry(code, err)); err != nil {
+			fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Unable to record %s in the operation log: %v", opLog.cmd, err)))
+		}
+	})
+}
+
+// exitOp records the command being run in the operation log, as by
+// recordOp, and exits with code. Commands which exit before returning use
+// it rather than os.Exit, so that they're still recorded.
+func exitOp(code int, err error) {
+	recordOp(code, err)
+	os.Exit(code)
+}
+
+// noteOpTarget records inst as a target of the command in the operation
+// log, for instances which RPCs don't name, such as those being created.
+func noteOpTarget(inst string) {
+	if opLog != nil {
+		opLog.target(inst)
+	}
+}
+
+// appendOpLog appends e to the operation log at path. The entry is written
+// with a single write to the file opened for appending, so that the
+// entries of gomote processes running at once don't interleave.
+func appendOpLog(path string, e opLogEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening operation log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing operation log: %w", err)
+	}
+	return f.Close()
+}
+
+// secretNameRE matches the names of flags and environment variables whose
+// values are secrets.
+var secretNameRE = regexp.MustCompile(`(?i)token|secret|passw(or)?d|credential|api[-_]?key|auth`)
+
+// redactArgs returns args with the values of flags and environment
+// variables named like secrets, such as -e GITHUB_TOKEN=..., the values of
+// HTTP headers, and the passwords of URLs replaced by xxxxx.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case i > 0 && isHeaderFlag(args[i-1]):
+			// Such as -url-header "Authorization: Bearer xyz".
+			redacted[i] = redactHeader(arg)
+		case i > 0 && isSecretFlag(args[i-1]):
+			// The value of a secret flag, such as -password xyz.
+			redacted[i] = "xxxxx"
+		default:
+			redacted[i] = redactArg(arg)
+		}
+	}
+	return redacted
+}
+
+// isHeaderFlag reports whether arg is the name of a flag whose values are
+// HTTP headers, such as those of puttar's -url-header.
+func isHeaderFlag(arg string) bool {
+	return arg == "-url-header" || arg == "--url-header"
+}
+
+// redactHeader redacts the value of an HTTP header of the form
+// "Name: value", leaving its name, since any header may carry
+// credentials.
+func redactHeader(header string) string {
+	name, _, ok := strings.Cut(header, ":")
+	if !ok {
+		return "xxxxx"
+	}
+	return name + ": xxxxx"
+}
+
+// isSecretFlag reports whether arg is a flag named like a secret whose
+// value may be the next argument.
+func isSecretFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") && secretNameRE.MatchString(arg)
+}
+
+// redactArg redacts a single argument, such as a URL, KEY=value or
+// -flag=value.
+func redactArg(arg string) string {
+	if u, err := url.Parse(arg); err == nil && u.Host != "" {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+	}
+	k, v, ok := strings.Cut(arg, "=")
+	switch {
+	case !ok:
+		return arg
+	case isHeaderFlag(k):
+		return k + "=" + redactHeader(v)
+	case secretNameRE.MatchString(k):
+		return k + "=xxxxx"
+	case strings.HasPrefix(k, "-"):
+		// Such as -e=GITHUB_TOKEN=...
+		return k + "=" + redactArg(v)
+	}
+	return arg
+}
+
+// readOpLog reads the entries of the operation log from r. It returns the
+// number of lines which aren't entries, such as those cut short when the
+// disk was full, along with the entries.
+func readOpLog(r io.Reader) (entries []opLogEntry, malformed int, err error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		if len(strings.TrimSpace(s.Text())) == 0 {
+			continue
+		}
+		var e opLogEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			malformed++
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, malformed, s.Err()
+}
+
+// filterOpLog returns the entries which target inst, unless it is empty,
+// and started after since, unless it is zero.
+func filterOpLog(entries []opLogEntry, inst string, since time.Time) []opLogEntry {
+	var filtered []opLogEntry
+	for _, e := range entries {
+		if !since.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		if inst != "" && !slices.Contains(e.Instances, inst) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// writeOpLog writes entries as a table to w.
+func writeOpLog(w io.Writer, entries []opLogEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tUSER\tCOMMAND\tINSTANCES\tDURATION\tARGS\tRESULT")
+	for _, e := range entries {
+		insts := strings.Join(e.Instances, ",")
+		if insts == "" {
+			insts = "-"
+		}
+		result := "ok"
+		if e.ExitCode != 0 || e.Error != "" {
+			result = fmt.Sprintf("exit %d: %s", e.ExitCode, e.Error)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%v\t%s\t%s\n",
+			e.Time.Local().Format(time.DateTime), e.User, e.Command, insts,
+			e.Duration.Round(time.Millisecond), strings.Join(e.Args, " "), result)
+	}
+	return tw.Flush()
+}
+
+func opLogCmd(args []string) error {
+	var flags opLogFlags
+	fs := opLogFlagSet(&flags)
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+	}
+	if *opLogFlag == "" {
+		return usageErrorf("no operation log; set -oplog or $GOMOTE_OPLOG")
+	}
+	if flags.since < 0 {
+		return usageErrorf("invalid -since %v", flags.since)
+	}
+	f, err := os.Open(*opLogFlag)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# The operation log %s is empty.", *opLogFlag)))
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, malformed, err := readOpLog(f)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", *opLogFlag, err)
+	}
+	if malformed > 0 {
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Skipped %d malformed lines of %s.", malformed, *opLogFlag)))
+	}
+	var since time.Time
+	if flags.since > 0 {
+		since = time.Now().Add(-flags.since)
+	}
+	return writeOpLog(os.Stdout, filterOpLog(entries, flags.inst, since))
+}
+
+// opLogFlags are the flags of the log command.
+type opLogFlags struct {
+	inst  string
+	since time.Duration
+}
+
+func opLogFlagSet(flags *opLogFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("log", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "log usage: gomote log [log-opts]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Prints the operation log set by -oplog or $GOMOTE_OPLOG: the commands")
+		fmt.Fprintln(os.Stderr, "which changed instances, such as create, run and destroy, with their")
+		fmt.Fprintln(os.Stderr, "instances, arguments, duration and result.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.inst, "instance", "", "only print the commands which targeted `instance`")
+	fs.DurationVar(&flags.since, "since", 0, "only print the commands run in the last `duration`, such as 24h")
+	return fs
+}
@@ -8,25 +8,26 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"golang.org/x/build/internal/gomote/protos"
 )
 
 func ping(args []string) error {
-	fs := flag.NewFlagSet("ping", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "ping usage: gomote ping [instance]")
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	fs.Parse(args)
+	var flags pingFlags
+	fs := pingFlagSet(&flags)
+	parseFlags(fs, args)
 
 	var pingSet []string
 	if fs.NArg() == 1 {
-		pingSet = []string{fs.Arg(0)}
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		pingSet = []string{inst}
 	} else if fs.NArg() == 0 && activeGroup != nil {
 		for _, inst := range activeGroup.Instances {
 			pingSet = append(pingSet, inst)
@@ -37,15 +38,42 @@ func ping(args []string) error {
 
 	ctx := context.Background()
 	for _, inst := range pingSet {
-		if err := doPing(ctx, inst); err != nil {
+		if !flags.verbose {
+			if err := doPing(ctx, inst); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", inst, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: alive\n", inst)
+			}
+			continue
+		}
+		resp, err := doPingBuildlet(ctx, inst)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s: %v\n", inst, err)
-		} else {
-			fmt.Fprintf(os.Stderr, "%s: alive\n", inst)
+			continue
 		}
+		printPing(os.Stderr, inst, resp)
 	}
 	return nil
 }
 
+// pingFlags are the flags of the ping command.
+type pingFlags struct {
+	verbose bool
+}
+
+func pingFlagSet(flags *pingFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("ping", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "ping usage: gomote ping [instance]")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.verbose, "v", false, "also report the latency of the buildlet and the skew of its clock")
+	return fs
+}
+
 func doPing(ctx context.Context, name string) error {
 	client := gomoteServerClient(ctx)
 	_, err := client.InstanceAlive(ctx, &protos.InstanceAliveRequest{
@@ -56,3 +84,36 @@ func doPing(ctx context.Context, name string) error {
 	}
 	return nil
 }
+
+// doPingBuildlet is like doPing, but also has the server make a round
+// trip to the instance's buildlet.
+func doPingBuildlet(ctx context.Context, name string) (*protos.InstanceAliveResponse, error) {
+	client := gomoteServerClient(ctx)
+	resp, err := client.InstanceAlive(ctx, &protos.InstanceAliveRequest{
+		GomoteId:     name,
+		PingBuildlet: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to ping instance: %w", err)
+	}
+	return resp, nil
+}
+
+// printPing writes the result of pinging inst's buildlet to w.
+func printPing(w io.Writer, inst string, resp *protos.InstanceAliveResponse) {
+	us := func(v int64) time.Duration { return time.Duration(v) * time.Microsecond }
+	fmt.Fprintf(w, "%s: alive, rtt %v", inst, us(resp.GetBuildletRttMicros()))
+	if resp.GetClockReported() {
+		fmt.Fprintf(w, ", clock skew %v", us(resp.GetClockSkewMicros()))
+	} else {
+		fmt.Fprintf(w, ", clock skew unknown")
+	}
+	fmt.Fprintln(w)
+	if hb := resp.GetHeartbeatRttMicros(); len(hb) > 0 {
+		rtts := make([]string, len(hb))
+		for i, v := range hb {
+			rtts[i] = us(v).String()
+		}
+		fmt.Fprintf(w, "%s: recent heartbeats: %s\n", inst, strings.Join(rtts, " "))
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+func TestPrintPing(t *testing.T) {
+	var b strings.Builder
+	printPing(&b, "inst", &protos.InstanceAliveResponse{
+		BuildletRttMicros:  1500,
+		ClockSkewMicros:    -2000000,
+		ClockReported:      true,
+		HeartbeatRttMicros: []int64{1000, 3000},
+	})
+	want := "inst: alive, rtt 1.5ms, clock skew -2s\ninst: recent heartbeats: 1ms 3ms\n"
+	if b.String() != want {
+		t.Errorf("printPing wrote %q; want %q", b.String(), want)
+	}
+
+	b.Reset()
+	printPing(&b, "inst", &protos.InstanceAliveResponse{BuildletRttMicros: 1000})
+	want = "inst: alive, rtt 1ms, clock skew unknown\n"
+	if b.String() != want {
+		t.Errorf("printPing wrote %q; want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/build/cmd/gomote/progresstypes"
+)
+
+var (
+	progressFD   = flag.Int("progress-fd", 0, "write progress events as newline-delimited JSON to this file descriptor; see golang.org/x/build/cmd/gomote/progresstypes")
+	progressFile = flag.String("progress-file", "", "write progress events as newline-delimited JSON to this file")
+)
+
+var (
+	progressMu  sync.Mutex
+	progressEnc *json.Encoder // nil unless progress events were requested
+)
+
+// setupProgress enables progress events if they were requested by
+// -progress-fd or -progress-file.
+func setupProgress() error {
+	var w io.Writer
+	switch {
+	case *progressFD != 0 && *progressFile != "":
+		return usageErrorf("-progress-fd and -progress-file are mutually exclusive")
+	case *progressFD != 0:
+		if *progressFD < 0 {
+			return usageErrorf("invalid -progress-fd %d", *progressFD)
+		}
+		w = os.NewFile(uintptr(*progressFD), "progress")
+	case *progressFile != "":
+		f, err := os.OpenFile(*progressFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("opening progress file: %w", err)
+		}
+		// The file is closed when the process exits.
+		w = f
+	default:
+		return nil
+	}
+	progressEnc = json.NewEncoder(w)
+	return nil
+}
+
+// emitProgress writes ev as a progress event if they are enabled. The
+// event's time defaults to now.
+func emitProgress(ev progresstypes.Event) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if progressEnc == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	// Progress events are best effort; failing to write them
+	// must not fail the command.
+	progressEnc.Encode(ev)
+}
+
+// progressInterval is the minimum interval between progress events
+// reporting the bytes transferred by an operation.
+const progressInterval = time.Second
+
+// emitProgressDone writes ev as the event finishing its phase, which failed
+// if err is non-nil.
+func emitProgressDone(ev progresstypes.Event, err error) {
+	ev.Time = time.Time{}
+	ev.Done = true
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	emitProgress(ev)
+}
+
+// progressReader is an io.Reader which emits progress events as it is read.
+type progressReader struct {
+	r    io.Reader
+	ev   progresstypes.Event // ev.TotalBytes is set if known
+	last time.Time
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.ev.Bytes += int64(n)
+	if err == io.EOF || time.Since(pr.last) >= progressInterval {
+		pr.last = time.Now()
+		ev := pr.ev
+		if ev.TotalBytes > 0 {
+			ev.Percent = 100 * float64(ev.Bytes) / float64(ev.TotalBytes)
+		}
+		emitProgress(ev)
+	}
+	return n, err
+}
+
+// progressWriter is an io.Writer which emits progress events reporting the
+// bytes written to it.
+type progressWriter struct {
+	ev   progresstypes.Event
+	last time.Time
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	pw.ev.Bytes += int64(len(p))
+	if time.Since(pw.last) >= progressInterval {
+		pw.last = time.Now()
+		emitProgress(pw.ev)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/build/cmd/gomote/progresstypes"
+)
+
+// captureProgress enables progress events for the duration of the test
+// and returns the buffer they are written to.
+func captureProgress(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	progressEnc = json.NewEncoder(&buf)
+	t.Cleanup(func() { progressEnc = nil })
+	return &buf
+}
+
+func decodeProgress(t *testing.T, r io.Reader) []progresstypes.Event {
+	var evs []progresstypes.Event
+	dec := json.NewDecoder(r)
+	for {
+		var ev progresstypes.Event
+		if err := dec.Decode(&ev); err == io.EOF {
+			return evs
+		} else if err != nil {
+			t.Fatalf("decoding progress event: %v", err)
+		}
+		evs = append(evs, ev)
+	}
+}
+
+func TestEmitProgress(t *testing.T) {
+	// Events are dropped when not enabled.
+	emitProgress(progresstypes.Event{Phase: progresstypes.PhasePush})
+
+	buf := captureProgress(t)
+	ev := progresstypes.Event{Phase: progresstypes.PhasePush, Instance: "inst"}
+	emitProgress(ev)
+	emitProgressDone(ev, errors.New("oops"))
+	evs := decodeProgress(t, buf)
+	if len(evs) != 2 {
+		t.Fatalf("got %d events; want 2", len(evs))
+	}
+	if evs[0].Time.IsZero() || evs[0].Phase != progresstypes.PhasePush || evs[0].Instance != "inst" || evs[0].Done {
+		t.Errorf("first event = %+v; want push on inst with a time", evs[0])
+	}
+	if !evs[1].Done || evs[1].Error != "oops" {
+		t.Errorf("second event = %+v; want done with error oops", evs[1])
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	buf := captureProgress(t)
+	const data = "some tarball contents"
+	pr := &progressReader{r: strings.NewReader(data), ev: progresstypes.Event{Phase: progresstypes.PhasePush, TotalBytes: int64(len(data))}}
+	if _, err := io.Copy(io.Discard, pr); err != nil {
+		t.Fatal(err)
+	}
+	evs := decodeProgress(t, buf)
+	if len(evs) == 0 {
+		t.Fatal("got no events")
+	}
+	last := evs[len(evs)-1]
+	if last.Bytes != int64(len(data)) || last.Percent != 100 {
+		t.Errorf("last event = %+v; want all %d bytes read and 100 percent", last, len(data))
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package progresstypes contains the progress events written by gomote.
+//
+// When run with -progress-fd or -progress-file, gomote writes one
+// JSON-encoded Event per line describing the progress of long operations,
+// so that tools embedding gomote don't need to parse its human-readable
+// output.
+package progresstypes
+
+import "time"
+
+// Phase is the operation an Event reports progress on.
+type Phase string
+
+const (
+	// PhaseCreate is the creation of an instance, including any time
+	// spent waiting for capacity.
+	PhaseCreate Phase = "create"
+	// PhasePush is the push of a GOROOT to an instance.
+	PhasePush Phase = "push"
+	// PhaseRun is the execution of a command on an instance.
+	PhaseRun Phase = "run"
+	// PhaseBootstrap is the installation of the bootstrap version of Go
+	// on an instance.
+	PhaseBootstrap Phase = "bootstrap"
+)
+
+// Event is a progress event written by gomote.
+type Event struct {
+	// Time is the time of the event.
+	Time time.Time
+
+	// Phase is the operation in progress.
+	Phase Phase
+
+	// Instance is the name of the instance the event applies to.
+	// It is empty while an instance is waiting to be created.
+	Instance string `json:",omitempty"`
+
+	// Builder is the builder type of an instance being created.
+	Builder string `json:",omitempty"`
+
+	// Done reports whether the phase has finished, successfully
+	// unless Error is set.
+	Done bool `json:",omitempty"`
+
+	// Error describes why the phase failed.
+	Error string `json:",omitempty"`
+
+	// Bytes is the number of bytes transferred so far, and TotalBytes
+	// the number expected, when known.
+	Bytes      int64 `json:",omitempty"`
+	TotalBytes int64 `json:",omitempty"`
+
+	// Percent is the completion percentage of the phase, when known.
+	Percent float64 `json:",omitempty"`
+
+	// Message is a human-readable description of the event.
+	Message string `json:",omitempty"`
+}
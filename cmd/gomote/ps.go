@@ -0,0 +1,130 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func ps(args []string) error {
+	fs := psFlagSet()
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+	}
+	ctx := context.Background()
+	inst, err := resolveInstance(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	client := gomoteServerClient(ctx)
+	resp, err := client.ListCommands(ctx, &protos.ListCommandsRequest{GomoteId: inst})
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("unable to list commands on %s: the server does not support it", inst)
+	} else if err != nil {
+		return fmt.Errorf("unable to list commands on %s: %w", inst, err)
+	}
+	return writeCommandsTable(os.Stdout, resp.GetCommands(), time.Now())
+}
+
+func psFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("ps", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "ps usage: gomote ps <instance>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Lists the commands running on an instance. Their IDs may be passed to \"gomote kill\".")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	return fs
+}
+
+// writeCommandsTable writes a table of the commands running on an instance
+// at now.
+func writeCommandsTable(w io.Writer, cmds []*protos.Command, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tRUNNING\tCOMMAND")
+	for _, c := range cmds {
+		running := now.Sub(time.Unix(c.GetStarted(), 0)).Round(time.Second)
+		fmt.Fprintf(tw, "%s\t%v\t%s\n", c.GetExecutionId(), running, strings.Join(append([]string{c.GetCommand()}, c.GetArgs()...), " "))
+	}
+	return tw.Flush()
+}
+
+func kill(args []string) error {
+	fs := killFlagSet()
+	parseFlags(fs, args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+	}
+	ctx := context.Background()
+	inst, err := resolveInstance(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return doKill(ctx, gomoteServerClient(ctx), inst, fs.Arg(1))
+}
+
+func killFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("kill", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "kill usage: gomote kill <instance> <id>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Kills a command running on an instance, given its ID as listed by \"gomote ps\".")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	return fs
+}
+
+// doKill cancels the command with execution ID id running on inst.
+func doKill(ctx context.Context, client protos.GomoteServiceClient, inst, id string) error {
+	_, err := client.CancelCommand(ctx, &protos.CancelCommandRequest{
+		GomoteId:    inst,
+		ExecutionId: id,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("unable to kill command %s on %s: the server does not support it", id, inst)
+	} else if err != nil {
+		return fmt.Errorf("unable to kill command %s on %s: %w", id, inst, err)
+	}
+	return nil
+}
+
+// cancelCommandsTimeout bounds how long cancelCommands waits for the server.
+const cancelCommandsTimeout = 10 * time.Second
+
+// cancelCommands cancels the commands running on instances, given their
+// execution IDs keyed by instance, such as when gomote is interrupted. It
+// returns the first error encountered, once it has tried to cancel all of
+// them.
+func cancelCommands(client protos.GomoteServiceClient, execIDs map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelCommandsTimeout)
+	defer cancel()
+	var firstErr error
+	for inst, id := range execIDs {
+		fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Killing command %s on %s...", id, styles.Instance(inst))))
+		err := doKill(ctx, client, inst, id)
+		if code, _ := grpcCode(err); code == codes.NotFound {
+			// The command has already finished.
+			continue
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWriteCommandsTable(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var b strings.Builder
+	err := writeCommandsTable(&b, []*protos.Command{
+		{ExecutionId: "1", Command: "go/bin/go", Args: []string{"test", "./..."}, Started: now.Add(-90 * time.Second).Unix()},
+		{ExecutionId: "12", Command: "bash", Started: now.Unix()},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `ID  RUNNING  COMMAND
+1   1m30s    go/bin/go test ./...
+12  0s       bash
+`
+	if b.String() != want {
+		t.Errorf("writeCommandsTable wrote:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+// fakeCancelClient is a GomoteServiceClient which records the commands
+// canceled, and fails to cancel those in errs.
+type fakeCancelClient struct {
+	protos.GomoteServiceClient
+	canceled map[string]string
+	errs     map[string]error // keyed by instance
+}
+
+func (c *fakeCancelClient) CancelCommand(_ context.Context, req *protos.CancelCommandRequest, _ ...grpc.CallOption) (*protos.CancelCommandResponse, error) {
+	if err := c.errs[req.GetGomoteId()]; err != nil {
+		return nil, err
+	}
+	c.canceled[req.GetGomoteId()] = req.GetExecutionId()
+	return &protos.CancelCommandResponse{}, nil
+}
+
+func TestCancelCommands(t *testing.T) {
+	client := &fakeCancelClient{
+		canceled: map[string]string{},
+		errs: map[string]error{
+			"finished": status.Error(codes.NotFound, "command 3 is not executing on the instance"),
+		},
+	}
+	if err := cancelCommands(client, map[string]string{"a": "1", "b": "2", "finished": "3"}); err != nil {
+		t.Errorf("cancelCommands = %v; want nil, ignoring commands which have finished", err)
+	}
+	if client.canceled["a"] != "1" || client.canceled["b"] != "2" {
+		t.Errorf("canceled commands = %v; want 1 on a and 2 on b", client.canceled)
+	}
+
+	client.errs["old"] = status.Error(codes.Unimplemented, "method CancelCommand not implemented")
+	if err := cancelCommands(client, map[string]string{"old": "1"}); err == nil || !strings.Contains(err.Error(), "does not support") {
+		t.Errorf("cancelCommands with an old server = %v; want error", err)
+	}
+}
@@ -15,28 +15,27 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/build/buildlet"
+	"golang.org/x/build/cmd/gomote/progresstypes"
 	"golang.org/x/build/internal/gomote/protos"
 	"golang.org/x/sync/errgroup"
 )
 
 func push(args []string) error {
-	fs := flag.NewFlagSet("push", flag.ContinueOnError)
-	var dryRun bool
-	fs.BoolVar(&dryRun, "dry-run", false, "print what would be done only")
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "push usage: gomote push <instance>")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	fs.Parse(args)
+	var flags pushFlags
+	fs := pushFlagSet(&flags)
+	parseFlags(fs, args)
 
 	goroot, err := getGOROOT()
 	if err != nil {
@@ -45,7 +44,11 @@ func push(args []string) error {
 
 	var pushSet []string
 	if fs.NArg() == 1 {
-		pushSet = append(pushSet, fs.Arg(0))
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		pushSet = append(pushSet, inst)
 	} else if activeGroup != nil {
 		for _, inst := range activeGroup.Instances {
 			pushSet = append(pushSet, inst)
@@ -54,77 +57,107 @@ func push(args []string) error {
 		fs.Usage()
 	}
 
+	t := newTimer("push")
+	defer t.report(os.Stderr, flags.timings)
 	detailedProgress := len(pushSet) == 1
+	src := newPushSource(goroot, flags.gzipLevel)
 	eg, ctx := errgroup.WithContext(context.Background())
 	for _, inst := range pushSet {
 		inst := inst
 		eg.Go(func() error {
-			fmt.Fprintf(os.Stderr, "# Pushing GOROOT %q to %q...\n", goroot, inst)
-			return doPush(ctx, inst, goroot, dryRun, detailedProgress)
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Pushing GOROOT %q to %s...", goroot, styles.Instance(inst))))
+			defer t.span("push", inst)()
+			return doPush(ctx, inst, src, flags.dryRun, detailedProgress)
 		})
 	}
 	return eg.Wait()
 }
 
-func doPush(ctx context.Context, name, goroot string, dryRun, detailedProgress bool) error {
-	logf := func(s string, a ...interface{}) {
-		if detailedProgress {
-			log.Printf(s, a...)
-		}
-	}
-	remote := map[string]buildlet.DirEntry{} // keys like "src/make.bash"
+// pushFlags are the flags of the push command.
+type pushFlags struct {
+	dryRun    bool
+	gzipLevel int
+	timings   timingsFlag
+}
 
-	client := gomoteServerClient(ctx)
-	resp, err := client.ListDirectory(ctx, &protos.ListDirectoryRequest{
-		GomoteId:  name,
-		Directory: ".",
-		Recursive: true,
-		SkipFiles: []string{
-			// Ignore binary output directories:
-			"go/pkg", "go/bin",
-			// We don't care about the digest of
-			// particular source files for Go 1.4.  And
-			// exclude /pkg. This leaves go1.4/bin, which
-			// is enough to know whether we have Go 1.4 or
-			// not.
-			"go1.4/src", "go1.4/pkg",
-			// Ignore the cache and tmp directories, these slowly grow, and will
-			// eventually cause the listing to exceed the maximum gRPC message
-			// size.
-			"gocache", "goplscache", "tmp",
-		},
-		Digest: true,
-	})
-	if err != nil {
-		return fmt.Errorf("error listing buildlet's existing files: %w", err)
+func pushFlagSet(flags *pushFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	fs.BoolVar(&flags.dryRun, "dry-run", false, "print what would be done only")
+	fs.IntVar(&flags.gzipLevel, "gzip-level", gzip.DefaultCompression, "gzip compression level of the uploaded changes, from 1 for the fastest to 9 for the smallest; higher levels upload less over slow connections")
+	fs.Var(&flags.timings, "timings", timingsUsage)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "push usage: gomote push <instance>")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
 	}
-	for _, entry := range resp.GetEntries() {
-		de := buildlet.DirEntry{Line: entry}
-		en := de.Name()
-		if strings.HasPrefix(en, "go/") && en != "go/" {
-			remote[en[len("go/"):]] = de
-		}
+	return fs
+}
+
+// A pushSource is a local GOROOT to push to instances. However many
+// instances it's pushed to, GOROOT is walked and hashed once, and each
+// distinct set of files to send is archived and uploaded once: instances
+// in the same state, such as new ones, share an archive, and only those
+// whose remote GOROOT diverges get archives of their own.
+type pushSource struct {
+	goroot    string
+	gzipLevel int
+
+	scanOnce sync.Once
+	local    map[string]localFile // keys like "src/make.bash"
+	ignored  map[string]bool      // git-ignored files, with the same keys
+	gitExec  map[string]bool      // on Windows, files git records as executable, with the same keys
+	scanErr  error
+
+	mu       sync.Mutex
+	archives map[string]*pushArchive // by their newline-separated files
+}
+
+// A localFile is a file of a pushSource.
+type localFile struct {
+	fi   os.FileInfo
+	sha1 string // if regular file
+}
+
+// A pushArchive is a .tar.gz archive of files of a pushSource. It's
+// generated, and uploaded, on first use.
+type pushArchive struct {
+	files []string
+
+	genOnce sync.Once
+	tgz     []byte
+	genErr  error
+
+	uploadOnce sync.Once
+	url        string
+	uploadErr  error
+}
+
+// newPushSource returns a source pushing goroot, whose archives are
+// compressed with gzipLevel.
+func newPushSource(goroot string, gzipLevel int) *pushSource {
+	return &pushSource{
+		goroot:    goroot,
+		gzipLevel: gzipLevel,
+		archives:  make(map[string]*pushArchive),
 	}
-	// TODO(66635) remove once gomotes can no longer be created via the coordinator.
-	if luciDisabled() {
-		logf("installing go-bootstrap version in the working directory")
-		if dryRun {
-			logf("(Dry-run) Would have pushed go-bootstrap")
-		} else {
-			_, err := client.AddBootstrap(ctx, &protos.AddBootstrapRequest{
-				GomoteId: name,
-			})
-			if err != nil {
-				return fmt.Errorf("unable to add bootstrap version of Go to instance: %w", err)
-			}
+}
+
+// scan walks and hashes the files of the GOROOT of src, once.
+func (src *pushSource) scan() (local map[string]localFile, ignored map[string]bool, err error) {
+	src.scanOnce.Do(func() {
+		src.local, src.ignored, src.scanErr = scanGOROOT(src.goroot)
+		if runtime.GOOS == "windows" {
+			// Files have no executable bits to push; see archiveMode.
+			src.gitExec = gitExecutables(src.goroot)
 		}
-	}
+	})
+	return src.local, src.ignored, src.scanErr
+}
 
-	type fileInfo struct {
-		fi   os.FileInfo
-		sha1 string // if regular file
-	}
-	local := map[string]fileInfo{} // keys like "src/make.bash"
+// scanGOROOT returns the files of goroot, except those git ignores, which
+// it returns separately.
+func scanGOROOT(goroot string) (local map[string]localFile, ignored map[string]bool, err error) {
+	local = make(map[string]localFile)
 
 	// Ensure that the goroot passed to filepath.Walk ends in a trailing slash,
 	// so that if GOROOT is a symlink we walk the underlying directory.
@@ -144,7 +177,10 @@ func doPush(ctx context.Context, name, goroot string, dryRun, detailedProgress b
 		if err != nil {
 			return fmt.Errorf("error calculating relative path from %q to %q", goroot, path)
 		}
-		rel = filepath.ToSlash(rel)
+		rel, err = archivePath(rel, filepath.Separator)
+		if err != nil {
+			return err
+		}
 		if rel == "." {
 			return nil
 		}
@@ -160,7 +196,7 @@ func doPush(ctx context.Context, name, goroot string, dryRun, detailedProgress b
 				return filepath.SkipDir
 			}
 		}
-		inf := fileInfo{fi: fi}
+		inf := localFile{fi: fi}
 		absToRel[path] = rel
 		if fi.Mode().IsRegular() {
 			inf.sha1, err = fileSHA1(path)
@@ -171,14 +207,125 @@ func doPush(ctx context.Context, name, goroot string, dryRun, detailedProgress b
 		local[rel] = inf
 		return nil
 	}); err != nil {
-		return fmt.Errorf("error enumerating local GOROOT files: %w", err)
+		return nil, nil, fmt.Errorf("error enumerating local GOROOT files: %w", err)
 	}
 
-	ignored := make(map[string]bool)
+	ignored = make(map[string]bool)
 	for _, path := range gitIgnored(goroot, absToRel) {
 		ignored[absToRel[path]] = true
 		delete(local, absToRel[path])
 	}
+	return local, ignored, nil
+}
+
+// archive returns the archive of files, which are sorted, shared by all
+// the instances they're sent to.
+func (src *pushSource) archive(files []string) *pushArchive {
+	key := strings.Join(files, "\n")
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	a, ok := src.archives[key]
+	if !ok {
+		a = &pushArchive{files: files}
+		src.archives[key] = a
+	}
+	return a
+}
+
+// generate returns the contents of a, generating them on first use.
+func (a *pushArchive) generate(src *pushSource) ([]byte, error) {
+	a.genOnce.Do(func() {
+		var tgz *bytes.Buffer
+		tgz, a.genErr = generateDeltaTgz(src.goroot, a.files, src.gzipLevel, src.gitExec)
+		if a.genErr == nil {
+			a.tgz = tgz.Bytes()
+		}
+	})
+	return a.tgz, a.genErr
+}
+
+// upload uploads a, on first use, and returns the URL instances can
+// fetch it from. The progress of the upload is reported as that of ev.
+func (a *pushArchive) upload(ctx context.Context, ev progresstypes.Event) (string, error) {
+	a.uploadOnce.Do(func() {
+		resp, err := gomoteServerClient(ctx).UploadFile(ctx, &protos.UploadFileRequest{})
+		if err != nil {
+			a.uploadErr = fmt.Errorf("unable to request credentials for a file upload: %w", err)
+			return
+		}
+		upload := &progressReader{r: bytes.NewReader(a.tgz), ev: ev}
+		upload.ev.TotalBytes = int64(len(a.tgz))
+		upload.ev.Message = fmt.Sprintf("uploading %d new/changed files", len(a.files))
+		if err := uploadToGCS(ctx, resp.GetFields(), upload, resp.GetObjectName(), resp.GetUrl()); err != nil {
+			a.uploadErr = fmt.Errorf("unable to upload file to GCS: %w", err)
+			return
+		}
+		a.url = resp.GetUrl() + resp.GetObjectName()
+	})
+	return a.url, a.uploadErr
+}
+
+func doPush(ctx context.Context, name string, src *pushSource, dryRun, detailedProgress bool) (err error) {
+	logf := func(s string, a ...interface{}) {
+		if detailedProgress {
+			log.Printf(s, a...)
+		}
+	}
+	ev := progresstypes.Event{Phase: progresstypes.PhasePush, Instance: name}
+	emitProgress(ev)
+	defer func() { emitProgressDone(ev, err) }()
+	remote := map[string]buildlet.DirEntry{} // keys like "src/make.bash"
+
+	client := gomoteServerClient(ctx)
+	resp, err := client.ListDirectory(ctx, &protos.ListDirectoryRequest{
+		GomoteId:  name,
+		Directory: ".",
+		Recursive: true,
+		SkipFiles: []string{
+			// Ignore binary output directories:
+			"go/pkg", "go/bin",
+			// We don't care about the digest of
+			// particular source files for Go 1.4.  And
+			// exclude /pkg. This leaves go1.4/bin, which
+			// is enough to know whether we have Go 1.4 or
+			// not.
+			"go1.4/src", "go1.4/pkg",
+			// Ignore the cache and tmp directories, these slowly grow, and will
+			// eventually cause the listing to exceed the maximum gRPC message
+			// size.
+			"gocache", "goplscache", "tmp",
+		},
+		Digest: true,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing buildlet's existing files: %w", err)
+	}
+	for _, entry := range resp.GetEntries() {
+		de := buildlet.DirEntry{Line: entry}
+		en := de.Name()
+		if strings.HasPrefix(en, "go/") && en != "go/" {
+			remote[en[len("go/"):]] = de
+		}
+	}
+	// TODO(66635) remove once gomotes can no longer be created via the coordinator.
+	if luciDisabled() {
+		logf("installing go-bootstrap version in the working directory")
+		if dryRun {
+			logf("(Dry-run) Would have pushed go-bootstrap")
+		} else {
+			_, err := client.AddBootstrap(ctx, &protos.AddBootstrapRequest{
+				GomoteId: name,
+			})
+			if err != nil {
+				return fmt.Errorf("unable to add bootstrap version of Go to instance: %w", err)
+			}
+		}
+	}
+
+	local, ignored, err := src.scan()
+	if err != nil {
+		return err
+	}
 
 	var toDel []string
 	for rel := range remote {
@@ -269,25 +416,23 @@ func doPush(ctx context.Context, name, goroot string, dryRun, detailedProgress b
 	}
 	if len(toSend) > 0 {
 		sort.Strings(toSend)
-		tgz, err := generateDeltaTgz(goroot, toSend)
+		archive := src.archive(toSend)
+		tgz, err := archive.generate(src)
 		if err != nil {
 			return err
 		}
-		logf("Uploading %d new/changed files; %d byte .tar.gz", len(toSend), tgz.Len())
+		logf("Uploading %d new/changed files; %d byte .tar.gz", len(toSend), len(tgz))
 		if dryRun {
 			logf("(Dry-run mode; not doing anything.")
 			return nil
 		}
-		resp, err := client.UploadFile(ctx, &protos.UploadFileRequest{})
+		url, err := archive.upload(ctx, ev)
 		if err != nil {
-			return fmt.Errorf("unable to request credentials for a file upload: %w", err)
-		}
-		if err := uploadToGCS(ctx, resp.GetFields(), tgz, resp.GetObjectName(), resp.GetUrl()); err != nil {
-			return fmt.Errorf("unable to upload file to GCS: %w", err)
+			return err
 		}
 		if _, err := client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{
 			GomoteId:  name,
-			Url:       fmt.Sprintf("%s%s", resp.GetUrl(), resp.GetObjectName()),
+			Url:       url,
 			Directory: "go",
 		}); err != nil {
 			return fmt.Errorf("failed writing tarball to buildlet: %w", err)
@@ -325,10 +470,16 @@ func isEditorBackup(path string) bool {
 	return false
 }
 
-// file is forward-slash separated
-func generateDeltaTgz(goroot string, files []string) (*bytes.Buffer, error) {
+// generateDeltaTgz returns a .tar.gz archive of files, which are
+// forward-slash separated, of goroot. If gitExec is non-nil, the local
+// files have no executable bits, and those named in gitExec are
+// executable in the archive; see archiveMode.
+func generateDeltaTgz(goroot string, files []string, level int, gitExec map[string]bool) (*bytes.Buffer, error) {
 	var buf bytes.Buffer
-	zw := gzip.NewWriter(&buf)
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
 	tw := tar.NewWriter(zw)
 	for _, file := range files {
 		// Special.
@@ -349,7 +500,7 @@ func generateDeltaTgz(goroot string, files []string) (*bytes.Buffer, error) {
 			}
 			continue
 		}
-		f, err := os.Open(filepath.Join(goroot, file))
+		f, err := os.Open(filepath.Join(goroot, filepath.FromSlash(file)))
 		if err != nil {
 			return nil, err
 		}
@@ -364,6 +515,7 @@ func generateDeltaTgz(goroot string, files []string) (*bytes.Buffer, error) {
 			return nil, err
 		}
 		header.Name = file // forward slash
+		header.Mode = int64(archiveMode(file, fi.Mode().Perm(), gitExec))
 		if err := tw.WriteHeader(header); err != nil {
 			f.Close()
 			return nil, err
@@ -409,8 +561,102 @@ func getGOROOT() (string, error) {
 			return "", errors.New("Failed to get $GOROOT from environment or go env")
 		}
 	}
-	goroot = filepath.Clean(goroot)
-	return goroot, nil
+	return normalizeGOROOT(goroot, runtime.GOOS == "windows"), nil
+}
+
+// normalizeGOROOT cleans goroot, a path on Windows if windows is set. On
+// Windows, it also accepts forward slashes, drive letters in either case,
+// MSYS-style paths such as /c/go and extended-length paths such as
+// \\?\C:\go, and returns them, as well as UNC paths, in the canonical form
+// the rest of the system expects, such as C:\go or \\server\share\go.
+func normalizeGOROOT(goroot string, windows bool) string {
+	if !windows {
+		return path.Clean(goroot)
+	}
+	p := strings.ReplaceAll(goroot, `\`, "/")
+	switch {
+	case strings.HasPrefix(p, "//?/UNC/"):
+		p = "//" + p[len("//?/UNC/"):]
+	case strings.HasPrefix(p, "//?/"):
+		p = p[len("//?/"):]
+	}
+	isLetter := func(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' }
+	var vol string
+	switch {
+	case strings.HasPrefix(p, "//"):
+		// A UNC path, whose volume is \\server\share.
+		server, rest, _ := strings.Cut(p[len("//"):], "/")
+		share, rest, _ := strings.Cut(rest, "/")
+		vol, p = `\\`+server+`\`+share, "/"+rest
+	case len(p) >= 2 && p[1] == ':' && isLetter(p[0]):
+		vol, p = strings.ToUpper(p[:1])+":", p[2:]
+	case len(p) >= 2 && p[0] == '/' && isLetter(p[1]) && (len(p) == 2 || p[2] == '/'):
+		// An MSYS or Git Bash path, such as /c/go.
+		vol, p = strings.ToUpper(p[1:2])+":", "/"+strings.TrimPrefix(p[2:], "/")
+	}
+	return vol + strings.ReplaceAll(path.Clean(p), "/", `\`)
+}
+
+// archivePath returns the name in archives, and on instances, of rel, a
+// path relative to GOROOT whose elements are separated by sep. The names
+// are always slash-separated, whatever the local system, so that a push
+// from Windows doesn't create files named like src\runtime on instances.
+func archivePath(rel string, sep byte) (string, error) {
+	name := rel
+	if sep != '/' {
+		name = strings.ReplaceAll(rel, string(sep), "/")
+	}
+	volume := sep == '\\' && len(name) >= 2 && name[1] == ':'
+	if path.IsAbs(name) || volume || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("%q isn't a path within GOROOT", rel)
+	}
+	return path.Clean(name), nil
+}
+
+// scriptExts are the extensions of the scripts of GOROOT, which must be
+// executable on instances.
+var scriptExts = map[string]bool{
+	".bash": true,
+	".pl":   true,
+	".py":   true,
+	".rc":   true,
+	".sh":   true,
+}
+
+// archiveMode returns the permissions in archives of the file name, whose
+// local permissions are perm. A nil gitExec means that perm is accurate.
+// Otherwise the local files have no executable bits, as on Windows, and
+// the files which git records as executable, and scripts, are executable
+// even though perm says otherwise, so that make.bash runs after the push.
+func archiveMode(name string, perm fs.FileMode, gitExec map[string]bool) fs.FileMode {
+	if gitExec != nil && perm&0111 == 0 && (gitExec[name] || scriptExts[path.Ext(name)]) {
+		return 0755
+	}
+	return perm
+}
+
+// gitExecutables returns the files of goroot which the git index records
+// as executable, with keys like "src/make.bash". The map is empty, but
+// not nil, if goroot isn't a git checkout.
+func gitExecutables(goroot string) map[string]bool {
+	out, err := exec.Command("git", "-C", goroot, "ls-files", "--stage", "-z").Output()
+	if err != nil {
+		return make(map[string]bool)
+	}
+	return parseGitExecutables(out)
+}
+
+// parseGitExecutables returns the executable files listed by git ls-files
+// --stage -z, whose entries are like "100755 <object> 0\tsrc/make.bash".
+func parseGitExecutables(out []byte) map[string]bool {
+	gitExec := make(map[string]bool)
+	for _, entry := range strings.Split(string(out), "\x00") {
+		info, name, ok := strings.Cut(entry, "\t")
+		if ok && strings.HasPrefix(info, "100755 ") {
+			gitExec[name] = true
+		}
+	}
+	return gitExec
 }
 
 func localFileExists(path string) bool {
@@ -5,8 +5,15 @@
 package main
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
 	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,7 +22,12 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
 func testGOROOT(t *testing.T) string {
@@ -120,3 +132,286 @@ func TestIsGoToolDistGenerated(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// fakePushServer is a GomoteServiceClient serving pushes to instances
+// whose GOROOTs list the entries in remote, and the server its archives
+// are uploaded to.
+type fakePushServer struct {
+	protos.GomoteServiceClient
+	uploads *httptest.Server
+	remote  map[string][]string // by instance
+
+	mu       sync.Mutex
+	uploaded int            // archives
+	written  map[string]int // archives, by instance
+}
+
+func newFakePushServer(tb testing.TB, remote map[string][]string) *fakePushServer {
+	s := &fakePushServer{remote: remote, written: make(map[string]int)}
+	s.uploads = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		s.mu.Lock()
+		s.uploaded++
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tb.Cleanup(s.uploads.Close)
+	return s
+}
+
+func (s *fakePushServer) ListDirectory(ctx context.Context, req *protos.ListDirectoryRequest, _ ...grpc.CallOption) (*protos.ListDirectoryResponse, error) {
+	return &protos.ListDirectoryResponse{Entries: s.remote[req.GetGomoteId()]}, nil
+}
+
+func (s *fakePushServer) AddBootstrap(context.Context, *protos.AddBootstrapRequest, ...grpc.CallOption) (*protos.AddBootstrapResponse, error) {
+	return &protos.AddBootstrapResponse{}, nil
+}
+
+func (s *fakePushServer) UploadFile(context.Context, *protos.UploadFileRequest, ...grpc.CallOption) (*protos.UploadFileResponse, error) {
+	return &protos.UploadFileResponse{Url: s.uploads.URL + "/", ObjectName: "go.tar.gz"}, nil
+}
+
+func (s *fakePushServer) WriteTGZFromURL(ctx context.Context, req *protos.WriteTGZFromURLRequest, _ ...grpc.CallOption) (*protos.WriteTGZFromURLResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written[req.GetGomoteId()]++
+	return &protos.WriteTGZFromURLResponse{}, nil
+}
+
+// useGomoteClient makes c the client of the server for the rest of the test.
+func useGomoteClient(tb testing.TB, c protos.GomoteServiceClient) {
+	serverClientMu.Lock()
+	defer serverClientMu.Unlock()
+	old := serverClient
+	serverClient = c
+	tb.Cleanup(func() {
+		serverClientMu.Lock()
+		defer serverClientMu.Unlock()
+		serverClient = old
+	})
+}
+
+// syntheticGitGOROOT is like syntheticGOROOT, but the GOROOT is a git
+// repository, if git is installed, as it is for most users of push.
+func syntheticGitGOROOT(tb testing.TB, n int) (string, []string) {
+	goroot, files := syntheticGOROOT(tb, n)
+	exec.Command("git", "init", "-q", goroot).Run()
+	return goroot, files
+}
+
+// pushAll pushes GOROOT from src to insts concurrently, or from a source
+// of its own for each instance if src is nil.
+func pushAll(ctx context.Context, goroot string, src *pushSource, insts []string) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, inst := range insts {
+		inst := inst
+		eg.Go(func() error {
+			src := src
+			if src == nil {
+				src = newPushSource(goroot, gzip.DefaultCompression)
+			}
+			return doPush(ctx, inst, src, false, false)
+		})
+	}
+	return eg.Wait()
+}
+
+func TestPushSharesArchives(t *testing.T) {
+	goroot, files := syntheticGitGOROOT(t, 20)
+	// a and b are new, and c already has all the files but one.
+	var c []string
+	for _, f := range files[1:] {
+		sum, err := fileSHA1(filepath.Join(goroot, filepath.FromSlash(f)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c = append(c, fmt.Sprintf("-rw-r--r--\tgo/%s\t16384\t2024-01-01T00:00:00Z\t%s", f, sum))
+	}
+	c = append(c, "-rw-r--r--\tgo/VERSION\t5\t2024-01-01T00:00:00Z\tsum")
+	srv := newFakePushServer(t, map[string][]string{"c": c})
+	useGomoteClient(t, srv)
+
+	src := newPushSource(goroot, gzip.DefaultCompression)
+	if err := pushAll(context.Background(), goroot, src, []string{"a", "b", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if srv.uploaded != 2 {
+		t.Errorf("pushing to 2 new instances and 1 with a different GOROOT uploaded %d archives; want 2", srv.uploaded)
+	}
+	for _, inst := range []string{"a", "b", "c"} {
+		if srv.written[inst] != 1 {
+			t.Errorf("pushing wrote %d archives to %s; want 1", srv.written[inst], inst)
+		}
+	}
+	if len(src.archives) != 2 {
+		t.Errorf("pushing generated %d archives; want 2", len(src.archives))
+	}
+	for _, a := range src.archives {
+		if got, want := len(a.files), len(files)+1; got != want && got != 1 {
+			t.Errorf("archive of %d files; want %d (with VERSION) for the new instances, or 1 for the other one", got, want)
+		}
+	}
+}
+
+// BenchmarkPushInstances measures pushing a synthetic GOROOT to new
+// instances of a local fake server at once, with a source shared by all
+// the instances, as push, shell and create -setup do, and with a source
+// for each instance, as they used to.
+func BenchmarkPushInstances(b *testing.B) {
+	goroot, _ := syntheticGitGOROOT(b, 500)
+	insts := []string{"inst-0", "inst-1", "inst-2", "inst-3", "inst-4", "inst-5", "inst-6", "inst-7"}
+	srv := newFakePushServer(b, nil)
+	useGomoteClient(b, srv)
+	ctx := context.Background()
+
+	for _, shared := range []bool{true, false} {
+		b.Run(fmt.Sprintf("shared=%t", shared), func(b *testing.B) {
+			srv.mu.Lock()
+			srv.uploaded = 0
+			srv.mu.Unlock()
+			for i := 0; i < b.N; i++ {
+				var src *pushSource
+				if shared {
+					src = newPushSource(goroot, gzip.DefaultCompression)
+				}
+				if err := pushAll(ctx, goroot, src, insts); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(srv.uploaded)/float64(b.N), "archives/op")
+		})
+	}
+}
+
+func TestArchivePath(t *testing.T) {
+	for _, tc := range []struct {
+		rel  string
+		sep  byte
+		want string // or "" if rel is invalid
+	}{
+		{`src\runtime\proc.go`, '\\', "src/runtime/proc.go"},
+		{`src\make.bash`, '\\', "src/make.bash"},
+		{`.`, '\\', "."},
+		{`misc\.\wasm\go_js_wasm_exec`, '\\', "misc/wasm/go_js_wasm_exec"},
+		{`..\other\src`, '\\', ""},
+		{`C:\go\src`, '\\', ""},
+		{`\go\src`, '\\', ""},
+		{"src/runtime/proc.go", '/', "src/runtime/proc.go"},
+		{`src/weird\name.go`, '/', `src/weird\name.go`},
+		{"/usr/local/go", '/', ""},
+	} {
+		got, err := archivePath(tc.rel, tc.sep)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("archivePath(%q, %q) = %q; want error", tc.rel, tc.sep, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("archivePath(%q, %q) = %q, %v; want %q", tc.rel, tc.sep, got, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizeGOROOT(t *testing.T) {
+	for _, tc := range []struct {
+		goroot  string
+		windows bool
+		want    string
+	}{
+		{`C:\Program Files\Go`, true, `C:\Program Files\Go`},
+		{`c:\go\`, true, `C:\go`},
+		{`c:/Users/gopher/sdk/go/../gotip`, true, `C:\Users\gopher\sdk\gotip`},
+		{`/c/Users/gopher/go`, true, `C:\Users\gopher\go`},
+		{`\\?\C:\go`, true, `C:\go`},
+		{`\\fileserver\share\go\`, true, `\\fileserver\share\go`},
+		{`//fileserver/share/go`, true, `\\fileserver\share\go`},
+		{`\\?\UNC\fileserver\share\go`, true, `\\fileserver\share\go`},
+		{"/usr/local/go/", false, "/usr/local/go"},
+		{"/home/gopher/sdk/go/../gotip", false, "/home/gopher/sdk/gotip"},
+	} {
+		if got := normalizeGOROOT(tc.goroot, tc.windows); got != tc.want {
+			t.Errorf("normalizeGOROOT(%q, %t) = %q; want %q", tc.goroot, tc.windows, got, tc.want)
+		}
+	}
+}
+
+func TestParseGitExecutables(t *testing.T) {
+	out := "100644 5b2e0a3d0b6f7fe6d3b5c1c0a3a1f3c2b3d4e5f6 0\tsrc/runtime/proc.go\x00" +
+		"100755 7c1e0a3d0b6f7fe6d3b5c1c0a3a1f3c2b3d4e5f6 0\tsrc/make.bash\x00" +
+		"100755 8d2e0a3d0b6f7fe6d3b5c1c0a3a1f3c2b3d4e5f6 0\tmisc/wasm/go_js_wasm_exec\x00" +
+		"120000 9e3e0a3d0b6f7fe6d3b5c1c0a3a1f3c2b3d4e5f6 0\tsrc/link\x00"
+	want := map[string]bool{"src/make.bash": true, "misc/wasm/go_js_wasm_exec": true}
+	if got := parseGitExecutables([]byte(out)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGitExecutables = %v; want %v", got, want)
+	}
+}
+
+// TestGenerateDeltaTgzWindows checks the archive pushed from a Windows
+// GOROOT, whose paths are separated by backslashes and whose files have
+// no executable bits.
+func TestGenerateDeltaTgzWindows(t *testing.T) {
+	goroot := t.TempDir()
+	var files []string
+	for _, rel := range []string{`src\make.bash`, `src\runtime\proc.go`, `misc\wasm\go_js_wasm_exec`, `src\all.bat`} {
+		name, err := archivePath(rel, '\\')
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(goroot, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, name)
+	}
+	gitExec := map[string]bool{"misc/wasm/go_js_wasm_exec": true}
+	tgz, err := generateDeltaTgz(goroot, files, gzip.BestSpeed, gitExec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := gzip.NewReader(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool) // whether each file is executable
+	tr := tar.NewReader(zr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got[h.Name] = h.Mode&0111 != 0
+	}
+	want := map[string]bool{
+		"src/make.bash":             true,
+		"src/runtime/proc.go":       false,
+		"misc/wasm/go_js_wasm_exec": true,
+		"src/all.bat":               false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("archive has files (executable) %v; want %v", got, want)
+	}
+}
+
+func TestArchiveModeExecBits(t *testing.T) {
+	// Without gitExec, the local permissions are accurate, as on Unix.
+	for _, tc := range []struct {
+		name string
+		perm fs.FileMode
+	}{
+		{"src/make.bash", 0755},
+		{"src/run.sh", 0644},
+		{"src/testdata/script.py", 0644},
+		{"src/runtime/proc.go", 0644},
+	} {
+		if got := archiveMode(tc.name, tc.perm, nil); got != tc.perm {
+			t.Errorf("archiveMode(%q, %v, nil) = %v; want %v", tc.name, tc.perm, got, tc.perm)
+		}
+	}
+}
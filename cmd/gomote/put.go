@@ -21,31 +21,19 @@ import (
 	"strconv"
 	"strings"
 
+	"golang.org/x/build/cmd/gomote/progresstypes"
 	"golang.org/x/build/internal/gomote/protos"
 	"golang.org/x/build/tarutil"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // putTar a .tar.gz
 func putTar(args []string) error {
-	fs := flag.NewFlagSet("put", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "puttar usage: gomote puttar [put-opts] [instance] <source>")
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "<source> may be one of:")
-		fmt.Fprintln(os.Stderr, "- A path to a local .tar.gz file.")
-		fmt.Fprintln(os.Stderr, "- A URL that points at a .tar.gz file.")
-		fmt.Fprintln(os.Stderr, "- The '-' character to indicate a .tar.gz file passed via stdin.")
-		fmt.Fprintln(os.Stderr, "- Git hash (min 7 characters) for the Go repository (extract a .tar.gz of the repository at that commit w/o history)")
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	var dir string
-	fs.StringVar(&dir, "dir", "", "relative directory from buildlet's work dir to extra tarball into")
-
-	fs.Parse(args)
+	var flags putTarFlags
+	fs := putTarFlagSet(&flags)
+	parseFlags(fs, args)
 
 	// Parse arguments.
 	var putSet []string
@@ -63,7 +51,11 @@ func putTar(args []string) error {
 		src = fs.Arg(0)
 	case 2:
 		// Instance and source is specified.
-		putSet = []string{fs.Arg(0)}
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		putSet = []string{inst}
 		src = fs.Arg(1)
 	case 0:
 		fmt.Fprintln(os.Stderr, "error: not enough arguments")
@@ -75,6 +67,7 @@ func putTar(args []string) error {
 
 	// Interpret source.
 	var putTarFn func(ctx context.Context, inst string) error
+	isURL := false
 	if src == "-" {
 		// We might have multiple readers, so slurp up STDIN
 		// and store it, then hand out bytes.Readers to everyone.
@@ -85,19 +78,20 @@ func putTar(args []string) error {
 		}
 		sharedTarBuf := buf.Bytes()
 		putTarFn = func(ctx context.Context, inst string) error {
-			return doPutTar(ctx, inst, dir, bytes.NewReader(sharedTarBuf))
+			return doPutTar(ctx, inst, flags.dir, bytes.NewReader(sharedTarBuf))
 		}
 	} else {
 		u, err := url.Parse(src)
 		if err != nil {
 			// The URL parser should technically accept any of these, so the fact that
 			// we failed means its *very* malformed.
-			return fmt.Errorf("malformed source: not a path, a URL, -, or a git hash")
+			return usageErrorf("malformed source: not a path, a URL, -, or a git hash")
 		}
 		if u.Scheme != "" || u.Host != "" {
 			// Probably a real URL.
+			isURL = true
 			putTarFn = func(ctx context.Context, inst string) error {
-				return doPutTarURL(ctx, inst, dir, u.String())
+				return doPutTarURL(ctx, inst, flags.dir, u.String(), flags.sha256, flags.urlHeaders)
 			}
 		} else {
 			// Probably a path. Check if it exists.
@@ -105,10 +99,10 @@ func putTar(args []string) error {
 			if os.IsNotExist(err) {
 				// It must be a git hash. Check if this actually matches a git hash.
 				if len(src) < 7 || len(src) > 40 || regexp.MustCompile("[^a-f0-9]").MatchString(src) {
-					return fmt.Errorf("malformed source: not a path, a URL, -, or a git hash")
+					return usageErrorf("malformed source: not a path, a URL, -, or a git hash")
 				}
 				putTarFn = func(ctx context.Context, inst string) error {
-					return doPutTarGoRev(ctx, inst, dir, src)
+					return doPutTarGoRev(ctx, inst, flags.dir, src)
 				}
 			} else if err != nil {
 				return fmt.Errorf("failed to stat %q: %w", src, err)
@@ -120,11 +114,14 @@ func putTar(args []string) error {
 						return fmt.Errorf("opening %q: %w", src, err)
 					}
 					defer f.Close()
-					return doPutTar(ctx, inst, dir, f)
+					return doPutTar(ctx, inst, flags.dir, f)
 				}
 			}
 		}
 	}
+	if !isURL && (flags.sha256 != "" || len(flags.urlHeaders) > 0) {
+		return usageErrorf("-sha256 and -url-header may only be used with a URL source")
+	}
 	eg, ctx := errgroup.WithContext(context.Background())
 	for _, inst := range putSet {
 		inst := inst
@@ -135,12 +132,48 @@ func putTar(args []string) error {
 	return eg.Wait()
 }
 
-func doPutTarURL(ctx context.Context, name, dir, tarURL string) error {
+// putTarFlags are the flags of the puttar command.
+type putTarFlags struct {
+	dir        string
+	sha256     string
+	urlHeaders urlHeaderFlag
+}
+
+func putTarFlagSet(flags *putTarFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("puttar", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "puttar usage: gomote puttar [put-opts] [instance] <source>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "<source> may be one of:")
+		fmt.Fprintln(os.Stderr, "- A path to a local .tar.gz file.")
+		fmt.Fprintln(os.Stderr, "- A URL that points at a .tar.gz file.")
+		fmt.Fprintln(os.Stderr, "- The '-' character to indicate a .tar.gz file passed via stdin.")
+		fmt.Fprintln(os.Stderr, "- Git hash (min 7 characters) for the Go repository (extract a .tar.gz of the repository at that commit w/o history)")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fmt.Fprintln(os.Stderr, "A URL which requires credentials may be fetched with -url-header, such as")
+		fmt.Fprintln(os.Stderr, "-url-header \"Authorization: Bearer $TOKEN\"; verify what it serves with -sha256.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.dir, "dir", "", "relative directory from buildlet's work dir to extra tarball into")
+	fs.StringVar(&flags.sha256, "sha256", "", "expected hex-encoded SHA-256 checksum of the .tar.gz file at the URL source")
+	fs.Var(&flags.urlHeaders, "url-header", urlHeaderUsage)
+	return fs
+}
+
+// doPutTarURL writes the .tar.gz file at tarURL to dir on the instance
+// name. If wantSHA256 is set, the instance verifies the file has that
+// hex-encoded SHA-256 checksum. It sends headers with the request fetching
+// tarURL.
+func doPutTarURL(ctx context.Context, name, dir, tarURL, wantSHA256 string, headers []*protos.HTTPHeader) error {
 	client := gomoteServerClient(ctx)
 	_, err := client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{
-		GomoteId:  name,
-		Directory: dir,
-		Url:       tarURL,
+		GomoteId:   name,
+		Directory:  dir,
+		Url:        tarURL,
+		Sha256:     wantSHA256,
+		UrlHeaders: headers,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to write tar to instance: %w", err)
@@ -150,7 +183,7 @@ func doPutTarURL(ctx context.Context, name, dir, tarURL string) error {
 
 func doPutTarGoRev(ctx context.Context, name, dir, rev string) error {
 	tarURL := "https://go.googlesource.com/go/+archive/" + rev + ".tar.gz"
-	if err := doPutTarURL(ctx, name, dir, tarURL); err != nil {
+	if err := doPutTarURL(ctx, name, dir, tarURL, "", nil); err != nil {
 		return err
 	}
 
@@ -204,15 +237,9 @@ func doPutTar(ctx context.Context, name, dir string, tgz io.Reader) error {
 
 // putBootstrap places the bootstrap version of go in the workdir
 func putBootstrap(args []string) error {
-	fs := flag.NewFlagSet("putbootstrap", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "putbootstrap usage: gomote putbootstrap [instance]")
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	fs.Parse(args)
+	var flags putBootstrapFlags
+	fs := putBootstrapFlagSet(&flags)
+	parseFlags(fs, args)
 
 	var putSet []string
 	switch fs.NArg() {
@@ -225,11 +252,21 @@ func putBootstrap(args []string) error {
 			putSet = append(putSet, inst)
 		}
 	case 1:
-		putSet = []string{fs.Arg(0)}
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		putSet = []string{inst}
 	default:
 		fmt.Fprintln(os.Stderr, "error: too many arguments")
 		fs.Usage()
 	}
+	if flags.version != "" && flags.url != "" {
+		return usageErrorf("-version and -url are mutually exclusive")
+	}
+	if len(flags.urlHeaders) > 0 && flags.url == "" {
+		return usageErrorf("-url-header may only be used with -url")
+	}
 
 	eg, ctx := errgroup.WithContext(context.Background())
 	for _, inst := range putSet {
@@ -237,16 +274,14 @@ func putBootstrap(args []string) error {
 		eg.Go(func() error {
 			// TODO(66635) remove once gomotes can no longer be created via the coordinator.
 			if luciDisabled() {
-				client := gomoteServerClient(ctx)
-				resp, err := client.AddBootstrap(ctx, &protos.AddBootstrapRequest{
-					GomoteId: inst,
+				_, err := doPutBootstrap(ctx, &protos.AddBootstrapRequest{
+					GomoteId:   inst,
+					Version:    flags.version,
+					Url:        flags.url,
+					Sha256:     flags.sha256,
+					UrlHeaders: flags.urlHeaders,
 				})
-				if err != nil {
-					return fmt.Errorf("unable to add bootstrap version of Go to instance: %w", err)
-				}
-				if resp.GetBootstrapGoUrl() == "" {
-					fmt.Printf("No GoBootstrapURL defined for %q; ignoring. (may be baked into image)\n", inst)
-				}
+				return err
 			}
 			return nil
 		})
@@ -254,18 +289,144 @@ func putBootstrap(args []string) error {
 	return eg.Wait()
 }
 
-// put single file
-func put(args []string) error {
-	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+// doPutBootstrap installs the bootstrap version of Go requested by req,
+// reporting the progress of the installation as it goes. It returns the
+// final update, which says where the bootstrap version of Go was installed
+// from and in, if it was.
+func doPutBootstrap(ctx context.Context, req *protos.AddBootstrapRequest) (_ *protos.InstallBootstrapResponse, err error) {
+	inst := req.GetGomoteId()
+	ev := progresstypes.Event{Phase: progresstypes.PhaseBootstrap, Instance: inst}
+	emitProgress(ev)
+	defer func() { emitProgressDone(ev, err) }()
+
+	client := gomoteServerClient(ctx)
+	stream, err := client.InstallBootstrap(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to add bootstrap version of Go to instance: %w", err)
+	}
+	var last protos.InstallBootstrapResponse_Phase
+	for {
+		update, err := stream.Recv()
+		if status.Code(err) == codes.Unimplemented {
+			// The server predates InstallBootstrap.
+			if req.GetVersion() != "" || req.GetUrl() != "" || req.GetSha256() != "" || len(req.GetUrlHeaders()) > 0 {
+				return nil, fmt.Errorf("the server doesn't support -version, -url, -url-header or -sha256; omit them to install its default bootstrap version of Go")
+			}
+			resp, err := client.AddBootstrap(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("unable to add bootstrap version of Go to instance: %w", err)
+			}
+			update = &protos.InstallBootstrapResponse{
+				Phase:          protos.InstallBootstrapResponse_COMPLETE,
+				BootstrapGoUrl: resp.GetBootstrapGoUrl(),
+			}
+		} else if err == io.EOF {
+			return nil, fmt.Errorf("unable to add bootstrap version of Go to instance: stream ended before completion")
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to add bootstrap version of Go to instance: %w", err)
+		}
+		msg := bootstrapProgress(update)
+		pev := ev
+		pev.Bytes, pev.TotalBytes, pev.Message = update.GetBytesDone(), update.GetBytesTotal(), msg
+		if pev.TotalBytes > 0 {
+			pev.Percent = 100 * float64(pev.Bytes) / float64(pev.TotalBytes)
+		}
+		if update.GetPhase() == protos.InstallBootstrapResponse_COMPLETE {
+			ev.Message = msg
+			if update.GetBootstrapGoUrl() == "" {
+				fmt.Printf("No GoBootstrapURL defined for %q; ignoring. (may be baked into image)\n", inst)
+			} else {
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# %s: %s", styles.Instance(inst), msg)))
+			}
+			return update, nil
+		}
+		emitProgress(pev)
+		// Phases of unknown size are only reported once.
+		if update.GetPhase() != last || update.GetBytesTotal() > 0 {
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# %s: %s", styles.Instance(inst), msg)))
+		}
+		last = update.GetPhase()
+	}
+}
+
+// bootstrapProgress describes an update on the installation of the
+// bootstrap version of Go.
+func bootstrapProgress(update *protos.InstallBootstrapResponse) string {
+	var msg string
+	switch update.GetPhase() {
+	case protos.InstallBootstrapResponse_DOWNLOADING:
+		msg = "downloading bootstrap Go"
+	case protos.InstallBootstrapResponse_EXTRACTING:
+		msg = "extracting bootstrap Go"
+	case protos.InstallBootstrapResponse_COMPLETE:
+		if update.GetBootstrapGoUrl() == "" {
+			return "no bootstrap Go needed"
+		}
+		return fmt.Sprintf("installed bootstrap Go from %s in $WORKDIR/%s", update.GetBootstrapGoUrl(), update.GetDirectory())
+	default:
+		msg = "installing bootstrap Go"
+	}
+	if total := update.GetBytesTotal(); total > 0 {
+		return fmt.Sprintf("%s... %d%%", msg, 100*update.GetBytesDone()/total)
+	}
+	return msg + "..."
+}
+
+type putBootstrapFlags struct {
+	version    string
+	url        string
+	sha256     string
+	urlHeaders urlHeaderFlag
+}
+
+func putBootstrapFlagSet(flags *putBootstrapFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("putbootstrap", flag.ContinueOnError)
 	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "put usage: gomote put [put-opts] [instance] <source or '-' for stdin> [destination]")
+		fmt.Fprintln(os.Stderr, "putbootstrap usage: gomote putbootstrap [putbootstrap-opts] [instance]")
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fmt.Fprintln(os.Stderr, "Without -version or -url, the instance's default bootstrap version of Go is installed.")
 		fs.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.version, "version", "", "version of Go to install as the bootstrap toolchain, such as 1.22.6, built for the instance's GOOS and GOARCH")
+	fs.StringVar(&flags.url, "url", "", "URL of a .tar.gz archive of the bootstrap toolchain, hosted by the Go project")
+	fs.StringVar(&flags.sha256, "sha256", "", "expected hex-encoded SHA-256 checksum of the bootstrap toolchain archive")
+	fs.Var(&flags.urlHeaders, "url-header", urlHeaderUsage)
+	return fs
+}
+
+const urlHeaderUsage = `header "Name: value" to send with the request fetching the URL, such as credentials; may be repeated. Only some headers, such as Authorization, are allowed, and only with https URLs`
+
+// urlHeaderFlag is a repeatable flag of the headers sent with the request
+// fetching a URL.
+type urlHeaderFlag []*protos.HTTPHeader
+
+// String returns the names of the headers, without their values, which
+// are usually credentials.
+func (f *urlHeaderFlag) String() string {
+	var names []string
+	for _, h := range *f {
+		names = append(names, h.GetName())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (f *urlHeaderFlag) Set(v string) error {
+	name, value, ok := strings.Cut(v, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return errors.New(`want a header of the form "Name: value"`)
 	}
-	modeStr := fs.String("mode", "", "Unix file mode (octal); default to source file mode")
-	fs.Parse(args)
+	*f = append(*f, &protos.HTTPHeader{Name: name, Value: strings.TrimSpace(value)})
+	return nil
+}
+
+// put single file
+func put(args []string) error {
+	var flags putFlags
+	fs := putFlagSet(&flags)
+	parseFlags(fs, args)
 
 	if fs.NArg() == 0 {
 		fs.Usage()
@@ -274,10 +435,21 @@ func put(args []string) error {
 	ctx := context.Background()
 	var putSet []string
 	var src, dst string
-	if err := doPing(ctx, fs.Arg(0)); instanceDoesNotExist(err) {
+	// Instance names may only be abbreviated without an active group,
+	// since otherwise the first argument may not be an instance at all.
+	name := fs.Arg(0)
+	var err error
+	if activeGroup == nil {
+		if name, err = resolveInstance(ctx, name); err != nil {
+			return err
+		}
+	} else if name, err = resolveAlias(name); err != nil {
+		return err
+	}
+	if err := doPing(ctx, name); instanceDoesNotExist(err) {
 		// When there's no active group, this is just an error.
 		if activeGroup == nil {
-			return fmt.Errorf("instance %q: %w", fs.Arg(0), err)
+			return fmt.Errorf("instance %q: %w", name, err)
 		}
 		// When there is an active group, this just means that we're going
 		// to use the group instead and assume the rest is a command.
@@ -292,7 +464,7 @@ func put(args []string) error {
 			fs.Usage()
 		}
 	} else if err == nil {
-		putSet = append(putSet, fs.Arg(0))
+		putSet = append(putSet, name)
 		if fs.NArg() == 1 {
 			fmt.Fprintln(os.Stderr, "error: missing source")
 			fs.Usage()
@@ -305,24 +477,24 @@ func put(args []string) error {
 			fs.Usage()
 		}
 	} else {
-		return fmt.Errorf("checking instance %q: %w", fs.Arg(0), err)
+		return fmt.Errorf("checking instance %q: %w", name, err)
 	}
 	if dst == "" {
 		if src == "-" {
-			return errors.New("must specify destination file name when source is standard input")
+			return usageErrorf("must specify destination file name when source is standard input")
 		}
 		dst = filepath.Base(src)
 	}
 
 	var mode os.FileMode = 0666
-	if *modeStr != "" {
-		modeInt, err := strconv.ParseInt(*modeStr, 8, 64)
+	if flags.modeStr != "" {
+		modeInt, err := strconv.ParseInt(flags.modeStr, 8, 64)
 		if err != nil {
 			return err
 		}
 		mode = os.FileMode(modeInt)
 		if !mode.IsRegular() {
-			return fmt.Errorf("bad mode: %v", mode)
+			return usageErrorf("bad mode: %v", mode)
 		}
 	}
 
@@ -345,7 +517,7 @@ func put(args []string) error {
 			}
 			defer f.Close()
 
-			if *modeStr == "" {
+			if flags.modeStr == "" {
 				fi, err := f.Stat()
 				if err != nil {
 					return err
@@ -366,6 +538,24 @@ func put(args []string) error {
 	return eg.Wait()
 }
 
+// putFlags are the flags of the put command.
+type putFlags struct {
+	modeStr string
+}
+
+func putFlagSet(flags *putFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "put usage: gomote put [put-opts] [instance] <source or '-' for stdin> [destination]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.StringVar(&flags.modeStr, "mode", "", "Unix file mode (octal); default to source file mode")
+	return fs
+}
+
 func doPutFile(ctx context.Context, inst string, r io.Reader, dst string, mode os.FileMode) error {
 	client := gomoteServerClient(ctx)
 	resp, err := client.UploadFile(ctx, &protos.UploadFileRequest{})
@@ -408,7 +598,7 @@ func uploadToGCS(ctx context.Context, fields map[string]string, file io.Reader,
 		return fmt.Errorf("unable to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", mw.FormDataContentType())
-	res, err := http.DefaultClient.Do(req)
+	res, err := uploadClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("http request failed: %w", err)
 	}
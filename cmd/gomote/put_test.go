@@ -0,0 +1,75 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestBootstrapProgress(t *testing.T) {
+	for _, tc := range []struct {
+		update *protos.InstallBootstrapResponse
+		want   string
+	}{
+		{
+			&protos.InstallBootstrapResponse{Phase: protos.InstallBootstrapResponse_DOWNLOADING, BytesDone: 45, BytesTotal: 100},
+			"downloading bootstrap Go... 45%",
+		},
+		{
+			&protos.InstallBootstrapResponse{Phase: protos.InstallBootstrapResponse_DOWNLOADING, BytesDone: 45},
+			"downloading bootstrap Go...",
+		},
+		{
+			&protos.InstallBootstrapResponse{Phase: protos.InstallBootstrapResponse_EXTRACTING, BytesDone: 100, BytesTotal: 100},
+			"extracting bootstrap Go... 100%",
+		},
+		{
+			&protos.InstallBootstrapResponse{Phase: protos.InstallBootstrapResponse_COMPLETE, BootstrapGoUrl: "https://go.dev/dl/go1.22.6.linux-amd64.tar.gz", Directory: "go1.4"},
+			"installed bootstrap Go from https://go.dev/dl/go1.22.6.linux-amd64.tar.gz in $WORKDIR/go1.4",
+		},
+		{
+			&protos.InstallBootstrapResponse{Phase: protos.InstallBootstrapResponse_COMPLETE},
+			"no bootstrap Go needed",
+		},
+	} {
+		if got := bootstrapProgress(tc.update); got != tc.want {
+			t.Errorf("bootstrapProgress(%v) = %q; want %q", tc.update, got, tc.want)
+		}
+	}
+}
+
+func TestURLHeaderFlag(t *testing.T) {
+	var flags putTarFlags
+	fs := putTarFlagSet(&flags)
+	err := fs.Parse([]string{
+		"-url-header", "Authorization: Bearer secret",
+		"-url-header", "X-JFrog-Art-Api:key",
+		"-sha256", "abc",
+		"https://example.com/go.tar.gz",
+	})
+	if err != nil {
+		t.Fatalf("parsing puttar flags: %v", err)
+	}
+	want := urlHeaderFlag{
+		{Name: "Authorization", Value: "Bearer secret"},
+		{Name: "X-JFrog-Art-Api", Value: "key"},
+	}
+	if diff := cmp.Diff(want, flags.urlHeaders, protocmp.Transform()); diff != "" {
+		t.Errorf("-url-header mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := flags.urlHeaders.String(), "Authorization, X-JFrog-Art-Api"; got != want {
+		t.Errorf("urlHeaderFlag.String() = %q; want %q", got, want)
+	}
+	for _, v := range []string{"Authorization", ": secret", "Bad Name: secret"} {
+		var f urlHeaderFlag
+		if err := f.Set(v); err == nil {
+			t.Errorf("urlHeaderFlag.Set(%q) = nil; want error", v)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/build/cmd/gomote/internal/builderlist"
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+// resolveInstance resolves name, which may be an unambiguous prefix or
+// substring of the name of one of the user's instances, to the full name
+// of that instance. Aliases are resolved first. See matchInstance for the
+// details.
+func resolveInstance(ctx context.Context, name string) (string, error) {
+	if inst, err := resolveAlias(name); err != nil {
+		return "", err
+	} else if inst != name {
+		return inst, nil
+	}
+	client := gomoteServerClient(ctx)
+	resp, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+	if err != nil {
+		return "", fmt.Errorf("unable to list instances: %w", err)
+	}
+	var names []string
+	for _, inst := range resp.GetInstances() {
+		names = append(names, inst.GetGomoteId())
+	}
+	var group []string
+	if activeGroup != nil {
+		group = activeGroup.Instances
+	}
+	resolved, err := matchInstance(name, names, group)
+	if err != nil {
+		return "", err
+	}
+	if resolved != name {
+		fmt.Fprintf(os.Stderr, "# Using instance %s\n", resolved)
+	}
+	return resolved, nil
+}
+
+// matchInstance returns the instance in instances which name refers to.
+//
+// An exact match always wins. Otherwise, name may be a prefix of an
+// instance name or, failing that, a substring of one. If several instances
+// match, the instances which are also in group are preferred. If the match
+// is still ambiguous, an error listing the candidates is returned.
+//
+// If no instance matches, name is returned unchanged, so that commands
+// report that the instance does not exist as they usually do.
+func matchInstance(name string, instances, group []string) (string, error) {
+	var prefix, substr []string
+	for _, inst := range instances {
+		switch {
+		case inst == name:
+			return inst, nil
+		case strings.HasPrefix(inst, name):
+			prefix = append(prefix, inst)
+		case strings.Contains(inst, name):
+			substr = append(substr, inst)
+		}
+	}
+	candidates := prefix
+	if len(candidates) == 0 {
+		candidates = substr
+	}
+	switch len(candidates) {
+	case 0:
+		return name, nil
+	case 1:
+		return candidates[0], nil
+	}
+	var inGroup []string
+	for _, inst := range candidates {
+		for _, g := range group {
+			if inst == g {
+				inGroup = append(inGroup, inst)
+				break
+			}
+		}
+	}
+	if len(inGroup) == 1 {
+		return inGroup[0], nil
+	}
+	return "", usageErrorf("instance name %q is ambiguous; it matches:\n\t%s", name, strings.Join(candidates, "\n\t"))
+}
+
+// resolveBuilderType resolves name, which may be a prefix of the name of a
+// builder type, to the full name of that builder type. See
+// matchBuilderType for the details. If the builder types can't be listed,
+// name is returned unchanged. If the builder type was retired, an error
+// saying what replaces it is returned; if it's deprecated, a warning is
+// printed.
+func resolveBuilderType(ctx context.Context, name string) (string, error) {
+	c := builderListClient()
+	// Resolving against the built-in snapshot could pick a builder
+	// type which doesn't exist anymore.
+	c.UseSnapshot = false
+	bts, err := c.List(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# Unable to list the builder types to resolve %q: %v", name, err)))
+		return name, nil
+	}
+	resolved, err := matchBuilderType(name, bts)
+	if err != nil {
+		return "", err
+	}
+	if resolved != name {
+		fmt.Fprintf(os.Stderr, "# Using builder type %s\n", resolved)
+	}
+	if r, err := c.Retirement(ctx, resolved); err == nil && r != nil {
+		if r.Retired {
+			return "", usageErrorf("%v", r)
+		}
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# warning: %v", r)))
+	}
+	return resolved, nil
+}
+
+// warnRetiredBuilderType prints a warning if builderType was retired or is
+// deprecated, which may be why creating instances of it failed. It's
+// silent if that can't be determined.
+func warnRetiredBuilderType(builderType string) {
+	ctx, cancel := context.WithTimeout(context.Background(), builderTypesTimeout)
+	defer cancel()
+	c := builderListClient()
+	c.Logf = nil
+	c.UseSnapshot = false
+	if r, err := c.Retirement(ctx, builderType); err == nil && r != nil {
+		fmt.Fprintln(os.Stderr, styles.Warning(fmt.Sprintf("# %v", r)))
+	}
+}
+
+// matchBuilderType returns the builder type in bts which name refers to.
+//
+// An exact match always wins. Otherwise, name may be a prefix of builder
+// type names, such as "linux-amd64" for "linux-amd64-bookworm", in which
+// case the only one which isn't deprecated is chosen. If there are several
+// such builder types, or only deprecated ones, an error listing the
+// candidates is returned.
+//
+// If no builder type matches, name is returned unchanged, so that the
+// server reports that the builder type does not exist as it usually does.
+func matchBuilderType(name string, bts []builderlist.Builder) (string, error) {
+	var candidates, current []string
+	for _, bt := range bts {
+		switch {
+		case bt.Name == name:
+			return name, nil
+		case strings.HasPrefix(bt.Name, name):
+			if bt.Deprecated {
+				candidates = append(candidates, bt.Name+" (deprecated)")
+				continue
+			}
+			candidates = append(candidates, bt.Name)
+			current = append(current, bt.Name)
+		}
+	}
+	switch {
+	case len(candidates) == 0:
+		return name, nil
+	case len(current) == 1:
+		return current[0], nil
+	case len(current) == 0:
+		return "", usageErrorf("builder type %q only matches deprecated builder types; use one of them with -no-resolve:\n\t%s", name, strings.Join(candidates, "\n\t"))
+	}
+	return "", usageErrorf("builder type %q is ambiguous; it matches:\n\t%s", name, strings.Join(candidates, "\n\t"))
+}
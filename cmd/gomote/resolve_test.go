@@ -0,0 +1,147 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/build/cmd/gomote/internal/builderlist"
+)
+
+func TestMatchInstance(t *testing.T) {
+	instances := []string{
+		"user-foo-linux-amd64-0",
+		"user-foo-linux-amd64-1",
+		"user-foo-linux-amd64-10",
+		"user-foo-windows-amd64-0",
+	}
+	testCases := []struct {
+		desc  string
+		name  string
+		group []string
+		want  string
+	}{
+		{"exact", "user-foo-linux-amd64-0", nil, "user-foo-linux-amd64-0"},
+		{"exact and prefix of another", "user-foo-linux-amd64-1", nil, "user-foo-linux-amd64-1"},
+		{"prefix", "user-foo-win", nil, "user-foo-windows-amd64-0"},
+		{"substring", "windows", nil, "user-foo-windows-amd64-0"},
+		{"unique substring", "amd64-10", nil, "user-foo-linux-amd64-10"},
+		{"ambiguous prefix resolved by group", "user-foo-linux", []string{"user-foo-linux-amd64-1", "user-foo-windows-amd64-0"}, "user-foo-linux-amd64-1"},
+		{"ambiguous substring resolved by group", "amd64-0", []string{"user-foo-windows-amd64-0"}, "user-foo-windows-amd64-0"},
+		{"no match", "darwin", nil, "darwin"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := matchInstance(tc.name, instances, tc.group)
+			if err != nil {
+				t.Fatalf("matchInstance(%q) = %v; want no error", tc.name, err)
+			}
+			if got != tc.want {
+				t.Errorf("matchInstance(%q) = %q; want %q", tc.name, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchInstanceAmbiguous(t *testing.T) {
+	instances := []string{
+		"user-foo-linux-amd64-0",
+		"user-foo-linux-amd64-1",
+		"user-foo-windows-amd64-0",
+	}
+	testCases := []struct {
+		desc           string
+		name           string
+		group          []string
+		wantCandidates []string
+	}{
+		{"prefix", "user-foo-linux", nil, []string{"user-foo-linux-amd64-0", "user-foo-linux-amd64-1"}},
+		{"substring", "amd64-0", nil, []string{"user-foo-linux-amd64-0", "user-foo-windows-amd64-0"}},
+		{"tie within group", "linux", []string{"user-foo-linux-amd64-0", "user-foo-linux-amd64-1"}, []string{"user-foo-linux-amd64-0", "user-foo-linux-amd64-1"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := matchInstance(tc.name, instances, tc.group)
+			if err == nil {
+				t.Fatalf("matchInstance(%q) = %q; want error", tc.name, got)
+			}
+			for _, c := range tc.wantCandidates {
+				if !strings.Contains(err.Error(), c) {
+					t.Errorf("matchInstance(%q) error %q does not list candidate %q", tc.name, err, c)
+				}
+			}
+		})
+	}
+}
+
+// testBuilderTypes returns the builder types of the coordinator's listing
+// of builders in testdata.
+func testBuilderTypes(t *testing.T) []builderlist.Builder {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+	c := &builderlist.Client{HTTPClient: ts.Client(), URL: ts.URL + "/builders.json"}
+	bts, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("listing the builder types: %v", err)
+	}
+	return bts
+}
+
+func TestMatchBuilderType(t *testing.T) {
+	bts := testBuilderTypes(t)
+	testCases := []struct {
+		desc string
+		name string
+		want string
+	}{
+		{"exact", "linux-amd64", "linux-amd64"},
+		{"exact deprecated", "linux-386-buster", "linux-386-buster"},
+		{"one candidate", "windows", "windows-amd64-2016"},
+		{"one candidate besides a deprecated one", "linux-386", "linux-386-bookworm"},
+		{"no candidates", "plan9-386", "plan9-386"},
+		{"retired", "linux-amd64-stretch", "linux-amd64-stretch"},
+		{"no candidates but a misc-compile builder", "misc-compile-windows", "misc-compile-windows"},
+		{"substring", "bookworm", "bookworm"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := matchBuilderType(tc.name, bts)
+			if err != nil {
+				t.Fatalf("matchBuilderType(%q) = %v; want no error", tc.name, err)
+			}
+			if got != tc.want {
+				t.Errorf("matchBuilderType(%q) = %q; want %q", tc.name, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchBuilderTypeCandidates(t *testing.T) {
+	bts := testBuilderTypes(t)
+	testCases := []struct {
+		desc           string
+		name           string
+		wantCandidates []string
+	}{
+		{"many candidates", "linux-amd64-", []string{"linux-amd64-bookworm", "linux-amd64-race"}},
+		{"many candidates including a deprecated one", "linux", []string{"linux-386-bookworm", "linux-386-buster (deprecated)", "linux-amd64", "linux-amd64-bookworm", "linux-amd64-race"}},
+		{"only deprecated candidates", "darwin-amd64", []string{"darwin-amd64-12 (deprecated)", "darwin-amd64-13 (deprecated)"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := matchBuilderType(tc.name, bts)
+			if err == nil {
+				t.Fatalf("matchBuilderType(%q) = %q; want error", tc.name, got)
+			}
+			if !strings.Contains(err.Error(), "\n\t"+strings.Join(tc.wantCandidates, "\n\t")) {
+				t.Errorf("matchBuilderType(%q) error = %q; want it to list %q", tc.name, err, tc.wantCandidates)
+			}
+		})
+	}
+}
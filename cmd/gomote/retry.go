@@ -0,0 +1,55 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+
+	"golang.org/x/build/buildlet"
+	"google.golang.org/grpc"
+)
+
+var retriesFlag = flag.Int("retries", buildlet.DefaultRetryPolicy.Attempts-1, "how many times to retry requests which are safe to repeat, such as those of list, ls and status, when the server is briefly unavailable; 0 disables retrying")
+
+// idempotentRPCs are the RPCs of the gomote server which are safe to
+// repeat, and so are retried when they fail transiently.
+var idempotentRPCs = map[string]bool{
+	"/protos.GomoteService/Authenticate":         true,
+	"/protos.GomoteService/InstanceAlive":        true,
+	"/protos.GomoteService/InstanceStatus":       true,
+	"/protos.GomoteService/ListDirectory":        true,
+	"/protos.GomoteService/ListInstances":        true,
+	"/protos.GomoteService/ListSwarmingBuilders": true,
+	"/protos.GomoteService/ServerVersion":        true,
+}
+
+// retryDialOptions returns the dial options which retry the idempotent
+// RPCs made to the gomote server according to -retries.
+func retryDialOptions() []grpc.DialOption {
+	policy := buildlet.DefaultRetryPolicy
+	policy.Attempts = *retriesFlag + 1
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(retryUnaryInterceptor(policy)),
+	}
+}
+
+func retryUnaryInterceptor(policy buildlet.RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !idempotentRPCs[method] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return policy.Retry(ctx, retryableRPCError, func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// retryableRPCError reports whether an RPC which failed with err may
+// succeed if retried. Proxies which are briefly unable to reach the
+// server result in buildlet.ErrServerUnavailable.
+func retryableRPCError(err error) bool {
+	return isServerError(err, buildlet.ErrServerUnavailable)
+}
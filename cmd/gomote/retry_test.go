@@ -0,0 +1,81 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryUnaryInterceptor(t *testing.T) {
+	policy := buildlet.RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	interceptor := retryUnaryInterceptor(policy)
+	// flakyInvoker fails with code fails times before succeeding.
+	flakyInvoker := func(code codes.Code, fails int, calls *int) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			*calls++
+			if *calls <= fails {
+				return status.Errorf(code, "failure %d", *calls)
+			}
+			return nil
+		}
+	}
+	testCases := []struct {
+		desc      string
+		method    string
+		code      codes.Code
+		fails     int
+		wantErr   bool
+		wantCalls int
+	}{
+		{"idempotent", "/protos.GomoteService/ListInstances", codes.Unavailable, 2, false, 3},
+		{"too many failures", "/protos.GomoteService/ListInstances", codes.Unavailable, 3, true, 3},
+		{"not transient", "/protos.GomoteService/InstanceStatus", codes.NotFound, 1, true, 1},
+		{"not idempotent", "/protos.GomoteService/WriteTGZFromURL", codes.Unavailable, 1, true, 1},
+		{"not idempotent either", "/protos.GomoteService/DestroyInstance", codes.Unavailable, 1, true, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var calls int
+			err := interceptor(context.Background(), tc.method, nil, nil, nil, flakyInvoker(tc.code, tc.fails, &calls))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("interceptor(%s) = %v; want error: %t", tc.method, err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("interceptor(%s) made %d calls; want %d", tc.method, calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryableRPCError(t *testing.T) {
+	testCases := []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), true},
+		{"classified unavailable", &buildlet.ServerError{Kind: buildlet.ErrServerUnavailable, Err: errors.New("503 Service Unavailable")}, true},
+		{"wrapped", fmt.Errorf("listing instances: %w", status.Error(codes.Unavailable, "connection refused")), true},
+		{"not found", buildlet.GRPCServerError("/protos.GomoteService/InstanceStatus", status.Error(codes.NotFound, "instance not found")), false},
+		{"quota exceeded", &buildlet.ServerError{Kind: buildlet.ErrQuotaExceeded, Err: errors.New("429 Too Many Requests")}, false},
+		{"not grpc", errors.New("disk full"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := retryableRPCError(tc.err); got != tc.want {
+				t.Errorf("retryableRPCError(%v) = %t; want %t", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -9,30 +9,35 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"golang.org/x/build/internal/gomote/protos"
 	"golang.org/x/sync/errgroup"
 )
 
 func rm(args []string) error {
-	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "rm usage: gomote rm [instance] <file-or-dir>+")
-		fmt.Fprintln(os.Stderr, "          gomote rm [instance] .  (to delete everything)")
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	fs.Parse(args)
+	var flags rmFlags
+	fs := rmFlagSet(&flags)
+	parseFlags(fs, args)
 
 	ctx := context.Background()
 	var rmSet []string
 	var paths []string
-	if err := doPing(ctx, fs.Arg(0)); instanceDoesNotExist(err) {
+	// Instance names may only be abbreviated without an active group,
+	// since otherwise the first argument may not be an instance at all.
+	name := fs.Arg(0)
+	var err error
+	if activeGroup == nil {
+		if name, err = resolveInstance(ctx, name); err != nil {
+			return err
+		}
+	} else if name, err = resolveAlias(name); err != nil {
+		return err
+	}
+	if err := doPing(ctx, name); instanceDoesNotExist(err) {
 		// When there's no active group, this is just an error.
 		if activeGroup == nil {
-			return fmt.Errorf("instance %q: %w", fs.Arg(0), err)
+			return fmt.Errorf("instance %q: %w", name, err)
 		}
 		// When there is an active group, this just means that we're going
 		// to use the group instead and assume the rest is a command.
@@ -45,14 +50,26 @@ func rm(args []string) error {
 		}
 		paths = fs.Args()
 	} else if err == nil {
-		rmSet = append(rmSet, fs.Arg(0))
+		rmSet = append(rmSet, name)
 		if fs.NArg() == 1 {
 			fmt.Fprintln(os.Stderr, "error: not enough arguments")
 			fs.Usage()
 		}
 		paths = fs.Args()[1:]
 	} else {
-		return fmt.Errorf("checking instance %q: %w", fs.Arg(0), err)
+		return fmt.Errorf("checking instance %q: %w", name, err)
+	}
+
+	if needsRmConfirmation(rmSet, paths) {
+		var targets []string
+		for _, inst := range rmSet {
+			for _, p := range paths {
+				targets = append(targets, fmt.Sprintf("%s:%s", inst, p))
+			}
+		}
+		if err := confirmStdio(flags.force, fmt.Sprintf("remove %d paths from %d instances", len(paths), len(rmSet)), targets, "y"); err != nil {
+			return err
+		}
 	}
 
 	eg, ctx := errgroup.WithContext(context.Background())
@@ -65,6 +82,25 @@ func rm(args []string) error {
 	return eg.Wait()
 }
 
+// rmFlags are the flags of the rm command.
+type rmFlags struct {
+	force bool
+}
+
+func rmFlagSet(flags *rmFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "rm usage: gomote rm [instance] <file-or-dir>+")
+		fmt.Fprintln(os.Stderr, "          gomote rm [instance] .  (to delete everything)")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.force, "f", false, "do not ask for confirmation before removing files from more than one instance, the whole work directory, or many paths")
+	return fs
+}
+
 func doRm(ctx context.Context, inst string, paths []string) error {
 	client := gomoteServerClient(ctx)
 	if _, err := client.RemoveFiles(ctx, &protos.RemoveFilesRequest{
@@ -75,3 +111,17 @@ func doRm(ctx context.Context, inst string, paths []string) error {
 	}
 	return nil
 }
+
+// needsRmConfirmation reports whether removing paths from the instances is
+// destructive enough to ask the user for confirmation first.
+func needsRmConfirmation(insts, paths []string) bool {
+	if len(insts) > 1 || len(paths) > rmConfirmThreshold {
+		return true
+	}
+	for _, p := range paths {
+		if p == "." || strings.ContainsAny(p, "*?[") {
+			return true
+		}
+	}
+	return false
+}
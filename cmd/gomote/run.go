@@ -7,16 +7,18 @@ package main
 import (
 	"bytes"
 	"context"
-	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
+	"golang.org/x/build/cmd/gomote/progresstypes"
 	"golang.org/x/build/internal/gomote/protos"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
@@ -40,45 +42,19 @@ func (ss *stringSlice) Set(v string) error {
 }
 
 func run(args []string) error {
-	fs := flag.NewFlagSet("run", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "run usage: gomote run [run-opts] <instance> <cmd> [args...]")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	var sys bool
-	fs.BoolVar(&sys, "system", false, "run inside the system, and not inside the workdir; this is implicit if cmd starts with '/'")
-	var debug bool
-	fs.BoolVar(&debug, "debug", false, "write debug info about the command's execution before it begins")
-	var env stringSlice
-	fs.Var(&env, "e", "Environment variable KEY=value. The -e flag may be repeated multiple times to add multiple things to the environment.")
-	var firewall bool
-	fs.BoolVar(&firewall, "firewall", false, "Enable outbound firewall on machine. This is on by default on many builders (where supported) but disabled by default on gomote for ease of debugging. Once any command has been run with the -firewall flag on, it's on for the lifetime of that gomote instance.")
-	var path string
-	fs.StringVar(&path, "path", "", "Comma-separated list of ExecOpts.Path elements. The special string 'EMPTY' means to run without any $PATH. The empty string (default) does not modify the $PATH. Otherwise, the following expansions apply: the string '$PATH' expands to the current PATH element(s), the substring '$WORKDIR' expands to the buildlet's temp workdir.")
-
-	var dir string
-	fs.StringVar(&dir, "dir", "", "Directory to run from. Defaults to the directory of the command, or the work directory if -system is true.")
-	var builderEnv string
-	fs.StringVar(&builderEnv, "builderenv", "", "Optional alternate builder to act like. Must share the same underlying buildlet host type, or it's an error. For instance, linux-amd64-race or linux-386-387 are compatible with linux-amd64, but openbsd-amd64 and openbsd-386 are different hosts.")
-
-	var collect bool
-	fs.BoolVar(&collect, "collect", false, "Collect artifacts (stdout, work dir .tar.gz) into $PWD once complete.")
-
-	var untilPattern string
-	fs.StringVar(&untilPattern, "until", "", "Run command repeatedly until the output matches the provided regexp.")
-
-	fs.Parse(args)
+	var flags runFlags
+	fs := runFlagSet(&flags)
+	parseFlags(fs, args)
 	if fs.NArg() == 0 {
 		fs.Usage()
 	}
 
 	var until *regexp.Regexp
 	var err error
-	if untilPattern != "" {
-		until, err = regexp.Compile(untilPattern)
+	if flags.untilPattern != "" {
+		until, err = regexp.Compile(flags.untilPattern)
 		if err != nil {
-			return fmt.Errorf("bad regexp %q for 'until': %w", untilPattern, err)
+			return usageErrorf("bad regexp %q for 'until': %w", flags.untilPattern, err)
 		}
 	}
 
@@ -86,12 +62,23 @@ func run(args []string) error {
 	var cmdArgs []string
 	var runSet []string
 
-	// First check if the instance name refers to a live instance.
+	// Instance names may only be abbreviated without an active group,
+	// since otherwise the first argument may not be an instance at all.
 	ctx := context.Background()
-	if err := doPing(ctx, fs.Arg(0)); instanceDoesNotExist(err) {
+	name := fs.Arg(0)
+	if activeGroup == nil {
+		if name, err = resolveInstance(ctx, name); err != nil {
+			return err
+		}
+	} else if name, err = resolveAlias(name); err != nil {
+		return err
+	}
+
+	// First check if the instance name refers to a live instance.
+	if err := doPing(ctx, name); instanceDoesNotExist(err) {
 		// When there's no active group, this is just an error.
 		if activeGroup == nil {
-			return fmt.Errorf("instance %q: %w", fs.Arg(0), err)
+			return fmt.Errorf("instance %q: %w", name, err)
 		}
 		// When there is an active group, this just means that we're going
 		// to use the group instead and assume the rest is a command.
@@ -101,7 +88,7 @@ func run(args []string) error {
 		cmd = fs.Arg(0)
 		cmdArgs = fs.Args()[1:]
 	} else if err == nil {
-		runSet = append(runSet, fs.Arg(0))
+		runSet = append(runSet, name)
 		if fs.NArg() == 1 {
 			fmt.Fprintln(os.Stderr, "missing command")
 			fs.Usage()
@@ -109,21 +96,28 @@ func run(args []string) error {
 		cmd = fs.Arg(1)
 		cmdArgs = fs.Args()[2:]
 	} else {
-		return fmt.Errorf("checking instance %q: %w", fs.Arg(0), err)
+		return fmt.Errorf("checking instance %q: %w", name, err)
+	}
+
+	env, err := forwardedEnv(flags.forwardEnv, flags.env, os.Getenv)
+	if err != nil {
+		return err
 	}
+	reportForwardedEnv(os.Stderr, env)
+	env = append(env, flags.env...)
 
 	var pathOpt []string
-	if path == "EMPTY" {
+	if flags.path == "EMPTY" {
 		pathOpt = []string{} // non-nil
-	} else if path != "" {
-		pathOpt = strings.Split(path, ",")
+	} else if flags.path != "" {
+		pathOpt = strings.Split(flags.path, ",")
 	}
 
 	// Create temporary directory for output.
 	// This is useful even if we don't have multiple gomotes running, since
 	// it's easy to accidentally lose the output.
 	var outDir string
-	if collect {
+	if flags.collect {
 		outDir, err = os.Getwd()
 		if err != nil {
 			return err
@@ -135,6 +129,37 @@ func run(args []string) error {
 		}
 	}
 
+	// Interrupting gomote kills the commands on the instances, rather than
+	// leaving them running, using the execution IDs sent by the server.
+	var execIDsMu sync.Mutex
+	execIDs := make(map[string]string) // keyed by instance
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sigc:
+		case <-done:
+			return
+		}
+		// Interrupting gomote again exits immediately.
+		signal.Stop(sigc)
+		execIDsMu.Lock()
+		ids := make(map[string]string)
+		for inst, id := range execIDs {
+			ids[inst] = id
+		}
+		execIDsMu.Unlock()
+		if err := cancelCommands(gomoteServerClient(context.Background()), ids); err != nil {
+			fmt.Fprintf(os.Stderr, "# %v\n", err)
+		}
+		exitOp(exitInterrupted, errInterrupted)
+	}()
+
+	t := newTimer("run")
+	defer t.report(os.Stderr, flags.timings)
 	var cmdsFailedMu sync.Mutex
 	var cmdsFailed []*cmdFailedError
 	eg, ctx := errgroup.WithContext(context.Background())
@@ -143,7 +168,7 @@ func run(args []string) error {
 		if len(runSet) > 1 {
 			// There's more than one instance running the command, so let's
 			// be explicit about that.
-			fmt.Fprintf(os.Stderr, "# Running command on %q...\n", inst)
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Running command on %s...", styles.Instance(inst))))
 		}
 		eg.Go(func() error {
 			// Create a file to write output to so it doesn't get lost.
@@ -153,9 +178,9 @@ func run(args []string) error {
 			}
 			defer func() {
 				outf.Close()
-				fmt.Fprintf(os.Stderr, "# Wrote results from %q to %q.\n", inst, outf.Name())
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Wrote results from %s to %q.", styles.Instance(inst), outf.Name())))
 			}()
-			fmt.Fprintf(os.Stderr, "# Streaming results from %q to %q...\n", inst, outf.Name())
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Streaming results from %s to %q...", styles.Instance(inst), outf.Name())))
 
 			outputs := []io.Writer{outf}
 			// If this is the only command running, print to stdout too, for convenience and
@@ -163,26 +188,51 @@ func run(args []string) error {
 			if len(runSet) == 1 {
 				outputs = append(outputs, os.Stdout)
 			}
+			// With -split-output, the command's stderr goes to a file of its own, and
+			// to our stderr.
+			var errf *os.File
+			var errOutputs []io.Writer
+			if flags.splitOutput {
+				errf, err = os.Create(filepath.Join(outDir, fmt.Sprintf("%s.stderr", inst)))
+				if err != nil {
+					return err
+				}
+				defer errf.Close()
+				errOutputs = append(errOutputs, errf)
+				if len(runSet) == 1 {
+					errOutputs = append(errOutputs, os.Stderr)
+				}
+			}
 			// Give ourselves the output too so that we can match against it.
 			var outBuf bytes.Buffer
 			if until != nil {
 				outputs = append(outputs, &outBuf)
+				if errOutputs != nil {
+					errOutputs = append(errOutputs, &outBuf)
+				}
 			}
 			var ce *cmdFailedError
+			endRun := t.span("run", inst)
 			for {
 				err := doRun(
 					ctx,
 					inst,
 					cmd,
 					cmdArgs,
-					runDir(dir),
-					runBuilderEnv(builderEnv),
+					runDir(flags.dir),
+					runBuilderEnv(flags.builderEnv),
 					runEnv(env),
 					runPath(pathOpt),
-					runSystem(sys),
-					runDebug(debug),
-					runFirewall(firewall),
+					runSystem(flags.sys),
+					runDebug(flags.debug),
+					runFirewall(flags.firewall),
 					runWriters(outputs...),
+					runStderrWriters(errOutputs...),
+					runStarted(func(id string) {
+						execIDsMu.Lock()
+						defer execIDsMu.Unlock()
+						execIDs[inst] = id
+					}),
 				)
 				// If it's just that the command failed, don't exit just yet, and don't return
 				// an error to the errgroup because we want the other commands to keep going.
@@ -201,11 +251,17 @@ func run(args []string) error {
 				if err := outf.Truncate(0); err != nil {
 					return fmt.Errorf("failed to truncate output file %q: %w", outf.Name(), err)
 				}
+				if errf != nil {
+					if err := errf.Truncate(0); err != nil {
+						return fmt.Errorf("failed to truncate output file %q: %w", errf.Name(), err)
+					}
+				}
 
-				fmt.Fprintf(os.Stderr, "# No match found on %q, running again...\n", inst)
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# No match found on %s, running again...", styles.Instance(inst))))
 			}
+			endRun()
 			if until != nil {
-				fmt.Fprintf(os.Stderr, "# Match found on %q.\n", inst)
+				fmt.Fprintln(os.Stderr, styles.Success(fmt.Sprintf("# Match found on %s.", styles.Instance(inst))))
 			}
 			if ce != nil {
 				// N.B. If err this wasn't a cmdFailedError
@@ -218,14 +274,15 @@ func run(args []string) error {
 					fmt.Fprintf(os.Stderr, "failed to write error to output: %v", err)
 				}
 			}
-			if collect {
+			if flags.collect {
 				f, err := os.Create(fmt.Sprintf("%s.tar.gz", inst))
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "failed to create file to write instance tarball: %v", err)
 					return nil
 				}
 				defer f.Close()
-				fmt.Fprintf(os.Stderr, "# Downloading work dir tarball for %q to %q...\n", inst, f.Name())
+				fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Downloading work dir tarball for %s to %q...", styles.Instance(inst), f.Name())))
+				defer t.span("collect", inst)()
 				if err := doGetTar(ctx, inst, ".", f); err != nil {
 					fmt.Fprintf(os.Stderr, "failed to retrieve instance tarball: %v", err)
 					return nil
@@ -241,15 +298,65 @@ func run(args []string) error {
 	// running. We still want to handle them, though, because we want to make sure
 	// we exit with a non-zero exit code to reflect the command failure.
 	for _, ce := range cmdsFailed {
-		fmt.Fprintf(os.Stderr, "# Command %q failed on %q: %v\n", ce.cmd, ce.inst, err)
+		fmt.Fprintln(os.Stderr, styles.Failure(fmt.Sprintf("# Command %q failed on %s: %s", ce.cmd, styles.Instance(ce.inst), ce.reason())))
+	}
+	if len(runSet) > 1 {
+		summary := fmt.Sprintf("# Command succeeded on %d of %d instances.", len(runSet)-len(cmdsFailed), len(runSet))
+		if len(cmdsFailed) > 0 {
+			summary = styles.Failure(summary)
+		} else {
+			summary = styles.Success(summary)
+		}
+		fmt.Fprintln(os.Stderr, summary)
 	}
 	if len(cmdsFailed) > 0 {
-		return errors.New("one or more commands failed")
+		return errCommandsFailed
 	}
 	return nil
 }
 
-func doRun(ctx context.Context, inst, cmd string, cmdArgs []string, opts ...runOpt) error {
+// runFlags are the flags of the run command.
+type runFlags struct {
+	sys          bool
+	debug        bool
+	env          stringSlice
+	forwardEnv   string
+	firewall     bool
+	path         string
+	dir          string
+	builderEnv   string
+	collect      bool
+	untilPattern string
+	splitOutput  bool
+	timings      timingsFlag
+}
+
+func runFlagSet(flags *runFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "run usage: gomote run [run-opts] <instance> <cmd> [args...]")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.sys, "system", false, "run inside the system, and not inside the workdir; this is implicit if cmd starts with '/'")
+	fs.BoolVar(&flags.debug, "debug", false, "write debug info about the command's execution before it begins")
+	fs.Var(&flags.env, "e", "Environment variable KEY=value. The -e flag may be repeated multiple times to add multiple things to the environment.")
+	fs.StringVar(&flags.forwardEnv, "forward-env", defaultForwardEnv, forwardEnvUsage+"; -e takes precedence")
+	fs.BoolVar(&flags.firewall, "firewall", false, "Enable outbound firewall on machine. This is on by default on many builders (where supported) but disabled by default on gomote for ease of debugging. Once any command has been run with the -firewall flag on, it's on for the lifetime of that gomote instance.")
+	fs.StringVar(&flags.path, "path", "", "Comma-separated list of ExecOpts.Path elements. The special string 'EMPTY' means to run without any $PATH. The empty string (default) does not modify the $PATH. Otherwise, the following expansions apply: the string '$PATH' expands to the current PATH element(s), the substring '$WORKDIR' expands to the buildlet's temp workdir.")
+
+	fs.StringVar(&flags.dir, "dir", "", "Directory to run from. Defaults to the directory of the command, or the work directory if -system is true.")
+	fs.StringVar(&flags.builderEnv, "builderenv", "", "Optional alternate builder to act like. Must share the same underlying buildlet host type, or it's an error. For instance, linux-amd64-race or linux-386-387 are compatible with linux-amd64, but openbsd-amd64 and openbsd-386 are different hosts.")
+
+	fs.BoolVar(&flags.collect, "collect", false, "Collect artifacts (stdout, work dir .tar.gz) into $PWD once complete.")
+
+	fs.StringVar(&flags.untilPattern, "until", "", "Run command repeatedly until the output matches the provided regexp.")
+	fs.BoolVar(&flags.splitOutput, "split-output", false, "Write the command's stderr to its own <instance>.stderr file, and to gomote's stderr, rather than combining it with stdout. Older servers and buildlets only report combined output.")
+	fs.Var(&flags.timings, "timings", timingsUsage)
+	return fs
+}
+
+func doRun(ctx context.Context, inst, cmd string, cmdArgs []string, opts ...runOpt) (err error) {
 	cfg := &runCfg{
 		req: protos.ExecuteCommandRequest{
 			AppendEnvironment: []string{},
@@ -266,12 +373,22 @@ func doRun(ctx context.Context, inst, cmd string, cmdArgs []string, opts ...runO
 		cfg.req.SystemLevel = strings.HasPrefix(cmd, "/")
 	}
 
-	outWriter := io.MultiWriter(cfg.outputs...)
+	ev := progresstypes.Event{Phase: progresstypes.PhaseRun, Instance: inst, Message: cmd}
+	emitProgress(ev)
+	// Report the output received so far on long runs.
+	outProgress := &progressWriter{ev: ev, last: time.Now()}
+	defer func() { emitProgressDone(outProgress.ev, err) }()
+	outWriter := io.MultiWriter(append(cfg.outputs, outProgress)...)
+	errWriter := outWriter
+	if len(cfg.stderr) > 0 {
+		errWriter = io.MultiWriter(append(cfg.stderr, outProgress)...)
+	}
 	client := gomoteServerClient(ctx)
 	stream, err := client.ExecuteCommand(ctx, &cfg.req)
 	if err != nil {
 		return fmt.Errorf("unable to execute %s: %w", cmd, err)
 	}
+	var exit *protos.ExecuteCommandResponse_ExitStatus
 	for {
 		update, err := stream.Recv()
 		if err == io.EOF {
@@ -282,20 +399,49 @@ func doRun(ctx context.Context, inst, cmd string, cmdArgs []string, opts ...runO
 			if status.Code(err) == codes.Aborted {
 				return &cmdFailedError{inst: inst, cmd: cmd, err: err}
 			}
+			// The command failed, and the server reported how it exited.
+			if exit != nil && exit.GetState() != "ok" {
+				return &cmdFailedError{inst: inst, cmd: cmd, err: err, exit: exit}
+			}
 			// remote error
 			return fmt.Errorf("unable to execute %s: %w", cmd, err)
 		}
-		fmt.Fprint(outWriter, string(update.GetOutput()))
+		if id := update.GetExecutionId(); id != "" {
+			if cfg.started != nil {
+				cfg.started(id)
+			}
+			continue
+		}
+		if st := update.GetExitStatus(); st != nil {
+			exit = st
+			continue
+		}
+		w := outWriter
+		if update.GetStream() == protos.ExecuteCommandResponse_STDERR {
+			w = errWriter
+		}
+		fmt.Fprint(w, string(update.GetOutput()))
 	}
 }
 
 type cmdFailedError struct {
 	inst, cmd string
 	err       error
+	// exit is how the command exited, if the server reported it.
+	exit *protos.ExecuteCommandResponse_ExitStatus
 }
 
 func (e *cmdFailedError) Error() string {
-	return fmt.Sprintf("Error trying to execute %s: %v", e.cmd, e.err)
+	return fmt.Sprintf("Error trying to execute %s: %s", e.cmd, e.reason())
+}
+
+// reason describes why the command failed.
+func (e *cmdFailedError) reason() string {
+	if e.exit == nil {
+		return e.err.Error()
+	}
+	d := time.Duration(e.exit.GetDurationMillis()) * time.Millisecond
+	return fmt.Sprintf("%s after %v", e.exit.GetState(), d.Round(time.Millisecond))
 }
 
 func (e *cmdFailedError) Unwrap() error {
@@ -304,6 +450,8 @@ func (e *cmdFailedError) Unwrap() error {
 
 type runCfg struct {
 	outputs []io.Writer
+	stderr  []io.Writer // or nil to combine stderr with outputs
+	started func(id string)
 	req     protos.ExecuteCommandRequest
 }
 
@@ -356,3 +504,17 @@ func runWriters(writers ...io.Writer) runOpt {
 		r.outputs = writers
 	}
 }
+
+func runStderrWriters(writers ...io.Writer) runOpt {
+	return func(r *runCfg) {
+		r.stderr = writers
+	}
+}
+
+// runStarted sets a function called with the execution ID of the command once
+// it has started, if the server reports it.
+func runStarted(fn func(id string)) runOpt {
+	return func(r *runCfg) {
+		r.started = fn
+	}
+}
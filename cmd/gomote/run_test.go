@@ -0,0 +1,39 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCmdFailedErrorReason(t *testing.T) {
+	err := status.Error(codes.Unknown, "command execution failed: exit status 3")
+	for _, tc := range []struct {
+		ce   *cmdFailedError
+		want string
+	}{
+		{
+			// An older server reports only the error.
+			ce:   &cmdFailedError{inst: "a", cmd: "go", err: err},
+			want: err.Error(),
+		},
+		{
+			ce: &cmdFailedError{inst: "a", cmd: "go", err: err, exit: &protos.ExecuteCommandResponse_ExitStatus{
+				ExitCode:       3,
+				State:          "exit status 3",
+				DurationMillis: 61500,
+			}},
+			want: "exit status 3 after 1m1.5s",
+		},
+	} {
+		if got := tc.ce.reason(); got != tc.want {
+			t.Errorf("reason() = %q; want %q", got, tc.want)
+		}
+	}
+}
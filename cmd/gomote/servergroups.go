@@ -0,0 +1,247 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var localGroupsFlag = flag.Bool("local-groups", false, "store groups in local files even if the server can store them")
+
+var (
+	serverGroupsOnce      sync.Once
+	serverGroupsSupported bool
+)
+
+// useServerGroups reports whether groups are stored by the gomote server,
+// which makes them available on every machine. They are unless
+// -local-groups is set or the server doesn't support groups, in which case
+// they're stored in local files.
+func useServerGroups() bool {
+	if *localGroupsFlag {
+		return false
+	}
+	serverGroupsOnce.Do(func() {
+		ctx := context.Background()
+		serverGroupsSupported = serverSupportsGroups(ctx, gomoteServerClient(ctx))
+	})
+	return serverGroupsSupported
+}
+
+// serverSupportsGroups reports whether the server stores groups.
+func serverSupportsGroups(ctx context.Context, client protos.GomoteServiceClient) bool {
+	_, err := client.ListGroups(ctx, &protos.ListGroupsRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		return false
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "# Unable to list the server's groups; using local groups: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// noGroupError is returned when loading a group the server doesn't have.
+// Like a missing group file, it matches os.ErrNotExist.
+type noGroupError struct {
+	name string
+}
+
+func (e noGroupError) Error() string {
+	return fmt.Sprintf("group %q does not exist", e.name)
+}
+
+func (noGroupError) Is(target error) bool {
+	return target == os.ErrNotExist
+}
+
+// groupFromProto converts a group stored by the server. The groups of
+// other users are named "owner/name".
+func groupFromProto(pg *protos.Group) *groupData {
+	g := &groupData{
+		Name:      pg.GetName(),
+		Instances: slices.Clone(pg.GetGomoteIds()),
+		Shared:    pg.GetShared(),
+		server:    true,
+		stored:    slices.Clone(pg.GetGomoteIds()),
+	}
+	if !pg.GetOwned() {
+		g.Owner = pg.GetOwner()
+		g.Name = pg.GetOwner() + "/" + pg.GetName()
+	}
+	return g
+}
+
+// loadServerGroups loads the caller's groups stored by the server, and
+// if includeShared is set, the groups shared by other users.
+func loadServerGroups(ctx context.Context, client protos.GomoteServiceClient, includeShared bool) ([]*groupData, error) {
+	resp, err := client.ListGroups(ctx, &protos.ListGroupsRequest{IncludeShared: includeShared})
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+	var groups []*groupData
+	for _, pg := range resp.GetGroups() {
+		groups = append(groups, groupFromProto(pg))
+	}
+	return groups, nil
+}
+
+// loadServerGroup loads the named group stored by the server. A group
+// shared by another user is named "owner/name".
+func loadServerGroup(ctx context.Context, client protos.GomoteServiceClient, name string) (*groupData, error) {
+	groups, err := loadServerGroups(ctx, client, strings.Contains(name, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("loading group %q: %w", name, err)
+	}
+	for _, g := range groups {
+		if g.Name == name {
+			return g, nil
+		}
+	}
+	return nil, noGroupError{name}
+}
+
+// createServerGroup creates a group of the instances stored by the server.
+func createServerGroup(ctx context.Context, client protos.GomoteServiceClient, name string, insts []string) (*groupData, error) {
+	resp, err := client.CreateGroup(ctx, &protos.CreateGroupRequest{Name: name, GomoteIds: insts})
+	if status.Code(err) == codes.AlreadyExists {
+		return nil, fmt.Errorf("group %q already exists", name)
+	} else if err != nil {
+		return nil, fmt.Errorf("creating group %q: %w", name, err)
+	}
+	return groupFromProto(resp.GetGroup()), nil
+}
+
+// storeServerGroup makes the server add the instances added to g since
+// it was loaded, and remove the instances removed from it.
+func storeServerGroup(ctx context.Context, client protos.GomoteServiceClient, g *groupData) error {
+	if g.Owner != "" {
+		return fmt.Errorf("group %q is owned by %s and can't be modified", g.Name, g.Owner)
+	}
+	req := &protos.ModifyGroupRequest{Name: g.Name}
+	for _, inst := range g.Instances {
+		if !slices.Contains(g.stored, inst) && !slices.Contains(req.AddGomoteIds, inst) {
+			req.AddGomoteIds = append(req.AddGomoteIds, inst)
+		}
+	}
+	for _, inst := range g.stored {
+		if !slices.Contains(g.Instances, inst) {
+			req.RemoveGomoteIds = append(req.RemoveGomoteIds, inst)
+		}
+	}
+	if len(req.AddGomoteIds) == 0 && len(req.RemoveGomoteIds) == 0 {
+		return nil
+	}
+	resp, err := client.ModifyGroup(ctx, req)
+	if err != nil {
+		return fmt.Errorf("storing group %q: %w", g.Name, err)
+	}
+	g.Instances = slices.Clone(resp.GetGroup().GetGomoteIds())
+	g.stored = slices.Clone(resp.GetGroup().GetGomoteIds())
+	return nil
+}
+
+// deleteServerGroup deletes a group stored by the server.
+func deleteServerGroup(ctx context.Context, client protos.GomoteServiceClient, name string) error {
+	if _, err := client.DeleteGroup(ctx, &protos.DeleteGroupRequest{Name: name}); err != nil {
+		return fmt.Errorf("deleting group %q: %w", name, err)
+	}
+	return nil
+}
+
+func shareGroup(args []string) error {
+	return doShareGroup("share", args, protos.ModifyGroupRequest_SHARED)
+}
+
+func unshareGroup(args []string) error {
+	return doShareGroup("unshare", args, protos.ModifyGroupRequest_PRIVATE)
+}
+
+func doShareGroup(cmd string, args []string, sharing protos.ModifyGroupRequest_Sharing) error {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "group %s usage: gomote group %s <name>\n", cmd, cmd)
+		os.Exit(exitUsage)
+	}
+	if !useServerGroups() {
+		return errors.New("only groups stored by the server can be shared")
+	}
+	ctx := context.Background()
+	if _, err := gomoteServerClient(ctx).ModifyGroup(ctx, &protos.ModifyGroupRequest{Name: args[0], Sharing: sharing}); err != nil {
+		return fmt.Errorf("changing sharing of group %q: %w", args[0], err)
+	}
+	return nil
+}
+
+func migrateGroups(args []string) error {
+	fs := flag.NewFlagSet("group migrate", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "group migrate usage: gomote group migrate [-keep]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Moves the groups stored in local files to the server, merging them")
+		fmt.Fprintln(os.Stderr, "into any groups of the same names already stored by the server.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	keep := fs.Bool("keep", false, "keep the local group files")
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+	}
+	if *localGroupsFlag {
+		return usageErrorf("group migrate can't be used with -local-groups")
+	}
+	if !useServerGroups() {
+		return errors.New("the server doesn't store groups")
+	}
+	groups, err := loadLocalGroups()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	return doMigrateGroups(ctx, gomoteServerClient(ctx), os.Stderr, groups, *keep)
+}
+
+// doMigrateGroups stores the local groups on the server, and deletes their
+// files unless keep is set. Groups the server already has are merged.
+func doMigrateGroups(ctx context.Context, client protos.GomoteServiceClient, w io.Writer, groups []*groupData, keep bool) error {
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "# No local groups to migrate.")
+		return nil
+	}
+	var failed []string
+	for _, g := range groups {
+		_, err := client.CreateGroup(ctx, &protos.CreateGroupRequest{Name: g.Name, GomoteIds: g.Instances})
+		if status.Code(err) == codes.AlreadyExists {
+			_, err = client.ModifyGroup(ctx, &protos.ModifyGroupRequest{Name: g.Name, AddGomoteIds: g.Instances})
+		}
+		if err != nil {
+			fmt.Fprintf(w, "# Unable to migrate group %q: %v\n", g.Name, err)
+			failed = append(failed, g.Name)
+			continue
+		}
+		fmt.Fprintf(w, "# Migrated group %q with %d instances\n", g.Name, len(g.Instances))
+		if !keep {
+			if err := deleteLocalGroup(g.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to migrate groups %s; their local files were kept", strings.Join(failed, ", "))
+	}
+	return nil
+}
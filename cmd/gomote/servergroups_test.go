@@ -0,0 +1,220 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"testing"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeGroupClient is a GomoteServiceClient which stores groups the way
+// the gomote server does, for a caller who owns the instances in owned.
+type fakeGroupClient struct {
+	protos.GomoteServiceClient
+	owned  []string
+	groups map[string]*protos.Group // keyed by name; the caller owns them unless Owned is false
+}
+
+func newFakeGroupClient(owned ...string) *fakeGroupClient {
+	return &fakeGroupClient{owned: owned, groups: map[string]*protos.Group{}}
+}
+
+func (c *fakeGroupClient) checkOwned(ids []string) error {
+	for _, id := range ids {
+		if !slices.Contains(c.owned, id) {
+			return status.Errorf(codes.PermissionDenied, "instance %s is owned by another user", id)
+		}
+	}
+	return nil
+}
+
+func (c *fakeGroupClient) CreateGroup(_ context.Context, req *protos.CreateGroupRequest, _ ...grpc.CallOption) (*protos.CreateGroupResponse, error) {
+	if _, ok := c.groups[req.GetName()]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "group already exists")
+	}
+	if err := c.checkOwned(req.GetGomoteIds()); err != nil {
+		return nil, err
+	}
+	g := &protos.Group{Name: req.GetName(), Owner: "me", Owned: true, Shared: req.GetShared(), GomoteIds: slices.Clone(req.GetGomoteIds())}
+	sort.Strings(g.GomoteIds)
+	c.groups[g.Name] = g
+	return &protos.CreateGroupResponse{Group: g}, nil
+}
+
+func (c *fakeGroupClient) ModifyGroup(_ context.Context, req *protos.ModifyGroupRequest, _ ...grpc.CallOption) (*protos.ModifyGroupResponse, error) {
+	g, ok := c.groups[req.GetName()]
+	if !ok || !g.Owned {
+		return nil, status.Errorf(codes.NotFound, "group does not exist")
+	}
+	if err := c.checkOwned(req.GetAddGomoteIds()); err != nil {
+		return nil, err
+	}
+	for _, id := range req.GetAddGomoteIds() {
+		if !slices.Contains(g.GomoteIds, id) {
+			g.GomoteIds = append(g.GomoteIds, id)
+		}
+	}
+	g.GomoteIds = slices.DeleteFunc(g.GomoteIds, func(id string) bool {
+		return slices.Contains(req.GetRemoveGomoteIds(), id)
+	})
+	sort.Strings(g.GomoteIds)
+	switch req.GetSharing() {
+	case protos.ModifyGroupRequest_SHARED:
+		g.Shared = true
+	case protos.ModifyGroupRequest_PRIVATE:
+		g.Shared = false
+	}
+	return &protos.ModifyGroupResponse{Group: g}, nil
+}
+
+func (c *fakeGroupClient) DeleteGroup(_ context.Context, req *protos.DeleteGroupRequest, _ ...grpc.CallOption) (*protos.DeleteGroupResponse, error) {
+	if g, ok := c.groups[req.GetName()]; !ok || !g.Owned {
+		return nil, status.Errorf(codes.NotFound, "group does not exist")
+	}
+	delete(c.groups, req.GetName())
+	return &protos.DeleteGroupResponse{}, nil
+}
+
+func (c *fakeGroupClient) ListGroups(_ context.Context, req *protos.ListGroupsRequest, _ ...grpc.CallOption) (*protos.ListGroupsResponse, error) {
+	resp := &protos.ListGroupsResponse{}
+	for _, g := range c.groups {
+		if g.Owned || (req.GetIncludeShared() && g.Shared) {
+			resp.Groups = append(resp.Groups, g)
+		}
+	}
+	return resp, nil
+}
+
+// unimplementedGroupClient is a GomoteServiceClient of a server which
+// doesn't store groups.
+type unimplementedGroupClient struct {
+	protos.GomoteServiceClient
+}
+
+func (unimplementedGroupClient) ListGroups(context.Context, *protos.ListGroupsRequest, ...grpc.CallOption) (*protos.ListGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
+
+func TestServerSupportsGroups(t *testing.T) {
+	ctx := context.Background()
+	if !serverSupportsGroups(ctx, newFakeGroupClient()) {
+		t.Errorf("serverSupportsGroups = false for a server which stores groups; want true")
+	}
+	if serverSupportsGroups(ctx, unimplementedGroupClient{}) {
+		t.Errorf("serverSupportsGroups = true for a server which doesn't store groups; want false")
+	}
+}
+
+func TestServerGroups(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeGroupClient("me-linux-amd64-0", "me-linux-amd64-1")
+	client.groups["tests"] = &protos.Group{Name: "tests", Owner: "other", Shared: true, GomoteIds: []string{"other-linux-amd64-0"}}
+
+	g, err := createServerGroup(ctx, client, "debug", []string{"me-linux-amd64-0"})
+	if err != nil {
+		t.Fatalf("createServerGroup: %v", err)
+	}
+	if _, err := createServerGroup(ctx, client, "debug", nil); err == nil {
+		t.Errorf("createServerGroup of an existing group succeeded; want error")
+	}
+
+	g.Instances = append(g.Instances, "me-linux-amd64-1")
+	if err := storeServerGroup(ctx, client, g); err != nil {
+		t.Fatalf("storeServerGroup: %v", err)
+	}
+	g.Instances = slices.DeleteFunc(g.Instances, func(inst string) bool { return inst == "me-linux-amd64-0" })
+	if err := storeServerGroup(ctx, client, g); err != nil {
+		t.Fatalf("storeServerGroup: %v", err)
+	}
+	loaded, err := loadServerGroup(ctx, client, "debug")
+	if err != nil {
+		t.Fatalf("loadServerGroup: %v", err)
+	}
+	if want := []string{"me-linux-amd64-1"}; !slices.Equal(loaded.Instances, want) {
+		t.Errorf("loaded group instances = %q; want %q", loaded.Instances, want)
+	}
+
+	// Adding an instance owned by another user fails.
+	loaded.Instances = append(loaded.Instances, "other-linux-amd64-0")
+	if err := storeServerGroup(ctx, client, loaded); status.Code(errors.Unwrap(err)) != codes.PermissionDenied {
+		t.Errorf("storeServerGroup with another user's instance = %v; want PermissionDenied", err)
+	}
+
+	// Groups shared by other users are named after their owner, and
+	// can't be modified.
+	shared, err := loadServerGroup(ctx, client, "other/tests")
+	if err != nil {
+		t.Fatalf("loadServerGroup of a shared group: %v", err)
+	}
+	if shared.Owner != "other" || !slices.Equal(shared.Instances, []string{"other-linux-amd64-0"}) {
+		t.Errorf("shared group = %+v; want one owned by other with its instance", shared)
+	}
+	if err := storeServerGroup(ctx, client, shared); err == nil {
+		t.Errorf("storeServerGroup of another user's group succeeded; want error")
+	}
+	if groups, err := loadServerGroups(ctx, client, false); err != nil || len(groups) != 1 || groups[0].Name != "debug" {
+		t.Errorf("loadServerGroups(false) = %v, %v; want only the caller's group", groups, err)
+	}
+
+	if err := deleteServerGroup(ctx, client, "debug"); err != nil {
+		t.Fatalf("deleteServerGroup: %v", err)
+	}
+	if _, err := loadServerGroup(ctx, client, "debug"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("loadServerGroup of a deleted group = %v; want an error matching os.ErrNotExist", err)
+	}
+}
+
+func TestMigrateGroups(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	groups := []*groupData{
+		{Name: "new", Instances: []string{"me-linux-amd64-0"}},
+		{Name: "existing", Instances: []string{"me-linux-amd64-1"}},
+		{Name: "foreign", Instances: []string{"other-linux-amd64-0"}},
+	}
+	for _, g := range groups {
+		if err := storeLocalGroup(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	client := newFakeGroupClient("me-linux-amd64-0", "me-linux-amd64-1", "me-linux-amd64-2")
+	if _, err := createServerGroup(ctx, client, "existing", []string{"me-linux-amd64-2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doMigrateGroups(ctx, client, io.Discard, groups, false); err == nil {
+		t.Errorf("doMigrateGroups with another user's instance succeeded; want error")
+	}
+	if got, want := client.groups["new"].GetGomoteIds(), []string{"me-linux-amd64-0"}; !slices.Equal(got, want) {
+		t.Errorf("migrated group instances = %q; want %q", got, want)
+	}
+	if got, want := client.groups["existing"].GetGomoteIds(), []string{"me-linux-amd64-1", "me-linux-amd64-2"}; !slices.Equal(got, want) {
+		t.Errorf("merged group instances = %q; want %q", got, want)
+	}
+	if _, ok := client.groups["foreign"]; ok {
+		t.Errorf("group with another user's instance was migrated")
+	}
+	for name, wantFile := range map[string]bool{"new": false, "existing": false, "foreign": true} {
+		fname, err := groupFilePath(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(fname); (err == nil) != wantFile {
+			t.Errorf("group file of %q exists = %t; want %t", name, err == nil, wantFile)
+		}
+	}
+}
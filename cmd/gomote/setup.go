@@ -0,0 +1,356 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/sync/errgroup"
+)
+
+// setupInstances sets up existing instances like create -setup does.
+func setupInstances(args []string) error {
+	var flags setupCommandFlags
+	fs := setupCommandFlagSet(&flags)
+	parseFlags(fs, args)
+
+	var insts []string
+	switch fs.NArg() {
+	case 0:
+		if activeGroup == nil {
+			fmt.Fprintln(os.Stderr, "no active group found; need an active group with no arguments")
+			fs.Usage()
+		}
+		insts = activeGroup.Instances
+	case 1:
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		insts = []string{inst}
+	default:
+		fs.Usage()
+	}
+
+	s, err := newSetup(context.Background(), flags.setup, len(insts) == 1)
+	if err != nil {
+		return err
+	}
+	reportForwardedEnv(os.Stderr, s.env)
+	t := newTimer("setup")
+	defer t.report(os.Stderr, flags.timings)
+	eg, ctx := errgroup.WithContext(context.Background())
+	for _, inst := range insts {
+		inst := inst
+		eg.Go(func() error {
+			resp, err := gomoteServerClient(ctx).InstanceStatus(ctx, &protos.InstanceStatusRequest{GomoteId: inst})
+			if err != nil {
+				return fmt.Errorf("unable to retrieve status of instance %s: %w", inst, err)
+			}
+			return s.run(ctx, t, inst, resp.GetInstance().GetBuilderType(), resp.GetInstance().GetWorkingDir())
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	return s.report(os.Stderr)
+}
+
+// setupCommandFlags are the flags of the setup command.
+type setupCommandFlags struct {
+	setup   setupFlags
+	timings timingsFlag
+}
+
+func setupCommandFlagSet(flags *setupCommandFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("setup", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "setup usage: gomote setup [setup-opts] [instance]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Pushes GOROOT to existing instances and builds Go on them, like")
+		fmt.Fprintln(os.Stderr, "\"gomote create -setup\" does for new ones.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	flags.setup.register(fs, false)
+	fs.Var(&flags.timings, "timings", timingsUsage)
+	return fs
+}
+
+// setupFlags are the flags of create which apply with -setup, and those
+// of the setup command.
+type setupFlags struct {
+	run             string
+	cl              string
+	logDir          string
+	noAutoBootstrap bool
+	forwardEnv      string
+
+	// goroot is the GOROOT to push, rather than the local one. It
+	// isn't a flag.
+	goroot string
+}
+
+// register defines the flags in fs. If withSetup is set, their usage says
+// that they require -setup.
+func (f *setupFlags) register(fs *flag.FlagSet, withSetup bool) {
+	var prefix string
+	if withSetup {
+		prefix = "with -setup, "
+	}
+	fs.StringVar(&f.run, "setup-run", "", prefix+"what to run once GOROOT is pushed: make to build Go (the default), all to build and test it with all.bash or its equivalent, or a command, relative to GOROOT, to run after building it, such as \"bin/go test -short std\"")
+	fs.StringVar(&f.logDir, "log-dir", "", prefix+"write the output of each instance to `dir`/<instance>.log; the default is a new directory under the user's cache directory")
+	fs.StringVar(&f.cl, "cl", "", prefix+"apply the Gerrit CL `number[/patchset]` of the go repository to the pushed GOROOT before building it; the default patch set is the current one")
+	fs.BoolVar(&f.noAutoBootstrap, "no-auto-bootstrap", false, prefix+"don't install a bootstrap version of Go, and build again, when building Go fails for lack of one")
+	fs.StringVar(&f.forwardEnv, "forward-env", defaultForwardEnv, prefix+forwardEnvUsage)
+}
+
+// set reports whether any of the flags is set.
+func (f *setupFlags) set() bool {
+	return f.run != "" || f.cl != "" || f.logDir != "" || f.noAutoBootstrap || f.forwardEnv != defaultForwardEnv
+}
+
+// A setup sets up instances by pushing GOROOT to them, applying a CL to it
+// if requested, and running the setup commands, which build Go. It's used
+// by both create -setup and the setup command.
+type setup struct {
+	target           string      // the -setup-run target
+	cl               *gerritCL   // or nil
+	src              *pushSource // shared by all the instances
+	logDir           string
+	autoBootstrap    bool
+	env              []string // forwarded from the local environment
+	detailedProgress bool
+
+	mu      sync.Mutex
+	cmds    map[string][]setupCmd // by builder type
+	results map[string]setupResult
+}
+
+// newSetup returns the setup requested by flags. If detailedProgress is
+// set, the progress of pushing GOROOT and the output of the commands are
+// printed, which is only sensible for a single instance. The CL to apply
+// is looked up, and the log directory created, up front.
+func newSetup(ctx context.Context, flags setupFlags, detailedProgress bool) (*setup, error) {
+	// Check the target, for a typical make script, before anything else.
+	if _, err := setupCommands("go/src/make.bash", flags.run); err != nil {
+		return nil, err
+	}
+	goroot := flags.goroot
+	var err error
+	if goroot == "" {
+		if goroot, err = getGOROOT(); err != nil {
+			return nil, err
+		}
+	}
+	s := &setup{
+		target:           flags.run,
+		src:              newPushSource(goroot, gzip.DefaultCompression),
+		logDir:           flags.logDir,
+		autoBootstrap:    !flags.noAutoBootstrap,
+		detailedProgress: detailedProgress,
+		cmds:             make(map[string][]setupCmd),
+		results:          make(map[string]setupResult),
+	}
+	if s.env, err = forwardedEnv(flags.forwardEnv, nil, os.Getenv); err != nil {
+		return nil, err
+	}
+	if flags.cl != "" {
+		number, patchSet, err := parseCL(flags.cl)
+		if err != nil {
+			return nil, err
+		}
+		if s.cl, err = lookupCL(ctx, number, patchSet); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "# Applying %v\n", s.cl)
+	}
+	if s.logDir == "" {
+		s.logDir = defaultSetupLogDir(time.Now())
+	}
+	// The logs are kept, whether or not setup succeeds.
+	if err := os.MkdirAll(s.logDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the directory for setup output: %w", err)
+	}
+	return s, nil
+}
+
+// commands returns the setup commands for instances of builderType.
+func (s *setup) commands(ctx context.Context, builderType string) ([]setupCmd, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cmds, ok := s.cmds[builderType]; ok {
+		return cmds, nil
+	}
+	cmds, err := setupCommands(setupMakeScript(ctx, builderType), s.target)
+	if err != nil {
+		return nil, err
+	}
+	s.cmds[builderType] = cmds
+	return cmds, nil
+}
+
+// run sets up inst, an instance of builderType with the work directory
+// workDir, which may be unknown, recording the spans of the setup in t. If
+// one of the setup commands fails, it's recorded for report rather than
+// returned, so that the other instances keep going.
+func (s *setup) run(ctx context.Context, t *timer, inst, builderType, workDir string) error {
+	cmds, err := s.commands(ctx, builderType)
+	if err != nil {
+		return err
+	}
+
+	// Pushing GOROOT and building the toolchain takes a while,
+	// so check up front that there's room for them.
+	warnLowDiskSpace(ctx, inst)
+
+	// Push GOROOT.
+	if !s.detailedProgress {
+		fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Pushing GOROOT %q to %s...", s.src.goroot, styles.Instance(inst))))
+	}
+	endPush := t.span("push", inst)
+	if err := doPush(ctx, inst, s.src, false, s.detailedProgress); err != nil {
+		return err
+	}
+	endPush()
+
+	if s.cl != nil {
+		endCL := t.span("cl", inst)
+		if err := applyCL(ctx, inst, s.cl); err != nil {
+			return err
+		}
+		endCL()
+	}
+
+	// Create a file to write output to so it doesn't get lost.
+	outf, err := os.Create(filepath.Join(s.logDir, inst+".log"))
+	if err != nil {
+		return err
+	}
+	defer outf.Close()
+	fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Streaming results from %s to %q...", styles.Instance(inst), outf.Name())))
+
+	// If this is the only command running, print to stdout too, for convenience and
+	// backwards compatibility.
+	outputs := []io.Writer{outf}
+	if s.detailedProgress {
+		outputs = append(outputs, os.Stdout)
+	}
+	var ce *cmdFailedError
+	env := s.env
+	retried := false
+	for i := 0; i < len(cmds); i++ {
+		sc := cmds[i]
+		if !s.detailedProgress {
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Running %q on %s...", sc, styles.Instance(inst))))
+		}
+		endCmd := t.span(path.Base(sc.cmd), inst)
+		err := doRun(ctx, inst, sc.cmd, sc.args, runWriters(outputs...), runEnv(env))
+		endCmd()
+		if err != nil && !errors.As(err, &ce) {
+			return err
+		}
+		// The first command is the make script, which may fail for
+		// lack of a bootstrap version of Go. Install one and try again.
+		if ce != nil && i == 0 && s.autoBootstrap && !retried {
+			retried = true
+			if benv, ok := s.installBootstrap(ctx, t, inst, workDir, outf); ok {
+				env = append(append([]string(nil), s.env...), benv...)
+				ce = nil
+				i--
+				continue
+			}
+		}
+		if ce != nil {
+			fmt.Fprintln(outf, ce.Error())
+			break
+		}
+	}
+	s.mu.Lock()
+	s.results[inst] = setupResult{log: outf.Name(), err: ce}
+	s.mu.Unlock()
+	return nil
+}
+
+// installBootstrap installs a bootstrap version of Go on inst, whose work
+// directory is workDir, if the output of its make script, so far written
+// to outf, says that it lacks one. It returns the environment to build Go
+// with it, and whether it was installed. A failure to install it is
+// written to outf, for the make script's failure to be reported as usual.
+func (s *setup) installBootstrap(ctx context.Context, t *timer, inst, workDir string, outf *os.File) (env []string, ok bool) {
+	tail, err := readTail(outf.Name(), setupLogTailLines)
+	if err != nil {
+		return nil, false
+	}
+	version, missing := bootstrapRequired(string(tail))
+	if !missing {
+		return nil, false
+	}
+	fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# %s lacks a bootstrap version of Go; installing one and building again...", styles.Instance(inst))))
+	defer t.span("bootstrap", inst)()
+	update, err := doPutBootstrap(ctx, &protos.AddBootstrapRequest{GomoteId: inst, Version: version})
+	if err != nil {
+		fmt.Fprintf(outf, "# Unable to install a bootstrap version of Go: %v\n", err)
+		return nil, false
+	}
+	if update.GetBootstrapGoUrl() == "" {
+		fmt.Fprintln(outf, "# No bootstrap version of Go is defined for the instance.")
+		return nil, false
+	}
+	fmt.Fprintf(outf, "# Installed a bootstrap version of Go from %s; building again.\n", update.GetBootstrapGoUrl())
+	if dir := update.GetDirectory(); dir != "" && workDir != "" {
+		env = []string{"GOROOT_BOOTSTRAP=" + remoteJoin(workDir, dir)}
+	}
+	return env, true
+}
+
+// bootstrapRequiredRE matches the message of the make scripts of Go which
+// fail for lack of a bootstrap version of Go, such as "Set
+// $GOROOT_BOOTSTRAP to a working Go tree >= Go 1.22.6.", which make.bat
+// prints without the dollar sign.
+var bootstrapRequiredRE = regexp.MustCompile(`Set \$?GOROOT_BOOTSTRAP to a working Go tree(?: >= Go ([0-9]+\.[0-9]+(?:\.[0-9]+)?))?`)
+
+// bootstrapRequired reports whether the output of a make script says that
+// it failed for lack of a bootstrap version of Go, and the minimum version
+// it requires, if it says.
+func bootstrapRequired(output string) (version string, missing bool) {
+	m := bootstrapRequiredRE.FindStringSubmatch(output)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// remoteJoin joins dir, a slash-separated path relative to the work
+// directory workDir of an instance, to workDir, using the separator that
+// workDir uses.
+func remoteJoin(workDir, dir string) string {
+	if strings.Contains(workDir, `\`) {
+		return strings.TrimRight(workDir, `\`) + `\` + strings.ReplaceAll(dir, "/", `\`)
+	}
+	return path.Join(workDir, dir)
+}
+
+// report writes the results of the setup to w, like reportSetupResults.
+func (s *setup) report(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return reportSetupResults(w, s.results)
+}
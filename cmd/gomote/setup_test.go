@@ -0,0 +1,70 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSetup(t *testing.T) {
+	ctx := context.Background()
+	for _, flags := range []setupFlags{
+		{run: "  "},
+		{cl: "abc"},
+		{cl: "12345/0"},
+	} {
+		if _, err := newSetup(ctx, flags, true); err == nil || exitCode(err) != exitUsage {
+			t.Errorf("newSetup(%+v) = %v; want a usage error", flags, err)
+		}
+	}
+
+	logDir := filepath.Join(t.TempDir(), "logs")
+	s, err := newSetup(ctx, setupFlags{run: "all", logDir: logDir}, false)
+	if err != nil {
+		t.Fatalf("newSetup: %v", err)
+	}
+	if s.target != "all" || s.cl != nil || s.detailedProgress {
+		t.Errorf("newSetup = %+v; want target all, no CL and no detailed progress", s)
+	}
+	if fi, err := os.Stat(logDir); err != nil || !fi.IsDir() {
+		t.Errorf("newSetup didn't create the log directory %s: %v", logDir, err)
+	}
+}
+
+func TestBootstrapRequired(t *testing.T) {
+	for _, tc := range []struct {
+		output      string
+		wantVersion string
+		wantMissing bool
+	}{
+		{"ERROR: Cannot find /home/gopher/go1.4/bin/go.\nSet $GOROOT_BOOTSTRAP to a working Go tree >= Go 1.22.6.\n", "1.22.6", true},
+		{"ERROR: Cannot find C:\\workdir\\go1.4\\bin\\go.exe\nSet GOROOT_BOOTSTRAP to a working Go tree >= Go 1.20.\n", "1.20", true},
+		{"Set $GOROOT_BOOTSTRAP to a working Go tree >= Go", "", true},
+		{"Building Go cmd/dist using /home/gopher/go1.4.\nfoo.go:1: syntax error\n", "", false},
+	} {
+		version, missing := bootstrapRequired(tc.output)
+		if version != tc.wantVersion || missing != tc.wantMissing {
+			t.Errorf("bootstrapRequired(%q) = %q, %t; want %q, %t", tc.output, version, missing, tc.wantVersion, tc.wantMissing)
+		}
+	}
+}
+
+func TestRemoteJoin(t *testing.T) {
+	for _, tc := range []struct {
+		workDir, dir, want string
+	}{
+		{"/home/gopher/workdir", "go1.4", "/home/gopher/workdir/go1.4"},
+		{"/tmp/workdir/", "1.22.6", "/tmp/workdir/1.22.6"},
+		{`C:\workdir`, "go1.4", `C:\workdir\go1.4`},
+		{`C:\workdir\`, "go-bootstrap/go", `C:\workdir\go-bootstrap\go`},
+	} {
+		if got := remoteJoin(tc.workDir, tc.dir); got != tc.want {
+			t.Errorf("remoteJoin(%q, %q) = %q; want %q", tc.workDir, tc.dir, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/term"
+)
+
+func shell(args []string) error {
+	fs := shellFlagSet()
+	parseFlags(fs, args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+	}
+
+	ctx := context.Background()
+	// Dial the server once up front; the connection is reused by every command.
+	gomoteServerClient(ctx)
+	sh := &shellSession{ctx: ctx, out: os.Stdout}
+	if fs.NArg() == 1 {
+		if err := sh.use([]string{fs.Arg(0)}); err != nil {
+			return err
+		}
+	} else if activeGroup != nil {
+		sh.useGroup(activeGroup)
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// Read commands from a script.
+		s := bufio.NewScanner(os.Stdin)
+		for s.Scan() {
+			if sh.exec(s.Text()) {
+				return nil
+			}
+		}
+		return s.Err()
+	}
+
+	fmt.Fprintln(os.Stderr, "# Type \"help\" for a list of commands; exiting the shell does not destroy any instances.")
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return completeShellLine(line, pos, sh.listDir)
+	}
+	for {
+		t.SetPrompt(sh.prompt())
+		// Only put the terminal in raw mode while reading a line, so
+		// that commands print as usual.
+		state, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		line, err := t.ReadLine()
+		term.Restore(int(os.Stdin.Fd()), state)
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if sh.exec(line) {
+			return nil
+		}
+	}
+}
+
+func shellFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "shell usage: gomote shell [instance]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Starts an interactive shell which runs commands on a target instance")
+		fmt.Fprintln(os.Stderr, "or group over a single connection to the gomote server. The target")
+		fmt.Fprintln(os.Stderr, "defaults to the active group. Type \"help\" in the shell for a list")
+		fmt.Fprintln(os.Stderr, "of commands.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	return fs
+}
+
+// shellSession is the state of a gomote shell.
+type shellSession struct {
+	ctx context.Context
+	out io.Writer
+
+	// target is the name of the current instance, or group:NAME if
+	// the current target is a group.
+	target string
+	insts  []string
+}
+
+type shellCommand struct {
+	run   func(sh *shellSession, args []string) error
+	usage string
+	desc  string
+}
+
+var shellCommands map[string]shellCommand
+
+func init() {
+	shellCommands = map[string]shellCommand{
+		"use":  {(*shellSession).use, "use <instance> | use group:<name>", "set the target instance or group"},
+		"run":  {(*shellSession).run, "run <cmd> [args...]", "run a command on the target"},
+		"push": {(*shellSession).push, "push", "sync your GOROOT directory to the target"},
+		"ls":   {(*shellSession).ls, "ls [dir]", "list the contents of a directory on the target"},
+		"ping": {(*shellSession).ping, "ping", "test whether the target is alive"},
+		"help": {(*shellSession).help, "help", "list the shell commands"},
+		"exit": {nil, "exit", "exit the shell, leaving all instances running"},
+	}
+}
+
+func (sh *shellSession) prompt() string {
+	if sh.target == "" {
+		return "gomote> "
+	}
+	return fmt.Sprintf("gomote(%s)> ", sh.target)
+}
+
+// exec executes a line of input and reports whether the shell should exit.
+func (sh *shellSession) exec(line string) bool {
+	args, err := splitShellLine(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return false
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+		return false
+	}
+	if args[0] == "exit" || args[0] == "quit" {
+		return true
+	}
+	cmd, ok := shellCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q; type \"help\" for a list of commands\n", args[0])
+		return false
+	}
+
+	// Interrupting a command cancels it rather than exiting the shell.
+	ctx, cancel := context.WithCancel(sh.ctx)
+	defer cancel()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	go func() {
+		select {
+		case <-sigc:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	cmdSh := *sh
+	cmdSh.ctx = ctx
+	if err := cmd.run(&cmdSh, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, styles.Failure(fmt.Sprintf("# %s: %v", args[0], err)))
+	}
+	// Keep any change of target.
+	sh.target, sh.insts = cmdSh.target, cmdSh.insts
+	return false
+}
+
+func (sh *shellSession) useGroup(g *groupData) {
+	sh.target = "group:" + g.Name
+	sh.insts = append([]string(nil), g.Instances...)
+}
+
+func (sh *shellSession) use(args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("usage: %s", shellCommands["use"].usage)
+	}
+	if name, ok := strings.CutPrefix(args[0], "group:"); ok {
+		g, err := loadGroup(name)
+		if err != nil {
+			return err
+		}
+		sh.useGroup(g)
+		return nil
+	}
+	inst, err := resolveInstance(sh.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if err := doPing(sh.ctx, inst); err != nil {
+		return fmt.Errorf("instance %q: %w", inst, err)
+	}
+	sh.target, sh.insts = inst, []string{inst}
+	return nil
+}
+
+// targets returns the instances commands should operate on.
+func (sh *shellSession) targets() ([]string, error) {
+	if len(sh.insts) == 0 {
+		return nil, errors.New(`no target; set one with "use <instance>"`)
+	}
+	return sh.insts, nil
+}
+
+// forEachTarget calls f for each target instance, printing a header
+// before each one if there are several.
+func (sh *shellSession) forEachTarget(f func(inst string) error) error {
+	insts, err := sh.targets()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, inst := range insts {
+		if len(insts) > 1 {
+			fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# %s", styles.Instance(inst))))
+		}
+		if err := f(inst); err != nil {
+			if len(insts) == 1 {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, styles.Failure(fmt.Sprintf("# %s: %v", inst, err)))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (sh *shellSession) run(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: %s", shellCommands["run"].usage)
+	}
+	return sh.forEachTarget(func(inst string) error {
+		return doRun(sh.ctx, inst, args[0], args[1:], runWriters(sh.out))
+	})
+}
+
+func (sh *shellSession) push(args []string) error {
+	if len(args) != 0 {
+		return usageErrorf("usage: %s", shellCommands["push"].usage)
+	}
+	goroot, err := getGOROOT()
+	if err != nil {
+		return err
+	}
+	insts, err := sh.targets()
+	if err != nil {
+		return err
+	}
+	src := newPushSource(goroot, gzip.DefaultCompression)
+	return sh.forEachTarget(func(inst string) error {
+		fmt.Fprintln(os.Stderr, styles.Status(fmt.Sprintf("# Pushing GOROOT %q to %s...", goroot, styles.Instance(inst))))
+		return doPush(sh.ctx, inst, src, false, len(insts) == 1)
+	})
+}
+
+func (sh *shellSession) ls(args []string) error {
+	if len(args) > 1 {
+		return usageErrorf("usage: %s", shellCommands["ls"].usage)
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	return sh.forEachTarget(func(inst string) error {
+		resp, err := gomoteServerClient(sh.ctx).ListDirectory(sh.ctx, &protos.ListDirectoryRequest{
+			GomoteId:  inst,
+			Directory: dir,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to ls: %w", err)
+		}
+		for _, entry := range resp.GetEntries() {
+			fmt.Fprintln(sh.out, entry)
+		}
+		return nil
+	})
+}
+
+func (sh *shellSession) ping(args []string) error {
+	if len(args) != 0 {
+		return usageErrorf("usage: %s", shellCommands["ping"].usage)
+	}
+	return sh.forEachTarget(func(inst string) error {
+		if err := doPing(sh.ctx, inst); err != nil {
+			return err
+		}
+		fmt.Fprintf(sh.out, "%s: alive\n", inst)
+		return nil
+	})
+}
+
+func (sh *shellSession) help(args []string) error {
+	var names []string
+	for name := range shellCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(sh.out, "  %-34s %s\n", shellCommands[name].usage, shellCommands[name].desc)
+	}
+	return nil
+}
+
+// listDir returns the names of the entries of dir on the first target
+// instance, for completion. Directories end in a slash.
+func (sh *shellSession) listDir(dir string) ([]string, error) {
+	insts, err := sh.targets()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := gomoteServerClient(sh.ctx).ListDirectory(sh.ctx, &protos.ListDirectoryRequest{
+		GomoteId:  insts[0],
+		Directory: dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range resp.GetEntries() {
+		names = append(names, buildlet.DirEntry{Line: entry}.Name())
+	}
+	return names, nil
+}
+
+// splitShellLine splits a line of shell input into words. Words are
+// separated by spaces, and single or double quotes may be used to include
+// spaces in a word.
+func splitShellLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// completeShellLine completes the word before pos in line. The first word
+// is completed from the shell commands, and later ones from the remote
+// paths returned by listDir.
+func completeShellLine(line string, pos int, listDir func(dir string) ([]string, error)) (string, int, bool) {
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	word := line[start:pos]
+	var candidates []string
+	if strings.TrimSpace(line[:start]) == "" {
+		for name := range shellCommands {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name+" ")
+			}
+		}
+	} else {
+		dir, base := path.Split(word)
+		listed := dir
+		if listed == "" {
+			listed = "."
+		}
+		names, err := listDir(listed)
+		if err != nil {
+			return "", 0, false
+		}
+		for _, name := range names {
+			if !strings.HasPrefix(name, base) {
+				continue
+			}
+			if !strings.HasSuffix(name, "/") {
+				name += " "
+			}
+			candidates = append(candidates, dir+name)
+		}
+	}
+	completed := commonPrefix(candidates)
+	if len(completed) <= len(word) {
+		return "", 0, false
+	}
+	return line[:start] + completed + line[pos:], start + len(completed), true
+}
+
+// commonPrefix returns the longest common prefix of strs.
+func commonPrefix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
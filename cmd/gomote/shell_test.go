@@ -0,0 +1,75 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitShellLine(t *testing.T) {
+	testCases := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"run go/bin/go test", []string{"run", "go/bin/go", "test"}},
+		{"run  go/bin/go\ttest ", []string{"run", "go/bin/go", "test"}},
+		{`run echo "hello world"`, []string{"run", "echo", "hello world"}},
+		{`run echo 'it"s' ""`, []string{"run", "echo", `it"s`, ""}},
+		{`ls go/"my dir"/x`, []string{"ls", "go/my dir/x"}},
+	}
+	for _, tc := range testCases {
+		got, err := splitShellLine(tc.line)
+		if err != nil {
+			t.Errorf("splitShellLine(%q) = %v", tc.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitShellLine(%q) = %q; want %q", tc.line, got, tc.want)
+		}
+	}
+	if _, err := splitShellLine(`run echo "oops`); err == nil {
+		t.Errorf("splitShellLine with unterminated quote = nil error; want error")
+	}
+}
+
+func TestCompleteShellLine(t *testing.T) {
+	listDir := func(dir string) ([]string, error) {
+		switch dir {
+		case ".":
+			return []string{"go/", "gocache/", "tmp/"}, nil
+		case "go/src/":
+			return []string{"make.bash", "make.bat", "net/", "os/"}, nil
+		}
+		return nil, errors.New("no such directory")
+	}
+	testCases := []struct {
+		line     string
+		pos      int
+		want     string
+		wantPos  int
+		wantDone bool
+	}{
+		{"pu", 2, "push ", 5, true},
+		{"p", 1, "", 0, false}, // ping or push
+		{"ls g", 4, "ls go", 5, true},
+		{"ls go", 5, "", 0, false}, // go/ and gocache/
+		{"ls t", 4, "ls tmp/", 7, true},
+		{"run go/src/m", 12, "run go/src/make.ba", 18, true},
+		{"run go/src/n", 12, "run go/src/net/", 15, true},
+		{"run go/src/mk", 13, "", 0, false},
+		{"ls nope/x", 9, "", 0, false},
+		{"ls t go/src/os", 4, "ls tmp/ go/src/os", 7, true},
+	}
+	for _, tc := range testCases {
+		got, gotPos, ok := completeShellLine(tc.line, tc.pos, listDir)
+		if ok != tc.wantDone || got != tc.want || gotPos != tc.wantPos {
+			t.Errorf("completeShellLine(%q, %d) = %q, %d, %t; want %q, %d, %t", tc.line, tc.pos, got, gotPos, ok, tc.want, tc.wantPos, tc.wantDone)
+		}
+	}
+}
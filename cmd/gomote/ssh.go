@@ -20,21 +20,19 @@ import (
 
 func ssh(args []string) error {
 	if activeGroup != nil {
-		return fmt.Errorf("command does not support groups")
+		return usageErrorf("command does not support groups")
 	}
 
-	fs := flag.NewFlagSet("ssh", flag.ContinueOnError)
-	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "ssh usage: gomote ssh <instance>")
-		fs.PrintDefaults()
-		os.Exit(1)
-	}
-	fs.Parse(args)
+	fs := sshFlagSet()
+	parseFlags(fs, args)
 	if fs.NArg() != 1 {
 		fs.Usage()
 	}
 
-	name := fs.Arg(0)
+	name, err := resolveInstance(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
 	sshKeyDir, err := sshConfigDirectory()
 	if err != nil {
 		return err
@@ -63,6 +61,16 @@ func ssh(args []string) error {
 	return sshConnect(name, priKey, certPath)
 }
 
+func sshFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("ssh", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "ssh usage: gomote ssh <instance>")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	return fs
+}
+
 func sshConfigDirectory() (string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -0,0 +1,227 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/types"
+)
+
+func instanceStatus(args []string) error {
+	var flags statusFlags
+	fs := statusFlagSet(&flags)
+	parseFlags(fs, args)
+
+	var statusSet []string
+	var detailed bool
+	if fs.NArg() == 1 {
+		inst, err := resolveInstance(context.Background(), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		statusSet = []string{inst}
+		detailed = true
+	} else if fs.NArg() == 0 && activeGroup != nil {
+		statusSet = activeGroup.Instances
+	} else {
+		fs.Usage()
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	var statuses []instanceStatusJSON
+	for _, inst := range statusSet {
+		st, err := doStatus(ctx, inst, now)
+		if err != nil {
+			if detailed {
+				return err
+			}
+			st = instanceStatusJSON{Instance: types.GomoteInstanceInfo{ID: inst}, Error: err.Error()}
+		}
+		statuses = append(statuses, st)
+	}
+	switch {
+	case flags.jsonOut && detailed:
+		return writeJSON(os.Stdout, statuses[0])
+	case flags.jsonOut:
+		return writeJSON(os.Stdout, statuses)
+	case detailed:
+		return writeStatusBlock(os.Stdout, statuses[0], now)
+	}
+	return writeStatusRows(os.Stdout, statuses)
+}
+
+// statusFlags are the flags of the status command.
+type statusFlags struct {
+	jsonOut bool
+}
+
+func statusFlagSet(flags *statusFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "status usage: gomote status [status-opts] [instance]")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Shows detailed information about an instance, or a summary")
+		fmt.Fprintln(os.Stderr, "row for each instance in a group.")
+		fmt.Fprintln(os.Stderr, "Instance name is optional if a group is specified.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.jsonOut, "json", false, "print the status as JSON")
+	return fs
+}
+
+// instanceStatusJSON is the JSON representation of an instance printed by "gomote status -json".
+type instanceStatusJSON struct {
+	// Instance describes the instance. Only its ID is set if Error is.
+	Instance types.GomoteInstanceInfo `json:"instance"`
+	// Remaining is the remaining lifetime of the instance at the time
+	// of the status, such as "29m41s".
+	Remaining       string `json:"remaining,omitempty"`
+	WorkDir         string `json:"work_dir,omitempty"`
+	ActiveCommands  int    `json:"active_commands"`
+	ActiveSessions  int    `json:"active_ssh_sessions"`
+	Reachable       bool   `json:"reachable"`
+	BuildletVersion int    `json:"buildlet_version,omitempty"`
+	// The following describe the instance's machine. They are zero if
+	// the buildlet doesn't report them.
+	DiskFree     int64     `json:"disk_free_bytes,omitempty"`
+	DiskTotal    int64     `json:"disk_total_bytes,omitempty"`
+	MemTotal     int64     `json:"mem_total_bytes,omitempty"`
+	MemAvailable int64     `json:"mem_available_bytes,omitempty"`
+	LoadAverage  []float64 `json:"load_average,omitempty"`
+	WorkDirSize  int64     `json:"work_dir_size_bytes,omitempty"`
+	// Error is set if the status of the instance could not be retrieved.
+	Error string `json:"error,omitempty"`
+}
+
+func doStatus(ctx context.Context, name string, now time.Time) (instanceStatusJSON, error) {
+	client := gomoteServerClient(ctx)
+	resp, err := client.InstanceStatus(ctx, &protos.InstanceStatusRequest{
+		GomoteId: name,
+	})
+	if err != nil {
+		return instanceStatusJSON{}, fmt.Errorf("unable to retrieve status of instance %s: %w", name, err)
+	}
+	inst := buildlet.RemoteBuildletFromInstance(resp.GetInstance())
+	return instanceStatusJSON{
+		Instance:        inst.InstanceInfo(),
+		Remaining:       remainingLifetime(inst.Expires, now),
+		WorkDir:         resp.GetInstance().GetWorkingDir(),
+		ActiveCommands:  int(resp.GetActiveCommands()),
+		ActiveSessions:  int(resp.GetActiveSessions()),
+		Reachable:       resp.GetReachable(),
+		BuildletVersion: int(resp.GetBuildletVersion()),
+		DiskFree:        resp.GetDiskFreeBytes(),
+		DiskTotal:       resp.GetDiskTotalBytes(),
+		MemTotal:        resp.GetMemTotalBytes(),
+		MemAvailable:    resp.GetMemAvailableBytes(),
+		LoadAverage:     resp.GetLoadAverage(),
+		WorkDirSize:     resp.GetWorkDirSizeBytes(),
+	}, nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v)
+}
+
+// writeStatusBlock writes a detailed, human readable description of the status of a single instance.
+func writeStatusBlock(w io.Writer, st instanceStatusJSON, now time.Time) error {
+	inst := st.Instance
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "instance:\t%s\n", inst.ID)
+	fmt.Fprintf(tw, "builder type:\t%s\n", inst.BuilderType)
+	fmt.Fprintf(tw, "host type:\t%s\n", inst.HostType)
+	if len(inst.Labels) > 0 {
+		fmt.Fprintf(tw, "labels:\t%s\n", formatLabels(inst.Labels))
+	}
+	if !inst.Created.IsZero() {
+		fmt.Fprintf(tw, "created:\t%s (%v ago)\n", inst.Created.Local().Format(time.RFC1123), now.Sub(inst.Created).Round(time.Second))
+	} else {
+		fmt.Fprintf(tw, "created:\tunknown\n")
+	}
+	fmt.Fprintf(tw, "expires:\t%s (in %s)\n", inst.Expires.Local().Format(time.RFC1123), st.Remaining)
+	workDir := st.WorkDir
+	if workDir == "" {
+		workDir = "unknown"
+	}
+	fmt.Fprintf(tw, "work dir:\t%s\n", workDir)
+	fmt.Fprintf(tw, "running:\t%s\n", runningString(st.ActiveCommands))
+	fmt.Fprintf(tw, "ssh sessions:\t%d\n", st.ActiveSessions)
+	if st.Reachable {
+		fmt.Fprintf(tw, "buildlet:\treachable (version %d)\n", st.BuildletVersion)
+	} else {
+		fmt.Fprintf(tw, "buildlet:\tunreachable\n")
+	}
+	if st.DiskTotal > 0 {
+		fmt.Fprintf(tw, "disk:\t%s free of %s\n", formatBytes(st.DiskFree), formatBytes(st.DiskTotal))
+	}
+	if st.WorkDirSize > 0 {
+		fmt.Fprintf(tw, "work dir size:\t%s\n", formatBytes(st.WorkDirSize))
+	}
+	switch {
+	case st.MemAvailable > 0:
+		fmt.Fprintf(tw, "memory:\t%s available of %s\n", formatBytes(st.MemAvailable), formatBytes(st.MemTotal))
+	case st.MemTotal > 0:
+		fmt.Fprintf(tw, "memory:\t%s\n", formatBytes(st.MemTotal))
+	}
+	if len(st.LoadAverage) == 3 {
+		fmt.Fprintf(tw, "load average:\t%.2f %.2f %.2f\n", st.LoadAverage[0], st.LoadAverage[1], st.LoadAverage[2])
+	}
+	return tw.Flush()
+}
+
+// formatBytes formats n bytes in binary units, such as "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1 << 10
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTP"[exp])
+}
+
+// writeStatusRows writes a compact row describing the status of each instance.
+func writeStatusRows(w io.Writer, statuses []instanceStatusJSON) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, st := range statuses {
+		if st.Error != "" {
+			fmt.Fprintf(tw, "%s\terror: %s\n", st.Instance.ID, st.Error)
+			continue
+		}
+		health := "unreachable"
+		if st.Reachable {
+			health = "reachable"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\texpires in %s\n", st.Instance.ID, st.Instance.BuilderType, health, runningString(st.ActiveCommands), st.Remaining)
+	}
+	return tw.Flush()
+}
+
+func runningString(activeCommands int) string {
+	switch activeCommands {
+	case 0:
+		return "idle"
+	case 1:
+		return "running 1 command"
+	}
+	return fmt.Sprintf("running %d commands", activeCommands)
+}
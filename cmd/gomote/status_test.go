@@ -0,0 +1,86 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/build/types"
+)
+
+func TestFormatBytes(t *testing.T) {
+	for _, tc := range []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{3 << 19, "1.5 MiB"},
+		{100 << 30, "100.0 GiB"},
+		{2 << 40, "2.0 TiB"},
+	} {
+		if got := formatBytes(tc.n); got != tc.want {
+			t.Errorf("formatBytes(%d) = %q; want %q", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestWriteStatusBlockMachine(t *testing.T) {
+	now := time.Now()
+	st := instanceStatusJSON{
+		Instance:        types.GomoteInstanceInfo{ID: "inst", Expires: now.Add(time.Hour)},
+		Remaining:       "1h0m0s",
+		Reachable:       true,
+		BuildletVersion: 35,
+		DiskFree:        10 << 30,
+		DiskTotal:       100 << 30,
+		MemTotal:        16 << 30,
+		MemAvailable:    4 << 30,
+		LoadAverage:     []float64{0.5, 1, 1.25},
+		WorkDirSize:     512 << 20,
+	}
+	var b strings.Builder
+	if err := writeStatusBlock(&b, st, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"disk:          10.0 GiB free of 100.0 GiB\n",
+		"work dir size: 512.0 MiB\n",
+		"memory:        4.0 GiB available of 16.0 GiB\n",
+		"load average:  0.50 1.00 1.25\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("writeStatusBlock output is missing %q:\n%s", want, b.String())
+		}
+	}
+
+	// Buildlets which don't describe their machine get no such lines.
+	st.DiskFree, st.DiskTotal, st.MemTotal, st.MemAvailable, st.LoadAverage, st.WorkDirSize = 0, 0, 0, 0, nil, 0
+	b.Reset()
+	if err := writeStatusBlock(&b, st, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"disk:", "work dir size:", "memory:", "load average:"} {
+		if strings.Contains(b.String(), line) {
+			t.Errorf("writeStatusBlock output unexpectedly contains %q:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestLowDiskSpaceWarning(t *testing.T) {
+	if got := lowDiskSpaceWarning("inst", 0); got != "" {
+		t.Errorf("lowDiskSpaceWarning with unknown free space = %q; want no warning", got)
+	}
+	if got := lowDiskSpaceWarning("inst", 50<<30); got != "" {
+		t.Errorf("lowDiskSpaceWarning with 50 GiB free = %q; want no warning", got)
+	}
+	got := lowDiskSpaceWarning("inst", 300<<20)
+	if !strings.Contains(got, "inst has only 300.0 MiB of free disk space") {
+		t.Errorf("lowDiskSpaceWarning with 300 MiB free = %q; want a warning", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+func tail(args []string) error {
+	var flags tailFlags
+	fs := tailFlagSet(&flags)
+	parseFlags(fs, args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+	}
+	if flags.lines < 0 {
+		return usageErrorf("-n must not be negative")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	inst, err := resolveInstance(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return tailFile(ctx, gomoteServerClient(ctx), inst, fs.Arg(1), flags, os.Stdout, os.Stderr)
+}
+
+// tailFile writes the contents of the file at path on inst to stdout,
+// and notices about the file to stderr. When following the file, it
+// returns once ctx is done.
+func tailFile(ctx context.Context, client protos.GomoteServiceClient, inst, path string, flags tailFlags, stdout, stderr io.Writer) error {
+	stream, err := client.TailFile(ctx, &protos.TailFileRequest{
+		GomoteId: inst,
+		Path:     path,
+		Lines:    int32(flags.lines),
+		Follow:   flags.follow,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to tail: %w", err)
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// Interrupted, which is how following ends.
+				return nil
+			}
+			return fmt.Errorf("unable to tail: %w", err)
+		}
+		if resp.GetTruncated() {
+			fmt.Fprintln(stderr, styles.Status(fmt.Sprintf("# %s was truncated; restarting from its beginning", path)))
+		}
+		if _, err := stdout.Write(resp.GetData()); err != nil {
+			return err
+		}
+	}
+}
+
+// tailFlags are the flags of the tail command.
+type tailFlags struct {
+	follow bool
+	lines  int
+}
+
+func tailFlagSet(flags *tailFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "tail usage: gomote tail [tail-opts] <instance> <path>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "The path is relative to the work directory. With -f, tail")
+		fmt.Fprintln(os.Stderr, "keeps printing data appended to the file until interrupted,")
+		fmt.Fprintln(os.Stderr, "starting over if the file is truncated or replaced.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.follow, "f", false, "follow the file, printing data appended to it")
+	fs.IntVar(&flags.lines, "n", 10, "print the last `N` lines of the file; 0 prints the whole file")
+	return fs
+}
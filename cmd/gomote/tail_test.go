@@ -0,0 +1,87 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+)
+
+// fakeTailClient is a GomoteServiceClient which streams responses to
+// TailFile requests, then ends the stream with err.
+type fakeTailClient struct {
+	protos.GomoteServiceClient
+	resps []*protos.TailFileResponse
+	err   error
+	req   *protos.TailFileRequest
+}
+
+func (c *fakeTailClient) TailFile(_ context.Context, req *protos.TailFileRequest, _ ...grpc.CallOption) (protos.GomoteService_TailFileClient, error) {
+	c.req = req
+	return &fakeTailStream{resps: c.resps, err: c.err}, nil
+}
+
+type fakeTailStream struct {
+	grpc.ClientStream
+	resps []*protos.TailFileResponse
+	err   error
+}
+
+func (s *fakeTailStream) Recv() (*protos.TailFileResponse, error) {
+	if len(s.resps) == 0 {
+		return nil, s.err
+	}
+	resp := s.resps[0]
+	s.resps = s.resps[1:]
+	return resp, nil
+}
+
+func TestTailFile(t *testing.T) {
+	client := &fakeTailClient{
+		resps: []*protos.TailFileResponse{
+			{Data: []byte("one\n")},
+			{Truncated: true},
+			{Data: []byte("two\n")},
+		},
+		err: io.EOF,
+	}
+	var stdout, stderr bytes.Buffer
+	err := tailFile(context.Background(), client, "inst", "go/test.log", tailFlags{follow: true, lines: 5}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("tailFile: %v", err)
+	}
+	if got := client.req; got.GetGomoteId() != "inst" || got.GetPath() != "go/test.log" || got.GetLines() != 5 || !got.GetFollow() {
+		t.Errorf("request = %v; want instance, path, 5 lines and follow", got)
+	}
+	if stdout.String() != "one\ntwo\n" {
+		t.Errorf("stdout = %q; want %q", stdout.String(), "one\ntwo\n")
+	}
+	if !strings.Contains(stderr.String(), "truncated") {
+		t.Errorf("stderr = %q; want a notice about truncation", stderr.String())
+	}
+}
+
+func TestTailFileErrors(t *testing.T) {
+	client := &fakeTailClient{err: errors.New("no such file")}
+	var stdout, stderr bytes.Buffer
+	if err := tailFile(context.Background(), client, "inst", "missing", tailFlags{}, &stdout, &stderr); err == nil {
+		t.Errorf("tailFile with failing stream succeeded; want error")
+	}
+
+	// Interrupting ends following without an error.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client = &fakeTailClient{err: context.Canceled}
+	if err := tailFile(ctx, client, "inst", "go/test.log", tailFlags{follow: true}, &stdout, &stderr); err != nil {
+		t.Errorf("interrupted tailFile = %v; want nil", err)
+	}
+}
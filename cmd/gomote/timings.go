@@ -0,0 +1,220 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/build/buildlet"
+)
+
+// timingsThreshold is the total duration above which the timing summary
+// is printed even without -timings.
+const timingsThreshold = time.Minute
+
+const timingsUsage = "print a breakdown of where the time went once done; -timings=json prints it as JSON (the breakdown is always printed if the command takes more than a minute)"
+
+// timingsFlag is the value of the -timings flag: "", "text" or "json".
+// It may be used as a boolean flag.
+type timingsFlag string
+
+func (f *timingsFlag) String() string { return string(*f) }
+
+func (f *timingsFlag) Set(v string) error {
+	switch v {
+	case "true", "text":
+		*f = "text"
+	case "false", "":
+		*f = ""
+	case "json":
+		*f = "json"
+	default:
+		return fmt.Errorf("want true, false, text or json")
+	}
+	return nil
+}
+
+func (f *timingsFlag) IsBoolFlag() bool { return true }
+
+// span is a timed phase of a command.
+type span struct {
+	Name     string        `json:"name"`
+	Instance string        `json:"instance,omitempty"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// rpc is an RPC made to the gomote server during a command.
+type rpc struct {
+	Method        string        `json:"method"`
+	Instance      string        `json:"instance,omitempty"`
+	Start         time.Time     `json:"start"`
+	Duration      time.Duration `json:"duration_ns"`
+	BytesSent     int64         `json:"bytes_sent"`
+	BytesReceived int64         `json:"bytes_received"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// timer records the spans of a command, and the RPCs it makes as a
+// buildlet.Observer. It is safe for concurrent use.
+type timer struct {
+	cmd   string
+	start time.Time
+
+	mu    sync.Mutex
+	spans []span
+	rpcs  []rpc
+}
+
+// newTimer returns a timer for cmd which observes the RPCs made to the
+// gomote server.
+func newTimer(cmd string) *timer {
+	t := &timer{cmd: cmd, start: time.Now()}
+	observeRPCs(t)
+	return t
+}
+
+// RequestStart implements buildlet.Observer.
+func (t *timer) RequestStart(buildlet.Request) {}
+
+// RequestDone implements buildlet.Observer.
+func (t *timer) RequestDone(r buildlet.Request, s buildlet.RequestStats) {
+	c := rpc{
+		Method:        path.Base(r.Method),
+		Instance:      r.Instance,
+		Start:         r.Start,
+		Duration:      s.Duration,
+		BytesSent:     s.BytesSent,
+		BytesReceived: s.BytesReceived,
+	}
+	if s.Err != nil {
+		c.Error = s.Err.Error()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rpcs = append(t.rpcs, c)
+}
+
+// add adds a span which has already ended.
+func (t *timer) add(s span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, s)
+}
+
+// record records the span name on inst, which started at start and ends now.
+func (t *timer) record(name, inst string, start time.Time) {
+	t.add(span{Name: name, Instance: inst, Start: start, Duration: time.Since(start)})
+}
+
+// span starts the span name on inst and returns a function which ends it.
+func (t *timer) span(name, inst string) func() {
+	start := time.Now()
+	return func() { t.record(name, inst, start) }
+}
+
+// report writes the recorded spans to w according to mode. A text summary
+// is written regardless of mode if the command took longer than
+// timingsThreshold.
+func (t *timer) report(w io.Writer, mode timingsFlag) {
+	total := time.Since(t.start)
+	if mode == "" && total <= timingsThreshold {
+		return
+	}
+	t.mu.Lock()
+	spans := append([]span(nil), t.spans...)
+	rpcs := append([]rpc(nil), t.rpcs...)
+	t.mu.Unlock()
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	sort.SliceStable(rpcs, func(i, j int) bool {
+		return rpcs[i].Start.Before(rpcs[j].Start)
+	})
+	var err error
+	if mode == "json" {
+		err = writeTimingsJSON(w, t.cmd, t.start, total, spans, rpcs)
+	} else {
+		err = writeTimings(w, total, spans, rpcs)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "# Unable to write timings: %v\n", err)
+	}
+}
+
+func writeTimings(w io.Writer, total time.Duration, spans []span, rpcs []rpc) error {
+	fmt.Fprintln(w, "# Timings:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, s := range spans {
+		fmt.Fprintf(tw, "#   %s\t%s\t%v\n", s.Name, s.Instance, s.Duration.Round(100*time.Millisecond))
+	}
+	fmt.Fprintf(tw, "#   total\t\t%v\n", total.Round(100*time.Millisecond))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(rpcs) == 0 {
+		return nil
+	}
+
+	// RPCs are too many to list one by one, so summarize them by method.
+	type summary struct {
+		calls, errors  int
+		sent, received int64
+		duration       time.Duration
+	}
+	var methods []string
+	byMethod := make(map[string]*summary)
+	for _, c := range rpcs {
+		s := byMethod[c.Method]
+		if s == nil {
+			s = new(summary)
+			byMethod[c.Method] = s
+			methods = append(methods, c.Method)
+		}
+		s.calls++
+		if c.Error != "" {
+			s.errors++
+		}
+		s.sent += c.BytesSent
+		s.received += c.BytesReceived
+		s.duration += c.Duration
+	}
+	fmt.Fprintln(w, "# RPCs:")
+	for _, m := range methods {
+		s := byMethod[m]
+		calls := fmt.Sprintf("%d calls", s.calls)
+		if s.calls == 1 {
+			calls = "1 call"
+		}
+		if s.errors > 0 {
+			calls += fmt.Sprintf(", %d failed", s.errors)
+		}
+		fmt.Fprintf(tw, "#   %s\t%s\tsent %d bytes\treceived %d bytes\t%v\n", m, calls, s.sent, s.received, s.duration.Round(100*time.Millisecond))
+	}
+	return tw.Flush()
+}
+
+func writeTimingsJSON(w io.Writer, cmd string, start time.Time, total time.Duration, spans []span, rpcs []rpc) error {
+	if spans == nil {
+		spans = []span{}
+	}
+	if rpcs == nil {
+		rpcs = []rpc{}
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Command  string        `json:"command"`
+		Start    time.Time     `json:"start"`
+		Duration time.Duration `json:"duration_ns"`
+		Spans    []span        `json:"spans"`
+		RPCs     []rpc         `json:"rpcs"`
+	}{cmd, start, total, spans, rpcs})
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTimingsFlag(t *testing.T) {
+	testCases := []struct {
+		args    []string
+		want    timingsFlag
+		wantErr bool
+	}{
+		{nil, "", false},
+		{[]string{"-timings"}, "text", false},
+		{[]string{"-timings=false"}, "", false},
+		{[]string{"-timings=json"}, "json", false},
+		{[]string{"-timings=yaml"}, "", true},
+	}
+	for _, tc := range testCases {
+		var f timingsFlag
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Var(&f, "timings", "")
+		err := fs.Parse(tc.args)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Parse(%q) = %v; want error %t", tc.args, err, tc.wantErr)
+		}
+		if err == nil && f != tc.want {
+			t.Errorf("Parse(%q) = %q; want %q", tc.args, f, tc.want)
+		}
+	}
+}
+
+func TestWriteTimings(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	spans := []span{
+		{Name: "queue", Instance: "user-a-linux-amd64-0", Start: start, Duration: 12 * time.Second},
+		{Name: "make.bash", Instance: "user-a-linux-amd64-0", Start: start.Add(12 * time.Second), Duration: 90*time.Second + 240*time.Millisecond},
+	}
+	var buf bytes.Buffer
+	if err := writeTimings(&buf, 102*time.Second, spans, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `# Timings:
+#   queue      user-a-linux-amd64-0  12s
+#   make.bash  user-a-linux-amd64-0  1m30.2s
+#   total                            1m42s
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeTimings() =\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := writeTimingsJSON(&buf, "create", start, 102*time.Second, spans, nil); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Command string
+		Spans   []span
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("writeTimingsJSON() wrote invalid JSON: %v", err)
+	}
+	if got.Command != "create" || len(got.Spans) != 2 || got.Spans[1] != spans[1] {
+		t.Errorf("writeTimingsJSON() = %s; want command create and the spans", buf.Bytes())
+	}
+}
+
+func TestTimerReport(t *testing.T) {
+	tm := newTimer("push")
+	tm.span("push", "inst")()
+	var buf bytes.Buffer
+	tm.report(&buf, "")
+	if buf.Len() != 0 {
+		t.Errorf("report() without -timings wrote %q for a short command; want nothing", buf.String())
+	}
+	tm.start = tm.start.Add(-2 * timingsThreshold)
+	tm.report(&buf, "")
+	if buf.Len() == 0 {
+		t.Errorf("report() without -timings wrote nothing for a long command")
+	}
+}
+
+// fakeClientStream is a server stream of replies to an ExecuteCommand RPC.
+type fakeClientStream struct {
+	grpc.ClientStream
+	replies []string
+}
+
+func (s *fakeClientStream) SendMsg(m any) error { return nil }
+func (s *fakeClientStream) CloseSend() error    { return nil }
+
+func (s *fakeClientStream) RecvMsg(m any) error {
+	if len(s.replies) == 0 {
+		return io.EOF
+	}
+	m.(*protos.ExecuteCommandResponse).Output = []byte(s.replies[0])
+	s.replies = s.replies[1:]
+	return nil
+}
+
+func TestTimerObservesRPCs(t *testing.T) {
+	defer func(list []buildlet.Observer) { rpcObservers.list = list }(rpcObservers.list)
+	rpcObservers.list = nil
+	tm := newTimer("run")
+
+	ctx := context.Background()
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Errorf(codes.NotFound, "instance not found")
+	}
+	req := &protos.InstanceAliveRequest{GomoteId: "inst"}
+	observeUnaryInterceptor(ctx, "/protos.GomoteService/InstanceAlive", req, &protos.InstanceAliveResponse{}, nil, invoker)
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &fakeClientStream{replies: []string{"hello ", "world\n"}}, nil
+	}
+	cs, err := observeStreamInterceptor(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/protos.GomoteService/ExecuteCommand", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &protos.ExecuteCommandRequest{GomoteId: "inst", Command: "go"}
+	if err := cs.SendMsg(exec); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if err := cs.RecvMsg(new(protos.ExecuteCommandResponse)); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(tm.rpcs) != 2 {
+		t.Fatalf("timer recorded %d RPCs; want 2", len(tm.rpcs))
+	}
+	alive, run := tm.rpcs[0], tm.rpcs[1]
+	if alive.Method != "InstanceAlive" || alive.Instance != "inst" || alive.Error == "" {
+		t.Errorf("InstanceAlive recorded as %+v; want method, instance and error", alive)
+	}
+	wantSent := int64(proto.Size(exec))
+	wantRecv := int64(proto.Size(&protos.ExecuteCommandResponse{Output: []byte("hello ")}) + proto.Size(&protos.ExecuteCommandResponse{Output: []byte("world\n")}))
+	if run.Method != "ExecuteCommand" || run.Instance != "inst" || run.BytesSent != wantSent || run.BytesReceived != wantRecv || run.Error != "" {
+		t.Errorf("ExecuteCommand recorded as %+v; want instance inst, %d bytes sent and %d received", run, wantSent, wantRecv)
+	}
+}
+
+func TestWriteTimingsRPCs(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	rpcs := []rpc{
+		{Method: "UploadFile", Instance: "inst", Start: start, Duration: 2 * time.Second, BytesSent: 1000},
+		{Method: "ExecuteCommand", Instance: "inst", Start: start, Duration: 3 * time.Second, BytesSent: 20, BytesReceived: 300},
+		{Method: "UploadFile", Instance: "inst", Start: start, Duration: time.Second, BytesSent: 500, Error: "rpc error"},
+	}
+	var buf bytes.Buffer
+	if err := writeTimings(&buf, 6*time.Second, nil, rpcs); err != nil {
+		t.Fatal(err)
+	}
+	want := `# Timings:
+#   total    6s
+# RPCs:
+#   UploadFile      2 calls, 1 failed  sent 1500 bytes  received 0 bytes    3s
+#   ExecuteCommand  1 call             sent 20 bytes    received 300 bytes  3s
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeTimings() =\n%s\nwant:\n%s", got, want)
+	}
+}
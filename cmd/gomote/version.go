@@ -0,0 +1,180 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/build/internal/gomote/protos"
+)
+
+// versionCompatibilityWindow is how much older than the server the client
+// may be before "gomote version" suggests updating it.
+const versionCompatibilityWindow = 90 * 24 * time.Hour
+
+func version(args []string) error {
+	var flags versionFlags
+	fs := versionFlagSet(&flags)
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+	}
+
+	v := versionJSON{Client: clientVersion()}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	server, err := fetchServerVersion(ctx)
+	if err != nil {
+		server.Error = err.Error()
+	}
+	v.Server = server
+	v.UpdateRecommended = updateRecommended(v.Client, v.Server)
+	if flags.jsonOut {
+		return writeJSON(os.Stdout, v)
+	}
+	return writeVersion(os.Stdout, v)
+}
+
+// versionFlags are the flags of the version command.
+type versionFlags struct {
+	jsonOut bool
+}
+
+func versionFlagSet(flags *versionFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "version usage: gomote version [version-opts]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Prints the version of the gomote client and, if it is reachable,")
+		fmt.Fprintln(os.Stderr, "of the gomote server. Please include it in bug reports.")
+		fs.PrintDefaults()
+		os.Exit(exitUsage)
+	}
+	fs.BoolVar(&flags.jsonOut, "json", false, "print the versions as JSON")
+	return fs
+}
+
+// versionJSON is the JSON representation of the versions printed by "gomote version -json".
+type versionJSON struct {
+	Client            buildVersion `json:"client"`
+	Server            buildVersion `json:"server"`
+	UpdateRecommended bool         `json:"update_recommended"`
+}
+
+// buildVersion describes the build of the gomote client or server.
+type buildVersion struct {
+	Address      string     `json:"address,omitempty"`      // server only
+	AddressFrom  string     `json:"address_from,omitempty"` // server only: where the address was set
+	Version      string     `json:"version,omitempty"`
+	Revision     string     `json:"revision,omitempty"`
+	RevisionTime *time.Time `json:"revision_time,omitempty"`
+	Modified     bool       `json:"modified,omitempty"` // client only
+	GoVersion    string     `json:"go_version,omitempty"`
+	// Error is set if the version could not be retrieved.
+	Error string `json:"error,omitempty"`
+}
+
+func clientVersion() buildVersion {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return buildVersion{Error: "no build information available"}
+	}
+	v := buildVersion{GoVersion: bi.GoVersion}
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		v.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v.Revision = s.Value
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, s.Value); err == nil {
+				v.RevisionTime = &t
+			}
+		case "vcs.modified":
+			v.Modified = s.Value == "true"
+		}
+	}
+	return v
+}
+
+// fetchServerVersion asks the gomote server for its version. Unlike most
+// commands, it does not exit if the server cannot be reached.
+func fetchServerVersion(ctx context.Context) (buildVersion, error) {
+	v := buildVersion{Address: *serverAddr, AddressFrom: serverSource}
+	grpcClient, err := dialServer(ctx)
+	if err != nil {
+		return v, fmt.Errorf("unable to reach the server: %w", err)
+	}
+	defer grpcClient.Close()
+	resp, err := protos.NewGomoteServiceClient(grpcClient).ServerVersion(ctx, &protos.ServerVersionRequest{})
+	if err != nil {
+		return v, fmt.Errorf("unable to retrieve the server version: %w", err)
+	}
+	v.Version = resp.GetVersion()
+	v.Revision = resp.GetRevision()
+	if resp.GetRevisionTime() != 0 {
+		t := time.Unix(resp.GetRevisionTime(), 0).UTC()
+		v.RevisionTime = &t
+	}
+	return v, nil
+}
+
+// updateRecommended reports whether the client was built from a revision
+// more than versionCompatibilityWindow older than the server's.
+func updateRecommended(client, server buildVersion) bool {
+	if client.RevisionTime == nil || server.RevisionTime == nil {
+		return false
+	}
+	return server.RevisionTime.Sub(*client.RevisionTime) > versionCompatibilityWindow
+}
+
+func writeVersion(w io.Writer, v versionJSON) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "client:\t%s\n", v.Client)
+	addr := v.Server.Address
+	if v.Server.AddressFrom != "" {
+		addr += " (from " + v.Server.AddressFrom + ")"
+	}
+	fmt.Fprintf(tw, "server:\t%s: %s\n", addr, v.Server)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if v.UpdateRecommended {
+		fmt.Fprintf(os.Stderr, "# The gomote client is more than %d days older than the server; consider updating it with:\n", versionCompatibilityWindow/(24*time.Hour))
+		fmt.Fprintf(os.Stderr, "#\tgo install golang.org/x/build/cmd/gomote@latest\n")
+	}
+	return nil
+}
+
+func (v buildVersion) String() string {
+	if v.Error != "" {
+		return v.Error
+	}
+	s := v.Version
+	if s == "" {
+		s = "unknown version"
+	}
+	if v.Revision != "" {
+		s += ", revision " + v.Revision
+		if v.Modified {
+			s += " (modified)"
+		}
+	}
+	if v.RevisionTime != nil {
+		s += " from " + v.RevisionTime.Format(time.DateOnly)
+	}
+	if v.GoVersion != "" {
+		s += ", built with " + v.GoVersion
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateRecommended(t *testing.T) {
+	at := func(s string) *time.Time {
+		tm, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tm
+	}
+	testCases := []struct {
+		desc           string
+		client, server *time.Time
+		want           bool
+	}{
+		{"same revision time", at("2024-03-01"), at("2024-03-01"), false},
+		{"client newer", at("2024-06-01"), at("2024-03-01"), false},
+		{"within window", at("2024-01-01"), at("2024-03-01"), false},
+		{"beyond window", at("2023-10-01"), at("2024-03-01"), true},
+		{"unknown client", nil, at("2024-03-01"), false},
+		{"unknown server", at("2023-01-01"), nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := updateRecommended(buildVersion{RevisionTime: tc.client}, buildVersion{RevisionTime: tc.server})
+			if got != tc.want {
+				t.Errorf("updateRecommended() = %t; want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -30,15 +30,18 @@ import (
 	"golang.org/x/build/internal/https"
 	"golang.org/x/build/internal/rendezvous"
 	"golang.org/x/build/internal/secret"
+	"golang.org/x/build/internal/wstunnel"
 	"golang.org/x/build/revdial/v2"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // For clients which compress RPCs with -compression=gzip.
 )
 
 var (
 	sshAddr      = flag.String("ssh_addr", ":2222", "Address the gomote SSH server should listen on")
 	buildEnvName = flag.String("env", "", "The build environment configuration to use. Not required if running in dev mode locally or prod mode on GCE.")
 	mode         = flag.String("mode", "", "Valid modes are 'dev', 'prod', or '' for auto-detect. dev means localhost development, not be confused with staging on go-dashboard-dev, which is still the 'prod' mode.")
+	admins       = flag.String("admins", "", "Comma-separated email addresses of the administrators, who may extend the gomote instances of other users.")
 )
 
 var Version string // set by linker -X
@@ -66,6 +69,7 @@ func main() {
 
 	var gomoteBucket string
 	var opts []grpc.ServerOption
+	var requireIAP bool
 	if *buildEnvName == "" && *mode != "dev" && metadata.OnGCE() {
 		projectID, err := metadata.ProjectID()
 		if err != nil {
@@ -80,6 +84,7 @@ func main() {
 		}
 		opts = append(opts, grpc.UnaryInterceptor(access.RequireIAPAuthUnaryInterceptor(access.IAPSkipAudienceValidation)))
 		opts = append(opts, grpc.StreamInterceptor(access.RequireIAPAuthStreamInterceptor(access.IAPSkipAudienceValidation)))
+		requireIAP = true
 	}
 	grpcServer := grpc.NewServer(opts...)
 	rdv := rendezvous.New(ctx)
@@ -87,11 +92,18 @@ func main() {
 	if err != nil {
 		log.Fatalf("unable to create gomote server: %s", err)
 	}
+	gomoteServer.SetAdmins(strings.Split(*admins, ","))
 	gomotepb.RegisterGomoteServiceServer(grpcServer, gomoteServer)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/reverse", rdv.HandleReverse)
 	mux.Handle("/revdial", revdial.ConnHandler())
+	var tunnel http.Handler = wstunnel.Handler(grpcServer, access.IAPHeaders()...)
+	if requireIAP {
+		// Authenticate tunnels once, so that requests made through them don't fail when their JWT expires.
+		tunnel = access.RequireIAPAuthTunnelHandler(tunnel, access.IAPSkipAudienceValidation)
+	}
+	mux.Handle(wstunnel.Path, tunnel) // For clients which can only use HTTPS.
 	mux.HandleFunc("/style.css", ui.Redirect(ui.HandleStyleCSS, gomoteSSHHost, gomoteHost))
 	mux.HandleFunc("/", ui.Redirect(grpcHandlerFunc(grpcServer, ui.HandleStatusFunc(sp, Version)), gomoteSSHHost, gomoteHost)) // Serve a status page.
 
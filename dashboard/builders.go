@@ -120,6 +120,16 @@ var slowBotAliases = map[string]string{
 // Initialization happens below, via calls to addBuilder.
 var Builders = map[string]*BuildConfig{}
 
+// RetiredBuilders maps the names of builders which were removed to the
+// names of the builders replacing them, or "" if there are none, so
+// that users of the old names can be told what to use instead.
+var RetiredBuilders = map[string]string{
+	"freebsd-amd64-12_2":  "freebsd-amd64-12_3",
+	"linux-386-stretch":   "linux-386-bullseye",
+	"linux-amd64-stretch": "linux-amd64-bullseye",
+	"windows-amd64-2012":  "windows-amd64-2016",
+}
+
 // GoBootstrap is the bootstrap Go version.
 //
 // For bootstrap versions prior to Go 1.21.0,
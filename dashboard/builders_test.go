@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"golang.org/x/build/internal/migration"
+	"golang.org/x/build/types"
 )
 
 func TestOSARCHAccessors(t *testing.T) {
@@ -862,6 +863,16 @@ func TestCrossCompileOnlyBuilders(t *testing.T) {
 	}
 }
 
+// TestMakeScriptMatchesTypes verifies that the build script guessed by
+// clients which can't reach the listing of builders is the one builders use.
+func TestMakeScriptMatchesTypes(t *testing.T) {
+	for _, conf := range Builders {
+		if got, want := types.BuildScript(conf.GOOS()), conf.MakeScript(); got != want {
+			t.Errorf("types.BuildScript(%q) = %q; want %q, the MakeScript of builder %q", conf.GOOS(), got, want, conf.Name)
+		}
+	}
+}
+
 // TestTryBotsCompileAllPorts verifies that each port (go tool dist list)
 // is covered by either a real TryBot or a misc-compile TryBot.
 //
@@ -1145,6 +1156,17 @@ func TestBuildersPortedToLUCI(t *testing.T) {
 	}
 }
 
+func TestRetiredBuilders(t *testing.T) {
+	for name, replacement := range RetiredBuilders {
+		if _, ok := Builders[name]; ok {
+			t.Errorf("RetiredBuilders contains %v, which is still a builder", name)
+		}
+		if _, ok := Builders[replacement]; replacement != "" && !ok {
+			t.Errorf("RetiredBuilders replaces %v with an unknown builder name %v", name, replacement)
+		}
+	}
+}
+
 func TestHostConfigCosArchitecture(t *testing.T) {
 	testCases := []struct {
 		desc       string
@@ -24,6 +24,10 @@ type contextKeyIAP string
 const (
 	// contextIAP is the key used to store IAP provided fields in the context.
 	contextIAP contextKeyIAP = contextKeyIAP("IAP-JWT")
+	// contextTunnelIAP is the key used to store the IAP provided fields of a tunnel,
+	// authenticated when the tunnel was established, in the context of the requests
+	// it carries.
+	contextTunnelIAP contextKeyIAP = contextKeyIAP("IAP-tunnel")
 
 	// IAPHeaderJWT is the header IAP stores the JWT token in.
 	iapHeaderJWT = "X-Goog-IAP-JWT-Assertion"
@@ -48,6 +52,11 @@ type IAPFields struct {
 	ID string
 }
 
+// IAPHeaders returns the names of the request headers set by IAP.
+func IAPHeaders() []string {
+	return []string{iapHeaderJWT, iapHeaderEmail, iapHeaderID}
+}
+
 // IAPFromContext retrieves the IAPFields stored in the context if it exists.
 func IAPFromContext(ctx context.Context) (*IAPFields, error) {
 	v := ctx.Value(contextIAP)
@@ -78,13 +87,49 @@ func RequireIAPAuthHandler(h http.Handler, audience string) http.Handler {
 	})
 }
 
+// RequireIAPAuthTunnelHandler is like RequireIAPAuthHandler, but for the handler of a tunnel
+// which carries further requests to a GRPC server, such as that of the wstunnel package. The
+// identity authenticated when the tunnel is established is trusted for all of the requests it
+// carries, so that requests made once the JWT of the tunnel has expired don't fail.
+func RequireIAPAuthTunnelHandler(h http.Handler, audience string) http.Handler {
+	return requireIAPAuthTunnelHandler(h, audience, idtoken.Validate)
+}
+
+func requireIAPAuthTunnelHandler(h http.Handler, audience string, validatorFn validator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwt := r.Header.Get(iapHeaderJWT)
+		if jwt == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, "must run under IAP\n")
+			return
+		}
+		if err := validateIAPJWT(r.Context(), jwt, audience, validatorFn); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			log.Printf("JWT validation error: %v", err)
+			return
+		}
+		iap := IAPFields{Email: r.Header.Get(iapHeaderEmail), ID: r.Header.Get(iapHeaderID)}
+		if iap.Email == "" || iap.ID == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			log.Printf("access: tunnel request is missing IAP fields")
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextTunnelIAP, iap)))
+	})
+}
+
 // iapAuthFunc creates an authentication function used to create a GRPC interceptor.
 // It ensures that the caller has successfully authenticated via IAP. If the caller
 // has authenticated, the headers created by IAP will be added to the request scope
-// context passed down to the server implementation.
+// context passed down to the server implementation. Requests carried by a tunnel which
+// was authenticated by RequireIAPAuthTunnelHandler are authenticated as the tunnel was.
+// Streams are only authenticated when they're established, so they may outlive their JWT.
 // https://cloud.google.com/iap/docs/signed-headers-howto
 func iapAuthFunc(audience string, validatorFn validator) grpcauth.AuthFunc {
 	return func(ctx context.Context) (context.Context, error) {
+		if iap, ok := ctx.Value(contextTunnelIAP).(IAPFields); ok {
+			return ContextWithIAP(ctx, iap), nil
+		}
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			return ctx, status.Error(codes.Internal, codes.Internal.String())
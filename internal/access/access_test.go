@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -62,6 +64,73 @@ func TestIAPAuthFunc(t *testing.T) {
 	}
 }
 
+func TestRequireIAPAuthTunnelHandler(t *testing.T) {
+	want := IAPFields{
+		Email: "charlie@brown.com",
+		ID:    "chaz.service.moo",
+	}
+	const validJWT = "valid-jwt"
+	testValidator := func(ctx context.Context, token, audience string) (*idtoken.Payload, error) {
+		if token != validJWT {
+			return nil, fmt.Errorf("token expired")
+		}
+		return &idtoken.Payload{
+			Issuer:   "https://cloud.google.com/iap",
+			Audience: audience,
+			Expires:  time.Now().Add(time.Minute).Unix(),
+			IssuedAt: time.Now().Add(-time.Minute).Unix(),
+		}, nil
+	}
+	var got *IAPFields
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The JWT forwarded to the requests in the tunnel has expired
+		// since the tunnel was established.
+		ctx := metadata.NewIncomingContext(r.Context(), metadata.New(map[string]string{
+			iapHeaderJWT:   "expired-jwt",
+			iapHeaderEmail: want.Email,
+			iapHeaderID:    want.ID,
+		}))
+		ctx, err := iapAuthFunc("foo/bar/zar", testValidator)(ctx)
+		if err != nil {
+			t.Errorf("authFunc(ctx) in tunnel = %s; want no error", err)
+			return
+		}
+		got, err = IAPFromContext(ctx)
+		if err != nil {
+			t.Errorf("IAPFromContext(ctx) = %s; want no error", err)
+		}
+	})
+	h := requireIAPAuthTunnelHandler(inner, "foo/bar/zar", testValidator)
+	for _, tc := range []struct {
+		desc       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{"missing JWT", map[string]string{iapHeaderEmail: want.Email, iapHeaderID: want.ID}, http.StatusUnauthorized},
+		{"invalid JWT", map[string]string{iapHeaderJWT: "expired-jwt", iapHeaderEmail: want.Email, iapHeaderID: want.ID}, http.StatusUnauthorized},
+		{"missing ID", map[string]string{iapHeaderJWT: validJWT, iapHeaderEmail: want.Email}, http.StatusUnauthorized},
+		{"valid JWT", map[string]string{iapHeaderJWT: validJWT, iapHeaderEmail: want.Email, iapHeaderID: want.ID}, http.StatusOK},
+	} {
+		got = nil
+		r := httptest.NewRequest("GET", "/tunnel", nil)
+		for k, v := range tc.header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tc.wantStatus {
+			t.Errorf("%s: status = %d; want %d", tc.desc, w.Code, tc.wantStatus)
+		}
+		if tc.wantStatus == http.StatusOK {
+			if got == nil || *got != want {
+				t.Errorf("%s: IAP fields in tunnel = %+v; want %+v", tc.desc, got, want)
+			}
+		} else if got != nil {
+			t.Errorf("%s: tunnel handler ran with IAP fields %+v; want it rejected", tc.desc, got)
+		}
+	}
+}
+
 func TestIAPAuthFuncError(t *testing.T) {
 	testCases := []struct {
 		desc      string
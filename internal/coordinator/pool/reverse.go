@@ -370,6 +370,20 @@ func (p *ReverseBuildletPool) HostTypeCount() map[string]int {
 	return total
 }
 
+// WaitingCount returns the number of items waiting for a buildlet, per
+// hostType. Host types without any waiting items are omitted.
+func (p *ReverseBuildletPool) WaitingCount() map[string]int {
+	waiting := map[string]int{}
+	p.mu.Lock()
+	for typ, queue := range p.hostQueue {
+		if n := queue.Len(); n > 0 {
+			waiting[typ] = n
+		}
+	}
+	p.mu.Unlock()
+	return waiting
+}
+
 // SingleHostTypeCount iterates through the running reverse buildlets, and
 // constructs a count of the running buildlet hostType requested.
 func (p *ReverseBuildletPool) SingleHostTypeCount(hostType string) int {
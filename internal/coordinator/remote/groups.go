@@ -0,0 +1,163 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Errors returned by the group methods of SessionPool.
+var (
+	ErrGroupExists     = errors.New("group already exists")
+	ErrGroupNotFound   = errors.New("group does not exist")
+	ErrSessionNotFound = errors.New("remote buildlet does not exist")
+	ErrNotOwner        = errors.New("remote buildlet is owned by another user")
+)
+
+// Group is a named set of remote buildlet sessions. Groups are keyed by
+// their owner and name, and only contain sessions of their owner.
+// Sessions are removed from groups when they are destroyed.
+type Group struct {
+	Name    string
+	OwnerID string // identity aware proxy user id: "accounts.google.com:userIDvalue"
+	Owner   string // user name of the owner: "bradfitz"
+	// Shared is true if users other than the owner may read the group.
+	Shared bool
+	// Members are the IDs of the sessions in the group, sorted.
+	Members []string
+}
+
+func (g *Group) clone() *Group {
+	c := *g
+	c.Members = slices.Clone(g.Members)
+	return &c
+}
+
+type groupKey struct {
+	ownerID, name string
+}
+
+// CreateGroup creates the named group of the owner, with the given
+// members. All of the members must be sessions of the owner.
+func (sp *SessionPool) CreateGroup(ownerID, owner, name string, shared bool, members []string) (*Group, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	key := groupKey{ownerID, name}
+	if _, ok := sp.groups[key]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrGroupExists, name)
+	}
+	if err := sp.checkMembers(ownerID, members); err != nil {
+		return nil, err
+	}
+	g := &Group{
+		Name:    name,
+		OwnerID: ownerID,
+		Owner:   owner,
+		Shared:  shared,
+	}
+	g.add(members)
+	sp.groups[key] = g
+	return g.clone(), nil
+}
+
+// ModifyGroup adds and removes members of the named group of the owner,
+// and changes whether it is shared if shared is not nil. All of the added
+// members must be sessions of the owner. Either all of the changes are
+// made, or none of them.
+func (sp *SessionPool) ModifyGroup(ownerID, name string, add, remove []string, shared *bool) (*Group, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	g, ok := sp.groups[groupKey{ownerID, name}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+	if err := sp.checkMembers(ownerID, add); err != nil {
+		return nil, err
+	}
+	g.add(add)
+	g.Members = slices.DeleteFunc(g.Members, func(id string) bool {
+		return slices.Contains(remove, id)
+	})
+	if shared != nil {
+		g.Shared = *shared
+	}
+	return g.clone(), nil
+}
+
+// DeleteGroup deletes the named group of the owner. Its members are not
+// destroyed.
+func (sp *SessionPool) DeleteGroup(ownerID, name string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	key := groupKey{ownerID, name}
+	if _, ok := sp.groups[key]; !ok {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+	delete(sp.groups, key)
+	return nil
+}
+
+// Groups returns the groups of the owner, and if includeShared is set,
+// the groups shared by other users. They are sorted by owner and name.
+func (sp *SessionPool) Groups(ownerID string, includeShared bool) []*Group {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	var gs []*Group
+	for _, g := range sp.groups {
+		if g.OwnerID == ownerID || (includeShared && g.Shared) {
+			gs = append(gs, g.clone())
+		}
+	}
+	sort.Slice(gs, func(i, j int) bool {
+		if gs[i].Owner != gs[j].Owner {
+			return gs[i].Owner < gs[j].Owner
+		}
+		return gs[i].Name < gs[j].Name
+	})
+	return gs
+}
+
+// checkMembers returns an error if any of the session IDs is not a
+// session of the owner.
+// The SessionPool lock should be held before calling.
+func (sp *SessionPool) checkMembers(ownerID string, ids []string) error {
+	for _, id := range ids {
+		s, ok := sp.m[id]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+		}
+		if s.OwnerID != ownerID {
+			return fmt.Errorf("%w: %s", ErrNotOwner, id)
+		}
+	}
+	return nil
+}
+
+// add adds the session IDs which are not already members to the group.
+func (g *Group) add(ids []string) {
+	for _, id := range ids {
+		if !slices.Contains(g.Members, id) {
+			g.Members = append(g.Members, id)
+		}
+	}
+	sort.Strings(g.Members)
+}
+
+// removeFromGroups removes the destroyed session from all groups.
+// The SessionPool lock should be held before calling.
+func (sp *SessionPool) removeFromGroups(id string) {
+	for _, g := range sp.groups {
+		g.Members = slices.DeleteFunc(g.Members, func(m string) bool { return m == id })
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/build/buildlet"
+)
+
+func TestGroups(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	const owner, other = "accounts.google.com:user-x", "accounts.google.com:user-y"
+	a := sp.AddSession(owner, "user-x", "builder", "host", &buildlet.FakeClient{})
+	b := sp.AddSession(owner, "user-x", "builder", "host", &buildlet.FakeClient{})
+	c := sp.AddSession(other, "user-y", "builder", "host", &buildlet.FakeClient{})
+
+	g, err := sp.CreateGroup(owner, "user-x", "tests", false, []string{b, a, a})
+	if err != nil {
+		t.Fatalf("CreateGroup = %v; want no error", err)
+	}
+	if want := []string{a, b}; !slices.Equal(g.Members, want) {
+		t.Errorf("group members = %q; want %q", g.Members, want)
+	}
+	if _, err := sp.CreateGroup(owner, "user-x", "tests", false, nil); !errors.Is(err, ErrGroupExists) {
+		t.Errorf("CreateGroup of an existing group = %v; want %v", err, ErrGroupExists)
+	}
+	// The same name can be used by another owner.
+	if _, err := sp.CreateGroup(other, "user-y", "tests", false, []string{c}); err != nil {
+		t.Errorf("CreateGroup by another owner = %v; want no error", err)
+	}
+
+	// Adding another user's instance fails, and makes no changes.
+	if _, err := sp.ModifyGroup(owner, "tests", nil, []string{a}, nil); err != nil {
+		t.Fatalf("ModifyGroup = %v; want no error", err)
+	}
+	if _, err := sp.ModifyGroup(owner, "tests", []string{a, c}, nil, nil); !errors.Is(err, ErrNotOwner) {
+		t.Errorf("ModifyGroup adding another user's instance = %v; want %v", err, ErrNotOwner)
+	}
+	if gs := sp.Groups(owner, false); len(gs) != 1 || !slices.Equal(gs[0].Members, []string{b}) {
+		t.Errorf("Groups(owner, false) after a failed modification = %v; want one group with member %s", gs, b)
+	}
+	if _, err := sp.ModifyGroup(owner, "tests", []string{"missing"}, nil, nil); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ModifyGroup adding a missing instance = %v; want %v", err, ErrSessionNotFound)
+	}
+	if _, err := sp.ModifyGroup(owner, "missing", nil, nil, nil); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("ModifyGroup of a missing group = %v; want %v", err, ErrGroupNotFound)
+	}
+	shared := true
+	g, err = sp.ModifyGroup(owner, "tests", []string{a}, nil, &shared)
+	if err != nil {
+		t.Fatalf("ModifyGroup = %v; want no error", err)
+	}
+	if want := []string{a, b}; !slices.Equal(g.Members, want) || !g.Shared {
+		t.Errorf("group members, shared = %q, %t; want %q, true", g.Members, g.Shared, want)
+	}
+
+	if gs := sp.Groups(other, false); len(gs) != 1 || gs[0].OwnerID != other {
+		t.Errorf("Groups(other, false) = %v; want only the other user's group", gs)
+	}
+	if gs := sp.Groups(other, true); len(gs) != 2 || gs[0].Owner != "user-x" || gs[1].Owner != "user-y" {
+		t.Errorf("Groups(other, true) = %v; want the shared group and the other user's group", gs)
+	}
+
+	// Destroyed sessions are removed from groups, whether they are
+	// destroyed explicitly or expire.
+	if err := sp.DestroySession(a); err != nil {
+		t.Fatalf("DestroySession = %v; want no error", err)
+	}
+	if err := sp.SetDeadline(b, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("SetDeadline = %v; want no error", err)
+	}
+	sp.destroyExpiredSessions(context.Background())
+	if gs := sp.Groups(owner, false); len(gs) != 1 || len(gs[0].Members) != 0 {
+		t.Errorf("Groups(owner, false) = %v; want one group without members", gs)
+	}
+
+	if err := sp.DeleteGroup(owner, "tests"); err != nil {
+		t.Errorf("DeleteGroup = %v; want no error", err)
+	}
+	if err := sp.DeleteGroup(owner, "tests"); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("DeleteGroup of a deleted group = %v; want %v", err, ErrGroupNotFound)
+	}
+}
@@ -8,9 +8,11 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,8 +23,18 @@ import (
 const (
 	remoteBuildletIdleTimeout   = 30 * time.Minute
 	remoteBuildletCleanInterval = time.Minute
+	// remoteBuildletMaxLifetime is the maximum amount of time a session can
+	// be extended to, measured from when it was created.
+	remoteBuildletMaxLifetime = 24 * time.Hour
+	// remoteBuildletExtendRetryWindow is how long a repeated extension by
+	// the same duration is assumed to be a retry of the previous one.
+	remoteBuildletExtendRetryWindow = 10 * time.Second
 )
 
+// MaxLifetime is the longest a session can last, measured from when it
+// was created.
+const MaxLifetime = remoteBuildletMaxLifetime
+
 // Session stores the metadata for a remote buildlet Session.
 type Session struct {
 	BuilderType string // default builder config to use if not overwritten
@@ -31,13 +43,63 @@ type Session struct {
 	HostType    string
 	ID          string // unique identifier for instance "user-bradfitz-linux-amd64-0"
 	OwnerID     string // identity aware proxy user id: "accounts.google.com:userIDvalue"
+	// Deadline, if non-zero, is the lifetime requested by the owner. The
+	// session expires then even if it's in use, unless it's extended.
+	Deadline time.Time
+	// Labels are free-form key/value metadata set by the owner.
+	// They must not be modified.
+	Labels map[string]string
+	// ActiveCommands is the number of commands currently executing on the instance.
+	ActiveCommands int
+	// ActiveSSHSessions is the number of SSH sessions currently connected to the instance.
+	ActiveSSHSessions int
+	// LastActivity is the last time the instance was used by running a command, writing or
+	// removing files, or an SSH session. It is the creation time if the instance is unused.
+	LastActivity time.Time
+	// MaxLifetime, if non-zero, is the longest the session may be extended
+	// to last, measured from when it was created. It is at most MaxLifetime.
+	MaxLifetime time.Duration
 	buildlet    buildlet.Client
+
+	// lastExtension is the duration of the last extension of the session,
+	// at lastExtended, which returned lastExtendClamped.
+	lastExtension     time.Duration
+	lastExtended      time.Time
+	lastExtendClamped bool
+
+	// commands are the commands executing on the session, keyed by ID.
+	commands      map[string]*Command
+	lastCommandID int
+}
+
+// Command is a command executing on a remote buildlet session.
+type Command struct {
+	ID      string // unique within the session: "1"
+	Command string
+	Args    []string
+	Started time.Time
+	cancel  context.CancelFunc
 }
 
-// renew extends the expiration timestamp for a session.
+// renew extends the expiration timestamp for a session. An expiration
+// which is already further in the future is left unchanged.
 // The SessionPool lock should be held before calling.
 func (s *Session) renew() {
-	s.Expires = time.Now().Add(remoteBuildletIdleTimeout)
+	exp := time.Now().Add(remoteBuildletIdleTimeout)
+	if !s.Deadline.IsZero() && exp.After(s.Deadline) {
+		exp = s.Deadline
+	}
+	if exp.After(s.Expires) {
+		s.Expires = exp
+	}
+}
+
+// maxExpires returns the latest time the session can be extended to.
+func (s *Session) maxExpires() time.Time {
+	if s.MaxLifetime > 0 {
+		return s.Created.Add(s.MaxLifetime)
+	}
+	return s.Created.Add(remoteBuildletMaxLifetime)
 }
 
 // isExpired determines if the remote buildlet session has expired.
@@ -54,6 +116,7 @@ type SessionPool struct {
 	pollWait   sync.WaitGroup
 	cancelPoll context.CancelFunc
 	m          map[string]*Session // keyed by buildletName
+	groups     map[groupKey]*Group
 }
 
 // NewSessionPool creates a session pool which stores and provides access to active remote buildlet sessions.
@@ -63,6 +126,7 @@ func NewSessionPool(ctx context.Context) *SessionPool {
 	sp := &SessionPool{
 		cancelPoll: cancel,
 		m:          map[string]*Session{},
+		groups:     map[groupKey]*Group{},
 	}
 	sp.pollWait.Add(1)
 	go func() {
@@ -84,13 +148,14 @@ func (sp *SessionPool) AddSession(ownerID, username, builderType, hostType strin
 		if _, ok := sp.m[name]; !ok {
 			now := time.Now()
 			sp.m[name] = &Session{
-				BuilderType: builderType,
-				buildlet:    bc,
-				Created:     now,
-				Expires:     now.Add(remoteBuildletIdleTimeout),
-				HostType:    hostType,
-				ID:          name,
-				OwnerID:     ownerID,
+				BuilderType:  builderType,
+				buildlet:     bc,
+				Created:      now,
+				Expires:      now.Add(remoteBuildletIdleTimeout),
+				HostType:     hostType,
+				ID:           name,
+				OwnerID:      ownerID,
+				LastActivity: now,
 			}
 			return name
 		}
@@ -119,6 +184,7 @@ func (sp *SessionPool) destroyExpiredSessions(ctx context.Context) {
 		if s.isExpired() {
 			ss = append(ss, s)
 			delete(sp.m, name)
+			sp.removeFromGroups(name)
 		}
 	}
 	sp.mu.Unlock()
@@ -137,6 +203,7 @@ func (sp *SessionPool) DestroySession(buildletName string) error {
 	s, ok := sp.m[buildletName]
 	if ok {
 		delete(sp.m, buildletName)
+		sp.removeFromGroups(buildletName)
 	}
 	sp.mu.Unlock()
 	if !ok {
@@ -165,12 +232,18 @@ func (sp *SessionPool) List() []*Session {
 	var ss []*Session
 	for _, s := range sp.m {
 		ss = append(ss, &Session{
-			BuilderType: s.BuilderType,
-			Expires:     s.Expires,
-			HostType:    s.HostType,
-			ID:          s.ID,
-			OwnerID:     s.OwnerID,
-			Created:     s.Created,
+			BuilderType:       s.BuilderType,
+			Expires:           s.Expires,
+			Deadline:          s.Deadline,
+			Labels:            s.Labels,
+			HostType:          s.HostType,
+			ID:                s.ID,
+			OwnerID:           s.OwnerID,
+			Created:           s.Created,
+			ActiveCommands:    s.ActiveCommands,
+			ActiveSSHSessions: s.ActiveSSHSessions,
+			LastActivity:      s.LastActivity,
+			MaxLifetime:       s.MaxLifetime,
 		})
 	}
 	sort.Slice(ss, func(i, j int) bool { return ss[i].ID < ss[j].ID })
@@ -186,23 +259,48 @@ func (sp *SessionPool) Len() int {
 }
 
 // Session retrieves information about the instance associated with a session from the pool.
+// The session is renewed, as the instance is being used.
 func (sp *SessionPool) Session(buildletName string) (*Session, error) {
 	sp.mu.Lock()
 	defer sp.mu.Unlock()
 
 	if s, ok := sp.m[buildletName]; ok {
 		s.renew()
-		return &Session{
-			BuilderType: s.BuilderType,
-			Expires:     s.Expires,
-			HostType:    s.HostType,
-			ID:          s.ID,
-			OwnerID:     s.OwnerID,
-		}, nil
+		return s.info(), nil
 	}
 	return nil, fmt.Errorf("remote buildlet does not exist=%s", buildletName)
 }
 
+// Peek is like Session, but doesn't renew the session, so that looking at an
+// instance doesn't keep it alive.
+func (sp *SessionPool) Peek(buildletName string) (*Session, error) {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	if s, ok := sp.m[buildletName]; ok {
+		return s.info(), nil
+	}
+	return nil, fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+}
+
+// info returns a copy of the exported fields of s. sp.mu must be held.
+func (s *Session) info() *Session {
+	return &Session{
+		BuilderType:       s.BuilderType,
+		Created:           s.Created,
+		Expires:           s.Expires,
+		Deadline:          s.Deadline,
+		Labels:            s.Labels,
+		HostType:          s.HostType,
+		ID:                s.ID,
+		OwnerID:           s.OwnerID,
+		ActiveCommands:    s.ActiveCommands,
+		ActiveSSHSessions: s.ActiveSSHSessions,
+		LastActivity:      s.LastActivity,
+		MaxLifetime:       s.MaxLifetime,
+	}
+}
+
 // BuildletClient returns the buildlet client associated with the Session.
 func (sp *SessionPool) BuildletClient(buildletName string) (buildlet.Client, error) {
 	sp.mu.RLock()
@@ -251,3 +349,231 @@ func (sp *SessionPool) RenewTimeout(buildletName string) error {
 	s.renew()
 	return nil
 }
+
+// ExtendSession extends the expiration of the remote buildlet session by d. If d is zero,
+// the default idle timeout is used. The expiration is never extended past the maximum
+// lifetime of the session; clamped reports whether the extension was reduced to honor it.
+// An extension by the same duration as the previous one, shortly after it, is treated as
+// a retry of it and leaves the expiration unchanged.
+func (sp *SessionPool) ExtendSession(buildletName string, d time.Duration) (expires, maxExpires time.Time, clamped bool, err error) {
+	if d < 0 {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("invalid extension duration %s", d)
+	}
+	if d == 0 {
+		d = remoteBuildletIdleTimeout
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	now := time.Now()
+	maxExp := s.maxExpires()
+	if d == s.lastExtension && now.Sub(s.lastExtended) < remoteBuildletExtendRetryWindow {
+		return s.Expires, maxExp, s.lastExtendClamped, nil
+	}
+	base := s.Expires
+	if now.After(base) {
+		base = now
+	}
+	exp := base.Add(d)
+	if exp.After(maxExp) {
+		exp, clamped = maxExp, true
+	}
+	if exp.After(s.Expires) {
+		s.Expires = exp
+	}
+	if !s.Deadline.IsZero() {
+		s.Deadline = s.Expires
+	}
+	s.lastExtension, s.lastExtended, s.lastExtendClamped = d, now, clamped
+	return s.Expires, maxExp, clamped, nil
+}
+
+// SetMaxLifetime limits how long the remote buildlet session may be extended to last,
+// measured from when it was created, to d. It can't exceed MaxLifetime. An expiration
+// or deadline past the new limit is moved back to it.
+func (sp *SessionPool) SetMaxLifetime(buildletName string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("invalid maximum lifetime %s", d)
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	s.MaxLifetime = min(d, remoteBuildletMaxLifetime)
+	maxExp := s.maxExpires()
+	if s.Expires.After(maxExp) {
+		s.Expires = maxExp
+	}
+	if s.Deadline.After(maxExp) {
+		s.Deadline = maxExp
+	}
+	return nil
+}
+
+// SetDeadline makes the remote buildlet session expire at deadline, even if
+// it's in use, unless it's extended. The deadline is limited to the maximum
+// lifetime of the session.
+func (sp *SessionPool) SetDeadline(buildletName string, deadline time.Time) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	if maxExp := s.maxExpires(); deadline.After(maxExp) {
+		deadline = maxExp
+	}
+	s.Deadline = deadline
+	s.Expires = deadline
+	return nil
+}
+
+// SetLabels sets the labels of the remote buildlet session. The map must
+// not be modified afterwards.
+func (sp *SessionPool) SetLabels(buildletName string, labels map[string]string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	s.Labels = labels
+	return nil
+}
+
+// CommandStarted records that a command has started executing on the remote buildlet session.
+// The returned function must be called once the command has finished executing.
+func (sp *SessionPool) CommandStarted(buildletName string) (finished func()) {
+	return sp.trackActivity(buildletName, func(s *Session) *int { return &s.ActiveCommands })
+}
+
+// StartCommand is like CommandStarted, but also records the command, so that it's
+// listed by Commands, and returns its ID, which is unique within the session. Calling
+// CancelCommand with the ID calls cancel, which should stop the command.
+func (sp *SessionPool) StartCommand(buildletName, command string, args []string, cancel context.CancelFunc) (id string, finished func()) {
+	done := sp.CommandStarted(buildletName)
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return "", done
+	}
+	s.lastCommandID++
+	id = strconv.Itoa(s.lastCommandID)
+	if s.commands == nil {
+		s.commands = make(map[string]*Command)
+	}
+	s.commands[id] = &Command{
+		ID:      id,
+		Command: command,
+		Args:    args,
+		Started: time.Now(),
+		cancel:  cancel,
+	}
+	return id, func() {
+		done()
+		sp.mu.Lock()
+		defer sp.mu.Unlock()
+		delete(s.commands, id)
+	}
+}
+
+// Commands returns the commands executing on the remote buildlet session, in the
+// order they were started.
+func (sp *SessionPool) Commands(buildletName string) ([]Command, error) {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return nil, fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	cmds := make([]Command, 0, len(s.commands))
+	for _, c := range s.commands {
+		cmds = append(cmds, *c)
+	}
+	// IDs are assigned in increasing order.
+	sort.Slice(cmds, func(i, j int) bool {
+		a, _ := strconv.Atoi(cmds[i].ID)
+		b, _ := strconv.Atoi(cmds[j].ID)
+		return a < b
+	})
+	return cmds, nil
+}
+
+// ErrNoCommand is returned by CancelCommand when no command with the ID is executing
+// on the session.
+var ErrNoCommand = errors.New("no such command")
+
+// CancelCommand cancels the command with the given ID executing on the remote buildlet
+// session. It returns ErrNoCommand if the command isn't executing, such as when it has
+// already finished.
+func (sp *SessionPool) CancelCommand(buildletName, id string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	c, ok := s.commands[id]
+	if !ok {
+		return ErrNoCommand
+	}
+	c.cancel()
+	return nil
+}
+
+// SSHSessionStarted records that an SSH session has connected to the remote buildlet session.
+// The returned function must be called once the SSH session has ended.
+func (sp *SessionPool) SSHSessionStarted(buildletName string) (finished func()) {
+	return sp.trackActivity(buildletName, func(s *Session) *int { return &s.ActiveSSHSessions })
+}
+
+// RecordActivity records that the remote buildlet session was just used by an operation
+// other than a command or SSH session, such as writing files.
+func (sp *SessionPool) RecordActivity(buildletName string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return fmt.Errorf("remote buildlet does not exist=%s", buildletName)
+	}
+	s.LastActivity = time.Now()
+	return nil
+}
+
+// trackActivity increments the session counter returned by counter. The returned function
+// decrements it again; calling it more than once has no further effect. The session is
+// active both when the activity starts and when it finishes.
+func (sp *SessionPool) trackActivity(buildletName string, counter func(*Session) *int) (finished func()) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	s, ok := sp.m[buildletName]
+	if !ok {
+		return func() {}
+	}
+	*counter(s)++
+	s.LastActivity = time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sp.mu.Lock()
+			defer sp.mu.Unlock()
+			*counter(s)--
+			s.LastActivity = time.Now()
+		})
+	}
+}
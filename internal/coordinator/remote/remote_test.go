@@ -82,6 +82,30 @@ func TestSessionPoolList(t *testing.T) {
 	}
 }
 
+func TestSessionPoolPeek(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	expires := time.Now().Add(time.Minute)
+	sp.mu.Lock()
+	sp.m[name].Expires = expires
+	sp.mu.Unlock()
+	s, err := sp.Peek(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Peek(%q) = nil, %s; want no error", name, err)
+	}
+	if s.ID != name || !s.Expires.Equal(expires) {
+		t.Errorf("SessionPool.Peek(%q) = session %q expiring at %s; want %q expiring at %s", name, s.ID, s.Expires, name, expires)
+	}
+	if s, err := sp.Session(name); err != nil || !s.Expires.After(expires) {
+		t.Errorf("SessionPool.Session(%q) = %+v, %v; want it renewed past %s", name, s, err, expires)
+	}
+	if _, err := sp.Peek("user-x-builder-99"); err == nil {
+		t.Errorf("SessionPool.Peek of a missing session = nil error; want an error")
+	}
+}
+
 func TestSessionPoolDestroySession(t *testing.T) {
 	sp := NewSessionPool(context.Background())
 	defer sp.Close()
@@ -117,3 +141,366 @@ func TestRenewTimeoutError(t *testing.T) {
 		t.Errorf("SessionPool.RenewTimeout(%q) = %s; want error", name, err)
 	}
 }
+
+func TestExtendSession(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	before, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	exp, maxExp, clamped, err := sp.ExtendSession(name, time.Hour)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q, 1h) = %s; want no error", name, err)
+	}
+	if clamped {
+		t.Errorf("SessionPool.ExtendSession(%q, 1h) clamped = true; want false", name)
+	}
+	if want := before.Expires.Add(time.Hour); exp.Before(want) {
+		t.Errorf("SessionPool.ExtendSession(%q, 1h) expires = %s; want >= %s", name, exp, want)
+	}
+	if want := before.Created.Add(remoteBuildletMaxLifetime); !maxExp.Equal(want) {
+		t.Errorf("SessionPool.ExtendSession(%q, 1h) max expires = %s; want %s", name, maxExp, want)
+	}
+	// A renewal must not shorten the extended expiration.
+	if err := sp.RenewTimeout(name); err != nil {
+		t.Fatalf("SessionPool.RenewTimeout(%q) = %s; want no error", name, err)
+	}
+	after, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	if !after.Expires.Equal(exp) {
+		t.Errorf("Session.Expires after renewal = %s; want %s", after.Expires, exp)
+	}
+}
+
+func TestExtendSessionClamped(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	exp, maxExp, clamped, err := sp.ExtendSession(name, 2*remoteBuildletMaxLifetime)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q) = %s; want no error", name, err)
+	}
+	if !clamped {
+		t.Errorf("SessionPool.ExtendSession(%q) clamped = false; want true", name)
+	}
+	if !exp.Equal(maxExp) {
+		t.Errorf("SessionPool.ExtendSession(%q) expires = %s; want %s", name, exp, maxExp)
+	}
+}
+
+func TestExtendSessionRetry(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	exp, _, _, err := sp.ExtendSession(name, time.Hour)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q, 1h) = %s; want no error", name, err)
+	}
+	// A quick repeat is a retry, which doesn't extend the session again.
+	retryExp, _, _, err := sp.ExtendSession(name, time.Hour)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q, 1h) = %s; want no error", name, err)
+	}
+	if !retryExp.Equal(exp) {
+		t.Errorf("SessionPool.ExtendSession(%q, 1h) retry expires = %s; want %s", name, retryExp, exp)
+	}
+	// A different duration isn't.
+	twiceExp, _, _, err := sp.ExtendSession(name, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q, 2h) = %s; want no error", name, err)
+	}
+	if want := exp.Add(2 * time.Hour); !twiceExp.Equal(want) {
+		t.Errorf("SessionPool.ExtendSession(%q, 2h) expires = %s; want %s", name, twiceExp, want)
+	}
+}
+
+func TestSetMaxLifetime(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	if err := sp.SetMaxLifetime(name, 4*time.Hour); err != nil {
+		t.Fatalf("SessionPool.SetMaxLifetime(%q, 4h) = %s; want no error", name, err)
+	}
+	s, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	if s.MaxLifetime != 4*time.Hour {
+		t.Errorf("Session.MaxLifetime = %s; want 4h", s.MaxLifetime)
+	}
+	exp, maxExp, clamped, err := sp.ExtendSession(name, 8*time.Hour)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q, 8h) = %s; want no error", name, err)
+	}
+	if want := s.Created.Add(4 * time.Hour); !clamped || !exp.Equal(want) || !maxExp.Equal(want) {
+		t.Errorf("SessionPool.ExtendSession(%q, 8h) = %s, %s, %t; want %s, %s, true", name, exp, maxExp, clamped, want, want)
+	}
+	// Shortening the limit moves the expiration back.
+	if err := sp.SetMaxLifetime(name, 2*time.Hour); err != nil {
+		t.Fatalf("SessionPool.SetMaxLifetime(%q, 2h) = %s; want no error", name, err)
+	}
+	if s, _ = sp.Session(name); !s.Expires.Equal(s.Created.Add(2 * time.Hour)) {
+		t.Errorf("Session.Expires = %s; want %s", s.Expires, s.Created.Add(2*time.Hour))
+	}
+	// The limit can't exceed MaxLifetime.
+	if err := sp.SetMaxLifetime(name, 2*MaxLifetime); err != nil {
+		t.Fatalf("SessionPool.SetMaxLifetime(%q, 2*MaxLifetime) = %s; want no error", name, err)
+	}
+	if s, _ = sp.Session(name); s.MaxLifetime != MaxLifetime {
+		t.Errorf("Session.MaxLifetime = %s; want %s", s.MaxLifetime, MaxLifetime)
+	}
+	for _, d := range []time.Duration{0, -time.Hour} {
+		if err := sp.SetMaxLifetime(name, d); err == nil {
+			t.Errorf("SessionPool.SetMaxLifetime(%q, %s) = nil; want error", name, d)
+		}
+	}
+	if err := sp.SetMaxLifetime(name+"-wrong", time.Hour); err == nil {
+		t.Errorf("SessionPool.SetMaxLifetime(%q, 1h) = nil; want error", name+"-wrong")
+	}
+}
+
+func TestExtendSessionError(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	if _, _, _, err := sp.ExtendSession(name+"-wrong", time.Hour); err == nil {
+		t.Errorf("SessionPool.ExtendSession(%q) = nil; want error", name+"-wrong")
+	}
+	if _, _, _, err := sp.ExtendSession(name, -time.Hour); err == nil {
+		t.Errorf("SessionPool.ExtendSession(%q, -1h) = nil; want error", name)
+	}
+}
+
+func TestSetDeadline(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	deadline := time.Now().Add(5 * time.Minute)
+	if err := sp.SetDeadline(name, deadline); err != nil {
+		t.Fatalf("SessionPool.SetDeadline(%q) = %s; want no error", name, err)
+	}
+	// Activity must not renew the session past its deadline.
+	if err := sp.RenewTimeout(name); err != nil {
+		t.Fatalf("SessionPool.RenewTimeout(%q) = %s; want no error", name, err)
+	}
+	s, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	if !s.Expires.Equal(deadline) || !s.Deadline.Equal(deadline) {
+		t.Errorf("Session expires, deadline = %s, %s; want %s", s.Expires, s.Deadline, deadline)
+	}
+
+	// Extending the session moves its deadline.
+	exp, _, _, err := sp.ExtendSession(name, time.Hour)
+	if err != nil {
+		t.Fatalf("SessionPool.ExtendSession(%q, 1h) = %s; want no error", name, err)
+	}
+	if want := deadline.Add(time.Hour); !exp.Equal(want) {
+		t.Errorf("SessionPool.ExtendSession(%q, 1h) expires = %s; want %s", name, exp, want)
+	}
+	if s := sp.List()[0]; !s.Deadline.Equal(exp) {
+		t.Errorf("Session.Deadline after extension = %s; want %s", s.Deadline, exp)
+	}
+
+	// The session is destroyed once its deadline passes.
+	if err := sp.SetDeadline(name, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("SessionPool.SetDeadline(%q) = %s; want no error", name, err)
+	}
+	sp.destroyExpiredSessions(context.Background())
+	if sp.Len() != 0 {
+		t.Errorf("session with a past deadline was not destroyed")
+	}
+
+	if err := sp.SetDeadline(name+"-wrong", deadline); err == nil {
+		t.Errorf("SessionPool.SetDeadline(%q) = nil; want error", name+"-wrong")
+	}
+}
+
+func TestSetLabels(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	if err := sp.SetLabels(name, map[string]string{"cl": "12345"}); err != nil {
+		t.Fatalf("SessionPool.SetLabels(%q) = %s; want no error", name, err)
+	}
+	s, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	if got := s.Labels["cl"]; got != "12345" {
+		t.Errorf("Session.Labels[cl] = %q; want %q", got, "12345")
+	}
+	if got := sp.List()[0].Labels["cl"]; got != "12345" {
+		t.Errorf("List()[0].Labels[cl] = %q; want %q", got, "12345")
+	}
+	if err := sp.SetLabels("missing", nil); err == nil {
+		t.Errorf("SessionPool.SetLabels(missing) = nil; want error")
+	}
+}
+
+func TestCommandStarted(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	activeCommands := func() int {
+		t.Helper()
+		s, err := sp.Session(name)
+		if err != nil {
+			t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+		}
+		return s.ActiveCommands
+	}
+	finished1 := sp.CommandStarted(name)
+	finished2 := sp.CommandStarted(name)
+	if got := activeCommands(); got != 2 {
+		t.Errorf("Session.ActiveCommands = %d; want 2", got)
+	}
+	finished1()
+	finished1() // calling it more than once has no further effect
+	if got := activeCommands(); got != 1 {
+		t.Errorf("Session.ActiveCommands = %d; want 1", got)
+	}
+	finished2()
+	if got := activeCommands(); got != 0 {
+		t.Errorf("Session.ActiveCommands = %d; want 0", got)
+	}
+	// Unknown sessions are ignored.
+	sp.CommandStarted(name + "-wrong")()
+}
+
+func TestStartCommand(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	canceled := map[string]bool{}
+	id1, finished1 := sp.StartCommand(name, "go", []string{"test"}, func() { canceled["go"] = true })
+	id2, finished2 := sp.StartCommand(name, "bash", nil, func() { canceled["bash"] = true })
+	if id1 == "" || id1 == id2 {
+		t.Fatalf("SessionPool.StartCommand IDs = %q, %q; want distinct IDs", id1, id2)
+	}
+	cmds, err := sp.Commands(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Commands(%q) = nil, %s; want no error", name, err)
+	}
+	if len(cmds) != 2 || cmds[0].ID != id1 || cmds[0].Command != "go" || cmds[1].ID != id2 {
+		t.Errorf("SessionPool.Commands(%q) = %+v; want commands %s and %s", name, cmds, id1, id2)
+	}
+	if s, _ := sp.Session(name); s.ActiveCommands != 2 {
+		t.Errorf("Session.ActiveCommands = %d; want 2", s.ActiveCommands)
+	}
+
+	if err := sp.CancelCommand(name, id2); err != nil {
+		t.Errorf("SessionPool.CancelCommand(%q, %q) = %s; want no error", name, id2, err)
+	}
+	if !canceled["bash"] || canceled["go"] {
+		t.Errorf("canceled commands = %v; want only bash", canceled)
+	}
+	finished2()
+	if err := sp.CancelCommand(name, id2); err != ErrNoCommand {
+		t.Errorf("SessionPool.CancelCommand(%q, %q) of a finished command = %v; want %v", name, id2, err, ErrNoCommand)
+	}
+	if err := sp.CancelCommand(name+"-wrong", id1); err == nil {
+		t.Errorf("SessionPool.CancelCommand of an unknown session = nil; want error")
+	}
+	finished1()
+	if cmds, _ := sp.Commands(name); len(cmds) != 0 {
+		t.Errorf("SessionPool.Commands(%q) = %+v; want none", name, cmds)
+	}
+	if _, err := sp.Commands(name + "-wrong"); err == nil {
+		t.Errorf("SessionPool.Commands of an unknown session = nil; want error")
+	}
+}
+
+func TestSSHSessionStarted(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	finished := sp.SSHSessionStarted(name)
+	s, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	if s.ActiveSSHSessions != 1 || s.ActiveCommands != 0 {
+		t.Errorf("Session{ActiveSSHSessions: %d, ActiveCommands: %d}; want {1, 0}", s.ActiveSSHSessions, s.ActiveCommands)
+	}
+	finished()
+	if s, _ = sp.Session(name); s.ActiveSSHSessions != 0 {
+		t.Errorf("Session.ActiveSSHSessions = %d; want 0", s.ActiveSSHSessions)
+	}
+}
+
+func TestLastActivity(t *testing.T) {
+	sp := NewSessionPool(context.Background())
+	defer sp.Close()
+
+	name := sp.AddSession("accounts.google.com:user-xyz-124", "user-x", "builder", "host", &buildlet.FakeClient{})
+	s, err := sp.Session(name)
+	if err != nil {
+		t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+	}
+	if !s.LastActivity.Equal(s.Created) {
+		t.Errorf("Session.LastActivity = %s; want the creation time %s", s.LastActivity, s.Created)
+	}
+	// rewind moves the last activity into the past, and returns it.
+	rewind := func() time.Time {
+		sp.mu.Lock()
+		defer sp.mu.Unlock()
+		sp.m[name].LastActivity = sp.m[name].LastActivity.Add(-time.Hour)
+		return sp.m[name].LastActivity
+	}
+	lastActivity := func() time.Time {
+		s, err := sp.Session(name)
+		if err != nil {
+			t.Fatalf("SessionPool.Session(%q) = nil, %s; want no error", name, err)
+		}
+		return s.LastActivity
+	}
+	for _, tc := range []struct {
+		desc string
+		use  func()
+	}{
+		{"command started", func() { sp.CommandStarted(name) }},
+		{"SSH session started", func() { sp.SSHSessionStarted(name) }},
+		{"command finished", func() {
+			finished := sp.CommandStarted(name)
+			rewind()
+			finished()
+		}},
+		{"files written", func() {
+			if err := sp.RecordActivity(name); err != nil {
+				t.Fatalf("SessionPool.RecordActivity(%q) = %s; want no error", name, err)
+			}
+		}},
+	} {
+		before := rewind()
+		tc.use()
+		if got := lastActivity(); !got.After(before) {
+			t.Errorf("%s: Session.LastActivity = %s; want after %s", tc.desc, got, before)
+		}
+	}
+
+	// Listing or looking up the session isn't activity.
+	before := rewind()
+	sp.List()
+	if got := lastActivity(); !got.Equal(before) {
+		t.Errorf("Session.LastActivity = %s after lookups; want %s", got, before)
+	}
+	if err := sp.RecordActivity(name + "-wrong"); err == nil {
+		t.Errorf("SessionPool.RecordActivity(%q) = nil; want error", name+"-wrong")
+	}
+}
@@ -189,6 +189,7 @@ func (ss *SSHServer) HandleIncomingSSHPostAuth(s gssh.Session) {
 	if err := ss.sessionPool.KeepAlive(ctx, inst); err != nil {
 		log.Printf("ssh: KeepAlive on session=%s failed: %s", inst, err)
 	}
+	defer ss.sessionPool.SSHSessionStarted(inst)()
 
 	sshUser := hostConf.SSHUsername
 	useLocalSSHProxy := bconf.GOOS() != "plan9"
@@ -214,7 +215,7 @@ func (ss *SSHServer) HandleIncomingSSHPostAuth(s gssh.Session) {
 		return
 	}
 	if useLocalSSHProxy {
-		sshConn, err := bc.ConnectSSH(sshUser, ss.gomotePublicKey)
+		sshConn, err := bc.ConnectSSHContext(ctx, sshUser, ss.gomotePublicKey)
 		log.Printf("buildlet(%q).ConnectSSH = %T, %v", inst, sshConn, err)
 		if err != nil {
 			fmt.Fprintf(s, "failed to connect to ssh on %s: %v\n", inst, err)
@@ -329,6 +330,7 @@ func (ss *SSHServer) HandleIncomingSSHPostAuthSwarming(s gssh.Session) {
 	if err := ss.sessionPool.KeepAlive(ctx, inst); err != nil {
 		log.Printf("ssh: KeepAlive on session=%s failed: %s", inst, err)
 	}
+	defer ss.sessionPool.SSHSessionStarted(inst)()
 
 	sshUser := "swarming"
 	isPlan9 := strings.Contains(rs.HostType, "plan9")
@@ -350,7 +352,7 @@ func (ss *SSHServer) HandleIncomingSSHPostAuthSwarming(s gssh.Session) {
 		return
 	}
 	if useLocalSSHProxy {
-		sshConn, err := bc.ConnectSSH(sshUser, ss.gomotePublicKey)
+		sshConn, err := bc.ConnectSSHContext(ctx, sshUser, ss.gomotePublicKey)
 		log.Printf("buildlet(%q).ConnectSSH = %T, %v", inst, sshConn, err)
 		if err != nil {
 			fmt.Fprintf(s, "failed to connect to ssh on %s: %v\n", inst, err)
@@ -38,14 +38,24 @@ type Scheduler struct {
 	hostsCreating map[string]int // hostType -> count
 
 	lastProgress map[string]time.Time // hostType -> time last delivered buildlet
+
+	// waitTime is a moving average of how long the requests for each
+	// host type waited for their buildlet, from which WaiterState
+	// estimates how much longer waiters will wait.
+	waitTime map[string]time.Duration // hostType -> average wait
 }
 
+// waitTimeWeight is the weight of the latest wait in the moving
+// average of Scheduler.waitTime.
+const waitTimeWeight = 4 // 1/4
+
 // NewScheduler returns a new scheduler.
 func NewScheduler() *Scheduler {
 	s := &Scheduler{
 		hostsCreating: make(map[string]int),
 		waiting:       make(map[string]map[*queue.SchedItem]bool),
 		lastProgress:  make(map[string]time.Time),
+		waitTime:      make(map[string]time.Duration),
 	}
 	return s
 }
@@ -81,6 +91,18 @@ func (s *Scheduler) addWaiter(si *queue.SchedItem) {
 	s.waiting[si.HostType][si] = true
 }
 
+// recordDelivery records that si got its buildlet at t.
+func (s *Scheduler) recordDelivery(si *queue.SchedItem, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastProgress[si.HostType] = t
+	wait := t.Sub(si.RequestTime)
+	if avg, ok := s.waitTime[si.HostType]; ok {
+		wait = avg + (wait-avg)/waitTimeWeight
+	}
+	s.waitTime[si.HostType] = wait
+}
+
 func (s *Scheduler) hasWaiter(si *queue.SchedItem) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -151,18 +173,37 @@ func (s *Scheduler) State() (st SchedulerState) {
 }
 
 // WaiterState returns tells waiter how many callers are on the line
-// in front of them.
+// in front of them, both overall and for its host type, how many
+// machines of a static (reverse) host type are connected, and how much
+// longer it's likely to wait, if the scheduler has delivered buildlets
+// of its host type before.
 func (s *Scheduler) WaiterState(waiter *queue.SchedItem) (ws types.BuildletWaitStatus) {
+	now := time.Now()
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	m := s.waiting[waiter.HostType]
-	for si := range m {
-		if si.Less(waiter) {
-			ws.Ahead++
+	for hostType, m := range s.waiting {
+		for si := range m {
+			if si.Less(waiter) {
+				ws.Ahead++
+				if hostType == waiter.HostType {
+					ws.AheadHostType++
+				}
+			}
 		}
 	}
+	if avg, ok := s.waitTime[waiter.HostType]; ok {
+		// Waiters which have already waited longer than the average
+		// get no estimate, rather than a misleading one.
+		if left := (avg - now.Sub(waiter.RequestTime)).Round(time.Second); left > 0 {
+			ws.EstimatedWait = left
+			ws.EstimatedAt = &now
+		}
+	}
+	s.mu.Unlock()
 
+	if hc, ok := dashboard.Hosts[waiter.HostType]; ok && hc.IsReverse {
+		ws.PoolSize = hc.ExpectNum
+		ws.Connected = pool.ReversePool().SingleHostTypeCount(waiter.HostType)
+	}
 	return ws
 }
 
@@ -179,5 +220,9 @@ func (s *Scheduler) GetBuildlet(ctx context.Context, si *queue.SchedItem) (build
 	s.addWaiter(si)
 	defer s.removeWaiter(si)
 
-	return pool.ForHost(hostConf).GetBuildlet(ctx, si.HostType, stderrLogger{}, si)
+	bc, err := pool.ForHost(hostConf).GetBuildlet(ctx, si.HostType, stderrLogger{}, si)
+	if err == nil {
+		s.recordDelivery(si, time.Now())
+	}
+	return bc, err
 }
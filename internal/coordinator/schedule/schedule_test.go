@@ -205,3 +205,47 @@ func TestScheduler(t *testing.T) {
 		})
 	}
 }
+
+func TestWaiterState(t *testing.T) {
+	s := NewScheduler()
+	now := time.Now()
+	newWaiter := func(hostType string, ago time.Duration) *queue.SchedItem {
+		si := &queue.SchedItem{HostType: hostType, IsGomote: true, RequestTime: now.Add(-ago)}
+		s.addWaiter(si)
+		return si
+	}
+	newWaiter("test-host-foo", 3*time.Minute)
+	newWaiter("test-host-bar", 2*time.Minute)
+	newWaiter("test-host-foo", time.Minute)
+	waiter := newWaiter("test-host-foo", 30*time.Second)
+	newWaiter("test-host-foo", 0)
+	newWaiter("test-host-bar", 0)
+
+	ws := s.WaiterState(waiter)
+	if ws.Ahead != 3 || ws.AheadHostType != 2 {
+		t.Errorf("WaiterState = %d ahead, %d ahead of the same host type; want 3, 2", ws.Ahead, ws.AheadHostType)
+	}
+	if ws.PoolSize != 0 || ws.Connected != 0 {
+		t.Errorf("WaiterState for a dynamic host type = pool size %d, %d connected; want 0, 0", ws.PoolSize, ws.Connected)
+	}
+	if ws.EstimatedWait != 0 || ws.EstimatedAt != nil {
+		t.Errorf("WaiterState before any delivery = estimated wait %v at %v; want none", ws.EstimatedWait, ws.EstimatedAt)
+	}
+
+	s.recordDelivery(&queue.SchedItem{HostType: "test-host-foo", RequestTime: now.Add(-8 * time.Minute)}, now)
+	s.recordDelivery(&queue.SchedItem{HostType: "test-host-foo", RequestTime: now}, now)
+	// The average wait is 8m - 8m/4 = 6m, of which the waiter has waited 30s.
+	ws = s.WaiterState(waiter)
+	if got, want := ws.EstimatedWait, 5*time.Minute+30*time.Second; got != want || ws.EstimatedAt == nil {
+		t.Errorf("WaiterState after deliveries = estimated wait %v at %v; want %v", got, ws.EstimatedAt, want)
+	}
+	ws = s.WaiterState(&queue.SchedItem{HostType: "test-host-foo", RequestTime: now.Add(-10 * time.Minute)})
+	if ws.EstimatedWait != 0 || ws.EstimatedAt != nil {
+		t.Errorf("WaiterState for a waiter past the average wait = estimated wait %v at %v; want none", ws.EstimatedWait, ws.EstimatedAt)
+	}
+
+	ws = s.WaiterState(&queue.SchedItem{HostType: "host-aix-ppc64-osuosl", IsGomote: true, RequestTime: now})
+	if want := dashboard.Hosts["host-aix-ppc64-osuosl"].ExpectNum; ws.PoolSize != want {
+		t.Errorf("WaiterState for a reverse host type = pool size %d; want %d", ws.PoolSize, want)
+	}
+}
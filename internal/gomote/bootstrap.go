@@ -0,0 +1,217 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+package gomote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/build/buildlet"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bootstrapVersionRE matches the versions of Go which may be requested as
+// the bootstrap version.
+var bootstrapVersionRE = regexp.MustCompile(`^[0-9]+\.[0-9]+(\.[0-9]+)?((rc|beta)[0-9]+)?$`)
+
+// bootstrapURLPrefixes are the prefixes of the URLs the bootstrap version
+// of Go may be requested from. The server downloads them itself, so they
+// are limited to where the Go project hosts its releases.
+var bootstrapURLPrefixes = []string{
+	"https://dl.google.com/go/",
+	"https://go.dev/dl/",
+	"https://storage.googleapis.com/go-builder-data/",
+}
+
+// bootstrapProgressInterval is the minimum interval between the progress
+// reports of InstallBootstrap.
+const bootstrapProgressInterval = time.Second
+
+// validateBootstrapRequest returns an error if req requests the bootstrap
+// version of Go from an invalid source.
+func validateBootstrapRequest(req *protos.AddBootstrapRequest) error {
+	v, u := bootstrapVersion(req), req.GetUrl()
+	if v != "" && u != "" {
+		return status.Errorf(codes.InvalidArgument, "at most one of version and url may be set")
+	}
+	if v != "" && !bootstrapVersionRE.MatchString(v) {
+		return status.Errorf(codes.InvalidArgument, "invalid Go version %q", req.GetVersion())
+	}
+	if u != "" {
+		if !strings.HasSuffix(u, ".tar.gz") {
+			return status.Errorf(codes.InvalidArgument, "bootstrap URL %q is not a .tar.gz archive", u)
+		}
+		allowed := false
+		for _, p := range bootstrapURLPrefixes {
+			allowed = allowed || strings.HasPrefix(u, p)
+		}
+		if !allowed {
+			return status.Errorf(codes.InvalidArgument, "bootstrap URL %q is not hosted by the Go project", u)
+		}
+	}
+	if len(req.GetUrlHeaders()) > 0 && u == "" {
+		return status.Errorf(codes.InvalidArgument, "headers may only be sent with a bootstrap URL")
+	}
+	if _, err := urlHeader(u, req.GetUrlHeaders()); err != nil {
+		return err
+	}
+	if sum := req.GetSha256(); sum != "" {
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+			return status.Errorf(codes.InvalidArgument, "invalid SHA-256 checksum %q", sum)
+		}
+	}
+	return nil
+}
+
+// bootstrapVersion returns the version of Go requested by req, without
+// any "go" prefix.
+func bootstrapVersion(req *protos.AddBootstrapRequest) string {
+	return strings.TrimPrefix(req.GetVersion(), "go")
+}
+
+// bootstrapSource returns the URL of the bootstrap version of Go requested
+// by req, which has been validated, for an instance with the given GOOS
+// and GOARCH. If req doesn't request a version, it returns defaultURL.
+func bootstrapSource(req *protos.AddBootstrapRequest, goos, goarch, defaultURL string) string {
+	switch {
+	case req.GetUrl() != "":
+		return req.GetUrl()
+	case bootstrapVersion(req) != "":
+		return bootstrapURL(goos, goarch, bootstrapVersion(req))
+	}
+	return defaultURL
+}
+
+// bootstrapHeader returns the headers to send with the request downloading
+// the bootstrap version of Go requested by req, which has been validated.
+func bootstrapHeader(req *protos.AddBootstrapRequest) http.Header {
+	h, _ := urlHeader(req.GetUrl(), req.GetUrlHeaders())
+	return h
+}
+
+// bootstrapURL returns the URL of the bootstrap toolchain of the given Go
+// version for goos and goarch.
+func bootstrapURL(goos, goarch, version string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/go-builder-data/gobootstrap-%s-%s-go%s.tar.gz", goos, goarch, version)
+}
+
+// installBootstrap downloads the bootstrap version of Go from url, sending
+// header with the request, checks that it matches the hex-encoded SHA-256
+// sum if it's set, and writes it to the directory dir of the instance bc.
+// It reports its progress with send. If url is empty, the instance needs
+// no bootstrap version, and it only reports that it's complete.
+func installBootstrap(ctx context.Context, bc buildlet.Client, url string, header http.Header, sum, dir string, send func(*protos.InstallBootstrapResponse) error) error {
+	if url == "" {
+		return send(&protos.InstallBootstrapResponse{Phase: protos.InstallBootstrapResponse_COMPLETE})
+	}
+	f, err := os.CreateTemp("", "gobootstrap-*.tar.gz")
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to create temporary file: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to download bootstrap Go: %s", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("InstallBootstrap: downloading %s with headers %s: %s", url, redactHeader(header), err)
+		return status.Errorf(codes.Unavailable, "unable to download bootstrap Go: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return status.Errorf(codes.NotFound, "unable to download bootstrap Go from %s: %s", url, res.Status)
+	}
+	total := res.ContentLength
+	if total < 0 {
+		total = 0 // unknown
+	}
+	h := sha256.New()
+	download := &progressReader{r: res.Body, phase: protos.InstallBootstrapResponse_DOWNLOADING, total: total, send: send}
+	if _, err := io.Copy(io.MultiWriter(f, h), download); err != nil {
+		if download.sendErr != nil {
+			return status.Errorf(codes.Internal, "unable to stream progress: %s", err)
+		}
+		return status.Errorf(codes.Unavailable, "unable to download bootstrap Go: %s", err)
+	}
+	if err := download.report(); err != nil {
+		return status.Errorf(codes.Internal, "unable to stream progress: %s", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); sum != "" && !strings.EqualFold(got, sum) {
+		return status.Errorf(codes.DataLoss, "bootstrap Go from %s has SHA-256 %s; want %s", url, got, sum)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return status.Errorf(codes.Internal, "unable to read bootstrap Go: %s", err)
+	}
+	extract := &progressReader{r: f, phase: protos.InstallBootstrapResponse_EXTRACTING, total: download.done, send: send}
+	if err := bc.PutTar(ctx, extract, dir); err != nil {
+		log.Printf("InstallBootstrap: writing %s to %s: %s", url, dir, err)
+		return status.Errorf(codes.Internal, "unable to write bootstrap Go to the instance: %s", err)
+	}
+	return send(&protos.InstallBootstrapResponse{
+		Phase:          protos.InstallBootstrapResponse_COMPLETE,
+		BytesDone:      download.done,
+		BytesTotal:     download.done,
+		BootstrapGoUrl: url,
+		Directory:      dir,
+	})
+}
+
+// progressReader is a reader which reports how much of it has been read
+// in a phase of InstallBootstrap, at most once per
+// bootstrapProgressInterval.
+type progressReader struct {
+	r       io.Reader
+	phase   protos.InstallBootstrapResponse_Phase
+	done    int64
+	total   int64 // or 0 if unknown
+	last    time.Time
+	send    func(*protos.InstallBootstrapResponse) error
+	sendErr error // the error returned by send, if any
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.done += int64(n)
+	if time.Since(pr.last) >= bootstrapProgressInterval {
+		if err := pr.report(); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// report reports how much has been read so far.
+func (pr *progressReader) report() error {
+	pr.last = time.Now()
+	err := pr.send(&protos.InstallBootstrapResponse{
+		Phase:      pr.phase,
+		BytesDone:  pr.done,
+		BytesTotal: pr.total,
+	})
+	if err != nil {
+		pr.sendErr = err
+	}
+	return err
+}
@@ -15,6 +15,8 @@ import (
 	"log"
 	"net/http"
 	"regexp"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"time"
 
@@ -53,6 +55,7 @@ type Server struct {
 	// embed the unimplemented server.
 	protos.UnimplementedGomoteServiceServer
 
+	admins                  map[string]bool // emails of users who may manage any instance
 	bucket                  bucketHandle
 	buildlets               *remote.SessionPool
 	gceBucketName           string
@@ -75,6 +78,12 @@ func New(rsp *remote.SessionPool, sched *schedule.Scheduler, rawCAPriKey []byte,
 	}
 }
 
+// SetAdmins sets the email addresses of the administrators, who may manage the instances of
+// other users. It must be called before the server is used.
+func (s *Server) SetAdmins(emails []string) {
+	s.admins = adminSet(emails)
+}
+
 // AddBootstrap adds the bootstrap version of Go to an instance and returns the URL for the bootstrap version. If no
 // bootstrap version is defined then the returned version URL will be empty.
 func (s *Server) AddBootstrap(ctx context.Context, req *protos.AddBootstrapRequest) (*protos.AddBootstrapResponse, error) {
@@ -83,22 +92,26 @@ func (s *Server) AddBootstrap(ctx context.Context, req *protos.AddBootstrapReque
 		log.Printf("AddBootstrap access.IAPFromContext(ctx) = nil, %s", err)
 		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
 	}
+	if req.GetSha256() != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "the checksum is only verified by InstallBootstrap")
+	}
 	ses, bc, err := s.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
 	if err != nil {
 		// the helper function returns meaningful GRPC error.
 		return nil, err
 	}
-	bconf, ok := dashboard.Builders[ses.BuilderType]
-	if !ok {
-		return nil, status.Errorf(codes.Internal, "unknown builder type")
+	url, dir, err := s.bootstrap(ses, req)
+	if err != nil {
+		return nil, err
 	}
-	url := bconf.GoBootstrapURL(buildenv.Production)
 	if url == "" {
 		return &protos.AddBootstrapResponse{}, nil
 	}
-	if err = bc.PutTarFromURL(ctx, url, "go1.4"); err != nil {
+	if err = bc.PutTarFromURL(ctx, url, dir, buildlet.TarOpts{Header: bootstrapHeader(req)}); err != nil {
+		log.Printf("AddBootstrap: writing %s with headers %s to %s: %s", url, redactHeader(bootstrapHeader(req)), dir, err)
 		return nil, status.Errorf(codes.Internal, "unable to download bootstrap Go")
 	}
+	recordActivity(s.buildlets, req.GetGomoteId())
 	return &protos.AddBootstrapResponse{BootstrapGoUrl: url}, nil
 }
 
@@ -125,7 +138,7 @@ func (s *Server) CreateInstance(req *protos.CreateInstanceRequest, stream protos
 	}
 	bconf, ok := dashboard.Builders[req.GetBuilderType()]
 	if !ok {
-		return status.Errorf(codes.InvalidArgument, "unknown builder type")
+		return buildlet.UnknownBuilderTypeError()
 	}
 	if ((!bconf.HostConfig().IsHermetic() && bconf.HostConfig().IsGoogle()) || bconf.IsRestricted()) && !isPrivilegedUser(creds.Email) {
 		return status.Errorf(codes.PermissionDenied, "user is unable to create gomote of that builder type")
@@ -134,6 +147,13 @@ func (s *Server) CreateInstance(req *protos.CreateInstanceRequest, stream protos
 	if err != nil {
 		return status.Errorf(codes.Internal, "invalid user email format")
 	}
+	if err := validateLifetime(req, time.Now(), maxInstanceLifetime(creds.Email, bconf.IsReverse())); err != nil {
+		return err
+	}
+	labels, err := parseLabels(req.GetLabels())
+	if err != nil {
+		return err
+	}
 	si := &queue.SchedItem{
 		HostType:  bconf.HostType,
 		IsGomote:  true,
@@ -160,6 +180,14 @@ func (s *Server) CreateInstance(req *protos.CreateInstanceRequest, stream protos
 			err := stream.Send(&protos.CreateInstanceResponse{
 				Status:       protos.CreateInstanceResponse_WAITING,
 				WaitersAhead: int64(st.Ahead),
+				QueuePosition: &protos.CreateInstanceResponse_QueuePosition{
+					HostType:             bconf.HostType,
+					WaitersAhead:         int64(st.AheadHostType),
+					PoolSize:             int64(st.PoolSize),
+					Connected:            int64(st.Connected),
+					EstimatedWaitSeconds: int64(st.EstimatedWait / time.Second),
+					EstimatedAt:          estimatedAt(st),
+				},
 			})
 			if err != nil {
 				return status.Errorf(codes.Internal, "unable to stream result: %s", err)
@@ -172,6 +200,17 @@ func (s *Server) CreateInstance(req *protos.CreateInstanceRequest, stream protos
 			}
 			gomoteID := s.buildlets.AddSession(creds.ID, userName, req.GetBuilderType(), bconf.HostType, r.buildletClient)
 			log.Printf("created buildlet %v for %v (%s)", gomoteID, userName, r.buildletClient.String())
+			if err := s.buildlets.SetMaxLifetime(gomoteID, maxInstanceLifetime(creds.Email, bconf.IsReverse())); err != nil {
+				return status.Errorf(codes.Internal, "unable to set gomote maximum lifetime: %s", err) // this should never happen
+			}
+			if deadline := instanceDeadline(req, time.Now()); !deadline.IsZero() {
+				if err := s.buildlets.SetDeadline(gomoteID, deadline); err != nil {
+					return status.Errorf(codes.Internal, "unable to set gomote deadline: %s", err) // this should never happen
+				}
+			}
+			if err := s.buildlets.SetLabels(gomoteID, labels); err != nil {
+				return status.Errorf(codes.Internal, "unable to set gomote labels: %s", err) // this should never happen
+			}
 			session, err := s.buildlets.Session(gomoteID)
 			if err != nil {
 				return status.Errorf(codes.Internal, "unable to query for gomote timeout") // this should never happen
@@ -182,11 +221,14 @@ func (s *Server) CreateInstance(req *protos.CreateInstanceRequest, stream protos
 			}
 			err = stream.Send(&protos.CreateInstanceResponse{
 				Instance: &protos.Instance{
-					GomoteId:    gomoteID,
-					BuilderType: req.GetBuilderType(),
-					HostType:    bconf.HostType,
-					Expires:     session.Expires.Unix(),
-					WorkingDir:  wd,
+					GomoteId:     gomoteID,
+					BuilderType:  req.GetBuilderType(),
+					HostType:     bconf.HostType,
+					Expires:      session.Expires.Unix(),
+					WorkingDir:   wd,
+					Created:      session.Created.Unix(),
+					Labels:       formatLabels(session.Labels),
+					LastActivity: session.LastActivity.Unix(),
 				},
 				Status:       protos.CreateInstanceResponse_COMPLETE,
 				WaitersAhead: 0,
@@ -199,6 +241,30 @@ func (s *Server) CreateInstance(req *protos.CreateInstanceRequest, stream protos
 	}
 }
 
+// InstallBootstrap is like AddBootstrap, but the server downloads the bootstrap version of Go itself, verifying its
+// checksum if requested, and streams the progress of the installation to the caller.
+func (s *Server) InstallBootstrap(req *protos.AddBootstrapRequest, stream protos.GomoteService_InstallBootstrapServer) error {
+	creds, err := access.IAPFromContext(stream.Context())
+	if err != nil {
+		log.Printf("InstallBootstrap access.IAPFromContext(ctx) = nil, %s", err)
+		return status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	ses, bc, err := s.sessionAndClient(stream.Context(), req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return err
+	}
+	url, dir, err := s.bootstrap(ses, req)
+	if err != nil {
+		return err
+	}
+	if err := installBootstrap(stream.Context(), bc, url, bootstrapHeader(req), req.GetSha256(), dir, stream.Send); err != nil {
+		return err
+	}
+	recordActivity(s.buildlets, req.GetGomoteId())
+	return nil
+}
+
 // InstanceAlive will ensure that the gomote instance is still alive and will extend the timeout. The requester must be authenticated.
 func (s *Server) InstanceAlive(ctx context.Context, req *protos.InstanceAliveRequest) (*protos.InstanceAliveResponse, error) {
 	creds, err := access.IAPFromContext(ctx)
@@ -217,7 +283,112 @@ func (s *Server) InstanceAlive(ctx context.Context, req *protos.InstanceAliveReq
 	if err := s.buildlets.RenewTimeout(req.GetGomoteId()); err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to renew timeout")
 	}
-	return &protos.InstanceAliveResponse{}, nil
+	if !req.GetPingBuildlet() {
+		return &protos.InstanceAliveResponse{}, nil
+	}
+	_, bc, err := s.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	return pingBuildlet(ctx, bc)
+}
+
+// pingBuildlet makes a round trip to bc, reporting its latency and clock
+// skew for InstanceAlive.
+func pingBuildlet(ctx context.Context, bc buildlet.Client) (*protos.InstanceAliveResponse, error) {
+	pr, err := bc.Ping(ctx)
+	if err != nil {
+		log.Printf("InstanceAlive buildletClient.Ping(ctx) = %s", err)
+		return nil, status.Errorf(codes.Unavailable, "unable to reach buildlet: %s", err)
+	}
+	resp := &protos.InstanceAliveResponse{
+		BuildletRttMicros: pr.RTT.Microseconds(),
+		ClockSkewMicros:   pr.Skew.Microseconds(),
+		ClockReported:     !pr.Status.Time.IsZero(),
+	}
+	for _, rtt := range bc.HeartbeatLatencies() {
+		resp.HeartbeatRttMicros = append(resp.HeartbeatRttMicros, rtt.Microseconds())
+	}
+	return resp, nil
+}
+
+// InstanceStatus gives detailed information about a gomote instance, including whether the buildlet running on
+// the instance is reachable. The requester must be authenticated and be the owner of the instance.
+func (s *Server) InstanceStatus(ctx context.Context, req *protos.InstanceStatusRequest) (*protos.InstanceStatusResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("InstanceStatus access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if req.GetGomoteId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid gomote ID")
+	}
+	// Looking at the instance doesn't renew it, unlike the other RPCs.
+	ses, err := peekSession(s.buildlets, req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	res := sessionStatus(ses)
+	if req.GetSkipBuildlet() {
+		return res, nil
+	}
+	bc, err := s.buildlets.BuildletClient(req.GetGomoteId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "specified gomote instance does not exist")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if wd, err := bc.WorkDir(ctx); err == nil {
+		res.Instance.WorkingDir = wd
+	} else {
+		log.Printf("InstanceStatus WorkDir(%s) = %s", req.GetGomoteId(), err)
+	}
+	if st, err := bc.DetailedStatus(ctx); err == nil {
+		res.Reachable = true
+		res.BuildletVersion = int32(st.Version)
+		setMachineStatus(res, st)
+	} else {
+		log.Printf("InstanceStatus DetailedStatus(%s) = %s", req.GetGomoteId(), err)
+	}
+	return res, nil
+}
+
+// sessionStatus returns the status of the instance of session ses known
+// without contacting its buildlet.
+func sessionStatus(ses *remote.Session) *protos.InstanceStatusResponse {
+	return &protos.InstanceStatusResponse{
+		Instance:       protoInstance(instanceInfo(ses)),
+		ActiveCommands: int32(ses.ActiveCommands),
+		ActiveSessions: int32(ses.ActiveSSHSessions),
+	}
+}
+
+// setMachineStatus copies the buildlet's description of its machine in
+// st to res.
+func setMachineStatus(res *protos.InstanceStatusResponse, st buildlet.Status) {
+	res.DiskFreeBytes = st.DiskFree
+	res.DiskTotalBytes = st.DiskTotal
+	res.MemTotalBytes = st.MemTotal
+	res.MemAvailableBytes = st.MemAvailable
+	res.LoadAverage = st.LoadAvg
+	res.WorkDirSizeBytes = st.WorkDirSize
+}
+
+// ListCommands lists the commands executing on a gomote instance. The requester must be authenticated and own the
+// instance.
+func (s *Server) ListCommands(ctx context.Context, req *protos.ListCommandsRequest) (*protos.ListCommandsResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("ListCommands access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if _, err := s.session(req.GetGomoteId(), creds.ID); err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	return listCommands(s.buildlets, req)
 }
 
 // ListDirectory lists the contents of the directory on a gomote instance.
@@ -238,6 +409,8 @@ func (s *Server) ListDirectory(ctx context.Context, req *protos.ListDirectoryReq
 		Recursive: req.GetRecursive(),
 		Digest:    req.GetDigest(),
 		Skip:      req.GetSkipFiles(),
+		Patterns:  req.GetPatterns(),
+		MaxDepth:  int(req.GetMaxDepth()),
 	}
 	var entries []string
 	if err = bc.ListDir(context.Background(), req.GetDirectory(), opt, func(bi buildlet.DirEntry) {
@@ -257,17 +430,22 @@ func (s *Server) ListInstances(ctx context.Context, req *protos.ListInstancesReq
 		log.Printf("ListInstances access.IAPFromContext(ctx) = nil, %s", err)
 		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
 	}
+	if err := validateLabelSelector(req.GetLabelSelector()); err != nil {
+		return nil, err
+	}
 	res := &protos.ListInstancesResponse{}
+	minAge := time.Duration(req.GetMinAgeSeconds()) * time.Second
 	for _, s := range s.buildlets.List() {
-		if s.OwnerID != creds.ID {
+		if s.OwnerID != creds.ID || !strings.HasPrefix(s.BuilderType, req.GetBuilderTypePrefix()) {
 			continue
 		}
-		res.Instances = append(res.Instances, &protos.Instance{
-			GomoteId:    s.ID,
-			BuilderType: s.BuilderType,
-			HostType:    s.HostType,
-			Expires:     s.Expires.Unix(),
-		})
+		if minAge > 0 && time.Since(s.Created) < minAge {
+			continue
+		}
+		if !matchLabels(s.Labels, req.GetLabelSelector()) {
+			continue
+		}
+		res.Instances = append(res.Instances, protoInstance(instanceInfo(s)))
 	}
 	return res, nil
 }
@@ -314,27 +492,40 @@ func (s *Server) ExecuteCommand(req *protos.ExecuteCommandRequest, stream protos
 	if !ok {
 		return status.Errorf(codes.Internal, "unable to retrieve configuration for instance")
 	}
-	remoteErr, execErr := bc.Exec(stream.Context(), req.GetCommand(), buildlet.ExecOpts{
+	ctx, id, finished, err := startCommand(s.buildlets, req, stream)
+	if err != nil {
+		return err
+	}
+	defer finished()
+	start := time.Now()
+	remoteErr, execErr := bc.Exec(ctx, req.GetCommand(), buildlet.ExecOpts{
 		Dir:         req.GetDirectory(),
 		SystemLevel: req.GetSystemLevel(),
-		Output: &streamWriter{writeFunc: func(p []byte) (int, error) {
+		OnStream: func(s buildlet.ExecStream, p []byte) error {
 			err := stream.Send(&protos.ExecuteCommandResponse{
 				Output: p,
+				Stream: execStream(s),
 			})
 			if err != nil {
-				return 0, fmt.Errorf("unable to send data=%w", err)
+				return fmt.Errorf("unable to send data=%w", err)
 			}
-			return len(p), nil
-		}},
+			return nil
+		},
 		Args:     req.GetArgs(),
 		ExtraEnv: envutil.Dedup(conf.GOOS(), append(conf.Env(), req.GetAppendEnvironment()...)),
 		Debug:    req.GetDebug(),
 		Path:     req.GetPath(),
 	})
+	if ctx.Err() != nil && stream.Context().Err() == nil {
+		return status.Errorf(codes.Canceled, "command %s was canceled", id)
+	}
 	if execErr != nil {
 		// there were system errors preventing the command from being started or seen to completion.
 		return status.Errorf(codes.Aborted, "unable to execute command: %s", execErr)
 	}
+	if err := stream.Send(&protos.ExecuteCommandResponse{ExitStatus: exitStatus(remoteErr, time.Since(start))}); err != nil {
+		return status.Errorf(codes.Internal, "unable to stream result: %s", err)
+	}
 	if remoteErr != nil {
 		// the command succeeded remotely
 		return status.Errorf(codes.Unknown, "command execution failed: %s", remoteErr)
@@ -342,14 +533,31 @@ func (s *Server) ExecuteCommand(req *protos.ExecuteCommandRequest, stream protos
 	return nil
 }
 
-// streamWriter implements the io.Writer interface.
-type streamWriter struct {
-	writeFunc func(p []byte) (int, error)
+// CancelCommand cancels a command executing on a gomote instance, which kills its process. The requester must be
+// authenticated and own the instance.
+func (s *Server) CancelCommand(ctx context.Context, req *protos.CancelCommandRequest) (*protos.CancelCommandResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("CancelCommand access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if _, err := s.session(req.GetGomoteId(), creds.ID); err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	return cancelCommand(s.buildlets, req)
 }
 
-// Write calls the writeFunc function with the same arguments passed to the Write function.
-func (sw *streamWriter) Write(p []byte) (int, error) {
-	return sw.writeFunc(p)
+// ExtendInstance extends the expiration time of a gomote instance. The requester must be authenticated and
+// be the owner of the instance or an administrator. The extension may be reduced so the instance does not exceed
+// its maximum lifetime, which depends on its owner and builder type.
+func (s *Server) ExtendInstance(ctx context.Context, req *protos.ExtendInstanceRequest) (*protos.ExtendInstanceResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("ExtendInstance access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	return extendInstance(s.buildlets, s.admins, creds, req)
 }
 
 // ReadTGZToURL retrieves a directory from the gomote instance and writes the file to GCS. It returns a signed URL which the caller uses
@@ -413,9 +621,19 @@ func (s *Server) RemoveFiles(ctx context.Context, req *protos.RemoveFilesRequest
 		log.Printf("RemoveFiles buildletClient.RemoveAll(ctx, %q) = %s", req.GetPaths(), err)
 		return nil, status.Errorf(codes.Unknown, "unable to remove files")
 	}
+	recordActivity(s.buildlets, req.GetGomoteId())
 	return &protos.RemoveFilesResponse{}, nil
 }
 
+// ServerVersion returns the version of the gomote server binary.
+func (s *Server) ServerVersion(ctx context.Context, req *protos.ServerVersionRequest) (*protos.ServerVersionResponse, error) {
+	if _, err := access.IAPFromContext(ctx); err != nil {
+		log.Printf("ServerVersion access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	return serverVersion(), nil
+}
+
 // SignSSHKey signs the public SSH key with a certificate. The signed public SSH key is intended for use with the gomote service SSH
 // server. It will be signed by the certificate authority of the server and will restrict access to the gomote instance that it was
 // signed for.
@@ -438,6 +656,55 @@ func (s *Server) SignSSHKey(ctx context.Context, req *protos.SignSSHKeyRequest)
 	}, nil
 }
 
+// TailFile streams the contents of a file on a gomote instance. The requester must be authenticated and be the
+// owner of the instance.
+func (s *Server) TailFile(req *protos.TailFileRequest, stream protos.GomoteService_TailFileServer) error {
+	creds, err := access.IAPFromContext(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if req.GetGomoteId() == "" || req.GetPath() == "" || req.GetOffset() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid arguments")
+	}
+	_, bc, err := s.sessionAndClient(stream.Context(), req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return err
+	}
+	var sendErr error
+	err = bc.Tail(stream.Context(), req.GetPath(), tailWriter{stream}, buildlet.TailOpts{
+		Offset: req.GetOffset(),
+		Lines:  int(req.GetLines()),
+		Follow: req.GetFollow(),
+		OnTruncate: func() {
+			sendErr = stream.Send(&protos.TailFileResponse{Truncated: true})
+		},
+	})
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		log.Printf("TailFile buildletClient.Tail(ctx, %q) = %s", req.GetPath(), err)
+		return status.Errorf(codes.Aborted, "unable to tail file: %s", err)
+	}
+	return nil
+}
+
+// tailWriter sends the data written to it in TailFile responses.
+type tailWriter struct {
+	stream protos.GomoteService_TailFileServer
+}
+
+func (w tailWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&protos.TailFileResponse{Data: p}); err != nil {
+		return 0, fmt.Errorf("unable to send data=%w", err)
+	}
+	return len(p), nil
+}
+
 // UploadFile creates a URL and a set of HTTP post fields which are used to upload a file to a staging GCS bucket. Uploaded files are made available to the
 // gomote instances via a subsequent call to one of the WriteFromURL endpoints.
 func (s *Server) UploadFile(ctx context.Context, req *protos.UploadFileRequest) (*protos.UploadFileResponse, error) {
@@ -493,6 +760,10 @@ func (s *Server) WriteFileFromURL(ctx context.Context, req *protos.WriteFileFrom
 		log.Printf("WriteTGZFromURL access.IAPFromContext(ctx) = nil, %s", err)
 		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
 	}
+	header, err := urlHeader(req.GetUrl(), req.GetUrlHeaders())
+	if err != nil {
+		return nil, err
+	}
 	_, bc, err := s.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
 	if err != nil {
 		// the helper function returns meaningful GRPC error.
@@ -519,8 +790,10 @@ func (s *Server) WriteFileFromURL(ctx context.Context, req *protos.WriteFileFrom
 				TLSHandshakeTimeout: 5 * time.Second,
 			},
 		}
+		httpRequest.Header = header
 		resp, err := client.Do(httpRequest)
 		if err != nil {
+			log.Printf("WriteFileFromURL: fetching %s with headers %s: %s", req.GetUrl(), redactHeader(header), err)
 			return nil, status.Errorf(codes.Aborted, "failed to get file from URL: %s", err)
 		}
 		if resp.StatusCode != http.StatusOK {
@@ -532,6 +805,7 @@ func (s *Server) WriteFileFromURL(ctx context.Context, req *protos.WriteFileFrom
 	if err := bc.Put(ctx, rc, req.GetFilename(), fs.FileMode(req.GetMode())); err != nil {
 		return nil, status.Errorf(codes.Aborted, "failed to send the file to the gomote instance: %s", err)
 	}
+	recordActivity(s.buildlets, req.GetGomoteId())
 	return &protos.WriteFileFromURLResponse{}, nil
 }
 
@@ -549,6 +823,10 @@ func (s *Server) WriteTGZFromURL(ctx context.Context, req *protos.WriteTGZFromUR
 	if req.GetUrl() == "" {
 		return nil, status.Errorf(codes.InvalidArgument, "missing URL")
 	}
+	header, err := urlHeader(req.GetUrl(), req.GetUrlHeaders())
+	if err != nil {
+		return nil, err
+	}
 	_, bc, err := s.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
 	if err != nil {
 		// the helper function returns meaningful GRPC error.
@@ -564,11 +842,25 @@ func (s *Server) WriteTGZFromURL(ctx context.Context, req *protos.WriteTGZFromUR
 		if err != nil {
 			return nil, status.Errorf(codes.Aborted, "unable to sign url for download: %s", err)
 		}
+		// The signed URL grants access by itself.
+		header = nil
 	}
-	if err := bc.PutTarFromURL(ctx, url, req.GetDirectory()); err != nil {
+	sha256, err := bc.PutTarFromURLSHA256(ctx, url, req.GetDirectory(), req.GetSha256(), buildlet.TarOpts{Header: header})
+	if err != nil {
+		log.Printf("WriteTGZFromURL: writing %s with headers %s to %s: %s", req.GetUrl(), redactHeader(header), req.GetDirectory(), err)
 		return nil, status.Errorf(codes.FailedPrecondition, "unable to write tar.gz: %s", err)
 	}
-	return &protos.WriteTGZFromURLResponse{}, nil
+	recordActivity(s.buildlets, req.GetGomoteId())
+	return &protos.WriteTGZFromURLResponse{Sha256: sha256}, nil
+}
+
+// estimatedAt returns when the estimated wait of st was estimated, in Unix
+// seconds, or zero if there's no estimate.
+func estimatedAt(st types.BuildletWaitStatus) int64 {
+	if st.EstimatedAt == nil {
+		return 0
+	}
+	return st.EstimatedAt.Unix()
 }
 
 // session is a helper function that retrieves a session associated with the gomoteID and ownerID.
@@ -583,6 +875,18 @@ func (s *Server) session(gomoteID, ownerID string) (*remote.Session, error) {
 	return session, nil
 }
 
+// peekSession is like session, but doesn't renew the gomote instance.
+func peekSession(sp *remote.SessionPool, gomoteID, ownerID string) (*remote.Session, error) {
+	session, err := sp.Peek(gomoteID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "specified gomote instance does not exist")
+	}
+	if session.OwnerID != ownerID {
+		return nil, status.Errorf(codes.PermissionDenied, "not allowed to modify this gomote session")
+	}
+	return session, nil
+}
+
 // sessionAndClient is a helper function that retrieves a session and buildlet client for the
 // associated gomoteID and ownerID. The gomote instance timeout is renewed if the gomote id and owner id
 // are valid.
@@ -601,6 +905,19 @@ func (s *Server) sessionAndClient(ctx context.Context, gomoteID, ownerID string)
 	return session, bc, nil
 }
 
+// bootstrap returns the URL of the bootstrap version of Go requested by req for the
+// instance of session ses, or "" if it needs none, and the directory to install it in.
+func (s *Server) bootstrap(ses *remote.Session, req *protos.AddBootstrapRequest) (url, dir string, err error) {
+	if err := validateBootstrapRequest(req); err != nil {
+		return "", "", err
+	}
+	bconf, ok := dashboard.Builders[ses.BuilderType]
+	if !ok {
+		return "", "", status.Errorf(codes.Internal, "unknown builder type")
+	}
+	return bootstrapSource(req, bconf.GOOS(), bconf.GOARCH(), bconf.GoBootstrapURL(buildenv.Production)), "go1.4", nil
+}
+
 // isPrivilegedUser returns true if the user is trusted to use sensitive machines.
 // The user has to be a part of the appropriate IAM group.
 func isPrivilegedUser(email string) bool {
@@ -608,10 +925,299 @@ func isPrivilegedUser(email string) bool {
 		strings.HasSuffix(email, "@go-security-trybots.iam.gserviceaccount.com")
 }
 
+// Limits on the lifetime callers may request for an instance.
+const (
+	// maxPrivilegedLifetime is the limit for privileged users.
+	maxPrivilegedLifetime = remote.MaxLifetime
+	// maxUserLifetime is the limit for other users.
+	maxUserLifetime = 8 * time.Hour
+	// maxReverseLifetime is the limit for builders on reverse hosts,
+	// which are scarce.
+	maxReverseLifetime = 4 * time.Hour
+)
+
+// maxInstanceLifetime returns the longest lifetime the user with the
+// given email may request for an instance. Builders on reverse hosts
+// have a shorter limit.
+func maxInstanceLifetime(email string, reverse bool) time.Duration {
+	max := maxUserLifetime
+	if isPrivilegedUser(email) {
+		max = maxPrivilegedLifetime
+	}
+	if reverse {
+		max = min(max, maxReverseLifetime)
+	}
+	return max
+}
+
+// adminSet returns the set of the administrators' email addresses, in
+// the form used by IAP.
+func adminSet(emails []string) map[string]bool {
+	admins := make(map[string]bool)
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email == "" {
+			continue
+		}
+		if !strings.Contains(email, ":") {
+			email = "accounts.google.com:" + email
+		}
+		admins[email] = true
+	}
+	return admins
+}
+
+// startCommand records the command requested by req as executing on its instance, so that it
+// can be listed and canceled, and sends its execution ID to the caller. The command must be
+// executed with the returned context, which is canceled by CancelCommand, and finished must
+// be called once it has exited.
+func startCommand(sp *remote.SessionPool, req *protos.ExecuteCommandRequest, stream protos.GomoteService_ExecuteCommandServer) (ctx context.Context, id string, finished func(), err error) {
+	ctx, cancel := context.WithCancel(stream.Context())
+	id, done := sp.StartCommand(req.GetGomoteId(), req.GetCommand(), req.GetArgs(), cancel)
+	finished = func() {
+		done()
+		cancel()
+	}
+	if err := stream.Send(&protos.ExecuteCommandResponse{ExecutionId: id}); err != nil {
+		finished()
+		return nil, "", nil, status.Errorf(codes.Internal, "unable to stream execution ID: %s", err)
+	}
+	return ctx, id, finished, nil
+}
+
+// listCommands lists the commands executing on the instance requested by req.
+func listCommands(sp *remote.SessionPool, req *protos.ListCommandsRequest) (*protos.ListCommandsResponse, error) {
+	cmds, err := sp.Commands(req.GetGomoteId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "specified gomote instance does not exist")
+	}
+	resp := &protos.ListCommandsResponse{}
+	for _, c := range cmds {
+		resp.Commands = append(resp.Commands, &protos.Command{
+			ExecutionId: c.ID,
+			Command:     c.Command,
+			Args:        c.Args,
+			Started:     c.Started.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+// cancelCommand cancels the command requested by req.
+func cancelCommand(sp *remote.SessionPool, req *protos.CancelCommandRequest) (*protos.CancelCommandResponse, error) {
+	if req.GetExecutionId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments")
+	}
+	err := sp.CancelCommand(req.GetGomoteId(), req.GetExecutionId())
+	if errors.Is(err, remote.ErrNoCommand) {
+		return nil, status.Errorf(codes.NotFound, "command %s is not executing on the instance", req.GetExecutionId())
+	} else if err != nil {
+		return nil, status.Errorf(codes.NotFound, "specified gomote instance does not exist")
+	}
+	log.Printf("gomote: canceled command %s on %s", req.GetExecutionId(), req.GetGomoteId())
+	return &protos.CancelCommandResponse{}, nil
+}
+
+// extendInstance extends the instance requested by req on behalf of the
+// caller with creds, who must own it or be one of admins. Extensions are
+// logged for auditing.
+func extendInstance(sp *remote.SessionPool, admins map[string]bool, creds *access.IAPFields, req *protos.ExtendInstanceRequest) (*protos.ExtendInstanceResponse, error) {
+	if req.GetGomoteId() == "" || req.GetDuration() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments")
+	}
+	ses, err := sp.Session(req.GetGomoteId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "specified gomote instance does not exist")
+	}
+	if ses.OwnerID != creds.ID && !admins[creds.Email] {
+		return nil, status.Errorf(codes.PermissionDenied, "not allowed to modify this gomote session")
+	}
+	d := time.Duration(req.GetDuration()) * time.Second
+	expires, maxExpires, clamped, err := sp.ExtendSession(req.GetGomoteId(), d)
+	if err != nil {
+		log.Printf("ExtendInstance remote.ExtendSession(%s) = %s", req.GetGomoteId(), err)
+		return nil, status.Errorf(codes.Internal, "unable to extend gomote instance")
+	}
+	log.Printf("audit: %s extended gomote instance %s of %s by %v: expires %s (max %s, clamped %t)",
+		creds.Email, req.GetGomoteId(), ses.OwnerID, d, expires.UTC().Format(time.RFC3339), maxExpires.UTC().Format(time.RFC3339), clamped)
+	return &protos.ExtendInstanceResponse{
+		Expires:    expires.Unix(),
+		MaxExpires: maxExpires.Unix(),
+		Clamped:    clamped,
+	}, nil
+}
+
+// validateLifetime returns an InvalidArgument error if the lifetime
+// requested by req, at now, is invalid or longer than max.
+func validateLifetime(req *protos.CreateInstanceRequest, now time.Time, max time.Duration) error {
+	secs, exp := req.GetLifetimeSeconds(), req.GetExpires()
+	switch {
+	case secs != 0 && exp != 0:
+		return status.Errorf(codes.InvalidArgument, "at most one of lifetime and expiration may be requested")
+	case secs < 0:
+		return status.Errorf(codes.InvalidArgument, "invalid lifetime of %d seconds", secs)
+	case secs > int64(max/time.Second):
+		return status.Errorf(codes.InvalidArgument, "requested lifetime of %d seconds exceeds the maximum of %v", secs, max)
+	case exp != 0 && !time.Unix(exp, 0).After(now):
+		return status.Errorf(codes.InvalidArgument, "requested expiration %s is in the past", time.Unix(exp, 0).UTC().Format(time.RFC3339))
+	case exp != 0 && time.Unix(exp, 0).Sub(now) > max:
+		return status.Errorf(codes.InvalidArgument, "requested expiration %s exceeds the maximum lifetime of %v", time.Unix(exp, 0).UTC().Format(time.RFC3339), max)
+	}
+	return nil
+}
+
+// instanceDeadline returns the deadline requested by req, which must be
+// valid, for an instance created at now, or the zero time if req doesn't
+// request one.
+func instanceDeadline(req *protos.CreateInstanceRequest, now time.Time) time.Time {
+	if secs := req.GetLifetimeSeconds(); secs > 0 {
+		return now.Add(time.Duration(secs) * time.Second)
+	}
+	if exp := req.GetExpires(); exp != 0 {
+		return time.Unix(exp, 0)
+	}
+	return time.Time{}
+}
+
+// Limits on the labels attached to an instance.
+const (
+	maxLabels        = 16
+	maxLabelKeyLen   = 63
+	maxLabelValueLen = 255
+)
+
+// parseLabels parses the labels requested for an instance, each of the
+// form "key=value". It returns an InvalidArgument error if they are
+// malformed or exceed the limits.
+func parseLabels(labels []string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	if len(labels) > maxLabels {
+		return nil, status.Errorf(codes.InvalidArgument, "%d labels requested; the maximum is %d", len(labels), maxLabels)
+	}
+	m := make(map[string]string, len(labels))
+	for _, l := range labels {
+		k, v, ok := strings.Cut(l, "=")
+		switch {
+		case !ok || k == "":
+			return nil, status.Errorf(codes.InvalidArgument, "invalid label %q; want key=value", l)
+		case len(k) > maxLabelKeyLen:
+			return nil, status.Errorf(codes.InvalidArgument, "label key %q is longer than the maximum of %d bytes", k, maxLabelKeyLen)
+		case len(v) > maxLabelValueLen:
+			return nil, status.Errorf(codes.InvalidArgument, "value of label %q is longer than the maximum of %d bytes", k, maxLabelValueLen)
+		}
+		if _, dup := m[k]; dup {
+			return nil, status.Errorf(codes.InvalidArgument, "duplicate label %q", k)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// instanceInfo describes the instance of the session ses.
+func instanceInfo(ses *remote.Session) types.GomoteInstanceInfo {
+	return types.GomoteInstanceInfo{
+		ID:           ses.ID,
+		BuilderType:  ses.BuilderType,
+		HostType:     ses.HostType,
+		Created:      ses.Created,
+		Expires:      ses.Expires,
+		LastActivity: ses.LastActivity,
+		Labels:       ses.Labels,
+	}
+}
+
+// protoInstance returns ii as the instance of a response. Its zero
+// timestamps are zero, rather than the Unix time of the year 1.
+func protoInstance(ii types.GomoteInstanceInfo) *protos.Instance {
+	unix := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	}
+	return &protos.Instance{
+		GomoteId:     ii.ID,
+		BuilderType:  ii.BuilderType,
+		HostType:     ii.HostType,
+		Expires:      unix(ii.Expires),
+		Created:      unix(ii.Created),
+		Labels:       formatLabels(ii.Labels),
+		LastActivity: unix(ii.LastActivity),
+	}
+}
+
+// formatLabels returns labels as "key=value" strings sorted by key.
+func formatLabels(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var ls []string
+	for _, k := range keys {
+		ls = append(ls, k+"="+labels[k])
+	}
+	return ls
+}
+
+// validateLabelSelector returns an InvalidArgument error if any of the
+// label selectors, each of the form "key=value" or "key", is malformed.
+func validateLabelSelector(selector []string) error {
+	for _, sel := range selector {
+		if k, _, _ := strings.Cut(sel, "="); k == "" {
+			return status.Errorf(codes.InvalidArgument, "invalid label selector %q; want key=value or key", sel)
+		}
+	}
+	return nil
+}
+
+// matchLabels reports whether labels satisfy all of the selectors. A
+// selector "key=value" requires the label key to have that value, and a
+// selector "key" requires the label key to be present.
+func matchLabels(labels map[string]string, selector []string) bool {
+	for _, sel := range selector {
+		k, want, hasValue := strings.Cut(sel, "=")
+		v, ok := labels[k]
+		if !ok || (hasValue && v != want) {
+			return false
+		}
+	}
+	return true
+}
+
 // iapEmailRE matches the email string returned by Identity Aware Proxy for sessions where
 // the authority is Google.
 var iapEmailRE = regexp.MustCompile(`^accounts\.google\.com:.+@.+\..+$`)
 
+// execStream returns the ExecuteCommandResponse stream of output from
+// buildlet stream s.
+func execStream(s buildlet.ExecStream) protos.ExecuteCommandResponse_Stream {
+	switch s {
+	case buildlet.ExecStdout:
+		return protos.ExecuteCommandResponse_STDOUT
+	case buildlet.ExecStderr:
+		return protos.ExecuteCommandResponse_STDERR
+	case buildlet.ExecSystem:
+		return protos.ExecuteCommandResponse_SYSTEM
+	}
+	return protos.ExecuteCommandResponse_OUTPUT
+}
+
+// exitStatus returns the exit status of a command which ran for d and
+// failed with remoteErr, or succeeded if it's nil.
+func exitStatus(remoteErr error, d time.Duration) *protos.ExecuteCommandResponse_ExitStatus {
+	st := &protos.ExecuteCommandResponse_ExitStatus{
+		State:          "ok",
+		DurationMillis: d.Milliseconds(),
+	}
+	if remoteErr != nil {
+		st.State = remoteErr.Error()
+		st.ExitCode = int32(buildlet.ExitCode(remoteErr))
+	}
+	return st
+}
+
 // emailToUser returns the displayed user for the IAP email string passed in.
 // For example, "accounts.google.com:example@gmail.com" -> "example"
 func emailToUser(email string) (string, error) {
@@ -634,3 +1240,34 @@ func objectFromURL(bucketName, url string) (string, error) {
 	objectName := strings.TrimPrefix(url, fmt.Sprintf("https://storage.googleapis.com/%s/", bucketName))
 	return objectName, nil
 }
+
+// serverVersion returns the version of the running binary, as recorded in its build information.
+func serverVersion() *protos.ServerVersionResponse {
+	resp := &protos.ServerVersionResponse{}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return resp
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		resp.Version = v
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			resp.Revision = s.Value
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, s.Value); err == nil {
+				resp.RevisionTime = t.Unix()
+			}
+		}
+	}
+	return resp
+}
+
+// recordActivity records that the instance gomoteID was just used. The
+// instance may have been destroyed meanwhile, which isn't an error.
+func recordActivity(sp *remote.SessionPool, gomoteID string) {
+	if err := sp.RecordActivity(gomoteID); err != nil {
+		log.Printf("recordActivity(%q) = %s", gomoteID, err)
+	}
+}
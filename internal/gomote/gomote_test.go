@@ -8,20 +8,26 @@ package gomote
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/build/buildlet"
 	"golang.org/x/build/internal/access"
 	"golang.org/x/build/internal/coordinator/remote"
 	"golang.org/x/build/internal/coordinator/schedule"
 	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/build/types"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/net/nettest"
 	"google.golang.org/grpc"
@@ -32,12 +38,17 @@ import (
 
 const testBucketName = "unit-testing-bucket"
 
+// testAdminEmail is the email address of the administrator of the fake
+// gomote server, as returned by fakeIAPWithUser("gomote-admin", ...).
+const testAdminEmail = "gomote-admin@gmail.com"
+
 func fakeGomoteServer(t *testing.T, ctx context.Context) protos.GomoteServiceServer {
 	signer, err := ssh.ParsePrivateKey([]byte(devCertCAPrivate))
 	if err != nil {
 		t.Fatalf("unable to parse raw certificate authority private key into signer=%s", err)
 	}
 	return &Server{
+		admins:                  adminSet([]string{testAdminEmail}),
 		bucket:                  &fakeBucketHandler{bucketName: testBucketName},
 		buildlets:               remote.NewSessionPool(ctx),
 		gceBucketName:           testBucketName,
@@ -163,6 +174,215 @@ func TestAddBootstrapError(t *testing.T) {
 	}
 }
 
+func TestInstallBootstrapError(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		ctx      context.Context
+		req      *protos.AddBootstrapRequest // GomoteId is set by the test
+		wantCode codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			req:      &protos.AddBootstrapRequest{},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:     "gomote is not owned by caller",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("user-x", "email-y")),
+			req:      &protos.AddBootstrapRequest{},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			desc:     "version and url",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			req:      &protos.AddBootstrapRequest{Version: "1.22.6", Url: "https://go.dev/dl/go1.22.6.linux-amd64.tar.gz"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "invalid version",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			req:      &protos.AddBootstrapRequest{Version: "../1.22"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "url not hosted by the Go project",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			req:      &protos.AddBootstrapRequest{Url: "https://example.com/go.tar.gz"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "invalid checksum",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			req:      &protos.AddBootstrapRequest{Version: "1.22.6", Sha256: "abc"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "headers without url",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			req:      &protos.AddBootstrapRequest{Version: "1.22.6", UrlHeaders: []*protos.HTTPHeader{{Name: "Authorization", Value: "Bearer secret"}}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "header not allowed",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			req:      &protos.AddBootstrapRequest{Url: "https://go.dev/dl/go1.22.6.linux-amd64.tar.gz", UrlHeaders: []*protos.HTTPHeader{{Name: "Cookie", Value: "secret"}}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteTest(t, context.Background())
+			tc.req.GomoteId = mustCreateInstance(t, client, fakeIAP())
+			stream, err := client.InstallBootstrap(tc.ctx, tc.req)
+			if err != nil {
+				t.Fatalf("client.InstallBootstrap(ctx, %v) = %v, %s; want no error", tc.req, stream, err)
+			}
+			for {
+				res, err := stream.Recv()
+				if err == io.EOF {
+					t.Fatalf("client.InstallBootstrap(ctx, %v) succeeded; want error", tc.req)
+				}
+				if err != nil {
+					if status.Code(err) != tc.wantCode {
+						t.Fatalf("unexpected error: %s; want %s", err, tc.wantCode)
+					}
+					break
+				}
+				if res.GetPhase() == protos.InstallBootstrapResponse_COMPLETE {
+					t.Fatalf("client.InstallBootstrap(ctx, %v) completed; want error", tc.req)
+				}
+			}
+		})
+	}
+	t.Run("checksum with AddBootstrap", func(t *testing.T) {
+		client := setupGomoteTest(t, context.Background())
+		req := &protos.AddBootstrapRequest{
+			GomoteId: mustCreateInstance(t, client, fakeIAP()),
+			Version:  "1.22.6",
+			Sha256:   strings.Repeat("0", 64),
+		}
+		ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+		if _, err := client.AddBootstrap(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("client.AddBootstrap(ctx, %v) = %v; want %s", req, err, codes.InvalidArgument)
+		}
+	})
+}
+
+func TestBootstrapSource(t *testing.T) {
+	const defaultURL = "https://go.dev/dl/go1.20.6.linux-amd64.tar.gz"
+	for _, tc := range []struct {
+		req  *protos.AddBootstrapRequest
+		want string
+	}{
+		{&protos.AddBootstrapRequest{}, defaultURL},
+		{&protos.AddBootstrapRequest{Version: "1.22.6"}, "https://storage.googleapis.com/go-builder-data/gobootstrap-linux-amd64-go1.22.6.tar.gz"},
+		{&protos.AddBootstrapRequest{Version: "go1.22.6"}, "https://storage.googleapis.com/go-builder-data/gobootstrap-linux-amd64-go1.22.6.tar.gz"},
+		{&protos.AddBootstrapRequest{Url: "https://dl.google.com/go/go1.22.6.linux-amd64.tar.gz"}, "https://dl.google.com/go/go1.22.6.linux-amd64.tar.gz"},
+	} {
+		if err := validateBootstrapRequest(tc.req); err != nil {
+			t.Errorf("validateBootstrapRequest(%v) = %v; want nil", tc.req, err)
+		}
+		if got := bootstrapSource(tc.req, "linux", "amd64", defaultURL); got != tc.want {
+			t.Errorf("bootstrapSource(%v) = %q; want %q", tc.req, got, tc.want)
+		}
+	}
+}
+
+func TestURLHeader(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		headers []*protos.HTTPHeader
+		want    http.Header
+		wantErr bool
+	}{
+		{"http://example.com/go.tar.gz", nil, nil, false},
+		{"https://example.com/go.tar.gz", []*protos.HTTPHeader{{Name: "authorization", Value: "Bearer secret"}, {Name: "X-JFrog-Art-Api", Value: "key"}}, http.Header{"Authorization": {"Bearer secret"}, "X-Jfrog-Art-Api": {"key"}}, false},
+		{"http://example.com/go.tar.gz", []*protos.HTTPHeader{{Name: "Authorization", Value: "Bearer secret"}}, nil, true},
+		{"https://example.com/go.tar.gz", []*protos.HTTPHeader{{Name: "Cookie", Value: "secret"}}, nil, true},
+		{"https://example.com/go.tar.gz", []*protos.HTTPHeader{{Name: "Authorization", Value: "Bearer secret\r\nX-Evil: 1"}}, nil, true},
+	} {
+		got, err := urlHeader(tc.url, tc.headers)
+		if (err != nil) != tc.wantErr || !cmp.Equal(got, tc.want) {
+			t.Errorf("urlHeader(%q, %v) = %v, %v; want %v, error %t", tc.url, tc.headers, got, err, tc.want, tc.wantErr)
+		}
+		if err != nil && strings.Contains(err.Error(), "secret") {
+			t.Errorf("urlHeader(%q, %v) = %v; the error contains the header value", tc.url, tc.headers, err)
+		}
+	}
+	h := http.Header{"Authorization": {"Bearer secret"}, "Private-Token": {"token"}}
+	if got, want := redactHeader(h), "[Authorization: REDACTED, Private-Token: REDACTED]"; got != want {
+		t.Errorf("redactHeader(%v) = %q; want %q", h, got, want)
+	}
+}
+
+// putTarClient is a buildlet client which records the archive written to
+// it by PutTar.
+type putTarClient struct {
+	buildlet.Client
+	dir  string
+	data []byte
+}
+
+func (c *putTarClient) PutTar(ctx context.Context, r io.Reader, dir string, opts ...buildlet.TarOpts) error {
+	data, err := io.ReadAll(r)
+	c.dir, c.data = dir, data
+	return err
+}
+
+func TestInstallBootstrapProgress(t *testing.T) {
+	archive := []byte(strings.Repeat("not really a tar.gz archive\n", 1000))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer ts.Close()
+	sum := sha256.Sum256(archive)
+
+	bc := new(putTarClient)
+	var updates []*protos.InstallBootstrapResponse
+	send := func(res *protos.InstallBootstrapResponse) error {
+		updates = append(updates, res)
+		return nil
+	}
+	if err := installBootstrap(context.Background(), bc, ts.URL, nil, hex.EncodeToString(sum[:]), "go1.4", send); err != nil {
+		t.Fatalf("installBootstrap = %v; want no error", err)
+	}
+	if bc.dir != "go1.4" || string(bc.data) != string(archive) {
+		t.Errorf("PutTar got %d bytes in %q; want %d bytes in %q", len(bc.data), bc.dir, len(archive), "go1.4")
+	}
+	var phases []protos.InstallBootstrapResponse_Phase
+	for _, u := range updates {
+		if n := len(phases); n == 0 || phases[n-1] != u.GetPhase() {
+			phases = append(phases, u.GetPhase())
+		}
+	}
+	wantPhases := []protos.InstallBootstrapResponse_Phase{
+		protos.InstallBootstrapResponse_DOWNLOADING,
+		protos.InstallBootstrapResponse_EXTRACTING,
+		protos.InstallBootstrapResponse_COMPLETE,
+	}
+	if diff := cmp.Diff(wantPhases, phases); diff != "" {
+		t.Errorf("phases mismatch (-want +got):\n%s", diff)
+	}
+	last := updates[len(updates)-1]
+	if last.GetBootstrapGoUrl() != ts.URL || last.GetDirectory() != "go1.4" || last.GetBytesDone() != int64(len(archive)) {
+		t.Errorf("last update = %v; want completion of %d bytes from %s in go1.4", last, len(archive), ts.URL)
+	}
+
+	err := installBootstrap(context.Background(), new(putTarClient), ts.URL, nil, strings.Repeat("0", 64), "go1.4", send)
+	if status.Code(err) != codes.DataLoss {
+		t.Errorf("installBootstrap with the wrong checksum = %v; want %s", err, codes.DataLoss)
+	}
+
+	updates = nil
+	if err := installBootstrap(context.Background(), new(putTarClient), "", nil, "", "go1.4", send); err != nil {
+		t.Fatalf("installBootstrap without a bootstrap version = %v; want no error", err)
+	}
+	if len(updates) != 1 || updates[0].GetPhase() != protos.InstallBootstrapResponse_COMPLETE || updates[0].GetBootstrapGoUrl() != "" {
+		t.Errorf("installBootstrap without a bootstrap version sent %v; want only completion", updates)
+	}
+}
+
 func TestCreateInstance(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	req := &protos.CreateInstanceRequest{BuilderType: "linux-amd64"}
@@ -189,6 +409,168 @@ func TestCreateInstance(t *testing.T) {
 	}
 }
 
+func TestCreateInstanceLifetime(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	client := setupGomoteTest(t, context.Background())
+	start := time.Now()
+	inst := mustCreateInstanceFrom(t, client, fakeIAP(), &protos.CreateInstanceRequest{BuilderType: "linux-amd64", LifetimeSeconds: 10 * 60})
+	wantMin, wantMax := start.Add(10*time.Minute).Unix(), time.Now().Add(10*time.Minute).Unix()
+	if got := inst.GetExpires(); got < wantMin || got > wantMax {
+		t.Errorf("instance expires at %d; want between %d and %d", got, wantMin, wantMax)
+	}
+	list, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+	if err != nil {
+		t.Fatalf("client.ListInstances = nil, %s; want no error", err)
+	}
+	if len(list.GetInstances()) != 1 || list.GetInstances()[0].GetExpires() != inst.GetExpires() {
+		t.Errorf("ListInstances = %v; want one instance expiring at %d", list.GetInstances(), inst.GetExpires())
+	}
+}
+
+func TestCreateInstanceLabels(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	client := setupGomoteTest(t, context.Background())
+	inst := mustCreateInstanceFrom(t, client, fakeIAP(), &protos.CreateInstanceRequest{
+		BuilderType: "linux-amd64",
+		Labels:      []string{"pipeline=nightly", "cl=12345"},
+	})
+	wantLabels := []string{"cl=12345", "pipeline=nightly"}
+	if diff := cmp.Diff(wantLabels, inst.GetLabels()); diff != "" {
+		t.Errorf("created instance labels mismatch (-want, +got):\n%s", diff)
+	}
+	unlabeled := mustCreateInstance(t, client, fakeIAP())
+
+	for _, tc := range []struct {
+		selector []string
+		want     []string
+	}{
+		{nil, []string{inst.GetGomoteId(), unlabeled}},
+		{[]string{"cl=12345"}, []string{inst.GetGomoteId()}},
+		{[]string{"cl"}, []string{inst.GetGomoteId()}},
+		{[]string{"cl=12345", "pipeline=nightly"}, []string{inst.GetGomoteId()}},
+		{[]string{"cl=1"}, nil},
+		{[]string{"cl=12345", "owner"}, nil},
+	} {
+		res, err := client.ListInstances(ctx, &protos.ListInstancesRequest{LabelSelector: tc.selector})
+		if err != nil {
+			t.Fatalf("client.ListInstances(selector %q) = nil, %s; want no error", tc.selector, err)
+		}
+		var got []string
+		for _, i := range res.GetInstances() {
+			got = append(got, i.GetGomoteId())
+			if i.GetGomoteId() == inst.GetGomoteId() {
+				if diff := cmp.Diff(wantLabels, i.GetLabels()); diff != "" {
+					t.Errorf("listed instance labels mismatch (-want, +got):\n%s", diff)
+				}
+			}
+		}
+		sort.Strings(got)
+		sort.Strings(tc.want)
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ListInstances(selector %q) mismatch (-want, +got):\n%s", tc.selector, diff)
+		}
+	}
+
+	if _, err := client.ListInstances(ctx, &protos.ListInstancesRequest{LabelSelector: []string{"=x"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListInstances with an invalid selector = %v; want InvalidArgument", err)
+	}
+	res, err := client.InstanceStatus(ctx, &protos.InstanceStatusRequest{GomoteId: inst.GetGomoteId()})
+	if err != nil {
+		t.Fatalf("client.InstanceStatus = nil, %s; want no error", err)
+	}
+	if diff := cmp.Diff(wantLabels, res.GetInstance().GetLabels()); diff != "" {
+		t.Errorf("instance status labels mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	var tooMany []string
+	for i := 0; i <= maxLabels; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("k%d=v", i))
+	}
+	for _, tc := range []struct {
+		desc    string
+		labels  []string
+		want    map[string]string
+		wantErr string
+	}{
+		{desc: "none"},
+		{desc: "labels", labels: []string{"cl=12345", "bot="}, want: map[string]string{"cl": "12345", "bot": ""}},
+		{desc: "value with equals", labels: []string{"env=A=B"}, want: map[string]string{"env": "A=B"}},
+		{desc: "missing value", labels: []string{"cl"}, wantErr: "want key=value"},
+		{desc: "empty key", labels: []string{"=x"}, wantErr: "want key=value"},
+		{desc: "duplicate key", labels: []string{"cl=1", "cl=2"}, wantErr: "duplicate"},
+		{desc: "long key", labels: []string{strings.Repeat("k", maxLabelKeyLen+1) + "=v"}, wantErr: "maximum of 63 bytes"},
+		{desc: "long value", labels: []string{"k=" + strings.Repeat("v", maxLabelValueLen+1)}, wantErr: "maximum of 255 bytes"},
+		{desc: "too many", labels: tooMany, wantErr: "the maximum is 16"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := parseLabels(tc.labels)
+			if tc.wantErr != "" {
+				if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("parseLabels(%q) = %v; want InvalidArgument error containing %q", tc.labels, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLabels(%q) = %v; want no error", tc.labels, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parseLabels(%q) mismatch (-want, +got):\n%s", tc.labels, diff)
+			}
+		})
+	}
+}
+
+func TestValidateLifetime(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		desc    string
+		req     *protos.CreateInstanceRequest
+		max     time.Duration
+		wantErr string
+	}{
+		{"none", &protos.CreateInstanceRequest{}, time.Hour, ""},
+		{"lifetime", &protos.CreateInstanceRequest{LifetimeSeconds: 3600}, time.Hour, ""},
+		{"lifetime too long", &protos.CreateInstanceRequest{LifetimeSeconds: 3601}, time.Hour, "maximum of 1h0m0s"},
+		{"negative lifetime", &protos.CreateInstanceRequest{LifetimeSeconds: -1}, time.Hour, "invalid lifetime"},
+		{"expiration", &protos.CreateInstanceRequest{Expires: now.Add(time.Hour).Unix()}, time.Hour, ""},
+		{"expiration too late", &protos.CreateInstanceRequest{Expires: now.Add(2 * time.Hour).Unix()}, time.Hour, "maximum lifetime of 1h0m0s"},
+		{"expiration in the past", &protos.CreateInstanceRequest{Expires: now.Add(-time.Minute).Unix()}, time.Hour, "in the past"},
+		{"both", &protos.CreateInstanceRequest{LifetimeSeconds: 60, Expires: now.Add(time.Minute).Unix()}, time.Hour, "at most one"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validateLifetime(tc.req, now, tc.max)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateLifetime(%v) = %v; want no error", tc.req, err)
+				}
+				return
+			}
+			if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("validateLifetime(%v) = %v; want InvalidArgument error containing %q", tc.req, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxInstanceLifetime(t *testing.T) {
+	for _, tc := range []struct {
+		email   string
+		reverse bool
+		want    time.Duration
+	}{
+		{"accounts.google.com:example@gmail.com", false, maxUserLifetime},
+		{"accounts.google.com:example@gmail.com", true, maxReverseLifetime},
+		{"accounts.google.com:example@google.com", false, maxPrivilegedLifetime},
+		{"accounts.google.com:example@google.com", true, maxReverseLifetime},
+	} {
+		if got := maxInstanceLifetime(tc.email, tc.reverse); got != tc.want {
+			t.Errorf("maxInstanceLifetime(%q, %t) = %v; want %v", tc.email, tc.reverse, got, tc.want)
+		}
+	}
+}
+
 func TestCreateInstanceError(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -216,6 +598,24 @@ func TestCreateInstanceError(t *testing.T) {
 			},
 			wantCode: codes.InvalidArgument,
 		},
+		{
+			desc: "lifetime too long",
+			ctx:  access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			request: &protos.CreateInstanceRequest{
+				BuilderType:     "linux-amd64",
+				LifetimeSeconds: int64(maxUserLifetime/time.Second) + 1,
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc: "invalid label",
+			ctx:  access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			request: &protos.CreateInstanceRequest{
+				BuilderType: "linux-amd64",
+				Labels:      []string{"no-value"},
+			},
+			wantCode: codes.InvalidArgument,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -239,6 +639,77 @@ func TestCreateInstanceError(t *testing.T) {
 	}
 }
 
+func TestGroups(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	otherCtx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("user-x", "uuid-user-x"))
+	client := setupGomoteTest(t, context.Background())
+	a := mustCreateInstance(t, client, fakeIAP())
+	b := mustCreateInstance(t, client, fakeIAP())
+	other := mustCreateInstance(t, client, fakeIAPWithUser("user-x", "uuid-user-x"))
+
+	created, err := client.CreateGroup(ctx, &protos.CreateGroupRequest{Name: "tests", GomoteIds: []string{a}})
+	if err != nil {
+		t.Fatalf("client.CreateGroup = nil, %s; want no error", err)
+	}
+	want := &protos.Group{Name: "tests", Owner: "example", GomoteIds: []string{a}, Owned: true}
+	if diff := cmp.Diff(want, created.GetGroup(), protocmp.Transform()); diff != "" {
+		t.Errorf("created group mismatch (-want, +got):\n%s", diff)
+	}
+	if _, err := client.CreateGroup(ctx, &protos.CreateGroupRequest{Name: "tests"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("client.CreateGroup of an existing group = %v; want AlreadyExists", err)
+	}
+	if _, err := client.CreateGroup(ctx, &protos.CreateGroupRequest{Name: "a/b"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("client.CreateGroup with an invalid name = %v; want InvalidArgument", err)
+	}
+
+	// Adding an instance owned by another user fails.
+	if _, err := client.ModifyGroup(ctx, &protos.ModifyGroupRequest{Name: "tests", AddGomoteIds: []string{b, other}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("client.ModifyGroup adding another user's instance = %v; want PermissionDenied", err)
+	}
+	if _, err := client.ModifyGroup(otherCtx, &protos.ModifyGroupRequest{Name: "tests", AddGomoteIds: []string{other}}); status.Code(err) != codes.NotFound {
+		t.Errorf("client.ModifyGroup of another user's group = %v; want NotFound", err)
+	}
+	modified, err := client.ModifyGroup(ctx, &protos.ModifyGroupRequest{
+		Name:         "tests",
+		AddGomoteIds: []string{b},
+		Sharing:      protos.ModifyGroupRequest_SHARED,
+	})
+	if err != nil {
+		t.Fatalf("client.ModifyGroup = nil, %s; want no error", err)
+	}
+	want = &protos.Group{Name: "tests", Owner: "example", Shared: true, GomoteIds: []string{a, b}, Owned: true}
+	if diff := cmp.Diff(want, modified.GetGroup(), protocmp.Transform()); diff != "" {
+		t.Errorf("modified group mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Destroying an instance removes it from the group, and other users
+	// can read the shared group.
+	if _, err := client.DestroyInstance(ctx, &protos.DestroyInstanceRequest{GomoteId: a}); err != nil {
+		t.Fatalf("client.DestroyInstance = nil, %s; want no error", err)
+	}
+	listed, err := client.ListGroups(otherCtx, &protos.ListGroupsRequest{IncludeShared: true})
+	if err != nil {
+		t.Fatalf("client.ListGroups = nil, %s; want no error", err)
+	}
+	wantList := []*protos.Group{{Name: "tests", Owner: "example", Shared: true, GomoteIds: []string{b}}}
+	if diff := cmp.Diff(wantList, listed.GetGroups(), protocmp.Transform()); diff != "" {
+		t.Errorf("listed groups mismatch (-want, +got):\n%s", diff)
+	}
+	if listed, err := client.ListGroups(otherCtx, &protos.ListGroupsRequest{}); err != nil || len(listed.GetGroups()) != 0 {
+		t.Errorf("client.ListGroups without shared groups = %v, %v; want no groups", listed, err)
+	}
+
+	if _, err := client.DeleteGroup(ctx, &protos.DeleteGroupRequest{Name: "tests"}); err != nil {
+		t.Fatalf("client.DeleteGroup = nil, %s; want no error", err)
+	}
+	if _, err := client.DeleteGroup(ctx, &protos.DeleteGroupRequest{Name: "tests"}); status.Code(err) != codes.NotFound {
+		t.Errorf("client.DeleteGroup of a deleted group = %v; want NotFound", err)
+	}
+	if _, err := client.ListGroups(context.Background(), &protos.ListGroupsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("client.ListGroups without authentication = %v; want Unauthenticated", err)
+	}
+}
+
 func TestInstanceAlive(t *testing.T) {
 	client := setupGomoteTest(t, context.Background())
 	gomoteID := mustCreateInstance(t, client, fakeIAP())
@@ -252,6 +723,23 @@ func TestInstanceAlive(t *testing.T) {
 	}
 }
 
+func TestInstanceAlivePingBuildlet(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	req := &protos.InstanceAliveRequest{
+		GomoteId:     gomoteID,
+		PingBuildlet: true,
+	}
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	got, err := client.InstanceAlive(ctx, req)
+	if err != nil {
+		t.Fatalf("client.InstanceAlive(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	if !got.GetClockReported() {
+		t.Errorf("client.InstanceAlive(ctx, %v) = %v; want the buildlet's clock reported", req, got)
+	}
+}
+
 func TestInstanceAliveError(t *testing.T) {
 	// This test will create a gomote instance and attempt to call InstanceAlive.
 	// If overrideID is set to true, the test will use a different gomoteID than
@@ -294,15 +782,118 @@ func TestInstanceAliveError(t *testing.T) {
 			if tc.overrideID {
 				gomoteID = tc.gomoteID
 			}
-			req := &protos.InstanceAliveRequest{
+			req := &protos.InstanceAliveRequest{
+				GomoteId: gomoteID,
+			}
+			got, err := client.InstanceAlive(tc.ctx, req)
+			if err != nil && status.Code(err) != tc.wantCode {
+				t.Fatalf("unexpected error: %s; want %s", err, tc.wantCode)
+			}
+			if err == nil {
+				t.Fatalf("client.InstanceAlive(ctx, %v) = %v, nil; want error", req, got)
+			}
+		})
+	}
+}
+
+func TestInstanceStatus(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	req := &protos.InstanceStatusRequest{
+		GomoteId: gomoteID,
+	}
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	got, err := client.InstanceStatus(ctx, req)
+	if err != nil {
+		t.Fatalf("client.InstanceStatus(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	want := &protos.InstanceStatusResponse{
+		Instance: &protos.Instance{
+			GomoteId:    gomoteID,
+			BuilderType: "linux-amd64",
+			WorkingDir:  "/work",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "created", "expires", "host_type", "last_activity"), protocmp.IgnoreFields(&protos.InstanceStatusResponse{}, "reachable", "buildlet_version")); diff != "" {
+		t.Errorf("InstanceStatus() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestInstanceStatusSkipBuildlet(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	req := &protos.InstanceStatusRequest{
+		GomoteId:     gomoteID,
+		SkipBuildlet: true,
+	}
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	got, err := client.InstanceStatus(ctx, req)
+	if err != nil {
+		t.Fatalf("client.InstanceStatus(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	// Without contacting the buildlet, the working directory is unknown.
+	want := &protos.InstanceStatusResponse{
+		Instance: &protos.Instance{
+			GomoteId:    gomoteID,
+			BuilderType: "linux-amd64",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "created", "expires", "host_type", "last_activity")); diff != "" {
+		t.Errorf("InstanceStatus() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestInstanceStatusError(t *testing.T) {
+	// This test will create a gomote instance and attempt to call InstanceStatus.
+	// If overrideID is set to true, the test will use a different gomoteID than
+	// the one created for the test.
+	testCases := []struct {
+		desc       string
+		ctx        context.Context
+		overrideID bool
+		gomoteID   string // Used iff overrideID is true.
+		wantCode   codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:       "missing gomote id",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			desc:       "gomote does not exist",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			gomoteID:   "xyz",
+			wantCode:   codes.NotFound,
+		},
+		{
+			desc:     "gomote is not owned by caller",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("user-x", "email-y")),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteTest(t, context.Background())
+			gomoteID := mustCreateInstance(t, client, fakeIAP())
+			if tc.overrideID {
+				gomoteID = tc.gomoteID
+			}
+			req := &protos.InstanceStatusRequest{
 				GomoteId: gomoteID,
 			}
-			got, err := client.InstanceAlive(tc.ctx, req)
+			got, err := client.InstanceStatus(tc.ctx, req)
 			if err != nil && status.Code(err) != tc.wantCode {
 				t.Fatalf("unexpected error: %s; want %s", err, tc.wantCode)
 			}
 			if err == nil {
-				t.Fatalf("client.InstanceAlive(ctx, %v) = %v, nil; want error", req, got)
+				t.Fatalf("client.InstanceStatus(ctx, %v) = %v, nil; want error", req, got)
 			}
 		})
 	}
@@ -409,11 +1000,92 @@ func TestListInstance(t *testing.T) {
 		t.Fatalf("client.ListInstances = nil, %s; want no error", err)
 	}
 	got := response.GetInstances()
-	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "expires", "host_type")); diff != "" {
+	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "created", "expires", "host_type", "last_activity")); diff != "" {
 		t.Errorf("ListInstances() mismatch (-want, +got):\n%s", diff)
 	}
 }
 
+func TestListInstanceLastActivity(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Go is an open source programming language")
+	}))
+	defer ts.Close()
+	uses := []struct {
+		desc string
+		use  func(gomoteID string) error
+	}{
+		{"unused", func(gomoteID string) error {
+			// Looking at an instance doesn't use it.
+			if _, err := client.InstanceStatus(ctx, &protos.InstanceStatusRequest{GomoteId: gomoteID}); err != nil {
+				return err
+			}
+			_, err := client.InstanceAlive(ctx, &protos.InstanceAliveRequest{GomoteId: gomoteID})
+			return err
+		}},
+		{"command", func(gomoteID string) error {
+			stream, err := client.ExecuteCommand(ctx, &protos.ExecuteCommandRequest{GomoteId: gomoteID, Command: "ls"})
+			if err != nil {
+				return err
+			}
+			for {
+				if _, err := stream.Recv(); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+			}
+		}},
+		{"file written", func(gomoteID string) error {
+			_, err := client.WriteFileFromURL(ctx, &protos.WriteFileFromURLRequest{GomoteId: gomoteID, Url: ts.URL, Filename: "foo"})
+			return err
+		}},
+		{"tar.gz written", func(gomoteID string) error {
+			_, err := client.WriteTGZFromURL(ctx, &protos.WriteTGZFromURLRequest{GomoteId: gomoteID, Url: ts.URL, Directory: "foo"})
+			return err
+		}},
+		{"files removed", func(gomoteID string) error {
+			_, err := client.RemoveFiles(ctx, &protos.RemoveFilesRequest{GomoteId: gomoteID, Paths: []string{"foo"}})
+			return err
+		}},
+	}
+	ids := make([]string, len(uses))
+	for i := range uses {
+		ids[i] = mustCreateInstance(t, client, fakeIAP())
+	}
+	// Timestamps have a resolution of a second, so use the instances in
+	// the second after they were created.
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	start := time.Now().Unix()
+	for i, u := range uses {
+		if err := u.use(ids[i]); err != nil {
+			t.Fatalf("%s: %s; want no error", u.desc, err)
+		}
+	}
+	response, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+	if err != nil {
+		t.Fatalf("client.ListInstances = nil, %s; want no error", err)
+	}
+	got := make(map[string]*protos.Instance)
+	for _, inst := range response.GetInstances() {
+		got[inst.GetGomoteId()] = inst
+	}
+	for i, u := range uses {
+		inst := got[ids[i]]
+		if inst.GetBuilderType() != "linux-amd64" || inst.GetCreated() == 0 || inst.GetExpires() <= inst.GetCreated() {
+			t.Errorf("%s: ListInstances() = %v; want builder type, creation and expiry", u.desc, inst)
+		}
+		if u.desc == "unused" {
+			if inst.GetLastActivity() != inst.GetCreated() {
+				t.Errorf("%s: Instance.LastActivity = %d; want the creation time %d", u.desc, inst.GetLastActivity(), inst.GetCreated())
+			}
+		} else if inst.GetLastActivity() < start {
+			t.Errorf("%s: Instance.LastActivity = %d; want at least %d", u.desc, inst.GetLastActivity(), start)
+		}
+	}
+}
+
 func TestDestroyInstance(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	client := setupGomoteTest(t, context.Background())
@@ -501,7 +1173,9 @@ func TestExecuteCommand(t *testing.T) {
 		t.Fatalf("client.ExecuteCommand(ctx, req) = response, %s; want no error", err)
 	}
 	var out []byte
-	for {
+	var exit *protos.ExecuteCommandResponse_ExitStatus
+	var execID string
+	for first := true; ; first = false {
 		res, err := stream.Recv()
 		if err != nil && err == io.EOF {
 			break
@@ -509,11 +1183,64 @@ func TestExecuteCommand(t *testing.T) {
 		if err != nil {
 			t.Fatalf("stream.Recv() = _, %s; want no error", err)
 		}
+		if exit != nil {
+			t.Fatalf("stream.Recv() = %v after the exit status; want EOF", res)
+		}
+		if (res.GetExecutionId() != "") != first {
+			t.Errorf("stream.Recv() = %v; want the execution ID in the first response only", res)
+		}
+		if first {
+			execID = res.GetExecutionId()
+		}
+		if len(res.GetOutput()) > 0 && res.GetStream() != protos.ExecuteCommandResponse_STDOUT {
+			t.Errorf("output stream = %v; want %v", res.GetStream(), protos.ExecuteCommandResponse_STDOUT)
+		}
 		out = append(out, res.GetOutput()...)
+		exit = res.GetExitStatus()
 	}
 	if len(out) == 0 {
 		t.Fatalf("output: %q, expected non-empty", out)
 	}
+	if exit.GetState() != "ok" || exit.GetExitCode() != 0 {
+		t.Errorf("exit status = %v; want ok", exit)
+	}
+	// The command has finished, so it can no longer be listed or canceled.
+	if res, err := client.ListCommands(ctx, &protos.ListCommandsRequest{GomoteId: gomoteID}); err != nil || len(res.GetCommands()) != 0 {
+		t.Errorf("client.ListCommands(ctx, %s) = %v, %v; want no commands", gomoteID, res, err)
+	}
+	if _, err := client.CancelCommand(ctx, &protos.CancelCommandRequest{GomoteId: gomoteID, ExecutionId: execID}); status.Code(err) != codes.NotFound {
+		t.Errorf("client.CancelCommand(ctx, %s, %q) = %v; want NotFound", gomoteID, execID, err)
+	}
+}
+
+func TestCommandsError(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	testCases := []struct {
+		desc     string
+		ctx      context.Context
+		gomoteID string
+		execID   string
+		wantCode codes.Code
+	}{
+		{"unauthenticated request", context.Background(), gomoteID, "1", codes.Unauthenticated},
+		{"gomote does not exist", access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()), "chucky", "1", codes.NotFound},
+		{"wrong owner", access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("foo", "bar")), gomoteID, "1", codes.PermissionDenied},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := client.ListCommands(tc.ctx, &protos.ListCommandsRequest{GomoteId: tc.gomoteID}); status.Code(err) != tc.wantCode {
+				t.Errorf("client.ListCommands(ctx, %s) = %v; want %s", tc.gomoteID, err, tc.wantCode)
+			}
+			if _, err := client.CancelCommand(tc.ctx, &protos.CancelCommandRequest{GomoteId: tc.gomoteID, ExecutionId: tc.execID}); status.Code(err) != tc.wantCode {
+				t.Errorf("client.CancelCommand(ctx, %s, %q) = %v; want %s", tc.gomoteID, tc.execID, err, tc.wantCode)
+			}
+		})
+	}
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	if _, err := client.CancelCommand(ctx, &protos.CancelCommandRequest{GomoteId: gomoteID}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("client.CancelCommand(ctx, %s, \"\") = %v; want %s", gomoteID, err, codes.InvalidArgument)
+	}
 }
 
 func TestExecuteCommandError(t *testing.T) {
@@ -582,6 +1309,10 @@ func TestExecuteCommandError(t *testing.T) {
 				t.Fatalf("unexpected error: %s", err)
 			}
 			res, err := stream.Recv()
+			if err == nil && res.GetExecutionId() != "" {
+				// Skip the execution ID, sent once the command has started.
+				res, err = stream.Recv()
+			}
 			if err != nil && status.Code(err) != tc.wantCode {
 				t.Fatalf("unexpected error: %s", err)
 			}
@@ -592,6 +1323,155 @@ func TestExecuteCommandError(t *testing.T) {
 	}
 }
 
+func TestExitStatus(t *testing.T) {
+	st := exitStatus(nil, 1500*time.Millisecond)
+	if st.GetState() != "ok" || st.GetExitCode() != 0 || st.GetDurationMillis() != 1500 {
+		t.Errorf("exitStatus(nil, 1.5s) = %v; want ok after 1500ms", st)
+	}
+	st = exitStatus(&buildlet.ExecError{State: "exit status 3", ExitCode: 3}, time.Second)
+	if st.GetState() != "exit status 3" || st.GetExitCode() != 3 {
+		t.Errorf("exitStatus(exit status 3) = %v; want exit code 3", st)
+	}
+	st = exitStatus(errors.New("signal: killed"), time.Second)
+	if st.GetState() != "signal: killed" || st.GetExitCode() != -1 {
+		t.Errorf("exitStatus(signal: killed) = %v; want exit code -1", st)
+	}
+}
+
+func TestExtendInstance(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	before := time.Now()
+	req := &protos.ExtendInstanceRequest{
+		GomoteId: gomoteID,
+		Duration: int64(time.Hour / time.Second),
+	}
+	got, err := client.ExtendInstance(ctx, req)
+	if err != nil {
+		t.Fatalf("client.ExtendInstance(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	if exp := time.Unix(got.GetExpires(), 0); exp.Before(before.Add(time.Hour)) {
+		t.Errorf("client.ExtendInstance(ctx, %v) expires = %s; want >= %s", req, exp, before.Add(time.Hour))
+	}
+	if got.GetClamped() {
+		t.Errorf("client.ExtendInstance(ctx, %v) clamped = true; want false", req)
+	}
+	if got.GetMaxExpires() < got.GetExpires() {
+		t.Errorf("client.ExtendInstance(ctx, %v) max_expires = %d; want >= %d", req, got.GetMaxExpires(), got.GetExpires())
+	}
+}
+
+func TestExtendInstanceLimits(t *testing.T) {
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	extend := func(ctx context.Context, d time.Duration) *protos.ExtendInstanceResponse {
+		t.Helper()
+		req := &protos.ExtendInstanceRequest{GomoteId: gomoteID, Duration: int64(d / time.Second)}
+		got, err := client.ExtendInstance(ctx, req)
+		if err != nil {
+			t.Fatalf("client.ExtendInstance(ctx, %v) = %v, %s; want no error", req, got, err)
+		}
+		return got
+	}
+	listed := func() *protos.Instance {
+		t.Helper()
+		res, err := client.ListInstances(ctx, &protos.ListInstancesRequest{})
+		if err != nil || len(res.GetInstances()) != 1 {
+			t.Fatalf("client.ListInstances = %v, %v; want 1 instance", res, err)
+		}
+		return res.GetInstances()[0]
+	}
+
+	first := extend(ctx, time.Hour)
+	if got := listed().GetExpires(); got != first.GetExpires() {
+		t.Errorf("ListInstances() expires = %d after extension; want %d", got, first.GetExpires())
+	}
+	// Retrying the extension doesn't extend the instance twice.
+	if got := extend(ctx, time.Hour); got.GetExpires() != first.GetExpires() {
+		t.Errorf("retried ExtendInstance expires = %d; want %d", got.GetExpires(), first.GetExpires())
+	}
+	// Users who aren't privileged are limited to maxUserLifetime.
+	got := extend(ctx, remote.MaxLifetime)
+	if want := listed().GetCreated() + int64(maxUserLifetime/time.Second); got.GetMaxExpires() != want || got.GetExpires() != want || !got.GetClamped() {
+		t.Errorf("ExtendInstance past the limit = %v; want clamped to max_expires %d", got, want)
+	}
+	if listed().GetExpires() != got.GetExpires() {
+		t.Errorf("ListInstances() expires = %d after extension; want %d", listed().GetExpires(), got.GetExpires())
+	}
+
+	// Administrators may extend instances they don't own.
+	adminCtx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("gomote-admin", "admin-id"))
+	if got := extend(adminCtx, 2*time.Hour); got.GetMaxExpires() != got.GetExpires() {
+		t.Errorf("administrator's ExtendInstance = %v; want the owner's limits", got)
+	}
+}
+
+func TestExtendInstanceError(t *testing.T) {
+	// This test will create a gomote instance and attempt to call ExtendInstance.
+	// If overrideID is set to true, the test will use a different gomoteID than
+	// the one created for the test.
+	testCases := []struct {
+		desc       string
+		ctx        context.Context
+		overrideID bool
+		gomoteID   string // Used iff overrideID is true.
+		duration   int64
+		wantCode   codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:       "missing gomote id",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			desc:     "negative duration",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			duration: -1,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:       "gomote does not exist",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			gomoteID:   "xyz",
+			wantCode:   codes.NotFound,
+		},
+		{
+			desc:     "gomote is not owned by caller",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("user-x", "email-y")),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteTest(t, context.Background())
+			gomoteID := mustCreateInstance(t, client, fakeIAP())
+			if tc.overrideID {
+				gomoteID = tc.gomoteID
+			}
+			req := &protos.ExtendInstanceRequest{
+				GomoteId: gomoteID,
+				Duration: tc.duration,
+			}
+			got, err := client.ExtendInstance(tc.ctx, req)
+			if err != nil && status.Code(err) != tc.wantCode {
+				t.Fatalf("unexpected error: %s; want %s", err, tc.wantCode)
+			}
+			if err == nil {
+				t.Fatalf("client.ExtendInstance(ctx, %v) = %v, nil; want error", req, got)
+			}
+		})
+	}
+}
+
 func TestReadTGZToURLError(t *testing.T) {
 	// This test will create a gomote instance and attempt to call ReadTGZToURL.
 	// If overrideID is set to true, the test will use a different gomoteID than
@@ -732,6 +1612,24 @@ func TestRemoveFilesError(t *testing.T) {
 	}
 }
 
+func TestServerVersion(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	client := setupGomoteTest(t, context.Background())
+	got, err := client.ServerVersion(ctx, &protos.ServerVersionRequest{})
+	if err != nil {
+		t.Fatalf("client.ServerVersion(ctx, request) = %v, %s; want no error", got, err)
+	}
+}
+
+func TestServerVersionError(t *testing.T) {
+	wantCode := codes.Unauthenticated
+	client := setupGomoteTest(t, context.Background())
+	_, err := client.ServerVersion(context.Background(), &protos.ServerVersionRequest{})
+	if status.Code(err) != wantCode {
+		t.Fatalf("client.ServerVersion(ctx, request) = _, %s; want %s", status.Code(err), wantCode)
+	}
+}
+
 func TestSignSSHKey(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	client := setupGomoteTest(t, context.Background())
@@ -811,6 +1709,109 @@ func TestSignSSHKeyError(t *testing.T) {
 	}
 }
 
+func TestTailFile(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	client := setupGomoteTest(t, context.Background())
+	gomoteID := mustCreateInstance(t, client, fakeIAP())
+	stream, err := client.TailFile(ctx, &protos.TailFileRequest{
+		GomoteId: gomoteID,
+		Path:     "go/test.log",
+		Lines:    10,
+	})
+	if err != nil {
+		t.Fatalf("client.TailFile(ctx, req) = response, %s; want no error", err)
+	}
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream.Recv() = _, %s; want no error", err)
+		}
+	}
+}
+
+func TestTailFileError(t *testing.T) {
+	// This test will create a gomote instance and attempt to call TailFile.
+	// If overrideID is set to true, the test will use a different gomoteID than
+	// the one created for the test.
+	testCases := []struct {
+		desc       string
+		ctx        context.Context
+		overrideID bool
+		gomoteID   string // Used iff overrideID is true.
+		path       string
+		offset     int64
+		wantCode   codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			path:     "go/test.log",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:       "missing gomote id",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			gomoteID:   "",
+			path:       "go/test.log",
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			desc:     "missing path",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "negative offset",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			path:     "go/test.log",
+			offset:   -1,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:       "gomote does not exist",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("foo", "bar")),
+			overrideID: true,
+			gomoteID:   "chucky",
+			path:       "go/test.log",
+			wantCode:   codes.NotFound,
+		},
+		{
+			desc:     "wrong gomote id",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("foo", "bar")),
+			path:     "go/test.log",
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteTest(t, context.Background())
+			gomoteID := mustCreateInstance(t, client, fakeIAP())
+			if tc.overrideID {
+				gomoteID = tc.gomoteID
+			}
+			stream, err := client.TailFile(tc.ctx, &protos.TailFileRequest{
+				GomoteId: gomoteID,
+				Path:     tc.path,
+				Offset:   tc.offset,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			res, err := stream.Recv()
+			if err != nil && status.Code(err) != tc.wantCode {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err == nil {
+				t.Fatalf("client.TailFile(ctx, req) = %v, nil; want error", res)
+			}
+		})
+	}
+}
+
 func TestUploadFile(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	client := setupGomoteTest(t, context.Background())
@@ -975,6 +1976,7 @@ func TestWriteTGZFromURLError(t *testing.T) {
 		gomoteID   string // Used iff overrideID is true.
 		url        string
 		directory  string
+		headers    []*protos.HTTPHeader
 		wantCode   codes.Code
 	}{
 		{
@@ -1009,6 +2011,20 @@ func TestWriteTGZFromURLError(t *testing.T) {
 			url:        "go.dev/dl/1_14.tar.gz",
 			wantCode:   codes.PermissionDenied,
 		},
+		{
+			desc:     "header not allowed",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			url:      "https://example.com/go.tar.gz",
+			headers:  []*protos.HTTPHeader{{Name: "Cookie", Value: "secret"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "header sent to an http URL",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			url:      "http://example.com/go.tar.gz",
+			headers:  []*protos.HTTPHeader{{Name: "Authorization", Value: "Bearer secret"}},
+			wantCode: codes.InvalidArgument,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -1018,9 +2034,10 @@ func TestWriteTGZFromURLError(t *testing.T) {
 				gomoteID = tc.gomoteID
 			}
 			req := &protos.WriteTGZFromURLRequest{
-				GomoteId:  gomoteID,
-				Url:       tc.url,
-				Directory: tc.directory,
+				GomoteId:   gomoteID,
+				Url:        tc.url,
+				Directory:  tc.directory,
+				UrlHeaders: tc.headers,
 			}
 			got, err := client.WriteTGZFromURL(tc.ctx, req)
 			if err != nil && status.Code(err) != tc.wantCode {
@@ -1129,18 +2146,22 @@ func fakeIAPWithUser(user string, id string) access.IAPFields {
 }
 
 func mustCreateInstance(t *testing.T, client protos.GomoteServiceClient, iap access.IAPFields) string {
-	req := &protos.CreateInstanceRequest{
+	return mustCreateInstanceFrom(t, client, iap, &protos.CreateInstanceRequest{
 		BuilderType: "linux-amd64",
-	}
+	}).GetGomoteId()
+}
+
+// mustCreateInstanceFrom creates an instance as requested by req and
+// returns it as described by the server once created.
+func mustCreateInstanceFrom(t *testing.T, client protos.GomoteServiceClient, iap access.IAPFields, req *protos.CreateInstanceRequest) *protos.Instance {
 	stream, err := client.CreateInstance(access.FakeContextWithOutgoingIAPAuth(context.Background(), iap), req)
 	if err != nil {
 		t.Fatalf("client.CreateInstance(ctx, %v) = %v,  %s; want no error", req, stream, err)
 	}
-	var updateComplete bool
-	var gomoteID string
+	var inst *protos.Instance
 	for {
 		update, err := stream.Recv()
-		if err == io.EOF && !updateComplete {
+		if err == io.EOF && inst == nil {
 			t.Fatal("stream.Recv = stream, io.EOF; want no EOF")
 		}
 		if err == io.EOF {
@@ -1150,11 +2171,10 @@ func mustCreateInstance(t *testing.T, client protos.GomoteServiceClient, iap acc
 			t.Fatalf("stream.Recv() = nil, %s; want no error", err)
 		}
 		if update.GetStatus() == protos.CreateInstanceResponse_COMPLETE {
-			gomoteID = update.Instance.GetGomoteId()
-			updateComplete = true
+			inst = update.GetInstance()
 		}
 	}
-	return gomoteID
+	return inst
 }
 
 const (
@@ -1195,3 +2215,27 @@ func (fbc *fakeBucketHandler) SignedURL(object string, opts *storage.SignedURLOp
 func (fbc *fakeBucketHandler) Object(name string) *storage.ObjectHandle {
 	return &storage.ObjectHandle{}
 }
+
+func TestProtoInstance(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	got := protoInstance(types.GomoteInstanceInfo{
+		ID:          "user-username-linux-amd64-0",
+		BuilderType: "gotip-linux-amd64",
+		HostType:    "host-linux-amd64-bullseye",
+		Created:     created,
+		Expires:     created.Add(30 * time.Minute),
+		Labels:      map[string]string{"pipeline": "nightly", "cl": "12345"},
+	})
+	want := &protos.Instance{
+		GomoteId:    "user-username-linux-amd64-0",
+		BuilderType: "gotip-linux-amd64",
+		HostType:    "host-linux-amd64-bullseye",
+		Created:     created.Unix(),
+		Expires:     created.Add(30 * time.Minute).Unix(),
+		Labels:      []string{"cl=12345", "pipeline=nightly"},
+		// The unknown last activity is zero.
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("protoInstance mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+package gomote
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"golang.org/x/build/internal/access"
+	"golang.org/x/build/internal/coordinator/remote"
+	"golang.org/x/build/internal/gomote/protos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The group methods are the same for both servers, since groups are stored
+// in the session pool.
+
+// CreateGroup creates a named group of gomote instances owned by the caller.
+func (s *Server) CreateGroup(ctx context.Context, req *protos.CreateGroupRequest) (*protos.CreateGroupResponse, error) {
+	return createGroup(ctx, s.buildlets, req)
+}
+
+// DeleteGroup deletes a group owned by the caller. Its instances are not destroyed.
+func (s *Server) DeleteGroup(ctx context.Context, req *protos.DeleteGroupRequest) (*protos.DeleteGroupResponse, error) {
+	return deleteGroup(ctx, s.buildlets, req)
+}
+
+// ListGroups lists the groups owned by the caller, and optionally those shared by other users.
+func (s *Server) ListGroups(ctx context.Context, req *protos.ListGroupsRequest) (*protos.ListGroupsResponse, error) {
+	return listGroups(ctx, s.buildlets, req)
+}
+
+// ModifyGroup adds instances to and removes instances from a group owned by the caller, and changes whether
+// it is shared.
+func (s *Server) ModifyGroup(ctx context.Context, req *protos.ModifyGroupRequest) (*protos.ModifyGroupResponse, error) {
+	return modifyGroup(ctx, s.buildlets, req)
+}
+
+// CreateGroup creates a named group of gomote instances owned by the caller.
+func (ss *SwarmingServer) CreateGroup(ctx context.Context, req *protos.CreateGroupRequest) (*protos.CreateGroupResponse, error) {
+	return createGroup(ctx, ss.buildlets, req)
+}
+
+// DeleteGroup deletes a group owned by the caller. Its instances are not destroyed.
+func (ss *SwarmingServer) DeleteGroup(ctx context.Context, req *protos.DeleteGroupRequest) (*protos.DeleteGroupResponse, error) {
+	return deleteGroup(ctx, ss.buildlets, req)
+}
+
+// ListGroups lists the groups owned by the caller, and optionally those shared by other users.
+func (ss *SwarmingServer) ListGroups(ctx context.Context, req *protos.ListGroupsRequest) (*protos.ListGroupsResponse, error) {
+	return listGroups(ctx, ss.buildlets, req)
+}
+
+// ModifyGroup adds instances to and removes instances from a group owned by the caller, and changes whether
+// it is shared.
+func (ss *SwarmingServer) ModifyGroup(ctx context.Context, req *protos.ModifyGroupRequest) (*protos.ModifyGroupResponse, error) {
+	return modifyGroup(ctx, ss.buildlets, req)
+}
+
+// maxGroupNameLen is the longest name a group may have.
+const maxGroupNameLen = 64
+
+func createGroup(ctx context.Context, sp *remote.SessionPool, req *protos.CreateGroupRequest) (*protos.CreateGroupResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("CreateGroup access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	name := req.GetName()
+	if name == "" || len(name) > maxGroupNameLen || strings.ContainsAny(name, "/ \t\n") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid group name %q", name)
+	}
+	userName, err := emailToUser(creds.Email)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid user email format")
+	}
+	g, err := sp.CreateGroup(creds.ID, userName, name, req.GetShared(), req.GetGomoteIds())
+	if err != nil {
+		return nil, groupError(err)
+	}
+	return &protos.CreateGroupResponse{Group: groupProto(g, creds.ID)}, nil
+}
+
+func deleteGroup(ctx context.Context, sp *remote.SessionPool, req *protos.DeleteGroupRequest) (*protos.DeleteGroupResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("DeleteGroup access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if err := sp.DeleteGroup(creds.ID, req.GetName()); err != nil {
+		return nil, groupError(err)
+	}
+	return &protos.DeleteGroupResponse{}, nil
+}
+
+func listGroups(ctx context.Context, sp *remote.SessionPool, req *protos.ListGroupsRequest) (*protos.ListGroupsResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("ListGroups access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	res := &protos.ListGroupsResponse{}
+	for _, g := range sp.Groups(creds.ID, req.GetIncludeShared()) {
+		res.Groups = append(res.Groups, groupProto(g, creds.ID))
+	}
+	return res, nil
+}
+
+func modifyGroup(ctx context.Context, sp *remote.SessionPool, req *protos.ModifyGroupRequest) (*protos.ModifyGroupResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("ModifyGroup access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	var shared *bool
+	switch req.GetSharing() {
+	case protos.ModifyGroupRequest_UNCHANGED:
+	case protos.ModifyGroupRequest_SHARED:
+		shared = new(bool)
+		*shared = true
+	case protos.ModifyGroupRequest_PRIVATE:
+		shared = new(bool)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid sharing %v", req.GetSharing())
+	}
+	g, err := sp.ModifyGroup(creds.ID, req.GetName(), req.GetAddGomoteIds(), req.GetRemoveGomoteIds(), shared)
+	if err != nil {
+		return nil, groupError(err)
+	}
+	return &protos.ModifyGroupResponse{Group: groupProto(g, creds.ID)}, nil
+}
+
+// groupProto converts a group into its protocol buffer representation,
+// for the caller with the given ID.
+func groupProto(g *remote.Group, callerID string) *protos.Group {
+	return &protos.Group{
+		Name:      g.Name,
+		Owner:     g.Owner,
+		Shared:    g.Shared,
+		GomoteIds: g.Members,
+		Owned:     g.OwnerID == callerID,
+	}
+}
+
+// groupError converts an error from a group method of the session pool
+// into a gRPC error.
+func groupError(err error) error {
+	switch {
+	case errors.Is(err, remote.ErrGroupExists):
+		return status.Errorf(codes.AlreadyExists, "%s", err)
+	case errors.Is(err, remote.ErrGroupNotFound), errors.Is(err, remote.ErrSessionNotFound):
+		return status.Errorf(codes.NotFound, "%s", err)
+	case errors.Is(err, remote.ErrNotOwner):
+		return status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "%s", err)
+}
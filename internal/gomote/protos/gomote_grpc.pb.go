@@ -26,27 +26,51 @@ type GomoteServiceClient interface {
 	Authenticate(ctx context.Context, in *AuthenticateRequest, opts ...grpc.CallOption) (*AuthenticateResponse, error)
 	// AddBootstrap adds the bootstrap version of Go to the work directory.
 	AddBootstrap(ctx context.Context, in *AddBootstrapRequest, opts ...grpc.CallOption) (*AddBootstrapResponse, error)
+	// CancelCommand cancels a command executing on a gomote instance, killing its process.
+	CancelCommand(ctx context.Context, in *CancelCommandRequest, opts ...grpc.CallOption) (*CancelCommandResponse, error)
+	// CreateGroup creates a named group of gomote instances owned by the caller.
+	CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*CreateGroupResponse, error)
 	// CreateInstance creates a gomote instance.
 	CreateInstance(ctx context.Context, in *CreateInstanceRequest, opts ...grpc.CallOption) (GomoteService_CreateInstanceClient, error)
+	// DeleteGroup deletes a group owned by the caller. Its instances are not destroyed.
+	DeleteGroup(ctx context.Context, in *DeleteGroupRequest, opts ...grpc.CallOption) (*DeleteGroupResponse, error)
 	// DestroyInstance destroys a gomote instance.
 	DestroyInstance(ctx context.Context, in *DestroyInstanceRequest, opts ...grpc.CallOption) (*DestroyInstanceResponse, error)
 	// ExecuteCommand executes a command on the gomote instance.
 	ExecuteCommand(ctx context.Context, in *ExecuteCommandRequest, opts ...grpc.CallOption) (GomoteService_ExecuteCommandClient, error)
+	// ExtendInstance extends the expiration time of a gomote instance.
+	ExtendInstance(ctx context.Context, in *ExtendInstanceRequest, opts ...grpc.CallOption) (*ExtendInstanceResponse, error)
+	// InstallBootstrap is like AddBootstrap, but streams the progress of the installation. The server
+	// downloads the bootstrap version of Go, verifying its checksum if requested, and writes it to the instance.
+	InstallBootstrap(ctx context.Context, in *AddBootstrapRequest, opts ...grpc.CallOption) (GomoteService_InstallBootstrapClient, error)
 	// InstanceAlive gives the liveness state of a gomote instance.
 	InstanceAlive(ctx context.Context, in *InstanceAliveRequest, opts ...grpc.CallOption) (*InstanceAliveResponse, error)
+	// InstanceStatus gives detailed information about the state of a gomote instance.
+	InstanceStatus(ctx context.Context, in *InstanceStatusRequest, opts ...grpc.CallOption) (*InstanceStatusResponse, error)
+	// ListCommands lists the commands executing on a gomote instance.
+	ListCommands(ctx context.Context, in *ListCommandsRequest, opts ...grpc.CallOption) (*ListCommandsResponse, error)
 	// ListDirectory lists the contents of a directory on an gomote instance.
 	ListDirectory(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (*ListDirectoryResponse, error)
+	// ListGroups lists the groups owned by the caller, and optionally those shared by other users.
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error)
 	// ListInstances lists all of the live gomote instances owned by the caller.
 	ListInstances(ctx context.Context, in *ListInstancesRequest, opts ...grpc.CallOption) (*ListInstancesResponse, error)
 	// ListSwarmingBuilders lists all of the swarming builders for the project.
 	ListSwarmingBuilders(ctx context.Context, in *ListSwarmingBuildersRequest, opts ...grpc.CallOption) (*ListSwarmingBuildersResponse, error)
+	// ModifyGroup adds instances to and removes instances from a group owned by the caller, and changes whether
+	// it is shared.
+	ModifyGroup(ctx context.Context, in *ModifyGroupRequest, opts ...grpc.CallOption) (*ModifyGroupResponse, error)
 	// ReadTGZToURL tars and zips a directory which exists on the gomote instance and returns a URL where it can be
 	// downloaded from.
 	ReadTGZToURL(ctx context.Context, in *ReadTGZToURLRequest, opts ...grpc.CallOption) (*ReadTGZToURLResponse, error)
 	// RemoveFiles removes files or directories from the gomote instance.
 	RemoveFiles(ctx context.Context, in *RemoveFilesRequest, opts ...grpc.CallOption) (*RemoveFilesResponse, error)
+	// ServerVersion gives the version of the gomote server.
+	ServerVersion(ctx context.Context, in *ServerVersionRequest, opts ...grpc.CallOption) (*ServerVersionResponse, error)
 	// SignSSHKey signs an SSH public key which can be used to SSH into instances owned by the caller.
 	SignSSHKey(ctx context.Context, in *SignSSHKeyRequest, opts ...grpc.CallOption) (*SignSSHKeyResponse, error)
+	// TailFile streams the contents of a file on a gomote instance, optionally following data appended to it.
+	TailFile(ctx context.Context, in *TailFileRequest, opts ...grpc.CallOption) (GomoteService_TailFileClient, error)
 	// UploadFile generates a signed URL and associated fields to be used when uploading the object to GCS. Once uploaded
 	// the corresponding Write endpoint can be used to send the file to the gomote instance.
 	UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error)
@@ -82,6 +106,24 @@ func (c *gomoteServiceClient) AddBootstrap(ctx context.Context, in *AddBootstrap
 	return out, nil
 }
 
+func (c *gomoteServiceClient) CancelCommand(ctx context.Context, in *CancelCommandRequest, opts ...grpc.CallOption) (*CancelCommandResponse, error) {
+	out := new(CancelCommandResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/CancelCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gomoteServiceClient) CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*CreateGroupResponse, error) {
+	out := new(CreateGroupResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/CreateGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gomoteServiceClient) CreateInstance(ctx context.Context, in *CreateInstanceRequest, opts ...grpc.CallOption) (GomoteService_CreateInstanceClient, error) {
 	stream, err := c.cc.NewStream(ctx, &GomoteService_ServiceDesc.Streams[0], "/protos.GomoteService/CreateInstance", opts...)
 	if err != nil {
@@ -114,6 +156,15 @@ func (x *gomoteServiceCreateInstanceClient) Recv() (*CreateInstanceResponse, err
 	return m, nil
 }
 
+func (c *gomoteServiceClient) DeleteGroup(ctx context.Context, in *DeleteGroupRequest, opts ...grpc.CallOption) (*DeleteGroupResponse, error) {
+	out := new(DeleteGroupResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/DeleteGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gomoteServiceClient) DestroyInstance(ctx context.Context, in *DestroyInstanceRequest, opts ...grpc.CallOption) (*DestroyInstanceResponse, error) {
 	out := new(DestroyInstanceResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/DestroyInstance", in, out, opts...)
@@ -155,6 +206,47 @@ func (x *gomoteServiceExecuteCommandClient) Recv() (*ExecuteCommandResponse, err
 	return m, nil
 }
 
+func (c *gomoteServiceClient) ExtendInstance(ctx context.Context, in *ExtendInstanceRequest, opts ...grpc.CallOption) (*ExtendInstanceResponse, error) {
+	out := new(ExtendInstanceResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/ExtendInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gomoteServiceClient) InstallBootstrap(ctx context.Context, in *AddBootstrapRequest, opts ...grpc.CallOption) (GomoteService_InstallBootstrapClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GomoteService_ServiceDesc.Streams[2], "/protos.GomoteService/InstallBootstrap", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gomoteServiceInstallBootstrapClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GomoteService_InstallBootstrapClient interface {
+	Recv() (*InstallBootstrapResponse, error)
+	grpc.ClientStream
+}
+
+type gomoteServiceInstallBootstrapClient struct {
+	grpc.ClientStream
+}
+
+func (x *gomoteServiceInstallBootstrapClient) Recv() (*InstallBootstrapResponse, error) {
+	m := new(InstallBootstrapResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *gomoteServiceClient) InstanceAlive(ctx context.Context, in *InstanceAliveRequest, opts ...grpc.CallOption) (*InstanceAliveResponse, error) {
 	out := new(InstanceAliveResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/InstanceAlive", in, out, opts...)
@@ -164,6 +256,24 @@ func (c *gomoteServiceClient) InstanceAlive(ctx context.Context, in *InstanceAli
 	return out, nil
 }
 
+func (c *gomoteServiceClient) InstanceStatus(ctx context.Context, in *InstanceStatusRequest, opts ...grpc.CallOption) (*InstanceStatusResponse, error) {
+	out := new(InstanceStatusResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/InstanceStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gomoteServiceClient) ListCommands(ctx context.Context, in *ListCommandsRequest, opts ...grpc.CallOption) (*ListCommandsResponse, error) {
+	out := new(ListCommandsResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/ListCommands", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gomoteServiceClient) ListDirectory(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (*ListDirectoryResponse, error) {
 	out := new(ListDirectoryResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/ListDirectory", in, out, opts...)
@@ -173,6 +283,15 @@ func (c *gomoteServiceClient) ListDirectory(ctx context.Context, in *ListDirecto
 	return out, nil
 }
 
+func (c *gomoteServiceClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error) {
+	out := new(ListGroupsResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/ListGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gomoteServiceClient) ListInstances(ctx context.Context, in *ListInstancesRequest, opts ...grpc.CallOption) (*ListInstancesResponse, error) {
 	out := new(ListInstancesResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/ListInstances", in, out, opts...)
@@ -191,6 +310,15 @@ func (c *gomoteServiceClient) ListSwarmingBuilders(ctx context.Context, in *List
 	return out, nil
 }
 
+func (c *gomoteServiceClient) ModifyGroup(ctx context.Context, in *ModifyGroupRequest, opts ...grpc.CallOption) (*ModifyGroupResponse, error) {
+	out := new(ModifyGroupResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/ModifyGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gomoteServiceClient) ReadTGZToURL(ctx context.Context, in *ReadTGZToURLRequest, opts ...grpc.CallOption) (*ReadTGZToURLResponse, error) {
 	out := new(ReadTGZToURLResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/ReadTGZToURL", in, out, opts...)
@@ -209,6 +337,15 @@ func (c *gomoteServiceClient) RemoveFiles(ctx context.Context, in *RemoveFilesRe
 	return out, nil
 }
 
+func (c *gomoteServiceClient) ServerVersion(ctx context.Context, in *ServerVersionRequest, opts ...grpc.CallOption) (*ServerVersionResponse, error) {
+	out := new(ServerVersionResponse)
+	err := c.cc.Invoke(ctx, "/protos.GomoteService/ServerVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gomoteServiceClient) SignSSHKey(ctx context.Context, in *SignSSHKeyRequest, opts ...grpc.CallOption) (*SignSSHKeyResponse, error) {
 	out := new(SignSSHKeyResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/SignSSHKey", in, out, opts...)
@@ -218,6 +355,38 @@ func (c *gomoteServiceClient) SignSSHKey(ctx context.Context, in *SignSSHKeyRequ
 	return out, nil
 }
 
+func (c *gomoteServiceClient) TailFile(ctx context.Context, in *TailFileRequest, opts ...grpc.CallOption) (GomoteService_TailFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GomoteService_ServiceDesc.Streams[3], "/protos.GomoteService/TailFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gomoteServiceTailFileClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GomoteService_TailFileClient interface {
+	Recv() (*TailFileResponse, error)
+	grpc.ClientStream
+}
+
+type gomoteServiceTailFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *gomoteServiceTailFileClient) Recv() (*TailFileResponse, error) {
+	m := new(TailFileResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *gomoteServiceClient) UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error) {
 	out := new(UploadFileResponse)
 	err := c.cc.Invoke(ctx, "/protos.GomoteService/UploadFile", in, out, opts...)
@@ -253,27 +422,51 @@ type GomoteServiceServer interface {
 	Authenticate(context.Context, *AuthenticateRequest) (*AuthenticateResponse, error)
 	// AddBootstrap adds the bootstrap version of Go to the work directory.
 	AddBootstrap(context.Context, *AddBootstrapRequest) (*AddBootstrapResponse, error)
+	// CancelCommand cancels a command executing on a gomote instance, killing its process.
+	CancelCommand(context.Context, *CancelCommandRequest) (*CancelCommandResponse, error)
+	// CreateGroup creates a named group of gomote instances owned by the caller.
+	CreateGroup(context.Context, *CreateGroupRequest) (*CreateGroupResponse, error)
 	// CreateInstance creates a gomote instance.
 	CreateInstance(*CreateInstanceRequest, GomoteService_CreateInstanceServer) error
+	// DeleteGroup deletes a group owned by the caller. Its instances are not destroyed.
+	DeleteGroup(context.Context, *DeleteGroupRequest) (*DeleteGroupResponse, error)
 	// DestroyInstance destroys a gomote instance.
 	DestroyInstance(context.Context, *DestroyInstanceRequest) (*DestroyInstanceResponse, error)
 	// ExecuteCommand executes a command on the gomote instance.
 	ExecuteCommand(*ExecuteCommandRequest, GomoteService_ExecuteCommandServer) error
+	// ExtendInstance extends the expiration time of a gomote instance.
+	ExtendInstance(context.Context, *ExtendInstanceRequest) (*ExtendInstanceResponse, error)
+	// InstallBootstrap is like AddBootstrap, but streams the progress of the installation. The server
+	// downloads the bootstrap version of Go, verifying its checksum if requested, and writes it to the instance.
+	InstallBootstrap(*AddBootstrapRequest, GomoteService_InstallBootstrapServer) error
 	// InstanceAlive gives the liveness state of a gomote instance.
 	InstanceAlive(context.Context, *InstanceAliveRequest) (*InstanceAliveResponse, error)
+	// InstanceStatus gives detailed information about the state of a gomote instance.
+	InstanceStatus(context.Context, *InstanceStatusRequest) (*InstanceStatusResponse, error)
+	// ListCommands lists the commands executing on a gomote instance.
+	ListCommands(context.Context, *ListCommandsRequest) (*ListCommandsResponse, error)
 	// ListDirectory lists the contents of a directory on an gomote instance.
 	ListDirectory(context.Context, *ListDirectoryRequest) (*ListDirectoryResponse, error)
+	// ListGroups lists the groups owned by the caller, and optionally those shared by other users.
+	ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error)
 	// ListInstances lists all of the live gomote instances owned by the caller.
 	ListInstances(context.Context, *ListInstancesRequest) (*ListInstancesResponse, error)
 	// ListSwarmingBuilders lists all of the swarming builders for the project.
 	ListSwarmingBuilders(context.Context, *ListSwarmingBuildersRequest) (*ListSwarmingBuildersResponse, error)
+	// ModifyGroup adds instances to and removes instances from a group owned by the caller, and changes whether
+	// it is shared.
+	ModifyGroup(context.Context, *ModifyGroupRequest) (*ModifyGroupResponse, error)
 	// ReadTGZToURL tars and zips a directory which exists on the gomote instance and returns a URL where it can be
 	// downloaded from.
 	ReadTGZToURL(context.Context, *ReadTGZToURLRequest) (*ReadTGZToURLResponse, error)
 	// RemoveFiles removes files or directories from the gomote instance.
 	RemoveFiles(context.Context, *RemoveFilesRequest) (*RemoveFilesResponse, error)
+	// ServerVersion gives the version of the gomote server.
+	ServerVersion(context.Context, *ServerVersionRequest) (*ServerVersionResponse, error)
 	// SignSSHKey signs an SSH public key which can be used to SSH into instances owned by the caller.
 	SignSSHKey(context.Context, *SignSSHKeyRequest) (*SignSSHKeyResponse, error)
+	// TailFile streams the contents of a file on a gomote instance, optionally following data appended to it.
+	TailFile(*TailFileRequest, GomoteService_TailFileServer) error
 	// UploadFile generates a signed URL and associated fields to be used when uploading the object to GCS. Once uploaded
 	// the corresponding Write endpoint can be used to send the file to the gomote instance.
 	UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error)
@@ -294,36 +487,69 @@ func (UnimplementedGomoteServiceServer) Authenticate(context.Context, *Authentic
 func (UnimplementedGomoteServiceServer) AddBootstrap(context.Context, *AddBootstrapRequest) (*AddBootstrapResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddBootstrap not implemented")
 }
+func (UnimplementedGomoteServiceServer) CancelCommand(context.Context, *CancelCommandRequest) (*CancelCommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelCommand not implemented")
+}
+func (UnimplementedGomoteServiceServer) CreateGroup(context.Context, *CreateGroupRequest) (*CreateGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGroup not implemented")
+}
 func (UnimplementedGomoteServiceServer) CreateInstance(*CreateInstanceRequest, GomoteService_CreateInstanceServer) error {
 	return status.Errorf(codes.Unimplemented, "method CreateInstance not implemented")
 }
+func (UnimplementedGomoteServiceServer) DeleteGroup(context.Context, *DeleteGroupRequest) (*DeleteGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteGroup not implemented")
+}
 func (UnimplementedGomoteServiceServer) DestroyInstance(context.Context, *DestroyInstanceRequest) (*DestroyInstanceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DestroyInstance not implemented")
 }
 func (UnimplementedGomoteServiceServer) ExecuteCommand(*ExecuteCommandRequest, GomoteService_ExecuteCommandServer) error {
 	return status.Errorf(codes.Unimplemented, "method ExecuteCommand not implemented")
 }
+func (UnimplementedGomoteServiceServer) ExtendInstance(context.Context, *ExtendInstanceRequest) (*ExtendInstanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendInstance not implemented")
+}
+func (UnimplementedGomoteServiceServer) InstallBootstrap(*AddBootstrapRequest, GomoteService_InstallBootstrapServer) error {
+	return status.Errorf(codes.Unimplemented, "method InstallBootstrap not implemented")
+}
 func (UnimplementedGomoteServiceServer) InstanceAlive(context.Context, *InstanceAliveRequest) (*InstanceAliveResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InstanceAlive not implemented")
 }
+func (UnimplementedGomoteServiceServer) InstanceStatus(context.Context, *InstanceStatusRequest) (*InstanceStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstanceStatus not implemented")
+}
+func (UnimplementedGomoteServiceServer) ListCommands(context.Context, *ListCommandsRequest) (*ListCommandsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCommands not implemented")
+}
 func (UnimplementedGomoteServiceServer) ListDirectory(context.Context, *ListDirectoryRequest) (*ListDirectoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListDirectory not implemented")
 }
+func (UnimplementedGomoteServiceServer) ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
 func (UnimplementedGomoteServiceServer) ListInstances(context.Context, *ListInstancesRequest) (*ListInstancesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListInstances not implemented")
 }
 func (UnimplementedGomoteServiceServer) ListSwarmingBuilders(context.Context, *ListSwarmingBuildersRequest) (*ListSwarmingBuildersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListSwarmingBuilders not implemented")
 }
+func (UnimplementedGomoteServiceServer) ModifyGroup(context.Context, *ModifyGroupRequest) (*ModifyGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyGroup not implemented")
+}
 func (UnimplementedGomoteServiceServer) ReadTGZToURL(context.Context, *ReadTGZToURLRequest) (*ReadTGZToURLResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReadTGZToURL not implemented")
 }
 func (UnimplementedGomoteServiceServer) RemoveFiles(context.Context, *RemoveFilesRequest) (*RemoveFilesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveFiles not implemented")
 }
+func (UnimplementedGomoteServiceServer) ServerVersion(context.Context, *ServerVersionRequest) (*ServerVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ServerVersion not implemented")
+}
 func (UnimplementedGomoteServiceServer) SignSSHKey(context.Context, *SignSSHKeyRequest) (*SignSSHKeyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SignSSHKey not implemented")
 }
+func (UnimplementedGomoteServiceServer) TailFile(*TailFileRequest, GomoteService_TailFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method TailFile not implemented")
+}
 func (UnimplementedGomoteServiceServer) UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
 }
@@ -382,6 +608,42 @@ func _GomoteService_AddBootstrap_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GomoteService_CancelCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).CancelCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/CancelCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).CancelCommand(ctx, req.(*CancelCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GomoteService_CreateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).CreateGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/CreateGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).CreateGroup(ctx, req.(*CreateGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GomoteService_CreateInstance_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(CreateInstanceRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -403,6 +665,24 @@ func (x *gomoteServiceCreateInstanceServer) Send(m *CreateInstanceResponse) erro
 	return x.ServerStream.SendMsg(m)
 }
 
+func _GomoteService_DeleteGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).DeleteGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/DeleteGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).DeleteGroup(ctx, req.(*DeleteGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GomoteService_DestroyInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DestroyInstanceRequest)
 	if err := dec(in); err != nil {
@@ -442,6 +722,45 @@ func (x *gomoteServiceExecuteCommandServer) Send(m *ExecuteCommandResponse) erro
 	return x.ServerStream.SendMsg(m)
 }
 
+func _GomoteService_ExtendInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).ExtendInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/ExtendInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).ExtendInstance(ctx, req.(*ExtendInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GomoteService_InstallBootstrap_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AddBootstrapRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GomoteServiceServer).InstallBootstrap(m, &gomoteServiceInstallBootstrapServer{stream})
+}
+
+type GomoteService_InstallBootstrapServer interface {
+	Send(*InstallBootstrapResponse) error
+	grpc.ServerStream
+}
+
+type gomoteServiceInstallBootstrapServer struct {
+	grpc.ServerStream
+}
+
+func (x *gomoteServiceInstallBootstrapServer) Send(m *InstallBootstrapResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _GomoteService_InstanceAlive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(InstanceAliveRequest)
 	if err := dec(in); err != nil {
@@ -460,6 +779,42 @@ func _GomoteService_InstanceAlive_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GomoteService_InstanceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstanceStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).InstanceStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/InstanceStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).InstanceStatus(ctx, req.(*InstanceStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GomoteService_ListCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCommandsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).ListCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/ListCommands",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).ListCommands(ctx, req.(*ListCommandsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GomoteService_ListDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListDirectoryRequest)
 	if err := dec(in); err != nil {
@@ -478,6 +833,24 @@ func _GomoteService_ListDirectory_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GomoteService_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/ListGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GomoteService_ListInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListInstancesRequest)
 	if err := dec(in); err != nil {
@@ -514,6 +887,24 @@ func _GomoteService_ListSwarmingBuilders_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GomoteService_ModifyGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModifyGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).ModifyGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/ModifyGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).ModifyGroup(ctx, req.(*ModifyGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GomoteService_ReadTGZToURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ReadTGZToURLRequest)
 	if err := dec(in); err != nil {
@@ -550,6 +941,24 @@ func _GomoteService_RemoveFiles_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GomoteService_ServerVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GomoteServiceServer).ServerVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.GomoteService/ServerVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GomoteServiceServer).ServerVersion(ctx, req.(*ServerVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GomoteService_SignSSHKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SignSSHKeyRequest)
 	if err := dec(in); err != nil {
@@ -568,6 +977,27 @@ func _GomoteService_SignSSHKey_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GomoteService_TailFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GomoteServiceServer).TailFile(m, &gomoteServiceTailFileServer{stream})
+}
+
+type GomoteService_TailFileServer interface {
+	Send(*TailFileResponse) error
+	grpc.ServerStream
+}
+
+type gomoteServiceTailFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *gomoteServiceTailFileServer) Send(m *TailFileResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _GomoteService_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UploadFileRequest)
 	if err := dec(in); err != nil {
@@ -637,18 +1067,46 @@ var GomoteService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddBootstrap",
 			Handler:    _GomoteService_AddBootstrap_Handler,
 		},
+		{
+			MethodName: "CancelCommand",
+			Handler:    _GomoteService_CancelCommand_Handler,
+		},
+		{
+			MethodName: "CreateGroup",
+			Handler:    _GomoteService_CreateGroup_Handler,
+		},
+		{
+			MethodName: "DeleteGroup",
+			Handler:    _GomoteService_DeleteGroup_Handler,
+		},
 		{
 			MethodName: "DestroyInstance",
 			Handler:    _GomoteService_DestroyInstance_Handler,
 		},
+		{
+			MethodName: "ExtendInstance",
+			Handler:    _GomoteService_ExtendInstance_Handler,
+		},
 		{
 			MethodName: "InstanceAlive",
 			Handler:    _GomoteService_InstanceAlive_Handler,
 		},
+		{
+			MethodName: "InstanceStatus",
+			Handler:    _GomoteService_InstanceStatus_Handler,
+		},
+		{
+			MethodName: "ListCommands",
+			Handler:    _GomoteService_ListCommands_Handler,
+		},
 		{
 			MethodName: "ListDirectory",
 			Handler:    _GomoteService_ListDirectory_Handler,
 		},
+		{
+			MethodName: "ListGroups",
+			Handler:    _GomoteService_ListGroups_Handler,
+		},
 		{
 			MethodName: "ListInstances",
 			Handler:    _GomoteService_ListInstances_Handler,
@@ -657,6 +1115,10 @@ var GomoteService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListSwarmingBuilders",
 			Handler:    _GomoteService_ListSwarmingBuilders_Handler,
 		},
+		{
+			MethodName: "ModifyGroup",
+			Handler:    _GomoteService_ModifyGroup_Handler,
+		},
 		{
 			MethodName: "ReadTGZToURL",
 			Handler:    _GomoteService_ReadTGZToURL_Handler,
@@ -665,6 +1127,10 @@ var GomoteService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveFiles",
 			Handler:    _GomoteService_RemoveFiles_Handler,
 		},
+		{
+			MethodName: "ServerVersion",
+			Handler:    _GomoteService_ServerVersion_Handler,
+		},
 		{
 			MethodName: "SignSSHKey",
 			Handler:    _GomoteService_SignSSHKey_Handler,
@@ -693,6 +1159,16 @@ var GomoteService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _GomoteService_ExecuteCommand_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "InstallBootstrap",
+			Handler:       _GomoteService_InstallBootstrap_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TailFile",
+			Handler:       _GomoteService_TailFile_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "gomote.proto",
 }
@@ -58,6 +58,7 @@ type SwarmingServer struct {
 	// embed the unimplemented server.
 	protos.UnimplementedGomoteServiceServer
 
+	admins                  map[string]bool // emails of users who may manage any instance
 	bucket                  bucketHandle
 	buildersClient          BuildersClient
 	buildlets               *remote.SessionPool
@@ -84,6 +85,12 @@ func NewSwarming(rsp *remote.SessionPool, rawCAPriKey []byte, gomoteGCSBucket st
 	}, nil
 }
 
+// SetAdmins sets the email addresses of the administrators, who may manage the instances of
+// other users. It must be called before the server is used.
+func (ss *SwarmingServer) SetAdmins(emails []string) {
+	ss.admins = adminSet(emails)
+}
+
 // Authenticate will allow the caller to verify that they are properly authenticated and authorized to interact with the
 // Service.
 func (ss *SwarmingServer) Authenticate(ctx context.Context, req *protos.AuthenticateRequest) (*protos.AuthenticateResponse, error) {
@@ -103,47 +110,26 @@ func (ss *SwarmingServer) AddBootstrap(ctx context.Context, req *protos.AddBoots
 		log.Printf("AddBootstrap access.IAPFromContext(ctx) = nil, %s", err)
 		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
 	}
+	if req.GetSha256() != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "the checksum is only verified by InstallBootstrap")
+	}
 	ses, bc, err := ss.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
 	if err != nil {
 		// the helper function returns meaningful GRPC error.
 		return nil, err
 	}
-	bs, err := ss.validBuilders(ctx)
+	url, dir, err := ss.bootstrap(ctx, ses, req)
 	if err != nil {
 		return nil, err
 	}
-	builder, ok := bs[ses.BuilderType]
-	if !ok {
-		return nil, status.Errorf(codes.Internal, "unable to determine builder definition")
-	}
-	cp, err := builderProperties(builder)
-	if err != nil {
-		log.Printf("AddBootstrap: bootstrap version not found for %s: %s", builder.GetId().GetBuilder(), err)
-		return &protos.AddBootstrapResponse{}, nil
-	}
-	if cp.BootstrapVersion == "latest" {
+	if url == "" {
 		return &protos.AddBootstrapResponse{}, nil
 	}
-	var cipdPlatform string
-	for _, bd := range builder.GetConfig().GetDimensions() {
-		if !strings.HasPrefix(bd, "cipd_platform:") {
-			continue
-		}
-		var ok bool
-		_, cipdPlatform, ok = strings.Cut(bd, ":")
-		if !ok {
-			return nil, status.Errorf(codes.Internal, "unknown builder type")
-		}
-		break
-	}
-	goos, goarch, err := platformToGoValues(cipdPlatform)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "unknown platform type")
-	}
-	url := fmt.Sprintf("https://storage.googleapis.com/go-builder-data/gobootstrap-%s-%s-go%s.tar.gz", goos, goarch, cp.BootstrapVersion)
-	if err = bc.PutTarFromURL(ctx, url, cp.BootstrapVersion); err != nil {
+	if err = bc.PutTarFromURL(ctx, url, dir, buildlet.TarOpts{Header: bootstrapHeader(req)}); err != nil {
+		log.Printf("AddBootstrap: writing %s with headers %s to %s: %s", url, redactHeader(bootstrapHeader(req)), dir, err)
 		return nil, status.Errorf(codes.Internal, "unable to download bootstrap Go")
 	}
+	recordActivity(ss.buildlets, req.GetGomoteId())
 	return &protos.AddBootstrapResponse{BootstrapGoUrl: url}, nil
 }
 
@@ -163,12 +149,19 @@ func (ss *SwarmingServer) CreateInstance(req *protos.CreateInstanceRequest, stre
 	}
 	builder, ok := bs[req.GetBuilderType()]
 	if !ok {
-		return status.Errorf(codes.InvalidArgument, "unknown builder type")
+		return buildlet.UnknownBuilderTypeError()
 	}
 	userName, err := emailToUser(creds.Email)
 	if err != nil {
 		return status.Errorf(codes.Internal, "invalid user email format")
 	}
+	if err := validateLifetime(req, time.Now(), maxInstanceLifetime(creds.Email, false)); err != nil {
+		return err
+	}
+	labels, err := parseLabels(req.GetLabels())
+	if err != nil {
+		return err
+	}
 	type result struct {
 		buildletClient buildlet.Client
 		err            error
@@ -219,6 +212,17 @@ func (ss *SwarmingServer) CreateInstance(req *protos.CreateInstanceRequest, stre
 			}
 			gomoteID := ss.buildlets.AddSession(creds.ID, userName, req.GetBuilderType(), req.GetBuilderType(), r.buildletClient)
 			log.Printf("created buildlet %s for %s (%s)", gomoteID, userName, r.buildletClient.String())
+			if err := ss.buildlets.SetMaxLifetime(gomoteID, maxInstanceLifetime(creds.Email, false)); err != nil {
+				return status.Errorf(codes.Internal, "unable to set gomote maximum lifetime: %s", err) // this should never happen
+			}
+			if deadline := instanceDeadline(req, time.Now()); !deadline.IsZero() {
+				if err := ss.buildlets.SetDeadline(gomoteID, deadline); err != nil {
+					return status.Errorf(codes.Internal, "unable to set gomote deadline: %s", err) // this should never happen
+				}
+			}
+			if err := ss.buildlets.SetLabels(gomoteID, labels); err != nil {
+				return status.Errorf(codes.Internal, "unable to set gomote labels: %s", err) // this should never happen
+			}
 			session, err := ss.buildlets.Session(gomoteID)
 			if err != nil {
 				return status.Errorf(codes.Internal, "unable to query for gomote timeout") // this should never happen
@@ -229,11 +233,14 @@ func (ss *SwarmingServer) CreateInstance(req *protos.CreateInstanceRequest, stre
 			}
 			err = stream.Send(&protos.CreateInstanceResponse{
 				Instance: &protos.Instance{
-					GomoteId:    gomoteID,
-					BuilderType: req.GetBuilderType(),
-					HostType:    "swarming task",
-					Expires:     session.Expires.Unix(),
-					WorkingDir:  wd,
+					GomoteId:     gomoteID,
+					BuilderType:  req.GetBuilderType(),
+					HostType:     "swarming task",
+					Expires:      session.Expires.Unix(),
+					WorkingDir:   wd,
+					Created:      session.Created.Unix(),
+					Labels:       formatLabels(session.Labels),
+					LastActivity: session.LastActivity.Unix(),
 				},
 				Status:       protos.CreateInstanceResponse_COMPLETE,
 				WaitersAhead: 0,
@@ -285,27 +292,40 @@ func (ss *SwarmingServer) ExecuteCommand(req *protos.ExecuteCommandRequest, stre
 	if builderType == "" {
 		builderType = ses.BuilderType
 	}
-	remoteErr, execErr := bc.Exec(stream.Context(), req.GetCommand(), buildlet.ExecOpts{
+	ctx, id, finished, err := startCommand(ss.buildlets, req, stream)
+	if err != nil {
+		return err
+	}
+	defer finished()
+	start := time.Now()
+	remoteErr, execErr := bc.Exec(ctx, req.GetCommand(), buildlet.ExecOpts{
 		Dir:         req.GetDirectory(),
 		SystemLevel: req.GetSystemLevel(),
-		Output: &streamWriter{writeFunc: func(p []byte) (int, error) {
+		OnStream: func(s buildlet.ExecStream, p []byte) error {
 			err := stream.Send(&protos.ExecuteCommandResponse{
 				Output: p,
+				Stream: execStream(s),
 			})
 			if err != nil {
-				return 0, fmt.Errorf("unable to send data=%w", err)
+				return fmt.Errorf("unable to send data=%w", err)
 			}
-			return len(p), nil
-		}},
+			return nil
+		},
 		Args:     req.GetArgs(),
 		ExtraEnv: req.GetAppendEnvironment(),
 		Debug:    req.GetDebug(),
 		Path:     req.GetPath(),
 	})
+	if ctx.Err() != nil && stream.Context().Err() == nil {
+		return status.Errorf(codes.Canceled, "command %s was canceled", id)
+	}
 	if execErr != nil {
 		// there were system errors preventing the command from being started or seen to completion.
 		return status.Errorf(codes.Aborted, "unable to execute command: %s", execErr)
 	}
+	if err := stream.Send(&protos.ExecuteCommandResponse{ExitStatus: exitStatus(remoteErr, time.Since(start))}); err != nil {
+		return status.Errorf(codes.Internal, "unable to stream result: %s", err)
+	}
 	if remoteErr != nil {
 		// the command failed remotely
 		return status.Errorf(codes.Unknown, "command execution failed: %s", remoteErr)
@@ -313,6 +333,57 @@ func (ss *SwarmingServer) ExecuteCommand(req *protos.ExecuteCommandRequest, stre
 	return nil
 }
 
+// CancelCommand cancels a command executing on a gomote instance, which kills its process. The requester must be
+// authenticated and own the instance.
+func (ss *SwarmingServer) CancelCommand(ctx context.Context, req *protos.CancelCommandRequest) (*protos.CancelCommandResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("CancelCommand access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if _, err := ss.session(req.GetGomoteId(), creds.ID); err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	return cancelCommand(ss.buildlets, req)
+}
+
+// ExtendInstance extends the expiration time of a gomote instance. The requester must be authenticated and
+// be the owner of the instance or an administrator. The extension may be reduced so the instance does not exceed
+// its maximum lifetime, which depends on its owner and builder type.
+func (ss *SwarmingServer) ExtendInstance(ctx context.Context, req *protos.ExtendInstanceRequest) (*protos.ExtendInstanceResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("ExtendInstance access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	return extendInstance(ss.buildlets, ss.admins, creds, req)
+}
+
+// InstallBootstrap is like AddBootstrap, but the server downloads the bootstrap version of Go itself, verifying its
+// checksum if requested, and streams the progress of the installation to the caller.
+func (ss *SwarmingServer) InstallBootstrap(req *protos.AddBootstrapRequest, stream protos.GomoteService_InstallBootstrapServer) error {
+	creds, err := access.IAPFromContext(stream.Context())
+	if err != nil {
+		log.Printf("InstallBootstrap access.IAPFromContext(ctx) = nil, %s", err)
+		return status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	ses, bc, err := ss.sessionAndClient(stream.Context(), req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return err
+	}
+	url, dir, err := ss.bootstrap(stream.Context(), ses, req)
+	if err != nil {
+		return err
+	}
+	if err := installBootstrap(stream.Context(), bc, url, bootstrapHeader(req), req.GetSha256(), dir, stream.Send); err != nil {
+		return err
+	}
+	recordActivity(ss.buildlets, req.GetGomoteId())
+	return nil
+}
+
 // InstanceAlive will ensure that the gomote instance is still alive and will extend the timeout. The requester must be authenticated.
 func (ss *SwarmingServer) InstanceAlive(ctx context.Context, req *protos.InstanceAliveRequest) (*protos.InstanceAliveResponse, error) {
 	creds, err := access.IAPFromContext(ctx)
@@ -331,7 +402,72 @@ func (ss *SwarmingServer) InstanceAlive(ctx context.Context, req *protos.Instanc
 	if err := ss.buildlets.RenewTimeout(req.GetGomoteId()); err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to renew timeout")
 	}
-	return &protos.InstanceAliveResponse{}, nil
+	if !req.GetPingBuildlet() {
+		return &protos.InstanceAliveResponse{}, nil
+	}
+	_, bc, err := ss.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	return pingBuildlet(ctx, bc)
+}
+
+// InstanceStatus gives detailed information about a gomote instance, including whether the buildlet running on
+// the instance is reachable. The requester must be authenticated and be the owner of the instance.
+func (ss *SwarmingServer) InstanceStatus(ctx context.Context, req *protos.InstanceStatusRequest) (*protos.InstanceStatusResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("InstanceStatus access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if req.GetGomoteId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid gomote ID")
+	}
+	// Looking at the instance doesn't renew it, unlike the other RPCs.
+	ses, err := peekSession(ss.buildlets, req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	res := sessionStatus(ses)
+	if req.GetSkipBuildlet() {
+		return res, nil
+	}
+	bc, err := ss.buildlets.BuildletClient(req.GetGomoteId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "specified gomote instance does not exist")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if wd, err := bc.WorkDir(ctx); err == nil {
+		res.Instance.WorkingDir = wd
+	} else {
+		log.Printf("InstanceStatus WorkDir(%s) = %s", req.GetGomoteId(), err)
+	}
+	if st, err := bc.DetailedStatus(ctx); err == nil {
+		res.Reachable = true
+		res.BuildletVersion = int32(st.Version)
+		setMachineStatus(res, st)
+	} else {
+		log.Printf("InstanceStatus DetailedStatus(%s) = %s", req.GetGomoteId(), err)
+	}
+	return res, nil
+}
+
+// ListCommands lists the commands executing on a gomote instance. The requester must be authenticated and own the
+// instance.
+func (ss *SwarmingServer) ListCommands(ctx context.Context, req *protos.ListCommandsRequest) (*protos.ListCommandsResponse, error) {
+	creds, err := access.IAPFromContext(ctx)
+	if err != nil {
+		log.Printf("ListCommands access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if _, err := ss.session(req.GetGomoteId(), creds.ID); err != nil {
+		// the helper function returns meaningful GRPC error.
+		return nil, err
+	}
+	return listCommands(ss.buildlets, req)
 }
 
 // ListDirectory lists the contents of the directory on a gomote instance.
@@ -352,6 +488,8 @@ func (ss *SwarmingServer) ListDirectory(ctx context.Context, req *protos.ListDir
 		Recursive: req.GetRecursive(),
 		Digest:    req.GetDigest(),
 		Skip:      req.GetSkipFiles(),
+		Patterns:  req.GetPatterns(),
+		MaxDepth:  int(req.GetMaxDepth()),
 	}
 	var entries []string
 	if err = bc.ListDir(context.Background(), req.GetDirectory(), opt, func(bi buildlet.DirEntry) {
@@ -476,17 +614,22 @@ func (ss *SwarmingServer) ListInstances(ctx context.Context, req *protos.ListIns
 		log.Printf("ListInstances access.IAPFromContext(ctx) = nil, %s", err)
 		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
 	}
+	if err := validateLabelSelector(req.GetLabelSelector()); err != nil {
+		return nil, err
+	}
 	res := &protos.ListInstancesResponse{}
+	minAge := time.Duration(req.GetMinAgeSeconds()) * time.Second
 	for _, s := range ss.buildlets.List() {
-		if s.OwnerID != creds.ID {
+		if s.OwnerID != creds.ID || !strings.HasPrefix(s.BuilderType, req.GetBuilderTypePrefix()) {
 			continue
 		}
-		res.Instances = append(res.Instances, &protos.Instance{
-			GomoteId:    s.ID,
-			BuilderType: s.BuilderType,
-			HostType:    s.HostType,
-			Expires:     s.Expires.Unix(),
-		})
+		if minAge > 0 && time.Since(s.Created) < minAge {
+			continue
+		}
+		if !matchLabels(s.Labels, req.GetLabelSelector()) {
+			continue
+		}
+		res.Instances = append(res.Instances, protoInstance(instanceInfo(s)))
 	}
 	return res, nil
 }
@@ -552,9 +695,19 @@ func (ss *SwarmingServer) RemoveFiles(ctx context.Context, req *protos.RemoveFil
 		log.Printf("RemoveFiles buildletClient.RemoveAll(ctx, %q) = %s", req.GetPaths(), err)
 		return nil, status.Errorf(codes.Unknown, "unable to remove files")
 	}
+	recordActivity(ss.buildlets, req.GetGomoteId())
 	return &protos.RemoveFilesResponse{}, nil
 }
 
+// ServerVersion returns the version of the gomote server binary.
+func (ss *SwarmingServer) ServerVersion(ctx context.Context, req *protos.ServerVersionRequest) (*protos.ServerVersionResponse, error) {
+	if _, err := access.IAPFromContext(ctx); err != nil {
+		log.Printf("ServerVersion access.IAPFromContext(ctx) = nil, %s", err)
+		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	return serverVersion(), nil
+}
+
 // SignSSHKey signs the public SSH key with a certificate. The signed public SSH key is intended for use with the gomote service SSH
 // server. It will be signed by the certificate authority of the server and will restrict access to the gomote instance that it was
 // signed for.
@@ -577,6 +730,43 @@ func (ss *SwarmingServer) SignSSHKey(ctx context.Context, req *protos.SignSSHKey
 	}, nil
 }
 
+// TailFile streams the contents of a file on a gomote instance. The requester must be authenticated and be the
+// owner of the instance.
+func (ss *SwarmingServer) TailFile(req *protos.TailFileRequest, stream protos.GomoteService_TailFileServer) error {
+	creds, err := access.IAPFromContext(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
+	}
+	if req.GetGomoteId() == "" || req.GetPath() == "" || req.GetOffset() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid arguments")
+	}
+	_, bc, err := ss.sessionAndClient(stream.Context(), req.GetGomoteId(), creds.ID)
+	if err != nil {
+		// the helper function returns meaningful GRPC error.
+		return err
+	}
+	var sendErr error
+	err = bc.Tail(stream.Context(), req.GetPath(), tailWriter{stream}, buildlet.TailOpts{
+		Offset: req.GetOffset(),
+		Lines:  int(req.GetLines()),
+		Follow: req.GetFollow(),
+		OnTruncate: func() {
+			sendErr = stream.Send(&protos.TailFileResponse{Truncated: true})
+		},
+	})
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		log.Printf("TailFile buildletClient.Tail(ctx, %q) = %s", req.GetPath(), err)
+		return status.Errorf(codes.Aborted, "unable to tail file: %s", err)
+	}
+	return nil
+}
+
 // UploadFile creates a URL and a set of HTTP post fields which are used to upload a file to a staging GCS bucket. Uploaded files are made available to the
 // gomote instances via a subsequent call to one of the WriteFromURL endpoints.
 func (ss *SwarmingServer) UploadFile(ctx context.Context, req *protos.UploadFileRequest) (*protos.UploadFileResponse, error) {
@@ -619,6 +809,10 @@ func (ss *SwarmingServer) WriteFileFromURL(ctx context.Context, req *protos.Writ
 		log.Printf("WriteTGZFromURL access.IAPFromContext(ctx) = nil, %s", err)
 		return nil, status.Errorf(codes.Unauthenticated, "request does not contain the required authentication")
 	}
+	header, err := urlHeader(req.GetUrl(), req.GetUrlHeaders())
+	if err != nil {
+		return nil, err
+	}
 	_, bc, err := ss.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
 	if err != nil {
 		// the helper function returns meaningful GRPC error.
@@ -649,8 +843,10 @@ func (ss *SwarmingServer) WriteFileFromURL(ctx context.Context, req *protos.Writ
 				TLSHandshakeTimeout: 5 * time.Second,
 			},
 		}
+		httpRequest.Header = header
 		resp, err := client.Do(httpRequest)
 		if err != nil {
+			log.Printf("WriteFileFromURL: fetching %s with headers %s: %s", req.GetUrl(), redactHeader(header), err)
 			return nil, status.Errorf(codes.Aborted, "failed to get file from URL: %s", err)
 		}
 		if resp.StatusCode != http.StatusOK {
@@ -662,6 +858,7 @@ func (ss *SwarmingServer) WriteFileFromURL(ctx context.Context, req *protos.Writ
 	if err := bc.Put(ctx, rc, req.GetFilename(), fs.FileMode(req.GetMode())); err != nil {
 		return nil, status.Errorf(codes.Aborted, "failed to send the file to the gomote instance: %s", err)
 	}
+	recordActivity(ss.buildlets, req.GetGomoteId())
 	return &protos.WriteFileFromURLResponse{}, nil
 }
 
@@ -679,6 +876,10 @@ func (ss *SwarmingServer) WriteTGZFromURL(ctx context.Context, req *protos.Write
 	if req.GetUrl() == "" {
 		return nil, status.Errorf(codes.InvalidArgument, "missing URL")
 	}
+	header, err := urlHeader(req.GetUrl(), req.GetUrlHeaders())
+	if err != nil {
+		return nil, err
+	}
 	_, bc, err := ss.sessionAndClient(ctx, req.GetGomoteId(), creds.ID)
 	if err != nil {
 		// the helper function returns meaningful GRPC error.
@@ -694,11 +895,16 @@ func (ss *SwarmingServer) WriteTGZFromURL(ctx context.Context, req *protos.Write
 		if err != nil {
 			return nil, status.Errorf(codes.Aborted, "unable to sign url for download: %s", err)
 		}
+		// The signed URL grants access by itself.
+		header = nil
 	}
-	if err := bc.PutTarFromURL(ctx, url, req.GetDirectory()); err != nil {
+	sha256, err := bc.PutTarFromURLSHA256(ctx, url, req.GetDirectory(), req.GetSha256(), buildlet.TarOpts{Header: header})
+	if err != nil {
+		log.Printf("WriteTGZFromURL: writing %s with headers %s to %s: %s", req.GetUrl(), redactHeader(header), req.GetDirectory(), err)
 		return nil, status.Errorf(codes.FailedPrecondition, "unable to write tar.gz: %s", err)
 	}
-	return &protos.WriteTGZFromURLResponse{}, nil
+	recordActivity(ss.buildlets, req.GetGomoteId())
+	return &protos.WriteTGZFromURLResponse{Sha256: sha256}, nil
 }
 
 // session is a helper function that retrieves a session associated with the gomoteID and ownerID.
@@ -731,6 +937,44 @@ func (ss *SwarmingServer) sessionAndClient(ctx context.Context, gomoteID, ownerI
 	return session, bc, nil
 }
 
+// bootstrap returns the URL of the bootstrap version of Go requested by req for the
+// instance of session ses, or "" if it needs none, and the directory to install it in.
+func (ss *SwarmingServer) bootstrap(ctx context.Context, ses *remote.Session, req *protos.AddBootstrapRequest) (url, dir string, err error) {
+	if err := validateBootstrapRequest(req); err != nil {
+		return "", "", err
+	}
+	bs, err := ss.validBuilders(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	builder, ok := bs[ses.BuilderType]
+	if !ok {
+		return "", "", status.Errorf(codes.Internal, "unable to determine builder definition")
+	}
+	version := bootstrapVersion(req)
+	if version == "" && req.GetUrl() == "" {
+		cp, err := builderProperties(builder)
+		if err != nil {
+			log.Printf("AddBootstrap: bootstrap version not found for %s: %s", builder.GetId().GetBuilder(), err)
+			return "", "", nil
+		}
+		if cp.BootstrapVersion == "latest" {
+			return "", "", nil
+		}
+		version = cp.BootstrapVersion
+	}
+	goos, goarch, err := builderPlatform(builder)
+	if err != nil {
+		return "", "", err
+	}
+	dir = version
+	if dir == "" {
+		// An explicit URL of an unknown version.
+		dir = "go-bootstrap"
+	}
+	return bootstrapSource(req, goos, goarch, bootstrapURL(goos, goarch, version)), dir, nil
+}
+
 // signURLForDownload generates a signed URL and fields to be used to upload an object to GCS without authenticating.
 func (ss *SwarmingServer) signURLForDownload(object string) (url string, err error) {
 	url, err = ss.bucket.SignedURL(object, &storage.SignedURLOptions{
@@ -927,6 +1171,27 @@ func createStringPairs(m map[string]string) []*swarmpb.StringPair {
 	return dims
 }
 
+// builderPlatform returns the GOOS and GOARCH of the bots of builder.
+func builderPlatform(builder *buildbucketpb.BuilderItem) (goos, goarch string, err error) {
+	var cipdPlatform string
+	for _, bd := range builder.GetConfig().GetDimensions() {
+		if !strings.HasPrefix(bd, "cipd_platform:") {
+			continue
+		}
+		var ok bool
+		_, cipdPlatform, ok = strings.Cut(bd, ":")
+		if !ok {
+			return "", "", status.Errorf(codes.Internal, "unknown builder type")
+		}
+		break
+	}
+	goos, goarch, err = platformToGoValues(cipdPlatform)
+	if err != nil {
+		return "", "", status.Errorf(codes.Internal, "unknown platform type")
+	}
+	return goos, goarch, nil
+}
+
 func platformToGoValues(platform string) (goos string, goarch string, err error) {
 	goos, goarch, ok := strings.Cut(platform, "-")
 	if !ok {
@@ -280,6 +280,15 @@ func TestSwarmingCreateInstanceError(t *testing.T) {
 			},
 			wantCode: codes.InvalidArgument,
 		},
+		{
+			desc: "lifetime too long",
+			ctx:  access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			request: &protos.CreateInstanceRequest{
+				BuilderType:     "gotip-linux-amd64-boringcrypto",
+				LifetimeSeconds: int64(maxUserLifetime/time.Second) + 1,
+			},
+			wantCode: codes.InvalidArgument,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -390,7 +399,9 @@ func TestSwarmingExecuteCommand(t *testing.T) {
 		t.Fatalf("client.ExecuteCommand(ctx, req) = response, %s; want no error", err)
 	}
 	var out []byte
-	for {
+	var exit *protos.ExecuteCommandResponse_ExitStatus
+	var execID string
+	for first := true; ; first = false {
 		res, err := stream.Recv()
 		if err != nil && err == io.EOF {
 			break
@@ -398,11 +409,34 @@ func TestSwarmingExecuteCommand(t *testing.T) {
 		if err != nil {
 			t.Fatalf("stream.Recv() = _, %s; want no error", err)
 		}
+		if exit != nil {
+			t.Fatalf("stream.Recv() = %v after the exit status; want EOF", res)
+		}
+		if (res.GetExecutionId() != "") != first {
+			t.Errorf("stream.Recv() = %v; want the execution ID in the first response only", res)
+		}
+		if first {
+			execID = res.GetExecutionId()
+		}
+		if len(res.GetOutput()) > 0 && res.GetStream() != protos.ExecuteCommandResponse_STDOUT {
+			t.Errorf("output stream = %v; want %v", res.GetStream(), protos.ExecuteCommandResponse_STDOUT)
+		}
 		out = append(out, res.GetOutput()...)
+		exit = res.GetExitStatus()
 	}
 	if len(out) == 0 {
 		t.Fatalf("output: %q, expected non-empty", out)
 	}
+	if exit.GetState() != "ok" || exit.GetExitCode() != 0 {
+		t.Errorf("exit status = %v; want ok", exit)
+	}
+	// The command has finished, so it can no longer be listed or canceled.
+	if res, err := client.ListCommands(ctx, &protos.ListCommandsRequest{GomoteId: gomoteID}); err != nil || len(res.GetCommands()) != 0 {
+		t.Errorf("client.ListCommands(ctx, %s) = %v, %v; want no commands", gomoteID, res, err)
+	}
+	if _, err := client.CancelCommand(ctx, &protos.CancelCommandRequest{GomoteId: gomoteID, ExecutionId: execID}); status.Code(err) != codes.NotFound {
+		t.Errorf("client.CancelCommand(ctx, %s, %q) = %v; want NotFound", gomoteID, execID, err)
+	}
 }
 
 func TestSwarmingExecuteCommandError(t *testing.T) {
@@ -471,6 +505,10 @@ func TestSwarmingExecuteCommandError(t *testing.T) {
 				t.Fatalf("unexpected error: %s", err)
 			}
 			res, err := stream.Recv()
+			if err == nil && res.GetExecutionId() != "" {
+				// Skip the execution ID, sent once the command has started.
+				res, err = stream.Recv()
+			}
 			if err != nil && status.Code(err) != tc.wantCode {
 				t.Fatalf("unexpected error: %s", err)
 			}
@@ -481,6 +519,94 @@ func TestSwarmingExecuteCommandError(t *testing.T) {
 	}
 }
 
+func TestSwarmingExtendInstance(t *testing.T) {
+	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+	gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	before := time.Now()
+	req := &protos.ExtendInstanceRequest{
+		GomoteId: gomoteID,
+		Duration: int64(time.Hour / time.Second),
+	}
+	got, err := client.ExtendInstance(ctx, req)
+	if err != nil {
+		t.Fatalf("client.ExtendInstance(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	if exp := time.Unix(got.GetExpires(), 0); exp.Before(before.Add(time.Hour)) {
+		t.Errorf("client.ExtendInstance(ctx, %v) expires = %s; want >= %s", req, exp, before.Add(time.Hour))
+	}
+	if got.GetClamped() {
+		t.Errorf("client.ExtendInstance(ctx, %v) clamped = true; want false", req)
+	}
+	if got.GetMaxExpires() < got.GetExpires() {
+		t.Errorf("client.ExtendInstance(ctx, %v) max_expires = %d; want >= %d", req, got.GetMaxExpires(), got.GetExpires())
+	}
+}
+
+func TestSwarmingExtendInstanceError(t *testing.T) {
+	// This test will create a gomote instance and attempt to call ExtendInstance.
+	// If overrideID is set to true, the test will use a different gomoteID than
+	// the one created for the test.
+	testCases := []struct {
+		desc       string
+		ctx        context.Context
+		overrideID bool
+		gomoteID   string // Used iff overrideID is true.
+		duration   int64
+		wantCode   codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:       "missing gomote id",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			desc:     "negative duration",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			duration: -1,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:       "gomote does not exist",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			gomoteID:   "xyz",
+			wantCode:   codes.NotFound,
+		},
+		{
+			desc:     "gomote is not owned by caller",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("user-x", "email-y")),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+			gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+			if tc.overrideID {
+				gomoteID = tc.gomoteID
+			}
+			req := &protos.ExtendInstanceRequest{
+				GomoteId: gomoteID,
+				Duration: tc.duration,
+			}
+			got, err := client.ExtendInstance(tc.ctx, req)
+			if err != nil && status.Code(err) != tc.wantCode {
+				t.Fatalf("unexpected error: %s; want %s", err, tc.wantCode)
+			}
+			if err == nil {
+				t.Fatalf("client.ExtendInstance(ctx, %v) = %v, nil; want error", req, got)
+			}
+		})
+	}
+}
+
 func TestSwarmingInstanceAlive(t *testing.T) {
 	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
 	gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
@@ -494,6 +620,23 @@ func TestSwarmingInstanceAlive(t *testing.T) {
 	}
 }
 
+func TestSwarmingInstanceAlivePingBuildlet(t *testing.T) {
+	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+	gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+	req := &protos.InstanceAliveRequest{
+		GomoteId:     gomoteID,
+		PingBuildlet: true,
+	}
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	got, err := client.InstanceAlive(ctx, req)
+	if err != nil {
+		t.Fatalf("client.InstanceAlive(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	if !got.GetClockReported() {
+		t.Errorf("client.InstanceAlive(ctx, %v) = %v; want the buildlet's clock reported", req, got)
+	}
+}
+
 func TestSwarmingInstanceAliveError(t *testing.T) {
 	// This test will create a gomote instance and attempt to call InstanceAlive.
 	// If overrideID is set to true, the test will use a different gomoteID than
@@ -550,6 +693,85 @@ func TestSwarmingInstanceAliveError(t *testing.T) {
 	}
 }
 
+func TestSwarmingInstanceStatus(t *testing.T) {
+	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+	gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+	req := &protos.InstanceStatusRequest{
+		GomoteId: gomoteID,
+	}
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	got, err := client.InstanceStatus(ctx, req)
+	if err != nil {
+		t.Fatalf("client.InstanceStatus(ctx, %v) = %v, %s; want no error", req, got, err)
+	}
+	want := &protos.InstanceStatusResponse{
+		Instance: &protos.Instance{
+			GomoteId:    gomoteID,
+			BuilderType: "gotip-linux-amd64-boringcrypto",
+			WorkingDir:  "/work",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "created", "expires", "host_type", "last_activity"), protocmp.IgnoreFields(&protos.InstanceStatusResponse{}, "reachable", "buildlet_version")); diff != "" {
+		t.Errorf("InstanceStatus() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSwarmingInstanceStatusError(t *testing.T) {
+	// This test will create a gomote instance and attempt to call InstanceStatus.
+	// If overrideID is set to true, the test will use a different gomoteID than
+	// the one created for the test.
+	testCases := []struct {
+		desc       string
+		ctx        context.Context
+		overrideID bool
+		gomoteID   string // Used iff overrideID is true.
+		wantCode   codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:       "missing gomote id",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			desc:       "gomote does not exist",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			gomoteID:   "xyz",
+			wantCode:   codes.NotFound,
+		},
+		{
+			desc:     "gomote is not owned by caller",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("user-x", "email-y")),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+			gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+			if tc.overrideID {
+				gomoteID = tc.gomoteID
+			}
+			req := &protos.InstanceStatusRequest{
+				GomoteId: gomoteID,
+			}
+			got, err := client.InstanceStatus(tc.ctx, req)
+			if err != nil && status.Code(err) != tc.wantCode {
+				t.Fatalf("unexpected error: %s; want %s", err, tc.wantCode)
+			}
+			if err == nil {
+				t.Fatalf("client.InstanceStatus(ctx, %v) = %v, nil; want error", req, got)
+			}
+		})
+	}
+}
+
 func TestSwarmingListDirectory(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
@@ -651,9 +873,21 @@ func TestSwarmingListInstance(t *testing.T) {
 		t.Fatalf("client.ListInstances = nil, %s; want no error", err)
 	}
 	got := response.GetInstances()
-	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "expires", "host_type")); diff != "" {
+	if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&protos.Instance{}, "created", "expires", "host_type", "last_activity")); diff != "" {
 		t.Errorf("ListInstances() mismatch (-want, +got):\n%s", diff)
 	}
+	for _, req := range []*protos.ListInstancesRequest{
+		{BuilderTypePrefix: "gotip-darwin"},
+		{MinAgeSeconds: 3600},
+	} {
+		response, err := client.ListInstances(ctx, req)
+		if err != nil {
+			t.Fatalf("client.ListInstances(%v) = nil, %s; want no error", req, err)
+		}
+		if got := response.GetInstances(); len(got) != 0 {
+			t.Errorf("client.ListInstances(%v) = %v; want no instances", req, got)
+		}
+	}
 }
 
 func TestSwarmingReadTGZToURLError(t *testing.T) {
@@ -728,6 +962,24 @@ func TestSwarmingRemoveFiles(t *testing.T) {
 	}
 }
 
+func TestSwarmingServerVersion(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClient())
+	got, err := client.ServerVersion(ctx, &protos.ServerVersionRequest{})
+	if err != nil {
+		t.Fatalf("client.ServerVersion(ctx, request) = %v, %s; want no error", got, err)
+	}
+}
+
+func TestSwarmingServerVersionError(t *testing.T) {
+	wantCode := codes.Unauthenticated
+	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClient())
+	_, err := client.ServerVersion(context.Background(), &protos.ServerVersionRequest{})
+	if status.Code(err) != wantCode {
+		t.Fatalf("client.ServerVersion(ctx, request) = _, %s; want %s", status.Code(err), wantCode)
+	}
+}
+
 func TestSwarmingSignSSHKey(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
@@ -807,6 +1059,109 @@ func TestSwarmingSignSSHKeyError(t *testing.T) {
 	}
 }
 
+func TestSwarmingTailFile(t *testing.T) {
+	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
+	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+	gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+	stream, err := client.TailFile(ctx, &protos.TailFileRequest{
+		GomoteId: gomoteID,
+		Path:     "go/test.log",
+		Lines:    10,
+	})
+	if err != nil {
+		t.Fatalf("client.TailFile(ctx, req) = response, %s; want no error", err)
+	}
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream.Recv() = _, %s; want no error", err)
+		}
+	}
+}
+
+func TestSwarmingTailFileError(t *testing.T) {
+	// This test will create a gomote instance and attempt to call TailFile.
+	// If overrideID is set to true, the test will use a different gomoteID than
+	// the one created for the test.
+	testCases := []struct {
+		desc       string
+		ctx        context.Context
+		overrideID bool
+		gomoteID   string // Used iff overrideID is true.
+		path       string
+		offset     int64
+		wantCode   codes.Code
+	}{
+		{
+			desc:     "unauthenticated request",
+			ctx:      context.Background(),
+			path:     "go/test.log",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			desc:       "missing gomote id",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			overrideID: true,
+			gomoteID:   "",
+			path:       "go/test.log",
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			desc:     "missing path",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "negative offset",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP()),
+			path:     "go/test.log",
+			offset:   -1,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:       "gomote does not exist",
+			ctx:        access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("foo", "bar")),
+			overrideID: true,
+			gomoteID:   "chucky",
+			path:       "go/test.log",
+			wantCode:   codes.NotFound,
+		},
+		{
+			desc:     "wrong gomote id",
+			ctx:      access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAPWithUser("foo", "bar")),
+			path:     "go/test.log",
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
+			gomoteID := mustCreateSwarmingInstance(t, client, fakeIAP())
+			if tc.overrideID {
+				gomoteID = tc.gomoteID
+			}
+			stream, err := client.TailFile(tc.ctx, &protos.TailFileRequest{
+				GomoteId: gomoteID,
+				Path:     tc.path,
+				Offset:   tc.offset,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			res, err := stream.Recv()
+			if err != nil && status.Code(err) != tc.wantCode {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err == nil {
+				t.Fatalf("client.TailFile(ctx, req) = %v, nil; want error", res)
+			}
+		})
+	}
+}
+
 func TestSwarmingUploadFile(t *testing.T) {
 	ctx := access.FakeContextWithOutgoingIAPAuth(context.Background(), fakeIAP())
 	client := setupGomoteSwarmingTest(t, context.Background(), mockSwarmClientSimple())
@@ -0,0 +1,76 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+package gomote
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/build/internal/gomote/protos"
+	"golang.org/x/net/http/httpguts"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// urlHeaderAllowlist are the canonical names of the headers which may be
+// sent with the requests fetching the URLs of WriteFileFromURL,
+// WriteTGZFromURL and the bootstrap RPCs. They're limited to the headers
+// artifact stores authenticate with, so that the server and instances
+// can't be used to send arbitrary requests.
+var urlHeaderAllowlist = map[string]bool{
+	"Authorization":   true,
+	"Private-Token":   true, // GitLab
+	"X-Jfrog-Art-Api": true, // Artifactory
+}
+
+// urlHeader validates the headers requested to be sent with the request
+// fetching rawURL, and returns them. It returns nil if none are requested.
+// The errors it returns don't contain the values of the headers, which are
+// usually credentials.
+func urlHeader(rawURL string, hs []*protos.HTTPHeader) (http.Header, error) {
+	if len(hs) == 0 {
+		return nil, nil
+	}
+	if !strings.HasPrefix(rawURL, "https://") {
+		return nil, status.Errorf(codes.InvalidArgument, "headers may only be sent to https URLs")
+	}
+	h := make(http.Header)
+	for _, hdr := range hs {
+		name := http.CanonicalHeaderKey(hdr.GetName())
+		if !urlHeaderAllowlist[name] {
+			return nil, status.Errorf(codes.InvalidArgument, "header %q may not be sent; the allowed headers are %s", hdr.GetName(), strings.Join(allowedURLHeaders(), ", "))
+		}
+		if !httpguts.ValidHeaderFieldValue(hdr.GetValue()) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid value of header %s", name)
+		}
+		h.Add(name, hdr.GetValue())
+	}
+	return h, nil
+}
+
+// allowedURLHeaders returns the sorted names of the headers in
+// urlHeaderAllowlist.
+func allowedURLHeaders() []string {
+	var names []string
+	for name := range urlHeaderAllowlist {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// redactHeader returns a description of h for logs, without the values of
+// the headers.
+func redactHeader(h http.Header) string {
+	var names []string
+	for name := range h {
+		names = append(names, name+": REDACTED")
+	}
+	sort.Strings(names)
+	return "[" + strings.Join(names, ", ") + "]"
+}
@@ -14,8 +14,10 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -24,14 +26,36 @@ import (
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/build/internal/wstunnel"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/idtoken"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/status"
 )
 
+// ErrCredentialsExpired is returned when the user's stored credentials have
+// expired or been revoked, so fresh tokens can't be obtained. The stored
+// credentials are removed, so the next run asks the user to log in again.
+var ErrCredentialsExpired = errors.New("gomote credentials have expired")
+
+// IsCredentialsExpired reports whether err, which may have been returned
+// by an RPC, is due to ErrCredentialsExpired.
+func IsCredentialsExpired(err error) bool {
+	if errors.Is(err, ErrCredentialsExpired) {
+		return true
+	}
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.Unauthenticated && st.Message() == ErrCredentialsExpired.Error()
+}
+
+// tokenRefreshMargin is how long before tokens expire they are refreshed,
+// so that they are still valid by the time requests reach the server.
+const tokenRefreshMargin = 5 * time.Minute
+
 var gomoteConfig = &oauth2.Config{
 	// Gomote client ID and secret.
 	ClientID:     "872405196845-odamr0j3kona7rp7fima6h4ummnd078t.apps.googleusercontent.com",
@@ -40,7 +64,7 @@ var gomoteConfig = &oauth2.Config{
 	Scopes:       []string{"email openid profile"},
 }
 
-func login(ctx context.Context) (*oauth2.Token, error) {
+func login(ctx context.Context, scope string) (*oauth2.Token, error) {
 	resp, err := http.PostForm("https://oauth2.googleapis.com/device/code", url.Values{
 		"client_id": []string{gomoteConfig.ClientID},
 		"scope":     gomoteConfig.Scopes,
@@ -89,7 +113,7 @@ outer:
 		}
 	}
 
-	if err := writeToken(refresh); err != nil {
+	if err := writeToken(scope, refresh); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: could not save token, you will be asked to log in again: %v\n", err)
 	}
 	return refresh, nil
@@ -103,8 +127,34 @@ type codeResponse struct {
 	VerificationURL string `json:"verification_url"`
 }
 
-func writeToken(refresh *oauth2.Token) error {
+// TokenPath returns the path of the file storing the refresh token of
+// the credential scope, as set by Dialer.CredentialScope.
+func TokenPath(scope string) (string, error) {
 	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if scope == "" {
+		return filepath.Join(configDir, "gomote/iap-refresh-tv-token"), nil
+	}
+	return filepath.Join(configDir, "gomote/servers", scopeDir(scope), "iap-refresh-tv-token"), nil
+}
+
+// scopeDir returns the name of the directory storing the credentials of
+// scope, with any character which isn't safe in file names on all
+// systems, such as the colon before a port, replaced by an underscore.
+func scopeDir(scope string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, scope)
+}
+
+func writeToken(scope string, refresh *oauth2.Token) error {
+	path, err := TokenPath(scope)
 	if err != nil {
 		return err
 	}
@@ -112,19 +162,31 @@ func writeToken(refresh *oauth2.Token) error {
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(filepath.Join(configDir, "gomote"), 0755)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(configDir, "gomote/iap-refresh-tv-token"), refreshBytes, 0600)
+	return os.WriteFile(path, refreshBytes, 0600)
 }
 
-func cachedToken() (*oauth2.Token, error) {
-	configDir, err := os.UserConfigDir()
+// removeToken removes the stored refresh token of scope, if any.
+func removeToken(scope string) error {
+	path, err := TokenPath(scope)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func cachedToken(scope string) (*oauth2.Token, error) {
+	path, err := TokenPath(scope)
 	if err != nil {
 		return nil, err
 	}
-	refreshBytes, err := os.ReadFile(filepath.Join(configDir, "gomote/iap-refresh-tv-token"))
+	refreshBytes, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -144,6 +206,12 @@ func cachedToken() (*oauth2.Token, error) {
 // TokenSource returns a TokenSource that can be used to access Go's
 // IAP-protected sites. It will prompt for login if necessary.
 func TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	return new(Dialer).TokenSource(ctx)
+}
+
+// TokenSource is like the package's TokenSource function, but uses and
+// stores the credentials of d.CredentialScope.
+func (d *Dialer) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
 	const audience = "872405196845-b6fu2qpi0fehdssmc8qo47h2u3cepi0e.apps.googleusercontent.com" // Go build IAP client ID.
 
 	if metadata.OnGCE() {
@@ -152,17 +220,17 @@ func TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
 		}
 	}
 
-	refresh, err := cachedToken()
+	refresh, err := cachedToken(d.CredentialScope)
 	if err != nil {
 		return nil, err
 	}
 	if refresh == nil {
-		refresh, err = login(ctx)
+		refresh, err = login(ctx, d.CredentialScope)
 		if err != nil {
 			return nil, err
 		}
 	}
-	tokenSource := oauth2.ReuseTokenSource(nil, &jwtTokenSource{gomoteConfig, audience, refresh})
+	tokenSource := oauth2.ReuseTokenSourceWithExpiry(nil, &jwtTokenSource{gomoteConfig, audience, refresh, d.CredentialScope}, tokenRefreshMargin)
 	// Eagerly request a token to verify we're good. The source will cache it.
 	if _, err := tokenSource.Token(); err != nil {
 		return nil, err
@@ -181,24 +249,128 @@ func HTTPClient(ctx context.Context) (*http.Client, error) {
 }
 
 // GRPCClient returns a *gprc.ClientConn that can access Go's IAP-protected
-// servers. It will prompt for login if necessary.
-func GRPCClient(ctx context.Context, addr string) (*grpc.ClientConn, error) {
-	ts, err := TokenSource(ctx)
+// servers. It will prompt for login if necessary. Any additional dial
+// options, such as interceptors, are appended to the default ones.
+func GRPCClient(ctx context.Context, addr string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return new(Dialer).GRPCClient(ctx, addr, extraOpts...)
+}
+
+// GRPCTunnelClient is like GRPCClient, but connects to addr through a
+// WebSocket tunnel on its HTTPS port rather than directly. It is for
+// networks which only allow HTTPS through a proxy. The proxy is taken
+// from the environment, as for HTTP requests.
+func GRPCTunnelClient(ctx context.Context, addr string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return new(Dialer).GRPCTunnelClient(ctx, addr, extraOpts...)
+}
+
+// A Dialer customizes how GRPCClient and GRPCTunnelClient reach a server.
+// The zero value connects directly, or through the HTTPS proxy given by
+// the environment, and verifies the server's certificate with the
+// system's roots.
+type Dialer struct {
+	// TLSConfig is the base configuration of TLS connections to the
+	// server, such as one whose RootCAs include a private CA.
+	TLSConfig *tls.Config
+
+	// DialContext dials the TCP connections to the server, such as
+	// through a SOCKS proxy. If it is set, the HTTPS proxy given by
+	// the environment is only used by tunnels.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// CredentialScope names the credentials used to authenticate to the
+	// server, which are stored apart from those of other scopes, so
+	// that the credentials of a server, such as a staging or
+	// self-hosted one, are never sent to another. The empty scope is
+	// that of Go's production servers.
+	CredentialScope string
+}
+
+// tlsConfig returns the configuration of TLS connections to addr.
+func (d *Dialer) tlsConfig(addr string) *tls.Config {
+	cfg := d.TLSConfig.Clone()
+	if cfg == nil {
+		cfg = new(tls.Config)
+	}
+	if strings.HasPrefix(addr, "localhost:") {
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg
+}
+
+// GRPCClient is like the package's GRPCClient function, but connects
+// as configured by d.
+func (d *Dialer) GRPCClient(ctx context.Context, addr string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	ts, err := d.TokenSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(d.tlsConfig(addr))),
+		grpc.WithDefaultCallOptions(grpc.PerRPCCredentials(perRPCCredentials{oauth.TokenSource{TokenSource: ts}})),
+		grpc.WithBlock(),
+	}
+	if d.DialContext != nil {
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return d.DialContext(ctx, "tcp", addr)
+		}))
+	}
+	opts = append(opts, extraOpts...)
+	return grpc.DialContext(ctx, addr, opts...)
+}
+
+// GRPCTunnelClient is like the package's GRPCTunnelClient function, but
+// connects as configured by d.
+func (d *Dialer) GRPCTunnelClient(ctx context.Context, addr string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	ts, err := d.TokenSource(ctx)
 	if err != nil {
 		return nil, err
 	}
+	tlsConfig := d.tlsConfig(addr)
+	dial := func(ctx context.Context, addr string) (net.Conn, error) {
+		// IAP authenticates the upgrade request, not the gRPC requests
+		// sent through the tunnel.
+		tok, err := ts.Token()
+		if err != nil {
+			return nil, err
+		}
+		wd := &wstunnel.Dialer{
+			Header:    http.Header{"Authorization": {tok.Type() + " " + tok.AccessToken}},
+			TLSConfig: tlsConfig,
+			NetDial:   d.DialContext,
+		}
+		return wd.DialContext(ctx, "wss://"+addr+wstunnel.Path)
+	}
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: strings.HasPrefix(addr, "localhost:")})),
-		grpc.WithDefaultCallOptions(grpc.PerRPCCredentials(oauth.TokenSource{TokenSource: ts})),
+		grpc.WithTransportCredentials(wstunnel.TransportCredentials()),
+		grpc.WithContextDialer(dial),
+		grpc.WithDefaultCallOptions(grpc.PerRPCCredentials(perRPCCredentials{oauth.TokenSource{TokenSource: ts}})),
 		grpc.WithBlock(),
 	}
+	opts = append(opts, extraOpts...)
 	return grpc.DialContext(ctx, addr, opts...)
 }
 
+// perRPCCredentials attach a token to each RPC, refreshing it when it
+// is about to expire. If it can't be refreshed because the user's
+// credentials have expired, the RPC fails with ErrCredentialsExpired
+// as its status message, rather than a generic error.
+type perRPCCredentials struct {
+	oauth.TokenSource
+}
+
+func (c perRPCCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	md, err := c.TokenSource.GetRequestMetadata(ctx, uri...)
+	if errors.Is(err, ErrCredentialsExpired) {
+		return nil, status.Error(codes.Unauthenticated, ErrCredentialsExpired.Error())
+	}
+	return md, err
+}
+
 type jwtTokenSource struct {
 	conf     *oauth2.Config
 	audience string
 	refresh  *oauth2.Token
+	scope    string // the credential scope of refresh
 }
 
 // Token exchanges a refresh token for a JWT that works with IAP. As of writing, there
@@ -217,6 +389,16 @@ func (s *jwtTokenSource) Token() (*oauth2.Token, error) {
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error == "invalid_grant" {
+			// The refresh token has expired or been revoked.
+			if err := removeToken(s.scope); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not remove expired token: %v\n", err)
+			}
+			return nil, ErrCredentialsExpired
+		}
 		return nil, fmt.Errorf("IAP token exchange failed: status %v, body %q", resp.Status, body)
 	}
 	body, err := io.ReadAll(resp.Body)
@@ -227,12 +409,18 @@ func (s *jwtTokenSource) Token() (*oauth2.Token, error) {
 	if err := json.Unmarshal(body, &token); err != nil {
 		return nil, err
 	}
-	return &oauth2.Token{
+	tok := &oauth2.Token{
 		TokenType:   "Bearer",
 		AccessToken: token.IDToken,
-	}, nil
+	}
+	if token.ExpiresIn > 0 {
+		// Without an expiry, the token would be reused forever.
+		tok.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return tok, nil
 }
 
 type jwtTokenJSON struct {
-	IDToken string `json:"id_token"`
+	IDToken   string `json:"id_token"`
+	ExpiresIn int64  `json:"expires_in"`
 }
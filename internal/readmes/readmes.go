@@ -0,0 +1,794 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package readmes creates or updates the README.md files of the
+// golang.org/x/build tree, and its index of packages, PACKAGES.md. It's
+// the implementation of the update-readmes.go tool at the root of the
+// tree, whose documentation describes the files it generates.
+package readmes
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	// header marks the files generated by this tool. Files which
+	// exist without it are left alone.
+	header = "Auto-generated by x/build/update-readmes.go"
+
+	// footerMarker marks the end of the generated content of a file.
+	// The content which follows it is preserved.
+	footerMarker = "<!-- End of auto-generated section -->"
+
+	// beginMarker marks the start of the generated content of a
+	// README.md file, if it's preceded by hand-written content, which
+	// is preserved.
+	beginMarker = "<!-- Begin of auto-generated section -->"
+
+	// indexFile is the name of the index of packages, in the root of
+	// the tree.
+	indexFile = "PACKAGES.md"
+
+	// usageMarker, after the footerMarker of the README.md file of a
+	// command, opts it in to documenting the command's usage.
+	usageMarker = "<!-- usage -->"
+
+	// DefaultTemplate is the default template of the README.md files.
+	DefaultTemplate = "<!-- " + header + ` -->
+
+[![Go Reference](https://pkg.go.dev/badge/{{.ImportPath}}.svg)](https://pkg.go.dev/{{.ImportPath}})
+
+# {{.ImportPath}}
+
+{{.Doc}}
+{{.Footer}}`
+)
+
+// LoadTemplate returns the template of the README.md files in file, or the
+// default one if file is empty.
+func LoadTemplate(file string) (*template.Template, error) {
+	if file == "" {
+		return template.New("README.md").Parse(DefaultTemplate)
+	}
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(filepath.Base(file)).Parse(string(text))
+}
+
+// ImportPackage imports the package pkgName, whose files are in dir.
+func ImportPackage(dir, pkgName string) (*build.Package, error) {
+	bctx := build.Default
+	bctx.Dir = dir // Set Dir since some x/build packages are in nested modules.
+	return bctx.Import(pkgName, "", 0)
+}
+
+// Options configure Update.
+type Options struct {
+	// Template generates the README.md files. If nil, DefaultTemplate
+	// is used.
+	Template *template.Template
+
+	// Import imports the package pkgName, whose files are in dir. If
+	// nil, ImportPackage is used.
+	Import func(dir, pkgName string) (*build.Package, error)
+
+	// Scope, if non-nil, limits the update to the packages it includes,
+	// and those without a README.md file. The index, which needs all of
+	// them, isn't updated.
+	Scope *Scope
+
+	// Check, if set, makes Update write a diff of each file which is
+	// missing or out of date instead of writing the file.
+	Check bool
+
+	// Verbose makes Update log how long each directory took, and how
+	// many README.md files were written, unchanged and skipped.
+	Verbose bool
+}
+
+// Update creates or updates the README.md files of the packages in the
+// tree rooted at root, which is the golang.org/x/build module, and its
+// index of packages, as configured by opts, and returns the paths of those
+// which were missing or out of date. In check mode, it writes their diffs
+// to w.
+//
+// It imports the packages and generates their files concurrently, but
+// writes them, and logs, in the order of the directories.
+func Update(root string, opts Options, w io.Writer) (stale []string, err error) {
+	tmpl, importPkg, sc, check := opts.Template, opts.Import, opts.Scope, opts.Check
+	if tmpl == nil {
+		if tmpl, err = LoadTemplate(""); err != nil {
+			return nil, err
+		}
+	}
+	if importPkg == nil {
+		importPkg = ImportPackage
+	}
+	// update creates or updates the file at path, given its existing
+	// and new contents.
+	update := func(path string, exist, newContents []byte) error {
+		if newContents == nil || bytes.Equal(exist, newContents) {
+			return nil
+		}
+		stale = append(stale, path)
+		if check {
+			oldName := path
+			if exist == nil {
+				oldName = "/dev/null"
+			}
+			_, err := io.WriteString(w, lineDiff(oldName, path, exist, newContents))
+			return err
+		}
+		if err := os.WriteFile(path, newContents, 0644); err != nil {
+			return err
+		}
+		log.Printf("Wrote %s", path)
+		return nil
+	}
+
+	dirs, err := packageDirs(root, sc)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]readmeResult, len(dirs))
+	done := make([]chan struct{}, len(dirs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	next := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(next)
+		for i := range dirs {
+			select {
+			case next <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for n := 0; n < runtime.GOMAXPROCS(0); n++ {
+		go func() {
+			for i := range next {
+				results[i] = generateReadme(dirs[i], tmpl, importPkg)
+				close(done[i])
+			}
+		}()
+	}
+
+	var index []indexEntry
+	var written, unchanged, skipped int
+	for i, d := range dirs {
+		<-done[i]
+		r := results[i]
+		if r.err != nil {
+			return stale, r.err
+		}
+		// The index lists every package but the root one, which the
+		// top-level README.md describes.
+		if r.imported && d.rest != "" {
+			index = append(index, indexEntry{dir: d.rest, doc: r.doc, skipped: r.skipped})
+		}
+		outcome := r.skipped
+		switch {
+		case r.skipped != "":
+			skipped++
+		case r.contents == nil:
+			outcome = "hand-written README.md"
+			skipped++
+		case bytes.Equal(r.exist, r.contents):
+			outcome = "unchanged"
+			unchanged++
+		default:
+			outcome = "written"
+			if check {
+				outcome = "out of date"
+			}
+			written++
+			if err := update(r.path, r.exist, r.contents); err != nil {
+				return stale, err
+			}
+		}
+		if opts.Verbose {
+			log.Printf("%s: %v: %s", path.Join(".", d.rest), r.elapsed.Round(time.Millisecond), outcome)
+		}
+	}
+	if opts.Verbose {
+		verb := "written"
+		if check {
+			verb = "out of date"
+		}
+		log.Printf("README.md files: %d %s, %d unchanged, %d skipped", written, verb, unchanged, skipped)
+	}
+	if sc != nil {
+		return stale, nil
+	}
+
+	indexPath := filepath.Join(root, indexFile)
+	exist, err := os.ReadFile(indexPath)
+	if err != nil && !os.IsNotExist(err) {
+		return stale, err
+	}
+	return stale, update(indexPath, exist, indexContents(index, exist))
+}
+
+// A packageDir is a directory of the tree which may have a package.
+type packageDir struct {
+	dir        string // the directory
+	rest       string // the directory relative to the root of the tree, with slashes, or "" for the root
+	importPath string // the import path of its package
+	modPath    string // the path of the module containing it
+}
+
+// packageDirs returns the directories of the tree rooted at root whose
+// packages may need a README.md file, in lexical order. If sc is non-nil,
+// it only returns those sc includes, and those without a README.md file.
+func packageDirs(root string, sc *Scope) ([]packageDir, error) {
+	var dirs []packageDir
+	mods := make(map[string]string)
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		rest := strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+		switch rest {
+		case "env", "version", "vendor":
+			return filepath.SkipDir
+		}
+		if name := fi.Name(); path != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			// The go command ignores these directories.
+			return filepath.SkipDir
+		}
+		if !sc.includes(filepath.ToSlash(rest)) {
+			if _, err := os.Stat(filepath.Join(path, "README.md")); err == nil {
+				return nil
+			}
+		}
+		pkgPath, modPath, err := importPath(root, path, mods)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, packageDir{dir: path, rest: filepath.ToSlash(rest), importPath: pkgPath, modPath: modPath})
+		return nil
+	})
+	return dirs, err
+}
+
+// A readmeResult is the README.md file generated for a packageDir.
+type readmeResult struct {
+	imported bool   // whether the package was imported
+	doc      string // the package doc synopsis
+	skipped  string // if non-empty, why no README.md file was generated
+	path     string // the path of the README.md file
+	exist    []byte // its existing contents, or nil if it doesn't exist
+	contents []byte // its new contents, or nil if it must be left alone
+	elapsed  time.Duration
+	err      error
+}
+
+// generateReadme imports the package in d with importPkg and generates its
+// README.md file with tmpl, without writing it.
+func generateReadme(d packageDir, tmpl *template.Template, importPkg func(dir, pkgName string) (*build.Package, error)) (r readmeResult) {
+	start := time.Now()
+	defer func() { r.elapsed = time.Since(start) }()
+
+	pkg, err := importPkg(d.dir, d.importPath)
+	if err != nil {
+		r.skipped = fmt.Sprintf("not imported: %v", err)
+		return r
+	}
+	r.imported = true
+	r.doc = pkg.Doc
+	if pkg.Doc == "" {
+		// There's no package comment, so don't create an empty README.
+		r.skipped = "no package comment"
+		return r
+	}
+	if _, err := os.Stat(filepath.Join(pkg.Dir, "README")); err == nil {
+		// Directory has exiting README; don't touch.
+		r.skipped = "has a README file"
+		return r
+	}
+	r.path = filepath.Join(pkg.Dir, "README.md")
+	r.exist, err = os.ReadFile(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		// A real error.
+		r.err = err
+		return r
+	}
+	doc := pkg.Doc
+	if pkg.Name == "main" && wantsUsage(r.exist) {
+		doc, err = commandUsage(pkg)
+		if err != nil {
+			r.err = err
+			return r
+		}
+	}
+	r.contents, err = readmeContents(tmpl, Data{
+		ImportPath: d.importPath,
+		Doc:        doc,
+		Synopsis:   pkg.Doc,
+		Dir:        d.rest,
+		ModulePath: d.modPath,
+	}, r.exist)
+	if err != nil {
+		r.err = fmt.Errorf("%s: %v", r.path, err)
+	}
+	return r
+}
+
+// A Scope is the set of directories of the tree whose packages may have
+// changed. A nil *Scope includes every directory.
+type Scope struct {
+	dirs  map[string]bool // directories with changed files
+	trees []string        // directories with a changed go.mod file, whose subdirectories all may have changed
+}
+
+// newScope returns the scope of the changed files, whose paths are
+// relative to the root of the tree, with slashes.
+func newScope(changed []string) *Scope {
+	sc := &Scope{dirs: make(map[string]bool)}
+	for _, p := range changed {
+		dir := path.Dir(p)
+		if dir == "." {
+			dir = ""
+		}
+		sc.dirs[dir] = true
+		if path.Base(p) == "go.mod" {
+			// The import paths of the packages of the module may
+			// have changed.
+			sc.trees = append(sc.trees, dir)
+		}
+	}
+	return sc
+}
+
+// includes reports whether sc includes dir, which is relative to the root
+// of the tree, with slashes.
+func (sc *Scope) includes(dir string) bool {
+	if sc == nil || sc.dirs[dir] {
+		return true
+	}
+	for _, t := range sc.trees {
+		if t == "" || dir == t || strings.HasPrefix(dir, t+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// SinceScope returns the scope of the changes since the revision rev,
+// which changedPaths lists. If it fails, SinceScope logs a warning and
+// returns nil, for a full update.
+func SinceScope(rev string, changedPaths func(rev string) ([]string, error)) *Scope {
+	changed, err := changedPaths(rev)
+	if err != nil {
+		log.Printf("warning: can't list the changes since %s, updating every README.md file: %v", rev, err)
+		return nil
+	}
+	return newScope(changed)
+}
+
+// GitChangedPaths returns the paths of the files of the git work tree at
+// root which changed since the revision rev, including uncommitted and
+// untracked ones, relative to root, with slashes.
+func GitChangedPaths(root, rev string) ([]string, error) {
+	var changed []string
+	for _, args := range [][]string{
+		{"diff", "-z", "--name-only", "--relative", rev, "--"},
+		{"ls-files", "-z", "--others", "--exclude-standard"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+				return nil, fmt.Errorf("git %s: %v: %s", args[0], err, bytes.TrimSpace(ee.Stderr))
+			}
+			return nil, fmt.Errorf("git %s: %v", args[0], err)
+		}
+		for _, p := range strings.Split(string(out), "\x00") {
+			if p != "" {
+				changed = append(changed, p)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// importPath returns the import path of the package in dir, a directory
+// of the tree rooted at root, and the path of the module containing it,
+// according to the go.mod file of the innermost module containing it,
+// which may be nested in another. mods caches the module paths of the
+// directories, which are empty for those without a go.mod file.
+func importPath(root, dir string, mods map[string]string) (pkgPath, modPath string, err error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		modPath, ok := mods[d]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(d, "go.mod"))
+			if err == nil {
+				modPath = modfile.ModulePath(data)
+				if modPath == "" {
+					return "", "", fmt.Errorf("%s has no module path", filepath.Join(d, "go.mod"))
+				}
+			} else if !os.IsNotExist(err) {
+				return "", "", err
+			}
+			mods[d] = modPath
+		}
+		if modPath != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return "", "", err
+			}
+			return path.Join(modPath, filepath.ToSlash(rel)), modPath, nil
+		}
+		if d == root || filepath.Dir(d) == d {
+			return "", "", fmt.Errorf("%s isn't in a module", dir)
+		}
+	}
+}
+
+// An indexEntry is a package of the index of packages.
+type indexEntry struct {
+	dir     string // relative to the root of the tree, with slashes: "cmd/gomote"
+	doc     string // the package doc synopsis
+	skipped string // why the package has no generated README.md, if it doesn't
+}
+
+// indexContents returns the contents of the index of the packages of
+// entries given its existing contents, which are nil if it doesn't exist.
+// It returns nil if the index wasn't generated by this tool, and so must
+// be left alone.
+//
+// The packages are grouped by the top-level directory they're in, and
+// sorted by directory, so that the index doesn't depend on the order in
+// which the packages were found.
+func indexContents(entries []indexEntry, exist []byte) []byte {
+	if len(exist) > 0 && !bytes.Contains(exist, []byte(header)) {
+		return nil
+	}
+	var footer []byte
+	if i := bytes.Index(exist, []byte(footerMarker)); i != -1 {
+		footer = exist[i:]
+	}
+	entries = append([]indexEntry(nil), entries...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].dir < entries[j].dir
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!-- %s -->\n\n# Packages of golang.org/x/build\n", header)
+	group := ""
+	var skipped []indexEntry
+	for _, e := range entries {
+		if e.skipped != "" {
+			skipped = append(skipped, e)
+			continue
+		}
+		if top, _, _ := strings.Cut(e.dir, "/"); top != group {
+			group = top
+			fmt.Fprintf(&buf, "\n## %s\n\n", group)
+		}
+		fmt.Fprintf(&buf, "- [%s](%s/): %s\n", e.dir, e.dir, e.doc)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&buf, "\n## Undocumented packages\n\n")
+		for _, e := range skipped {
+			fmt.Fprintf(&buf, "- [%s](%s/) (%s)\n", e.dir, e.dir, e.skipped)
+		}
+	}
+	if footer != nil {
+		fmt.Fprintf(&buf, "\n%s", footer)
+	}
+	return buf.Bytes()
+}
+
+// Data is the data the template of the README.md files is executed
+// with.
+type Data struct {
+	ImportPath string // the import path of the package: "golang.org/x/build/cmd/gomote"
+	Doc        string // the package doc synopsis, or, for commands documenting their usage, the Markdown of their package comment and flags
+	Synopsis   string // the package doc synopsis
+	Dir        string // the directory of the package, relative to the root of the tree, with slashes: "cmd/gomote"
+	ModulePath string // the path of the module containing the package: "golang.org/x/build"
+	Footer     string // the text of the existing file from the footerMarker on, if any, which must be preserved
+}
+
+// readmeContents returns the contents of the README.md file of a package
+// generated by tmpl with data, given the file's existing contents, which
+// are nil if it doesn't exist. It sets data.Footer from them, and keeps
+// their hand-written content before the beginMarker, if any. It returns
+// nil if the file wasn't generated by this tool, and so must be left
+// alone.
+func readmeContents(tmpl *template.Template, data Data, exist []byte) ([]byte, error) {
+	if len(exist) > 0 && !bytes.Contains(exist, []byte(header)) {
+		return nil, nil
+	}
+	prefix, footer, err := splitReadme(exist)
+	if err != nil {
+		return nil, err
+	}
+	data.Footer = string(footer)
+	var buf bytes.Buffer
+	if prefix != nil {
+		buf.Write(prefix)
+		buf.WriteByte('\n')
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(header)) {
+		return nil, fmt.Errorf("template %s doesn't output the header %q, so later runs wouldn't update the file", tmpl.Name(), "<!-- "+header+" -->")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(data.Footer)) {
+		return nil, fmt.Errorf("template %s doesn't output the footer of the existing file", tmpl.Name())
+	}
+	return buf.Bytes(), nil
+}
+
+// splitReadme returns the hand-written parts of the generated README.md
+// file with the contents exist: the content up to and including the
+// beginMarker, or nil if there's none, and the content from the
+// footerMarker on, or nil if there's none. It returns an error if the
+// markers are mismatched, rather than guess which content to keep.
+func splitReadme(exist []byte) (prefix, footer []byte, err error) {
+	for _, marker := range []string{beginMarker, footerMarker} {
+		if n := bytes.Count(exist, []byte(marker)); n > 1 {
+			return nil, nil, fmt.Errorf("%q appears %d times", marker, n)
+		}
+	}
+	begin := bytes.Index(exist, []byte(beginMarker))
+	end := bytes.Index(exist, []byte(footerMarker))
+	if end != -1 {
+		footer = exist[end:]
+	}
+	if begin == -1 {
+		return nil, footer, nil
+	}
+	switch {
+	case end == -1:
+		return nil, nil, fmt.Errorf("%q has no matching %q after it", beginMarker, footerMarker)
+	case end < begin:
+		return nil, nil, fmt.Errorf("%q is after %q", beginMarker, footerMarker)
+	}
+	prefix = exist[:begin+len(beginMarker)]
+	if bytes.Contains(prefix, []byte(header)) {
+		return nil, nil, fmt.Errorf("%q is before %q, in the hand-written content", header, beginMarker)
+	}
+	return prefix, footer, nil
+}
+
+// wantsUsage reports whether the README.md file with the contents exist
+// opts in to documenting the usage of its command.
+func wantsUsage(exist []byte) bool {
+	i := bytes.Index(exist, []byte(footerMarker))
+	return i != -1 && bytes.Contains(exist[i:], []byte(usageMarker))
+}
+
+// commandUsage returns the Markdown documenting the usage of the command
+// pkg: its package comment, followed by a table of its flags, if any.
+func commandUsage(pkg *build.Package) (string, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return "", err
+		}
+		files = append(files, f)
+	}
+	// Find the flags first: doc.NewFromFiles modifies the files.
+	flags := commandFlags(fset, files)
+	dpkg, err := doc.NewFromFiles(fset, files, pkg.ImportPath)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	buf.Write(dpkg.Markdown(dpkg.Doc))
+	if len(flags) > 0 {
+		buf.WriteString("\n## Flags\n\n| Flag | Default | Usage |\n| --- | --- | --- |\n")
+		for _, f := range flags {
+			fmt.Fprintf(&buf, "| `-%s` | %s | %s |\n", f.name, markdownCode(f.value), markdownCell(f.usage))
+		}
+	}
+	return strings.Trim(buf.String(), "\n"), nil
+}
+
+// A commandFlag is a flag registered with the flag package.
+type commandFlag struct {
+	name  string
+	value string // the Go expression of the default value, or "" if unknown
+	usage string
+}
+
+// flagFuncs are the functions of the flag package which register flags,
+// with the indexes of the name, the default value and the usage among
+// their arguments. The index of the default value is -1 if they have
+// none.
+var flagFuncs = map[string][3]int{
+	"Bool": {0, 1, 2}, "Duration": {0, 1, 2}, "Float64": {0, 1, 2}, "Int": {0, 1, 2},
+	"Int64": {0, 1, 2}, "String": {0, 1, 2}, "Uint": {0, 1, 2}, "Uint64": {0, 1, 2},
+	"BoolVar": {1, 2, 3}, "DurationVar": {1, 2, 3}, "Float64Var": {1, 2, 3}, "IntVar": {1, 2, 3},
+	"Int64Var": {1, 2, 3}, "StringVar": {1, 2, 3}, "UintVar": {1, 2, 3}, "Uint64Var": {1, 2, 3},
+	"TextVar": {1, 2, 3},
+	"Var":     {1, -1, 2},
+	"Func":    {0, -1, 1}, "BoolFunc": {0, -1, 1},
+}
+
+// commandFlags returns the flags the files register with the flag
+// package, sorted by name. Flags of FlagSets, and flags whose name isn't a
+// string literal, aren't included.
+func commandFlags(fset *token.FileSet, files []*ast.File) []commandFlag {
+	var flags []commandFlag
+	for _, f := range files {
+		flagPkg := ""
+		for _, imp := range f.Imports {
+			if imp.Path.Value == `"flag"` {
+				flagPkg = "flag"
+				if imp.Name != nil {
+					flagPkg = imp.Name.Name
+				}
+			}
+		}
+		if flagPkg == "" {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if x, ok := sel.X.(*ast.Ident); !ok || x.Name != flagPkg {
+				return true
+			}
+			args, ok := flagFuncs[sel.Sel.Name]
+			if !ok || len(call.Args) <= args[2] {
+				return true
+			}
+			name, ok := stringLit(call.Args[args[0]])
+			if !ok {
+				return true
+			}
+			cf := commandFlag{name: name}
+			if args[1] >= 0 {
+				cf.value = exprString(fset, call.Args[args[1]])
+			}
+			if usage, ok := stringLit(call.Args[args[2]]); ok {
+				cf.usage = usage
+			} else {
+				cf.usage = exprString(fset, call.Args[args[2]])
+			}
+			flags = append(flags, cf)
+			return true
+		})
+	}
+	sort.SliceStable(flags, func(i, j int) bool {
+		return flags[i].name < flags[j].name
+	})
+	return flags
+}
+
+// stringLit returns the value of e if it's a string literal.
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// exprString returns the Go source of e.
+func exprString(fset *token.FileSet, e ast.Expr) string {
+	var buf strings.Builder
+	printer.Fprint(&buf, fset, e)
+	return buf.String()
+}
+
+// markdownCode formats s as inline code in a Markdown table cell, or as
+// nothing if s is empty.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// markdownCell escapes s for a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// lineDiff returns a unified-diff-style description of the changes from
+// old, named oldName, to new, named newName. It has a single hunk, which
+// includes all the lines of both.
+func lineDiff(oldName, newName string, old, new []byte) string {
+	a, b := splitLines(old), splitLines(new)
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n@@ -%s +%s @@\n", oldName, newName, hunkRange(len(a)), hunkRange(len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&buf, " %s\n", a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&buf, "-%s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&buf, "+%s\n", b[j])
+			j++
+		}
+	}
+	return buf.String()
+}
+
+// splitLines splits b into lines, without their line terminators.
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+// hunkRange formats the range of a hunk of n lines starting at the first
+// line, as in a unified diff.
+func hunkRange(n int) string {
+	if n == 0 {
+		return "0,0"
+	}
+	return fmt.Sprintf("1,%d", n)
+}
@@ -0,0 +1,700 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readmes
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// importDir imports the package in dir, regardless of its import path,
+// so that the tests don't depend on the module of the tree.
+func importDir(dir, pkgName string) (*build.Package, error) {
+	return build.ImportDir(dir, 0)
+}
+
+// testTemplate returns the default template of the README.md files.
+func testTemplate(t *testing.T) *template.Template {
+	t.Helper()
+	tmpl, err := LoadTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+// defaultReadme returns the README.md file the default template generates
+// for the package importPath with doc, given the file's existing contents.
+func defaultReadme(t *testing.T, importPath, doc string, exist []byte) string {
+	t.Helper()
+	b, err := readmeContents(testTemplate(t), Data{ImportPath: importPath, Doc: doc}, exist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestUpdateReadmes(t *testing.T) {
+	root := t.TempDir()
+	const footer = "<!-- End of auto-generated section -->\n\nHand-written notes.\n"
+	files := map[string]string{
+		"go.mod":                "module golang.org/x/build\n",
+		"missing/doc.go":        "// Package missing has no README.md yet.\npackage missing\n",
+		"stale/doc.go":          "// Package stale has a new package comment.\npackage stale\n",
+		"stale/README.md":       defaultReadme(t, "golang.org/x/build/stale", "Package stale has an old package comment.", nil) + footer,
+		"current/doc.go":        "// Package current has an up to date README.md.\npackage current\n",
+		"current/README.md":     defaultReadme(t, "golang.org/x/build/current", "Package current has an up to date README.md.", nil),
+		"handwritten/doc.go":    "// Package handwritten has a hand-written README.md.\npackage handwritten\n",
+		"handwritten/README.md": "# Hand-written\n",
+		"legacy/doc.go":         "// Package legacy has a README.\npackage legacy\n",
+		"legacy/README":         "Legacy README.\n",
+		"nodoc/nodoc.go":        "package nodoc\n",
+		"vendor/v/doc.go":       "// Package v is vendored.\npackage v\n",
+		"cmd/b/main.go":         "// Command b is a command.\npackage main\n",
+		"cmd/a/main.go":         "// Command a is another command.\npackage main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexPath := filepath.Join(root, "PACKAGES.md")
+	missingPath := filepath.Join(root, "missing", "README.md")
+	stalePath := filepath.Join(root, "stale", "README.md")
+	wantStale := []string{
+		indexPath,
+		filepath.Join(root, "cmd", "a", "README.md"),
+		filepath.Join(root, "cmd", "b", "README.md"),
+		missingPath,
+		stalePath,
+	}
+	// snapshot returns the contents of the files of the tree.
+	snapshot := func() map[string]string {
+		m := make(map[string]string)
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() {
+				return err
+			}
+			b, err := os.ReadFile(path)
+			m[path] = string(b)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	// Check mode reports the missing and stale files, without
+	// touching anything.
+	before := snapshot()
+	var out strings.Builder
+	stale, err := Update(root, Options{Template: testTemplate(t), Import: importDir, Check: true}, &out)
+	if err != nil {
+		t.Fatalf("Update in check mode: %v", err)
+	}
+	slices.Sort(stale)
+	if !slices.Equal(stale, wantStale) {
+		t.Errorf("Update in check mode = %q; want %q", stale, wantStale)
+	}
+	if after := snapshot(); !maps.Equal(before, after) {
+		t.Errorf("Update in check mode modified the tree")
+	}
+	for _, want := range []string{
+		"--- /dev/null\n+++ " + missingPath + "\n",
+		"+Package missing has no README.md yet.\n",
+		"--- " + stalePath + "\n+++ " + stalePath + "\n",
+		"-Package stale has an old package comment.\n+Package stale has a new package comment.\n",
+		" Hand-written notes.\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Update in check mode output is missing %q:\n%s", want, out.String())
+		}
+	}
+	for _, unwanted := range []string{"current", "handwritten", "legacy", "nodoc", "vendor"} {
+		if strings.Contains(out.String(), string(filepath.Separator)+unwanted+string(filepath.Separator)) {
+			t.Errorf("Update in check mode reported a file in %s:\n%s", unwanted, out.String())
+		}
+	}
+
+	// Write mode updates the same files check mode reported.
+	out.Reset()
+	stale, err = Update(root, Options{Template: testTemplate(t), Import: importDir}, &out)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	slices.Sort(stale)
+	if !slices.Equal(stale, wantStale) {
+		t.Errorf("Update = %q; want %q", stale, wantStale)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Update wrote a diff in write mode:\n%s", out.String())
+	}
+	after := snapshot()
+	for path, content := range before {
+		if !slices.Contains(wantStale, path) && after[path] != content {
+			t.Errorf("Update modified %s", path)
+		}
+	}
+	if got := after[missingPath]; !strings.Contains(got, "Package missing has no README.md yet.") {
+		t.Errorf("Update created %s as:\n%s", missingPath, got)
+	}
+	if got := after[stalePath]; !strings.Contains(got, "Package stale has a new package comment.") || !strings.HasSuffix(got, footer) {
+		t.Errorf("Update updated %s as:\n%s\nwant the new package comment and the hand-written footer", stalePath, got)
+	}
+	wantIndex := `<!-- Auto-generated by x/build/update-readmes.go -->
+
+# Packages of golang.org/x/build
+
+## cmd
+
+- [cmd/a](cmd/a/): Command a is another command.
+- [cmd/b](cmd/b/): Command b is a command.
+
+## current
+
+- [current](current/): Package current has an up to date README.md.
+
+## handwritten
+
+- [handwritten](handwritten/): Package handwritten has a hand-written README.md.
+
+## missing
+
+- [missing](missing/): Package missing has no README.md yet.
+
+## stale
+
+- [stale](stale/): Package stale has a new package comment.
+
+## Undocumented packages
+
+- [legacy](legacy/) (has a README file)
+- [nodoc](nodoc/) (no package comment)
+`
+	if got := after[indexPath]; got != wantIndex {
+		t.Errorf("Update created %s as:\n%s\nwant:\n%s", indexPath, got, wantIndex)
+	}
+
+	// Once written, the tree passes the check.
+	out.Reset()
+	stale, err = Update(root, Options{Template: testTemplate(t), Import: importDir, Check: true}, &out)
+	if err != nil || len(stale) != 0 || out.Len() != 0 {
+		t.Errorf("Update in check mode after writing = %q, %v, with output:\n%s\nwant nothing stale", stale, err, out.String())
+	}
+
+	// A hand-written index is left alone, and the text after the end
+	// marker of a generated one is kept.
+	for _, index := range []string{
+		"# Hand-written index\n",
+		"<!-- Auto-generated by x/build/update-readmes.go -->\n\nOld index.\n" + footer,
+	} {
+		if err := os.WriteFile(indexPath, []byte(index), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Update(root, Options{Template: testTemplate(t), Import: importDir}, &out); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := os.ReadFile(indexPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := index
+		if strings.HasPrefix(index, "<!--") {
+			want = wantIndex + "\n" + footer
+		}
+		if string(got) != want {
+			t.Errorf("Update updated the index\n%s\nas:\n%s\nwant:\n%s", index, got, want)
+		}
+	}
+}
+
+func TestUpdateReadmesNestedModule(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                     "module golang.org/x/build\n",
+		"top/doc.go":                 "// Package top is in the main module.\npackage top\n",
+		"nested/go.mod":              "module example.com/nested\n\ngo 1.21\n",
+		"nested/doc.go":              "// Package nested is the root of a nested module.\npackage nested\n",
+		"nested/sub/doc.go":          "// Package sub is in a nested module.\npackage sub\n",
+		"nested/testdata/p/doc.go":   "// Package p is test data.\npackage p\n",
+		"top/_ignored/doc.go":        "// Package ignored is ignored.\npackage ignored\n",
+		"top/.hidden/doc.go":         "// Package hidden is ignored.\npackage hidden\n",
+		"top/testdata/doc.go":        "// Package testdata is test data.\npackage testdata\n",
+		"nested/sub/testdata/doc.go": "// Package testdata is test data.\npackage testdata\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out strings.Builder
+	stale, err := Update(root, Options{Template: testTemplate(t), Import: importDir}, &out)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	for dir, want := range map[string]string{
+		"top":        "golang.org/x/build/top",
+		"nested":     "example.com/nested",
+		"nested/sub": "example.com/nested/sub",
+	} {
+		got, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "README.md"))
+		if err != nil {
+			t.Errorf("README.md of %s: %v", dir, err)
+			continue
+		}
+		for _, link := range []string{"(https://pkg.go.dev/" + want + ")", "# " + want + "\n"} {
+			if !strings.Contains(string(got), link) {
+				t.Errorf("README.md of %s is missing %q:\n%s", dir, link, got)
+			}
+		}
+	}
+	for _, path := range stale {
+		if strings.Contains(path, "testdata") || strings.Contains(path, "_ignored") || strings.Contains(path, ".hidden") {
+			t.Errorf("Update wrote %s in an ignored directory", path)
+		}
+	}
+}
+
+func TestUpdateReadmesUsage(t *testing.T) {
+	root := t.TempDir()
+	// optIn is a generated README.md opting in to documenting the usage
+	// of its command.
+	optIn := func(pkgName string) string {
+		return defaultReadme(t, pkgName, "Old synopsis.", nil) + "<!-- End of auto-generated section -->\n\n<!-- usage -->\n"
+	}
+	files := map[string]string{
+		"go.mod": "module golang.org/x/build\n",
+		"cmd/usage/doc.go": `// Command usage has a long package comment.
+//
+// It's all included.
+package main
+`,
+		"cmd/usage/main.go": `package main
+
+import (
+	"flag"
+	"time"
+)
+
+var (
+	verbose = flag.Bool("v", false, "verbose output")
+	timeout time.Duration
+	set     = flag.NewFlagSet("other", flag.ExitOnError)
+)
+
+func init() {
+	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "how long to wait | or not")
+	flag.Func("f", "a func flag", func(string) error { return nil })
+	set.Bool("ignored", true, "a flag of another FlagSet")
+}
+
+func main() {}
+`,
+		"cmd/usage/README.md":  optIn("golang.org/x/build/cmd/usage"),
+		"cmd/synopsis/main.go": "// Command synopsis isn't opted in.\n//\n// This isn't included.\npackage main\n\nimport \"flag\"\n\nvar v = flag.Bool(\"v\", false, \"verbose output\")\n",
+		"lib/doc.go":           "// Package lib isn't a command.\n//\n// This isn't included.\npackage lib\n",
+		"lib/README.md":        optIn("golang.org/x/build/lib"),
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out strings.Builder
+	if _, err := Update(root, Options{Template: testTemplate(t), Import: importDir}, &out); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	readme := func(dir string) string {
+		b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "README.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	want := defaultReadme(t, "golang.org/x/build/cmd/usage", `Command usage has a long package comment.
+
+It's all included.
+
+## Flags
+
+| Flag | Default | Usage |
+| --- | --- | --- |
+| `+"`-f`"+` |  | a func flag |
+| `+"`-timeout`"+` | `+"`5 * time.Minute`"+` | how long to wait \| or not |
+| `+"`-v`"+` | `+"`false`"+` | verbose output |`, []byte(optIn("golang.org/x/build/cmd/usage")))
+	if got := readme("cmd/usage"); got != want {
+		t.Errorf("README.md of an opted-in command =\n%s\nwant:\n%s", got, want)
+	}
+	for _, dir := range []string{"cmd/synopsis", "lib"} {
+		if got := readme(dir); strings.Contains(got, "This isn't included.") || strings.Contains(got, "## Flags") {
+			t.Errorf("README.md of %s documents its usage:\n%s", dir, got)
+		}
+	}
+
+	// The generated usage is stable.
+	stale, err := Update(root, Options{Template: testTemplate(t), Import: importDir, Check: true}, &out)
+	if err != nil || len(stale) != 0 {
+		t.Errorf("Update in check mode after writing = %q, %v, with output:\n%s\nwant nothing stale", stale, err, out.String())
+	}
+}
+
+func TestUpdateReadmesSince(t *testing.T) {
+	root := t.TempDir()
+	generated := func(pkgName string) string {
+		return defaultReadme(t, pkgName, "Old synopsis.", nil)
+	}
+	files := map[string]string{
+		"go.mod":              "module golang.org/x/build\n",
+		"changed/doc.go":      "// Package changed has changed.\npackage changed\n",
+		"changed/README.md":   generated("golang.org/x/build/changed"),
+		"unchanged/doc.go":    "// Package unchanged hasn't changed.\npackage unchanged\n",
+		"unchanged/README.md": generated("golang.org/x/build/unchanged"),
+		"missing/doc.go":      "// Package missing has no README.md.\npackage missing\n",
+		"mod/go.mod":          "module example.com/mod\n",
+		"mod/sub/doc.go":      "// Package sub is in a module whose path changed.\npackage sub\n",
+		"mod/sub/README.md":   generated("example.com/old/sub"),
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// importPkg records the directories it imports. Update
+	// calls it concurrently.
+	var (
+		mu       sync.Mutex
+		imported []string
+	)
+	importPkg := func(dir, pkgName string) (*build.Package, error) {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		imported = append(imported, filepath.ToSlash(rel))
+		mu.Unlock()
+		return importDir(dir, pkgName)
+	}
+	sc := SinceScope("HEAD~1", func(rev string) ([]string, error) {
+		return []string{"changed/doc.go", "mod/go.mod", "deleted/doc.go"}, nil
+	})
+	var out strings.Builder
+	stale, err := Update(root, Options{Template: testTemplate(t), Import: importPkg, Scope: sc}, &out)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	slices.Sort(imported)
+	if want := []string{".", "changed", "missing", "mod", "mod/sub"}; !slices.Equal(imported, want) {
+		t.Errorf("Update imported %q; want %q", imported, want)
+	}
+	slices.Sort(stale)
+	wantStale := []string{
+		filepath.Join(root, "changed", "README.md"),
+		filepath.Join(root, "missing", "README.md"),
+		filepath.Join(root, "mod", "sub", "README.md"),
+	}
+	if !slices.Equal(stale, wantStale) {
+		t.Errorf("Update = %q; want %q", stale, wantStale)
+	}
+	if _, err := os.Stat(filepath.Join(root, "PACKAGES.md")); err == nil {
+		t.Errorf("Update wrote the index of packages with a scope")
+	}
+
+	// Without the changes, every package is imported.
+	sc = SinceScope("bad-rev", func(rev string) ([]string, error) {
+		return nil, errors.New("unknown revision")
+	})
+	if sc != nil {
+		t.Fatalf("SinceScope with an error = %+v; want nil", sc)
+	}
+	imported = nil
+	if _, err := Update(root, Options{Template: testTemplate(t), Import: importPkg, Scope: sc}, &out); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !slices.Contains(imported, "unchanged") {
+		t.Errorf("Update without a scope imported %q; want all packages", imported)
+	}
+}
+
+func TestGitChangedPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=gopher", "GIT_AUTHOR_EMAIL=gopher@golang.org", "GIT_COMMITTER_NAME=gopher", "GIT_COMMITTER_EMAIL=gopher@golang.org")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("old/a.go")
+	write("committed/a.go")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	write("committed/a.go")
+	write("committed/b.go")
+	git("add", ".")
+	git("commit", "-q", "-m", "second")
+	write("uncommitted/a.go")
+	git("add", ".")
+	write("untracked dir/a.go")
+
+	got, err := GitChangedPaths(root, "HEAD~1")
+	if err != nil {
+		t.Fatalf("GitChangedPaths: %v", err)
+	}
+	slices.Sort(got)
+	if want := []string{"committed/b.go", "uncommitted/a.go", "untracked dir/a.go"}; !slices.Equal(got, want) {
+		t.Errorf("GitChangedPaths = %q; want %q", got, want)
+	}
+	if got, err := GitChangedPaths(root, "no-such-rev"); err == nil {
+		t.Errorf("GitChangedPaths with an unknown revision = %q; want error", got)
+	}
+}
+
+func TestUpdateReadmesError(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":        "module golang.org/x/build\n",
+		"bad/doc.go":    "// Package bad has a malformed README.md.\npackage bad\n",
+		"bad/README.md": "<!-- Begin of auto-generated section -->\n<!-- Auto-generated by x/build/update-readmes.go -->\n",
+	}
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("p%d/doc.go", i)] = fmt.Sprintf("// Package p%d is fine.\npackage p%d\n", i, i)
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out strings.Builder
+	stale, err := Update(root, Options{Template: testTemplate(t), Import: importDir, Check: true}, &out)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(root, "bad", "README.md")) {
+		t.Errorf("Update with a malformed README.md = %q, %v; want an error about it", stale, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "PACKAGES.md")); err == nil {
+		t.Errorf("Update wrote the index of packages despite the error")
+	}
+}
+
+func TestReadmeTemplate(t *testing.T) {
+	data := Data{
+		ImportPath: "example.com/mod/cmd/tool",
+		Doc:        "Command tool is a tool.",
+		Synopsis:   "Command tool is a tool.",
+		Dir:        "cmd/tool",
+		ModulePath: "example.com/mod",
+	}
+	const footer = "<!-- End of auto-generated section -->\n\nHand-written notes.\n"
+	exist := []byte("<!-- Auto-generated by x/build/update-readmes.go -->\n\nOld.\n" + footer)
+
+	// The default template generates the same files as before it could
+	// be changed.
+	want := `<!-- Auto-generated by x/build/update-readmes.go -->
+
+[![Go Reference](https://pkg.go.dev/badge/example.com/mod/cmd/tool.svg)](https://pkg.go.dev/example.com/mod/cmd/tool)
+
+# example.com/mod/cmd/tool
+
+Command tool is a tool.
+` + footer
+	if got := defaultReadme(t, data.ImportPath, data.Doc, exist); got != want {
+		t.Errorf("default template output =\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		tmpl    string
+		want    string
+		wantErr string
+	}{
+		{
+			desc: "custom",
+			tmpl: "<!-- Auto-generated by x/build/update-readmes.go -->\n# {{.Dir}} in {{.ModulePath}}\n\n{{.Synopsis}} See {{.ImportPath}}.\n{{.Footer}}",
+			want: "<!-- Auto-generated by x/build/update-readmes.go -->\n# cmd/tool in example.com/mod\n\nCommand tool is a tool. See example.com/mod/cmd/tool.\n" + footer,
+		},
+		{
+			desc:    "no header",
+			tmpl:    "# {{.ImportPath}}\n{{.Footer}}",
+			wantErr: "header",
+		},
+		{
+			desc:    "no footer",
+			tmpl:    "<!-- Auto-generated by x/build/update-readmes.go -->\n# {{.ImportPath}}\n",
+			wantErr: "footer",
+		},
+		{
+			desc:    "bad field",
+			tmpl:    "<!-- Auto-generated by x/build/update-readmes.go -->\n{{.NoSuchField}}",
+			wantErr: "NoSuchField",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "README.tmpl")
+			if err := os.WriteFile(file, []byte(tc.tmpl), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tmpl, err := LoadTemplate(file)
+			if err != nil {
+				t.Fatalf("LoadTemplate: %v", err)
+			}
+			got, err := readmeContents(tmpl, data, exist)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("readmeContents = %q, %v; want error containing %q", got, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != tc.want {
+				t.Errorf("readmeContents = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadmeRegions(t *testing.T) {
+	const (
+		hdr    = "<!-- Auto-generated by x/build/update-readmes.go -->\n"
+		begin  = "<!-- Begin of auto-generated section -->\n"
+		end    = "<!-- End of auto-generated section -->\n"
+		intro  = "# Intro\n\nHand-written intro.\n\n"
+		notes  = "\nHand-written notes.\n"
+		pkg    = "golang.org/x/build/p"
+		newDoc = "Package p is new."
+	)
+	generated := defaultReadme(t, pkg, newDoc, nil)
+	for _, tc := range []struct {
+		desc    string
+		exist   string
+		want    string
+		wantErr string
+	}{
+		{
+			desc:  "no markers",
+			exist: hdr + "\nOld.\n",
+			want:  generated,
+		},
+		{
+			desc:  "end marker",
+			exist: hdr + "\nOld.\n" + end + notes,
+			want:  generated + end + notes,
+		},
+		{
+			desc:  "begin and end markers",
+			exist: intro + begin + hdr + "\nOld.\n" + end + notes,
+			want:  intro + begin + generated + end + notes,
+		},
+		{
+			desc:  "hand-written",
+			exist: intro + begin + "Old.\n" + end,
+			want:  "",
+		},
+		{
+			desc:    "begin marker without end marker",
+			exist:   intro + begin + hdr + "\nOld.\n",
+			wantErr: "no matching",
+		},
+		{
+			desc:    "end marker before begin marker",
+			exist:   hdr + end + intro + begin + "\nOld.\n",
+			wantErr: "after",
+		},
+		{
+			desc:    "two begin markers",
+			exist:   begin + intro + begin + hdr + "\nOld.\n" + end,
+			wantErr: "2 times",
+		},
+		{
+			desc:    "two end markers",
+			exist:   hdr + "\nOld.\n" + end + notes + end,
+			wantErr: "2 times",
+		},
+		{
+			desc:    "header before begin marker",
+			exist:   hdr + intro + begin + "\nOld.\n" + end,
+			wantErr: "hand-written",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := readmeContents(testTemplate(t), Data{ImportPath: pkg, Doc: newDoc}, []byte(tc.exist))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("readmeContents(%q) = %q, %v; want error containing %q", tc.exist, got, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != tc.want {
+				t.Fatalf("readmeContents(%q) = %q, %v; want %q", tc.exist, got, err, tc.want)
+			}
+			if got == nil {
+				return
+			}
+			// Regenerating the file doesn't change it.
+			again, err := readmeContents(testTemplate(t), Data{ImportPath: pkg, Doc: newDoc}, got)
+			if err != nil || string(again) != string(got) {
+				t.Errorf("readmeContents(%q) = %q, %v; want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestImportPathNotInModule(t *testing.T) {
+	root := t.TempDir()
+	if got, _, err := importPath(root, root, make(map[string]string)); err == nil {
+		t.Errorf("importPath of a tree without go.mod = %q; want error", got)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("a", "b", []byte("one\ntwo\nthree\n"), []byte("one\n2\nthree\nfour\n"))
+	want := "--- a\n+++ b\n@@ -1,3 +1,4 @@\n one\n-two\n+2\n three\n+four\n"
+	if got != want {
+		t.Errorf("lineDiff =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wstunnel tunnels HTTP/2, and so gRPC, over a WebSocket.
+//
+// It is meant for clients on networks which only allow HTTPS through
+// a proxy, and so can't reach a gRPC server directly. The client side
+// of a tunnel is a net.Conn obtained from a Dialer; the server side is
+// an http.Handler which can be served alongside other handlers on an
+// existing HTTPS listener.
+package wstunnel
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc/credentials"
+)
+
+// Path is the path at which servers accept tunnels.
+const Path = "/tunnel"
+
+// A Dialer opens tunnels.
+type Dialer struct {
+	// Header is sent with the WebSocket upgrade request. It typically
+	// carries the credentials needed to pass through a proxy in front
+	// of the server.
+	Header http.Header
+
+	// TLSConfig is used for wss URLs. If nil, the default
+	// configuration is used.
+	TLSConfig *tls.Config
+
+	// Proxy returns the proxy to use for a request, as in
+	// http.Transport.Proxy. If nil, http.ProxyFromEnvironment is used.
+	// Credentials in the proxy URL are sent to the proxy using basic
+	// authentication.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// NetDial dials the TCP connections to the server or proxy, such
+	// as through a SOCKS proxy. If nil, net.Dialer.DialContext is used.
+	NetDial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// DialContext opens a tunnel to rawURL, a ws or wss URL. The returned
+// connection carries a byte stream in both directions and is not tied
+// to ctx once DialContext returns.
+func (d *Dialer) DialContext(ctx context.Context, rawURL string) (net.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var scheme, port string
+	switch u.Scheme {
+	case "ws":
+		scheme, port = "http", "80"
+	case "wss":
+		scheme, port = "https", "443"
+	default:
+		return nil, fmt.Errorf("wstunnel: unsupported URL scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	proxy := d.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	proxyURL, err := proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: u.Host}})
+	if err != nil {
+		return nil, fmt.Errorf("wstunnel: finding proxy: %w", err)
+	}
+	netDial := d.NetDial
+	if netDial == nil {
+		netDial = new(net.Dialer).DialContext
+	}
+	var conn net.Conn
+	if proxyURL != nil {
+		conn, err = dialProxy(ctx, netDial, proxyURL, addr)
+	} else {
+		conn, err = netDial(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The WebSocket handshake can't be canceled, so interrupt it by
+	// closing the connection.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	ws, err := d.handshake(ctx, conn, u)
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("wstunnel: opening tunnel to %s: %w", u.Redacted(), err)
+	}
+	return ws, nil
+}
+
+func (d *Dialer) handshake(ctx context.Context, conn net.Conn, u *url.URL) (*websocket.Conn, error) {
+	origin := &url.URL{Scheme: "http", Host: u.Host}
+	if u.Scheme == "wss" {
+		origin.Scheme = "https"
+		cfg := d.TLSConfig.Clone()
+		if cfg == nil {
+			cfg = new(tls.Config)
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tc := tls.Client(conn, cfg)
+		if err := tc.HandshakeContext(ctx); err != nil {
+			return nil, err
+		}
+		conn = tc
+	}
+	config, err := websocket.NewConfig(u.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Header = d.Header.Clone()
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
+
+// dialProxy connects to addr through the HTTP proxy at proxyURL, which
+// it dials with netDial.
+func dialProxy(ctx context.Context, netDial func(ctx context.Context, network, addr string) (net.Conn, error), proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := netDial(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	conn, err = connect(ctx, conn, proxyURL, addr)
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("wstunnel: connecting through proxy %s: %w", proxyURL.Redacted(), err)
+	}
+	return conn, nil
+}
+
+// connect asks the proxy at the other end of conn to connect to addr.
+func connect(ctx context.Context, conn net.Conn, proxyURL *url.URL, addr string) (net.Conn, error) {
+	if proxyURL.Scheme == "https" {
+		tc := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			return conn, err
+		}
+		conn = tc
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u := proxyURL.User; u != nil {
+		password, _ := u.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(u.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		return conn, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return conn, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return conn, fmt.Errorf("proxy responded %s", resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return conn, fmt.Errorf("proxy sent unexpected data after its response")
+	}
+	return conn, nil
+}
+
+// Handler returns a handler which accepts tunnels and serves HTTP/2
+// requests sent through them with h. The values of the forward
+// headers in the WebSocket upgrade request replace those of every
+// request sent through the tunnel, so that headers set by a proxy in
+// front of the server, such as identity assertions, can't be forged.
+func Handler(h http.Handler, forward ...string) http.Handler {
+	ws := websocket.Server{
+		// Tunnel clients aren't browsers, so don't check the Origin.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.PayloadType = websocket.BinaryFrame
+			up := ws.Request()
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for _, k := range forward {
+					k = http.CanonicalHeaderKey(k)
+					delete(r.Header, k)
+					if v := up.Header.Values(k); len(v) > 0 {
+						r.Header[k] = v
+					}
+				}
+				h.ServeHTTP(w, r)
+			})
+			conn := serverConn{Conn: ws, remote: addr(up.RemoteAddr)}
+			if a, ok := up.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+				conn.local = addr(a.String())
+			}
+			new(http2.Server).ServeConn(conn, &http2.ServeConnOpts{
+				Context: up.Context(),
+				Handler: inner,
+			})
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 1 {
+			// Connections can only be hijacked from HTTP/1.
+			http.Error(w, "WebSocket upgrade requires HTTP/1.1", http.StatusHTTPVersionNotSupported)
+			return
+		}
+		ws.ServeHTTP(w, r)
+	})
+}
+
+// serverConn is the server side of a tunnel. On the server side, a
+// websocket.Conn's addresses are WebSocket origins, which are not set
+// as the Origin isn't checked, so serverConn reports the addresses of
+// the HTTP connection carrying the tunnel instead.
+type serverConn struct {
+	*websocket.Conn
+	local, remote addr
+}
+
+func (c serverConn) LocalAddr() net.Addr  { return c.local }
+func (c serverConn) RemoteAddr() net.Addr { return c.remote }
+
+// addr is the address of an HTTP connection carrying a tunnel.
+type addr string
+
+func (addr) Network() string  { return "tcp" }
+func (a addr) String() string { return string(a) }
+
+// TransportCredentials returns gRPC transport credentials for
+// connections made through tunnels. They don't secure the connection
+// themselves; the tunnel must be a wss one.
+func TransportCredentials() credentials.TransportCredentials {
+	return tunnelCreds{}
+}
+
+type tunnelCreds struct{}
+
+type tunnelAuthInfo struct {
+	credentials.CommonAuthInfo
+}
+
+func (tunnelAuthInfo) AuthType() string { return "wstunnel" }
+
+func (tunnelCreds) ClientHandshake(_ context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, tunnelAuthInfo{credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}, nil
+}
+
+func (tunnelCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, tunnelAuthInfo{credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}, nil
+}
+
+func (tunnelCreds) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "wstunnel"}
+}
+
+func (c tunnelCreds) Clone() credentials.TransportCredentials { return c }
+
+func (tunnelCreds) OverrideServerName(string) error { return nil }
@@ -0,0 +1,247 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wstunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+const userHeader = "X-Test-User"
+
+// startServer starts an HTTPS server accepting tunnels to a gRPC
+// server offering the health service. Each RPC records the value of
+// userHeader it was sent with in users.
+func startServer(t *testing.T) (ts *httptest.Server, hs *health.Server, users chan string) {
+	users = make(chan string, 10)
+	record := func(ctx context.Context) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		users <- strings.Join(md.Get(userHeader), ",")
+	}
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			record(ctx)
+			return h(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			record(ss.Context())
+			return h(srv, ss)
+		}),
+	)
+	hs = health.NewServer()
+	healthpb.RegisterHealthServer(gs, hs)
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler(gs, userHeader))
+	ts = httptest.NewTLSServer(mux)
+	t.Cleanup(func() {
+		ts.Close()
+		gs.Stop()
+	})
+	return ts, hs, users
+}
+
+// dialGRPC dials the gRPC server behind ts through d.
+func dialGRPC(t *testing.T, ts *httptest.Server, d *Dialer) *grpc.ClientConn {
+	d.TLSConfig = ts.Client().Transport.(*http.Transport).TLSClientConfig
+	u, _ := url.Parse(ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cc, err := grpc.DialContext(ctx, u.Host,
+		grpc.WithTransportCredentials(TransportCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return d.DialContext(ctx, "wss://"+addr+Path)
+		}),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		t.Fatalf("grpc.DialContext: %v", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+func TestTunnel(t *testing.T) {
+	ts, hs, users := startServer(t)
+	cc := dialGRPC(t, ts, &Dialer{
+		Header: http.Header{userHeader: {"gopher"}},
+		Proxy:  func(*http.Request) (*url.URL, error) { return nil, nil },
+	})
+	client := healthpb.NewHealthClient(cc)
+
+	// The header sent through the tunnel is replaced by the one sent
+	// with the upgrade request.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), userHeader, "forged")
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Check = %v; want SERVING", resp.GetStatus())
+	}
+	if got := <-users; got != "gopher" {
+		t.Errorf("server saw user %q; want %q", got, "gopher")
+	}
+
+	// Server streams are delivered as they are sent.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "svc"})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	for _, want := range []healthpb.HealthCheckResponse_ServingStatus{
+		healthpb.HealthCheckResponse_SERVICE_UNKNOWN,
+		healthpb.HealthCheckResponse_SERVING,
+		healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		hs.SetServingStatus("svc", want)
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if resp.GetStatus() != want {
+			t.Errorf("Recv = %v; want %v", resp.GetStatus(), want)
+		}
+	}
+}
+
+// connectProxy is an HTTP proxy which only accepts CONNECT requests.
+type connectProxy struct {
+	mu    sync.Mutex
+	auths []string
+}
+
+func (p *connectProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.auths = append(p.auths, r.Header.Get("Proxy-Authorization"))
+	p.mu.Unlock()
+	if r.Method != http.MethodConnect {
+		http.Error(w, "only CONNECT is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	backend, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	conn, brw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		backend.Close()
+		return
+	}
+	io.WriteString(conn, "HTTP/1.1 200 OK\r\n\r\n")
+	go func() {
+		io.Copy(backend, brw)
+		backend.Close()
+	}()
+	io.Copy(conn, backend)
+	conn.Close()
+}
+
+func TestTunnelThroughProxy(t *testing.T) {
+	ts, _, users := startServer(t)
+	p := new(connectProxy)
+	ps := httptest.NewServer(p)
+	defer ps.Close()
+	proxyURL, _ := url.Parse(ps.URL)
+	proxyURL.User = url.UserPassword("user", "secret")
+
+	cc := dialGRPC(t, ts, &Dialer{
+		Header: http.Header{userHeader: {"gopher"}},
+		Proxy:  http.ProxyURL(proxyURL),
+	})
+	if _, err := healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if got := <-users; got != "gopher" {
+		t.Errorf("server saw user %q; want %q", got, "gopher")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.auths) == 0 || p.auths[0] != "Basic dXNlcjpzZWNyZXQ=" {
+		t.Errorf("proxy saw authorizations %q; want basic authorization for user:secret", p.auths)
+	}
+}
+
+func TestTunnelNetDial(t *testing.T) {
+	ts, _, users := startServer(t)
+	ps := httptest.NewServer(new(connectProxy))
+	defer ps.Close()
+	proxyURL, _ := url.Parse(ps.URL)
+
+	// NetDial dials the proxy, not the server.
+	var dialed []string
+	var mu sync.Mutex
+	cc := dialGRPC(t, ts, &Dialer{
+		Header: http.Header{userHeader: {"gopher"}},
+		Proxy:  http.ProxyURL(proxyURL),
+		NetDial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, addr)
+			mu.Unlock()
+			return new(net.Dialer).DialContext(ctx, network, addr)
+		},
+	})
+	if _, err := healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	<-users
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) == 0 || dialed[0] != proxyURL.Host {
+		t.Errorf("NetDial dialed %q; want the proxy %s", dialed, proxyURL.Host)
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+	d := &Dialer{
+		TLSConfig: ts.Client().Transport.(*http.Transport).TLSClientConfig,
+		Proxy:     func(*http.Request) (*url.URL, error) { return nil, nil },
+	}
+	ctx := context.Background()
+	if _, err := d.DialContext(ctx, "https://"+u.Host+Path); err == nil {
+		t.Errorf("DialContext(https URL) succeeded; want error")
+	}
+	if _, err := d.DialContext(ctx, "wss://"+u.Host+Path); err == nil {
+		t.Errorf("DialContext(server without tunnels) succeeded; want error")
+	}
+
+	// A server which never answers the upgrade request.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := d.DialContext(ctx, "ws://"+l.Addr().String()+Path); err != context.DeadlineExceeded {
+		t.Errorf("DialContext(unresponsive server) = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
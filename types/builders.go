@@ -0,0 +1,197 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// BuilderListingURL is the URL of the coordinator's listing of its
+// builders and host types, as JSON.
+const BuilderListingURL = "https://farmer.golang.org/builders?mode=json"
+
+// HostCapacityURL is the URL of the coordinator's listing of the current
+// capacity of its reverse host types, as a JSON object mapping their
+// names to their HostCapacity.
+const HostCapacityURL = "https://farmer.golang.org/capacity"
+
+// HostCapacity is the current capacity of a reverse host type of the
+// coordinator.
+type HostCapacity struct {
+	// HostType is the name of the host type, such as
+	// "host-darwin-arm64-13".
+	HostType string
+
+	// Expected is the number of machines expected to be connected,
+	// which is HostInfo.ExpectNum.
+	Expected int
+
+	// Connected is the number of machines currently connected.
+	Connected int
+
+	// Waiting is the number of builds and gomote instances currently
+	// waiting for a machine.
+	Waiting int
+}
+
+// BuilderListing is the data structure that's marshalled as JSON for
+// the https://farmer.golang.org/builders?mode=json page. It holds a
+// subset of the fields of the dashboard package's BuildConfig and
+// HostConfig, which the coordinator encodes; other fields are ignored
+// when decoding it.
+type BuilderListing struct {
+	// Builders are the builders, keyed by name.
+	Builders map[string]BuilderInfo
+
+	// Hosts are the host types the builders run on, keyed by name.
+	Hosts map[string]HostInfo
+
+	// Retired maps the names of builders which were removed to the
+	// names of the builders replacing them, or "" if there are none.
+	// It's empty in the listings of coordinators which predate it.
+	Retired map[string]string
+}
+
+// BuilderInfo describes a builder of a BuilderListing.
+type BuilderInfo struct {
+	// Name is the name of the builder, such as "linux-amd64-race".
+	Name string
+
+	// HostType is the key of the host type the builder runs on in
+	// BuilderListing.Hosts, such as "host-linux-amd64-bullseye".
+	HostType string
+
+	// KnownIssues are the numbers of the go.dev/issue issues the
+	// builder is known to fail because of, if any.
+	KnownIssues []int
+
+	// MakeScript and AllScript are the paths, relative to GOROOT, of
+	// the scripts which build Go, and build and test it, on the
+	// builder, such as "src/make.bash" and "src/race.bash". They're
+	// empty in the listings of coordinators which predate them.
+	MakeScript string
+	AllScript  string
+
+	// Notes are notes for humans.
+	Notes string
+}
+
+// HostInfo describes a host type of a BuilderListing.
+type HostInfo struct {
+	// HostType is the name of the host type, such as
+	// "host-linux-amd64-bullseye".
+	HostType string
+
+	// HostArch is the GOOS-GOARCH, with an optional suffix, of the
+	// machines of the host type, such as "openbsd-arm-5".
+	HostArch string
+
+	// GoBootstrap is the version of Go the host type bootstraps
+	// from, such as "go1.20.6", or "none".
+	GoBootstrap string
+
+	// VMImage and ContainerImage are the images the machines of a
+	// dynamic host type are created from. At least one of them is set
+	// unless IsReverse is.
+	VMImage        string
+	ContainerImage string
+
+	// IsReverse is whether the machines of the host type dial the
+	// coordinator, rather than being created on demand.
+	IsReverse bool
+
+	// ExpectNum is the number of machines expected to be connected
+	// for a reverse host type.
+	ExpectNum int
+
+	// HermeticReverse is whether the machines of a reverse host type
+	// have a fresh environment for each connection.
+	HermeticReverse bool
+
+	// GoogleReverse is whether the machines of a reverse host type
+	// are owned by Google.
+	GoogleReverse bool
+
+	// SSHUsername is the user to connect as with SSH, or empty if the
+	// host type doesn't support SSH.
+	SSHUsername string
+
+	// Notes are notes for humans.
+	Notes string
+}
+
+// Host returns the host type of builder, and whether builder and its
+// host type are both listed.
+func (l *BuilderListing) Host(builder string) (HostInfo, bool) {
+	bi, ok := l.Builders[builder]
+	if !ok {
+		return HostInfo{}, false
+	}
+	hi, ok := l.Hosts[bi.HostType]
+	return hi, ok
+}
+
+// BuildScript returns the path, relative to GOROOT, of the script which
+// builds Go on goos, such as "src/make.bash". It's for when the listing
+// of builders is unavailable, or doesn't know the builder; the MakeScript
+// of a builder is authoritative.
+func BuildScript(goos string) string {
+	switch goos {
+	case "windows":
+		return "src/make.bat"
+	case "plan9":
+		return "src/make.rc"
+	}
+	return "src/make.bash"
+}
+
+// AllScript returns the path, relative to GOROOT, of the script which
+// builds and tests Go on goos, such as "src/all.bash". Like BuildScript,
+// it's for when the listing of builders is unavailable; the AllScript of
+// a builder also accounts for race and cross-compiling builders.
+func AllScript(goos string) string {
+	switch goos {
+	case "windows":
+		return "src/all.bat"
+	case "plan9":
+		return "src/all.rc"
+	}
+	return "src/all.bash"
+}
+
+// FetchBuilderListing fetches the listing of builders at url, usually
+// BuilderListingURL, with client, or http.DefaultClient if client is
+// nil. If timeout is positive, it gives up after that long.
+func FetchBuilderListing(ctx context.Context, client *http.Client, url string, timeout time.Duration) (*BuilderListing, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, res.Status)
+	}
+	var l BuilderListing
+	if err := json.NewDecoder(res.Body).Decode(&l); err != nil {
+		return nil, fmt.Errorf("decoding builder list: %w", err)
+	}
+	return &l, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFetchBuilderListing(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+
+	got, err := FetchBuilderListing(context.Background(), nil, ts.URL+"/builders.json", 0)
+	if err != nil {
+		t.Fatalf("FetchBuilderListing = %v", err)
+	}
+	want := &BuilderListing{
+		Builders: map[string]BuilderInfo{
+			"aix-ppc64":          {Name: "aix-ppc64", HostType: "host-aix-ppc64-osuosl", KnownIssues: []int{45118}},
+			"linux-amd64":        {Name: "linux-amd64", HostType: "host-linux-amd64-bullseye", MakeScript: "src/make.bash", AllScript: "src/all.bash"},
+			"misc-compile-other": {Name: "misc-compile-other", HostType: "host-linux-amd64-bullseye"},
+		},
+		Hosts: map[string]HostInfo{
+			"host-aix-ppc64-osuosl": {
+				HostType:    "host-aix-ppc64-osuosl",
+				GoBootstrap: "go1.20.6",
+				IsReverse:   true,
+				ExpectNum:   1,
+				Notes:       "AIX 7.2 VM on OSU; run by Tony Reix",
+			},
+			"host-linux-amd64-bullseye": {
+				HostType:       "host-linux-amd64-bullseye",
+				GoBootstrap:    "go1.20.6",
+				ContainerImage: "linux-x86-bullseye:latest",
+				Notes:          "Debian Bullseye",
+				SSHUsername:    "root",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FetchBuilderListing mismatch (-want +got):\n%s", diff)
+	}
+	if hi, ok := got.Host("aix-ppc64"); !ok || !hi.IsReverse {
+		t.Errorf("Host(%q) = %+v, %t; want the reverse host type", "aix-ppc64", hi, ok)
+	}
+	if _, ok := got.Host("plan9-386"); ok {
+		t.Errorf("Host(%q) found a host type; want none", "plan9-386")
+	}
+
+	if _, err := FetchBuilderListing(context.Background(), nil, ts.URL+"/missing.json", 0); err == nil {
+		t.Errorf("FetchBuilderListing of a missing page = nil error; want error")
+	}
+}
+
+func TestBuildScript(t *testing.T) {
+	testCases := []struct {
+		goos     string
+		wantMake string
+		wantAll  string
+	}{
+		{"linux", "src/make.bash", "src/all.bash"},
+		{"darwin", "src/make.bash", "src/all.bash"},
+		{"windows", "src/make.bat", "src/all.bat"},
+		{"plan9", "src/make.rc", "src/all.rc"},
+		{"", "src/make.bash", "src/all.bash"},
+	}
+	for _, tc := range testCases {
+		if got := BuildScript(tc.goos); got != tc.wantMake {
+			t.Errorf("BuildScript(%q) = %q; want %q", tc.goos, got, tc.wantMake)
+		}
+		if got := AllScript(tc.goos); got != tc.wantAll {
+			t.Errorf("AllScript(%q) = %q; want %q", tc.goos, got, tc.wantAll)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GomoteInstanceInfo describes a gomote instance. It's what the gomote
+// server lists, and what "gomote list -json" and "gomote status -json"
+// print for each instance.
+//
+// Its JSON encoding is a stable interface for scripts: fields may be
+// added, but the existing JSON field names won't be renamed or removed.
+// Timestamps are encoded in RFC 3339 format, in UTC, and are omitted when
+// they're unknown.
+type GomoteInstanceInfo struct {
+	// ID is the name of the instance, such as
+	// "user-username-linux-amd64-0".
+	ID string `json:"id"`
+
+	// BuilderType is the builder type the instance was created with,
+	// such as "gotip-linux-amd64".
+	BuilderType string `json:"builder_type"`
+
+	// HostType is the host type of the builder type, such as
+	// "host-linux-amd64-bullseye".
+	HostType string `json:"host_type"`
+
+	// Created is when the instance was created, or zero if unknown.
+	Created time.Time `json:"created"`
+
+	// Expires is when the instance will be destroyed unless it's used
+	// or extended, or zero if unknown.
+	Expires time.Time `json:"expires"`
+
+	// LastActivity is the last time the instance ran a command, had
+	// files written or removed, or had an SSH session, or zero if
+	// unknown.
+	LastActivity time.Time `json:"last_activity"`
+
+	// Labels are the labels attached to the instance when it was
+	// created, if any.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Groups are the names of the local groups of the gomote command
+	// the instance is a member of, if any. The server doesn't know
+	// about them.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, omitting the zero timestamps of
+// ii rather than encoding them as dates in the year 1.
+func (ii GomoteInstanceInfo) MarshalJSON() ([]byte, error) {
+	// info has the fields of GomoteInstanceInfo, but not this method.
+	type info GomoteInstanceInfo
+	return json.Marshal(struct {
+		info
+		Created      *time.Time `json:"created,omitempty"`
+		Expires      *time.Time `json:"expires,omitempty"`
+		LastActivity *time.Time `json:"last_activity,omitempty"`
+	}{
+		info:         info(ii),
+		Created:      utcTime(ii.Created),
+		Expires:      utcTime(ii.Expires),
+		LastActivity: utcTime(ii.LastActivity),
+	})
+}
+
+// utcTime returns t in UTC, or nil if t is zero.
+func utcTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGomoteInstanceInfoJSON(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	testCases := []struct {
+		desc string
+		ii   GomoteInstanceInfo
+		want string
+	}{
+		{
+			desc: "full",
+			ii: GomoteInstanceInfo{
+				ID:           "user-username-linux-amd64-0",
+				BuilderType:  "gotip-linux-amd64",
+				HostType:     "host-linux-amd64-bullseye",
+				Created:      created,
+				Expires:      created.Add(30 * time.Minute),
+				LastActivity: created.Add(time.Minute),
+				Labels:       map[string]string{"cl": "12345"},
+				Groups:       []string{"g"},
+			},
+			want: `{"id":"user-username-linux-amd64-0","builder_type":"gotip-linux-amd64","host_type":"host-linux-amd64-bullseye","labels":{"cl":"12345"},"groups":["g"],"created":"2024-05-01T17:00:00Z","expires":"2024-05-01T17:30:00Z","last_activity":"2024-05-01T17:01:00Z"}`,
+		},
+		{
+			desc: "unknown timestamps",
+			ii:   GomoteInstanceInfo{ID: "user-username-linux-amd64-0", BuilderType: "gotip-linux-amd64"},
+			want: `{"id":"user-username-linux-amd64-0","builder_type":"gotip-linux-amd64","host_type":""}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := json.Marshal(tc.ii)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("json.Marshal(%+v) =\n%s\nwant:\n%s", tc.ii, got, tc.want)
+			}
+			var back GomoteInstanceInfo
+			if err := json.Unmarshal(got, &back); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			for _, p := range []*time.Time{&tc.ii.Created, &tc.ii.Expires, &tc.ii.LastActivity} {
+				if !p.IsZero() {
+					*p = p.UTC()
+				}
+			}
+			if !reflect.DeepEqual(back, tc.ii) {
+				t.Errorf("decoded %s as %+v; want %+v", got, back, tc.ii)
+			}
+		})
+	}
+}
@@ -173,16 +173,35 @@ type BuildletWaitStatus struct {
 	// If present, all other fields are ignored.
 	Message string `json:"message"`
 
-	// Ahead are the number of waiters ahead of this buildlet request.
+	// Ahead are the number of waiters ahead of this buildlet request,
+	// across all host types.
 	Ahead int `json:"ahead"`
 
+	// AheadHostType are the number of waiters ahead of this buildlet
+	// request for the same host type.
+	AheadHostType int `json:"aheadHostType,omitempty"`
+
+	// PoolSize is the number of machines expected in the pool of a
+	// static (reverse) host type. It is zero for dynamic host types,
+	// whose buildlets are created on demand.
+	PoolSize int `json:"poolSize,omitempty"`
+
+	// Connected is the number of machines of a static (reverse) host
+	// type currently connected to the coordinator.
+	Connected int `json:"connected,omitempty"`
+
+	// EstimatedWait is how much longer the request is estimated to
+	// wait for its buildlet, from how long recent requests for the
+	// same host type waited. It is zero if there's no estimate.
+	EstimatedWait time.Duration `json:"estimatedWait,omitempty"`
+
+	// EstimatedAt is when EstimatedWait was estimated, or nil if
+	// there's no estimate.
+	EstimatedAt *time.Time `json:"estimatedAt,omitempty"`
+
 	// TODO: add number of active builds, and number of builds
 	// creating. And for how long. And maybe an estimate of how
-	// long those builds typically take? But recognize which are
-	// dynamic vs static (reverse) builder types and don't say
-	// that "1 is creating" on a reverse buildlet that can't
-	// actually "create" any. (It can just wait for one register
-	// itself)
+	// long those builds typically take?
 }
 
 // ActivePostSubmitBuild is a summary of an active build that the
@@ -9,87 +9,87 @@
 // missing or were previously generated by this tool. If the file
 // contains a "<!-- End of auto-generated section -->" comment,
 // the tool leaves content in the rest of the file unmodified.
+// Likewise, if it contains a "<!-- Begin of auto-generated section -->"
+// comment, followed by the generated content and the end comment, the
+// tool leaves the content before it unmodified.
 //
 // The auto-generated Markdown contains the package doc synopsis
-// and a link to pkg.go.dev for the API reference.
+// and a link to pkg.go.dev for the API reference. The import path of each
+// package is that of the innermost module containing it, since some
+// directories of the tree are nested modules.
+//
+// The README.md files of commands may opt in to documenting their usage by
+// containing a "<!-- usage -->" comment after the end of the generated
+// section. Their generated section then contains the whole package
+// comment, rather than its synopsis, and a table of the flags registered
+// with the flag package, which are found by parsing the source of the
+// command rather than running it.
+//
+// The tool also creates or updates PACKAGES.md, an index of the packages
+// of the tree grouped by top-level directory, with the same rules. It
+// lists the packages without a package comment, or with a README file,
+// in a separate section.
+//
+// With the -check flag, the tool doesn't write anything. Instead, it
+// prints a diff of each README.md file it would create or update, and
+// exits with status 1 if there are any:
+//
+//	go run update-readmes.go -check
+//
+// With the -since flag, the tool only imports the packages in directories
+// with files changed since the given git revision, or in the working tree,
+// and those without a README.md file, and leaves the index alone:
+//
+//	go run update-readmes.go -since origin/master
+//
+// If git can't list the changes, the tool updates every file.
+//
+// The -template flag names a file with a text/template for the README.md
+// files to use instead of the default one, readmes.DefaultTemplate. It's
+// executed with a readmes.Data. Its output must contain the header
+// "<!-- Auto-generated by x/build/update-readmes.go -->", or later runs
+// would leave the files alone, and the footer of the existing files.
 package main
 
 import (
-	"bytes"
+	"flag"
 	"fmt"
 	"go/build"
 	"log"
 	"os"
-	"path/filepath"
-	"strings"
+
+	"golang.org/x/build/internal/readmes"
+)
+
+var (
+	check    = flag.Bool("check", false, "don't write any files; print a diff of each README.md file which is missing or out of date, and exit with status 1 if there are any")
+	since    = flag.String("since", "", "if non-empty, a git revision; only update the README.md files of directories with changes since it, and those which are missing")
+	tmplFile = flag.String("template", "", "if non-empty, the file of the text/template of the README.md files, instead of the default one")
+	verbose  = flag.Bool("v", false, "log how long each directory took, and how many README.md files were written, unchanged and skipped")
 )
 
 func main() {
+	flag.Parse()
 	root, err := build.Import("golang.org/x/build", "", build.FindOnly)
 	if err != nil {
 		log.Fatalf("failed to find golang.org/x/build root: %v", err)
 	}
-	err = filepath.Walk(root.Dir, func(path string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !fi.IsDir() {
-			return nil
-		}
-		rest := strings.TrimPrefix(strings.TrimPrefix(path, root.Dir), "/")
-		switch rest {
-		case "env", "version", "vendor":
-			return filepath.SkipDir
-		}
-		pkgName := "golang.org/x/build/" + filepath.ToSlash(rest)
-
-		bctx := build.Default
-		bctx.Dir = path // Set Dir since some x/build packages are in nested modules.
-		pkg, err := bctx.Import(pkgName, "", 0)
-		if err != nil {
-			// Skip.
-			return nil
-		}
-		if pkg.Doc == "" {
-			// There's no package comment, so don't create an empty README.
-			return nil
-		}
-		if _, err := os.Stat(filepath.Join(pkg.Dir, "README")); err == nil {
-			// Directory has exiting README; don't touch.
-			return nil
-		}
-		readmePath := filepath.Join(pkg.Dir, "README.md")
-		exist, err := os.ReadFile(readmePath)
-		if err != nil && !os.IsNotExist(err) {
-			// A real error.
-			return err
-		}
-		const header = "Auto-generated by x/build/update-readmes.go"
-		if len(exist) > 0 && !bytes.Contains(exist, []byte(header)) {
-			return nil
-		}
-		var footer []byte
-		if i := bytes.Index(exist, []byte("<!-- End of auto-generated section -->")); i != -1 {
-			footer = exist[i:]
-		}
-		newContents := []byte(fmt.Sprintf(`<!-- %s -->
-
-[![Go Reference](https://pkg.go.dev/badge/%s.svg)](https://pkg.go.dev/%s)
-
-# %s
-
-%s
-%s`, header, pkgName, pkgName, pkgName, pkg.Doc, footer))
-		if bytes.Equal(exist, newContents) {
-			return nil
-		}
-		if err := os.WriteFile(readmePath, newContents, 0644); err != nil {
-			return err
-		}
-		log.Printf("Wrote %s", readmePath)
-		return nil
-	})
+	tmpl, err := readmes.LoadTemplate(*tmplFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	opts := readmes.Options{Template: tmpl, Check: *check, Verbose: *verbose}
+	if *since != "" {
+		opts.Scope = readmes.SinceScope(*since, func(rev string) ([]string, error) {
+			return readmes.GitChangedPaths(root.Dir, rev)
+		})
+	}
+	stale, err := readmes.Update(root.Dir, opts, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check && len(stale) > 0 {
+		fmt.Fprintf(os.Stderr, "%d README.md files are out of date; run go run update-readmes.go to update them\n", len(stale))
+		os.Exit(1)
+	}
 }